@@ -0,0 +1,293 @@
+package server
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync"
+
+	accountbalance "github.com/withobsrvr/account-balance-processor/gen/account_balance_service"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/sac"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+// balanceKeySymbol is the first element of the storage key under which both
+// the Stellar Asset Contract and the SEP-41 reference token keep balances:
+// Vec[Symbol("Balance"), Address]
+const balanceKeySymbol = "Balance"
+
+// balanceSnapshot is one side (pre or post) of a balance-carrying ledger entry
+type balanceSnapshot struct {
+	holder          string
+	holderType      accountbalance.HolderType
+	source          accountbalance.BalanceSource
+	assetCode       string
+	assetIssuer     string
+	contractID      string
+	liquidityPoolID string
+	amount          *big.Int
+	lastModified    uint32
+}
+
+// sacAssetCache maps Stellar Asset Contract IDs to the classic asset they
+// wrap. A SAC balance entry only names its contract, so the asset is learned
+// from instance entries seen in the stream or from the request's filter.
+type sacAssetCache struct {
+	mu     sync.RWMutex
+	assets map[xdr.ContractId]xdr.Asset
+}
+
+func newSACAssetCache() *sacAssetCache {
+	return &sacAssetCache{assets: make(map[xdr.ContractId]xdr.Asset)}
+}
+
+func (c *sacAssetCache) get(id xdr.ContractId) (xdr.Asset, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	asset, ok := c.assets[id]
+	return asset, ok
+}
+
+func (c *sacAssetCache) put(id xdr.ContractId, asset xdr.Asset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.assets[id] = asset
+}
+
+// rememberSACAssets records the asset of every SAC instance entry in changes
+func (s *AccountBalanceServer) rememberSACAssets(changes []ingest.Change) {
+	for _, change := range changes {
+		if change.Type != xdr.LedgerEntryTypeContractData || change.Post == nil {
+			continue
+		}
+		asset, ok := sac.AssetFromContractData(*change.Post, s.networkPassphrase)
+		if !ok {
+			continue
+		}
+		s.sacAssets.put(*change.Post.Data.ContractData.Contract.ContractId, asset)
+	}
+}
+
+// rememberFilterAsset derives the SAC contract ID of the requested asset so
+// its contract balances resolve even if the instance entry never streams by
+func (s *AccountBalanceServer) rememberFilterAsset(code, issuer string) {
+	if code == "" || issuer == "" {
+		return
+	}
+	asset, err := xdr.NewCreditAsset(code, issuer)
+	if err != nil {
+		return
+	}
+	id, err := asset.ContractID(s.networkPassphrase)
+	if err != nil {
+		return
+	}
+	s.sacAssets.put(xdr.ContractId(id), asset)
+}
+
+// balanceFromChange turns a ledger entry change into an AccountBalance carrying
+// the new balance, the previous one and their difference. Entries that hold no
+// balance, and updates that leave the balance untouched, yield false.
+func (s *AccountBalanceServer) balanceFromChange(change ingest.Change, ledgerSeq uint32) (*accountbalance.AccountBalance, bool) {
+	pre, preOK := s.balanceFromEntry(change.Pre)
+	post, postOK := s.balanceFromEntry(change.Post)
+	if !preOK && !postOK {
+		return nil, false
+	}
+
+	previous := new(big.Int)
+	current := new(big.Int)
+	snapshot := post
+	lastModified := post.lastModified
+	if preOK {
+		previous = pre.amount
+	}
+	if postOK {
+		current = post.amount
+	} else {
+		snapshot = pre
+		lastModified = ledgerSeq
+	}
+	if preOK && postOK && previous.Cmp(current) == 0 {
+		return nil, false
+	}
+
+	return &accountbalance.AccountBalance{
+		AccountId:          snapshot.holder,
+		AssetCode:          snapshot.assetCode,
+		AssetIssuer:        snapshot.assetIssuer,
+		LastModifiedLedger: lastModified,
+		Balance:            current.String(),
+		PreviousBalance:    previous.String(),
+		Delta:              new(big.Int).Sub(current, previous).String(),
+		HolderType:         snapshot.holderType,
+		Source:             snapshot.source,
+		ContractId:         snapshot.contractID,
+		LiquidityPoolId:    snapshot.liquidityPoolID,
+	}, true
+}
+
+func (s *AccountBalanceServer) balanceFromEntry(entry *xdr.LedgerEntry) (balanceSnapshot, bool) {
+	if entry == nil {
+		return balanceSnapshot{}, false
+	}
+
+	snapshot := balanceSnapshot{lastModified: uint32(entry.LastModifiedLedgerSeq)}
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		account := entry.Data.MustAccount()
+		snapshot.holder = account.AccountId.Address()
+		snapshot.holderType = accountbalance.HolderType_HOLDER_TYPE_ACCOUNT
+		snapshot.source = accountbalance.BalanceSource_BALANCE_SOURCE_NATIVE
+		snapshot.amount = big.NewInt(int64(account.Balance))
+		return snapshot, true
+
+	case xdr.LedgerEntryTypeTrustline:
+		trustline := entry.Data.MustTrustLine()
+		snapshot.holder = trustline.AccountId.Address()
+		snapshot.holderType = accountbalance.HolderType_HOLDER_TYPE_ACCOUNT
+		snapshot.source = accountbalance.BalanceSource_BALANCE_SOURCE_TRUSTLINE
+		snapshot.amount = big.NewInt(int64(trustline.Balance))
+		if trustline.Asset.Type == xdr.AssetTypeAssetTypePoolShare {
+			poolID := trustline.Asset.MustLiquidityPoolId()
+			snapshot.liquidityPoolID = hex.EncodeToString(poolID[:])
+		} else {
+			asset := trustline.Asset.ToAsset()
+			snapshot.assetCode = asset.GetCode()
+			snapshot.assetIssuer = asset.GetIssuer()
+		}
+		return snapshot, true
+
+	case xdr.LedgerEntryTypeContractData:
+		return s.contractBalanceFromEntry(entry.Data.MustContractData(), snapshot)
+	}
+
+	return balanceSnapshot{}, false
+}
+
+// contractBalanceFromEntry recognizes the two contract balance layouts: the
+// SAC stores Map{amount: i128, authorized: bool, clawback: bool}, while the
+// SEP-41 reference token stores the i128 amount directly.
+func (s *AccountBalanceServer) contractBalanceFromEntry(data xdr.ContractDataEntry, snapshot balanceSnapshot) (balanceSnapshot, bool) {
+	if data.Durability != xdr.ContractDataDurabilityPersistent || data.Contract.ContractId == nil {
+		return balanceSnapshot{}, false
+	}
+
+	keyVec, ok := data.Key.GetVec()
+	if !ok || keyVec == nil || len(*keyVec) != 2 {
+		return balanceSnapshot{}, false
+	}
+	if sym, ok := (*keyVec)[0].GetSym(); !ok || sym != balanceKeySymbol {
+		return balanceSnapshot{}, false
+	}
+	holder, ok := (*keyVec)[1].GetAddress()
+	if !ok {
+		return balanceSnapshot{}, false
+	}
+
+	switch holder.Type {
+	case xdr.ScAddressTypeScAddressTypeAccount:
+		snapshot.holderType = accountbalance.HolderType_HOLDER_TYPE_ACCOUNT
+	case xdr.ScAddressTypeScAddressTypeContract:
+		snapshot.holderType = accountbalance.HolderType_HOLDER_TYPE_CONTRACT
+	case xdr.ScAddressTypeScAddressTypeMuxedAccount:
+		snapshot.holderType = accountbalance.HolderType_HOLDER_TYPE_MUXED
+	default:
+		return balanceSnapshot{}, false
+	}
+	holderAddress, err := holder.String()
+	if err != nil {
+		return balanceSnapshot{}, false
+	}
+	snapshot.holder = holderAddress
+
+	contractID := *data.Contract.ContractId
+	snapshot.contractID = strkey.MustEncode(strkey.VersionByteContract, contractID[:])
+
+	if amount, ok := data.Val.GetI128(); ok {
+		snapshot.source = accountbalance.BalanceSource_BALANCE_SOURCE_SEP41
+		snapshot.amount = int128ToBigInt(amount)
+		return snapshot, true
+	}
+
+	amount, ok := sacBalanceAmount(data.Val)
+	if !ok {
+		return balanceSnapshot{}, false
+	}
+	snapshot.source = accountbalance.BalanceSource_BALANCE_SOURCE_SAC
+	snapshot.amount = int128ToBigInt(amount)
+	if contractID != s.nativeAssetContractID {
+		if asset, ok := s.sacAssets.get(contractID); ok {
+			snapshot.assetCode = asset.GetCode()
+			snapshot.assetIssuer = asset.GetIssuer()
+		}
+	}
+	return snapshot, true
+}
+
+// sacBalanceAmount extracts the amount from a SAC balance value
+func sacBalanceAmount(val xdr.ScVal) (xdr.Int128Parts, bool) {
+	balanceMap, ok := val.GetMap()
+	if !ok || balanceMap == nil || len(*balanceMap) != 3 {
+		return xdr.Int128Parts{}, false
+	}
+	entries := *balanceMap
+	for i, name := range []string{"amount", "authorized", "clawback"} {
+		if sym, ok := entries[i].Key.GetSym(); !ok || string(sym) != name {
+			return xdr.Int128Parts{}, false
+		}
+	}
+	if !entries[1].Val.IsBool() || !entries[2].Val.IsBool() {
+		return xdr.Int128Parts{}, false
+	}
+	return entries[0].Val.GetI128()
+}
+
+func int128ToBigInt(parts xdr.Int128Parts) *big.Int {
+	value := new(big.Int).Lsh(big.NewInt(int64(parts.Hi)), 64)
+	return value.Add(value, new(big.Int).SetUint64(uint64(parts.Lo)))
+}
+
+// validateStreamRequest rejects filters that could never match
+func validateStreamRequest(req *accountbalance.StreamAccountBalancesRequest) error {
+	for _, source := range req.Sources {
+		if _, ok := accountbalance.BalanceSource_name[int32(source)]; !ok || source == accountbalance.BalanceSource_BALANCE_SOURCE_UNSPECIFIED {
+			return fmt.Errorf("sources: unknown balance source %d", source)
+		}
+	}
+	if req.FilterContractId != "" {
+		if _, err := strkey.Decode(strkey.VersionByteContract, req.FilterContractId); err != nil {
+			return fmt.Errorf("filter_contract_id: %q is not a contract address", req.FilterContractId)
+		}
+	}
+	return nil
+}
+
+// matchesRequest applies the request's optional filters to a balance
+func matchesRequest(balance *accountbalance.AccountBalance, req *accountbalance.StreamAccountBalancesRequest) bool {
+	if len(req.Sources) > 0 {
+		found := false
+		for _, source := range req.Sources {
+			if source == balance.Source {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if req.FilterAssetCode != "" && balance.AssetCode != req.FilterAssetCode {
+		return false
+	}
+	if req.FilterAssetIssuer != "" && balance.AssetIssuer != req.FilterAssetIssuer {
+		return false
+	}
+	if req.FilterContractId != "" && balance.ContractId != req.FilterContractId {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"testing"
+
+	accountbalance "github.com/withobsrvr/account-balance-processor/gen/account_balance_service"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"google.golang.org/protobuf/proto"
+)
+
+const testPassphrase = "Test SDF Network ; September 2015"
+
+var (
+	alice  = strkey.MustEncode(strkey.VersionByteAccountID, bytes.Repeat([]byte{1}, 32))
+	issuer = strkey.MustEncode(strkey.VersionByteAccountID, bytes.Repeat([]byte{9}, 32))
+)
+
+func testServer(t *testing.T) *AccountBalanceServer {
+	t.Helper()
+	native, err := xdr.MustNewNativeAsset().ContractID(testPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &AccountBalanceServer{
+		networkPassphrase:     testPassphrase,
+		nativeAssetContractID: native,
+		sacAssets:             newSACAssetCache(),
+	}
+}
+
+func accountEntry(balance int64, lastModified uint32) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{
+		LastModifiedLedgerSeq: xdr.Uint32(lastModified),
+		Data: xdr.LedgerEntryData{
+			Type:    xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{AccountId: xdr.MustAddress(alice), Balance: xdr.Int64(balance)},
+		},
+	}
+}
+
+func trustlineEntry(asset xdr.TrustLineAsset, balance int64) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 10,
+		Data: xdr.LedgerEntryData{
+			Type:      xdr.LedgerEntryTypeTrustline,
+			TrustLine: &xdr.TrustLineEntry{AccountId: xdr.MustAddress(alice), Asset: asset, Balance: xdr.Int64(balance)},
+		},
+	}
+}
+
+func symbol(s string) xdr.ScVal {
+	sym := xdr.ScSymbol(s)
+	return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym}
+}
+
+func i128(hi int64, lo uint64) xdr.ScVal {
+	return xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &xdr.Int128Parts{Hi: xdr.Int64(hi), Lo: xdr.Uint64(lo)}}
+}
+
+func boolVal(b bool) xdr.ScVal {
+	return xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &b}
+}
+
+func sacBalance(amount xdr.ScVal) xdr.ScVal {
+	m := &xdr.ScMap{
+		{Key: symbol("amount"), Val: amount},
+		{Key: symbol("authorized"), Val: boolVal(true)},
+		{Key: symbol("clawback"), Val: boolVal(false)},
+	}
+	return xdr.ScVal{Type: xdr.ScValTypeScvMap, Map: &m}
+}
+
+// contractBalanceEntry is a Balance(holder) entry of contract, as the SAC
+// and the SEP-41 reference token store them
+func contractBalanceEntry(contract xdr.ContractId, holder xdr.ScAddress, val xdr.ScVal) *xdr.LedgerEntry {
+	vec := &xdr.ScVec{symbol(balanceKeySymbol), {Type: xdr.ScValTypeScvAddress, Address: &holder}}
+	return &xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 10,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contract},
+				Key:        xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &vec},
+				Durability: xdr.ContractDataDurabilityPersistent,
+				Val:        val,
+			},
+		},
+	}
+}
+
+func change(entryType xdr.LedgerEntryType, pre, post *xdr.LedgerEntry) ingest.Change {
+	return ingest.Change{Type: entryType, Pre: pre, Post: post}
+}
+
+func TestBalanceFromChange(t *testing.T) {
+	usdc := xdr.MustNewCreditAsset("USDC", issuer)
+	usdcContract, err := usdc.ContractID(testPassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var poolID xdr.PoolId
+	poolID[0] = 0xab
+	poolShare := xdr.TrustLineAsset{Type: xdr.AssetTypeAssetTypePoolShare, LiquidityPoolId: &poolID}
+
+	token := xdr.ContractId{5}
+	tokenAddress := strkey.MustEncode(strkey.VersionByteContract, token[:])
+	holderContract := xdr.ContractId{6}
+	holder := xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &holderContract}
+	holderAddress := strkey.MustEncode(strkey.VersionByteContract, holderContract[:])
+	aliceHolder := xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: xdr.MustAddressPtr(alice)}
+
+	// 2^64 + 5: above int64, split across the i128 halves
+	aboveInt64 := i128(1, 5)
+
+	tests := []struct {
+		name   string
+		change ingest.Change
+		want   *accountbalance.AccountBalance
+	}{
+		{
+			name:   "native account created",
+			change: change(xdr.LedgerEntryTypeAccount, nil, accountEntry(100, 10)),
+			want: &accountbalance.AccountBalance{
+				AccountId: alice, LastModifiedLedger: 10, Balance: "100", PreviousBalance: "0", Delta: "100",
+				HolderType: accountbalance.HolderType_HOLDER_TYPE_ACCOUNT, Source: accountbalance.BalanceSource_BALANCE_SOURCE_NATIVE,
+			},
+		},
+		{
+			name:   "native account updated",
+			change: change(xdr.LedgerEntryTypeAccount, accountEntry(100, 10), accountEntry(40, 11)),
+			want: &accountbalance.AccountBalance{
+				AccountId: alice, LastModifiedLedger: 11, Balance: "40", PreviousBalance: "100", Delta: "-60",
+				HolderType: accountbalance.HolderType_HOLDER_TYPE_ACCOUNT, Source: accountbalance.BalanceSource_BALANCE_SOURCE_NATIVE,
+			},
+		},
+		{
+			name:   "native account removed",
+			change: change(xdr.LedgerEntryTypeAccount, accountEntry(40, 11), nil),
+			want: &accountbalance.AccountBalance{
+				AccountId: alice, LastModifiedLedger: 12, Balance: "0", PreviousBalance: "40", Delta: "-40",
+				HolderType: accountbalance.HolderType_HOLDER_TYPE_ACCOUNT, Source: accountbalance.BalanceSource_BALANCE_SOURCE_NATIVE,
+			},
+		},
+		{
+			name:   "unchanged balance",
+			change: change(xdr.LedgerEntryTypeAccount, accountEntry(100, 10), accountEntry(100, 11)),
+		},
+		{
+			name:   "trustline",
+			change: change(xdr.LedgerEntryTypeTrustline, trustlineEntry(usdc.ToTrustLineAsset(), 5), trustlineEntry(usdc.ToTrustLineAsset(), math.MaxInt64)),
+			want: &accountbalance.AccountBalance{
+				AccountId: alice, AssetCode: "USDC", AssetIssuer: issuer, LastModifiedLedger: 10,
+				Balance: "9223372036854775807", PreviousBalance: "5", Delta: "9223372036854775802",
+				HolderType: accountbalance.HolderType_HOLDER_TYPE_ACCOUNT, Source: accountbalance.BalanceSource_BALANCE_SOURCE_TRUSTLINE,
+			},
+		},
+		{
+			name:   "pool share trustline",
+			change: change(xdr.LedgerEntryTypeTrustline, nil, trustlineEntry(poolShare, 7)),
+			want: &accountbalance.AccountBalance{
+				AccountId: alice, LastModifiedLedger: 10, Balance: "7", PreviousBalance: "0", Delta: "7",
+				HolderType: accountbalance.HolderType_HOLDER_TYPE_ACCOUNT, Source: accountbalance.BalanceSource_BALANCE_SOURCE_TRUSTLINE,
+				LiquidityPoolId: "ab00000000000000000000000000000000000000000000000000000000000000",
+			},
+		},
+		{
+			name: "SAC balance of a contract holder",
+			change: change(xdr.LedgerEntryTypeContractData,
+				contractBalanceEntry(usdcContract, holder, sacBalance(i128(0, 10))),
+				contractBalanceEntry(usdcContract, holder, sacBalance(aboveInt64))),
+			want: &accountbalance.AccountBalance{
+				AccountId: holderAddress, AssetCode: "USDC", AssetIssuer: issuer, LastModifiedLedger: 10,
+				Balance: "18446744073709551621", PreviousBalance: "10", Delta: "18446744073709551611",
+				HolderType: accountbalance.HolderType_HOLDER_TYPE_CONTRACT, Source: accountbalance.BalanceSource_BALANCE_SOURCE_SAC,
+				ContractId: strkey.MustEncode(strkey.VersionByteContract, usdcContract[:]),
+			},
+		},
+		{
+			name:   "SEP-41 raw i128 above int64",
+			change: change(xdr.LedgerEntryTypeContractData, nil, contractBalanceEntry(token, aliceHolder, aboveInt64)),
+			want: &accountbalance.AccountBalance{
+				AccountId: alice, LastModifiedLedger: 10, Balance: "18446744073709551621", PreviousBalance: "0", Delta: "18446744073709551621",
+				HolderType: accountbalance.HolderType_HOLDER_TYPE_ACCOUNT, Source: accountbalance.BalanceSource_BALANCE_SOURCE_SEP41,
+				ContractId: tokenAddress,
+			},
+		},
+		{
+			name:   "SEP-41 balance removed",
+			change: change(xdr.LedgerEntryTypeContractData, contractBalanceEntry(token, aliceHolder, i128(0, 3)), nil),
+			want: &accountbalance.AccountBalance{
+				AccountId: alice, LastModifiedLedger: 12, Balance: "0", PreviousBalance: "3", Delta: "-3",
+				HolderType: accountbalance.HolderType_HOLDER_TYPE_ACCOUNT, Source: accountbalance.BalanceSource_BALANCE_SOURCE_SEP41,
+				ContractId: tokenAddress,
+			},
+		},
+		{
+			name:   "contract data that is not a balance",
+			change: change(xdr.LedgerEntryTypeContractData, nil, contractBalanceEntry(token, aliceHolder, symbol("nope"))),
+		},
+	}
+
+	s := testServer(t)
+	s.rememberFilterAsset("USDC", issuer)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.balanceFromChange(tt.change, 12)
+			if tt.want == nil {
+				if ok {
+					t.Fatalf("balanceFromChange = %+v, want no balance", got)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("balanceFromChange yielded no balance")
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("balanceFromChange =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInt128ToBigInt(t *testing.T) {
+	twoTo64 := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	tests := []struct {
+		hi   int64
+		lo   uint64
+		want *big.Int
+	}{
+		{0, 0, big.NewInt(0)},
+		{0, math.MaxUint64, new(big.Int).SetUint64(math.MaxUint64)},
+		{1, 5, new(big.Int).Add(twoTo64, big.NewInt(5))},
+		{-1, math.MaxUint64, big.NewInt(-1)},
+		{-1, 0, new(big.Int).Neg(twoTo64)},
+		{-2, 3, new(big.Int).Add(new(big.Int).Mul(big.NewInt(-2), twoTo64), big.NewInt(3))},
+		{math.MinInt64, 0, new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))},
+	}
+
+	for _, tt := range tests {
+		got := int128ToBigInt(xdr.Int128Parts{Hi: xdr.Int64(tt.hi), Lo: xdr.Uint64(tt.lo)})
+		if got.Cmp(tt.want) != 0 {
+			t.Errorf("int128ToBigInt(%d, %d) = %s, want %s", tt.hi, tt.lo, got, tt.want)
+		}
+	}
+}
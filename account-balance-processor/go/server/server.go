@@ -13,7 +13,6 @@ import (
 
 	// Import Stellar SDK packages
 	"github.com/stellar/go/ingest"
-	"github.com/stellar/go/processors/trustline"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 	"go.uber.org/zap"
@@ -82,6 +81,10 @@ type AccountBalanceServer struct {
 	logger            *zap.Logger
 	metrics           *ProcessorMetrics
 	configPath        string // Path to config file (if used)
+
+	// nativeAssetContractID is the SAC wrapping XLM on this network
+	nativeAssetContractID xdr.ContractId
+	sacAssets             *sacAssetCache
 }
 
 // NewAccountBalanceServer creates a new instance of the account balance processor server
@@ -104,13 +107,21 @@ func NewAccountBalanceServer(passphrase string, sourceServiceAddr string, config
 
 	client := rawledger.NewRawLedgerServiceClient(conn)
 
+	nativeAssetContractID, err := xdr.MustNewNativeAsset().ContractID(passphrase)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "failed to derive native asset contract ID")
+	}
+
 	return &AccountBalanceServer{
-		networkPassphrase: passphrase,
-		rawLedgerClient:   client,
-		rawLedgerConn:     conn,
-		logger:            logger,
-		metrics:           NewProcessorMetrics(),
-		configPath:        configPath,
+		networkPassphrase:     passphrase,
+		rawLedgerClient:       client,
+		rawLedgerConn:         conn,
+		logger:                logger,
+		metrics:               NewProcessorMetrics(),
+		configPath:            configPath,
+		nativeAssetContractID: nativeAssetContractID,
+		sacAssets:             newSACAssetCache(),
 	}, nil
 }
 
@@ -131,9 +142,15 @@ func (s *AccountBalanceServer) StreamAccountBalances(req *accountbalance.StreamA
 		zap.Uint32("end_ledger", req.EndLedger),
 		zap.String("filter_asset_code", req.FilterAssetCode),
 		zap.String("filter_asset_issuer", req.FilterAssetIssuer),
+		zap.String("filter_contract_id", req.FilterContractId),
 	)
 	logger.Info("received StreamAccountBalances request")
 
+	if err := validateStreamRequest(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	s.rememberFilterAsset(req.FilterAssetCode, req.FilterAssetIssuer)
+
 	// Create a request for the raw ledger source service
 	sourceReq := &rawledger.StreamLedgersRequest{
 		StartLedger: req.StartLedger,
@@ -247,9 +264,6 @@ func (s *AccountBalanceServer) processLedger(
 			return balancesSent, errors.Wrapf(err, "failed to read transaction in ledger %d", rawLedgerMsg.Sequence)
 		}
 
-		// Get the ledger header for the trustline processor
-		header := lcm.LedgerHeaderHistoryEntry()
-
 		// Process all changes in this transaction
 		changes, err := tx.GetChanges()
 		if err != nil {
@@ -257,37 +271,15 @@ func (s *AccountBalanceServer) processLedger(
 				zap.Error(err))
 			continue
 		}
-		for _, change := range changes {
-			// We only care about trustline entries
-			if change.Type != xdr.LedgerEntryTypeTrustline {
-				continue
-			}
+		s.rememberSACAssets(changes)
 
-			// Use the Stellar SDK trustline processor to extract trustline data
-			trustlineOutput, err := trustline.TransformTrustline(change, header)
-			if err != nil {
-				logger.Warn("failed to transform trustline",
-					zap.Error(err))
-				continue
-			}
-
-			// Filter by asset if requested
-			if req.FilterAssetCode != "" && trustlineOutput.AssetCode != req.FilterAssetCode {
-				continue
-			}
-			if req.FilterAssetIssuer != "" && trustlineOutput.AssetIssuer != req.FilterAssetIssuer {
+		for _, change := range changes {
+			// Native, trustline and contract data entries carry balances
+			balance, ok := s.balanceFromChange(change, rawLedgerMsg.Sequence)
+			if !ok || !matchesRequest(balance, req) {
 				continue
 			}
 
-			// Convert to our AccountBalance message
-			balance := &accountbalance.AccountBalance{
-				AccountId:          trustlineOutput.AccountID,
-				AssetCode:          trustlineOutput.AssetCode,
-				AssetIssuer:        trustlineOutput.AssetIssuer,
-				Balance:            int64(trustlineOutput.Balance),
-				LastModifiedLedger: trustlineOutput.LastModifiedLedger,
-			}
-
 			// Send to consumer
 			if err := stream.Send(balance); err != nil {
 				logger.Error("failed to send balance to consumer",
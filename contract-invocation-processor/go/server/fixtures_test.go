@@ -0,0 +1,275 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"go.uber.org/zap"
+
+	rawledger "github.com/stellar/stellar-live-source/gen/raw_ledger_service"
+)
+
+// Fixture ledgers are assembled in memory: each invocation becomes a
+// single-operation Soroban transaction whose meta carries the given ledger
+// entry changes, so the processor sees the same shapes stellar-core emits.
+
+const testPassphrase = network.TestNetworkPassphrase
+
+var fixtureCloseTime = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type fixtureInvocation struct {
+	source    string
+	contract  xdr.ContractId
+	function  string
+	failed    bool
+	changes   xdr.LedgerEntryChanges
+	footprint []xdr.LedgerKey
+	events    []xdr.DiagnosticEvent
+}
+
+func newTestProcessor(now time.Time) *LedgerProcessor {
+	logger := zap.NewNop()
+	server := &ContractInvocationServer{
+		logger:            logger,
+		networkPassphrase: testPassphrase,
+		metrics:           &ProcessorMetrics{StartTime: now},
+	}
+	lp := NewLedgerProcessor(logger, testPassphrase, server)
+	lp.now = func() time.Time { return now }
+	return lp
+}
+
+func testAccount(seed byte) string {
+	return strkey.MustEncode(strkey.VersionByteAccountID, bytes.Repeat([]byte{seed}, 32))
+}
+
+func testContract(seed byte) xdr.ContractId {
+	var id xdr.ContractId
+	for i := range id {
+		id[i] = seed
+	}
+	return id
+}
+
+func contractAddress(id xdr.ContractId) string {
+	return strkey.MustEncode(strkey.VersionByteContract, id[:])
+}
+
+func scContract(id xdr.ContractId) xdr.ScAddress {
+	return xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &id}
+}
+
+func scSymbol(s string) xdr.ScVal {
+	sym := xdr.ScSymbol(s)
+	return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym}
+}
+
+func scU32(v uint32) xdr.ScVal {
+	u := xdr.Uint32(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &u}
+}
+
+func contractDataKey(contract xdr.ContractId, key string, durability xdr.ContractDataDurability) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type: xdr.LedgerEntryTypeContractData,
+		ContractData: &xdr.LedgerKeyContractData{
+			Contract:   scContract(contract),
+			Key:        scSymbol(key),
+			Durability: durability,
+		},
+	}
+}
+
+func contractDataEntry(contract xdr.ContractId, key string, durability xdr.ContractDataDurability, val uint32) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 1,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract:   scContract(contract),
+				Key:        scSymbol(key),
+				Durability: durability,
+				Val:        scU32(val),
+			},
+		},
+	}
+}
+
+func ttlEntry(t *testing.T, key xdr.LedgerKey, liveUntil uint32) xdr.LedgerEntry {
+	t.Helper()
+	keyBytes, err := key.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal ledger key: %v", err)
+	}
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 1,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTtl,
+			Ttl: &xdr.TtlEntry{
+				KeyHash:            xdr.Hash(sha256.Sum256(keyBytes)),
+				LiveUntilLedgerSeq: xdr.Uint32(liveUntil),
+			},
+		},
+	}
+}
+
+func entryCreated(post xdr.LedgerEntry) xdr.LedgerEntryChanges {
+	return xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &post},
+	}
+}
+
+func entryUpdated(pre, post xdr.LedgerEntry) xdr.LedgerEntryChanges {
+	return xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &pre},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &post},
+	}
+}
+
+func entryRemoved(t *testing.T, pre xdr.LedgerEntry) xdr.LedgerEntryChanges {
+	t.Helper()
+	key, err := pre.LedgerKey()
+	if err != nil {
+		t.Fatalf("ledger key: %v", err)
+	}
+	return xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &pre},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &key},
+	}
+}
+
+func contractEvent(contract xdr.ContractId, topic string, data uint32) xdr.DiagnosticEvent {
+	return xdr.DiagnosticEvent{
+		InSuccessfulContractCall: true,
+		Event: xdr.ContractEvent{
+			ContractId: &contract,
+			Type:       xdr.ContractEventTypeContract,
+			Body: xdr.ContractEventBody{
+				V: 0,
+				V0: &xdr.ContractEventV0{
+					Topics: xdr.ScVec{scSymbol(topic)},
+					Data:   scU32(data),
+				},
+			},
+		},
+	}
+}
+
+// fixtureLedger encodes the invocations as one ledger closed at closeTime
+func fixtureLedger(t *testing.T, seq uint32, closeTime time.Time, invocations ...fixtureInvocation) *rawledger.RawLedger {
+	t.Helper()
+
+	var envelopes []xdr.TransactionEnvelope
+	var processing []xdr.TransactionResultMeta
+	for i, inv := range invocations {
+		contract := inv.contract
+		envelope := xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: xdr.MustMuxedAddress(inv.source),
+					Fee:           100,
+					SeqNum:        xdr.SequenceNumber(i + 1),
+					Operations: []xdr.Operation{{
+						Body: xdr.OperationBody{
+							Type: xdr.OperationTypeInvokeHostFunction,
+							InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{
+								HostFunction: xdr.HostFunction{
+									Type: xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+									InvokeContract: &xdr.InvokeContractArgs{
+										ContractAddress: scContract(contract),
+										FunctionName:    xdr.ScSymbol(inv.function),
+										Args:            []xdr.ScVal{scU32(uint32(i))},
+									},
+								},
+							},
+						},
+					}},
+					Ext: xdr.TransactionExt{
+						V: 1,
+						SorobanData: &xdr.SorobanTransactionData{
+							Resources: xdr.SorobanResources{
+								Footprint: xdr.LedgerFootprint{ReadWrite: inv.footprint},
+							},
+						},
+					},
+				},
+			},
+		}
+		hash, err := network.HashTransactionInEnvelope(envelope, testPassphrase)
+		if err != nil {
+			t.Fatalf("hash transaction %d: %v", i, err)
+		}
+
+		txCode := xdr.TransactionResultCodeTxSuccess
+		opResult := xdr.InvokeHostFunctionResult{
+			Code:    xdr.InvokeHostFunctionResultCodeInvokeHostFunctionSuccess,
+			Success: &xdr.Hash{},
+		}
+		if inv.failed {
+			txCode = xdr.TransactionResultCodeTxFailed
+			opResult = xdr.InvokeHostFunctionResult{
+				Code: xdr.InvokeHostFunctionResultCodeInvokeHostFunctionTrapped,
+			}
+		}
+		results := []xdr.OperationResult{{
+			Code: xdr.OperationResultCodeOpInner,
+			Tr: &xdr.OperationResultTr{
+				Type:                     xdr.OperationTypeInvokeHostFunction,
+				InvokeHostFunctionResult: &opResult,
+			},
+		}}
+
+		envelopes = append(envelopes, envelope)
+		processing = append(processing, xdr.TransactionResultMeta{
+			Result: xdr.TransactionResultPair{
+				TransactionHash: hash,
+				Result: xdr.TransactionResult{
+					FeeCharged: 100,
+					Result: xdr.TransactionResultResult{
+						Code:    txCode,
+						Results: &results,
+					},
+				},
+			},
+			TxApplyProcessing: xdr.TransactionMeta{
+				V: 3,
+				V3: &xdr.TransactionMetaV3{
+					Operations: []xdr.OperationMeta{{Changes: inv.changes}},
+					SorobanMeta: &xdr.SorobanTransactionMeta{
+						ReturnValue:      xdr.ScVal{Type: xdr.ScValTypeScvVoid},
+						DiagnosticEvents: inv.events,
+					},
+				},
+			},
+		})
+	}
+
+	lcm := xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					LedgerVersion: 22,
+					LedgerSeq:     xdr.Uint32(seq),
+					ScpValue: xdr.StellarValue{
+						CloseTime: xdr.TimePoint(closeTime.Unix()),
+					},
+				},
+			},
+			TxSet:        xdr.TransactionSet{Txs: envelopes},
+			TxProcessing: processing,
+		},
+	}
+
+	raw, err := lcm.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal ledger %d: %v", seq, err)
+	}
+	return &rawledger.RawLedger{Sequence: seq, LedgerCloseMetaXdr: raw}
+}
@@ -1,9 +1,12 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"io"
+	"regexp"
 	"time"
 
 	"github.com/stellar/go/ingest"
@@ -30,6 +33,7 @@ type LedgerProcessor struct {
 	scValConverter    *ScValConverter
 	server            *ContractInvocationServer
 	protocol23Features *Protocol23Features
+	now               func() time.Time // clock for TimeFilter.recent_only
 }
 
 // NewLedgerProcessor creates a new ledger processor
@@ -40,6 +44,7 @@ func NewLedgerProcessor(logger *zap.Logger, networkPassphrase string, server *Co
 		scValConverter:    NewScValConverter(logger),
 		server:            server,
 		protocol23Features: NewProtocol23Features(logger),
+		now:               time.Now,
 	}
 }
 
@@ -64,6 +69,18 @@ func (lp *LedgerProcessor) ProcessLedger(
 		zap.Uint32("ledger_sequence", lcm.LedgerSequence()),
 		zap.Int("request_filters", lp.countActiveFilters(req)))
 
+	// Apply time filter to the whole ledger; every event in it shares the close time
+	closedAt := time.Unix(int64(lcm.LedgerHeaderHistoryEntry().Header.ScpValue.CloseTime), 0)
+	include, past := inTimeWindow(closedAt, req.TimeFilter, lp.now())
+	if past {
+		return nil, errPastTimeWindow
+	}
+	if !include {
+		ledgerLogger.Debug("ledger outside requested time window",
+			zap.Time("closed_at", closedAt))
+		return nil, nil
+	}
+
 	// Validate Protocol 23 compatibility
 	if err := lp.validateProtocol23Compatibility(lcm); err != nil {
 		return nil, fmt.Errorf("protocol 23 validation failed for ledger %d: %w", lcm.LedgerSequence(), err)
@@ -97,6 +114,9 @@ func (lp *LedgerProcessor) ProcessLedger(
 
 		txCount++
 
+		// Raw XDR is shared by every event in the transaction
+		var rawXdr *cipb.RawXdr
+
 		// Process each operation in the transaction
 		for opIndex, op := range tx.Envelope.Operations() {
 			event, err := lp.processOperation(tx, opIndex, op, lcm, req)
@@ -108,10 +128,23 @@ func (lp *LedgerProcessor) ProcessLedger(
 				continue
 			}
 
-			if event != nil {
-				events = append(events, event)
-				invocationCount++
+			if event == nil {
+				continue
+			}
+
+			if req.GetOptions().GetIncludeRawXdr() {
+				if rawXdr == nil {
+					rawXdr, err = rawXdrForTransaction(tx)
+					if err != nil {
+						return nil, fmt.Errorf("failed to encode raw XDR for transaction %d in ledger %d: %w", txCount-1, lcm.LedgerSequence(), err)
+					}
+				}
+				event.RawXdr = rawXdr
 			}
+			applyContentInclusion(event, req.GetOptions().GetInclusion())
+
+			events = append(events, event)
+			invocationCount++
 		}
 	}
 
@@ -322,6 +355,11 @@ func (lp *LedgerProcessor) processContractCall(
 		}
 	}
 
+	// Apply state change filter
+	if !matchesStateFilter(contractCall, req.StateFilter) {
+		return nil
+	}
+
 	return contractCall
 }
 
@@ -338,6 +376,11 @@ func (lp *LedgerProcessor) processCreateContract(
 		return nil
 	}
 
+	// Contract creation carries no state changes to match a state filter against
+	if stateFilterActive(req.StateFilter) {
+		return nil
+	}
+
 	createContract := invokeOp.HostFunction.MustCreateContract()
 
 	// Extract contract ID (this is generated during execution)
@@ -387,6 +430,11 @@ func (lp *LedgerProcessor) processUploadWasm(
 		return nil
 	}
 
+	// WASM uploads carry no state changes to match a state filter against
+	if stateFilterActive(req.StateFilter) {
+		return nil
+	}
+
 	wasmBytes := invokeOp.HostFunction.MustWasm()
 
 	// Calculate WASM hash (simplified - should use proper hashing)
@@ -455,6 +503,12 @@ func (lp *LedgerProcessor) countActiveFilters(req *pb.GetInvocationsRequest) int
 	if req.ContentFilter != nil {
 		count++
 	}
+	if req.TimeFilter != nil {
+		count++
+	}
+	if stateFilterActive(req.StateFilter) {
+		count++
+	}
 	return count
 }
 
@@ -1182,9 +1236,10 @@ func (lp *LedgerProcessor) processLedgerEntryChangeForState(change ingest.Change
 		return nil
 	}
 	
-	// Create or restore
-	if change.Pre == nil && change.Post != nil {
-		if change.Post != nil && change.Post.Data.Type == xdr.LedgerEntryTypeContractData {
+	switch {
+	case change.Pre == nil && change.Post != nil:
+		// Created, or restored from the archive
+		if change.Post.Data.Type == xdr.LedgerEntryTypeContractData {
 			return lp.createStateChange(
 				*change.Post.Data.ContractData,
 				nil,
@@ -1193,9 +1248,8 @@ func (lp *LedgerProcessor) processLedgerEntryChangeForState(change ingest.Change
 			)
 		}
 		
-	case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
-		if change.Pre != nil && change.Post != nil &&
-			change.Pre.Data.Type == xdr.LedgerEntryTypeContractData &&
+	case change.Pre != nil && change.Post != nil:
+		if change.Pre.Data.Type == xdr.LedgerEntryTypeContractData &&
 			change.Post.Data.Type == xdr.LedgerEntryTypeContractData {
 			
 			return lp.createStateChange(
@@ -1206,8 +1260,8 @@ func (lp *LedgerProcessor) processLedgerEntryChangeForState(change ingest.Change
 			)
 		}
 		
-	case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
-		if change.Pre != nil && change.Pre.Data.Type == xdr.LedgerEntryTypeContractData {
+	case change.Pre != nil && change.Post == nil:
+		if change.Pre.Data.Type == xdr.LedgerEntryTypeContractData {
 			return lp.createStateChange(
 				*change.Pre.Data.ContractData,
 				&change.Pre.Data.ContractData.Val,
@@ -1215,17 +1269,6 @@ func (lp *LedgerProcessor) processLedgerEntryChangeForState(change ingest.Change
 				cipb.StateChangeOperation_STATE_CHANGE_OPERATION_DELETE,
 			)
 		}
-		
-	case xdr.LedgerEntryChangeTypeLedgerEntryRestored:
-		// Handle restored entries as a special case of creation
-		if change.Post != nil && change.Post.Data.Type == xdr.LedgerEntryTypeContractData {
-			return lp.createStateChange(
-				*change.Post.Data.ContractData,
-				nil,
-				&change.Post.Data.ContractData.Val,
-				cipb.StateChangeOperation_STATE_CHANGE_OPERATION_CREATE,
-			)
-		}
 	}
 	
 	return nil
@@ -1238,7 +1281,7 @@ func (lp *LedgerProcessor) createStateChange(
 	operation cipb.StateChangeOperation,
 ) *cipb.StateChange {
 	// Extract contract ID
-	contractID, err := contractData.Contract.Address.String()
+	contractID, err := contractData.Contract.String()
 	if err != nil {
 		lp.logger.Debug("failed to extract contract ID from state change", zap.Error(err))
 		return nil
@@ -1251,6 +1294,7 @@ func (lp *LedgerProcessor) createStateChange(
 		ContractId: contractID,
 		Key:        key,
 		Operation:  operation,
+		Durability: convertDurability(contractData.Durability),
 	}
 	
 	// Convert old value if present
@@ -1266,33 +1310,51 @@ func (lp *LedgerProcessor) createStateChange(
 	return stateChange
 }
 
+// convertDurability maps XDR contract data durability onto the event enum
+func convertDurability(durability xdr.ContractDataDurability) cipb.ContractDataDurability {
+	if durability == xdr.ContractDataDurabilityPersistent {
+		return cipb.ContractDataDurability_CONTRACT_DATA_DURABILITY_PERSISTENT
+	}
+	return cipb.ContractDataDurability_CONTRACT_DATA_DURABILITY_TEMPORARY
+}
+
 func (lp *LedgerProcessor) extractTtlExtensions(tx ingest.LedgerTransaction, opIndex int) []*cipb.TtlExtension {
 	var ttlExtensions []*cipb.TtlExtension
 	
-	// TTL extensions are tracked through specific operations and state changes
-	// Check if this operation is an ExtendFootprintTtlOp
-	if len(tx.Envelope.Operations()) > opIndex {
-		op := tx.Envelope.Operations()[opIndex]
-		if op.Body.Type == xdr.OperationTypeExtendFootprintTtl {
-			extensions := lp.extractTtlFromExtendFootprintOp(op, tx)
-			ttlExtensions = append(ttlExtensions, extensions...)
-		}
+	// TTL entries are keyed by the hash of the entry they keep alive, so index
+	// the footprint's contract data keys by hash to see what was extended
+	footprintKeys := lp.footprintContractDataKeys(tx)
+	if len(footprintKeys) == 0 {
+		return ttlExtensions
 	}
 	
-	// Also check for automatic TTL extensions in Soroban operations
-	if tx.UnsafeMeta.V == 3 && tx.UnsafeMeta.V3 != nil && tx.UnsafeMeta.V3.SorobanMeta != nil {
-		extensions := lp.extractTtlFromSorobanMeta(tx.UnsafeMeta.V3.SorobanMeta, tx)
-		ttlExtensions = append(ttlExtensions, extensions...)
+	changes, err := tx.GetOperationChanges(uint32(opIndex))
+	if err != nil {
+		lp.logger.Debug("failed to get operation changes for TTL tracking",
+			zap.Error(err),
+			zap.Int("op_index", opIndex))
+		return ttlExtensions
 	}
 	
-	// Check operation changes for TTL-related modifications
-	changes, err := tx.GetOperationChanges(uint32(opIndex))
-	if err == nil {
-		for _, change := range changes {
-			extension := lp.extractTtlFromLedgerChange(change, tx)
-			if extension != nil {
-				ttlExtensions = append(ttlExtensions, extension)
-			}
+	for _, change := range changes {
+		if change.Type != xdr.LedgerEntryTypeTtl || change.Pre == nil || change.Post == nil {
+			continue
+		}
+		
+		preTtl := change.Pre.Data.MustTtl()
+		postTtl := change.Post.Data.MustTtl()
+		if postTtl.LiveUntilLedgerSeq <= preTtl.LiveUntilLedgerSeq {
+			continue
+		}
+		
+		key, ok := footprintKeys[postTtl.KeyHash]
+		if !ok {
+			continue
+		}
+		
+		extension := lp.createTtlExtension(key, uint32(preTtl.LiveUntilLedgerSeq), uint32(postTtl.LiveUntilLedgerSeq))
+		if extension != nil {
+			ttlExtensions = append(ttlExtensions, extension)
 		}
 	}
 	
@@ -1304,167 +1366,47 @@ func (lp *LedgerProcessor) extractTtlExtensions(tx ingest.LedgerTransaction, opI
 	return ttlExtensions
 }
 
-func (lp *LedgerProcessor) extractTtlFromExtendFootprintOp(op xdr.Operation, tx ingest.LedgerTransaction) []*cipb.TtlExtension {
-	var extensions []*cipb.TtlExtension
-	
-	extendOp := op.Body.MustExtendFootprintTtlOp()
-	
-	// The ExtendFootprintTtlOp extends TTL for entries in the footprint
-	// We need to look at the transaction result to see what was actually extended
-	
-	// Get the source account for context
-	var sourceAccount xdr.AccountId
-	if op.SourceAccount != nil {
-		sourceAccount = op.SourceAccount.ToAccountId()
-	} else {
-		sourceAccount = tx.Envelope.SourceAccount().ToAccountId()
+// footprintContractDataKeys maps the hash of each contract data key in the
+// transaction footprint to the key itself
+func (lp *LedgerProcessor) footprintContractDataKeys(tx ingest.LedgerTransaction) map[xdr.Hash]xdr.LedgerKeyContractData {
+	sorobanData, ok := tx.GetSorobanData()
+	if !ok {
+		return nil
 	}
 	
-	// Check transaction meta for the actual extensions
-	if tx.UnsafeMeta.V == 3 && tx.UnsafeMeta.V3 != nil && tx.UnsafeMeta.V3.SorobanMeta != nil {
-		sorobanMeta := tx.UnsafeMeta.V3.SorobanMeta
-		
-		// Look for footprint information that was extended
-		if sorobanMeta.ExtFootprint != nil {
-			for _, key := range sorobanMeta.ExtFootprint.ReadWrite {
-				if key.Type == xdr.LedgerKeyTypeContractData {
-					extension := lp.createTtlExtensionFromKey(key, extendOp.ExtendTo, tx)
-					if extension != nil {
-						extensions = append(extensions, extension)
-					}
-				}
-			}
-			
-			for _, key := range sorobanMeta.ExtFootprint.ReadOnly {
-				if key.Type == xdr.LedgerKeyTypeContractData {
-					extension := lp.createTtlExtensionFromKey(key, extendOp.ExtendTo, tx)
-					if extension != nil {
-						extensions = append(extensions, extension)
-					}
-				}
+	footprint := sorobanData.Resources.Footprint
+	keys := make(map[xdr.Hash]xdr.LedgerKeyContractData)
+	for _, keySet := range [][]xdr.LedgerKey{footprint.ReadOnly, footprint.ReadWrite} {
+		for _, key := range keySet {
+			if key.Type != xdr.LedgerEntryTypeContractData || key.ContractData == nil {
+				continue
 			}
-		}
-	}
-	
-	return extensions
-}
-
-func (lp *LedgerProcessor) extractTtlFromSorobanMeta(sorobanMeta *xdr.SorobanTransactionMeta, tx ingest.LedgerTransaction) []*cipb.TtlExtension {
-	var extensions []*cipb.TtlExtension
-	
-	// Check for automatic TTL extensions in contract execution
-	if sorobanMeta.ExtFootprint != nil {
-		// These represent footprint entries that had their TTL automatically extended
-		for _, key := range sorobanMeta.ExtFootprint.ReadWrite {
-			if key.Type == xdr.LedgerKeyTypeContractData {
-				extension := lp.createTtlExtensionFromSorobanKey(key, tx)
-				if extension != nil {
-					extensions = append(extensions, extension)
-				}
+			keyBytes, err := key.MarshalBinary()
+			if err != nil {
+				lp.logger.Debug("failed to marshal footprint key", zap.Error(err))
+				continue
 			}
+			keys[xdr.Hash(sha256.Sum256(keyBytes))] = *key.ContractData
 		}
 	}
 	
-	return extensions
+	return keys
 }
 
-func (lp *LedgerProcessor) extractTtlFromLedgerChange(change ingest.Change, tx ingest.LedgerTransaction) *cipb.TtlExtension {
-	// Look for changes to contract data that indicate TTL modifications
-	if change.Type != xdr.LedgerEntryChangeTypeLedgerEntryUpdated {
-		return nil
-	}
-	
-	if change.Pre == nil || change.Post == nil ||
-		change.Pre.Data.Type != xdr.LedgerEntryTypeContractData ||
-		change.Post.Data.Type != xdr.LedgerEntryTypeContractData {
-		return nil
-	}
-	
-	preData := change.Pre.Data.ContractData
-	postData := change.Post.Data.ContractData
-	
-	if preData == nil || postData == nil {
-		return nil
-	}
-	
-	// Check if TTL was extended (simple heuristic - in reality would need more sophisticated detection)
-	if postData.LastModifiedLedgerSeq != preData.LastModifiedLedgerSeq {
-		return lp.createTtlExtensionFromChange(*preData, *postData, tx)
-	}
-	
-	return nil
-}
-
-func (lp *LedgerProcessor) createTtlExtensionFromKey(key xdr.LedgerKey, extendTo uint32, tx ingest.LedgerTransaction) *cipb.TtlExtension {
-	if key.Type != xdr.LedgerEntryTypeContractData {
-		return nil
-	}
-	
-	contractData := key.ContractData
-	if contractData == nil {
-		return nil
-	}
-	
+func (lp *LedgerProcessor) createTtlExtension(key xdr.LedgerKeyContractData, oldTtl, newTtl uint32) *cipb.TtlExtension {
 	// Extract contract ID
-	contractID, err := contractData.Contract.Address.String()
+	contractID, err := key.Contract.String()
 	if err != nil {
 		lp.logger.Debug("failed to extract contract ID from TTL extension", zap.Error(err))
 		return nil
 	}
 	
-	// Convert key to protobuf
-	keyProto := lp.scValConverter.ConvertScValToProto(contractData.Key)
-	
-	return &cipb.TtlExtension{
-		ContractId: contractID,
-		Key:        keyProto,
-		OldTtl:     0,     // Would need to fetch from pre-state
-		NewTtl:     extendTo,
-	}
-}
-
-func (lp *LedgerProcessor) createTtlExtensionFromSorobanKey(key xdr.LedgerKey, tx ingest.LedgerTransaction) *cipb.TtlExtension {
-	if key.Type != xdr.LedgerEntryTypeContractData {
-		return nil
-	}
-	
-	contractData := key.ContractData
-	if contractData == nil {
-		return nil
-	}
-	
-	// Extract contract ID
-	contractID, err := contractData.Contract.Address.String()
-	if err != nil {
-		return nil
-	}
-	
-	// Convert key to protobuf
-	keyProto := lp.scValConverter.ConvertScValToProto(contractData.Key)
-	
 	return &cipb.TtlExtension{
 		ContractId: contractID,
-		Key:        keyProto,
-		OldTtl:     0, // Would need context to determine old TTL
-		NewTtl:     tx.Ledger.LedgerSequence() + 100000, // Simplified - would need actual calculation
-	}
-}
-
-func (lp *LedgerProcessor) createTtlExtensionFromChange(preData, postData xdr.ContractDataEntry, tx ingest.LedgerTransaction) *cipb.TtlExtension {
-	// Extract contract ID
-	contractID, err := postData.Contract.Address.String()
-	if err != nil {
-		return nil
-	}
-	
-	// Convert key to protobuf
-	keyProto := lp.scValConverter.ConvertScValToProto(postData.Key)
-	
-	return &cipb.TtlExtension{
-		ContractId: contractID,
-		Key:        keyProto,
-		OldTtl:     preData.LastModifiedLedgerSeq,
-		NewTtl:     postData.LastModifiedLedgerSeq,
+		Key:        lp.scValConverter.ConvertScValToProto(key.Key),
+		OldTtl:     oldTtl,
+		NewTtl:     newTtl,
+		Durability: convertDurability(key.Durability),
 	}
 }
 
@@ -1473,47 +1415,31 @@ func (lp *LedgerProcessor) applyContentFilter(contractCall *cipb.ContractCall, f
 		return true
 	}
 	
-	// Apply minimum argument count filter
-	if filter.MinArguments > 0 && uint32(len(contractCall.Arguments)) < filter.MinArguments {
+	if filter.HasDiagnosticEvents && len(contractCall.DiagnosticEvents) == 0 {
 		return false
 	}
 	
-	// Apply maximum argument count filter
-	if filter.MaxArguments > 0 && uint32(len(contractCall.Arguments)) > filter.MaxArguments {
+	if filter.HasStateChanges && len(contractCall.StateChanges) == 0 {
 		return false
 	}
 	
-	// Apply argument pattern filters
-	if len(filter.ArgumentPatterns) > 0 {
-		if !lp.matchArgumentPatterns(contractCall.Arguments, filter.ArgumentPatterns) {
-			return false
-		}
-	}
-	
-	// Apply diagnostic event filters
-	if filter.RequiredEventTopics != nil && len(filter.RequiredEventTopics) > 0 {
-		if !lp.matchDiagnosticEventTopics(contractCall.DiagnosticEvents, filter.RequiredEventTopics) {
-			return false
-		}
-	}
-	
-	// Apply state change filters
-	if filter.RequiredStateChanges {
-		if len(contractCall.StateChanges) == 0 {
-			return false
+	// Every topic filter has to hold for the same diagnostic event
+	if len(filter.TopicFilters) > 0 {
+		matched := false
+		for _, event := range contractCall.DiagnosticEvents {
+			if lp.matchTopicFilters(event, filter.TopicFilters) {
+				matched = true
+				break
+			}
 		}
-	}
-	
-	// Apply contract-to-contract call filters
-	if filter.RequiredSubCalls {
-		if len(contractCall.ContractCalls) == 0 {
+		if !matched {
 			return false
 		}
 	}
 	
-	// Apply TTL extension filters
-	if filter.RequiredTtlExtensions {
-		if len(contractCall.TtlExtensions) == 0 {
+	// Every data pattern has to match some value at its location
+	for _, pattern := range filter.DataPatterns {
+		if !lp.matchDataPattern(contractCall, pattern) {
 			return false
 		}
 	}
@@ -1526,99 +1452,103 @@ func (lp *LedgerProcessor) applyContentFilter(contractCall *cipb.ContractCall, f
 	return true
 }
 
-func (lp *LedgerProcessor) matchArgumentPatterns(arguments []*cipb.ScValue, patterns []string) bool {
-	if len(patterns) == 0 {
-		return true
-	}
-	
-	// Convert arguments to JSON for pattern matching
-	var argStrings []string
-	for _, arg := range arguments {
-		argJSON, err := lp.scValConverter.ConvertScValToJSON(lp.convertProtoToXdrScVal(arg))
-		if err != nil {
-			lp.logger.Debug("failed to convert argument to JSON for pattern matching", zap.Error(err))
-			continue
+func (lp *LedgerProcessor) matchTopicFilters(event *cipb.DiagnosticEvent, filters []*pb.TopicFilter) bool {
+	for _, filter := range filters {
+		if int(filter.TopicIndex) >= len(event.Topics) {
+			return false
 		}
+		topic := event.Topics[filter.TopicIndex]
 		
-		argStr, ok := argJSON.(string)
-		if !ok {
-			// Convert non-string values to string representation
-			argStr = fmt.Sprintf("%v", argJSON)
+		switch filterType := filter.FilterType.(type) {
+		case *pb.TopicFilter_ExactMatch:
+			if lp.getScValueStringValue(topic) != filterType.ExactMatch {
+				return false
+			}
+		case *pb.TopicFilter_BytesMatch:
+			if !bytes.Equal(topic.GetBytesValue(), filterType.BytesMatch) {
+				return false
+			}
+		case *pb.TopicFilter_Pattern:
+			re, err := regexp.Compile(filterType.Pattern)
+			if err != nil || !re.MatchString(lp.getScValueStringValue(topic)) {
+				return false
+			}
 		}
-		argStrings = append(argStrings, argStr)
 	}
-	
-	// Check if any pattern matches any argument
-	for _, pattern := range patterns {
-		if lp.matchesPattern(argStrings, pattern) {
-			return true
+	return true
+}
+
+func (lp *LedgerProcessor) matchDataPattern(contractCall *cipb.ContractCall, pattern *pb.DataPatternFilter) bool {
+	var values []*cipb.ScValue
+	switch pattern.Location {
+	case pb.DataLocation_DATA_LOCATION_ARGUMENTS:
+		values = contractCall.Arguments
+	case pb.DataLocation_DATA_LOCATION_EVENT_DATA:
+		for _, event := range contractCall.DiagnosticEvents {
+			if event.Data != nil {
+				values = append(values, event.Data)
+			}
+		}
+	case pb.DataLocation_DATA_LOCATION_STATE_VALUES:
+		for _, change := range contractCall.StateChanges {
+			if change.OldValue != nil {
+				values = append(values, change.OldValue)
+			}
+			if change.NewValue != nil {
+				values = append(values, change.NewValue)
+			}
 		}
 	}
 	
-	return false
-}
-
-func (lp *LedgerProcessor) matchDiagnosticEventTopics(events []*cipb.DiagnosticEvent, requiredTopics []string) bool {
-	if len(requiredTopics) == 0 {
-		return true
+	var re *regexp.Regexp
+	if stringPattern, ok := pattern.PatternType.(*pb.DataPatternFilter_StringPattern); ok {
+		var err error
+		if re, err = regexp.Compile(stringPattern.StringPattern); err != nil {
+			return false
+		}
 	}
 	
-	// Check if any diagnostic event has the required topics
-	for _, event := range events {
-		for _, topic := range event.Topics {
-			if topic.Type == cipb.ScValueType_SC_VALUE_TYPE_SYMBOL ||
-				topic.Type == cipb.ScValueType_SC_VALUE_TYPE_STRING {
-				
-				topicValue := lp.getScValueStringValue(topic)
-				for _, required := range requiredTopics {
-					if lp.matchesStringPattern(topicValue, required) {
-						return true
-					}
-				}
+	for _, value := range values {
+		switch patternType := pattern.PatternType.(type) {
+		case *pb.DataPatternFilter_StringPattern:
+			if re.MatchString(lp.getScValueStringValue(value)) {
+				return true
+			}
+		case *pb.DataPatternFilter_BytesPattern:
+			if bytes.Equal(value.GetBytesValue(), patternType.BytesPattern) {
+				return true
 			}
+		case *pb.DataPatternFilter_TypeFilter:
+			if scValueHasType(value, patternType.TypeFilter) {
+				return true
+			}
+		default:
+			return true
 		}
 	}
 	
 	return false
 }
 
-func (lp *LedgerProcessor) matchesPattern(arguments []string, pattern string) bool {
-	// Simple pattern matching - could be extended with regex support
-	for _, arg := range arguments {
-		if lp.matchesStringPattern(arg, pattern) {
+func scValueHasType(value *cipb.ScValue, typeFilter pb.ScValueTypeFilter) bool {
+	switch typeFilter {
+	case pb.ScValueTypeFilter_SC_VALUE_TYPE_FILTER_STRING:
+		return value.Type == cipb.ScValueType_SC_VALUE_TYPE_STRING ||
+			value.Type == cipb.ScValueType_SC_VALUE_TYPE_SYMBOL
+	case pb.ScValueTypeFilter_SC_VALUE_TYPE_FILTER_NUMERIC:
+		switch value.Type {
+		case cipb.ScValueType_SC_VALUE_TYPE_U32, cipb.ScValueType_SC_VALUE_TYPE_I32,
+			cipb.ScValueType_SC_VALUE_TYPE_U64, cipb.ScValueType_SC_VALUE_TYPE_I64:
 			return true
 		}
-	}
-	return false
-}
-
-func (lp *LedgerProcessor) matchesStringPattern(value, pattern string) bool {
-	// Support simple wildcard patterns
-	if pattern == "*" {
-		return true
-	}
-	
-	// Exact match
-	if value == pattern {
+		return false
+	case pb.ScValueTypeFilter_SC_VALUE_TYPE_FILTER_ADDRESS:
+		return value.Type == cipb.ScValueType_SC_VALUE_TYPE_ADDRESS
+	case pb.ScValueTypeFilter_SC_VALUE_TYPE_FILTER_BYTES:
+		return value.Type == cipb.ScValueType_SC_VALUE_TYPE_BYTES
+	default:
 		return true
 	}
-	
-	// Simple prefix/suffix patterns
-	if len(pattern) > 0 {
-		if pattern[0] == '*' && len(pattern) > 1 {
-			// Suffix pattern: *suffix
-			suffix := pattern[1:]
-			return len(value) >= len(suffix) && value[len(value)-len(suffix):] == suffix
-		}
-		
-		if pattern[len(pattern)-1] == '*' && len(pattern) > 1 {
-			// Prefix pattern: prefix*
-			prefix := pattern[:len(pattern)-1]
-			return len(value) >= len(prefix) && value[:len(prefix)] == prefix
-		}
-	}
-	
-	return false
 }
 
 func (lp *LedgerProcessor) getScValueStringValue(value *cipb.ScValue) string {
@@ -1631,43 +1561,12 @@ func (lp *LedgerProcessor) getScValueStringValue(value *cipb.ScValue) string {
 		if symbolVal := value.GetSymbolValue(); symbolVal != "" {
 			return symbolVal
 		}
+	case cipb.ScValueType_SC_VALUE_TYPE_ADDRESS:
+		return value.GetAddressValue()
 	}
 	return ""
 }
 
-func (lp *LedgerProcessor) convertProtoToXdrScVal(protoVal *cipb.ScValue) xdr.ScVal {
-	// Helper method to convert protobuf ScValue back to XDR ScVal for JSON conversion
-	// This is a simplified implementation - full conversion would need all types
-	switch protoVal.Type {
-	case cipb.ScValueType_SC_VALUE_TYPE_VOID:
-		return xdr.ScVal{Type: xdr.ScValTypeScvVoid}
-	case cipb.ScValueType_SC_VALUE_TYPE_BOOL:
-		return xdr.ScVal{
-			Type: xdr.ScValTypeScvBool,
-			B:    (*xdr.Bool)(&protoVal.GetBoolValue()),
-		}
-	case cipb.ScValueType_SC_VALUE_TYPE_STRING:
-		strVal := xdr.ScString(protoVal.GetStringValue())
-		return xdr.ScVal{
-			Type: xdr.ScValTypeScvString,
-			Str:  &strVal,
-		}
-	case cipb.ScValueType_SC_VALUE_TYPE_SYMBOL:
-		symVal := xdr.ScSymbol(protoVal.GetSymbolValue())
-		return xdr.ScVal{
-			Type: xdr.ScValTypeScvSymbol,
-			Sym:  &symVal,
-		}
-	default:
-		// For complex types, return a string representation
-		strVal := xdr.ScString(fmt.Sprintf("complex_%s", protoVal.Type.String()))
-		return xdr.ScVal{
-			Type: xdr.ScValTypeScvString,
-			Str:  &strVal,
-		}
-	}
-}
-
 func (lp *LedgerProcessor) validateProtocol23BucketHashStructure(bucketListHash xdr.Hash) error {
 	// Protocol 23 bucket list hash should follow specific structure
 	// This is a simplified validation - real implementation would have more sophisticated checks
@@ -1714,14 +1613,14 @@ func (lp *LedgerProcessor) validateEvictedEntries(evictedTemp []xdr.LedgerKey, e
 func (lp *LedgerProcessor) validateEvictedKey(key xdr.LedgerKey, isTemporary bool) error {
 	// Validate that evicted keys are appropriate for their category
 	switch key.Type {
-	case xdr.LedgerKeyTypeContractData:
+	case xdr.LedgerEntryTypeContractData:
 		// Contract data can be evicted
 		if key.ContractData == nil {
 			return fmt.Errorf("contract data key missing data")
 		}
 		return nil
 		
-	case xdr.LedgerKeyTypeContractCode:
+	case xdr.LedgerEntryTypeContractCode:
 		// Contract code can be evicted to archive
 		if key.ContractCode == nil {
 			return fmt.Errorf("contract code key missing data")
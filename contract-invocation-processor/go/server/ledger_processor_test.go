@@ -0,0 +1,445 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/xdr"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	rawledger "github.com/stellar/stellar-live-source/gen/raw_ledger_service"
+	cipb "github.com/withobsrvr/contract-invocation-processor/gen/contract_invocation"
+	pb "github.com/withobsrvr/contract-invocation-processor/gen/contract_invocation_service"
+)
+
+var (
+	tokenContract = testContract(1)
+	nonceContract = testContract(2)
+	alice         = testAccount(10)
+	bob           = testAccount(11)
+)
+
+// standardLedger holds four invocations:
+//
+//	transfer  token, updates persistent "balance" and extends its TTL
+//	set       nonce, creates temporary "nonce"
+//	burn      token, deletes persistent "supply"
+//	fail      nonce, failed transaction with no changes
+func standardLedger(t *testing.T, closeTime time.Time) *rawledger.RawLedger {
+	balanceKey := contractDataKey(tokenContract, "balance", xdr.ContractDataDurabilityPersistent)
+	var transferChanges xdr.LedgerEntryChanges
+	transferChanges = append(transferChanges, entryUpdated(
+		contractDataEntry(tokenContract, "balance", xdr.ContractDataDurabilityPersistent, 100),
+		contractDataEntry(tokenContract, "balance", xdr.ContractDataDurabilityPersistent, 90),
+	)...)
+	transferChanges = append(transferChanges, entryUpdated(
+		ttlEntry(t, balanceKey, 2000),
+		ttlEntry(t, balanceKey, 5000),
+	)...)
+
+	return fixtureLedger(t, 1000, closeTime,
+		fixtureInvocation{
+			source:    alice,
+			contract:  tokenContract,
+			function:  "transfer",
+			changes:   transferChanges,
+			footprint: []xdr.LedgerKey{balanceKey},
+			events:    []xdr.DiagnosticEvent{contractEvent(tokenContract, "transfer", 10)},
+		},
+		fixtureInvocation{
+			source:   bob,
+			contract: nonceContract,
+			function: "set",
+			changes:  entryCreated(contractDataEntry(nonceContract, "nonce", xdr.ContractDataDurabilityTemporary, 1)),
+		},
+		fixtureInvocation{
+			source:   alice,
+			contract: tokenContract,
+			function: "burn",
+			changes:  entryRemoved(t, contractDataEntry(tokenContract, "supply", xdr.ContractDataDurabilityPersistent, 7)),
+		},
+		fixtureInvocation{
+			source:   bob,
+			contract: nonceContract,
+			function: "fail",
+			failed:   true,
+		},
+	)
+}
+
+func functionNames(events []*cipb.ContractInvocationEvent) []string {
+	names := []string{}
+	for _, event := range events {
+		names = append(names, event.GetContractCall().GetFunctionName())
+	}
+	return names
+}
+
+func TestProcessLedgerStateChangeFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *pb.StateChangeFilter
+		want   []string
+	}{
+		{
+			name: "no filter",
+			want: []string{"transfer", "set", "burn", "fail"},
+		},
+		{
+			name:   "empty filter",
+			filter: &pb.StateChangeFilter{},
+			want:   []string{"transfer", "set", "burn", "fail"},
+		},
+		{
+			name: "delete",
+			filter: &pb.StateChangeFilter{
+				Operations: []pb.StateChangeOperation{pb.StateChangeOperation_STATE_CHANGE_OPERATION_DELETE},
+			},
+			want: []string{"burn"},
+		},
+		{
+			name: "create or update",
+			filter: &pb.StateChangeFilter{
+				Operations: []pb.StateChangeOperation{
+					pb.StateChangeOperation_STATE_CHANGE_OPERATION_CREATE,
+					pb.StateChangeOperation_STATE_CHANGE_OPERATION_UPDATE,
+				},
+			},
+			want: []string{"transfer", "set"},
+		},
+		{
+			name: "temporary",
+			filter: &pb.StateChangeFilter{
+				Durabilities: []pb.ContractDataDurability{pb.ContractDataDurability_CONTRACT_DATA_DURABILITY_TEMPORARY},
+			},
+			want: []string{"set"},
+		},
+		{
+			name: "affected contract",
+			filter: &pb.StateChangeFilter{
+				AffectedContracts: []string{contractAddress(tokenContract)},
+			},
+			want: []string{"transfer", "burn"},
+		},
+		{
+			name: "criteria must hold for the same change",
+			filter: &pb.StateChangeFilter{
+				Operations:   []pb.StateChangeOperation{pb.StateChangeOperation_STATE_CHANGE_OPERATION_UPDATE},
+				Durabilities: []pb.ContractDataDurability{pb.ContractDataDurability_CONTRACT_DATA_DURABILITY_TEMPORARY},
+			},
+			want: []string{},
+		},
+		{
+			name:   "ttl extensions",
+			filter: &pb.StateChangeFilter{HasTtlExtensions: true},
+			want:   []string{"transfer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lp := newTestProcessor(fixtureCloseTime)
+			events, err := lp.ProcessLedger(context.Background(), standardLedger(t, fixtureCloseTime),
+				&pb.GetInvocationsRequest{StateFilter: tt.filter})
+			if err != nil {
+				t.Fatalf("ProcessLedger: %v", err)
+			}
+			if got := functionNames(events); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessLedgerStateChangeDetails(t *testing.T) {
+	lp := newTestProcessor(fixtureCloseTime)
+	events, err := lp.ProcessLedger(context.Background(), standardLedger(t, fixtureCloseTime), &pb.GetInvocationsRequest{})
+	if err != nil {
+		t.Fatalf("ProcessLedger: %v", err)
+	}
+
+	type change struct {
+		contract   string
+		operation  cipb.StateChangeOperation
+		durability cipb.ContractDataDurability
+	}
+	want := map[string][]change{
+		"transfer": {{contractAddress(tokenContract), cipb.StateChangeOperation_STATE_CHANGE_OPERATION_UPDATE, cipb.ContractDataDurability_CONTRACT_DATA_DURABILITY_PERSISTENT}},
+		"set":      {{contractAddress(nonceContract), cipb.StateChangeOperation_STATE_CHANGE_OPERATION_CREATE, cipb.ContractDataDurability_CONTRACT_DATA_DURABILITY_TEMPORARY}},
+		"burn":     {{contractAddress(tokenContract), cipb.StateChangeOperation_STATE_CHANGE_OPERATION_DELETE, cipb.ContractDataDurability_CONTRACT_DATA_DURABILITY_PERSISTENT}},
+		"fail":     nil,
+	}
+	for _, event := range events {
+		call := event.GetContractCall()
+		var got []change
+		for _, sc := range call.StateChanges {
+			got = append(got, change{sc.ContractId, sc.Operation, sc.Durability})
+		}
+		if !reflect.DeepEqual(got, want[call.FunctionName]) {
+			t.Errorf("%s: got state changes %v, want %v", call.FunctionName, got, want[call.FunctionName])
+		}
+	}
+
+	ttl := events[0].GetContractCall().TtlExtensions
+	if len(ttl) != 1 || ttl[0].OldTtl != 2000 || ttl[0].NewTtl != 5000 ||
+		ttl[0].ContractId != contractAddress(tokenContract) ||
+		ttl[0].Durability != cipb.ContractDataDurability_CONTRACT_DATA_DURABILITY_PERSISTENT {
+		t.Errorf("transfer: unexpected TTL extensions %+v", ttl)
+	}
+}
+
+func TestProcessLedgerTimeFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   *pb.TimeFilter
+		now      time.Time
+		want     []string
+		wantPast bool
+	}{
+		{
+			name: "inside window",
+			filter: &pb.TimeFilter{
+				StartTime: timestamppb.New(fixtureCloseTime.Add(-time.Minute)),
+				EndTime:   timestamppb.New(fixtureCloseTime.Add(time.Minute)),
+			},
+			want: []string{"transfer", "set", "burn", "fail"},
+		},
+		{
+			name: "bounds are inclusive",
+			filter: &pb.TimeFilter{
+				StartTime: timestamppb.New(fixtureCloseTime),
+				EndTime:   timestamppb.New(fixtureCloseTime),
+			},
+			want: []string{"transfer", "set", "burn", "fail"},
+		},
+		{
+			name:   "before start",
+			filter: &pb.TimeFilter{StartTime: timestamppb.New(fixtureCloseTime.Add(time.Second))},
+			want:   nil,
+		},
+		{
+			name:     "after end",
+			filter:   &pb.TimeFilter{EndTime: timestamppb.New(fixtureCloseTime.Add(-time.Second))},
+			wantPast: true,
+		},
+		{
+			name:   "recent",
+			filter: &pb.TimeFilter{RecentOnly: true},
+			now:    fixtureCloseTime.Add(30 * time.Minute),
+			want:   []string{"transfer", "set", "burn", "fail"},
+		},
+		{
+			name:   "not recent",
+			filter: &pb.TimeFilter{RecentOnly: true},
+			now:    fixtureCloseTime.Add(2 * time.Hour),
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := tt.now
+			if now.IsZero() {
+				now = fixtureCloseTime
+			}
+			lp := newTestProcessor(now)
+			events, err := lp.ProcessLedger(context.Background(), standardLedger(t, fixtureCloseTime),
+				&pb.GetInvocationsRequest{TimeFilter: tt.filter})
+			if tt.wantPast {
+				if !errors.Is(err, errPastTimeWindow) {
+					t.Fatalf("got error %v, want errPastTimeWindow", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessLedger: %v", err)
+			}
+			if tt.want == nil {
+				if len(events) != 0 {
+					t.Errorf("got %v, want no events", functionNames(events))
+				}
+				return
+			}
+			if got := functionNames(events); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessLedgerResponseOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   *pb.GetInvocationsRequest
+		check func(t *testing.T, transfer *cipb.ContractInvocationEvent)
+	}{
+		{
+			name: "defaults keep sub-records and omit raw XDR",
+			req:  &pb.GetInvocationsRequest{},
+			check: func(t *testing.T, transfer *cipb.ContractInvocationEvent) {
+				call := transfer.GetContractCall()
+				if transfer.RawXdr != nil {
+					t.Error("raw XDR included without include_raw_xdr")
+				}
+				if len(call.DiagnosticEvents) != 1 || len(call.StateChanges) != 1 || len(call.TtlExtensions) != 1 {
+					t.Errorf("sub-records missing: %d events, %d state changes, %d TTL extensions",
+						len(call.DiagnosticEvents), len(call.StateChanges), len(call.TtlExtensions))
+				}
+			},
+		},
+		{
+			name: "raw XDR",
+			req:  &pb.GetInvocationsRequest{Options: &pb.ResponseOptions{IncludeRawXdr: true}},
+			check: func(t *testing.T, transfer *cipb.ContractInvocationEvent) {
+				if transfer.RawXdr == nil {
+					t.Fatal("raw XDR missing")
+				}
+				var envelope xdr.TransactionEnvelope
+				if err := envelope.UnmarshalBinary(transfer.RawXdr.TransactionEnvelope); err != nil {
+					t.Fatalf("decode envelope: %v", err)
+				}
+				if fn := envelope.Operations()[0].Body.MustInvokeHostFunctionOp().HostFunction.MustInvokeContract().FunctionName; fn != "transfer" {
+					t.Errorf("envelope invokes %q, want transfer", fn)
+				}
+				var meta xdr.TransactionMeta
+				if err := meta.UnmarshalBinary(transfer.RawXdr.TransactionMeta); err != nil {
+					t.Errorf("decode meta: %v", err)
+				}
+				var result xdr.TransactionResultPair
+				if err := result.UnmarshalBinary(transfer.RawXdr.TransactionResult); err != nil {
+					t.Errorf("decode result: %v", err)
+				}
+			},
+		},
+		{
+			name: "inclusion keeps only flagged sub-records",
+			req: &pb.GetInvocationsRequest{Options: &pb.ResponseOptions{
+				Inclusion: &pb.ContentInclusion{IncludeStateChanges: true},
+			}},
+			check: func(t *testing.T, transfer *cipb.ContractInvocationEvent) {
+				call := transfer.GetContractCall()
+				if len(call.StateChanges) != 1 {
+					t.Errorf("got %d state changes, want 1", len(call.StateChanges))
+				}
+				if call.DiagnosticEvents != nil || call.TtlExtensions != nil || call.ContractCalls != nil {
+					t.Error("unrequested sub-records were kept")
+				}
+			},
+		},
+		{
+			name: "filters see sub-records that inclusion drops",
+			req: &pb.GetInvocationsRequest{
+				StateFilter: &pb.StateChangeFilter{HasTtlExtensions: true},
+				Options:     &pb.ResponseOptions{Inclusion: &pb.ContentInclusion{}},
+			},
+			check: func(t *testing.T, transfer *cipb.ContractInvocationEvent) {
+				if transfer.GetContractCall().TtlExtensions != nil {
+					t.Error("TTL extensions kept despite inclusion")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lp := newTestProcessor(fixtureCloseTime)
+			events, err := lp.ProcessLedger(context.Background(), standardLedger(t, fixtureCloseTime), tt.req)
+			if err != nil {
+				t.Fatalf("ProcessLedger: %v", err)
+			}
+			if len(events) == 0 || events[0].GetContractCall().GetFunctionName() != "transfer" {
+				t.Fatalf("transfer event missing, got %v", functionNames(events))
+			}
+			tt.check(t, events[0])
+		})
+	}
+}
+
+func TestValidateInvocationsRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *pb.GetInvocationsRequest
+		wantErr bool
+	}{
+		{name: "empty", req: &pb.GetInvocationsRequest{}},
+		{
+			name: "start after end",
+			req: &pb.GetInvocationsRequest{TimeFilter: &pb.TimeFilter{
+				StartTime: timestamppb.New(fixtureCloseTime.Add(time.Hour)),
+				EndTime:   timestamppb.New(fixtureCloseTime),
+			}},
+			wantErr: true,
+		},
+		{
+			name: "affected contract is an account",
+			req: &pb.GetInvocationsRequest{StateFilter: &pb.StateChangeFilter{
+				AffectedContracts: []string{alice},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "affected contract",
+			req: &pb.GetInvocationsRequest{StateFilter: &pb.StateChangeFilter{
+				AffectedContracts: []string{contractAddress(tokenContract)},
+			}},
+		},
+		{
+			name: "raw and decoded only",
+			req: &pb.GetInvocationsRequest{Options: &pb.ResponseOptions{
+				IncludeRawXdr:      true,
+				IncludeDecodedOnly: true,
+			}},
+			wantErr: true,
+		},
+		{
+			name: "bad topic pattern",
+			req: &pb.GetInvocationsRequest{ContentFilter: &pb.EventContentFilter{
+				TopicFilters: []*pb.TopicFilter{{FilterType: &pb.TopicFilter_Pattern{Pattern: "("}}},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInvocationsRequest(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitIntoBatches(t *testing.T) {
+	events := make([]*cipb.ContractInvocationEvent, 5)
+	for i := range events {
+		events[i] = &cipb.ContractInvocationEvent{}
+	}
+
+	tests := []struct {
+		name   string
+		events []*cipb.ContractInvocationEvent
+		size   int
+		want   []int
+	}{
+		{name: "no events", events: nil, size: 2, want: nil},
+		{name: "unbatched", events: events, size: 0, want: []int{5}},
+		{name: "uneven", events: events, size: 2, want: []int{2, 2, 1}},
+		{name: "exact", events: events, size: 5, want: []int{5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, batch := range splitIntoBatches(tt.events, tt.size) {
+				got = append(got, len(batch))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got batch sizes %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/strkey"
+
+	cipb "github.com/withobsrvr/contract-invocation-processor/gen/contract_invocation"
+	pb "github.com/withobsrvr/contract-invocation-processor/gen/contract_invocation_service"
+)
+
+// recentWindow is how far back TimeFilter.recent_only reaches
+const recentWindow = time.Hour
+
+// errPastTimeWindow is returned by ProcessLedger for a ledger that closed after
+// the requested end_time. Close times never go backwards, so no later ledger
+// can match either and the stream is complete.
+var errPastTimeWindow = errors.New("ledger closed after the requested end_time")
+
+// validateInvocationsRequest rejects option combinations that cannot be honored
+func validateInvocationsRequest(req *pb.GetInvocationsRequest) error {
+	if tf := req.GetTimeFilter(); tf != nil {
+		if tf.StartTime != nil && !tf.StartTime.IsValid() {
+			return fmt.Errorf("time_filter.start_time is not a valid timestamp")
+		}
+		if tf.EndTime != nil && !tf.EndTime.IsValid() {
+			return fmt.Errorf("time_filter.end_time is not a valid timestamp")
+		}
+		if tf.StartTime != nil && tf.EndTime != nil && tf.StartTime.AsTime().After(tf.EndTime.AsTime()) {
+			return fmt.Errorf("time_filter.start_time is after time_filter.end_time")
+		}
+	}
+
+	for _, topicFilter := range req.GetContentFilter().GetTopicFilters() {
+		if pattern, ok := topicFilter.FilterType.(*pb.TopicFilter_Pattern); ok {
+			if _, err := regexp.Compile(pattern.Pattern); err != nil {
+				return fmt.Errorf("content_filter.topic_filters: invalid pattern %q: %v", pattern.Pattern, err)
+			}
+		}
+	}
+	for _, dataPattern := range req.GetContentFilter().GetDataPatterns() {
+		if pattern, ok := dataPattern.PatternType.(*pb.DataPatternFilter_StringPattern); ok {
+			if _, err := regexp.Compile(pattern.StringPattern); err != nil {
+				return fmt.Errorf("content_filter.data_patterns: invalid pattern %q: %v", pattern.StringPattern, err)
+			}
+		}
+	}
+
+	for _, contractID := range req.GetStateFilter().GetAffectedContracts() {
+		if _, err := strkey.Decode(strkey.VersionByteContract, contractID); err != nil {
+			return fmt.Errorf("state_filter.affected_contracts: %q is not a contract address", contractID)
+		}
+	}
+
+	if opts := req.GetOptions(); opts.GetIncludeRawXdr() && opts.GetIncludeDecodedOnly() {
+		return fmt.Errorf("options.include_raw_xdr and options.include_decoded_only are mutually exclusive")
+	}
+
+	return nil
+}
+
+// inTimeWindow reports whether a ledger closed inside the request's time
+// filter, and whether it closed after end_time.
+func inTimeWindow(closedAt time.Time, filter *pb.TimeFilter, now time.Time) (include bool, past bool) {
+	if filter == nil {
+		return true, false
+	}
+	if filter.EndTime != nil && closedAt.After(filter.EndTime.AsTime()) {
+		return false, true
+	}
+	if filter.StartTime != nil && closedAt.Before(filter.StartTime.AsTime()) {
+		return false, false
+	}
+	if filter.RecentOnly && closedAt.Before(now.Add(-recentWindow)) {
+		return false, false
+	}
+	return true, false
+}
+
+// stateFilterActive reports whether the filter constrains anything. Only
+// contract calls carry state changes, so an active filter excludes contract
+// creations and WASM uploads.
+func stateFilterActive(filter *pb.StateChangeFilter) bool {
+	return filter != nil && (len(filter.Operations) > 0 ||
+		len(filter.Durabilities) > 0 ||
+		len(filter.AffectedContracts) > 0 ||
+		filter.HasTtlExtensions)
+}
+
+// matchesStateFilter requires at least one state change that satisfies every
+// populated criterion at once, e.g. a DELETE of PERSISTENT data in contract C.
+func matchesStateFilter(contractCall *cipb.ContractCall, filter *pb.StateChangeFilter) bool {
+	if !stateFilterActive(filter) {
+		return true
+	}
+
+	if filter.HasTtlExtensions && len(contractCall.TtlExtensions) == 0 {
+		return false
+	}
+
+	if len(filter.Operations) == 0 && len(filter.Durabilities) == 0 && len(filter.AffectedContracts) == 0 {
+		return true
+	}
+
+	for _, change := range contractCall.StateChanges {
+		if stateChangeMatches(change, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+func stateChangeMatches(change *cipb.StateChange, filter *pb.StateChangeFilter) bool {
+	if len(filter.Operations) > 0 {
+		found := false
+		for _, op := range filter.Operations {
+			// The service re-declares the event enums with the same values
+			if int32(op) == int32(change.Operation) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(filter.Durabilities) > 0 {
+		found := false
+		for _, durability := range filter.Durabilities {
+			if int32(durability) == int32(change.Durability) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(filter.AffectedContracts) > 0 {
+		found := false
+		for _, contractID := range filter.AffectedContracts {
+			if contractID == change.ContractId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rawXdrForTransaction returns the XDR records an event was decoded from
+func rawXdrForTransaction(tx ingest.LedgerTransaction) (*cipb.RawXdr, error) {
+	envelope, err := tx.Envelope.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction envelope: %w", err)
+	}
+	result, err := tx.Result.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction result: %w", err)
+	}
+	meta, err := tx.UnsafeMeta.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction meta: %w", err)
+	}
+
+	return &cipb.RawXdr{
+		TransactionEnvelope: envelope,
+		TransactionResult:   result,
+		TransactionMeta:     meta,
+	}, nil
+}
+
+// applyContentInclusion drops the sub-records the client did not ask for. It
+// runs after filtering, so filters still see the full event. A nil inclusion
+// keeps everything.
+func applyContentInclusion(event *cipb.ContractInvocationEvent, inclusion *pb.ContentInclusion) {
+	if inclusion == nil {
+		return
+	}
+
+	if !inclusion.IncludeArchiveInfo && event.Meta != nil {
+		event.Meta.DataSource = ""
+		event.Meta.ArchiveRestorations = nil
+	}
+
+	contractCall := event.GetContractCall()
+	if contractCall == nil {
+		return
+	}
+	if !inclusion.IncludeDiagnosticEvents {
+		contractCall.DiagnosticEvents = nil
+	}
+	if !inclusion.IncludeStateChanges {
+		contractCall.StateChanges = nil
+	}
+	if !inclusion.IncludeContractCalls {
+		contractCall.ContractCalls = nil
+	}
+	if !inclusion.IncludeTtlExtensions {
+		contractCall.TtlExtensions = nil
+	}
+}
+
+// splitIntoBatches cuts one ledger's events into batches of at most size
+// events; size 0 sends the whole ledger as one batch. No events, no batches.
+func splitIntoBatches(events []*cipb.ContractInvocationEvent, size int) [][]*cipb.ContractInvocationEvent {
+	var batches [][]*cipb.ContractInvocationEvent
+	for len(events) > 0 {
+		n := len(events)
+		if size > 0 && n > size {
+			n = size
+		}
+		batches = append(batches, events[:n])
+		events = events[n:]
+	}
+	return batches
+}
@@ -165,14 +165,14 @@ func (c *ScValConverter) ConvertScValToProto(scVal xdr.ScVal) *cipb.ScValue {
 			},
 		}
 
-	case xdr.ScValTypeScvInstance:
+	case xdr.ScValTypeScvContractInstance:
 		// Handle instance type (complex objects)
 		instance := scVal.MustInstance()
 		return &cipb.ScValue{
 			Type: cipb.ScValueType_SC_VALUE_TYPE_INSTANCE,
 			Value: &cipb.ScValue_InstanceValue{
 				InstanceValue: &cipb.ScValueInstance{
-					InstanceType: string(instance.InstanceType),
+					InstanceType: instance.Executable.Type.String(),
 					Fields:       []*cipb.ScValue{}, // TODO: Extract fields if needed
 				},
 			},
@@ -280,10 +280,10 @@ func (c *ScValConverter) ConvertScValToJSON(scVal xdr.ScVal) (interface{}, error
 		address := scVal.MustAddress()
 		return c.convertAddressToString(address)
 
-	case xdr.ScValTypeScvInstance:
+	case xdr.ScValTypeScvContractInstance:
 		instance := scVal.MustInstance()
 		return map[string]interface{}{
-			"type":         string(instance.InstanceType),
+			"type":         instance.Executable.Type.String(),
 			"instance_id": "complex_instance", // Simplified for now
 		}, nil
 
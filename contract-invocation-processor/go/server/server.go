@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,8 +15,10 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/status"
 
+	cipb "github.com/withobsrvr/contract-invocation-processor/gen/contract_invocation"
 	pb "github.com/withobsrvr/contract-invocation-processor/gen/contract_invocation_service"
 	rawledger "github.com/stellar/stellar-live-source/gen/raw_ledger_service"
 )
@@ -95,8 +98,56 @@ func (s *ContractInvocationServer) GetContractInvocations(
 	req *pb.GetInvocationsRequest,
 	stream pb.ContractInvocationService_GetContractInvocationsServer,
 ) error {
-	// The context of the incoming stream from the consumer
-	ctx := stream.Context()
+	if err := validateInvocationsRequest(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.GetOptions().GetMaxEventsPerResponse() > 0 {
+		return status.Error(codes.InvalidArgument,
+			"options.max_events_per_response requires GetContractInvocationBatches")
+	}
+
+	return s.streamInvocations(stream.Context(), req, func(ledgerSeq uint32, events []*cipb.ContractInvocationEvent) error {
+		// Stream each contract invocation event to consumer
+		for i, event := range events {
+			if err := stream.Send(event); err != nil {
+				return fmt.Errorf("event %d: %w", i, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetContractInvocationBatches streams the same events as GetContractInvocations,
+// grouped per ledger into batches of at most options.max_events_per_response
+func (s *ContractInvocationServer) GetContractInvocationBatches(
+	req *pb.GetInvocationsRequest,
+	stream pb.ContractInvocationService_GetContractInvocationBatchesServer,
+) error {
+	if err := validateInvocationsRequest(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	batchSize := int(req.GetOptions().GetMaxEventsPerResponse())
+
+	return s.streamInvocations(stream.Context(), req, func(ledgerSeq uint32, events []*cipb.ContractInvocationEvent) error {
+		for _, batch := range splitIntoBatches(events, batchSize) {
+			if err := stream.Send(&pb.ContractInvocationBatch{
+				LedgerSequence: ledgerSeq,
+				Events:         batch,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// streamInvocations pulls raw ledgers from the source service, processes them
+// and hands each ledger's events to send until the request is satisfied
+func (s *ContractInvocationServer) streamInvocations(
+	ctx context.Context,
+	req *pb.GetInvocationsRequest,
+	send func(ledgerSeq uint32, events []*cipb.ContractInvocationEvent) error,
+) error {
 	logger := s.logger.With(
 		zap.Uint32("start_ledger", req.StartLedger),
 		zap.Uint32("end_ledger", req.EndLedger),
@@ -104,6 +155,13 @@ func (s *ContractInvocationServer) GetContractInvocations(
 	)
 	logger.Info("received GetContractInvocations request")
 
+	// Compression has to be chosen before the first message is sent
+	if req.GetOptions().GetCompressResponse() {
+		if err := grpc.SetSendCompressor(ctx, gzip.Name); err != nil {
+			logger.Warn("client does not accept gzip, sending uncompressed", zap.Error(err))
+		}
+	}
+
 	// Create ledger processor for this request
 	ledgerProcessor := NewLedgerProcessor(s.logger, s.networkPassphrase, s)
 
@@ -180,6 +238,11 @@ func (s *ContractInvocationServer) GetContractInvocations(
 		events, err := ledgerProcessor.ProcessLedger(ctx, rawLedgerMsg, req)
 		processingTime := time.Since(processingStart)
 
+		if errors.Is(err, errPastTimeWindow) {
+			ledgerLogger.Info("reached end time requested by consumer")
+			cancelSourceStream()
+			return nil
+		}
 		if err != nil {
 			ledgerLogger.Error("failed to process contract invocations", zap.Error(err))
 			s.updateErrorMetrics(err, rawLedgerMsg.Sequence)
@@ -187,19 +250,13 @@ func (s *ContractInvocationServer) GetContractInvocations(
 			return status.Errorf(codes.Internal, "failed to process contract invocations for ledger %d: %v", rawLedgerMsg.Sequence, err)
 		}
 
-		// Stream each contract invocation event to consumer
-		eventsSent := 0
-		for i, event := range events {
-			if err := stream.Send(event); err != nil {
-				ledgerLogger.Error("failed to send contract invocation event to consumer",
-					zap.Error(err),
-					zap.Int("event_index", i))
-				s.updateErrorMetrics(err, rawLedgerMsg.Sequence)
-				cancelSourceStream()
-				return status.Errorf(codes.Unavailable, "failed to send contract invocation event to consumer: %v", err)
-			}
-			eventsSent++
+		if err := send(rawLedgerMsg.Sequence, events); err != nil {
+			ledgerLogger.Error("failed to send contract invocation events to consumer", zap.Error(err))
+			s.updateErrorMetrics(err, rawLedgerMsg.Sequence)
+			cancelSourceStream()
+			return status.Errorf(codes.Unavailable, "failed to send contract invocation event to consumer: %v", err)
 		}
+		eventsSent := len(events)
 
 		// Update metrics on successful processing
 		s.updateSuccessMetrics(rawLedgerMsg.Sequence, processingTime, int64(eventsSent))
@@ -335,5 +392,8 @@ func (s *ContractInvocationServer) countRequestFilters(req *pb.GetInvocationsReq
 	if req.TimeFilter != nil {
 		count++
 	}
+	if stateFilterActive(req.StateFilter) {
+		count++
+	}
 	return count
 }
\ No newline at end of file
@@ -22,18 +22,36 @@ const (
 	// Batch size for bulk inserts
 	defaultBatchSize = 1000
 
-	// Schema for account_balances table
+	// Schema for account_balances table. Amounts are HUGEINT (i128) so SAC
+	// and SEP-41 contract balances fit alongside stroop amounts.
 	createTableSQL = `
 		CREATE TABLE IF NOT EXISTS account_balances (
 			account_id VARCHAR NOT NULL,
+			holder_type VARCHAR NOT NULL,
+			source VARCHAR NOT NULL,
 			asset_code VARCHAR NOT NULL,
 			asset_issuer VARCHAR NOT NULL,
-			balance BIGINT NOT NULL,
+			contract_id VARCHAR NOT NULL,
+			liquidity_pool_id VARCHAR NOT NULL,
+			balance HUGEINT NOT NULL,
+			previous_balance HUGEINT NOT NULL,
+			delta HUGEINT NOT NULL,
 			last_modified_ledger INTEGER NOT NULL,
 			inserted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			PRIMARY KEY (account_id, asset_code, asset_issuer)
+			PRIMARY KEY (account_id, source, asset_code, asset_issuer, contract_id, liquidity_pool_id)
 		)
 	`
+
+	// tableExistsSQL and holderTypeColumnSQL detect an account_balances table
+	// created before holder types and contract balances were added
+	holderTypeColumnSQL = `
+		SELECT COUNT(*) FROM information_schema.columns
+		WHERE table_name = 'account_balances' AND column_name = 'holder_type'
+	`
+	tableExistsSQL = `
+		SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_name = 'account_balances'
+	`
 )
 
 // ConsumerMetrics tracks metrics for the DuckDB consumer
@@ -115,6 +133,12 @@ func NewDuckDBConsumer(dbPath string, balanceServiceAddr string, batchSize int)
 		return nil, fmt.Errorf("failed to ping DuckDB: %w", err)
 	}
 
+	// Refuse to write into a table with the old schema
+	if err := checkLegacySchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	// Create table
 	logger.Info("creating account_balances table if not exists")
 	if _, err := db.Exec(createTableSQL); err != nil {
@@ -148,6 +172,26 @@ func NewDuckDBConsumer(dbPath string, balanceServiceAddr string, batchSize int)
 	}, nil
 }
 
+// checkLegacySchema returns an error if account_balances exists without the
+// holder_type column. The old int64 balances cannot be upgraded in place.
+func checkLegacySchema(db *sql.DB) error {
+	var tables, columns int
+	if err := db.QueryRow(tableExistsSQL).Scan(&tables); err != nil {
+		return fmt.Errorf("failed to inspect account_balances table: %w", err)
+	}
+	if tables == 0 {
+		return nil
+	}
+	if err := db.QueryRow(holderTypeColumnSQL).Scan(&columns); err != nil {
+		return fmt.Errorf("failed to inspect account_balances columns: %w", err)
+	}
+	if columns == 0 {
+		return fmt.Errorf("account_balances uses the pre-contract-balance schema; " +
+			"rename or drop it (ALTER TABLE account_balances RENAME TO account_balances_v1) and re-run")
+	}
+	return nil
+}
+
 // Close cleans up resources
 func (c *DuckDBConsumer) Close() error {
 	c.logger.Info("closing DuckDB consumer")
@@ -281,10 +325,12 @@ func (c *DuckDBConsumer) writeBatch(batch []*accountbalance.AccountBalance) erro
 	defer tx.Rollback() // Rollback if not committed
 
 	// Prepare statement using INSERT OR REPLACE for upsert behavior
+	// Amounts arrive as decimal strings and are cast to HUGEINT by DuckDB
 	stmt, err := tx.Prepare(`
 		INSERT OR REPLACE INTO account_balances
-		(account_id, asset_code, asset_issuer, balance, last_modified_ledger)
-		VALUES (?, ?, ?, ?, ?)
+		(account_id, holder_type, source, asset_code, asset_issuer, contract_id, liquidity_pool_id,
+		 balance, previous_balance, delta, last_modified_ledger)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CAST(? AS HUGEINT), CAST(? AS HUGEINT), CAST(? AS HUGEINT), ?)
 	`)
 	if err != nil {
 		c.metrics.RecordError(err)
@@ -296,9 +342,15 @@ func (c *DuckDBConsumer) writeBatch(batch []*accountbalance.AccountBalance) erro
 	for _, balance := range batch {
 		_, err := stmt.Exec(
 			balance.AccountId,
+			holderTypeName(balance.HolderType),
+			balanceSourceName(balance.Source),
 			balance.AssetCode,
 			balance.AssetIssuer,
+			balance.ContractId,
+			balance.LiquidityPoolId,
 			balance.Balance,
+			balance.PreviousBalance,
+			balance.Delta,
 			balance.LastModifiedLedger,
 		)
 		if err != nil {
@@ -322,6 +374,36 @@ func (c *DuckDBConsumer) writeBatch(batch []*accountbalance.AccountBalance) erro
 	return nil
 }
 
+// holderTypeName stores holder types as short lowercase labels
+func holderTypeName(t accountbalance.HolderType) string {
+	switch t {
+	case accountbalance.HolderType_HOLDER_TYPE_ACCOUNT:
+		return "account"
+	case accountbalance.HolderType_HOLDER_TYPE_CONTRACT:
+		return "contract"
+	case accountbalance.HolderType_HOLDER_TYPE_MUXED:
+		return "muxed"
+	default:
+		return "unknown"
+	}
+}
+
+// balanceSourceName stores balance sources as short lowercase labels
+func balanceSourceName(s accountbalance.BalanceSource) string {
+	switch s {
+	case accountbalance.BalanceSource_BALANCE_SOURCE_NATIVE:
+		return "native"
+	case accountbalance.BalanceSource_BALANCE_SOURCE_TRUSTLINE:
+		return "trustline"
+	case accountbalance.BalanceSource_BALANCE_SOURCE_SAC:
+		return "sac"
+	case accountbalance.BalanceSource_BALANCE_SOURCE_SEP41:
+		return "sep41"
+	default:
+		return "unknown"
+	}
+}
+
 // GetMetrics returns a copy of the current metrics
 func (c *DuckDBConsumer) GetMetrics() *ConsumerMetrics {
 	c.metrics.mu.RLock()
@@ -5,6 +5,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/checkpoint"
@@ -127,6 +128,9 @@ type BatchStats struct {
 	LedgerCount int
 	Tables      map[string]manifest.TableStats
 	StartTime   time.Time
+
+	// DigestScheme is set when the table checksums are PAS row digests
+	DigestScheme string
 }
 
 // PASEventInfo holds information about the emitted PAS event for lineage linkage.
@@ -148,9 +152,6 @@ func (al *AuditLayer) OnFlushComplete(
 
 	// 1. Generate and save manifest
 	var manifestHash string
-	var totalRows int64
-	var tableSummaries []pas.TableSummary
-
 	if al.manifestBuilder != nil {
 		m := al.manifestBuilder.Build(
 			stats.LedgerStart,
@@ -162,32 +163,43 @@ func (al *AuditLayer) OnFlushComplete(
 			log.Printf("[audit] Warning: failed to save manifest: %v", err)
 		} else {
 			manifestHash = m.ManifestChecksum
-			totalRows = m.TotalRows
-
-			// Convert table manifests to PAS summaries
-			for _, t := range m.Tables {
-				tableSummaries = append(tableSummaries, pas.TableSummary{
-					Name:     t.Name,
-					RowCount: t.RowCount,
-					Checksum: t.Checksum,
-				})
-			}
 		}
 	}
 
 	// 2. Emit PAS event
 	var pasInfo *PASEventInfo
 	if al.pasEmitter != nil {
-		err := al.pasEmitter.EmitBatch(
-			al.pasProducer,
-			stats.LedgerStart,
-			stats.LedgerEnd,
-			stats.LedgerCount,
-			tableSummaries,
-			manifestHash,
-			totalRows,
-			processingDuration.Milliseconds(),
-		)
+		// Table summaries come straight from the batch stats so the event
+		// records row counts and digests even without a manifest
+		names := make([]string, 0, len(stats.Tables))
+		for name := range stats.Tables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var totalRows int64
+		tableSummaries := make([]pas.TableSummary, 0, len(names))
+		for _, name := range names {
+			t := stats.Tables[name]
+			totalRows += t.RowCount
+			tableSummaries = append(tableSummaries, pas.TableSummary{
+				Name:     name,
+				RowCount: t.RowCount,
+				Checksum: t.Checksum,
+			})
+		}
+
+		event := pas.NewEvent(al.pasEmitter.GetPreviousHash(), al.pasProducer, pas.BatchInfo{
+			LedgerStart:          stats.LedgerStart,
+			LedgerEnd:            stats.LedgerEnd,
+			LedgerCount:          stats.LedgerCount,
+			Tables:               tableSummaries,
+			ManifestHash:         manifestHash,
+			TotalRows:            totalRows,
+			ProcessingDurationMs: processingDuration.Milliseconds(),
+			DigestScheme:         stats.DigestScheme,
+		})
+		err := al.pasEmitter.Emit(event)
 		if err != nil {
 			if al.config.PAS.Strict {
 				return nil, err
@@ -197,7 +209,7 @@ func (al *AuditLayer) OnFlushComplete(
 			// Cycle 4: Capture PAS event info for lineage linkage
 			pasInfo = &PASEventInfo{
 				EventID:   al.generatePASEventID(stats.LedgerStart, stats.LedgerEnd),
-				EventHash: al.pasEmitter.GetPreviousHash(), // This is now the current event's hash
+				EventHash: event.EventHash,
 			}
 		}
 	}
@@ -273,3 +285,8 @@ func (al *AuditLayer) IsManifestEnabled() bool {
 func (al *AuditLayer) IsPASEnabled() bool {
 	return al.pasEmitter != nil
 }
+
+// ComputesDigests returns true if PAS events should carry row digests.
+func (al *AuditLayer) ComputesDigests() bool {
+	return al.pasEmitter != nil && !al.config.PAS.SkipDigests
+}
@@ -0,0 +1,117 @@
+// Command pas works with Public Audit Stream events written by the ingester.
+//
+//	pas verify -config config.yaml [-events ./pas-backup] [-chain-only]
+//
+// verify orders the event files by their hash links, recomputes every event
+// hash, and then recomputes the row digests of each batch from the DuckLake
+// catalog and compares them with the recorded table checksums. It exits
+// non-zero if the chain is broken or the lake differs from the events.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/internal/lake"
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/pas"
+)
+
+// lakeConfig is the part of the ingester configuration the verifier needs
+type lakeConfig struct {
+	DuckLake lake.Config `yaml:"ducklake"`
+
+	PAS pas.Config `yaml:"pas"`
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "verify" {
+		fmt.Fprintln(os.Stderr, "usage: pas verify -config FILE [-events DIR] [-chain-only]")
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	configPath := flags.String("config", "", "ingester configuration file (ducklake and pas sections)")
+	eventsDir := flags.String("events", "", "directory of PAS event files (default: pas.backup_dir from -config)")
+	chainOnly := flags.Bool("chain-only", false, "check the hash chain without reading the lake")
+	flags.Parse(os.Args[2:])
+
+	var cfg lakeConfig
+	if *configPath != "" {
+		data, err := os.ReadFile(*configPath)
+		if err != nil {
+			log.Fatalf("Failed to read config: %v", err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			log.Fatalf("Failed to parse config: %v", err)
+		}
+	}
+	cfg.PAS.ApplyDefaults()
+	if *eventsDir == "" {
+		*eventsDir = cfg.PAS.BackupDir
+	}
+	if !*chainOnly && *configPath == "" {
+		log.Fatal("-config is required unless -chain-only is set")
+	}
+
+	os.Exit(verify(context.Background(), cfg, *eventsDir, *chainOnly))
+}
+
+func verify(ctx context.Context, cfg lakeConfig, eventsDir string, chainOnly bool) int {
+	events, err := pas.LoadEvents(eventsDir)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+	if len(events) == 0 {
+		log.Printf("❌ No PAS events in %s", eventsDir)
+		return 1
+	}
+
+	chain, err := pas.OrderChain(events)
+	if err != nil {
+		log.Printf("❌ Chain verification failed: %v", err)
+		return 1
+	}
+
+	head, tail := chain[0].Event, chain[len(chain)-1].Event
+	log.Printf("✅ Chain intact: %d events, ledgers %d-%d", len(chain), head.Batch.LedgerStart, tail.Batch.LedgerEnd)
+	if !head.IsGenesis() {
+		log.Printf("   Chain starts after %s, not at genesis", head.PreviousHash)
+	}
+	if chainOnly {
+		return 0
+	}
+
+	db, err := lake.Open(cfg.DuckLake)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return 1
+	}
+	defer db.Close()
+
+	reader := &pas.LakeReader{DB: db, Catalog: cfg.DuckLake.CatalogName, Schema: cfg.DuckLake.SchemaName}
+	mismatches, skipped, err := pas.VerifyLake(ctx, chain, reader)
+	if err != nil {
+		log.Printf("❌ Lake verification failed: %v", err)
+		return 1
+	}
+
+	if skipped > 0 {
+		log.Printf("   %d events carry no row digests and were not checked against the lake", skipped)
+	}
+	if len(mismatches) > 0 {
+		for _, m := range mismatches {
+			log.Printf("❌ %s", m)
+		}
+		log.Printf("❌ %d mismatches between the lake and its audit stream", len(mismatches))
+		return 1
+	}
+
+	log.Printf("✅ Lake matches %d events", len(chain)-skipped)
+	return 0
+}
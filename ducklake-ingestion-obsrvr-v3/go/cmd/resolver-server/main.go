@@ -0,0 +1,110 @@
+// Command resolver-server serves the Bronze resolver over HTTP: coverage and
+// gap queries, signed read manifests pinned to a DuckLake snapshot, and
+// queries replayed against an issued manifest.
+//
+//	resolver-server -config config.yaml [-addr :8090] [-networks testnet,mainnet]
+//	                [-manifests ./resolver-manifests] [-signing-key-file FILE]
+//
+// The signing key is read from -signing-key-file or RESOLVER_SIGNING_KEY and
+// must be at least 32 bytes. Manifests stay verifiable for as long as the key
+// does not change.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/internal/lake"
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/resolver/server"
+)
+
+// lakeConfig is the part of the ingester configuration the server needs
+type lakeConfig struct {
+	DuckLake lake.Config `yaml:"ducklake"`
+}
+
+func main() {
+	configPath := flag.String("config", "", "ingester configuration file (ducklake section)")
+	addr := flag.String("addr", ":8090", "HTTP listen address")
+	networks := flag.String("networks", "", "comma-separated network schemas to serve (default: ducklake.schema_name)")
+	manifestDir := flag.String("manifests", "./resolver-manifests", "directory for issued manifests")
+	keyFile := flag.String("signing-key-file", "", "file holding the manifest signing key (default: $RESOLVER_SIGNING_KEY)")
+	flag.Parse()
+
+	if *configPath == "" {
+		log.Fatal("-config is required")
+	}
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to read config: %v", err)
+	}
+	var cfg lakeConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		log.Fatalf("Failed to parse config: %v", err)
+	}
+
+	key := []byte(os.Getenv("RESOLVER_SIGNING_KEY"))
+	if *keyFile != "" {
+		key, err = os.ReadFile(*keyFile)
+		if err != nil {
+			log.Fatalf("Failed to read signing key: %v", err)
+		}
+		key = []byte(strings.TrimSpace(string(key)))
+	}
+	signer, err := server.NewSigner(key)
+	if err != nil {
+		log.Fatalf("Invalid signing key: %v", err)
+	}
+
+	store, err := server.NewStore(*manifestDir)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	served := []string{cfg.DuckLake.SchemaName}
+	if *networks != "" {
+		served = strings.Split(*networks, ",")
+	}
+
+	db, err := lake.Open(cfg.DuckLake)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	defer db.Close()
+
+	srv, err := server.New(server.Config{
+		DB:          db,
+		CatalogName: cfg.DuckLake.CatalogName,
+		Networks:    served,
+		Signer:      signer,
+		Store:       store,
+		Addr:        *addr,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create resolver server: %v", err)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Stop(ctx); err != nil {
+			log.Printf("Resolver server shutdown error: %v", err)
+		}
+	}()
+
+	log.Printf("Serving networks %s from catalog %s, manifests in %s", strings.Join(served, ", "), cfg.DuckLake.CatalogName, *manifestDir)
+	if err := srv.Start(); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
@@ -0,0 +1,486 @@
+//go:build duckdb_arrow
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/flight/flightsql"
+	"github.com/apache/arrow-go/v18/arrow/flight/flightsql/schema_ref"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	duckdb "github.com/duckdb/duckdb-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/internal/sqlguard"
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/resolver"
+)
+
+// FlightSQLServer serves the DuckDB catalog over Arrow Flight SQL. Results
+// stream as Arrow record batches straight from DuckDB, so large pulls keep
+// their column types instead of being flattened to JSON like POST /query.
+//
+// Statements go through the same read-only validation as the HTTP Query API.
+// A statement may name an era-routed dataset with dataset('core.x', ...)
+// (see resolver.DatasetRef) wherever a table is allowed.
+type FlightSQLServer struct {
+	flightsql.BaseServer
+
+	db        *sql.DB
+	catalog   string
+	networks  []string
+	resolvers map[string]*resolver.Resolver
+	addr      string
+	server    flight.Server
+}
+
+// NewFlightSQLServer creates a Flight SQL server for the catalog opened as
+// db's main database. networks are the schemas dataset references can route to.
+// The server has no authentication, so an addr without a host (":8815")
+// listens on loopback only; give a host ("0.0.0.0:8815") to expose it.
+func NewFlightSQLServer(db *sql.DB, networks []string, addr string) (*FlightSQLServer, error) {
+	var catalog string
+	if err := db.QueryRow("SELECT current_database()").Scan(&catalog); err != nil {
+		return nil, fmt.Errorf("failed to read catalog name: %w", err)
+	}
+
+	s := &FlightSQLServer{
+		db:        db,
+		catalog:   catalog,
+		networks:  networks,
+		resolvers: make(map[string]*resolver.Resolver, len(networks)),
+		addr:      loopbackDefault(addr),
+	}
+	s.Alloc = memory.DefaultAllocator
+
+	for _, network := range networks {
+		r, err := resolver.New(resolver.Config{
+			DB:           db,
+			CatalogName:  catalog,
+			SchemaName:   network,
+			CacheEnabled: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create resolver for %s: %w", network, err)
+		}
+		s.resolvers[network] = r
+	}
+
+	sqlInfo := map[flightsql.SqlInfo]interface{}{
+		flightsql.SqlInfoFlightSqlServerName:     "ducklake-ingestion-obsrvr-v3",
+		flightsql.SqlInfoFlightSqlServerVersion:  ProcessorVersion,
+		flightsql.SqlInfoFlightSqlServerReadOnly: true,
+	}
+	for id, value := range sqlInfo {
+		if err := s.RegisterSqlInfo(id, value); err != nil {
+			return nil, fmt.Errorf("failed to register SQL info %v: %w", id, err)
+		}
+	}
+
+	return s, nil
+}
+
+// Start starts the Flight SQL server and blocks until it stops
+func (s *FlightSQLServer) Start() error {
+	s.server = flight.NewServerWithMiddleware(nil)
+	s.server.RegisterFlightService(flightsql.NewFlightServer(s))
+	if err := s.server.Init(s.addr); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+
+	log.Printf("Flight SQL server listening on %s (catalog: %s, networks: %v)", s.server.Addr(), s.catalog, s.networks)
+
+	if err := s.server.Serve(); err != nil {
+		return fmt.Errorf("flight sql server error: %w", err)
+	}
+	return nil
+}
+
+// Stop shuts the server down after in-flight streams finish
+func (s *FlightSQLServer) Stop() {
+	if s.server != nil {
+		log.Printf("Shutting down Flight SQL server...")
+		s.server.Shutdown()
+	}
+}
+
+// GetFlightInfoStatement validates a query and returns a ticket for it, with
+// the schema of its results. The ticket carries the query text; it is
+// validated and resolved again on DoGet since clients can present any ticket.
+func (s *FlightSQLServer) GetFlightInfoStatement(ctx context.Context, cmd flightsql.StatementQuery, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	schema, err := s.resultSchema(ctx, cmd.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := flightsql.CreateStatementQueryTicket([]byte(cmd.GetQuery()))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create ticket: %v", err)
+	}
+	return flightInfoFor(desc, ticket, schema), nil
+}
+
+// CreatePreparedStatement validates a query and returns it as the handle of
+// a prepared statement, with the schema of its results. Handles hold no
+// server state: like statement tickets they carry the query text and are
+// validated again on every use. Parameters are not supported.
+func (s *FlightSQLServer) CreatePreparedStatement(ctx context.Context, req flightsql.ActionCreatePreparedStatementRequest) (flightsql.ActionCreatePreparedStatementResult, error) {
+	schema, err := s.resultSchema(ctx, req.GetQuery())
+	if err != nil {
+		return flightsql.ActionCreatePreparedStatementResult{}, err
+	}
+	return flightsql.ActionCreatePreparedStatementResult{
+		Handle:        []byte(req.GetQuery()),
+		DatasetSchema: schema,
+	}, nil
+}
+
+// ClosePreparedStatement has nothing to release; handles hold no state
+func (s *FlightSQLServer) ClosePreparedStatement(context.Context, flightsql.ActionClosePreparedStatementRequest) error {
+	return nil
+}
+
+// GetFlightInfoPreparedStatement returns a ticket for a prepared statement
+func (s *FlightSQLServer) GetFlightInfoPreparedStatement(ctx context.Context, cmd flightsql.PreparedStatementQuery, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	schema, err := s.resultSchema(ctx, string(cmd.GetPreparedStatementHandle()))
+	if err != nil {
+		return nil, err
+	}
+	return flightInfoFor(desc, desc.Cmd, schema), nil
+}
+
+// DoGetPreparedStatement streams the results of a prepared statement
+func (s *FlightSQLServer) DoGetPreparedStatement(ctx context.Context, cmd flightsql.PreparedStatementQuery) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	query, err := s.prepareQuery(ctx, string(cmd.GetPreparedStatementHandle()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.streamQuery(ctx, query)
+}
+
+// DoGetStatement streams the results of a query ticket
+func (s *FlightSQLServer) DoGetStatement(ctx context.Context, ticket flightsql.StatementQueryTicket) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	query, err := s.prepareQuery(ctx, string(ticket.GetStatementHandle()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.streamQuery(ctx, query)
+}
+
+// GetFlightInfoCatalogs returns a ticket listing the served catalog
+func (s *FlightSQLServer) GetFlightInfoCatalogs(_ context.Context, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	return flightInfoFor(desc, desc.Cmd, schema_ref.Catalogs), nil
+}
+
+// DoGetCatalogs lists the served catalog
+func (s *FlightSQLServer) DoGetCatalogs(_ context.Context) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	b := array.NewRecordBuilder(s.Alloc, schema_ref.Catalogs)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).Append(s.catalog)
+	return schema_ref.Catalogs, singleChunk(b.NewRecord()), nil
+}
+
+// GetFlightInfoSchemas returns a ticket listing the catalog's schemas
+func (s *FlightSQLServer) GetFlightInfoSchemas(_ context.Context, _ flightsql.GetDBSchemas, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	return flightInfoFor(desc, desc.Cmd, schema_ref.DBSchemas), nil
+}
+
+// DoGetDBSchemas lists the catalog's schemas matching the command's filter
+func (s *FlightSQLServer) DoGetDBSchemas(ctx context.Context, cmd flightsql.GetDBSchemas) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	query := "SELECT catalog_name, schema_name FROM information_schema.schemata WHERE catalog_name = ?"
+	args := []interface{}{s.catalog}
+	if cmd.GetCatalog() != nil {
+		args[0] = *cmd.GetCatalog()
+	}
+	if pattern := cmd.GetDBSchemaFilterPattern(); pattern != nil {
+		query += " AND schema_name LIKE ?"
+		args = append(args, *pattern)
+	}
+	query += " ORDER BY schema_name"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "failed to list schemas: %v", err)
+	}
+	defer rows.Close()
+
+	b := array.NewRecordBuilder(s.Alloc, schema_ref.DBSchemas)
+	defer b.Release()
+	for rows.Next() {
+		var catalog, schema string
+		if err := rows.Scan(&catalog, &schema); err != nil {
+			return nil, nil, status.Errorf(codes.Internal, "failed to scan schema: %v", err)
+		}
+		b.Field(0).(*array.StringBuilder).Append(catalog)
+		b.Field(1).(*array.StringBuilder).Append(schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "failed to list schemas: %v", err)
+	}
+
+	return schema_ref.DBSchemas, singleChunk(b.NewRecord()), nil
+}
+
+// GetFlightInfoTables returns a ticket listing the catalog's tables
+func (s *FlightSQLServer) GetFlightInfoTables(_ context.Context, cmd flightsql.GetTables, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	schema := schema_ref.Tables
+	if cmd.GetIncludeSchema() {
+		schema = schema_ref.TablesWithIncludedSchema
+	}
+	return flightInfoFor(desc, desc.Cmd, schema), nil
+}
+
+// DoGetTables lists the catalog's tables matching the command's filters,
+// with their Arrow schemas if requested
+func (s *FlightSQLServer) DoGetTables(ctx context.Context, cmd flightsql.GetTables) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	query := "SELECT table_catalog, table_schema, table_name, table_type FROM information_schema.tables WHERE table_catalog = ?"
+	args := []interface{}{s.catalog}
+	if cmd.GetCatalog() != nil {
+		args[0] = *cmd.GetCatalog()
+	}
+	if pattern := cmd.GetDBSchemaFilterPattern(); pattern != nil {
+		query += " AND table_schema LIKE ?"
+		args = append(args, *pattern)
+	}
+	if pattern := cmd.GetTableNameFilterPattern(); pattern != nil {
+		query += " AND table_name LIKE ?"
+		args = append(args, *pattern)
+	}
+	if types := cmd.GetTableTypes(); len(types) > 0 {
+		query += " AND table_type IN (?" + strings.Repeat(", ?", len(types)-1) + ")"
+		for _, t := range types {
+			args = append(args, t)
+		}
+	}
+	query += " ORDER BY table_schema, table_name"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "failed to list tables: %v", err)
+	}
+	defer rows.Close()
+
+	type table struct{ catalog, schema, name, tableType string }
+	var tables []table
+	for rows.Next() {
+		var t table
+		if err := rows.Scan(&t.catalog, &t.schema, &t.name, &t.tableType); err != nil {
+			return nil, nil, status.Errorf(codes.Internal, "failed to scan table: %v", err)
+		}
+		tables = append(tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "failed to list tables: %v", err)
+	}
+
+	resultSchema := schema_ref.Tables
+	if cmd.GetIncludeSchema() {
+		resultSchema = schema_ref.TablesWithIncludedSchema
+	}
+	b := array.NewRecordBuilder(s.Alloc, resultSchema)
+	defer b.Release()
+	for _, t := range tables {
+		b.Field(0).(*array.StringBuilder).Append(t.catalog)
+		b.Field(1).(*array.StringBuilder).Append(t.schema)
+		b.Field(2).(*array.StringBuilder).Append(t.name)
+		b.Field(3).(*array.StringBuilder).Append(t.tableType)
+		if cmd.GetIncludeSchema() {
+			tableSchema, err := s.querySchema(ctx, fmt.Sprintf("SELECT * FROM %s.%s.%s LIMIT 0",
+				quoteIdentifier(t.catalog), quoteIdentifier(t.schema), quoteIdentifier(t.name)))
+			if err != nil {
+				return nil, nil, status.Errorf(codes.Internal, "failed to read schema of %s.%s: %v", t.schema, t.name, err)
+			}
+			b.Field(4).(*array.BinaryBuilder).Append(flight.SerializeSchema(tableSchema, s.Alloc))
+		}
+	}
+
+	return resultSchema, singleChunk(b.NewRecord()), nil
+}
+
+// GetFlightInfoTableTypes returns a ticket listing the table types
+func (s *FlightSQLServer) GetFlightInfoTableTypes(_ context.Context, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	return flightInfoFor(desc, desc.Cmd, schema_ref.TableTypes), nil
+}
+
+// DoGetTableTypes lists the table types information_schema reports
+func (s *FlightSQLServer) DoGetTableTypes(_ context.Context) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	b := array.NewRecordBuilder(s.Alloc, schema_ref.TableTypes)
+	defer b.Release()
+	b.Field(0).(*array.StringBuilder).AppendValues([]string{"BASE TABLE", "VIEW"}, nil)
+	return schema_ref.TableTypes, singleChunk(b.NewRecord()), nil
+}
+
+// prepareQuery validates a client query and replaces its dataset references
+// with the SQL the resolver generates for them
+func (s *FlightSQLServer) prepareQuery(ctx context.Context, query string) (string, error) {
+	if err := sqlguard.ValidateNoFiles(query); err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid SQL: %v", err)
+	}
+
+	prepared, err := resolver.ExpandDatasetRefs(query, s.networks, func(ref resolver.DatasetRef) (string, error) {
+		r := s.resolvers[ref.Intent.Network]
+		resolved, err := r.ResolveDataset(ctx, ref.Dataset, ref.Intent)
+		if err != nil {
+			return "", status.Errorf(codes.FailedPrecondition, "dataset('%s'): %v", ref.Dataset, err)
+		}
+		datasetSQL, err := r.GenerateSQL(resolved, resolver.SQLOptions{IncludeVersionFilter: true})
+		if err != nil {
+			return "", status.Errorf(codes.Internal, "dataset('%s'): %v", ref.Dataset, err)
+		}
+		return datasetSQL, nil
+	})
+	if _, ok := status.FromError(err); ok {
+		// nil, or a status error from the resolver
+		return prepared, err
+	}
+	if errors.Is(err, resolver.ErrUnknownNetwork) {
+		return "", status.Errorf(codes.NotFound, "%v", err)
+	}
+	return "", status.Errorf(codes.InvalidArgument, "invalid dataset reference: %v", err)
+}
+
+// resultSchema validates and prepares a client query and returns the schema
+// of its results. SELECT and WITH queries are planned under LIMIT 0 so no
+// rows are read; SHOW, DESCRIBE and EXPLAIN cannot be wrapped and are small.
+func (s *FlightSQLServer) resultSchema(ctx context.Context, query string) (*arrow.Schema, error) {
+	prepared, err := s.prepareQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if sqlguard.ValidateQuery(query) == nil {
+		prepared = "SELECT * FROM (\n" + prepared + "\n) LIMIT 0"
+	}
+	schema, err := s.querySchema(ctx, prepared)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "query failed: %v", err)
+	}
+	return schema, nil
+}
+
+// streamQuery runs query through DuckDB's Arrow interface and forwards its
+// record batches. The connection is held until the stream is drained.
+func (s *FlightSQLServer) streamQuery(ctx context.Context, query string) (*arrow.Schema, <-chan flight.StreamChunk, error) {
+	schemas := make(chan *arrow.Schema, 1)
+	errs := make(chan error, 1)
+	chunks := make(chan flight.StreamChunk)
+
+	go func() {
+		defer close(chunks)
+
+		started := false
+		err := s.withArrow(ctx, func(ar *duckdb.Arrow) error {
+			reader, err := ar.QueryContext(ctx, query)
+			if err != nil {
+				return err
+			}
+			defer reader.Release()
+
+			started = true
+			schemas <- reader.Schema()
+			for reader.Next() {
+				rec := reader.Record()
+				rec.Retain()
+				select {
+				case chunks <- flight.StreamChunk{Data: rec}:
+				case <-ctx.Done():
+					rec.Release()
+					return ctx.Err()
+				}
+			}
+			return reader.Err()
+		})
+
+		switch {
+		case err == nil:
+		case !started:
+			errs <- err
+		default:
+			select {
+			case chunks <- flight.StreamChunk{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	select {
+	case schema := <-schemas:
+		return schema, chunks, nil
+	case err := <-errs:
+		return nil, nil, status.Errorf(codes.InvalidArgument, "query failed: %v", err)
+	}
+}
+
+// querySchema returns the Arrow schema of a query's result
+func (s *FlightSQLServer) querySchema(ctx context.Context, query string) (*arrow.Schema, error) {
+	var schema *arrow.Schema
+	err := s.withArrow(ctx, func(ar *duckdb.Arrow) error {
+		reader, err := ar.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer reader.Release()
+		schema = reader.Schema()
+		return nil
+	})
+	return schema, err
+}
+
+// withArrow runs fn with DuckDB's Arrow interface on a pooled connection
+func (s *FlightSQLServer) withArrow(ctx context.Context, fn func(*duckdb.Arrow) error) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		ar, err := duckdb.NewArrowFromConn(driverConn.(driver.Conn))
+		if err != nil {
+			return fmt.Errorf("failed to open Arrow interface: %w", err)
+		}
+		return fn(ar)
+	})
+}
+
+func flightInfoFor(desc *flight.FlightDescriptor, ticket []byte, schema *arrow.Schema) *flight.FlightInfo {
+	info := &flight.FlightInfo{
+		Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: ticket}}},
+		FlightDescriptor: desc,
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}
+	if schema != nil {
+		info.Schema = flight.SerializeSchema(schema, memory.DefaultAllocator)
+	}
+	return info
+}
+
+// loopbackDefault binds an address without a host to loopback
+func loopbackDefault(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+func singleChunk(rec arrow.RecordBatch) <-chan flight.StreamChunk {
+	ch := make(chan flight.StreamChunk, 1)
+	ch <- flight.StreamChunk{Data: rec}
+	close(ch)
+	return ch
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
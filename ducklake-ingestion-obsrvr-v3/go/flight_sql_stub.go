@@ -0,0 +1,25 @@
+//go:build !duckdb_arrow
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// FlightSQLServer is unavailable without DuckDB's Arrow interface, which
+// duckdb-go only compiles with -tags duckdb_arrow.
+type FlightSQLServer struct{}
+
+// NewFlightSQLServer reports that the binary was built without Arrow support
+func NewFlightSQLServer(db *sql.DB, networks []string, addr string) (*FlightSQLServer, error) {
+	return nil, fmt.Errorf("flight sql requires a build with -tags duckdb_arrow")
+}
+
+// Start is never reached; NewFlightSQLServer always fails
+func (s *FlightSQLServer) Start() error {
+	return nil
+}
+
+// Stop is never reached; NewFlightSQLServer always fails
+func (s *FlightSQLServer) Stop() {}
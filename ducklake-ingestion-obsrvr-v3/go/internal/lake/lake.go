@@ -0,0 +1,74 @@
+// Package lake attaches a DuckLake catalog read-only for the tools that read
+// what the ingester wrote (cmd/pas, cmd/resolver-server).
+package lake
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	duckdb "github.com/duckdb/duckdb-go/v2"
+)
+
+// Config is the ducklake section of the ingester configuration
+type Config struct {
+	CatalogPath        string `yaml:"catalog_path"`
+	DataPath           string `yaml:"data_path"`
+	MetadataSchema     string `yaml:"metadata_schema"`
+	CatalogName        string `yaml:"catalog_name"`
+	SchemaName         string `yaml:"schema_name"`
+	AWSAccessKeyID     string `yaml:"aws_access_key_id"`
+	AWSSecretAccessKey string `yaml:"aws_secret_access_key"`
+	AWSRegion          string `yaml:"aws_region"`
+	AWSEndpoint        string `yaml:"aws_endpoint"`
+}
+
+// Open attaches the DuckLake catalog read-only the same way the ingester
+// attaches it for writing
+func Open(lake Config) (*sql.DB, error) {
+	if lake.CatalogPath == "" || lake.CatalogName == "" || lake.SchemaName == "" {
+		return nil, fmt.Errorf("config must set ducklake.catalog_path, catalog_name and schema_name")
+	}
+
+	connector, err := duckdb.NewConnector("", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DuckDB connector: %w", err)
+	}
+	db := sql.OpenDB(connector)
+
+	for _, ext := range []string{"INSTALL ducklake", "INSTALL httpfs", "LOAD ducklake", "LOAD httpfs"} {
+		if _, err := db.Exec(ext); err != nil {
+			log.Printf("Extension setup: %s (error: %v)", ext, err)
+		}
+	}
+
+	if lake.AWSAccessKeyID != "" {
+		endpoint := strings.TrimPrefix(strings.TrimPrefix(lake.AWSEndpoint, "https://"), "http://")
+		createSecretSQL := fmt.Sprintf(
+			"CREATE SECRET (TYPE S3, KEY_ID '%s', SECRET '%s', REGION '%s', ENDPOINT '%s', URL_STYLE 'path')",
+			lake.AWSAccessKeyID, lake.AWSSecretAccessKey, lake.AWSRegion, endpoint)
+		if _, err := db.Exec(createSecretSQL); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create S3 secret: %w", err)
+		}
+	}
+
+	options := []string{"READ_ONLY"}
+	if !strings.HasPrefix(lake.CatalogPath, "ducklake:") {
+		options = append(options, "TYPE ducklake")
+	}
+	if lake.DataPath != "" {
+		options = append(options, fmt.Sprintf("DATA_PATH '%s'", lake.DataPath))
+	}
+	if lake.MetadataSchema != "" {
+		options = append(options, fmt.Sprintf("METADATA_SCHEMA '%s'", lake.MetadataSchema))
+	}
+	attachSQL := fmt.Sprintf("ATTACH '%s' AS %s (%s)", lake.CatalogPath, lake.CatalogName, strings.Join(options, ", "))
+	if _, err := db.Exec(attachSQL); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to attach DuckLake catalog: %w", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,127 @@
+// Package sqlguard screens client SQL before the ingester's Query API, its
+// Flight SQL server and the resolver server run it.
+//
+// The checks are lexical and deliberately conservative: keywords and file
+// references are matched anywhere in the text, string literals included, so
+// a query that only mentions 'drop' in a literal is rejected too.
+package sqlguard
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// deniedKeywordPattern matches statements that modify the catalog or the
+// session: writes, DDL, settings, extensions, attached databases and
+// checkpoints. Keywords are matched as whole words so columns like
+// created_at and updated_at pass.
+var deniedKeywordPattern = regexp.MustCompile(`(?i)\b(DROP|DELETE|TRUNCATE|INSERT|UPDATE|MERGE|ALTER|CREATE|GRANT|REVOKE|ATTACH|DETACH|COPY|SET|RESET|PRAGMA|INSTALL|LOAD|CALL|EXPORT|IMPORT|USE|CHECKPOINT|VACUUM)\b`)
+
+// leadingKeywordPattern captures the first word of a statement
+var leadingKeywordPattern = regexp.MustCompile(`^\s*([A-Za-z]+)\b`)
+
+// fileReadPattern matches table functions that read files, URLs or other
+// databases, and query()/query_table(), which run SQL given as a string
+var fileReadPattern = regexp.MustCompile(`(?i)\b(read_\w+|\w+_scan|parquet_\w+|ducklake_\w+|glob|sniff_csv|st_read|query|query_table)\s*\(`)
+
+// fileRefPattern matches what DuckDB would read as a file when it appears
+// where a table is expected: data file extensions, URLs and paths
+var fileRefPattern = regexp.MustCompile(`(?i)(\.(csv|tsv|parquet|json|jsonl|ndjson|txt|xlsx|arrow|avro|gz|zst)\b|://|'\s*[/~.])`)
+
+// readStatements are the statements Validate allows
+var readStatements = []string{"SELECT", "WITH", "SHOW", "DESCRIBE", "EXPLAIN"}
+
+// queryStatements are the statements ValidateQuery allows
+var queryStatements = []string{"SELECT", "WITH"}
+
+// Validate allows a single read statement: SELECT, WITH, SHOW, DESCRIBE or
+// EXPLAIN.
+func Validate(sql string) error {
+	return validate(sql, readStatements)
+}
+
+// ValidateQuery allows a single SELECT or WITH query, the statements that
+// can be wrapped as a subquery.
+func ValidateQuery(sql string) error {
+	return validate(sql, queryStatements)
+}
+
+// ValidateNoFiles allows what Validate does, except table functions that
+// read files or run SQL strings, and file paths and URLs. Network-facing
+// endpoints use it so clients cannot reach the host's files or storage
+// credentials.
+func ValidateNoFiles(sql string) error {
+	if err := Validate(sql); err != nil {
+		return err
+	}
+	return checkFiles(sql)
+}
+
+// ValidateSelfContained allows a query that reads only the relations its
+// caller wraps it in: on top of ValidateQuery it rejects table functions
+// that read files or run SQL strings, file paths and URLs, and names
+// qualified by any of catalogs.
+func ValidateSelfContained(sql string, catalogs []string) error {
+	if err := ValidateQuery(sql); err != nil {
+		return err
+	}
+	if err := checkFiles(sql); err != nil {
+		return err
+	}
+	for _, catalog := range catalogs {
+		if catalog == "" {
+			continue
+		}
+		// Quoted or bare, followed by the dot of a qualified name
+		qualified := regexp.MustCompile(`(?i)(^|[^\w.])"?` + regexp.QuoteMeta(catalog) + `"?\s*\.`)
+		if qualified.MatchString(sql) {
+			return fmt.Errorf("references to catalog %s are not allowed", catalog)
+		}
+	}
+	return nil
+}
+
+// checkFiles rejects table functions that read files or run SQL strings,
+// and file paths and URLs
+func checkFiles(sql string) error {
+	if m := fileReadPattern.FindStringSubmatch(sql); m != nil {
+		return fmt.Errorf("table function not allowed: %s", m[1])
+	}
+	if ref := fileRefPattern.FindString(sql); ref != "" {
+		return fmt.Errorf("file reference not allowed: %s", strings.TrimSpace(ref))
+	}
+	return nil
+}
+
+func validate(sql string, allowed []string) error {
+	if strings.TrimSpace(sql) == "" {
+		return fmt.Errorf("empty query")
+	}
+
+	// A statement separator anywhere, even in a literal, could start a second
+	// statement that the checks below would not see as one
+	if strings.Contains(sql, ";") {
+		return fmt.Errorf("multiple statements are not allowed (remove ';')")
+	}
+
+	if m := deniedKeywordPattern.FindStringSubmatch(sql); m != nil {
+		return fmt.Errorf("operation not allowed: %s", strings.ToUpper(m[1]))
+	}
+
+	m := leadingKeywordPattern.FindStringSubmatch(sql)
+	if m == nil || !slices.Contains(allowed, strings.ToUpper(m[1])) {
+		return fmt.Errorf("only %s queries are allowed", joinStatements(allowed))
+	}
+
+	return nil
+}
+
+// joinStatements lists statements as "A, B, and C" or "A and B"
+func joinStatements(statements []string) string {
+	if len(statements) <= 2 {
+		return strings.Join(statements, " and ")
+	}
+	return strings.Join(statements[:len(statements)-1], ", ") + ", and " + statements[len(statements)-1]
+}
@@ -0,0 +1,129 @@
+package sqlguard
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		sql     string
+		wantErr string
+	}{
+		{"SELECT created_at, updated_at FROM testnet.ledgers_row_v2", ""},
+		{"  select count(*) from t", ""},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", ""},
+		{"SHOW TABLES", ""},
+		{"DESCRIBE testnet.ledgers_row_v2", ""},
+		{"EXPLAIN SELECT 1", ""},
+		{"SELECT settings, loader, user_id FROM t", ""},
+
+		{"", "empty query"},
+		{"   ", "empty query"},
+		{"SELECT 1; DROP TABLE t", "multiple statements"},
+		{"SELECT 1; SELECT 2", "multiple statements"},
+		{"SELECT 1;", "multiple statements"},
+		{"SELECT ';'", "multiple statements"},
+		{"SELECTED FROM t", "only SELECT, WITH, SHOW, DESCRIBE, and EXPLAIN queries are allowed"},
+		{"(SELECT 1)", "only SELECT"},
+		{"SUMMARIZE t", "only SELECT"},
+	}
+	for _, keyword := range []string{
+		"DROP", "DELETE", "TRUNCATE", "INSERT", "UPDATE", "MERGE", "ALTER", "CREATE", "GRANT", "REVOKE",
+		"ATTACH", "DETACH", "COPY", "SET", "RESET", "PRAGMA", "INSTALL", "LOAD", "CALL", "EXPORT",
+		"IMPORT", "USE", "CHECKPOINT", "VACUUM",
+	} {
+		tests = append(tests,
+			struct{ sql, wantErr string }{keyword + " x", "operation not allowed: " + keyword},
+			struct{ sql, wantErr string }{"WITH t AS (SELECT 1) " + strings.ToLower(keyword) + " x", "operation not allowed: " + keyword},
+		)
+	}
+
+	for _, tt := range tests {
+		err := Validate(tt.sql)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%q) = %v, want nil", tt.sql, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Validate(%q) = %v, want error containing %q", tt.sql, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateQuery(t *testing.T) {
+	for _, sql := range []string{"SELECT * FROM manifest", "with t AS (SELECT 1) SELECT * FROM t"} {
+		if err := ValidateQuery(sql); err != nil {
+			t.Errorf("ValidateQuery(%q) = %v, want nil", sql, err)
+		}
+	}
+	for _, sql := range []string{"SHOW TABLES", "DESCRIBE manifest", "EXPLAIN SELECT 1", "SELECT 1; SELECT 2"} {
+		if err := ValidateQuery(sql); err == nil {
+			t.Errorf("ValidateQuery(%q) succeeded", sql)
+		}
+	}
+}
+
+func TestValidateNoFiles(t *testing.T) {
+	for _, sql := range []string{
+		"SELECT * FROM testnet.ledgers_row_v2",
+		"SHOW TABLES",
+		"DESCRIBE testnet.ledgers_row_v2",
+		"SELECT * FROM dataset('core.ledgers_row_v2', 'network=testnet')",
+	} {
+		if err := ValidateNoFiles(sql); err != nil {
+			t.Errorf("ValidateNoFiles(%q) = %v, want nil", sql, err)
+		}
+	}
+
+	tests := []struct {
+		sql     string
+		wantErr string
+	}{
+		{"SELECT * FROM read_csv('/etc/passwd')", "table function not allowed: read_csv"},
+		{"SELECT * FROM read_text('/root/.aws/credentials')", "table function not allowed: read_text"},
+		{"SELECT * FROM glob('/data/*')", "table function not allowed: glob"},
+		{"SELECT * FROM read_parquet('s3://bucket/x.parquet')", "table function not allowed: read_parquet"},
+		{"SELECT * FROM 's3://bucket/x'", "file reference not allowed: ://"},
+		{"SELECT * FROM '/etc/passwd'", "file reference not allowed"},
+		{"DROP TABLE x", "operation not allowed: DROP"},
+	}
+	for _, tt := range tests {
+		if err := ValidateNoFiles(tt.sql); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateNoFiles(%q) = %v, want error containing %q", tt.sql, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateSelfContained(t *testing.T) {
+	catalogs := []string{"lake", ""}
+
+	for _, sql := range []string{
+		"SELECT * FROM manifest",
+		"SELECT m.lake FROM manifest m",
+		"SELECT * FROM lakehouse.t",
+		"SELECT * FROM mylake.t",
+		"SELECT readings FROM manifest",
+	} {
+		if err := ValidateSelfContained(sql, catalogs); err != nil {
+			t.Errorf("ValidateSelfContained(%q) = %v, want nil", sql, err)
+		}
+	}
+
+	for _, sql := range []string{
+		"SHOW TABLES",
+		"SELECT * FROM lake.testnet.t",
+		"SELECT * FROM LAKE . testnet.t",
+		`SELECT * FROM "lake"."testnet"."t"`,
+		"SELECT * FROM manifest JOIN (SELECT 1 FROM lake.main.t) USING (x)",
+		"SELECT * FROM read_json('x')",
+		"SELECT * FROM delta_scan('x')",
+		"SELECT * FROM 'x.csv.gz'",
+	} {
+		if err := ValidateSelfContained(sql, catalogs); err == nil {
+			t.Errorf("ValidateSelfContained(%q) succeeded", sql)
+		}
+	}
+}
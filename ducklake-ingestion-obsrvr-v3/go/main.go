@@ -26,6 +26,7 @@ import (
 	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/era"
 	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/manifest"
 	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/metrics"
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/pas"
 	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/source"
 	pb "github.com/withObsrvr/ttp-processor-demo/stellar-live-source-datalake/go/gen/raw_ledger_service"
 	"gopkg.in/yaml.v3"
@@ -1050,6 +1051,7 @@ func main() {
 	useLegacyConfig := flag.Bool("legacy-config", false, "Use legacy Config format (gRPC only, no audit/metrics)")
 	multiNetwork := flag.Bool("multi-network", false, "Enable multi-network concurrent ingestion mode")
 	queryPort := flag.String("query-port", ":8080", "HTTP Query API port (default :8080, empty to disable)")
+	flightSQLPort := flag.String("flight-sql-port", "", "Arrow Flight SQL address (e.g. :8815 for loopback only, 0.0.0.0:8815 to expose; empty to disable; requires -tags duckdb_arrow)")
 	flag.Parse()
 
 	log.Println("DuckLake Ingestion Processor v3 - Bronze Copier")
@@ -1067,7 +1069,7 @@ func main() {
 	if *multiNetwork {
 		log.Println("=== MULTI-NETWORK MODE ===")
 		go func() {
-			errChan <- runMultiNetworkPipelines(ctx, *configPath, *queryPort)
+			errChan <- runMultiNetworkPipelines(ctx, *configPath, *queryPort, *flightSQLPort)
 		}()
 	} else if !*useLegacyConfig {
 		// Use enhanced AppConfig format (supports datastore mode, audit, metrics)
@@ -1326,7 +1328,7 @@ func getCheckpointConfigForNetwork(globalCheckpoint *struct {
 
 // runMultiNetworkPipelines orchestrates concurrent ingestion from multiple networks
 // Each network runs its own pipeline(s), all feeding a shared write queue
-func runMultiNetworkPipelines(ctx context.Context, configPath, queryPort string) error {
+func runMultiNetworkPipelines(ctx context.Context, configPath, queryPort, flightSQLPort string) error {
 	log.Println("[MULTI-NETWORK] Starting multi-network ingestion orchestrator...")
 
 	// Load app config (supports both single source and multi-source)
@@ -1349,6 +1351,11 @@ func runMultiNetworkPipelines(ctx context.Context, configPath, queryPort string)
 	// Configure HTTP Query API (if port specified)
 	orchestrator.SetQueryPort(queryPort)
 
+	// Configure Arrow Flight SQL (if port specified)
+	if err := orchestrator.SetFlightSQLPort(flightSQLPort); err != nil {
+		return err
+	}
+
 	// Start all source runners and write worker
 	if err := orchestrator.Start(); err != nil {
 		return fmt.Errorf("failed to start orchestrator: %w", err)
@@ -5031,6 +5038,16 @@ func (ing *Ingester) flush(ctx context.Context) error {
 			tableStats["account_signers_snapshot_v1"] = manifest.TableStats{RowCount: int64(numAccountSigners)}
 		}
 
+		// Replace table checksums with PAS row digests read back from the lake
+		var digestScheme string
+		if ing.auditLayer.ComputesDigests() {
+			if err := ing.digestTables(ctx, tableStats, ledgerStart, ledgerEnd); err != nil {
+				log.Printf("⚠️  [PAS] Warning: Failed to compute row digests, emitting event without them: %v", err)
+			} else {
+				digestScheme = pas.DigestScheme
+			}
+		}
+
 		// Get source mode from source
 		sourceMode := "datastore"
 		if ing.config.Source.Endpoint != "" {
@@ -5038,11 +5055,12 @@ func (ing *Ingester) flush(ctx context.Context) error {
 		}
 
 		batchStats := BatchStats{
-			LedgerStart: ledgerStart,
-			LedgerEnd:   ledgerEnd,
-			LedgerCount: numLedgers,
-			Tables:      tableStats,
-			StartTime:   flushStart,
+			LedgerStart:  ledgerStart,
+			LedgerEnd:    ledgerEnd,
+			LedgerCount:  numLedgers,
+			Tables:       tableStats,
+			StartTime:    flushStart,
+			DigestScheme: digestScheme,
 		}
 
 		pasInfo, err := ing.auditLayer.OnFlushComplete(
@@ -5094,6 +5112,37 @@ func (ing *Ingester) flush(ctx context.Context) error {
 	return nil
 }
 
+// digestTables sets the Checksum of every table in stats to its PAS row
+// digest for the flushed ledger range. The rows are read back from the lake
+// with the same code `pas verify` uses, so the digest covers what was stored.
+// Tables that received rows without being counted are added to stats.
+func (ing *Ingester) digestTables(ctx context.Context, stats map[string]manifest.TableStats, ledgerStart, ledgerEnd uint32) error {
+	reader := &pas.LakeReader{
+		DB:      ing.db,
+		Catalog: ing.config.DuckLake.CatalogName,
+		Schema:  ing.config.DuckLake.SchemaName,
+	}
+
+	for table := range pas.DigestTables {
+		digest, err := reader.TableDigest(ctx, table, ledgerStart, ledgerEnd, ing.eraConfig.EraID)
+		if err != nil {
+			return err
+		}
+		counted, ok := stats[table]
+		if !ok && digest.RowCount == 0 {
+			continue
+		}
+		if digest.RowCount != counted.RowCount {
+			log.Printf("⚠️  [PAS] %s has %d rows for ledgers %d-%d, batch wrote %d; recording the lake count",
+				table, digest.RowCount, ledgerStart, ledgerEnd, counted.RowCount)
+		}
+		counted.RowCount = digest.RowCount
+		counted.Checksum = digest.Root
+		stats[table] = counted
+	}
+	return nil
+}
+
 // flushToQueue submits buffered data to the shared write queue (multi-network mode)
 // This replaces direct DuckDB writes with queue-based serialization to prevent catalog locks
 func (ing *Ingester) flushToQueue(ctx context.Context) error {
@@ -36,6 +36,9 @@ type MultiSourceOrchestrator struct {
 	queryServer *QueryServer
 	queryPort   string
 
+	// Arrow Flight SQL server
+	flightSQLServer *FlightSQLServer
+
 	// Lifecycle management
 	wg         sync.WaitGroup
 	ctx        context.Context
@@ -44,6 +47,12 @@ type MultiSourceOrchestrator struct {
 
 // NewMultiSourceOrchestrator creates a new multi-source orchestrator
 func NewMultiSourceOrchestrator(appConfig *AppConfig) (*MultiSourceOrchestrator, error) {
+	// Queued writes go through the QueueWriter, which records no lineage and
+	// emits no PAS events, so digests could never be checked against the lake
+	if appConfig.PAS.Enabled {
+		return nil, fmt.Errorf("pas.enabled requires single-network mode (one source)")
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	o := &MultiSourceOrchestrator{
@@ -113,6 +122,26 @@ func (o *MultiSourceOrchestrator) SetQueryPort(port string) {
 	log.Printf("Query API configured on port %s", port)
 }
 
+// SetFlightSQLPort configures the Arrow Flight SQL server
+func (o *MultiSourceOrchestrator) SetFlightSQLPort(port string) error {
+	if port == "" {
+		return nil
+	}
+
+	networks := make([]string, 0, len(o.sources))
+	for _, runner := range o.sources {
+		networks = append(networks, runner.name)
+	}
+
+	server, err := NewFlightSQLServer(o.db, networks, port)
+	if err != nil {
+		return fmt.Errorf("failed to create Flight SQL server: %w", err)
+	}
+	o.flightSQLServer = server
+	log.Printf("Flight SQL configured on port %s", port)
+	return nil
+}
+
 // initDuckDB initializes the shared DuckDB connection
 func (o *MultiSourceOrchestrator) initDuckDB() error {
 	catalogPath := o.appConfig.DuckLake.CatalogPath
@@ -732,9 +761,8 @@ func (o *MultiSourceOrchestrator) createSourceRunner(index int, networkName, sch
 		netConfig.Checkpoint = o.appConfig.Checkpoint
 	}
 
-	// Set manifest and PAS configs
+	// Set manifest config (PAS is rejected in multi-network mode)
 	netConfig.Manifest = o.appConfig.Manifest
-	netConfig.PAS = o.appConfig.PAS
 
 	// Create Ingester from per-network config
 	log.Printf("[%s] Creating Ingester instance...", networkName)
@@ -780,6 +808,19 @@ func (o *MultiSourceOrchestrator) Start() error {
 		}()
 	}
 
+	// Start Arrow Flight SQL server (if configured)
+	if o.flightSQLServer != nil {
+		o.wg.Add(1)
+		go func() {
+			defer o.wg.Done()
+			if err := o.flightSQLServer.Start(); err != nil {
+				log.Printf("[FLIGHT-SQL] ❌ Server stopped with error: %v", err)
+			} else {
+				log.Printf("[FLIGHT-SQL] ✅ Server stopped gracefully")
+			}
+		}()
+	}
+
 	// Start each source runner (Ingester) in its own goroutine
 	for _, runner := range o.sources {
 		o.wg.Add(1)
@@ -819,6 +860,11 @@ func (o *MultiSourceOrchestrator) Stop() error {
 		}
 	}
 
+	// Stop Arrow Flight SQL server (if running)
+	if o.flightSQLServer != nil {
+		o.flightSQLServer.Stop()
+	}
+
 	// Cancel context to signal all Ingesters to stop
 	o.cancelFunc()
 
@@ -10,22 +10,33 @@ import (
 // ComputeEventHash calculates the SHA256 hash of the event's canonical content.
 // The hash is computed over a deterministic JSON representation that excludes
 // the EventHash field itself and uses sorted keys for reproducibility.
+//
+// From version 1.2 the representation leaves out the emission timestamp, the
+// processing duration and the manifest hash (which covers the manifest's own
+// generation time), so the hash depends only on the ledgers processed.
 func ComputeEventHash(e *Event) string {
-	// Build canonical representation for hashing
-	// We exclude EventHash and use a fixed field order
-	canonical := canonicalEvent{
+	if e.Version == EventVersionLegacy {
+		return hashCanonical(canonicalLegacyEvent{
+			Version:      e.Version,
+			PreviousHash: e.PreviousHash,
+			Timestamp:    e.Timestamp.UTC().Format("2006-01-02T15:04:05.000Z"),
+			Producer:     canonicalProducerFrom(e.Producer),
+			Batch:        canonicalLegacyBatchFrom(e.Batch),
+		})
+	}
+
+	return hashCanonical(canonicalEvent{
 		Version:      e.Version,
 		PreviousHash: e.PreviousHash,
-		// Note: Timestamp is included for uniqueness but makes events non-deterministic
-		// For fully deterministic hashes, consider a sequence number instead
-		Timestamp: e.Timestamp.UTC().Format("2006-01-02T15:04:05.000Z"),
-		Producer:  canonicalProducerFrom(e.Producer),
-		Batch:     canonicalBatchFrom(e.Batch),
-	}
+		Producer:     canonicalProducerFrom(e.Producer),
+		Batch:        canonicalBatchFrom(e.Batch),
+	})
+}
 
+func hashCanonical(canonical any) string {
 	data, err := json.Marshal(canonical)
 	if err != nil {
-		// Should never happen with our struct
+		// Should never happen with our structs
 		return ""
 	}
 
@@ -37,11 +48,19 @@ func ComputeEventHash(e *Event) string {
 type canonicalEvent struct {
 	Version      string            `json:"version"`
 	PreviousHash string            `json:"previous_hash"`
-	Timestamp    string            `json:"timestamp"`
 	Producer     canonicalProducer `json:"producer"`
 	Batch        canonicalBatch    `json:"batch"`
 }
 
+// canonicalLegacyEvent is the hashable representation of a 1.1 Event.
+type canonicalLegacyEvent struct {
+	Version      string               `json:"version"`
+	PreviousHash string               `json:"previous_hash"`
+	Timestamp    string               `json:"timestamp"`
+	Producer     canonicalProducer    `json:"producer"`
+	Batch        canonicalLegacyBatch `json:"batch"`
+}
+
 type canonicalProducer struct {
 	ID      string `json:"id"`
 	Version string `json:"version"`
@@ -50,6 +69,15 @@ type canonicalProducer struct {
 }
 
 type canonicalBatch struct {
+	LedgerStart  uint32           `json:"ledger_start"`
+	LedgerEnd    uint32           `json:"ledger_end"`
+	LedgerCount  int              `json:"ledger_count"`
+	Tables       []canonicalTable `json:"tables"`
+	TotalRows    int64            `json:"total_rows"`
+	DigestScheme string           `json:"digest_scheme"`
+}
+
+type canonicalLegacyBatch struct {
 	LedgerStart          uint32           `json:"ledger_start"`
 	LedgerEnd            uint32           `json:"ledger_end"`
 	LedgerCount          int              `json:"ledger_count"`
@@ -75,8 +103,31 @@ func canonicalProducerFrom(p Producer) canonicalProducer {
 }
 
 func canonicalBatchFrom(b BatchInfo) canonicalBatch {
-	tables := make([]canonicalTable, len(b.Tables))
-	for i, t := range b.Tables {
+	return canonicalBatch{
+		LedgerStart:  b.LedgerStart,
+		LedgerEnd:    b.LedgerEnd,
+		LedgerCount:  b.LedgerCount,
+		Tables:       canonicalTablesFrom(b.Tables),
+		TotalRows:    b.TotalRows,
+		DigestScheme: b.DigestScheme,
+	}
+}
+
+func canonicalLegacyBatchFrom(b BatchInfo) canonicalLegacyBatch {
+	return canonicalLegacyBatch{
+		LedgerStart:          b.LedgerStart,
+		LedgerEnd:            b.LedgerEnd,
+		LedgerCount:          b.LedgerCount,
+		Tables:               canonicalTablesFrom(b.Tables),
+		ManifestHash:         b.ManifestHash,
+		TotalRows:            b.TotalRows,
+		ProcessingDurationMs: b.ProcessingDurationMs,
+	}
+}
+
+func canonicalTablesFrom(summaries []TableSummary) []canonicalTable {
+	tables := make([]canonicalTable, len(summaries))
+	for i, t := range summaries {
 		tables[i] = canonicalTable{
 			Name:     t.Name,
 			RowCount: t.RowCount,
@@ -89,15 +140,7 @@ func canonicalBatchFrom(b BatchInfo) canonicalBatch {
 		return tables[i].Name < tables[j].Name
 	})
 
-	return canonicalBatch{
-		LedgerStart:          b.LedgerStart,
-		LedgerEnd:            b.LedgerEnd,
-		LedgerCount:          b.LedgerCount,
-		Tables:               tables,
-		ManifestHash:         b.ManifestHash,
-		TotalRows:            b.TotalRows,
-		ProcessingDurationMs: b.ProcessingDurationMs,
-	}
+	return tables
 }
 
 // Verify checks if the event's hash is correct.
@@ -0,0 +1,200 @@
+package pas
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DigestScheme identifies how TableSummary.Checksum is computed. Events that
+// carry it in BatchInfo.DigestScheme can be re-verified against the lake.
+//
+// Each row of a table that falls in the batch's ledger range (and era) is
+// hashed as SHA256(0x00 || columns), where columns are the row's non-NULL
+// values, sorted by column name, each written as a length-prefixed name
+// followed by a length-prefixed value. Values are rendered as text:
+// timestamps in UTC RFC 3339, blobs in hex, everything else with fmt.
+// Columns stamped with wall-clock time (see digestExcludedColumns) are
+// skipped. The row hashes are sorted and folded pairwise into a Merkle root
+// with SHA256(0x01 || left || right); an odd node is carried up unchanged.
+const DigestScheme = "sha256-merkle-v1"
+
+// digestExcludedColumns are filled at write time rather than from the ledger,
+// so two runs over the same ledgers disagree on them
+var digestExcludedColumns = map[string]bool{
+	"ingestion_timestamp": true,
+	"created_at":          true,
+	"updated_at":          true,
+}
+
+// DigestTables maps every table the ingester writes to the column holding the
+// ledger sequence a row belongs to.
+var DigestTables = map[string]string{
+	"ledgers_row_v2":                 "sequence",
+	"transactions_row_v2":            "ledger_sequence",
+	"operations_row_v2":              "ledger_sequence",
+	"native_balances_snapshot_v1":    "ledger_sequence",
+	"effects_row_v1":                 "ledger_sequence",
+	"trades_row_v1":                  "ledger_sequence",
+	"accounts_snapshot_v1":           "ledger_sequence",
+	"trustlines_snapshot_v1":         "ledger_sequence",
+	"offers_snapshot_v1":             "ledger_sequence",
+	"claimable_balances_snapshot_v1": "ledger_sequence",
+	"liquidity_pools_snapshot_v1":    "ledger_sequence",
+	"contract_events_stream_v1":      "ledger_sequence",
+	"contract_data_snapshot_v1":      "ledger_sequence",
+	"contract_code_snapshot_v1":      "ledger_sequence",
+	"config_settings_snapshot_v1":    "ledger_sequence",
+	"ttl_snapshot_v1":                "ledger_sequence",
+	"evicted_keys_state_v1":          "ledger_sequence",
+	"restored_keys_state_v1":         "ledger_sequence",
+	"account_signers_snapshot_v1":    "ledger_sequence",
+}
+
+// TableDigest is the recomputed digest of one table for one batch.
+type TableDigest struct {
+	RowCount int64
+	Root     string
+}
+
+// LakeReader computes table digests from a DuckDB connection with the
+// DuckLake catalog attached.
+type LakeReader struct {
+	DB      *sql.DB
+	Catalog string
+	Schema  string
+}
+
+// TableDigest hashes the rows of table written for ledgers
+// [ledgerStart, ledgerEnd]. A non-empty eraID restricts the rows to that era.
+func (r *LakeReader) TableDigest(ctx context.Context, table string, ledgerStart, ledgerEnd uint32, eraID string) (TableDigest, error) {
+	ledgerColumn, ok := DigestTables[table]
+	if !ok {
+		return TableDigest{}, fmt.Errorf("no digest definition for table %s", table)
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s.%s.%s WHERE %s BETWEEN ? AND ?",
+		r.Catalog, r.Schema, table, ledgerColumn)
+	args := []any{ledgerStart, ledgerEnd}
+	if eraID != "" {
+		query += " AND era_id = ?"
+		args = append(args, eraID)
+	}
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return TableDigest{}, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return TableDigest{}, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	var hashes [][32]byte
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return TableDigest{}, fmt.Errorf("failed to scan %s row: %w", table, err)
+		}
+		hashes = append(hashes, RowHash(columns, values))
+	}
+	if err := rows.Err(); err != nil {
+		return TableDigest{}, fmt.Errorf("failed to read %s rows: %w", table, err)
+	}
+
+	return TableDigest{
+		RowCount: int64(len(hashes)),
+		Root:     MerkleRoot(hashes),
+	}, nil
+}
+
+// RowHash computes the leaf hash of one row as described by DigestScheme.
+func RowHash(columns []string, values []any) [32]byte {
+	type column struct {
+		name  string
+		value string
+	}
+	cols := make([]column, 0, len(columns))
+	for i, name := range columns {
+		if digestExcludedColumns[name] || values[i] == nil {
+			continue
+		}
+		cols = append(cols, column{name: name, value: canonicalValue(values[i])})
+	}
+	sort.Slice(cols, func(i, j int) bool {
+		return cols[i].name < cols[j].name
+	})
+
+	var buf bytes.Buffer
+	buf.WriteByte(0x00)
+	for _, c := range cols {
+		writeLengthPrefixed(&buf, c.name)
+		writeLengthPrefixed(&buf, c.value)
+	}
+	return sha256.Sum256(buf.Bytes())
+}
+
+// MerkleRoot sorts the row hashes and folds them into a hex-encoded root.
+// An empty set has an empty root.
+func MerkleRoot(hashes [][32]byte) string {
+	if len(hashes) == 0 {
+		return ""
+	}
+
+	level := make([][32]byte, len(hashes))
+	copy(level, hashes)
+	sort.Slice(level, func(i, j int) bool {
+		return bytes.Compare(level[i][:], level[j][:]) < 0
+	})
+
+	for len(level) > 1 {
+		next := make([][32]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			var node [65]byte
+			node[0] = 0x01
+			copy(node[1:33], level[i][:])
+			copy(node[33:], level[i+1][:])
+			next = append(next, sha256.Sum256(node[:]))
+		}
+		level = next
+	}
+
+	return hex.EncodeToString(level[0][:])
+}
+
+func canonicalValue(v any) string {
+	switch val := v.(type) {
+	case time.Time:
+		return val.UTC().Format(time.RFC3339Nano)
+	case []byte:
+		return hex.EncodeToString(val)
+	case string:
+		return val
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func writeLengthPrefixed(buf *bytes.Buffer, s string) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(s)))
+	buf.Write(n[:])
+	buf.WriteString(s)
+}
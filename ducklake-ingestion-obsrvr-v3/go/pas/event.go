@@ -8,7 +8,15 @@ import (
 	"time"
 )
 
-// Event represents a PAS v1.1 event.
+// Event schema versions. Version 1.2 hashes only ledger-derived content, so
+// re-running the same ledgers reproduces the chain; 1.1 events also hashed
+// the emission timestamp and processing duration and are still verifiable.
+const (
+	EventVersionLegacy = "1.1"
+	EventVersion       = "1.2"
+)
+
+// Event represents a PAS v1.2 event.
 // Events form a hash chain where each event references the previous one,
 // creating an immutable audit trail.
 type Event struct {
@@ -68,6 +76,10 @@ type BatchInfo struct {
 
 	// ProcessingDuration in milliseconds
 	ProcessingDurationMs int64 `json:"processing_duration_ms"`
+
+	// DigestScheme names how table checksums were computed (empty if the
+	// batch was emitted without row digests)
+	DigestScheme string `json:"digest_scheme,omitempty"`
 }
 
 // TableSummary provides a checksum-verified record of table output.
@@ -78,7 +90,8 @@ type TableSummary struct {
 	// RowCount is the number of rows written
 	RowCount int64 `json:"row_count"`
 
-	// Checksum is SHA256 of the row data (if computed)
+	// Checksum is the Merkle root of the table's rows for this batch, as
+	// described by the batch's DigestScheme (if computed)
 	Checksum string `json:"checksum,omitempty"`
 }
 
@@ -90,7 +103,7 @@ func NewEvent(
 	batch BatchInfo,
 ) *Event {
 	return &Event{
-		Version:      "1.1",
+		Version:      EventVersion,
 		PreviousHash: previousHash,
 		Timestamp:    time.Now().UTC(),
 		Producer:     producer,
@@ -108,7 +121,8 @@ func (e *Event) IsGenesis() bool {
 
 // Config holds PAS configuration.
 type Config struct {
-	// Enabled determines if PAS events are generated
+	// Enabled determines if PAS events are generated. PAS requires
+	// single-network mode: queued multi-network writes emit no events.
 	Enabled bool `yaml:"enabled"`
 
 	// BackupDir is where PAS event files are stored
@@ -119,6 +133,10 @@ type Config struct {
 
 	// Strict mode fails the batch if PAS emission fails
 	Strict bool `yaml:"strict"`
+
+	// SkipDigests emits events without per-table row digests. Digests re-read
+	// each batch from the lake after it is written.
+	SkipDigests bool `yaml:"skip_digests"`
 }
 
 // ApplyDefaults sets default values for PAS config.
@@ -0,0 +1,179 @@
+package pas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LoadEvents reads every PAS event file in dir, keyed by file name.
+func LoadEvents(dir string) (map[string]*Event, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event directory: %w", err)
+	}
+
+	events := make(map[string]*Event)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "pas_") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event file %s: %w", name, err)
+		}
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("failed to parse event file %s: %w", name, err)
+		}
+		events[name] = &event
+	}
+	return events, nil
+}
+
+// ChainEntry is one event in chain order.
+type ChainEntry struct {
+	File  string
+	Event *Event
+}
+
+// OrderChain arranges events by following previous_hash links instead of
+// trusting file names. The chain starts at the genesis event, or, for a
+// directory holding only the tail of a chain, at the single event whose
+// predecessor is absent (IsGenesis tells the two apart). Every event hash is
+// recomputed and every link is checked with VerifyChain; forks and events off
+// the chain are errors.
+func OrderChain(events map[string]*Event) ([]ChainEntry, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	files := make([]string, 0, len(events))
+	for file := range events {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	byHash := make(map[string]string, len(events))
+	successors := make(map[string][]string, len(events))
+	for _, file := range files {
+		event := events[file]
+		if !Verify(event) {
+			return nil, fmt.Errorf("%s: event_hash does not match its content", file)
+		}
+		if other, dup := byHash[event.EventHash]; dup {
+			return nil, fmt.Errorf("%s and %s carry the same event_hash", other, file)
+		}
+		byHash[event.EventHash] = file
+		successors[event.PreviousHash] = append(successors[event.PreviousHash], file)
+	}
+
+	var heads []string
+	for _, file := range files {
+		event := events[file]
+		if _, linked := byHash[event.PreviousHash]; !linked {
+			heads = append(heads, file)
+		}
+	}
+	if len(heads) != 1 {
+		return nil, fmt.Errorf("expected one chain start, found %d: %v", len(heads), heads)
+	}
+
+	chain := []ChainEntry{{File: heads[0], Event: events[heads[0]]}}
+	for {
+		previous := chain[len(chain)-1]
+		next := successors[previous.Event.EventHash]
+		if len(next) == 0 {
+			break
+		}
+		if len(next) > 1 {
+			return nil, fmt.Errorf("chain forks after %s: %v", previous.File, next)
+		}
+		current := events[next[0]]
+		if !VerifyChain(current, previous.Event) {
+			return nil, fmt.Errorf("%s does not link to %s", next[0], previous.File)
+		}
+		chain = append(chain, ChainEntry{File: next[0], Event: current})
+	}
+
+	if len(chain) != len(events) {
+		return nil, fmt.Errorf("%d of %d events are not on the chain", len(events)-len(chain), len(events))
+	}
+	return chain, nil
+}
+
+// Mismatch is a difference between an event and the lake.
+type Mismatch struct {
+	File   string
+	Table  string
+	Reason string
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: %s: %s", m.File, m.Table, m.Reason)
+}
+
+// DigestSource recomputes table digests; LakeReader is the DuckLake
+// implementation.
+type DigestSource interface {
+	TableDigest(ctx context.Context, table string, ledgerStart, ledgerEnd uint32, eraID string) (TableDigest, error)
+}
+
+// VerifyLake recomputes the row digests of every event emitted with
+// DigestScheme and compares them with the recorded checksums. Tables the
+// event does not list must have no rows in its ledger range. Events without
+// digests are skipped and counted.
+func VerifyLake(ctx context.Context, chain []ChainEntry, source DigestSource) (mismatches []Mismatch, skipped int, err error) {
+	tables := make([]string, 0, len(DigestTables))
+	for table := range DigestTables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, entry := range chain {
+		batch := entry.Event.Batch
+		if batch.DigestScheme == "" {
+			skipped++
+			continue
+		}
+		if batch.DigestScheme != DigestScheme {
+			mismatches = append(mismatches, Mismatch{entry.File, "*", fmt.Sprintf("unsupported digest scheme %q", batch.DigestScheme)})
+			continue
+		}
+
+		recorded := make(map[string]TableSummary, len(batch.Tables))
+		for _, summary := range batch.Tables {
+			recorded[summary.Name] = summary
+			if _, known := DigestTables[summary.Name]; !known {
+				mismatches = append(mismatches, Mismatch{entry.File, summary.Name, "table has no digest definition"})
+			}
+		}
+
+		for _, table := range tables {
+			digest, err := source.TableDigest(ctx, table, batch.LedgerStart, batch.LedgerEnd, entry.Event.Producer.EraID)
+			if err != nil {
+				return mismatches, skipped, fmt.Errorf("%s: %w", entry.File, err)
+			}
+
+			summary, listed := recorded[table]
+			switch {
+			case !listed && digest.RowCount > 0:
+				mismatches = append(mismatches, Mismatch{entry.File, table,
+					fmt.Sprintf("lake has %d rows the event does not record", digest.RowCount)})
+			case listed && digest.RowCount != summary.RowCount:
+				mismatches = append(mismatches, Mismatch{entry.File, table,
+					fmt.Sprintf("row count %d, event records %d", digest.RowCount, summary.RowCount)})
+			case listed && digest.Root != summary.Checksum:
+				mismatches = append(mismatches, Mismatch{entry.File, table,
+					fmt.Sprintf("digest %s, event records %s", digest.Root, summary.Checksum)})
+			}
+		}
+	}
+
+	return mismatches, skipped, nil
+}
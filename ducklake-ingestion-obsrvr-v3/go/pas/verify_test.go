@@ -0,0 +1,270 @@
+package pas
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testProducer() Producer {
+	return Producer{ID: "ducklake-ingestion-obsrvr-test", Version: "3.0.0", Network: "testnet", EraID: "p23_plus"}
+}
+
+// testChain emits n consecutive batches of ten ledgers starting at ledger 100
+func testChain(n int, tables func(i int) []TableSummary) map[string]*Event {
+	events := make(map[string]*Event)
+	previous := GenesisHash
+	for i := 0; i < n; i++ {
+		event := NewEvent(previous, testProducer(), BatchInfo{
+			LedgerStart:  uint32(100 + 10*i),
+			LedgerEnd:    uint32(109 + 10*i),
+			LedgerCount:  10,
+			Tables:       tables(i),
+			DigestScheme: DigestScheme,
+		})
+		event.EventHash = ComputeEventHash(event)
+		events[fmt.Sprintf("pas_%d_%d_x.json", event.Batch.LedgerStart, event.Batch.LedgerEnd)] = event
+		previous = event.EventHash
+	}
+	return events
+}
+
+func noTables(int) []TableSummary { return nil }
+
+func TestRowHash(t *testing.T) {
+	closed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	base := RowHash(
+		[]string{"sequence", "ledger_hash", "closed_at", "ingestion_timestamp"},
+		[]any{int64(100), "abc", closed, time.Now()},
+	)
+
+	tests := []struct {
+		name    string
+		columns []string
+		values  []any
+		same    bool
+	}{
+		{
+			name:    "column order and wall-clock columns do not matter",
+			columns: []string{"ingestion_timestamp", "closed_at", "ledger_hash", "sequence"},
+			values:  []any{time.Now().Add(time.Hour), closed.In(time.FixedZone("x", 3600)), "abc", int64(100)},
+			same:    true,
+		},
+		{
+			name:    "NULL columns are skipped",
+			columns: []string{"sequence", "ledger_hash", "closed_at", "memo"},
+			values:  []any{int64(100), "abc", closed, nil},
+			same:    true,
+		},
+		{
+			name:    "changed value",
+			columns: []string{"sequence", "ledger_hash", "closed_at"},
+			values:  []any{int64(100), "abd", closed},
+			same:    false,
+		},
+		{
+			name:    "value moved to another column",
+			columns: []string{"sequence", "previous_ledger_hash", "closed_at"},
+			values:  []any{int64(100), "abc", closed},
+			same:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RowHash(tt.columns, tt.values)
+			if (got == base) != tt.same {
+				t.Errorf("hash equal to base = %v, want %v", got == base, tt.same)
+			}
+		})
+	}
+}
+
+func TestMerkleRoot(t *testing.T) {
+	a := RowHash([]string{"id"}, []any{1})
+	b := RowHash([]string{"id"}, []any{2})
+	c := RowHash([]string{"id"}, []any{3})
+
+	if got := MerkleRoot(nil); got != "" {
+		t.Errorf("empty root = %q, want empty", got)
+	}
+	if got := MerkleRoot([][32]byte{a, b, c}); got != MerkleRoot([][32]byte{c, a, b}) {
+		t.Errorf("root depends on row order")
+	}
+	if MerkleRoot([][32]byte{a, b}) == MerkleRoot([][32]byte{a, b, b}) {
+		t.Errorf("duplicate row did not change the root")
+	}
+	if MerkleRoot([][32]byte{a}) == MerkleRoot([][32]byte{b}) {
+		t.Errorf("different single rows share a root")
+	}
+}
+
+func TestComputeEventHashIgnoresWallClock(t *testing.T) {
+	batch := BatchInfo{LedgerStart: 100, LedgerEnd: 109, LedgerCount: 10, TotalRows: 5, DigestScheme: DigestScheme}
+
+	first := NewEvent(GenesisHash, testProducer(), batch)
+	first.Batch.ProcessingDurationMs = 120
+	first.Batch.ManifestHash = "aaaa"
+
+	second := NewEvent(GenesisHash, testProducer(), batch)
+	second.Timestamp = first.Timestamp.Add(time.Hour)
+	second.Batch.ProcessingDurationMs = 4000
+	second.Batch.ManifestHash = "bbbb"
+
+	if ComputeEventHash(first) != ComputeEventHash(second) {
+		t.Errorf("1.2 event hash depends on emission time")
+	}
+
+	first.Version, second.Version = EventVersionLegacy, EventVersionLegacy
+	if ComputeEventHash(first) == ComputeEventHash(second) {
+		t.Errorf("1.1 event hash no longer covers the timestamp")
+	}
+}
+
+func TestOrderChain(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  func() map[string]*Event
+		want    []uint32 // ledger starts in chain order
+		wantErr string
+	}{
+		{
+			name: "follows links rather than file names",
+			events: func() map[string]*Event {
+				// Name files so that they sort in reverse chain order
+				renamed := make(map[string]*Event)
+				for _, event := range testChain(3, noTables) {
+					renamed[fmt.Sprintf("pas_%d.json", 1000-event.Batch.LedgerStart)] = event
+				}
+				return renamed
+			},
+			want: []uint32{100, 110, 120},
+		},
+		{
+			name: "tail of a chain",
+			events: func() map[string]*Event {
+				events := testChain(3, noTables)
+				delete(events, "pas_100_109_x.json")
+				return events
+			},
+			want: []uint32{110, 120},
+		},
+		{
+			name: "tampered event",
+			events: func() map[string]*Event {
+				events := testChain(2, noTables)
+				events["pas_110_119_x.json"].Batch.TotalRows = 99
+				return events
+			},
+			wantErr: "event_hash does not match",
+		},
+		{
+			name: "fork",
+			events: func() map[string]*Event {
+				events := testChain(2, noTables)
+				fork := NewEvent(events["pas_100_109_x.json"].EventHash, testProducer(), BatchInfo{LedgerStart: 110, LedgerEnd: 115})
+				fork.EventHash = ComputeEventHash(fork)
+				events["pas_110_115_x.json"] = fork
+				return events
+			},
+			wantErr: "chain forks",
+		},
+		{
+			name: "gap",
+			events: func() map[string]*Event {
+				events := testChain(3, noTables)
+				delete(events, "pas_110_119_x.json")
+				return events
+			},
+			wantErr: "expected one chain start, found 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := OrderChain(tt.events())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OrderChain: %v", err)
+			}
+			var got []uint32
+			for _, entry := range chain {
+				got = append(got, entry.Event.Batch.LedgerStart)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("chain = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeLake serves table digests keyed by "table@ledgerStart"
+type fakeLake map[string]TableDigest
+
+func (f fakeLake) TableDigest(_ context.Context, table string, ledgerStart, _ uint32, _ string) (TableDigest, error) {
+	return f[fmt.Sprintf("%s@%d", table, ledgerStart)], nil
+}
+
+func TestVerifyLake(t *testing.T) {
+	ledgers := TableDigest{RowCount: 10, Root: MerkleRoot([][32]byte{RowHash([]string{"sequence"}, []any{100})})}
+	recorded := func(int) []TableSummary {
+		return []TableSummary{{Name: "ledgers_row_v2", RowCount: ledgers.RowCount, Checksum: ledgers.Root}}
+	}
+
+	tests := []struct {
+		name string
+		lake fakeLake
+		want []string
+	}{
+		{
+			name: "lake matches",
+			lake: fakeLake{"ledgers_row_v2@100": ledgers, "ledgers_row_v2@110": ledgers},
+		},
+		{
+			name: "row rewritten",
+			lake: fakeLake{"ledgers_row_v2@100": ledgers, "ledgers_row_v2@110": {RowCount: 10, Root: "ff"}},
+			want: []string{"pas_110_119_x.json: ledgers_row_v2: digest ff"},
+		},
+		{
+			name: "row deleted",
+			lake: fakeLake{"ledgers_row_v2@100": {RowCount: 9, Root: ledgers.Root}, "ledgers_row_v2@110": ledgers},
+			want: []string{"pas_100_109_x.json: ledgers_row_v2: row count 9, event records 10"},
+		},
+		{
+			name: "unrecorded rows",
+			lake: fakeLake{"ledgers_row_v2@100": ledgers, "ledgers_row_v2@110": ledgers, "trades_row_v1@110": {RowCount: 2, Root: "ee"}},
+			want: []string{"pas_110_119_x.json: trades_row_v1: lake has 2 rows"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := OrderChain(testChain(2, recorded))
+			if err != nil {
+				t.Fatalf("OrderChain: %v", err)
+			}
+			mismatches, skipped, err := VerifyLake(context.Background(), chain, tt.lake)
+			if err != nil {
+				t.Fatalf("VerifyLake: %v", err)
+			}
+			if skipped != 0 {
+				t.Errorf("skipped = %d, want 0", skipped)
+			}
+			if len(mismatches) != len(tt.want) {
+				t.Fatalf("mismatches = %v, want %d", mismatches, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(mismatches[i].String(), want) {
+					t.Errorf("mismatch %d = %q, want prefix %q", i, mismatches[i], want)
+				}
+			}
+		})
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/internal/sqlguard"
 )
 
 // QueryRequest represents an incoming SQL query request
@@ -135,7 +137,7 @@ func (qs *QueryServer) handleQuery(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate SQL
-	if err := qs.validateSQL(req.SQL); err != nil {
+	if err := sqlguard.ValidateNoFiles(req.SQL); err != nil {
 		qs.sendError(w, fmt.Sprintf("Invalid SQL: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -257,45 +259,6 @@ func (qs *QueryServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "ducklake_db_wait_duration_seconds %.3f\n", stats.WaitDuration.Seconds())
 }
 
-// validateSQL performs basic SQL validation to prevent dangerous operations
-func (qs *QueryServer) validateSQL(sql string) error {
-	sqlUpper := strings.ToUpper(strings.TrimSpace(sql))
-
-	// Prevent write operations
-	dangerousKeywords := []string{
-		"DROP",
-		"DELETE",
-		"TRUNCATE",
-		"INSERT",
-		"UPDATE",
-		"ALTER",
-		"CREATE",
-		"GRANT",
-		"REVOKE",
-	}
-
-	for _, keyword := range dangerousKeywords {
-		if strings.Contains(sqlUpper, keyword) {
-			return fmt.Errorf("operation not allowed: %s", keyword)
-		}
-	}
-
-	// Require SELECT for read queries
-	if !strings.HasPrefix(sqlUpper, "SELECT") &&
-		!strings.HasPrefix(sqlUpper, "WITH") &&
-		!strings.HasPrefix(sqlUpper, "SHOW") &&
-		!strings.HasPrefix(sqlUpper, "DESCRIBE") &&
-		!strings.HasPrefix(sqlUpper, "EXPLAIN") {
-		return fmt.Errorf("only SELECT, WITH, SHOW, DESCRIBE, and EXPLAIN queries are allowed")
-	}
-
-	if len(sql) == 0 {
-		return fmt.Errorf("empty query")
-	}
-
-	return nil
-}
-
 // sendError sends a JSON error response
 func (qs *QueryServer) sendError(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
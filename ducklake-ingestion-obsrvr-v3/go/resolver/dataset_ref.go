@@ -0,0 +1,193 @@
+// Package resolver provides Bronze layer data resolution and routing.
+// Dataset references let SQL name an era-routed dataset instead of a table.
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// datasetRefPattern matches dataset('name'[, 'key=value' ...]) in a query
+var datasetRefPattern = regexp.MustCompile(`(?i)\bdataset\s*\(\s*'([^']*)'((?:\s*,\s*'[^']*')*)\s*\)`)
+
+// datasetRefOptionPattern matches one quoted option of a dataset reference
+var datasetRefOptionPattern = regexp.MustCompile(`'([^']*)'`)
+
+// DatasetRef is a dataset('name', 'key=value', ...) reference found in a
+// query. Options map onto Intent fields:
+//
+//	network=testnet     Intent.Network
+//	mode=latest         Intent.Mode (inferred from the options below if absent)
+//	ledger=500000       as_of_ledger
+//	protocol=23         as_of_protocol
+//	era=p23_plus        explicit, with optional version=v1
+//	range=1000-2000     Intent.Range
+//	strict_pas=true     Intent.StrictPAS
+//
+// Without options a reference resolves to the latest era of the default
+// network.
+type DatasetRef struct {
+	// Start and End are the byte offsets of the reference in the query
+	Start int
+	End   int
+
+	// Dataset name (e.g., "core.ledgers_row_v2")
+	Dataset string
+
+	// Intent built from the reference's options
+	Intent Intent
+}
+
+// ParseDatasetRefs returns the dataset references in query, in order.
+func ParseDatasetRefs(query string) ([]DatasetRef, error) {
+	var refs []DatasetRef
+	for _, m := range datasetRefPattern.FindAllStringSubmatchIndex(query, -1) {
+		ref := DatasetRef{
+			Start:   m[0],
+			End:     m[1],
+			Dataset: query[m[2]:m[3]],
+		}
+		if ref.Dataset == "" {
+			return nil, fmt.Errorf("dataset reference at offset %d has no dataset name", m[0])
+		}
+
+		var options []string
+		for _, opt := range datasetRefOptionPattern.FindAllStringSubmatch(query[m[4]:m[5]], -1) {
+			options = append(options, opt[1])
+		}
+		intent, err := ParseIntent(options)
+		if err != nil {
+			return nil, fmt.Errorf("dataset('%s'): %w", ref.Dataset, err)
+		}
+		ref.Intent = intent
+
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// ErrUnknownNetwork is returned by ExpandDatasetRefs for a reference to a
+// network that is not served.
+var ErrUnknownNetwork = errors.New("unknown network")
+
+// ExpandDatasetRefs replaces each dataset reference in query with the SQL
+// expand returns for it, in parentheses so it stands in for a table. A
+// reference without a network option gets the only network in networks;
+// with several networks it must name one. Errors from expand are returned
+// as they are.
+func ExpandDatasetRefs(query string, networks []string, expand func(DatasetRef) (string, error)) (string, error) {
+	refs, err := ParseDatasetRefs(query)
+	if err != nil {
+		return "", err
+	}
+	if len(refs) == 0 {
+		return query, nil
+	}
+
+	var expanded strings.Builder
+	last := 0
+	for _, ref := range refs {
+		if ref.Intent.Network == "" {
+			if len(networks) != 1 {
+				return "", fmt.Errorf("dataset('%s') needs a 'network=' option (serving %v)", ref.Dataset, networks)
+			}
+			ref.Intent.Network = networks[0]
+		}
+		if !slices.Contains(networks, ref.Intent.Network) {
+			return "", fmt.Errorf("dataset('%s'): %w %q", ref.Dataset, ErrUnknownNetwork, ref.Intent.Network)
+		}
+
+		datasetSQL, err := expand(ref)
+		if err != nil {
+			return "", err
+		}
+
+		expanded.WriteString(query[last:ref.Start])
+		expanded.WriteString("(" + datasetSQL + ")")
+		last = ref.End
+	}
+	expanded.WriteString(query[last:])
+
+	return expanded.String(), nil
+}
+
+// ParseIntent builds an Intent from key=value options, using the same keys
+// and mode inference as dataset references.
+func ParseIntent(options []string) (Intent, error) {
+	var intent Intent
+	var inferred []IntentMode
+
+	for _, opt := range options {
+		key, value, ok := strings.Cut(opt, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return Intent{}, fmt.Errorf("option %q is not key=value", opt)
+		}
+
+		switch strings.ToLower(key) {
+		case "network":
+			intent.Network = value
+		case "mode":
+			intent.Mode = IntentMode(strings.ToLower(value))
+		case "ledger":
+			ledger, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return Intent{}, fmt.Errorf("ledger: %q is not a ledger sequence", value)
+			}
+			l := uint32(ledger)
+			intent.Ledger = &l
+			inferred = append(inferred, IntentAsOfLedger)
+		case "protocol":
+			protocol, err := strconv.Atoi(value)
+			if err != nil {
+				return Intent{}, fmt.Errorf("protocol: %q is not a protocol version", value)
+			}
+			intent.Protocol = &protocol
+			inferred = append(inferred, IntentAsOfProtocol)
+		case "era":
+			intent.EraID = value
+			inferred = append(inferred, IntentExplicit)
+		case "version":
+			intent.VersionLabel = value
+		case "range":
+			start, end, ok := strings.Cut(value, "-")
+			s, errStart := strconv.ParseUint(strings.TrimSpace(start), 10, 32)
+			e, errEnd := strconv.ParseUint(strings.TrimSpace(end), 10, 32)
+			if !ok || errStart != nil || errEnd != nil || s > e {
+				return Intent{}, fmt.Errorf("range: %q is not START-END", value)
+			}
+			intent.Range = &LedgerRange{Start: uint32(s), End: uint32(e)}
+		case "strict_pas":
+			strict, err := strconv.ParseBool(value)
+			if err != nil {
+				return Intent{}, fmt.Errorf("strict_pas: %q is not a boolean", value)
+			}
+			intent.StrictPAS = strict
+		default:
+			return Intent{}, fmt.Errorf("unknown option %q", key)
+		}
+	}
+
+	if intent.Mode == "" {
+		switch len(inferred) {
+		case 0:
+			intent.Mode = IntentLatest
+		case 1:
+			intent.Mode = inferred[0]
+		default:
+			return Intent{}, fmt.Errorf("ledger, protocol and era are mutually exclusive without an explicit mode")
+		}
+	}
+
+	switch intent.Mode {
+	case IntentLatest, IntentAsOfLedger, IntentAsOfProtocol, IntentExplicit:
+	default:
+		return Intent{}, fmt.Errorf("unknown mode %q", intent.Mode)
+	}
+
+	return intent, nil
+}
@@ -0,0 +1,211 @@
+package resolver
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseDatasetRefs(t *testing.T) {
+	query := "SELECT l.sequence, t.tx_hash FROM dataset('core.ledgers_row_v2') l " +
+		"JOIN DATASET( 'core.transactions_row_v2', 'network=testnet', 'ledger=500000' ) t ON l.sequence = t.ledger_sequence"
+
+	refs, err := ParseDatasetRefs(query)
+	if err != nil {
+		t.Fatalf("ParseDatasetRefs failed: %v", err)
+	}
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 refs, got %d", len(refs))
+	}
+
+	if refs[0].Dataset != "core.ledgers_row_v2" || refs[0].Intent.Mode != IntentLatest {
+		t.Errorf("first ref = %+v, want latest core.ledgers_row_v2", refs[0])
+	}
+	if got := query[refs[0].Start:refs[0].End]; got != "dataset('core.ledgers_row_v2')" {
+		t.Errorf("first ref spans %q", got)
+	}
+
+	second := refs[1]
+	if second.Dataset != "core.transactions_row_v2" {
+		t.Errorf("second dataset = %q", second.Dataset)
+	}
+	if second.Intent.Mode != IntentAsOfLedger || second.Intent.Ledger == nil || *second.Intent.Ledger != 500000 {
+		t.Errorf("second intent = %+v, want as_of_ledger 500000", second.Intent)
+	}
+	if second.Intent.Network != "testnet" {
+		t.Errorf("second network = %q, want testnet", second.Intent.Network)
+	}
+	if !strings.HasPrefix(query[second.Start:second.End], "DATASET(") {
+		t.Errorf("second ref spans %q", query[second.Start:second.End])
+	}
+}
+
+func TestParseDatasetRefs_Options(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		check   func(Intent) bool
+		wantErr string
+	}{
+		{
+			name: "explicit era",
+			ref:  "dataset('core.ledgers_row_v2', 'era=p23_plus', 'version=v2')",
+			check: func(i Intent) bool {
+				return i.Mode == IntentExplicit && i.EraID == "p23_plus" && i.VersionLabel == "v2"
+			},
+		},
+		{
+			name: "protocol with range",
+			ref:  "dataset('core.ledgers_row_v2', 'protocol=23', 'range=100-200', 'strict_pas=true')",
+			check: func(i Intent) bool {
+				return i.Mode == IntentAsOfProtocol && *i.Protocol == 23 && i.Range.End == 200 && i.StrictPAS
+			},
+		},
+		{
+			name:  "explicit mode wins over inference",
+			ref:   "dataset('core.ledgers_row_v2', 'mode=as_of_ledger', 'ledger=5', 'era=p23_plus')",
+			check: func(i Intent) bool { return i.Mode == IntentAsOfLedger },
+		},
+		{
+			name:    "conflicting options",
+			ref:     "dataset('core.ledgers_row_v2', 'ledger=5', 'protocol=23')",
+			wantErr: "mutually exclusive",
+		},
+		{
+			name:    "unknown option",
+			ref:     "dataset('core.ledgers_row_v2', 'as_of=5')",
+			wantErr: "unknown option",
+		},
+		{
+			name:    "inverted range",
+			ref:     "dataset('core.ledgers_row_v2', 'range=200-100')",
+			wantErr: "not START-END",
+		},
+		{
+			name:    "unknown mode",
+			ref:     "dataset('core.ledgers_row_v2', 'mode=oldest')",
+			wantErr: "unknown mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, err := ParseDatasetRefs("SELECT * FROM " + tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDatasetRefs failed: %v", err)
+			}
+			if len(refs) != 1 || !tt.check(refs[0].Intent) {
+				t.Errorf("unexpected refs: %+v", refs)
+			}
+		})
+	}
+}
+
+func TestParseDatasetRefs_NoRefs(t *testing.T) {
+	refs, err := ParseDatasetRefs("SELECT dataset_name FROM testnet._meta_datasets")
+	if err != nil || len(refs) != 0 {
+		t.Errorf("refs = %+v, err = %v; want none", refs, err)
+	}
+}
+
+func TestExpandDatasetRefs(t *testing.T) {
+	expand := func(ref DatasetRef) (string, error) {
+		return ref.Intent.Network + ":" + ref.Dataset, nil
+	}
+	testnet := []string{"testnet"}
+	both := []string{"testnet", "mainnet"}
+
+	tests := []struct {
+		name     string
+		query    string
+		networks []string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "no references",
+			query:    "SELECT * FROM testnet.ledgers_row_v2",
+			networks: both,
+			want:     "SELECT * FROM testnet.ledgers_row_v2",
+		},
+		{
+			name:     "single network is the default",
+			query:    "SELECT * FROM dataset('core.ledgers_row_v2') l",
+			networks: testnet,
+			want:     "SELECT * FROM (testnet:core.ledgers_row_v2) l",
+		},
+		{
+			name:     "several networks need an option",
+			query:    "SELECT * FROM dataset('core.ledgers_row_v2')",
+			networks: both,
+			wantErr:  "needs a 'network=' option",
+		},
+		{
+			name:     "network option picks one of several",
+			query:    "SELECT * FROM dataset('core.ledgers_row_v2', 'network=mainnet')",
+			networks: both,
+			want:     "SELECT * FROM (mainnet:core.ledgers_row_v2)",
+		},
+		{
+			name:     "unknown network",
+			query:    "SELECT * FROM dataset('core.ledgers_row_v2', 'network=futurenet')",
+			networks: both,
+			wantErr:  `unknown network "futurenet"`,
+		},
+		{
+			name: "several references keep the text between them",
+			query: "SELECT l.sequence FROM dataset('core.ledgers_row_v2', 'network=testnet') l " +
+				"JOIN DATASET( 'core.transactions_row_v2', 'network=mainnet' ) t ON l.sequence = t.ledger_sequence " +
+				"WHERE l.sequence IN (SELECT ledger_sequence FROM dataset('core.operations_row_v2', 'network=testnet'))",
+			networks: both,
+			want: "SELECT l.sequence FROM (testnet:core.ledgers_row_v2) l " +
+				"JOIN (mainnet:core.transactions_row_v2) t ON l.sequence = t.ledger_sequence " +
+				"WHERE l.sequence IN (SELECT ledger_sequence FROM (testnet:core.operations_row_v2))",
+		},
+		{
+			name:     "invalid reference",
+			query:    "SELECT * FROM dataset('core.ledgers_row_v2', 'ledger=x')",
+			networks: testnet,
+			wantErr:  "not a ledger sequence",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandDatasetRefs(tt.query, tt.networks, expand)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandDatasetRefs failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expanded to\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandDatasetRefs_Errors(t *testing.T) {
+	_, err := ExpandDatasetRefs("SELECT * FROM dataset('core.ledgers_row_v2', 'network=futurenet')", []string{"testnet"},
+		func(DatasetRef) (string, error) { return "", nil })
+	if !errors.Is(err, ErrUnknownNetwork) {
+		t.Errorf("err = %v, want ErrUnknownNetwork", err)
+	}
+
+	expandErr := errors.New("no era covers the range")
+	_, err = ExpandDatasetRefs("SELECT * FROM dataset('core.ledgers_row_v2')", []string{"testnet"},
+		func(DatasetRef) (string, error) { return "", expandErr })
+	if err != expandErr {
+		t.Errorf("err = %v, want the expand error unchanged", err)
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/resolver"
+)
+
+// Manifest is a read manifest pinned to a DuckLake snapshot and signed by the
+// service that issued it. ID is content-addressed and Signature is an
+// HMAC-SHA256 over the same bytes, so a manifest handed back by a client can
+// be checked without trusting the store it came from.
+type Manifest struct {
+	ID           string    `json:"id"`
+	Network      string    `json:"network"`
+	Dataset      string    `json:"dataset"`
+	EraID        string    `json:"era_id"`
+	VersionLabel string    `json:"version_label"`
+	Snapshot     int64     `json:"snapshot"`
+	LedgerStart  uint32    `json:"ledger_start"`
+	LedgerEnd    uint32    `json:"ledger_end"`
+	Files        []File    `json:"files"`
+	TotalRows    int64     `json:"total_rows"`
+	PASVerified  bool      `json:"pas_verified"`
+	Checksum     string    `json:"checksum"`
+	IssuedAt     time.Time `json:"issued_at"`
+	Signature    string    `json:"signature"`
+}
+
+// File is one Parquet file of a pinned manifest
+type File struct {
+	Path       string `json:"path"`
+	Bytes      int64  `json:"bytes"`
+	DeleteFile string `json:"delete_file,omitempty"`
+}
+
+// newManifest builds an unsigned manifest from a resolution pinned with
+// resolver.PinManifest
+func newManifest(resolved *resolver.ResolvedDataset) *Manifest {
+	rm := resolved.Manifest
+	m := &Manifest{
+		Network:      resolved.Network,
+		Dataset:      resolved.Dataset,
+		EraID:        resolved.EraID,
+		VersionLabel: resolved.VersionLabel,
+		Snapshot:     rm.LakeSnapshot,
+		LedgerStart:  rm.LedgerStart,
+		LedgerEnd:    rm.LedgerEnd,
+		Files:        make([]File, len(rm.Files)),
+		TotalRows:    rm.TotalRows,
+		PASVerified:  resolved.PASVerified,
+		Checksum:     rm.Checksum,
+		IssuedAt:     time.Now().UTC(),
+	}
+	for i, f := range rm.Files {
+		m.Files[i] = File{Path: f.Path, Bytes: f.Bytes, DeleteFile: f.DeleteFile}
+	}
+	return m
+}
+
+// resolved rebuilds the resolution a manifest was issued for, for SQL
+// generation
+func (m *Manifest) resolved() *resolver.ResolvedDataset {
+	return &resolver.ResolvedDataset{
+		Dataset:      m.Dataset,
+		Network:      m.Network,
+		EraID:        m.EraID,
+		VersionLabel: m.VersionLabel,
+		Manifest: &resolver.ReadManifest{
+			Dataset:      m.Dataset,
+			EraID:        m.EraID,
+			LakeSnapshot: m.Snapshot,
+			LedgerStart:  m.LedgerStart,
+			LedgerEnd:    m.LedgerEnd,
+			TotalRows:    m.TotalRows,
+			Checksum:     m.Checksum,
+		},
+	}
+}
+
+// sameFiles reports whether files lists exactly the manifest's files
+func (m *Manifest) sameFiles(files []resolver.ManifestFile) bool {
+	if len(files) != len(m.Files) {
+		return false
+	}
+	for i, f := range files {
+		if m.Files[i] != (File{Path: f.Path, Bytes: f.Bytes, DeleteFile: f.DeleteFile}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Signer issues and checks manifest IDs and signatures
+type Signer struct {
+	key []byte
+}
+
+// NewSigner creates a signer; the key must be at least 32 bytes
+func NewSigner(key []byte) (*Signer, error) {
+	if len(key) < 32 {
+		return nil, fmt.Errorf("signing key must be at least 32 bytes, got %d", len(key))
+	}
+	return &Signer{key: key}, nil
+}
+
+// Sign sets the manifest's ID and Signature from its other fields
+func (s *Signer) Sign(m *Manifest) error {
+	payload, err := signingPayload(m)
+	if err != nil {
+		return err
+	}
+	m.ID = manifestID(payload)
+	m.Signature = s.mac(payload)
+	return nil
+}
+
+// Verify checks that the manifest's ID and Signature match its other fields
+func (s *Signer) Verify(m *Manifest) error {
+	payload, err := signingPayload(m)
+	if err != nil {
+		return err
+	}
+	if m.ID != manifestID(payload) {
+		return fmt.Errorf("manifest %s: id does not match contents", m.ID)
+	}
+	if !hmac.Equal([]byte(m.Signature), []byte(s.mac(payload))) {
+		return fmt.Errorf("manifest %s: invalid signature", m.ID)
+	}
+	return nil
+}
+
+func (s *Signer) mac(payload []byte) string {
+	h := hmac.New(sha256.New, s.key)
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// signingPayload is the manifest's JSON encoding without ID and Signature
+func signingPayload(m *Manifest) ([]byte, error) {
+	unsigned := *m
+	unsigned.ID = ""
+	unsigned.Signature = ""
+	payload, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return payload, nil
+}
+
+// manifestID is the first 16 bytes of the payload's SHA-256, like resolver
+// manifest checksums
+func manifestID(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:16])
+}
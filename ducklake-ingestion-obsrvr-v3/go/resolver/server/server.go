@@ -0,0 +1,500 @@
+// Package server exposes the Bronze resolver over HTTP so that notebooks and
+// non-Go consumers get the same era routing, coverage and reproducible reads
+// as code linking the resolver package.
+//
+//	GET  /health
+//	GET  /v1/networks/{network}/datasets/{dataset}/coverage?range=START-END&...
+//	POST /v1/networks/{network}/datasets/{dataset}/manifests
+//	GET  /v1/manifests/{id}
+//	POST /v1/manifests/{id}/query
+//
+// Intent options (ledger, protocol, era, version, mode, range, strict_pas)
+// use the keys of dataset('name', 'key=value') references: query parameters
+// for coverage, a JSON object for manifests. A manifest pins the resolved
+// range to the current DuckLake snapshot and its Parquet file list; queries
+// against it read the table AT that snapshot, so they return the same rows
+// until the snapshot is expired.
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/internal/sqlguard"
+	"github.com/withObsrvr/ttp-processor-demo/ducklake-ingestion-obsrvr-v3/go/resolver"
+)
+
+const (
+	defaultQueryLimit = 1000
+	maxQueryLimit     = 10000
+)
+
+// Config holds configuration for the resolver server.
+type Config struct {
+	// DB is the DuckDB connection with the DuckLake catalog attached
+	DB *sql.DB
+
+	// CatalogName is the attached DuckLake catalog
+	CatalogName string
+
+	// Networks are the schemas served (e.g., "testnet", "mainnet")
+	Networks []string
+
+	// Signer signs issued manifests and verifies them before use
+	Signer *Signer
+
+	// Store keeps issued manifests for lookup by ID
+	Store *Store
+
+	// Addr is the listen address (e.g., ":8090")
+	Addr string
+}
+
+// Server serves resolver requests over HTTP.
+type Server struct {
+	db        *sql.DB
+	catalogs  []string
+	resolvers map[string]*resolver.Resolver
+	signer    *Signer
+	store     *Store
+	addr      string
+	router    *http.ServeMux
+	server    *http.Server
+}
+
+// LedgerRange is an inclusive ledger range
+type LedgerRange struct {
+	Start uint32 `json:"start"`
+	End   uint32 `json:"end"`
+}
+
+// CoverageResponse describes the committed ranges and gaps of a dataset in
+// the era an intent resolves to
+type CoverageResponse struct {
+	Network         string        `json:"network"`
+	Dataset         string        `json:"dataset"`
+	EraID           string        `json:"era_id"`
+	VersionLabel    string        `json:"version_label"`
+	Range           *LedgerRange  `json:"range,omitempty"`
+	CommittedRanges []LedgerRange `json:"committed_ranges"`
+	Gaps            []LedgerRange `json:"gaps"`
+	TailLedger      uint32        `json:"tail_ledger"`
+	LastVerified    uint32        `json:"last_verified"`
+	TotalRows       int64         `json:"total_rows"`
+}
+
+// QueryRequest is a query to replay against a manifest. The SQL reads the
+// manifest's rows from a relation named manifest; without SQL every row of
+// the manifest is returned in ledger order. It may not read anything else:
+// names qualified by the catalog, file-reading table functions and file
+// paths are rejected.
+type QueryRequest struct {
+	SQL   string `json:"sql,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// QueryResponse is the result of a replayed query
+type QueryResponse struct {
+	ManifestID      string          `json:"manifest_id"`
+	Snapshot        int64           `json:"snapshot"`
+	Columns         []string        `json:"columns"`
+	Rows            [][]interface{} `json:"rows"`
+	RowCount        int             `json:"row_count"`
+	ExecutionTimeMs int64           `json:"execution_time_ms"`
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// New creates a server with a resolver per network.
+func New(cfg Config) (*Server, error) {
+	if cfg.Signer == nil || cfg.Store == nil {
+		return nil, fmt.Errorf("signer and store are required")
+	}
+	if len(cfg.Networks) == 0 {
+		return nil, fmt.Errorf("at least one network is required")
+	}
+
+	s := &Server{
+		db:        cfg.DB,
+		catalogs:  []string{cfg.CatalogName, "__ducklake_metadata_" + cfg.CatalogName},
+		resolvers: make(map[string]*resolver.Resolver, len(cfg.Networks)),
+		signer:    cfg.Signer,
+		store:     cfg.Store,
+		addr:      cfg.Addr,
+		router:    http.NewServeMux(),
+	}
+
+	for _, network := range cfg.Networks {
+		r, err := resolver.New(resolver.Config{
+			DB:           cfg.DB,
+			CatalogName:  cfg.CatalogName,
+			SchemaName:   network,
+			CacheEnabled: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create resolver for %s: %w", network, err)
+		}
+		s.resolvers[network] = r
+	}
+
+	s.router.HandleFunc("GET /health", s.handleHealth)
+	s.router.HandleFunc("GET /v1/networks/{network}/datasets/{dataset}/coverage", s.handleCoverage)
+	s.router.HandleFunc("POST /v1/networks/{network}/datasets/{dataset}/manifests", s.handleIssueManifest)
+	s.router.HandleFunc("GET /v1/manifests/{id}", s.handleGetManifest)
+	s.router.HandleFunc("POST /v1/manifests/{id}/query", s.handleQuery)
+
+	return s, nil
+}
+
+// Handler returns the server's routes, for embedding or tests
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
+// Start starts the HTTP server and blocks until it is stopped
+func (s *Server) Start() error {
+	s.server = &http.Server{
+		Addr:         s.addr,
+		Handler:      s.router,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 90 * time.Second, // Longer to handle query execution
+		IdleTimeout:  120 * time.Second,
+	}
+
+	log.Printf("Resolver server starting on %s", s.addr)
+	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("resolver server error: %w", err)
+	}
+
+	return nil
+}
+
+// Stop gracefully shuts down the HTTP server
+func (s *Server) Stop(ctx context.Context) error {
+	if s.server != nil {
+		log.Printf("Shutting down resolver server...")
+		return s.server.Shutdown(ctx)
+	}
+	return nil
+}
+
+// handleHealth handles GET /health
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	response := map[string]interface{}{
+		"status":       "ok",
+		"db_connected": s.db.PingContext(ctx) == nil,
+	}
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+// handleCoverage handles GET /v1/networks/{network}/datasets/{dataset}/coverage
+func (s *Server) handleCoverage(w http.ResponseWriter, r *http.Request) {
+	res, ok := s.resolverFor(w, r)
+	if !ok {
+		return
+	}
+
+	var options []string
+	for key, values := range r.URL.Query() {
+		for _, value := range values {
+			options = append(options, key+"="+value)
+		}
+	}
+	intent, err := s.parseIntent(r, options)
+	if err != nil {
+		s.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Coverage never needs a manifest; the range only narrows the ranges reported
+	ledgerRange := intent.Range
+	intent.Range = nil
+
+	dataset := r.PathValue("dataset")
+	resolved, err := res.ResolveDataset(r.Context(), dataset, intent)
+	if err != nil {
+		s.sendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	coverage := &resolved.Coverage
+	if ledgerRange != nil {
+		coverage, err = res.GetCoverageForRange(r.Context(), dataset, resolved.EraID, resolved.VersionLabel, *ledgerRange)
+		if err != nil {
+			s.sendError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response := CoverageResponse{
+		Network:         resolved.Network,
+		Dataset:         dataset,
+		EraID:           resolved.EraID,
+		VersionLabel:    resolved.VersionLabel,
+		CommittedRanges: toLedgerRanges(coverage.CommittedRanges),
+		Gaps:            toLedgerRanges(coverage.Gaps),
+		TailLedger:      coverage.TailLedger,
+		LastVerified:    coverage.LastVerified,
+		TotalRows:       coverage.TotalRows,
+	}
+	if ledgerRange != nil {
+		response.Range = &LedgerRange{Start: ledgerRange.Start, End: ledgerRange.End}
+	}
+	s.sendJSON(w, http.StatusOK, response)
+}
+
+// handleIssueManifest handles POST /v1/networks/{network}/datasets/{dataset}/manifests
+func (s *Server) handleIssueManifest(w http.ResponseWriter, r *http.Request) {
+	res, ok := s.resolverFor(w, r)
+	if !ok {
+		return
+	}
+
+	// Options are a JSON object, e.g. {"ledger": 500000, "range": "500000-500999"}
+	var body map[string]interface{}
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		s.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	var options []string
+	for key, value := range body {
+		options = append(options, fmt.Sprintf("%s=%v", key, value))
+	}
+	intent, err := s.parseIntent(r, options)
+	if err != nil {
+		s.sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if intent.Range == nil {
+		s.sendError(w, "range is required to issue a manifest", http.StatusBadRequest)
+		return
+	}
+
+	dataset := r.PathValue("dataset")
+	resolved, err := res.ResolveDataset(r.Context(), dataset, intent)
+	if err != nil {
+		s.sendError(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err := res.PinManifest(r.Context(), resolved); err != nil {
+		s.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	manifest := newManifest(resolved)
+	if err := s.signer.Sign(manifest); err != nil {
+		s.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := s.store.Put(manifest); err != nil {
+		s.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Issued manifest %s: %s %s/%s ledgers %d-%d at snapshot %d (%d files)",
+		manifest.ID, manifest.Network, manifest.Dataset, manifest.EraID,
+		manifest.LedgerStart, manifest.LedgerEnd, manifest.Snapshot, len(manifest.Files))
+
+	s.sendJSON(w, http.StatusCreated, manifest)
+}
+
+// handleGetManifest handles GET /v1/manifests/{id}
+func (s *Server) handleGetManifest(w http.ResponseWriter, r *http.Request) {
+	manifest, ok := s.loadManifest(w, r)
+	if !ok {
+		return
+	}
+	s.sendJSON(w, http.StatusOK, manifest)
+}
+
+// handleQuery handles POST /v1/manifests/{id}/query
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	manifest, ok := s.loadManifest(w, r)
+	if !ok {
+		return
+	}
+
+	var req QueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.SQL == "" {
+		req.SQL = "SELECT * FROM manifest ORDER BY ledger_sequence"
+	}
+	// The replay must read only the pinned manifest relation: live catalog
+	// tables and files would change under a rerun
+	if err := sqlguard.ValidateSelfContained(req.SQL, s.catalogs); err != nil {
+		s.sendError(w, fmt.Sprintf("Invalid SQL: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultQueryLimit
+	}
+	if req.Limit > maxQueryLimit {
+		req.Limit = maxQueryLimit
+	}
+
+	res, ok := s.resolvers[manifest.Network]
+	if !ok {
+		s.sendError(w, fmt.Sprintf("manifest %s is for network %s, which this server does not serve", manifest.ID, manifest.Network), http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	// The snapshot must still list the pinned files; expiring or compacting
+	// it would silently change what the query reads
+	files, err := res.ListSnapshotFiles(ctx, manifest.Network, manifest.Dataset, manifest.Snapshot,
+		resolver.LedgerRange{Start: manifest.LedgerStart, End: manifest.LedgerEnd})
+	if err != nil {
+		s.sendError(w, err.Error(), http.StatusGone)
+		return
+	}
+	if !manifest.sameFiles(files) {
+		s.sendError(w, fmt.Sprintf("snapshot %d no longer lists the files of manifest %s", manifest.Snapshot, manifest.ID), http.StatusGone)
+		return
+	}
+
+	pinnedSQL, err := res.GenerateSQL(manifest.resolved(), resolver.SQLOptions{
+		IncludeVersionFilter: true,
+		Snapshot:             manifest.Snapshot,
+	})
+	if err != nil {
+		s.sendError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	query := fmt.Sprintf("WITH manifest AS (\n%s\n)\nSELECT * FROM (\n%s\n) AS replay\nLIMIT %d", pinnedSQL, req.SQL, req.Limit)
+
+	startTime := time.Now()
+	columns, rows, err := s.runQuery(ctx, query)
+	if err != nil {
+		s.sendError(w, fmt.Sprintf("Query execution failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, QueryResponse{
+		ManifestID:      manifest.ID,
+		Snapshot:        manifest.Snapshot,
+		Columns:         columns,
+		Rows:            rows,
+		RowCount:        len(rows),
+		ExecutionTimeMs: time.Since(startTime).Milliseconds(),
+	})
+}
+
+// resolverFor returns the resolver of the request's network, or writes a 404
+func (s *Server) resolverFor(w http.ResponseWriter, r *http.Request) (*resolver.Resolver, bool) {
+	network := r.PathValue("network")
+	res, ok := s.resolvers[network]
+	if !ok {
+		s.sendError(w, fmt.Sprintf("unknown network %q", network), http.StatusNotFound)
+	}
+	return res, ok
+}
+
+// parseIntent builds an intent for the request's network from key=value
+// options. The network comes from the path, not the options.
+func (s *Server) parseIntent(r *http.Request, options []string) (resolver.Intent, error) {
+	for _, opt := range options {
+		if key, _, _ := strings.Cut(opt, "="); strings.EqualFold(strings.TrimSpace(key), "network") {
+			return resolver.Intent{}, fmt.Errorf("network is set by the path, not an option")
+		}
+	}
+	intent, err := resolver.ParseIntent(options)
+	if err != nil {
+		return resolver.Intent{}, err
+	}
+	intent.Network = r.PathValue("network")
+	return intent, nil
+}
+
+// loadManifest reads and verifies the request's manifest, or writes an error
+func (s *Server) loadManifest(w http.ResponseWriter, r *http.Request) (*Manifest, bool) {
+	manifest, err := s.store.Get(r.PathValue("id"))
+	if errors.Is(err, ErrManifestNotFound) {
+		s.sendError(w, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		s.sendError(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if err := s.signer.Verify(manifest); err != nil {
+		s.sendError(w, err.Error(), http.StatusConflict)
+		return nil, false
+	}
+	return manifest, true
+}
+
+// runQuery executes a query and returns its rows as JSON-ready values
+func (s *Server) runQuery(ctx context.Context, query string) ([]string, [][]interface{}, error) {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resultRows := [][]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		valuePtrs := make([]interface{}, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, nil, err
+		}
+
+		// Convert byte arrays to strings for JSON serialization
+		for i, val := range values {
+			if b, ok := val.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		resultRows = append(resultRows, values)
+	}
+
+	return columns, resultRows, rows.Err()
+}
+
+func toLedgerRanges(ranges []resolver.LedgerRange) []LedgerRange {
+	out := make([]LedgerRange, len(ranges))
+	for i, lr := range ranges {
+		out[i] = LedgerRange{Start: lr.Start, End: lr.End}
+	}
+	return out
+}
+
+// sendJSON writes a JSON response
+func (s *Server) sendJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+// sendError sends a JSON error response
+func (s *Server) sendError(w http.ResponseWriter, message string, statusCode int) {
+	s.sendJSON(w, statusCode, ErrorResponse{Error: message})
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testManifest() *Manifest {
+	return &Manifest{
+		Network:      "testnet",
+		Dataset:      "core.ledgers_row_v2",
+		EraID:        "p23_plus",
+		VersionLabel: "v1",
+		Snapshot:     42,
+		LedgerStart:  40000,
+		LedgerEnd:    45000,
+		Files: []File{
+			{Path: "s3://lake/testnet/ledgers_row_v2/a.parquet", Bytes: 1024},
+			{Path: "s3://lake/testnet/ledgers_row_v2/b.parquet", Bytes: 2048, DeleteFile: "s3://lake/testnet/ledgers_row_v2/b-delete.parquet"},
+		},
+		TotalRows: 5001,
+		Checksum:  "0123456789abcdef0123456789abcdef",
+		IssuedAt:  time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+}
+
+func testSigner(t *testing.T) *Signer {
+	t.Helper()
+	signer, err := NewSigner([]byte(strings.Repeat("k", 32)))
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	return signer
+}
+
+func TestSigner_SignVerify(t *testing.T) {
+	signer := testSigner(t)
+
+	m := testManifest()
+	if err := signer.Sign(m); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if !manifestIDPattern.MatchString(m.ID) || m.Signature == "" {
+		t.Fatalf("Sign set id %q, signature %q", m.ID, m.Signature)
+	}
+	if err := signer.Verify(m); err != nil {
+		t.Fatalf("Verify failed on a freshly signed manifest: %v", err)
+	}
+
+	again := testManifest()
+	signer.Sign(again)
+	if again.ID != m.ID {
+		t.Errorf("same contents signed to ids %s and %s", m.ID, again.ID)
+	}
+
+	tampered := *m
+	tampered.Files = append([]File(nil), m.Files...)
+	tampered.Files[1].DeleteFile = ""
+	if err := signer.Verify(&tampered); err == nil || !strings.Contains(err.Error(), "id does not match") {
+		t.Errorf("Verify(tampered files) = %v, want id mismatch", err)
+	}
+
+	other, _ := NewSigner([]byte(strings.Repeat("x", 32)))
+	if err := other.Verify(m); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("Verify with another key = %v, want invalid signature", err)
+	}
+}
+
+func TestNewSigner_ShortKey(t *testing.T) {
+	if _, err := NewSigner([]byte("short")); err == nil {
+		t.Error("NewSigner accepted a 5-byte key")
+	}
+}
+
+func TestStore_RoundTrip(t *testing.T) {
+	signer := testSigner(t)
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	m := testManifest()
+	if err := store.Put(m); err == nil {
+		t.Error("Put accepted an unsigned manifest")
+	}
+
+	signer.Sign(m)
+	if err := store.Put(m); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	loaded, err := store.Get(m.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if err := signer.Verify(loaded); err != nil {
+		t.Errorf("stored manifest no longer verifies: %v", err)
+	}
+
+	for _, id := range []string{"00000000000000000000000000000000", "../" + m.ID, ""} {
+		if _, err := store.Get(id); !errors.Is(err, ErrManifestNotFound) {
+			t.Errorf("Get(%q) = %v, want ErrManifestNotFound", id, err)
+		}
+	}
+}
+
+// noConnector is a database that cannot be reached, so a request that gets
+// past validation fails when it first needs the catalog
+type noConnector struct{}
+
+func (noConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("no database in tests")
+}
+
+func (noConnector) Driver() driver.Driver { return nil }
+
+func TestHandleQuery_ValidatesSQL(t *testing.T) {
+	signer := testSigner(t)
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	m := testManifest()
+	signer.Sign(m)
+	if err := store.Put(m); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	s, err := New(Config{
+		DB:          sql.OpenDB(noConnector{}),
+		CatalogName: "lake",
+		Networks:    []string{"testnet"},
+		Signer:      signer,
+		Store:       store,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	tests := []struct {
+		sql     string
+		wantErr string
+	}{
+		{"", ""},
+		{"SELECT count(*) FROM manifest", ""},
+		{"with t AS (SELECT * FROM manifest) SELECT * FROM t", ""},
+		{"SELECT created_at, m.ledger_sequence FROM manifest m", ""},
+
+		{"DESCRIBE manifest", "only SELECT and WITH queries are allowed"},
+		{"SELECT 1; DROP TABLE x", "multiple statements"},
+		{"SELECT * FROM manifest; SELECT 1", "multiple statements"},
+		{"SELECT * FROM lake.testnet.ledgers_row_v2", "catalog lake"},
+		{`SELECT * FROM "lake".testnet.ledgers_row_v2`, "catalog lake"},
+		{"SELECT * FROM manifest JOIN __ducklake_metadata_lake.ducklake_snapshot USING (snapshot_id)", "catalog __ducklake_metadata_lake"},
+		{"SELECT * FROM read_parquet('s3://lake/testnet/ledgers_row_v2/a.parquet')", "table function not allowed: read_parquet"},
+		{"SELECT * FROM READ_CSV_AUTO ('x')", "table function not allowed: READ_CSV_AUTO"},
+		{"SELECT * FROM parquet_scan('x')", "table function not allowed: parquet_scan"},
+		{"SELECT * FROM glob('*')", "table function not allowed: glob"},
+		{"SELECT * FROM query_table('lake.testnet.ledgers_row_v2')", "table function not allowed: query_table"},
+		{"SELECT * FROM query('SELECT 1')", "table function not allowed: query"},
+		{"SELECT * FROM ducklake_snapshots('lake')", "table function not allowed: ducklake_snapshots"},
+		{"SELECT * FROM 'ledgers.parquet'", "file reference not allowed"},
+		{"SELECT * FROM manifest, '/etc/passwd'", "file reference not allowed"},
+		{"SELECT * FROM 'https://example.com/x'", "file reference not allowed"},
+	}
+
+	for _, tt := range tests {
+		body, _ := json.Marshal(QueryRequest{SQL: tt.sql})
+		req := httptest.NewRequest(http.MethodPost, "/v1/manifests/"+m.ID+"/query", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+
+		if tt.wantErr == "" {
+			// Valid SQL gets as far as checking the snapshot's files
+			if rec.Code != http.StatusGone {
+				t.Errorf("%q: status %d (%s), want %d", tt.sql, rec.Code, rec.Body, http.StatusGone)
+			}
+			continue
+		}
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tt.wantErr) {
+			t.Errorf("%q: status %d (%s), want %d containing %q", tt.sql, rec.Code, rec.Body, http.StatusBadRequest, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrManifestNotFound is returned by Store.Get for unknown manifest IDs
+var ErrManifestNotFound = errors.New("manifest not found")
+
+// manifestIDPattern matches IDs produced by manifestID
+var manifestIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Store keeps issued manifests as <id>.json files in a directory
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store, creating dir if needed
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Put writes a signed manifest
+func (s *Store) Put(m *Manifest) error {
+	if !manifestIDPattern.MatchString(m.ID) {
+		return fmt.Errorf("manifest is not signed")
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	// Atomic write: temp file + rename
+	path := filepath.Join(s.dir, m.ID+".json")
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath) // Clean up on failure
+		return fmt.Errorf("failed to rename manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Get reads a manifest by ID. The caller verifies its signature.
+func (s *Store) Get(id string) (*Manifest, error) {
+	if !manifestIDPattern.MatchString(id) {
+		return nil, ErrManifestNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrManifestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", id, err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", id, err)
+	}
+	return &m, nil
+}
@@ -0,0 +1,178 @@
+// Package resolver provides Bronze layer data resolution and routing.
+// Snapshot pinning ties a read manifest to the DuckLake snapshot it was listed at.
+package resolver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CurrentSnapshot returns the catalog's latest DuckLake snapshot ID.
+func (r *Resolver) CurrentSnapshot(ctx context.Context) (int64, error) {
+	var snapshot sql.NullInt64
+	err := r.db.QueryRowContext(ctx, "SELECT max(snapshot_id) FROM ducklake_snapshots(?)", r.catalogName).Scan(&snapshot)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current snapshot: %w", err)
+	}
+	if !snapshot.Valid {
+		return 0, fmt.Errorf("catalog %s has no snapshots", r.catalogName)
+	}
+	return snapshot.Int64, nil
+}
+
+// ledgerColumn is the column GenerateSQL filters manifest ranges on
+const ledgerColumn = "ledger_sequence"
+
+// ledgerBounds is the ledger range a data file's column statistics record
+type ledgerBounds struct {
+	Min, Max uint32
+}
+
+// ListSnapshotFiles returns the Parquet files DuckLake reads for a dataset's
+// table at a snapshot that can hold rows of ledgers, sorted by path. Files
+// whose ledger_sequence statistics fall outside ledgers are left out; files
+// without statistics are kept.
+func (r *Resolver) ListSnapshotFiles(ctx context.Context, network, dataset string, snapshot int64, ledgers LedgerRange) ([]ManifestFile, error) {
+	tableName := dataset
+	if idx := strings.LastIndex(dataset, "."); idx != -1 {
+		tableName = dataset[idx+1:]
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT data_file, data_file_size_bytes, delete_file
+		FROM ducklake_list_files(?, ?, schema => ?, snapshot_version => ?)
+		ORDER BY data_file
+	`, r.catalogName, tableName, network, snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of %s at snapshot %d: %w", dataset, snapshot, err)
+	}
+	defer rows.Close()
+
+	var files []ManifestFile
+	for rows.Next() {
+		var f ManifestFile
+		var deleteFile sql.NullString
+		if err := rows.Scan(&f.Path, &f.Bytes, &deleteFile); err != nil {
+			return nil, fmt.Errorf("failed to scan file row: %w", err)
+		}
+		f.DeleteFile = deleteFile.String
+		files = append(files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list files of %s at snapshot %d: %w", dataset, snapshot, err)
+	}
+
+	bounds, err := r.fileLedgerBounds(ctx, network, tableName, snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file statistics of %s at snapshot %d: %w", dataset, snapshot, err)
+	}
+
+	return filesInLedgerRange(files, bounds, ledgers), nil
+}
+
+// fileLedgerBounds reads the ledger_sequence min/max statistics DuckLake
+// keeps for each data file of a table at a snapshot, keyed by the path the
+// metadata stores (relative to the table's data path unless absolute)
+func (r *Resolver) fileLedgerBounds(ctx context.Context, network, tableName string, snapshot int64) (map[string]ledgerBounds, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT f.path, s.min_value, s.max_value
+		FROM %[1]s.ducklake_data_file f
+		JOIN %[1]s.ducklake_table t ON t.table_id = f.table_id
+		JOIN %[1]s.ducklake_schema sc ON sc.schema_id = t.schema_id
+		JOIN %[1]s.ducklake_column c ON c.table_id = t.table_id
+		JOIN %[1]s.ducklake_file_column_statistics s ON s.data_file_id = f.data_file_id AND s.column_id = c.column_id
+		WHERE sc.schema_name = $1 AND t.table_name = $2 AND c.column_name = $3
+		  AND f.begin_snapshot <= $4 AND (f.end_snapshot IS NULL OR f.end_snapshot > $4)
+		  AND t.begin_snapshot <= $4 AND (t.end_snapshot IS NULL OR t.end_snapshot > $4)
+		  AND sc.begin_snapshot <= $4 AND (sc.end_snapshot IS NULL OR sc.end_snapshot > $4)
+		  AND c.begin_snapshot <= $4 AND (c.end_snapshot IS NULL OR c.end_snapshot > $4)
+	`, "__ducklake_metadata_"+r.catalogName), network, tableName, ledgerColumn, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bounds := make(map[string]ledgerBounds)
+	for rows.Next() {
+		var path string
+		var minValue, maxValue sql.NullString
+		if err := rows.Scan(&path, &minValue, &maxValue); err != nil {
+			return nil, err
+		}
+		lo, errMin := strconv.ParseUint(minValue.String, 10, 32)
+		hi, errMax := strconv.ParseUint(maxValue.String, 10, 32)
+		if errMin != nil || errMax != nil {
+			// Missing or unreadable statistics: the file cannot be pruned
+			continue
+		}
+		bounds[path] = ledgerBounds{Min: uint32(lo), Max: uint32(hi)}
+	}
+	return bounds, rows.Err()
+}
+
+// filesInLedgerRange keeps the files whose ledger bounds overlap ledgers.
+// bounds is keyed by metadata path, which may be the listed path itself or
+// a suffix of it after a '/'. Files without bounds are kept.
+func filesInLedgerRange(files []ManifestFile, bounds map[string]ledgerBounds, ledgers LedgerRange) []ManifestFile {
+	var kept []ManifestFile
+	for _, f := range files {
+		b, ok := boundsFor(f.Path, bounds)
+		if ok && (b.Max < ledgers.Start || b.Min > ledgers.End) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// boundsFor looks path up in bounds, then each suffix of it that follows a '/'
+func boundsFor(path string, bounds map[string]ledgerBounds) (ledgerBounds, bool) {
+	for suffix := path; ; {
+		if b, ok := bounds[suffix]; ok {
+			return b, true
+		}
+		idx := strings.Index(suffix, "/")
+		if idx == -1 {
+			return ledgerBounds{}, false
+		}
+		suffix = suffix[idx+1:]
+	}
+}
+
+// PinManifest replaces the lineage-derived file list of a resolved dataset's
+// manifest with the Parquet files DuckLake lists for its table at the current
+// snapshot, pruned to the manifest's ledger range by file statistics, and
+// records that snapshot. The checksum covers only those files. Reading with
+// SQLOptions.Snapshot set to the manifest's LakeSnapshot then sees exactly
+// those files for as long as the snapshot is not expired.
+func (r *Resolver) PinManifest(ctx context.Context, resolved *ResolvedDataset) error {
+	if resolved == nil || resolved.Manifest == nil {
+		return fmt.Errorf("resolved dataset has no manifest (intent needs a range)")
+	}
+	manifest := resolved.Manifest
+
+	snapshot, err := r.CurrentSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	ledgers := LedgerRange{Start: manifest.LedgerStart, End: manifest.LedgerEnd}
+	files, err := r.ListSnapshotFiles(ctx, resolved.Network, resolved.Dataset, snapshot, ledgers)
+	if err != nil {
+		return err
+	}
+
+	manifest.LakeSnapshot = snapshot
+	manifest.Files = files
+	manifest.Checksum = r.computeManifestChecksum(
+		manifest.Dataset,
+		manifest.EraID,
+		ledgers,
+		manifest.Files,
+	)
+
+	return nil
+}
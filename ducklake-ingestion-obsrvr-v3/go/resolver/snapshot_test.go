@@ -0,0 +1,67 @@
+package resolver
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilesInLedgerRange(t *testing.T) {
+	files := []ManifestFile{
+		{Path: "s3://lake/testnet/ledgers_row_v2/a.parquet"},
+		{Path: "s3://lake/testnet/ledgers_row_v2/b.parquet", DeleteFile: "s3://lake/testnet/ledgers_row_v2/b-delete.parquet"},
+		{Path: "s3://lake/testnet/ledgers_row_v2/c.parquet"},
+		{Path: "s3://lake/testnet/ledgers_row_v2/d.parquet"},
+		{Path: "/data/e.parquet"},
+	}
+	bounds := map[string]ledgerBounds{
+		// Relative to the table's data path, as DuckLake usually stores them
+		"a.parquet": {Min: 1, Max: 100},
+		"b.parquet": {Min: 101, Max: 200},
+		"c.parquet": {Min: 201, Max: 300},
+		// Absolute
+		"/data/e.parquet": {Min: 150, Max: 160},
+		// d.parquet has no statistics
+	}
+
+	tests := []struct {
+		name    string
+		ledgers LedgerRange
+		want    []string
+	}{
+		{"narrow range", LedgerRange{Start: 120, End: 130}, []string{"b", "d"}},
+		{"range on a file boundary", LedgerRange{Start: 100, End: 101}, []string{"a", "b", "d"}},
+		{"range overlapping an absolute path", LedgerRange{Start: 155, End: 250}, []string{"b", "c", "d", "e"}},
+		{"whole table", LedgerRange{Start: 1, End: 300}, []string{"a", "b", "c", "d", "e"}},
+		{"past every file", LedgerRange{Start: 301, End: 400}, []string{"d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range filesInLedgerRange(files, bounds, tt.ledgers) {
+				got = append(got, strings.TrimSuffix(path.Base(f.Path), ".parquet"))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPinnedChecksumCoversRangeFiles(t *testing.T) {
+	r := &Resolver{}
+	files := []ManifestFile{{Path: "a.parquet"}, {Path: "b.parquet"}}
+	bounds := map[string]ledgerBounds{"a.parquet": {Min: 1, Max: 100}, "b.parquet": {Min: 101, Max: 200}}
+	ledgers := LedgerRange{Start: 120, End: 130}
+
+	kept := filesInLedgerRange(files, bounds, ledgers)
+	if len(kept) != 1 || kept[0].Path != "b.parquet" {
+		t.Fatalf("kept %+v, want only b.parquet", kept)
+	}
+	pinned := r.computeManifestChecksum("core.ledgers_row_v2", "p23_plus", ledgers, kept)
+	if whole := r.computeManifestChecksum("core.ledgers_row_v2", "p23_plus", ledgers, files); pinned == whole {
+		t.Error("checksum over the range's files equals the checksum over the whole table")
+	}
+}
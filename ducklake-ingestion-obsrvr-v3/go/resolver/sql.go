@@ -18,6 +18,9 @@ type SQLOptions struct {
 	// Limit maximum number of rows (0 = no limit)
 	Limit uint32
 
+	// Snapshot reads the table as of a DuckLake snapshot (0 = current)
+	Snapshot int64
+
 	// IncludeVersionFilter adds era_id and version_label to WHERE clause
 	// This is critical for incremental versioning to prevent reading stale data
 	IncludeVersionFilter bool
@@ -66,6 +69,9 @@ func (r *Resolver) GenerateSQL(result *ResolvedDataset, options SQLOptions) (str
 	// Build FROM clause
 	// Format: catalog.network.table_name
 	fromClause := fmt.Sprintf("%s.%s.%s", r.catalogName, result.Network, tableName)
+	if options.Snapshot > 0 {
+		fromClause += fmt.Sprintf(" AT (VERSION => %d)", options.Snapshot)
+	}
 
 	// Build WHERE clause
 	var conditions []string
@@ -219,6 +225,9 @@ func (r *Resolver) GenerateSQLWithVersionOverlay(result *ResolvedDataset, option
 
 	// Build FROM clause
 	fromClause := fmt.Sprintf("%s.%s.%s", r.catalogName, result.Network, tableName)
+	if options.Snapshot > 0 {
+		fromClause += fmt.Sprintf(" AT (VERSION => %d)", options.Snapshot)
+	}
 
 	// Build WHERE clause for inner query (ledger range only)
 	var conditions []string
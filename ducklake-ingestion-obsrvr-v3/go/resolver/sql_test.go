@@ -94,6 +94,35 @@ func TestGenerateSQL_WithoutVersionFilter(t *testing.T) {
 	t.Logf("Generated SQL (no version filter):\n%s", sql)
 }
 
+// TestGenerateSQL_Snapshot verifies time travel to a pinned DuckLake snapshot.
+func TestGenerateSQL_Snapshot(t *testing.T) {
+	r := &Resolver{
+		catalogName: "obsrvr_lake_catalog_dev_4",
+	}
+
+	result := &ResolvedDataset{
+		Dataset:      "core.ledgers_row_v2",
+		Network:      "testnet",
+		EraID:        "p23_plus",
+		VersionLabel: "v1",
+		Manifest: &ReadManifest{
+			LedgerStart:  40000,
+			LedgerEnd:    45000,
+			LakeSnapshot: 42,
+		},
+	}
+
+	sql, err := r.GenerateSQL(result, SQLOptions{Snapshot: result.Manifest.LakeSnapshot})
+	if err != nil {
+		t.Fatalf("GenerateSQL failed: %v", err)
+	}
+
+	want := "FROM obsrvr_lake_catalog_dev_4.testnet.ledgers_row_v2 AT (VERSION => 42)\nWHERE"
+	if !strings.Contains(sql, want) {
+		t.Errorf("expected SQL to contain %q\nGot:\n%s", want, sql)
+	}
+}
+
 // TestGenerateSQLSimple verifies convenience method.
 func TestGenerateSQLSimple(t *testing.T) {
 	r := &Resolver{
@@ -113,6 +113,10 @@ type ReadManifest struct {
 	// SnapshotID is a monotonic identifier (e.g., max lineage ID)
 	SnapshotID int64
 
+	// LakeSnapshot is the DuckLake snapshot the files were listed at
+	// (0 until the manifest is pinned with PinManifest)
+	LakeSnapshot int64
+
 	// LedgerStart of the range
 	LedgerStart uint32
 
@@ -145,6 +149,9 @@ type ManifestFile struct {
 
 	// Bytes (file size)
 	Bytes int64
+
+	// DeleteFile lists rows deleted from Path, if any (pinned manifests only)
+	DeleteFile string
 }
 
 // Era represents an era record from _meta_eras.
@@ -297,7 +297,7 @@ func TestAccountFeedHotQueryShape(t *testing.T) {
 // serving-projection-processor) and returns column -> definition for a table.
 func servingSchemaColumnDefs(t *testing.T, table string) map[string]string {
 	t.Helper()
-	raw, err := os.ReadFile("../../serving-projection-processor/go/schema/serving_schema.sql")
+	raw, err := os.ReadFile("../../serving-projection-processor/go/migrations/000_serving_schema.up.sql")
 	if err != nil {
 		t.Fatalf("read serving schema: %v", err)
 	}
@@ -332,7 +332,7 @@ func TestServingFeedWriterColumnsMatchServingSchema(t *testing.T) {
 		for _, col := range insertCols {
 			inserted[col] = true
 			if _, ok := schemaCols[col]; !ok {
-				t.Errorf("%s: insert column %q does not exist in serving_schema.sql", table, col)
+				t.Errorf("%s: insert column %q does not exist in the serving schema baseline", table, col)
 			}
 		}
 		for col, def := range schemaCols {
@@ -17,7 +17,7 @@ func NewServingFeedWriter(db *sql.DB, config ServingFeedConfig) *ServingFeedWrit
 }
 
 // Column lists for the by-account feed tables. These MUST stay in lockstep with
-// serving-projection-processor/go/schema/serving_schema.sql (sv_transactions_by_account
+// serving-projection-processor/go/migrations/000_serving_schema.up.sql (sv_transactions_by_account
 // and sv_operations_by_account): the serving schema init or serving-cold-backfill
 // usually creates these tables first, and CREATE TABLE IF NOT EXISTS will not
 // reconcile drift — a mismatched column name fails at prepare time and stalls the feed.
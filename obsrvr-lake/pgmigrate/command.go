@@ -0,0 +1,85 @@
+package pgmigrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// CommandUsage documents the arguments Command accepts.
+const CommandUsage = `migrate status    list migrations and whether they are applied
+migrate up        apply every pending migration
+migrate down [N]  revert the N most recently applied migrations (default 1)`
+
+// Command runs a "migrate" subcommand: args are the words after "migrate".
+// Output for the operator goes to out.
+func Command(ctx context.Context, m *Migrator, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate subcommand\n%s", CommandUsage)
+	}
+	switch args[0] {
+	case "status":
+		if len(args) > 1 {
+			return fmt.Errorf("migrate status takes no arguments")
+		}
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return writeStatus(out, m.component, statuses)
+	case "up":
+		if len(args) > 1 {
+			return fmt.Errorf("migrate up takes no arguments")
+		}
+		n, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s: applied %d migration(s)\n", m.component, n)
+		return nil
+	case "down":
+		steps, err := parseDownSteps(args[1:])
+		if err != nil {
+			return err
+		}
+		n, err := m.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s: reverted %d migration(s)\n", m.component, n)
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate subcommand %q\n%s", args[0], CommandUsage)
+	}
+}
+
+func parseDownSteps(args []string) (int, error) {
+	switch len(args) {
+	case 0:
+		return 1, nil
+	case 1:
+		steps, err := strconv.Atoi(args[0])
+		if err != nil || steps < 1 {
+			return 0, fmt.Errorf("migrate down: N must be a positive number, got %q", args[0])
+		}
+		return steps, nil
+	default:
+		return 0, fmt.Errorf("migrate down takes at most one argument")
+	}
+}
+
+func writeStatus(out io.Writer, component string, statuses []MigrationStatus) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "COMPONENT\tVERSION\tNAME\tSTATE\tAPPLIED AT\n")
+	for _, s := range statuses {
+		appliedAt := "-"
+		if !s.AppliedAt.IsZero() {
+			appliedAt = s.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%03d\t%s\t%s\t%s\n", component, s.Version, s.Name, s.State, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,3 @@
+module github.com/withObsrvr/obsrvr-lake/pgmigrate
+
+go 1.26.1
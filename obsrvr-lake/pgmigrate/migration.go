@@ -0,0 +1,201 @@
+// Package pgmigrate applies ordered, checksummed PostgreSQL schema migrations
+// for obsrvr-lake services.
+//
+// A service embeds a directory of NNN_name.up.sql files, each optionally
+// paired with NNN_name.down.sql, and runs them with a Migrator. Applied
+// versions are recorded per component in obsrvr_lake.schema_migrations
+// together with the SHA-256 of the up file, so an edited migration is
+// reported instead of silently diverging between environments.
+package pgmigrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NoTransactionDirective marks a migration that must run outside a
+// transaction, such as CREATE INDEX CONCURRENTLY. Put it on its own line
+// anywhere in the up file; it applies to the down file when placed there.
+// Statements of such a migration run one at a time, so each must be safe to
+// repeat if the migration fails halfway.
+const NoTransactionDirective = "-- pgmigrate:no-transaction"
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one numbered schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string // empty when the migration cannot be reverted
+	// UpNoTransaction and DownNoTransaction are set by NoTransactionDirective.
+	UpNoTransaction   bool
+	DownNoTransaction bool
+	Checksum          string // hex SHA-256 of Up
+}
+
+// Load reads the migrations in dir of fsys, ordered by version. Files that do
+// not end in .sql are ignored; a .sql file that does not follow the
+// NNN_name.up.sql / NNN_name.down.sql pattern is an error, as is a down file
+// without an up file or two migrations sharing a version.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations dir %s: %w", dir, err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	downs := map[int64]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s must be named NNN_name.up.sql or NNN_name.down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s: %w", entry.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+		body := string(data)
+
+		if match[3] == "down" {
+			if _, ok := downs[version]; ok {
+				return nil, fmt.Errorf("migration version %d has more than one down file", version)
+			}
+			downs[version] = body
+			continue
+		}
+		if existing, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, existing.Name, match[2])
+		}
+		sum := sha256.Sum256(data)
+		byVersion[version] = &Migration{
+			Version:         version,
+			Name:            match[2],
+			Up:              body,
+			UpNoTransaction: hasNoTransactionDirective(body),
+			Checksum:        hex.EncodeToString(sum[:]),
+		}
+	}
+
+	for version, body := range downs {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("migration version %d has a down file but no up file", version)
+		}
+		m.Down = body
+		m.DownNoTransaction = hasNoTransactionDirective(body)
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func hasNoTransactionDirective(body string) bool {
+	for _, line := range strings.Split(body, "\n") {
+		if strings.TrimSpace(line) == NoTransactionDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// splitStatements splits SQL on top-level semicolons, keeping semicolons
+// inside quoted strings, quoted identifiers, dollar-quoted bodies and
+// comments. Empty statements are dropped.
+func splitStatements(sql string) []string {
+	var statements []string
+	start := 0
+	flush := func(end int) {
+		if s := strings.TrimSpace(sql[start:end]); s != "" && !onlyComments(s) {
+			statements = append(statements, s)
+		}
+	}
+	for i := 0; i < len(sql); i++ {
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(sql)
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(sql)
+			}
+		case sql[i] == '\'' || sql[i] == '"':
+			quote := sql[i]
+			for i++; i < len(sql); i++ {
+				if sql[i] == quote {
+					if i+1 < len(sql) && sql[i+1] == quote {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case sql[i] == '$':
+			tag := dollarQuoteTag(sql[i:])
+			if tag == "" {
+				continue
+			}
+			if end := strings.Index(sql[i+len(tag):], tag); end >= 0 {
+				i += len(tag) + end + len(tag) - 1
+			} else {
+				i = len(sql)
+			}
+		case sql[i] == ';':
+			flush(i)
+			start = i + 1
+		}
+	}
+	if start < len(sql) {
+		flush(len(sql))
+	}
+	return statements
+}
+
+// dollarQuoteTag returns the $tag$ opening s, or "" if s does not open a
+// dollar-quoted string.
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9':
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func onlyComments(s string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
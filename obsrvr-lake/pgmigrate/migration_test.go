@@ -0,0 +1,168 @@
+package pgmigrate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoadOrdersAndPairsMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/010_add_index.up.sql":   {Data: []byte("-- pgmigrate:no-transaction\nCREATE INDEX CONCURRENTLY IF NOT EXISTS i ON t (c);\n")},
+		"migrations/010_add_index.down.sql": {Data: []byte(NoTransactionDirective + "\nDROP INDEX CONCURRENTLY IF EXISTS i;\n")},
+		"migrations/002_add_table.up.sql":   {Data: []byte("CREATE TABLE t (c INT);")},
+		"migrations/002_add_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"migrations/000_baseline.up.sql":    {Data: []byte("CREATE TABLE base (id INT);")},
+		"migrations/README.md":              {Data: []byte("ignored")},
+	}
+	migrations, err := Load(fsys, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, m := range migrations {
+		got = append(got, m.Name)
+	}
+	if want := []string{"baseline", "add_table", "add_index"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("names = %v, want %v", got, want)
+	}
+	if migrations[0].Down != "" || migrations[1].Down != "DROP TABLE t;" {
+		t.Fatalf("down files = %q, %q", migrations[0].Down, migrations[1].Down)
+	}
+	if migrations[1].UpNoTransaction || !migrations[2].UpNoTransaction || !migrations[2].DownNoTransaction {
+		t.Fatalf("no-transaction flags = %+v", migrations)
+	}
+	// sha256("CREATE TABLE t (c INT);")
+	if migrations[1].Checksum != "eac0b195e156033edc9e18c2f26a1b08a833332b3088310ee4d60a192dc3de26" {
+		t.Fatalf("checksum = %q", migrations[1].Checksum)
+	}
+}
+
+func TestLoadRejectsBadMigrationSets(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		want  string
+	}{
+		{"bad name", fstest.MapFS{"m/1-add.sql": {}}, "must be named"},
+		{"orphan down", fstest.MapFS{"m/001_add.down.sql": {}}, "no up file"},
+		{"duplicate version", fstest.MapFS{"m/001_a.up.sql": {}, "m/1_b.up.sql": {}}, "used by both"},
+		{"two downs", fstest.MapFS{"m/001_a.up.sql": {}, "m/001_a.down.sql": {}, "m/1_b.down.sql": {}}, "more than one down"},
+	}
+	for _, tt := range tests {
+		_, err := Load(tt.files, "m")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	sql := `
+-- leading comment; with a semicolon
+CREATE INDEX CONCURRENTLY IF NOT EXISTS a ON t (c);
+INSERT INTO t (s) VALUES ('a;b'), ('it''s; fine');
+DO $$ BEGIN RAISE NOTICE 'x;y'; END $$;
+CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;
+/* block; comment */ SELECT "odd;name" FROM t
+-- trailing comment only;
+`
+	got := splitStatements(sql)
+	want := []string{
+		"-- leading comment; with a semicolon\nCREATE INDEX CONCURRENTLY IF NOT EXISTS a ON t (c)",
+		"INSERT INTO t (s) VALUES ('a;b'), ('it''s; fine')",
+		"DO $$ BEGIN RAISE NOTICE 'x;y'; END $$",
+		"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql",
+		"/* block; comment */ SELECT \"odd;name\" FROM t\n-- trailing comment only;",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("statements =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 0, Name: "baseline", Up: "a", Checksum: "c0"},
+		{Version: 1, Name: "one", Up: "b", Down: "undo b", Checksum: "c1"},
+		{Version: 2, Name: "two", Up: "c", Down: "undo c", Checksum: "c2"},
+	}
+}
+
+func TestMigrationStatusesAndPending(t *testing.T) {
+	appliedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	applied := []appliedMigration{{Version: 0, Name: "baseline", Checksum: "c0", AppliedAt: appliedAt}}
+
+	statuses := migrationStatuses(testMigrations(), applied)
+	var states []string
+	for _, s := range statuses {
+		states = append(states, s.State)
+	}
+	if want := []string{StateApplied, StatePending, StatePending}; !reflect.DeepEqual(states, want) {
+		t.Fatalf("states = %v, want %v", states, want)
+	}
+	if !statuses[0].AppliedAt.Equal(appliedAt) {
+		t.Fatalf("applied at = %v", statuses[0].AppliedAt)
+	}
+
+	pending, err := pendingMigrations(testMigrations(), applied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].Version != 1 || pending[1].Version != 2 {
+		t.Fatalf("pending = %+v", pending)
+	}
+}
+
+func TestPendingRefusesDriftedHistory(t *testing.T) {
+	modified := []appliedMigration{{Version: 0, Name: "baseline", Checksum: "edited"}}
+	if _, err := pendingMigrations(testMigrations(), modified); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Fatalf("err = %v, want a modified migration error", err)
+	}
+
+	missing := []appliedMigration{{Version: 0, Checksum: "c0"}, {Version: 7, Name: "from_the_future", Checksum: "c7"}}
+	statuses := migrationStatuses(testMigrations(), missing)
+	if last := statuses[len(statuses)-1]; last.Version != 7 || last.State != StateMissing {
+		t.Fatalf("last status = %+v, want missing 7", last)
+	}
+	if _, err := pendingMigrations(testMigrations(), missing); err == nil || !strings.Contains(err.Error(), "not shipped") {
+		t.Fatalf("err = %v, want a missing migration error", err)
+	}
+}
+
+func TestRevertMigrations(t *testing.T) {
+	applied := []appliedMigration{{Version: 0, Checksum: "c0"}, {Version: 1, Checksum: "c1"}, {Version: 2, Checksum: "c2"}}
+
+	targets, err := revertMigrations(testMigrations(), applied, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 || targets[0].Version != 2 || targets[1].Version != 1 {
+		t.Fatalf("targets = %+v, want 2 then 1", targets)
+	}
+	if _, err := revertMigrations(testMigrations(), applied, 3); err == nil || !strings.Contains(err.Error(), "no down file") {
+		t.Fatalf("err = %v, want the baseline to be irreversible", err)
+	}
+	if _, err := revertMigrations(testMigrations(), applied, 4); err == nil || !strings.Contains(err.Error(), "only 3 applied") {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := revertMigrations(testMigrations(), applied, 0); err == nil {
+		t.Fatal("zero steps accepted")
+	}
+}
+
+func TestParseDownSteps(t *testing.T) {
+	if n, err := parseDownSteps(nil); err != nil || n != 1 {
+		t.Fatalf("default steps = %d, %v", n, err)
+	}
+	if n, err := parseDownSteps([]string{"3"}); err != nil || n != 3 {
+		t.Fatalf("steps = %d, %v", n, err)
+	}
+	for _, bad := range [][]string{{"0"}, {"x"}, {"1", "2"}} {
+		if _, err := parseDownSteps(bad); err == nil {
+			t.Errorf("parseDownSteps(%q) succeeded", bad)
+		}
+	}
+}
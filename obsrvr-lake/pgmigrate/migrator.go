@@ -0,0 +1,360 @@
+package pgmigrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// The history table lives in its own schema: stellar_hot already has the
+// public.schema_migrations table of golang-migrate for the postgres-hot-buffer
+// base schema, and several components share silver_hot.
+const (
+	historySchema = "obsrvr_lake"
+	historyTable  = historySchema + ".schema_migrations"
+)
+
+// Migration states reported by Status.
+const (
+	StateApplied  = "applied"
+	StatePending  = "pending"
+	StateModified = "modified" // applied, but the up file changed since
+	StateMissing  = "missing"  // applied, but no longer shipped
+)
+
+// MigrationStatus describes one migration known to the code, the database,
+// or both.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	State     string
+	AppliedAt time.Time // zero unless applied
+}
+
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator applies one component's migrations to a database. Every
+// operation holds a session advisory lock for the component, so replicas
+// starting together apply each migration once; the others wait and then
+// find nothing to do.
+type Migrator struct {
+	db         *sql.DB
+	component  string
+	migrations []Migration
+}
+
+// New returns a Migrator for component, as recorded in the history table.
+// migrations must be ordered by version, as Load returns them.
+func New(db *sql.DB, component string, migrations []Migration) *Migrator {
+	return &Migrator{db: db, component: component, migrations: migrations}
+}
+
+// Status reports every migration and whether it is applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		statuses = migrationStatuses(m.migrations, applied)
+		return nil
+	})
+	return statuses, err
+}
+
+// Up applies every pending migration in version order and returns how many
+// ran. It refuses to run while an applied migration was modified or removed.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		pending, err := pendingMigrations(m.migrations, applied)
+		if err != nil {
+			return err
+		}
+		for _, mig := range pending {
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down reverts the steps most recently applied migrations, newest first. It
+// checks that all of them have a down file before reverting any.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		targets, err := revertMigrations(m.migrations, applied, steps)
+		if err != nil {
+			return err
+		}
+		for _, mig := range targets {
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// withLock runs fn on a dedicated connection holding the component's
+// advisory lock, creating the history table first if needed.
+func (m *Migrator) withLock(ctx context.Context, fn func(*sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire migration connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtext($1), hashtext($2))`, historyTable, m.component); err != nil {
+		return fmt.Errorf("acquire migration lock for %s: %w", m.component, err)
+	}
+	defer func() {
+		// A fresh context: the caller's may be done, and the lock must not
+		// outlive this connection's return to the pool.
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := conn.ExecContext(unlockCtx, `SELECT pg_advisory_unlock(hashtext($1), hashtext($2))`, historyTable, m.component); err != nil {
+			log.Printf("migrate component=%s release lock failed, discarding connection: %v", m.component, err)
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	if err := ensureHistoryTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func ensureHistoryTable(ctx context.Context, conn *sql.Conn) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin history table tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Components sharing a database create the table under one lock.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, historyTable); err != nil {
+		return fmt.Errorf("lock history table: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `CREATE SCHEMA IF NOT EXISTS `+historySchema); err != nil {
+		return fmt.Errorf("create %s schema: %w", historySchema, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+historyTable+` (
+			component    TEXT NOT NULL,
+			version      BIGINT NOT NULL,
+			name         TEXT NOT NULL,
+			checksum     TEXT NOT NULL,
+			applied_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			execution_ms BIGINT NOT NULL,
+			PRIMARY KEY (component, version)
+		)
+	`); err != nil {
+		return fmt.Errorf("create %s: %w", historyTable, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit history table: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) loadApplied(ctx context.Context, conn *sql.Conn) ([]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT version, name, checksum, applied_at
+		FROM `+historyTable+`
+		WHERE component = $1
+		ORDER BY version
+	`, m.component)
+	if err != nil {
+		return nil, fmt.Errorf("load applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []appliedMigration
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	start := time.Now()
+	record := func(ctx context.Context, exec execer) error {
+		_, err := exec.ExecContext(ctx, `
+			INSERT INTO `+historyTable+` (component, version, name, checksum, execution_ms)
+			VALUES ($1, $2, $3, $4, $5)
+		`, m.component, mig.Version, mig.Name, mig.Checksum, time.Since(start).Milliseconds())
+		return err
+	}
+	if err := run(ctx, conn, mig.Up, mig.UpNoTransaction, record); err != nil {
+		return fmt.Errorf("apply migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	log.Printf("migrate component=%s applied=%d_%s duration=%s", m.component, mig.Version, mig.Name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	start := time.Now()
+	record := func(ctx context.Context, exec execer) error {
+		_, err := exec.ExecContext(ctx, `DELETE FROM `+historyTable+` WHERE component = $1 AND version = $2`, m.component, mig.Version)
+		return err
+	}
+	if err := run(ctx, conn, mig.Down, mig.DownNoTransaction, record); err != nil {
+		return fmt.Errorf("revert migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	log.Printf("migrate component=%s reverted=%d_%s duration=%s", m.component, mig.Version, mig.Name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// run executes body and then record. A transactional body runs as one
+// statement batch in the same transaction as record; otherwise each
+// statement runs on its own and record follows the last one.
+func run(ctx context.Context, conn *sql.Conn, body string, noTransaction bool, record func(context.Context, execer) error) error {
+	if noTransaction {
+		for _, stmt := range splitStatements(body) {
+			if _, err := conn.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("%w\nStatement: %.200s", err, stmt)
+			}
+		}
+		if err := record(ctx, conn); err != nil {
+			return fmt.Errorf("record history: %w", err)
+		}
+		return nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	if err := record(ctx, tx); err != nil {
+		return fmt.Errorf("record history: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// migrationStatuses merges the shipped and applied migrations by version.
+func migrationStatuses(migrations []Migration, applied []appliedMigration) []MigrationStatus {
+	appliedByVersion := map[int64]appliedMigration{}
+	for _, a := range applied {
+		appliedByVersion[a.Version] = a
+	}
+
+	var statuses []MigrationStatus
+	shipped := map[int64]bool{}
+	for _, mig := range migrations {
+		shipped[mig.Version] = true
+		status := MigrationStatus{Version: mig.Version, Name: mig.Name, State: StatePending}
+		if a, ok := appliedByVersion[mig.Version]; ok {
+			status.AppliedAt = a.AppliedAt
+			status.State = StateApplied
+			if a.Checksum != mig.Checksum {
+				status.State = StateModified
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	for _, a := range applied {
+		if !shipped[a.Version] {
+			statuses = append(statuses, MigrationStatus{Version: a.Version, Name: a.Name, State: StateMissing, AppliedAt: a.AppliedAt})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
+
+// pendingMigrations returns the migrations Up should apply, or an error when
+// the applied history no longer matches the shipped migrations.
+func pendingMigrations(migrations []Migration, applied []appliedMigration) ([]Migration, error) {
+	if err := checkHistory(migrationStatuses(migrations, applied)); err != nil {
+		return nil, err
+	}
+	appliedVersions := map[int64]bool{}
+	for _, a := range applied {
+		appliedVersions[a.Version] = true
+	}
+	var pending []Migration
+	for _, mig := range migrations {
+		if !appliedVersions[mig.Version] {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// revertMigrations returns the steps newest applied migrations, newest
+// first.
+func revertMigrations(migrations []Migration, applied []appliedMigration, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("down steps must be at least 1, got %d", steps)
+	}
+	if err := checkHistory(migrationStatuses(migrations, applied)); err != nil {
+		return nil, err
+	}
+	if steps > len(applied) {
+		return nil, fmt.Errorf("cannot revert %d migrations: only %d applied", steps, len(applied))
+	}
+	byVersion := map[int64]Migration{}
+	for _, mig := range migrations {
+		byVersion[mig.Version] = mig
+	}
+	targets := make([]Migration, 0, steps)
+	for i := len(applied) - 1; i >= len(applied)-steps; i-- {
+		mig := byVersion[applied[i].Version]
+		if mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+		}
+		targets = append(targets, mig)
+	}
+	return targets, nil
+}
+
+func checkHistory(statuses []MigrationStatus) error {
+	for _, s := range statuses {
+		switch s.State {
+		case StateModified:
+			return fmt.Errorf("migration %d_%s was modified after it was applied; add a new migration instead", s.Version, s.Name)
+		case StateMissing:
+			return fmt.Errorf("migration %d_%s is applied but not shipped with this build", s.Version, s.Name)
+		}
+	}
+	return nil
+}
@@ -64,6 +64,17 @@ func NewAccountBalancesProjector(network string, batchSize int, sourcePool, targ
 
 func (p *AccountBalancesProjector) Name() string { return "account_balances" }
 
+func (p *AccountBalancesProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads: []string{
+			"silver.effects",
+			"silver.accounts_current",
+			"silver.trustlines_current",
+		},
+		Writes: []string{"serving.sv_account_balances_current"},
+	}
+}
+
 func (p *AccountBalancesProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	checkpoint, err := p.checkpoints.Load(ctx, p.Name(), p.network)
 	if err != nil {
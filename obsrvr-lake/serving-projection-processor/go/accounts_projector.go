@@ -48,6 +48,13 @@ func NewAccountsCurrentProjector(network string, batchSize int, sourcePool, targ
 
 func (p *AccountsCurrentProjector) Name() string { return "accounts_current" }
 
+func (p *AccountsCurrentProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads:  []string{"silver.accounts_current"},
+		Writes: []string{"serving.sv_accounts_current"},
+	}
+}
+
 func (p *AccountsCurrentProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	checkpoint, err := p.checkpoints.Load(ctx, p.Name(), p.network)
 	if err != nil {
@@ -19,6 +19,13 @@ func NewAssetStatsProjector(network string, targetPool *pgxpool.Pool) *AssetStat
 
 func (p *AssetStatsProjector) Name() string { return "asset_stats" }
 
+func (p *AssetStatsProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads:  []string{"serving.sv_account_balances_current"},
+		Writes: []string{"serving.sv_assets_current", "serving.sv_asset_stats_current"},
+	}
+}
+
 func (p *AssetStatsProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	tx, err := p.targetPool.Begin(ctx)
 	if err != nil {
@@ -67,3 +67,21 @@ func saveServingWatermark(ctx context.Context, tx pgx.Tx, tableName string, comp
 	}
 	return nil
 }
+
+// loadServingWatermark reads a table's watermark inside tx. ok is false when
+// the table has no complete watermark.
+func loadServingWatermark(ctx context.Context, tx pgx.Tx, tableName string) (ok bool, completeFrom, completeThru int64, err error) {
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT status, complete_from, complete_thru
+		FROM serving.sv_watermarks
+		WHERE table_name = $1
+	`, tableName).Scan(&status, &completeFrom, &completeThru)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, 0, 0, nil
+		}
+		return false, 0, 0, fmt.Errorf("load serving watermark for %s: %w", tableName, err)
+	}
+	return status == "complete", completeFrom, completeThru, nil
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -17,6 +20,16 @@ type Config struct {
 	Schema     SchemaConfig     `yaml:"schema"`
 	Health     HealthConfig     `yaml:"health"`
 	Trigger    TriggerConfig    `yaml:"trigger"`
+	Scheduler  SchedulerConfig  `yaml:"scheduler"`
+
+	SQLProjectors []SQLProjectorConfig `yaml:"sql_projectors"`
+}
+
+// SchedulerConfig bounds how many projectors run at once. Projectors that
+// share a table never run together, whatever the budget; 1 runs them one at
+// a time.
+type SchedulerConfig struct {
+	Workers int `yaml:"workers"`
 }
 
 type RadarConfig struct {
@@ -84,6 +97,84 @@ type ProjectorConfig struct {
 	BatchSize int  `yaml:"batch_size"`
 }
 
+// SQLProjectorConfig defines a serving table projected by a query instead of
+// a hand-written projector. Each run reads one ledger range,
+// (checkpoint, checkpoint+batch_ledgers] capped at the source high watermark,
+// and upserts the rows into the target table on the conflict key. Query
+// receives the range as $1 (exclusive) and $2 (inclusive); its column names
+// are the target columns.
+type SQLProjectorConfig struct {
+	Name               string   `yaml:"name"`
+	Enabled            bool     `yaml:"enabled"`
+	Source             string   `yaml:"source"` // bronze or silver
+	Query              string   `yaml:"query"`
+	HighWatermarkQuery string   `yaml:"high_watermark_query"` // one int8 column: highest committed source ledger
+	TargetTable        string   `yaml:"target_table"`
+	ConflictKey        []string `yaml:"conflict_key"`
+	BatchLedgers       int64    `yaml:"batch_ledgers"` // default 1000
+	StartLedger        int64    `yaml:"start_ledger"`  // first ledger projected when there is no checkpoint
+	CheckpointKey      string   `yaml:"checkpoint_key"`
+	ClosedAtColumn     string   `yaml:"closed_at_column"` // optional; recorded with the checkpoint
+	Retention          string   `yaml:"retention"`        // e.g. "30 days"; applied with applyRecentRetention
+	RetentionColumn    string   `yaml:"retention_column"`
+	Watermark          bool     `yaml:"watermark"` // record complete_from/complete_thru in serving.sv_watermarks
+	Reads              []string `yaml:"reads"`     // source tables, for the scheduler
+}
+
+const defaultSQLProjectorBatchLedgers = 1000
+
+var (
+	sqlIdentifierPattern      = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	sqlTablePattern           = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+	sqlRetentionPattern       = regexp.MustCompile(`^[1-9][0-9]* (minute|hour|day|week|month|year)s?$`)
+	sqlProjectorSourceOptions = map[string]bool{"bronze": true, "silver": true}
+)
+
+func (c *SQLProjectorConfig) applyDefaults() {
+	if c.BatchLedgers <= 0 {
+		c.BatchLedgers = defaultSQLProjectorBatchLedgers
+	}
+	if c.CheckpointKey == "" {
+		c.CheckpointKey = c.Name
+	}
+}
+
+func (c *SQLProjectorConfig) validate() error {
+	if !sqlIdentifierPattern.MatchString(c.Name) {
+		return fmt.Errorf("name %q must be a lower-case identifier", c.Name)
+	}
+	if !sqlProjectorSourceOptions[c.Source] {
+		return fmt.Errorf("%s: source must be \"bronze\" or \"silver\", got %q", c.Name, c.Source)
+	}
+	if strings.TrimSpace(c.Query) == "" || !strings.Contains(c.Query, "$1") || !strings.Contains(c.Query, "$2") {
+		return fmt.Errorf("%s: query must select the ledger range ($1, $2]", c.Name)
+	}
+	if strings.TrimSpace(c.HighWatermarkQuery) == "" {
+		return fmt.Errorf("%s: high_watermark_query is required", c.Name)
+	}
+	if !sqlTablePattern.MatchString(c.TargetTable) {
+		return fmt.Errorf("%s: target_table %q must be a lower-case table name", c.Name, c.TargetTable)
+	}
+	if len(c.ConflictKey) == 0 {
+		return fmt.Errorf("%s: conflict_key is required", c.Name)
+	}
+	for _, col := range append(append([]string(nil), c.ConflictKey...), c.ClosedAtColumn, c.RetentionColumn) {
+		if col != "" && !sqlIdentifierPattern.MatchString(col) {
+			return fmt.Errorf("%s: column %q must be a lower-case identifier", c.Name, col)
+		}
+	}
+	if (c.Retention == "") != (c.RetentionColumn == "") {
+		return fmt.Errorf("%s: retention and retention_column must be set together", c.Name)
+	}
+	if c.Retention != "" && !sqlRetentionPattern.MatchString(c.Retention) {
+		return fmt.Errorf("%s: retention %q must look like \"30 days\"", c.Name, c.Retention)
+	}
+	if c.Retention != "" && c.Watermark {
+		return fmt.Errorf("%s: a table with retention cannot claim a complete watermark", c.Name)
+	}
+	return nil
+}
+
 type SchemaConfig struct {
 	AutoApply bool `yaml:"auto_apply"`
 }
@@ -172,9 +263,15 @@ func LoadConfig(path string) (*Config, error) {
 	if cfg.Trigger.Mode == "" {
 		cfg.Trigger.Mode = "poll"
 	}
+	if cfg.Scheduler.Workers <= 0 {
+		cfg.Scheduler.Workers = defaultSchedulerWorkers
+	}
 	if cfg.Trigger.FallbackPollSeconds <= 0 {
 		cfg.Trigger.FallbackPollSeconds = cfg.Service.TickIntervalSeconds
 	}
+	for i := range cfg.SQLProjectors {
+		cfg.SQLProjectors[i].applyDefaults()
+	}
 
 	return &cfg, cfg.Validate()
 }
@@ -201,6 +298,29 @@ func (c *Config) Validate() error {
 	if c.Trigger.Mode == "grpc" && c.Trigger.Endpoint == "" {
 		return fmt.Errorf("trigger.endpoint is required when trigger.mode is \"grpc\"")
 	}
+	// Built-in projectors checkpoint under their own names.
+	names := map[string]bool{}
+	checkpointKeys := map[string]bool{}
+	projectorsType := reflect.TypeOf(ProjectorsConfig{})
+	for i := 0; i < projectorsType.NumField(); i++ {
+		name := projectorsType.Field(i).Tag.Get("yaml")
+		names[name] = true
+		checkpointKeys[name] = true
+	}
+	for i := range c.SQLProjectors {
+		sp := &c.SQLProjectors[i]
+		if err := sp.validate(); err != nil {
+			return fmt.Errorf("sql_projectors: %w", err)
+		}
+		if names[sp.Name] {
+			return fmt.Errorf("sql_projectors: duplicate name %q", sp.Name)
+		}
+		if checkpointKeys[sp.CheckpointKey] {
+			return fmt.Errorf("sql_projectors: duplicate checkpoint_key %q", sp.CheckpointKey)
+		}
+		names[sp.Name] = true
+		checkpointKeys[sp.CheckpointKey] = true
+	}
 	return nil
 }
 
@@ -42,6 +42,13 @@ func NewContractCallsRecentProjector(network string, batchSize int, sourcePool,
 
 func (p *ContractCallsRecentProjector) Name() string { return "contract_calls_recent" }
 
+func (p *ContractCallsRecentProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads:  []string{"silver.contract_invocations_raw"},
+		Writes: []string{"serving.sv_contract_calls_recent"},
+	}
+}
+
 func (p *ContractCallsRecentProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	checkpoint, err := p.checkpoints.Load(ctx, p.Name(), p.network)
 	if err != nil {
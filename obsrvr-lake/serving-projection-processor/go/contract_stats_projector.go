@@ -20,6 +20,22 @@ func NewContractStatsProjector(network string, sourcePool, targetPool *pgxpool.P
 
 func (p *ContractStatsProjector) Name() string { return "contract_stats" }
 
+func (p *ContractStatsProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads: []string{
+			"serving.sv_contract_calls_recent",
+			"serving.sv_events_recent",
+			"serving.sv_contract_storage_summary",
+			"serving.sv_smart_account_contracts_current",
+		},
+		Writes: []string{
+			"serving.sv_contract_stats_current",
+			"serving.sv_contract_function_stats_current",
+			"serving.sv_contract_activity_summary",
+		},
+	}
+}
+
 func (p *ContractStatsProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	dataTime := resolveDataTime(ctx, p.targetPool, "serving.sv_contract_calls_recent", "created_at")
 	var completeThru int64
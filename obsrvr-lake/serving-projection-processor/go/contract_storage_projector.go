@@ -98,6 +98,19 @@ func NewContractStorageProjector(network string, batchSize int, sourcePool, targ
 
 func (p *ContractStorageProjector) Name() string { return "contract_storage" }
 
+func (p *ContractStorageProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads: []string{
+			"silver.contract_data_current",
+			"silver.ttl_current",
+			"silver.contract_data_deletions",
+			"silver.evicted_keys",
+			"silver.realtime_transformer_checkpoint",
+		},
+		Writes: []string{"serving.sv_contract_storage_current", "serving.sv_contract_storage_summary"},
+	}
+}
+
 func (p *ContractStorageProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	checkpoint, err := p.checkpoints.Load(ctx, p.Name(), p.network)
 	if err != nil {
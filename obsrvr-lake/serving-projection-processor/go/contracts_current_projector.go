@@ -20,6 +20,18 @@ func NewContractsCurrentProjector(network string, sourcePool, targetPool *pgxpoo
 
 func (p *ContractsCurrentProjector) Name() string { return "contracts_current" }
 
+func (p *ContractsCurrentProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads: []string{
+			"silver.contract_data_current",
+			"silver.token_registry",
+			"silver.contract_metadata",
+			"silver.contract_code_current",
+		},
+		Writes: []string{"serving.sv_contracts_current"},
+	}
+}
+
 func (p *ContractsCurrentProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	tx, err := p.targetPool.Begin(ctx)
 	if err != nil {
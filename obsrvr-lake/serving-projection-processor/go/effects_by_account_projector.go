@@ -12,7 +12,7 @@ import (
 
 const effectsByAccountWatermarkTable = "serving.sv_effects_by_account"
 
-// effectsByAccountUpsertSQL is pinned against serving_schema.sql by
+// effectsByAccountUpsertSQL is pinned against the serving schema baseline by
 // TestEffectsByAccountUpsertColumnsMatchServingSchema.
 const effectsByAccountUpsertSQL = `
 	INSERT INTO serving.sv_effects_by_account (
@@ -94,6 +94,17 @@ func NewEffectsByAccountProjector(network string, batchSize int, sourcePool, tar
 
 func (p *EffectsByAccountProjector) Name() string { return "effects_by_account" }
 
+func (p *EffectsByAccountProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads: []string{
+			"silver.effects",
+			"silver.enriched_history_operations",
+			"silver.realtime_transformer_checkpoint",
+		},
+		Writes: []string{"serving.sv_effects_by_account"},
+	}
+}
+
 // SourceHighWatermark returns the highest ledger whose effects are guaranteed
 // committed. The transformer advances realtime_transformer_checkpoint only
 // after the batch's bronze→silver writes (including effects) have committed,
@@ -54,6 +54,13 @@ func NewEventsRecentProjector(network string, batchSize int, sourcePool, targetP
 
 func (p *EventsRecentProjector) Name() string { return "events_recent" }
 
+func (p *EventsRecentProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads:  []string{"bronze.contract_events_stream_v1", "bronze.transactions_row_v2"},
+		Writes: []string{"serving.sv_events_recent"},
+	}
+}
+
 func (p *EventsRecentProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	checkpoint, err := p.checkpoints.Load(ctx, p.Name(), p.network)
 	if err != nil {
@@ -35,6 +35,18 @@ func NewExplorerEventsRecentProjector(network string, batchSize int, sourcePool,
 
 func (p *ExplorerEventsRecentProjector) Name() string { return "explorer_events_recent" }
 
+func (p *ExplorerEventsRecentProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads: []string{
+			"bronze.contract_events_stream_v1",
+			"bronze.transactions_row_v2",
+			"silver.contract_registry",
+			"silver.token_registry",
+		},
+		Writes: []string{"serving.sv_explorer_events_recent"},
+	}
+}
+
 func explorerEventSuccessFields(transactionSuccessful, inSuccessfulContractCall *bool) (*bool, *bool) {
 	_ = inSuccessfulContractCall // raw evidence is projected separately, not used for public status.
 	// Compatibility `successful` must be an alias for transaction-level success,
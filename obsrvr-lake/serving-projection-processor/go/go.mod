@@ -7,6 +7,7 @@ require (
 	github.com/stellar/go v0.0.0-20251210100531-aab2ea4aca88
 	github.com/stellar/go-stellar-sdk v0.6.0
 	github.com/withObsrvr/flow-proto v0.1.3
+	github.com/withObsrvr/obsrvr-lake/pgmigrate v0.0.0-00010101000000-000000000000
 	google.golang.org/grpc v1.71.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -28,3 +29,5 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250115164207-1a7da9e5054f // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
 )
+
+replace github.com/withObsrvr/obsrvr-lake/pgmigrate => ../../pgmigrate
@@ -50,7 +50,27 @@ func (hs *HealthServer) RegisterProjector(name string) {
 	if _, ok := hs.projectors[name]; ok {
 		return
 	}
-	hs.projectors[name] = &ProjectorRuntimeStatus{Name: name}
+	hs.projectors[name] = &ProjectorRuntimeStatus{Name: name, State: projectorStateIdle}
+}
+
+func (hs *HealthServer) SetProjectorDependencies(name string, dependsOn []string) {
+	hs.update(name, func(s *ProjectorRuntimeStatus) { s.DependsOn = dependsOn })
+}
+
+func (hs *HealthServer) SetProjectorState(name, state string) {
+	hs.update(name, func(s *ProjectorRuntimeStatus) { s.State = state })
+}
+
+// SetProjectorTarget records the ledger a catch-up projector is running to.
+func (hs *HealthServer) SetProjectorTarget(name string, target int64) {
+	hs.update(name, func(s *ProjectorRuntimeStatus) { s.TargetLedger = target })
+}
+
+func (hs *HealthServer) update(name string, fn func(*ProjectorRuntimeStatus)) {
+	hs.RegisterProjector(name)
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	fn(hs.projectors[name])
 }
 
 func (hs *HealthServer) Start() error {
@@ -147,7 +167,7 @@ func (hs *HealthServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	hs.mu.RLock()
 	projectors := make([]ProjectorRuntimeStatus, 0, len(hs.projectors))
 	for _, p := range hs.projectors {
-		projectors = append(projectors, *p)
+		projectors = append(projectors, p.withLag())
 	}
 	hs.mu.RUnlock()
 
@@ -175,6 +195,10 @@ func (hs *HealthServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "# TYPE serving_projection_projector_last_success_timestamp_seconds gauge\n")
 	fmt.Fprintf(w, "# HELP serving_projection_projector_last_error_timestamp_seconds Unix timestamp of last projector error\n")
 	fmt.Fprintf(w, "# TYPE serving_projection_projector_last_error_timestamp_seconds gauge\n")
+	fmt.Fprintf(w, "# HELP serving_projection_projector_lag_ledgers Ledgers between the last checkpoint and the catch-up target\n")
+	fmt.Fprintf(w, "# TYPE serving_projection_projector_lag_ledgers gauge\n")
+	fmt.Fprintf(w, "# HELP serving_projection_projector_running Whether the projector is running (1) or not (0)\n")
+	fmt.Fprintf(w, "# TYPE serving_projection_projector_running gauge\n")
 
 	for _, p := range projectors {
 		labels := fmt.Sprintf("projector=%q", p.Name)
@@ -185,6 +209,14 @@ func (hs *HealthServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "serving_projection_projector_total_failures{%s} %d\n", labels, p.TotalFailures)
 		fmt.Fprintf(w, "serving_projection_projector_last_checkpoint{%s} %d\n", labels, p.LastCheckpoint)
 		fmt.Fprintf(w, "serving_projection_projector_consecutive_errors{%s} %d\n", labels, p.ConsecutiveErrors)
+		running := 0
+		if p.State == projectorStateRunning {
+			running = 1
+		}
+		fmt.Fprintf(w, "serving_projection_projector_running{%s} %d\n", labels, running)
+		if p.LagLedgers != nil {
+			fmt.Fprintf(w, "serving_projection_projector_lag_ledgers{%s} %d\n", labels, *p.LagLedgers)
+		}
 
 		if p.LastSuccessAt != nil {
 			fmt.Fprintf(w, "serving_projection_projector_last_success_timestamp_seconds{%s} %d\n", labels, p.LastSuccessAt.Unix())
@@ -204,7 +236,7 @@ func (hs *HealthServer) snapshot() HealthResponse {
 	status := "healthy"
 	starting := false
 	for _, p := range hs.projectors {
-		projectors = append(projectors, *p)
+		projectors = append(projectors, p.withLag())
 		if p.TotalRuns == 0 {
 			starting = true
 		}
@@ -227,6 +259,19 @@ func (hs *HealthServer) snapshot() HealthResponse {
 	}
 }
 
+// withLag returns a copy of the status with LagLedgers filled in.
+func (s *ProjectorRuntimeStatus) withLag() ProjectorRuntimeStatus {
+	c := *s
+	if c.TargetLedger > 0 {
+		lag := c.TargetLedger - c.LastCheckpoint
+		if lag < 0 {
+			lag = 0
+		}
+		c.LagLedgers = &lag
+	}
+	return c
+}
+
 func (hs *HealthServer) GetProjectorCheckpoint(name string) int64 {
 	hs.mu.RLock()
 	defer hs.mu.RUnlock()
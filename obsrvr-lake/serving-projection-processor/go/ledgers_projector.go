@@ -69,6 +69,13 @@ func (p *LedgersRecentProjector) Name() string {
 	return "ledgers_recent"
 }
 
+func (p *LedgersRecentProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads:  []string{"bronze.ledgers_row_v2", "bronze.operations_row_v2"},
+		Writes: []string{"serving.sv_ledger_stats_recent"},
+	}
+}
+
 func (p *LedgersRecentProjector) SourceHighWatermark(ctx context.Context) (int64, error) {
 	var wm int64
 	err := p.sourcePool.QueryRow(ctx, `SELECT COALESCE(MAX(sequence), 0) FROM ledgers_row_v2`).Scan(&wm)
@@ -70,12 +70,22 @@ func buildProjectors(cfg *Config, bronze, silver, serving *pgxpool.Pool, checkpo
 	if cfg.Projectors.EffectsByAccount.Enabled {
 		projectors = append(projectors, NewEffectsByAccountProjector(network, cfg.Projectors.EffectsByAccount.BatchSize, silver, serving, checkpoints))
 	}
+	for _, sp := range cfg.SQLProjectors {
+		if !sp.Enabled {
+			continue
+		}
+		source := bronze
+		if sp.Source == "silver" {
+			source = silver
+		}
+		projectors = append(projectors, NewSQLProjector(sp, network, source, serving, checkpoints))
+	}
 	return projectors
 }
 
 func main() {
 	configPath := flag.String("config", "config.yaml", "Path to config file")
-	applySchemaOnly := flag.Bool("apply-schema-only", false, "Apply serving schema and exit")
+	applySchemaOnly := flag.Bool("apply-schema-only", false, "Apply pending serving schema migrations and exit")
 	flag.Parse()
 
 	cfg, err := LoadConfig(*configPath)
@@ -105,6 +115,14 @@ func main() {
 	}
 	defer servingPool.Close()
 
+	// "migrate status|up|down [N]" manages the serving schema and exits.
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrateCommand(ctx, servingPool, args[1:]); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
 	if cfg.Schema.AutoApply || *applySchemaOnly {
 		log.Println("applying serving schema...")
 		if err := EnsureServingSchema(ctx, servingPool); err != nil {
@@ -138,21 +156,35 @@ func main() {
 		log.Println("no projectors enabled; exiting")
 		return
 	}
-	for _, p := range projectors {
-		healthServer.RegisterProjector(p.Name())
+	scheduler, err := NewProjectorScheduler(healthServer, projectors, cfg.Scheduler.Workers)
+	if err != nil {
+		log.Fatalf("schedule projectors: %v", err)
 	}
-	if err := RunProjectors(ctx, healthServer, projectors, 0); err != nil {
-		log.Printf("initial run error: %v", err)
+	schedulerDone := make(chan struct{})
+	go func() {
+		defer close(schedulerDone)
+		scheduler.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-schedulerDone
+	}()
+	log.Printf("projector scheduler started projectors=%d workers=%d", len(projectors), cfg.Scheduler.Workers)
+
+	scheduler.Trigger(0)
+	if err := scheduler.WaitFirstPass(ctx); err != nil {
+		log.Printf("initial run interrupted: %v", err)
+		return
 	}
 
 	if cfg.Trigger.Mode == "grpc" {
-		runGRPCTriggered(ctx, cfg, healthServer, projectors)
+		runGRPCTriggered(ctx, cfg, healthServer, scheduler)
 		return
 	}
-	runPolling(ctx, cfg, healthServer, projectors)
+	runPolling(ctx, cfg, scheduler)
 }
 
-func runPolling(ctx context.Context, cfg *Config, healthServer *HealthServer, projectors []ProjectorRunner) {
+func runPolling(ctx context.Context, cfg *Config, scheduler *ProjectorScheduler) {
 	ticker := time.NewTicker(cfg.TickInterval())
 	defer ticker.Stop()
 	log.Printf("%s started; trigger_mode=poll tick_interval=%s", cfg.Service.Name, cfg.TickInterval())
@@ -162,42 +194,27 @@ func runPolling(ctx context.Context, cfg *Config, healthServer *HealthServer, pr
 			log.Println("shutdown requested")
 			return
 		case <-ticker.C:
-			_ = RunProjectors(ctx, healthServer, projectors, 0)
+			scheduler.Trigger(0)
 		}
 	}
 }
 
-func runGRPCTriggered(ctx context.Context, cfg *Config, healthServer *HealthServer, projectors []ProjectorRunner) {
+// runGRPCTriggered triggers the scheduler on every silver checkpoint event,
+// and on the fallback poll when events stop. Triggers that arrive while
+// projectors are busy coalesce in the scheduler.
+func runGRPCTriggered(ctx context.Context, cfg *Config, healthServer *HealthServer, scheduler *ProjectorScheduler) {
 	client, err := NewSilverStreamClient(cfg.Trigger.Endpoint)
 	if err != nil {
 		log.Fatalf("create silver stream client: %v", err)
 	}
 	defer client.Close()
 
-	startLedger := highestCheckpoint(healthServer, projectors)
+	startLedger := highestCheckpoint(healthServer, scheduler.Projectors())
 	eventCh := client.StreamCheckpointEvents(ctx, startLedger)
 	fallbackTicker := time.NewTicker(cfg.FallbackPollInterval())
 	defer fallbackTicker.Stop()
 	log.Printf("%s started; trigger_mode=grpc endpoint=%s fallback_poll=%s", cfg.Service.Name, cfg.Trigger.Endpoint, cfg.FallbackPollInterval())
 
-	var pending bool
-	var cycleRunning bool
-	var latestTarget int64
-	cycleDone := make(chan struct{}, 1)
-
-	launchRun := func(reason string) {
-		cycleRunning = true
-		pending = false
-		go func() {
-			log.Printf("triggered projector run reason=%s target_ledger=%d", reason, latestTarget)
-			_ = RunProjectors(ctx, healthServer, projectors, latestTarget)
-			select {
-			case cycleDone <- struct{}{}:
-			case <-ctx.Done():
-			}
-		}()
-	}
-
 	for {
 		select {
 		case <-ctx.Done():
@@ -208,25 +225,9 @@ func runGRPCTriggered(ctx context.Context, cfg *Config, healthServer *HealthServ
 				log.Println("silver trigger stream closed; exiting")
 				return
 			}
-			if int64(evt.EndLedger) > latestTarget {
-				latestTarget = int64(evt.EndLedger)
-			}
-			if cycleRunning {
-				pending = true
-				continue
-			}
-			launchRun("grpc")
+			scheduler.Trigger(int64(evt.EndLedger))
 		case <-fallbackTicker.C:
-			if cycleRunning {
-				pending = true
-				continue
-			}
-			launchRun("fallback_poll")
-		case <-cycleDone:
-			cycleRunning = false
-			if pending || anyProjectorBehind(healthServer, projectors, latestTarget) {
-				launchRun("coalesced")
-			}
+			scheduler.Trigger(0)
 		}
 	}
 }
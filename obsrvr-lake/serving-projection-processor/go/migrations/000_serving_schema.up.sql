@@ -9,6 +9,12 @@
 --   - Assumes PostgreSQL 15+
 --   - Recent feed tables should be partitioned by time in production
 --   - Numeric display columns are optional; they exist to avoid repeated formatting in API handlers
+--
+-- Frozen baseline: this file used to be re-applied on every start with
+-- schema.auto_apply. It is now migration 000 and must not be edited (its
+-- checksum is recorded in obsrvr_lake.schema_migrations); add a new numbered
+-- migration instead. Migrations 001-006 were already folded in here and are
+-- no-ops on a database built from it.
 
 create schema if not exists serving;
 create schema if not exists ops;
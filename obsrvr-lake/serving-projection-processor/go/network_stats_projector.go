@@ -20,6 +20,13 @@ func NewNetworkStatsProjector(network string, targetPool *pgxpool.Pool) *Network
 
 func (p *NetworkStatsProjector) Name() string { return "network_stats" }
 
+func (p *NetworkStatsProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads:  []string{"serving.sv_ledger_stats_recent", "serving.sv_transactions_recent"},
+		Writes: []string{"serving.sv_network_stats_current"},
+	}
+}
+
 func (p *NetworkStatsProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	tx, err := p.targetPool.Begin(ctx)
 	if err != nil {
@@ -50,6 +50,13 @@ func NewOperationsRecentProjector(network string, batchSize int, sourcePool, tar
 
 func (p *OperationsRecentProjector) Name() string { return "operations_recent" }
 
+func (p *OperationsRecentProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads:  []string{"silver.enriched_history_operations"},
+		Writes: []string{"serving.sv_operations_recent"},
+	}
+}
+
 func (p *OperationsRecentProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	checkpoint, err := p.checkpoints.Load(ctx, p.Name(), p.network)
 	if err != nil {
@@ -14,14 +14,14 @@ import (
 
 func servingSchemaTableColumns(t *testing.T, table string) (map[string]bool, map[string]bool) {
 	t.Helper()
-	raw, err := os.ReadFile("schema/serving_schema.sql")
+	raw, err := os.ReadFile("migrations/000_serving_schema.up.sql")
 	if err != nil {
 		t.Fatalf("read serving schema: %v", err)
 	}
 	pattern := regexp.MustCompile(`(?s)create table if not exists ` + regexp.QuoteMeta(table) + `\s*\((.*?)\n\);`)
 	match := pattern.FindSubmatch(raw)
 	if match == nil {
-		t.Fatalf("table %s not found in serving_schema.sql", table)
+		t.Fatalf("table %s not found in the serving schema baseline", table)
 	}
 	columns := map[string]bool{}
 	requiredNoDefault := map[string]bool{}
@@ -88,7 +88,7 @@ func TestTransactionsRecentUpsertColumnsMatchServingSchema(t *testing.T) {
 }
 
 func TestAccountBalancesIncludedInServingUniqueIndexSelfHeal(t *testing.T) {
-	raw, err := os.ReadFile("schema/serving_schema.sql")
+	raw, err := os.ReadFile("migrations/000_serving_schema.up.sql")
 	if err != nil {
 		t.Fatalf("read serving schema: %v", err)
 	}
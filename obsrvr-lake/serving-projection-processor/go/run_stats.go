@@ -25,4 +25,18 @@ type ProjectorRuntimeStatus struct {
 	TotalRowsApplied  int64      `json:"total_rows_applied"`
 	TotalRowsDeleted  int64      `json:"total_rows_deleted"`
 	ConsecutiveErrors int64      `json:"consecutive_errors"`
+	// State is idle, pending or running; DependsOn lists the projectors
+	// that must run first when both are due.
+	State     string   `json:"state"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	// TargetLedger is the ledger the last catch-up run aimed for, and
+	// LagLedgers how far LastCheckpoint is behind it.
+	TargetLedger int64  `json:"target_ledger,omitempty"`
+	LagLedgers   *int64 `json:"lag_ledgers,omitempty"`
 }
+
+const (
+	projectorStateIdle    = "idle"
+	projectorStatePending = "pending"
+	projectorStateRunning = "running"
+)
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const defaultSchedulerWorkers = 4
+
+// ProjectorTables declares the tables a projector reads and writes. Source
+// tables are prefixed with their database ("bronze." or "silver."); serving
+// tables keep their schema ("serving.sv_..."). Tables a projector writes need
+// not be repeated in Reads. The per-projector rows in
+// serving.sv_projection_checkpoints and serving.sv_watermarks are not listed.
+type ProjectorTables struct {
+	Reads  []string
+	Writes []string
+}
+
+// TableDeclaringProjector is implemented by projectors that declare their
+// tables. A projector that does not is treated as touching every table, so it
+// never runs alongside another projector.
+type TableDeclaringProjector interface {
+	ProjectorRunner
+	Tables() ProjectorTables
+}
+
+// ProjectorScheduler runs projectors on a fixed worker budget. Two projectors
+// that share a table never run at the same time: a projector that reads a
+// table waits for the projectors that write it, and writers of the same table
+// keep their build order. Everything else runs concurrently, so a slow
+// catch-up no longer holds back unrelated projectors.
+//
+// Every Trigger requests a run of every projector. Requests coalesce while a
+// projector is pending, and a projector waits only for upstream requests made
+// no later than its own, so a busy upstream cannot starve it.
+type ProjectorScheduler struct {
+	healthServer *HealthServer
+	projectors   []ProjectorRunner
+	upstream     [][]int // projectors whose pending runs must finish first
+	conflicts    [][]int // projectors sharing a table, in either direction
+	workers      int
+
+	mu        sync.Mutex
+	states    []projectorState
+	gen       int64
+	target    int64
+	running   int
+	remaining int // projectors that have not finished a run yet
+	firstPass chan struct{}
+	wake      chan struct{}
+}
+
+type projectorState struct {
+	pending    bool
+	pendingGen int64 // trigger that first requested the pending run
+	running    bool
+	ran        bool
+}
+
+// NewProjectorScheduler works out the dependencies between projectors from
+// their declared tables. It fails when the dependencies form a cycle.
+func NewProjectorScheduler(healthServer *HealthServer, projectors []ProjectorRunner, workers int) (*ProjectorScheduler, error) {
+	if workers <= 0 {
+		workers = defaultSchedulerWorkers
+	}
+	upstream, err := projectorDependencies(projectors)
+	if err != nil {
+		return nil, err
+	}
+	s := &ProjectorScheduler{
+		healthServer: healthServer,
+		projectors:   projectors,
+		upstream:     upstream,
+		conflicts:    make([][]int, len(projectors)),
+		workers:      workers,
+		states:       make([]projectorState, len(projectors)),
+		remaining:    len(projectors),
+		firstPass:    make(chan struct{}),
+		wake:         make(chan struct{}, 1),
+	}
+	for i, deps := range upstream {
+		names := make([]string, len(deps))
+		for j, u := range deps {
+			names[j] = projectors[u].Name()
+			s.conflicts[i] = append(s.conflicts[i], u)
+			s.conflicts[u] = append(s.conflicts[u], i)
+		}
+		healthServer.RegisterProjector(projectors[i].Name())
+		healthServer.SetProjectorDependencies(projectors[i].Name(), names)
+	}
+	if len(projectors) == 0 {
+		close(s.firstPass)
+	}
+	return s, nil
+}
+
+// projectorDependencies returns, for each projector, the projectors it runs
+// after: the writers of every table it reads, earlier writers of the tables
+// it writes, and every earlier projector when either side declares no tables.
+func projectorDependencies(projectors []ProjectorRunner) ([][]int, error) {
+	tables := make([]*ProjectorTables, len(projectors))
+	writers := map[string][]int{}
+	names := map[string]bool{}
+	for i, p := range projectors {
+		if names[p.Name()] {
+			return nil, fmt.Errorf("duplicate projector name %q", p.Name())
+		}
+		names[p.Name()] = true
+		if d, ok := p.(TableDeclaringProjector); ok {
+			t := d.Tables()
+			tables[i] = &t
+			for _, table := range t.Writes {
+				writers[table] = append(writers[table], i)
+			}
+		}
+	}
+
+	upstream := make([][]int, len(projectors))
+	for i := range projectors {
+		deps := map[int]bool{}
+		if tables[i] == nil {
+			for j := 0; j < i; j++ {
+				deps[j] = true
+			}
+		} else {
+			for _, table := range tables[i].Reads {
+				for _, w := range writers[table] {
+					deps[w] = true
+				}
+			}
+			for _, table := range tables[i].Writes {
+				for _, w := range writers[table] {
+					if w < i {
+						deps[w] = true
+					}
+				}
+			}
+			for j := 0; j < i; j++ {
+				if tables[j] == nil {
+					deps[j] = true
+				}
+			}
+		}
+		delete(deps, i)
+		for d := range deps {
+			upstream[i] = append(upstream[i], d)
+		}
+		sort.Ints(upstream[i])
+	}
+
+	if cycle := findDependencyCycle(upstream); cycle != nil {
+		names := make([]string, len(cycle))
+		for i, c := range cycle {
+			names[i] = projectors[c].Name()
+		}
+		return nil, fmt.Errorf("projector dependency cycle: %s", strings.Join(names, " -> "))
+	}
+	return upstream, nil
+}
+
+// findDependencyCycle returns the projectors of a dependency cycle, or nil.
+func findDependencyCycle(upstream [][]int) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make([]int, len(upstream))
+	var stack []int
+	var visit func(int) []int
+	visit = func(i int) []int {
+		marks[i] = visiting
+		stack = append(stack, i)
+		for _, u := range upstream[i] {
+			switch marks[u] {
+			case visiting:
+				for j, s := range stack {
+					if s == u {
+						return append(append([]int(nil), stack[j:]...), u)
+					}
+				}
+			case unvisited:
+				if cycle := visit(u); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		marks[i] = visited
+		return nil
+	}
+	for i := range upstream {
+		if marks[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// Projectors returns the scheduled projectors in build order.
+func (s *ProjectorScheduler) Projectors() []ProjectorRunner {
+	return s.projectors
+}
+
+// Trigger requests a run of every projector. Catch-up projectors run to
+// targetLedger, or to their own source high watermark when it is 0; the
+// highest target seen is kept.
+func (s *ProjectorScheduler) Trigger(targetLedger int64) {
+	s.mu.Lock()
+	s.gen++
+	if targetLedger > s.target {
+		s.target = targetLedger
+	}
+	for i := range s.states {
+		st := &s.states[i]
+		if st.pending {
+			continue
+		}
+		st.pending = true
+		st.pendingGen = s.gen
+		if !st.running {
+			s.healthServer.SetProjectorState(s.projectors[i].Name(), projectorStatePending)
+		}
+	}
+	s.mu.Unlock()
+	s.signal()
+}
+
+// WaitFirstPass blocks until every projector has finished at least one run.
+func (s *ProjectorScheduler) WaitFirstPass(ctx context.Context) error {
+	select {
+	case <-s.firstPass:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run dispatches pending projectors until ctx is cancelled, then waits for
+// the running ones to return.
+func (s *ProjectorScheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		s.mu.Lock()
+		for s.running < s.workers {
+			i := s.next()
+			if i < 0 {
+				break
+			}
+			st := &s.states[i]
+			st.pending = false
+			st.running = true
+			s.running++
+			s.healthServer.SetProjectorState(s.projectors[i].Name(), projectorStateRunning)
+			wg.Add(1)
+			go func(i int, target int64) {
+				defer wg.Done()
+				s.runProjector(ctx, i, target)
+			}(i, s.target)
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		}
+	}
+}
+
+// next picks the runnable projector with the oldest request, preferring
+// build order on ties. It returns -1 when none is runnable. s.mu is held.
+func (s *ProjectorScheduler) next() int {
+	best := -1
+	for i := range s.states {
+		st := &s.states[i]
+		if !st.pending || st.running || !s.runnable(i) {
+			continue
+		}
+		if best < 0 || st.pendingGen < s.states[best].pendingGen {
+			best = i
+		}
+	}
+	return best
+}
+
+// runnable reports whether no projector sharing a table with i is running
+// and no upstream projector has a request at least as old as i's.
+func (s *ProjectorScheduler) runnable(i int) bool {
+	for _, c := range s.conflicts[i] {
+		if s.states[c].running {
+			return false
+		}
+	}
+	for _, u := range s.upstream[i] {
+		if up := s.states[u]; up.pending && up.pendingGen <= s.states[i].pendingGen {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *ProjectorScheduler) runProjector(ctx context.Context, i int, targetLedger int64) {
+	p := s.projectors[i]
+	if cp, ok := p.(CatchupProjector); ok {
+		runs, target, err := runCatchupProjector(ctx, s.healthServer, cp, targetLedger)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("projector run failed projector=%s target=%d runs=%d err=%v", p.Name(), target, runs, err)
+		}
+	} else if err := s.healthServer.RunProjector(ctx, p); err != nil && ctx.Err() == nil {
+		log.Printf("projector run failed projector=%s err=%v", p.Name(), err)
+	}
+
+	s.mu.Lock()
+	st := &s.states[i]
+	st.running = false
+	s.running--
+	if !st.ran {
+		st.ran = true
+		s.remaining--
+		if s.remaining == 0 {
+			close(s.firstPass)
+		}
+	}
+	state := projectorStateIdle
+	if st.pending {
+		state = projectorStatePending
+	}
+	s.healthServer.SetProjectorState(p.Name(), state)
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *ProjectorScheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
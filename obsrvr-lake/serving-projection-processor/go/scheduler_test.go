@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeProjector struct {
+	name   string
+	tables *ProjectorTables // nil leaves Tables undeclared
+	run    func(ctx context.Context) error
+}
+
+func (p *fakeProjector) Name() string { return p.name }
+
+func (p *fakeProjector) RunOnce(ctx context.Context) (RunStats, error) {
+	if p.run == nil {
+		return RunStats{}, nil
+	}
+	return RunStats{}, p.run(ctx)
+}
+
+type declaredFakeProjector struct{ *fakeProjector }
+
+func (p declaredFakeProjector) Tables() ProjectorTables { return *p.tables }
+
+func newFakeProjector(name string, reads, writes []string, run func(context.Context) error) ProjectorRunner {
+	p := &fakeProjector{name: name, run: run}
+	if reads == nil && writes == nil {
+		return p
+	}
+	p.tables = &ProjectorTables{Reads: reads, Writes: writes}
+	return declaredFakeProjector{p}
+}
+
+func startTestScheduler(t *testing.T, projectors []ProjectorRunner, workers int) (*ProjectorScheduler, *HealthServer) {
+	t.Helper()
+	hs := NewHealthServer("test", 0, time.Second)
+	s, err := NewProjectorScheduler(hs, projectors, workers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return s, hs
+}
+
+func TestBuildProjectorsDeclareAcyclicDependencies(t *testing.T) {
+	cfg := &Config{Service: ServiceConfig{Network: "mainnet"}}
+	for _, pc := range []*ProjectorConfig{
+		&cfg.Projectors.LedgersRecent, &cfg.Projectors.TransactionsRecent, &cfg.Projectors.AccountsCurrent,
+		&cfg.Projectors.AccountBalances, &cfg.Projectors.NetworkStats, &cfg.Projectors.AssetStats,
+		&cfg.Projectors.ContractsCurrent, &cfg.Projectors.ContractStorage, &cfg.Projectors.SmartAccounts,
+		&cfg.Projectors.ContractStats, &cfg.Projectors.OperationsRecent, &cfg.Projectors.EventsRecent,
+		&cfg.Projectors.ExplorerEventsRecent, &cfg.Projectors.ContractCallsRecent, &cfg.Projectors.TxReceipts,
+		&cfg.Projectors.EffectsByAccount, &cfg.Projectors.ValidatorIdentities,
+	} {
+		pc.Enabled = true
+	}
+	projectors := buildProjectors(cfg, nil, nil, nil, nil)
+	if len(projectors) != 17 {
+		t.Fatalf("projector count = %d, want 17", len(projectors))
+	}
+
+	schema, err := os.ReadFile("migrations/000_serving_schema.up.sql")
+	if err != nil {
+		t.Fatalf("read serving schema: %v", err)
+	}
+	for _, p := range projectors {
+		d, ok := p.(TableDeclaringProjector)
+		if !ok {
+			t.Fatalf("%s does not declare its tables", p.Name())
+		}
+		tables := d.Tables()
+		for _, table := range append(tables.Reads, tables.Writes...) {
+			if strings.HasPrefix(table, "serving.") && !strings.Contains(string(schema), "create table if not exists "+table+" ") {
+				t.Errorf("%s declares %s, which is not in the serving schema baseline", p.Name(), table)
+			}
+		}
+	}
+
+	hs := NewHealthServer("test", 0, time.Second)
+	if _, err := NewProjectorScheduler(hs, projectors, 4); err != nil {
+		t.Fatal(err)
+	}
+	status := map[string][]string{}
+	for _, p := range hs.snapshot().Projectors {
+		status[p.Name] = p.DependsOn
+	}
+	want := map[string]string{
+		"network_stats":  "ledgers_recent,transactions_recent",
+		"asset_stats":    "account_balances",
+		"contract_stats": "contract_storage,smart_accounts,events_recent,contract_calls_recent",
+		"tx_receipts":    "",
+		"ledgers_recent": "",
+	}
+	for name, deps := range want {
+		if got := strings.Join(status[name], ","); got != deps {
+			t.Errorf("%s depends on %q, want %q", name, got, deps)
+		}
+	}
+}
+
+func TestProjectorDependenciesRejectCycles(t *testing.T) {
+	projectors := []ProjectorRunner{
+		newFakeProjector("a", []string{"serving.y"}, []string{"serving.x"}, nil),
+		newFakeProjector("b", []string{"serving.x"}, []string{"serving.y"}, nil),
+	}
+	_, err := projectorDependencies(projectors)
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> a") && !strings.Contains(err.Error(), "b -> a -> b") {
+		t.Fatalf("err = %v, want a cycle error", err)
+	}
+}
+
+func TestProjectorDependenciesSerializeUndeclaredProjectors(t *testing.T) {
+	projectors := []ProjectorRunner{
+		newFakeProjector("a", nil, []string{"serving.a"}, nil),
+		newFakeProjector("legacy", nil, nil, nil),
+		newFakeProjector("c", nil, []string{"serving.c"}, nil),
+	}
+	upstream, err := projectorDependencies(projectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upstream[0]) != 0 || len(upstream[1]) != 1 || len(upstream[2]) != 1 || upstream[2][0] != 1 {
+		t.Fatalf("upstream = %v", upstream)
+	}
+}
+
+func TestSchedulerRunsIndependentProjectorsWhileOneIsSlow(t *testing.T) {
+	release := make(chan struct{})
+	slowStarted := make(chan struct{}, 1)
+	var mu sync.Mutex
+	fastRuns := 0
+	projectors := []ProjectorRunner{
+		newFakeProjector("slow", []string{"silver.effects"}, []string{"serving.slow"}, func(ctx context.Context) error {
+			slowStarted <- struct{}{}
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return nil
+		}),
+		newFakeProjector("fast", []string{"bronze.ledgers_row_v2"}, []string{"serving.fast"}, func(context.Context) error {
+			mu.Lock()
+			fastRuns++
+			mu.Unlock()
+			return nil
+		}),
+	}
+	s, hs := startTestScheduler(t, projectors, 2)
+
+	s.Trigger(0)
+	<-slowStarted
+	for i := 0; i < 3; i++ {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			mu.Lock()
+			n := fastRuns
+			mu.Unlock()
+			if n > i {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("fast projector ran %d times while slow one was running, want %d", n, i+1)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		s.Trigger(0)
+	}
+
+	for _, p := range hs.snapshot().Projectors {
+		if p.Name == "slow" && p.State != projectorStateRunning {
+			t.Fatalf("slow projector state = %s, want running", p.State)
+		}
+	}
+	close(release)
+	if err := s.WaitFirstPass(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSchedulerRunsReaderAfterWriter(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	writerRunning := false
+	record := func(name string) {
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+	}
+	projectors := []ProjectorRunner{
+		// The reader comes first in build order but must wait for the writer.
+		newFakeProjector("reader", []string{"serving.shared"}, []string{"serving.summary"}, func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if writerRunning {
+				t.Error("reader ran while writer was running")
+			}
+			order = append(order, "reader")
+			return nil
+		}),
+		newFakeProjector("writer", []string{"silver.effects"}, []string{"serving.shared"}, func(context.Context) error {
+			mu.Lock()
+			writerRunning = true
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			writerRunning = false
+			mu.Unlock()
+			record("writer")
+			return nil
+		}),
+	}
+	s, _ := startTestScheduler(t, projectors, 4)
+
+	s.Trigger(0)
+	if err := s.WaitFirstPass(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(order, ","); got != "writer,reader" {
+		t.Fatalf("run order = %s, want writer,reader", got)
+	}
+}
+
+type fakeCatchupProjector struct {
+	*fakeProjector
+	checkpoint int64
+}
+
+func (p *fakeCatchupProjector) Tables() ProjectorTables {
+	return ProjectorTables{Writes: []string{"serving.catchup"}}
+}
+
+func (p *fakeCatchupProjector) SourceHighWatermark(context.Context) (int64, error) { return 100, nil }
+
+func (p *fakeCatchupProjector) RunOnce(context.Context) (RunStats, error) {
+	p.checkpoint += 30
+	if p.checkpoint > 90 {
+		p.checkpoint = 90 // stalls ten ledgers short of the source
+	}
+	return RunStats{Checkpoint: p.checkpoint}, nil
+}
+
+func TestSchedulerReportsCatchupLag(t *testing.T) {
+	p := &fakeCatchupProjector{fakeProjector: &fakeProjector{name: "catchup"}}
+	s, hs := startTestScheduler(t, []ProjectorRunner{p}, 1)
+
+	s.Trigger(0)
+	if err := s.WaitFirstPass(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	status := hs.snapshot().Projectors[0]
+	if status.TargetLedger != 100 || status.LagLedgers == nil || *status.LagLedgers != 10 {
+		t.Fatalf("status = %+v, want target 100 and lag 10", status)
+	}
+}
+
+func TestProjectorDependenciesRejectDuplicateNames(t *testing.T) {
+	projectors := []ProjectorRunner{
+		newFakeProjector("pools", nil, []string{"serving.a"}, nil),
+		newFakeProjector("pools", nil, []string{"serving.b"}, nil),
+	}
+	if _, err := projectorDependencies(projectors); err == nil || !strings.Contains(err.Error(), "duplicate projector name") {
+		t.Fatalf("err = %v, want a duplicate name error", err)
+	}
+}
@@ -2,23 +2,55 @@ package main
 
 import (
 	"context"
-	_ "embed"
+	"embed"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/withObsrvr/obsrvr-lake/pgmigrate"
 )
 
-//go:embed schema/serving_schema.sql
-var servingSchemaSQL string
+// servingMigrations holds the versioned serving schema: the frozen 000
+// baseline plus every numbered change since.
+//
+//go:embed migrations/*.sql
+var servingMigrations embed.FS
 
-func EnsureServingSchema(ctx context.Context, pool *pgxpool.Pool) error {
-	if _, err := pool.Exec(ctx, servingSchemaSQL); err != nil {
-		return fmt.Errorf("apply serving schema: %w", err)
+// migrationComponent identifies this service's rows in obsrvr_lake.schema_migrations.
+const migrationComponent = "serving-projection-processor"
+
+// withServingMigrator runs fn with a migrator over the serving pool.
+func withServingMigrator(pool *pgxpool.Pool, fn func(*pgmigrate.Migrator) error) error {
+	migrations, err := pgmigrate.Load(servingMigrations, "migrations")
+	if err != nil {
+		return err
 	}
-	return nil
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+	return fn(pgmigrate.New(db, migrationComponent, migrations))
+}
+
+// EnsureServingSchema applies any pending serving schema migrations.
+func EnsureServingSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	return withServingMigrator(pool, func(m *pgmigrate.Migrator) error {
+		applied, err := m.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("apply serving schema: %w", err)
+		}
+		log.Printf("serving schema migrations applied=%d", applied)
+		return nil
+	})
+}
+
+// runMigrateCommand runs "migrate status|up|down [N]" against the serving database.
+func runMigrateCommand(ctx context.Context, pool *pgxpool.Pool, args []string) error {
+	return withServingMigrator(pool, func(m *pgmigrate.Migrator) error {
+		return pgmigrate.Command(ctx, m, args, os.Stdout)
+	})
 }
 
 // sourceIndex is a transaction_hash index the tx_receipts projector's batched
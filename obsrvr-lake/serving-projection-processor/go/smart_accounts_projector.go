@@ -21,6 +21,25 @@ func NewSmartAccountsProjector(network string, sourcePool, targetPool *pgxpool.P
 
 func (p *SmartAccountsProjector) Name() string { return "smart_accounts" }
 
+func (p *SmartAccountsProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads: []string{
+			"silver.smart_account_context_rules",
+			"silver.smart_account_signers",
+			"silver.smart_account_policies",
+			"silver.semantic_entities_contracts",
+		},
+		Writes: []string{
+			"serving.sv_smart_account_contracts",
+			"serving.sv_smart_account_contracts_current",
+			"serving.sv_smart_account_rules_current",
+			"serving.sv_smart_account_signers",
+			"serving.sv_smart_account_signers_by_address",
+			"serving.sv_smart_account_signers_by_credential",
+		},
+	}
+}
+
 func (p *SmartAccountsProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	var completeThru, sourceContracts int64
 	if err := p.sourcePool.QueryRow(ctx, `
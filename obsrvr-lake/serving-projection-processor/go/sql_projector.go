@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SQLProjector runs a SQLProjectorConfig.
+type SQLProjector struct {
+	cfg         SQLProjectorConfig
+	network     string
+	sourcePool  *pgxpool.Pool
+	targetPool  *pgxpool.Pool
+	checkpoints *CheckpointStore
+}
+
+func NewSQLProjector(cfg SQLProjectorConfig, network string, sourcePool, targetPool *pgxpool.Pool, checkpoints *CheckpointStore) *SQLProjector {
+	return &SQLProjector{
+		cfg:         cfg,
+		network:     network,
+		sourcePool:  sourcePool,
+		targetPool:  targetPool,
+		checkpoints: checkpoints,
+	}
+}
+
+func (p *SQLProjector) Name() string { return p.cfg.Name }
+
+func (p *SQLProjector) Tables() ProjectorTables {
+	return ProjectorTables{Reads: p.cfg.Reads, Writes: []string{p.cfg.TargetTable}}
+}
+
+func (p *SQLProjector) SourceHighWatermark(ctx context.Context) (int64, error) {
+	var wm *int64
+	if err := p.sourcePool.QueryRow(ctx, p.cfg.HighWatermarkQuery).Scan(&wm); err != nil {
+		return 0, fmt.Errorf("query %s high watermark: %w", p.Name(), err)
+	}
+	if wm == nil {
+		return 0, nil
+	}
+	return *wm, nil
+}
+
+func (p *SQLProjector) RunOnce(ctx context.Context) (RunStats, error) {
+	checkpoint, err := p.checkpoints.Load(ctx, p.cfg.CheckpointKey, p.network)
+	if err != nil {
+		return RunStats{}, err
+	}
+	if checkpoint == 0 && p.cfg.StartLedger > 0 {
+		checkpoint = p.cfg.StartLedger - 1
+	}
+
+	highWatermark, err := p.SourceHighWatermark(ctx)
+	if err != nil {
+		return RunStats{}, err
+	}
+	rangeEnd := checkpoint + p.cfg.BatchLedgers
+	if rangeEnd > highWatermark {
+		rangeEnd = highWatermark
+	}
+
+	var columns []string
+	var batch [][]any
+	if rangeEnd > checkpoint {
+		columns, batch, err = p.loadRange(ctx, checkpoint, rangeEnd)
+		if err != nil {
+			return RunStats{}, err
+		}
+	}
+
+	tx, err := p.targetPool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return RunStats{}, fmt.Errorf("begin %s tx: %w", p.Name(), err)
+	}
+	defer tx.Rollback(ctx)
+
+	var retainedRows int64
+	if p.cfg.Retention != "" {
+		retainedRows, err = applyRecentRetention(ctx, tx, p.cfg.TargetTable, p.cfg.RetentionColumn, p.cfg.Retention)
+		if err != nil {
+			return RunStats{}, err
+		}
+	}
+
+	if rangeEnd <= checkpoint {
+		if err := tx.Commit(ctx); err != nil {
+			return RunStats{}, fmt.Errorf("commit %s retention-only tx: %w", p.Name(), err)
+		}
+		if retainedRows > 0 {
+			log.Printf("projector=%s network=%s retention_deleted=%d checkpoint=%d", p.Name(), p.network, retainedRows, checkpoint)
+		}
+		return RunStats{RowsDeleted: retainedRows, Checkpoint: checkpoint}, nil
+	}
+
+	var lastClosedAt *time.Time
+	if len(batch) > 0 {
+		upsertSQL, err := buildSQLProjectorUpsert(p.cfg.TargetTable, columns, p.cfg.ConflictKey)
+		if err != nil {
+			return RunStats{}, fmt.Errorf("%s: %w", p.Name(), err)
+		}
+		closedAtIndex := -1
+		for i, col := range columns {
+			if col == p.cfg.ClosedAtColumn {
+				closedAtIndex = i
+			}
+		}
+		for _, values := range batch {
+			if _, err := tx.Exec(ctx, upsertSQL, values...); err != nil {
+				return RunStats{}, fmt.Errorf("upsert %s row: %w", p.Name(), err)
+			}
+			if closedAtIndex >= 0 {
+				if t, ok := values[closedAtIndex].(time.Time); ok {
+					lastClosedAt = &t
+				}
+			}
+		}
+	}
+
+	if err := p.checkpoints.Save(ctx, tx, p.cfg.CheckpointKey, p.network, rangeEnd, lastClosedAt); err != nil {
+		return RunStats{}, err
+	}
+	if p.cfg.Watermark {
+		// The table stays complete from the first ledger this projector wrote
+		// as long as each run continues where the watermark ends.
+		ok, completeFrom, completeThru, err := loadServingWatermark(ctx, tx, p.cfg.TargetTable)
+		if err != nil {
+			return RunStats{}, err
+		}
+		if !ok || completeThru < checkpoint {
+			completeFrom = checkpoint + 1
+		}
+		if err := saveServingWatermark(ctx, tx, p.cfg.TargetTable, completeFrom, rangeEnd); err != nil {
+			return RunStats{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return RunStats{}, fmt.Errorf("commit %s tx: %w", p.Name(), err)
+	}
+	log.Printf("projector=%s network=%s applied=%d retention_deleted=%d range=%d-%d checkpoint=%d", p.Name(), p.network, len(batch), retainedRows, checkpoint+1, rangeEnd, rangeEnd)
+	return RunStats{RowsApplied: int64(len(batch)), RowsDeleted: retainedRows, Checkpoint: rangeEnd}, nil
+}
+
+func (p *SQLProjector) loadRange(ctx context.Context, fromExclusive, toInclusive int64) ([]string, [][]any, error) {
+	rows, err := p.sourcePool.Query(ctx, p.cfg.Query, fromExclusive, toInclusive)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query %s: %w", p.Name(), err)
+	}
+	defer rows.Close()
+
+	fields := rows.FieldDescriptions()
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.Name
+	}
+	var batch [][]any
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, nil, fmt.Errorf("scan %s row: %w", p.Name(), err)
+		}
+		batch = append(batch, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate %s rows: %w", p.Name(), err)
+	}
+	return columns, batch, nil
+}
+
+// buildSQLProjectorUpsert builds the upsert for a query's columns. Columns
+// outside the conflict key are overwritten on conflict; when every column is
+// part of the key the row is left alone.
+func buildSQLProjectorUpsert(table string, columns, conflictKey []string) (string, error) {
+	if len(columns) == 0 {
+		return "", fmt.Errorf("query returned no columns")
+	}
+	key := map[string]bool{}
+	for _, col := range conflictKey {
+		key[col] = true
+	}
+	seen := map[string]bool{}
+	quoted := make([]string, len(columns))
+	placeholders := make([]string, len(columns))
+	var updates []string
+	for i, col := range columns {
+		if !sqlIdentifierPattern.MatchString(col) {
+			return "", fmt.Errorf("query column %q must be a lower-case identifier", col)
+		}
+		if seen[col] {
+			return "", fmt.Errorf("query returns column %q twice", col)
+		}
+		seen[col] = true
+		quoted[i] = col
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		if !key[col] {
+			updates = append(updates, col+" = EXCLUDED."+col)
+		}
+	}
+	for _, col := range conflictKey {
+		if !seen[col] {
+			return "", fmt.Errorf("query does not return conflict key column %q", col)
+		}
+	}
+
+	action := "DO NOTHING"
+	if len(updates) > 0 {
+		action = "DO UPDATE SET\n\t\t\t" + strings.Join(updates, ",\n\t\t\t")
+	}
+	return fmt.Sprintf(`
+		INSERT INTO %s (%s) VALUES (%s)
+		ON CONFLICT (%s) %s
+	`, table, strings.Join(quoted, ", "), strings.Join(placeholders, ", "), strings.Join(conflictKey, ", "), action), nil
+}
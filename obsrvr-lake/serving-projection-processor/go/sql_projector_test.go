@@ -0,0 +1,161 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func validSQLProjectorConfig() SQLProjectorConfig {
+	cfg := SQLProjectorConfig{
+		Name:               "liquidity_pools_recent",
+		Enabled:            true,
+		Source:             "silver",
+		Query:              "SELECT pool_id, ledger_sequence, closed_at FROM liquidity_pools WHERE ledger_sequence > $1 AND ledger_sequence <= $2",
+		HighWatermarkQuery: "SELECT MAX(ledger_sequence) FROM liquidity_pools",
+		TargetTable:        "serving.sv_liquidity_pools_recent",
+		ConflictKey:        []string{"pool_id"},
+		Retention:          "7 days",
+		RetentionColumn:    "closed_at",
+	}
+	cfg.applyDefaults()
+	return cfg
+}
+
+func TestSQLProjectorConfigDefaults(t *testing.T) {
+	cfg := validSQLProjectorConfig()
+	if cfg.BatchLedgers != defaultSQLProjectorBatchLedgers || cfg.CheckpointKey != cfg.Name {
+		t.Fatalf("defaults = batch %d checkpoint %q", cfg.BatchLedgers, cfg.CheckpointKey)
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQLProjectorConfigValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*SQLProjectorConfig)
+		want   string
+	}{
+		{"source", func(c *SQLProjectorConfig) { c.Source = "gold" }, "source"},
+		{"range params", func(c *SQLProjectorConfig) { c.Query = "SELECT 1 AS pool_id" }, "ledger range"},
+		{"high watermark", func(c *SQLProjectorConfig) { c.HighWatermarkQuery = " " }, "high_watermark_query"},
+		{"target table", func(c *SQLProjectorConfig) { c.TargetTable = "serving.sv_x; DROP TABLE y" }, "target_table"},
+		{"conflict key", func(c *SQLProjectorConfig) { c.ConflictKey = nil }, "conflict_key"},
+		{"conflict column", func(c *SQLProjectorConfig) { c.ConflictKey = []string{"Pool ID"} }, "column"},
+		{"retention interval", func(c *SQLProjectorConfig) { c.Retention = "7 days'; --" }, "retention"},
+		{"retention column", func(c *SQLProjectorConfig) { c.RetentionColumn = "" }, "retention_column"},
+		{"watermark with retention", func(c *SQLProjectorConfig) { c.Watermark = true }, "watermark"},
+	}
+	for _, tt := range tests {
+		cfg := validSQLProjectorConfig()
+		tt.mutate(&cfg)
+		err := cfg.validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestConfigRejectsSQLProjectorCheckpointCollisions(t *testing.T) {
+	base := Config{
+		Service: ServiceConfig{Network: "mainnet"},
+		Source: SourceConfig{
+			BronzeHot: DatabaseConfig{Host: "bronze"},
+			SilverHot: DatabaseConfig{Host: "silver"},
+		},
+		Target:  TargetConfig{ServingPostgres: DatabaseConfig{Host: "serving"}},
+		Trigger: TriggerConfig{Mode: "poll"},
+	}
+
+	builtin := validSQLProjectorConfig()
+	builtin.CheckpointKey = "ledgers_recent"
+	cfg := base
+	cfg.SQLProjectors = []SQLProjectorConfig{builtin}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "checkpoint_key") {
+		t.Fatalf("err = %v, want a checkpoint_key collision with ledgers_recent", err)
+	}
+
+	first, second := validSQLProjectorConfig(), validSQLProjectorConfig()
+	second.CheckpointKey = "other"
+	cfg = base
+	cfg.SQLProjectors = []SQLProjectorConfig{first, second}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "duplicate name") {
+		t.Fatalf("err = %v, want a duplicate name error", err)
+	}
+
+	cfg.SQLProjectors = []SQLProjectorConfig{first}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildSQLProjectorUpsert(t *testing.T) {
+	sql, err := buildSQLProjectorUpsert("serving.sv_pools", []string{"pool_id", "ledger_sequence", "closed_at"}, []string{"pool_id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"INSERT INTO serving.sv_pools (pool_id, ledger_sequence, closed_at) VALUES ($1, $2, $3)",
+		"ON CONFLICT (pool_id) DO UPDATE SET",
+		"ledger_sequence = EXCLUDED.ledger_sequence",
+		"closed_at = EXCLUDED.closed_at",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("upsert missing %q:\n%s", want, sql)
+		}
+	}
+	if strings.Contains(sql, "pool_id = EXCLUDED") {
+		t.Errorf("upsert overwrites the conflict key:\n%s", sql)
+	}
+
+	sql, err = buildSQLProjectorUpsert("serving.sv_pairs", []string{"a", "b"}, []string{"a", "b"})
+	if err != nil || !strings.Contains(sql, "ON CONFLICT (a, b) DO NOTHING") {
+		t.Fatalf("key-only upsert = %q, %v", sql, err)
+	}
+}
+
+func TestBuildSQLProjectorUpsertRejectsBadColumns(t *testing.T) {
+	tests := []struct {
+		columns []string
+		want    string
+	}{
+		{nil, "no columns"},
+		{[]string{"pool_id", "?column?"}, "identifier"},
+		{[]string{"pool_id", "pool_id"}, "twice"},
+		{[]string{"ledger_sequence"}, "conflict key"},
+	}
+	for _, tt := range tests {
+		_, err := buildSQLProjectorUpsert("serving.sv_pools", tt.columns, []string{"pool_id"})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("columns %v: err = %v, want it to mention %q", tt.columns, err, tt.want)
+		}
+	}
+}
+
+func TestBuildProjectorsAppendsEnabledSQLProjectors(t *testing.T) {
+	disabled := validSQLProjectorConfig()
+	disabled.Name = "disabled_pools"
+	disabled.Enabled = false
+	cfg := &Config{
+		Service:       ServiceConfig{Network: "mainnet"},
+		SQLProjectors: []SQLProjectorConfig{validSQLProjectorConfig(), disabled},
+	}
+	cfg.Projectors.LedgersRecent.Enabled = true
+	projectors := buildProjectors(cfg, nil, nil, nil, nil)
+	if len(projectors) != 2 || projectors[1].Name() != "liquidity_pools_recent" {
+		t.Fatalf("projectors = %v", projectorNames(projectors))
+	}
+	tables := projectors[1].(TableDeclaringProjector).Tables()
+	if len(tables.Writes) != 1 || tables.Writes[0] != "serving.sv_liquidity_pools_recent" {
+		t.Fatalf("tables = %+v", tables)
+	}
+}
+
+func projectorNames(projectors []ProjectorRunner) []string {
+	names := make([]string, len(projectors))
+	for i, p := range projectors {
+		names[i] = p.Name()
+	}
+	return names
+}
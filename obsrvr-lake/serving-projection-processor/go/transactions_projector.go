@@ -60,7 +60,19 @@ func NewTransactionsRecentProjector(network string, batchSize int, sourcePool, s
 
 func (p *TransactionsRecentProjector) Name() string { return "transactions_recent" }
 
-// transactionsRecentUpsertSQL is pinned against serving_schema.sql by
+func (p *TransactionsRecentProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads: []string{
+			"bronze.transactions_row_v2",
+			"silver.enriched_history_operations",
+			"silver.token_transfers_raw",
+			"silver.contract_invocations_raw",
+		},
+		Writes: []string{"serving.sv_transactions_recent"},
+	}
+}
+
+// transactionsRecentUpsertSQL is pinned against the serving schema baseline by
 // TestTransactionsRecentUpsertColumnsMatchServingSchema.
 const transactionsRecentUpsertSQL = `
 			INSERT INTO serving.sv_transactions_recent (
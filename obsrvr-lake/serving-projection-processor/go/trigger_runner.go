@@ -15,30 +15,13 @@ type CatchupProjector interface {
 }
 
 // maxCatchupRunsPerProjector caps how many consecutive RunOnce iterations a
-// single CatchupProjector can do per tick before yielding to the next projector
-// in the order. Without this, a slow source-bound projector (e.g. tx_receipts)
-// can monopolize the tick and starve later projectors (events_recent,
-// explorer_events_recent, etc.) — they only get a turn when the catchup loop
-// yields. Cap is intentionally small to keep ordering fair; over time, repeated
-// ticks still drive every catchup projector to its target.
+// single CatchupProjector does per scheduled run. The scheduler runs
+// unrelated projectors alongside it, but a long catch-up still holds a worker
+// and delays the projectors that read its tables; yielding lets them run on
+// what has been projected so far. Later triggers drive every catch-up
+// projector to its target.
 const maxCatchupRunsPerProjector = 200
 
-func RunProjectors(ctx context.Context, healthServer *HealthServer, projectors []ProjectorRunner, targetLedger int64) error {
-	for _, p := range projectors {
-		if cp, ok := p.(CatchupProjector); ok {
-			runs, target, err := runCatchupProjector(ctx, healthServer, cp, targetLedger)
-			if err != nil {
-				log.Printf("projector run failed projector=%s target=%d runs=%d err=%v", p.Name(), target, runs, err)
-			}
-			continue
-		}
-		if err := healthServer.RunProjector(ctx, p); err != nil {
-			log.Printf("projector run failed projector=%s err=%v", p.Name(), err)
-		}
-	}
-	return nil
-}
-
 func runCatchupProjector(ctx context.Context, healthServer *HealthServer, p CatchupProjector, targetLedger int64) (int, int64, error) {
 	target := targetLedger
 	if target <= 0 {
@@ -51,6 +34,7 @@ func runCatchupProjector(ctx context.Context, healthServer *HealthServer, p Catc
 	if target <= 0 {
 		return 0, 0, nil
 	}
+	healthServer.SetProjectorTarget(p.Name(), target)
 
 	lastCheckpoint := healthServer.GetProjectorCheckpoint(p.Name())
 	for run := 1; run <= maxCatchupRunsPerProjector; run++ {
@@ -82,12 +66,3 @@ func highestCheckpoint(healthServer *HealthServer, projectors []ProjectorRunner)
 	}
 	return max
 }
-
-func anyProjectorBehind(healthServer *HealthServer, projectors []ProjectorRunner, target int64) bool {
-	for _, p := range projectors {
-		if healthServer.GetProjectorCheckpoint(p.Name()) < target {
-			return true
-		}
-	}
-	return false
-}
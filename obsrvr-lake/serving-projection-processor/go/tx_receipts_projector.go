@@ -53,6 +53,19 @@ func NewTxReceiptsProjector(network string, batchSize int, bronzePool, silverPoo
 
 func (p *TxReceiptsProjector) Name() string { return "tx_receipts" }
 
+func (p *TxReceiptsProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Reads: []string{
+			"silver.enriched_history_operations",
+			"silver.effects",
+			"silver.token_transfers_raw",
+			"silver.semantic_activities",
+			"bronze.transactions_row_v2",
+		},
+		Writes: []string{"serving.sv_tx_receipts"},
+	}
+}
+
 func (p *TxReceiptsProjector) SourceHighWatermark(ctx context.Context) (int64, error) {
 	var wm int64
 	err := p.silverPool.QueryRow(ctx, `SELECT COALESCE(MAX(ledger_sequence), 0) FROM enriched_history_operations`, pgx.QueryExecModeSimpleProtocol).Scan(&wm)
@@ -49,6 +49,13 @@ func NewValidatorIdentityProjector(network, baseURL string, timeout time.Duratio
 
 func (p *ValidatorIdentityProjector) Name() string { return "validator_identities" }
 
+// Identities come from the Radar API, not from a source table.
+func (p *ValidatorIdentityProjector) Tables() ProjectorTables {
+	return ProjectorTables{
+		Writes: []string{"serving.sv_validator_identity_current", "serving.sv_validator_identity_history"},
+	}
+}
+
 func (p *ValidatorIdentityProjector) RunOnce(ctx context.Context) (RunStats, error) {
 	nodes, err := p.loadNodes(ctx)
 	if err != nil {
@@ -8,6 +8,7 @@ require (
 	github.com/lib/pq v1.10.9
 	github.com/stellar/go-stellar-sdk v0.6.0
 	github.com/withObsrvr/flow-proto v0.1.3
+	github.com/withObsrvr/obsrvr-lake/pgmigrate v0.0.0-00010101000000-000000000000
 	google.golang.org/grpc v1.79.3
 	google.golang.org/protobuf v1.36.11
 	gopkg.in/yaml.v3 v3.0.1
@@ -51,3 +52,5 @@ require (
 	golang.org/x/xerrors v0.0.0-20240903120638-7835f813f4da // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20260209200024-4cfbd4190f57 // indirect
 )
+
+replace github.com/withObsrvr/obsrvr-lake/pgmigrate => ../../pgmigrate
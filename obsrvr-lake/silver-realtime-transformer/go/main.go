@@ -61,6 +61,14 @@ func main() {
 		log.Fatalf("Invalid config: %v", err)
 	}
 
+	// "migrate status|up|down [N]" manages the silver_hot schema and exits.
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrateCommand(config, args[1:]); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
 	log.Printf("📋 Service: %s", config.Service.Name)
 	log.Printf("📋 Poll interval: %v", config.PollInterval())
 	log.Printf("📋 Batch size: %d ledgers", config.Performance.BatchSize)
@@ -97,8 +105,8 @@ func main() {
 	}
 	log.Println("✅ Connected to Silver Hot")
 
-	// Ensure silver_hot schema is up to date (CREATE IF NOT EXISTS)
-	if err := EnsureSilverHotSchema(silverDB); err != nil {
+	// Apply pending silver_hot migrations before anything reads or writes it
+	if err := EnsureSilverHotSchema(context.Background(), silverDB); err != nil {
 		log.Fatalf("Failed to ensure silver_hot schema: %v", err)
 	}
 
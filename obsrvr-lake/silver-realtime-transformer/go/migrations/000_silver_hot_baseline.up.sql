@@ -2,6 +2,11 @@
 -- PostgreSQL schema for real-time silver transformations
 -- Database: silver_hot
 -- Created: 2025-12-16 (Cycle 1 Day 1)
+--
+-- Frozen baseline: this file used to be re-executed in full on every
+-- transformer start. It is now migration 000 and must not be edited (its checksum
+-- is recorded in obsrvr_lake.schema_migrations); add a new numbered migration
+-- instead.
 
 -- ============================================================================
 -- PHASE 3: ENRICHED OPERATIONS TABLES (2 tables)
@@ -1143,5 +1148,11 @@ ALTER TABLE accounts_snapshot ADD COLUMN IF NOT EXISTS sequence_time BIGINT;
 -- ============================================================================
 
 -- Grant permissions to stellar user (already owner, but explicit for clarity)
-GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO stellar;
-GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA public TO stellar;
+-- Skipped where the role does not exist (local and test databases).
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'stellar') THEN
+        GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO stellar;
+        GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA public TO stellar;
+    END IF;
+END $$;
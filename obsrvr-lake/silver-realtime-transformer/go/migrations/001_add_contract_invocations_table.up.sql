@@ -2,8 +2,6 @@
 -- Purpose: Store transformed contract invocations with TOID support
 -- Date: 2026-01-03
 
-BEGIN;
-
 -- Create contract_invocations_raw table
 CREATE TABLE IF NOT EXISTS contract_invocations_raw (
     -- TOID components (enables TOID computation)
@@ -53,5 +51,3 @@ BEGIN
 
     RAISE NOTICE 'Successfully created contract_invocations_raw table with indexes';
 END $$;
-
-COMMIT;
@@ -0,0 +1,5 @@
+DROP VIEW IF EXISTS v_contract_callees;
+DROP VIEW IF EXISTS v_contract_callers;
+DROP VIEW IF EXISTS v_contract_call_summary;
+DROP TABLE IF EXISTS contract_invocation_hierarchy;
+DROP TABLE IF EXISTS contract_invocation_calls;
@@ -2,8 +2,6 @@
 -- Purpose: Silver layer tables for Freighter "Contracts Involved" feature
 -- Date: 2026-01-04
 
-BEGIN;
-
 -- ============================================================================
 -- Table: contract_invocation_calls
 -- Flattened cross-contract call relationships from Bronze call graphs
@@ -183,5 +181,3 @@ BEGIN
 
     RAISE NOTICE 'Successfully created Silver layer contract call tables';
 END $$;
-
-COMMIT;
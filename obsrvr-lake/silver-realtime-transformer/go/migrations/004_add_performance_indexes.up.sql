@@ -0,0 +1,10 @@
+-- Phase 4: Performance indexes for silver hot.
+-- Uses CONCURRENTLY to avoid blocking writes. The bronze hot (stellar_hot)
+-- half of this migration lives in stellar-postgres-ingester migration 010.
+-- pgmigrate:no-transaction
+
+-- Supports the semantic_entities_contracts Phase 3 fix (incremental upsert)
+-- and general semantic transform performance.
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_contract_invocations_contract_id
+    ON contract_invocations_raw (contract_id);
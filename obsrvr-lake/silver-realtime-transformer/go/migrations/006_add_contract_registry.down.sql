@@ -0,0 +1,3 @@
+-- The pg_trgm extension and the token_registry XLM seed are left in place:
+-- other objects may depend on them.
+DROP TABLE IF EXISTS contract_registry;
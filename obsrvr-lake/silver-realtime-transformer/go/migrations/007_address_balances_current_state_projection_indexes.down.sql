@@ -0,0 +1,5 @@
+-- pgmigrate:no-transaction
+
+DROP INDEX CONCURRENTLY IF EXISTS idx_address_balances_source_updated;
+DROP INDEX CONCURRENTLY IF EXISTS idx_trustlines_last_modified_account_asset;
+DROP INDEX CONCURRENTLY IF EXISTS idx_native_balances_last_modified_account;
@@ -1,5 +1,6 @@
 -- Performance indexes for address_balances_current state projection.
 -- Run on silver_hot. Uses CONCURRENTLY to avoid blocking hot writes.
+-- pgmigrate:no-transaction
 
 CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_native_balances_last_modified_account
     ON native_balances_current (last_modified_ledger, account_id);
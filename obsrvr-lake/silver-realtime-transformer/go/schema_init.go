@@ -1,52 +1,67 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	_ "embed"
+	"embed"
 	"fmt"
 	"log"
-	"strings"
+	"os"
 	"time"
+
+	"github.com/withObsrvr/obsrvr-lake/pgmigrate"
 )
 
-//go:embed schema/init_silver_hot_complete.sql
-var silverHotSchema string
+// silverHotMigrations holds the versioned silver_hot schema: the frozen
+// 000 baseline plus every numbered change since. Edit the schema by adding a
+// migration, never by changing an applied one.
+//
+//go:embed migrations/*.sql
+var silverHotMigrations embed.FS
+
+// migrationComponent identifies this service's rows in obsrvr_lake.schema_migrations.
+const migrationComponent = "silver-realtime-transformer"
+
+// newSilverHotMigrator loads the embedded migrations for silver_hot.
+func newSilverHotMigrator(db *sql.DB) (*pgmigrate.Migrator, error) {
+	migrations, err := pgmigrate.Load(silverHotMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return pgmigrate.New(db, migrationComponent, migrations), nil
+}
 
-// EnsureSilverHotSchema runs the embedded DDL against silver_hot.
-// Statements are executed individually so that index failures on existing tables
-// (e.g. column mismatches from schema drift) don't block table creation.
-func EnsureSilverHotSchema(db *sql.DB) error {
-	log.Println("🔧 Ensuring silver_hot schema is up to date...")
+// EnsureSilverHotSchema applies any pending silver_hot migrations. A failing
+// statement stops startup: a half-applied schema is worse than no transformer.
+func EnsureSilverHotSchema(ctx context.Context, db *sql.DB) error {
+	log.Println("🔧 Applying pending silver_hot migrations...")
 
-	statements := splitSQL(silverHotSchema)
+	migrator, err := newSilverHotMigrator(db)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
 
-	var created, skipped, failed int
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" {
-			continue
-		}
+	log.Printf("✅ Silver hot schema up to date (%d migrations applied)", applied)
+	return nil
+}
 
-		_, err := db.Exec(stmt)
-		if err != nil {
-			// Log but don't fail on index/grant errors — the table itself is what matters
-			upper := strings.ToUpper(stmt)
-			if strings.HasPrefix(upper, "CREATE TABLE") {
-				return fmt.Errorf("failed to create table: %w\nStatement: %.200s", err, stmt)
-			}
-			log.Printf("  ⚠️  Skipped (non-fatal): %v", err)
-			failed++
-		} else {
-			if strings.HasPrefix(strings.ToUpper(stmt), "CREATE TABLE") {
-				created++
-			} else {
-				skipped++ // indexes, grants, inserts — counted as "other"
-			}
-		}
+// runMigrateCommand runs "migrate status|up|down [N]" against silver_hot.
+func runMigrateCommand(config *Config, args []string) error {
+	db, err := sql.Open("pgx", config.SilverHot.ConnectionString())
+	if err != nil {
+		return fmt.Errorf("connect to silver hot: %w", err)
 	}
+	defer db.Close()
 
-	log.Printf("✅ Silver hot schema verified (%d table stmts ok, %d other stmts ok, %d non-fatal errors)", created, skipped, failed)
-	return nil
+	migrator, err := newSilverHotMigrator(db)
+	if err != nil {
+		return err
+	}
+	return pgmigrate.Command(context.Background(), migrator, args, os.Stdout)
 }
 
 // participantIndex is a partial index on a participant column of enriched_history_operations that
@@ -98,39 +113,3 @@ func EnsureSilverHotIndexes(db *sql.DB) {
 		log.Printf("silver-index: %s ready in %s", x.name, time.Since(start).Round(time.Second))
 	}
 }
-
-// splitSQL splits a SQL file into individual statements on semicolons,
-// respecting dollar-quoted strings ($$...$$) used in DO blocks.
-func splitSQL(sql string) []string {
-	var statements []string
-	var current strings.Builder
-	inDollarQuote := false
-
-	for i := 0; i < len(sql); i++ {
-		if sql[i] == '$' && i+1 < len(sql) && sql[i+1] == '$' {
-			inDollarQuote = !inDollarQuote
-			current.WriteByte(sql[i])
-			current.WriteByte(sql[i+1])
-			i++
-			continue
-		}
-
-		if sql[i] == ';' && !inDollarQuote {
-			s := strings.TrimSpace(current.String())
-			if s != "" {
-				statements = append(statements, s)
-			}
-			current.Reset()
-			continue
-		}
-
-		current.WriteByte(sql[i])
-	}
-
-	// Handle trailing statement without semicolon
-	if s := strings.TrimSpace(current.String()); s != "" {
-		statements = append(statements, s)
-	}
-
-	return statements
-}
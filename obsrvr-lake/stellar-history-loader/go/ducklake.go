@@ -179,52 +179,11 @@ func (p *DuckLakePusher) Push(ctx context.Context, outputDir string) (*DuckLakeP
 		}
 
 		parquetGlob := filepath.Join(bronzeDir, tableName, "**", "*.parquet")
-		fullTableName := fmt.Sprintf("%s.%s.%s", p.config.CatalogName, p.config.SchemaName, duckTableName)
-
-		// Get Parquet columns. A failed DESCRIBE here previously fell through to a
-		// positional INSERT ... SELECT *, which can silently write misaligned or
-		// partial columns into Bronze while still reporting success. Treat any
-		// column-discovery failure as fatal so a backfill never reports success on
-		// a wrong-shaped insert.
-		parquetColSQL := fmt.Sprintf("SELECT column_name FROM (DESCRIBE SELECT * FROM read_parquet('%s'))", parquetGlob)
-		parquetCols, err := describeColumns(ctx, p.db, parquetColSQL)
+		fullTableName := p.fullTableName(duckTableName)
+		insertSQL, err := p.parquetInsertSQL(ctx, duckTableName, parquetGlob)
 		if err != nil {
-			return nil, fmt.Errorf("describe parquet columns for %s: %w", duckTableName, err)
-		}
-		if len(parquetCols) == 0 {
-			return nil, fmt.Errorf("no parquet columns found for %s (glob %s)", duckTableName, parquetGlob)
-		}
-
-		// Get DuckLake table columns to find the intersection
-		// (Parquet may have extra columns the table doesn't, e.g. list types).
-		tableColSQL := fmt.Sprintf("SELECT column_name FROM (DESCRIBE %s)", fullTableName)
-		tableCols, err := describeColumns(ctx, p.db, tableColSQL)
-		if err != nil {
-			return nil, fmt.Errorf("describe table columns for %s: %w", duckTableName, err)
-		}
-		tableColSet := make(map[string]bool, len(tableCols))
-		for _, col := range tableCols {
-			tableColSet[col] = true
-		}
-
-		// Use only columns that exist in BOTH Parquet and DuckLake table.
-		var cols []string
-		for _, col := range parquetCols {
-			if tableColSet[col] {
-				cols = append(cols, col)
-			}
-		}
-		if len(cols) == 0 {
-			return nil, fmt.Errorf("no shared columns between parquet and table %s; refusing positional insert", duckTableName)
-		}
-
-		// Quote every column name to handle reserved words like "from", "to".
-		quoted := make([]string, len(cols))
-		for i, c := range cols {
-			quoted[i] = fmt.Sprintf(`"%s"`, c)
+			return nil, err
 		}
-		colList := strings.Join(quoted, ", ")
-		insertSQL := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM read_parquet('%s')", fullTableName, colList, colList, parquetGlob)
 
 		// Delete-before-insert for idempotent multi-push. Keep delete and insert
 		// in one transaction and fail hard on any table error; otherwise a failed
@@ -302,6 +261,207 @@ func (p *DuckLakePusher) Push(ctx context.Context, outputDir string) (*DuckLakeP
 	return pushResult, nil
 }
 
+// ReplaceRange replaces the rows of the selected bronze tables between
+// startLedger and endLedger with the Parquet output under outputDir, in one
+// transaction. A selected table without Parquet output has its rows in the
+// range deleted: the archive produced none for it. Setup and createTables
+// must have run.
+func (p *DuckLakePusher) ReplaceRange(ctx context.Context, outputDir string, sourceTables []string, startLedger, endLedger uint32) (map[string]int64, error) {
+	bronzeDir := filepath.Join(outputDir, "bronze")
+
+	// Describe every table before the transaction takes the only connection.
+	insertSQL := map[string]string{}
+	for _, table := range sourceTables {
+		if !hasParquetOutput(bronzeDir, table) {
+			continue
+		}
+		duckTableName := mapToDuckLakeTable(table)
+		stmt, err := p.parquetInsertSQL(ctx, duckTableName, filepath.Join(bronzeDir, table, "**", "*.parquet"))
+		if err != nil {
+			return nil, err
+		}
+		insertSQL[table] = stmt + fmt.Sprintf(" WHERE %s BETWEEN %d AND %d", ledgerSequenceColumn(duckTableName), startLedger, endLedger)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin DuckLake repair transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+				log.Printf("[DuckLake] rollback failed for repair %d-%d: %v", startLedger, endLedger, rbErr)
+			}
+		}
+	}()
+
+	counts := map[string]int64{}
+	for _, table := range sourceTables {
+		duckTableName := mapToDuckLakeTable(table)
+		deleteSQL := fmt.Sprintf("DELETE FROM %s WHERE %s BETWEEN %d AND %d",
+			p.fullTableName(duckTableName), ledgerSequenceColumn(duckTableName), startLedger, endLedger)
+		delResult, err := tx.ExecContext(ctx, deleteSQL)
+		if err != nil {
+			return nil, fmt.Errorf("delete %s rows in range %d-%d: %w", duckTableName, startLedger, endLedger, err)
+		}
+		deleted, _ := delResult.RowsAffected()
+
+		var inserted int64
+		if stmt, ok := insertSQL[table]; ok {
+			result, err := tx.ExecContext(ctx, stmt)
+			if err != nil {
+				return nil, fmt.Errorf("insert repaired %s rows: %w", duckTableName, err)
+			}
+			inserted, _ = result.RowsAffected()
+		}
+		counts[duckTableName] = inserted
+		log.Printf("[DuckLake] %s: replaced %d rows with %d in range %d-%d", duckTableName, deleted, inserted, startLedger, endLedger)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit DuckLake repair %d-%d: %w", startLedger, endLedger, err)
+	}
+	committed = true
+	return counts, nil
+}
+
+// RecordRepairLineage appends repair lineage records to
+// _meta_repair_lineage in one transaction. A repair records them only once
+// every store holds the repaired rows, since silver recomputes from them.
+func (p *DuckLakePusher) RecordRepairLineage(ctx context.Context, lineage []LineageRecord) error {
+	if err := p.ensureLineageTable(ctx); err != nil {
+		return err
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin DuckLake lineage transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+				log.Printf("[DuckLake] rollback failed for repair lineage: %v", rbErr)
+			}
+		}
+	}()
+
+	lineageSQL := fmt.Sprintf(`
+		INSERT INTO %s (
+			lineage_id, dataset, source_ledger_start, source_ledger_end, pipeline_name, pipeline_version,
+			records_processed, started_at, completed_at, duration_ms, worker_id, operation, repair_id, repair_reason
+		) VALUES (?, ?, ?, ?, ?, ?, ?, make_timestamp(?), make_timestamp(?), ?, ?, ?, ?, ?)
+	`, p.fullTableName(repairLineageTable))
+	for _, r := range lineage {
+		if _, err := tx.ExecContext(ctx, lineageSQL,
+			r.LineageID, r.Dataset, r.SourceLedgerStart, r.SourceLedgerEnd, r.PipelineName, r.PipelineVersion,
+			r.RecordsProcessed, r.StartedAt, r.CompletedAt, r.DurationMs, r.WorkerID, r.Operation, r.RepairID, r.RepairReason,
+		); err != nil {
+			return fmt.Errorf("record repair lineage %s: %w", r.LineageID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit DuckLake repair lineage: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// repairLineageTable holds the loader's repair lineage. It is kept apart from
+// _meta_lineage, whose layout belongs to the streaming ingester and whose rows
+// the resolver reads as committed coverage.
+const repairLineageTable = "_meta_repair_lineage"
+
+// ensureLineageTable creates the DuckLake table that mirrors LineageRecord.
+func (p *DuckLakePusher) ensureLineageTable(ctx context.Context) error {
+	createSQL := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			lineage_id VARCHAR,
+			dataset VARCHAR,
+			source_ledger_start UINTEGER,
+			source_ledger_end UINTEGER,
+			pipeline_name VARCHAR,
+			pipeline_version VARCHAR,
+			records_processed BIGINT,
+			started_at TIMESTAMP,
+			completed_at TIMESTAMP,
+			duration_ms BIGINT,
+			worker_id INTEGER,
+			operation VARCHAR,
+			repair_id VARCHAR,
+			repair_reason VARCHAR
+		)
+	`, p.fullTableName(repairLineageTable))
+	if _, err := p.db.ExecContext(ctx, createSQL); err != nil {
+		return fmt.Errorf("create repair lineage table: %w", err)
+	}
+	return nil
+}
+
+func (p *DuckLakePusher) fullTableName(duckTableName string) string {
+	return fmt.Sprintf("%s.%s.%s", p.config.CatalogName, p.config.SchemaName, duckTableName)
+}
+
+// parquetInsertSQL builds the INSERT that copies a table's Parquet output into
+// DuckLake by column name. It describes both sides on p.db, so it must not be
+// called while a transaction holds the pusher's only connection.
+func (p *DuckLakePusher) parquetInsertSQL(ctx context.Context, duckTableName, parquetGlob string) (string, error) {
+	fullTableName := p.fullTableName(duckTableName)
+
+	// Get Parquet columns. A failed DESCRIBE here previously fell through to a
+	// positional INSERT ... SELECT *, which can silently write misaligned or
+	// partial columns into Bronze while still reporting success. Treat any
+	// column-discovery failure as fatal so a backfill never reports success on
+	// a wrong-shaped insert.
+	parquetColSQL := fmt.Sprintf("SELECT column_name FROM (DESCRIBE SELECT * FROM read_parquet('%s'))", parquetGlob)
+	parquetCols, err := describeColumns(ctx, p.db, parquetColSQL)
+	if err != nil {
+		return "", fmt.Errorf("describe parquet columns for %s: %w", duckTableName, err)
+	}
+	if len(parquetCols) == 0 {
+		return "", fmt.Errorf("no parquet columns found for %s (glob %s)", duckTableName, parquetGlob)
+	}
+
+	// Get DuckLake table columns to find the intersection
+	// (Parquet may have extra columns the table doesn't, e.g. list types).
+	tableColSQL := fmt.Sprintf("SELECT column_name FROM (DESCRIBE %s)", fullTableName)
+	tableCols, err := describeColumns(ctx, p.db, tableColSQL)
+	if err != nil {
+		return "", fmt.Errorf("describe table columns for %s: %w", duckTableName, err)
+	}
+	tableColSet := make(map[string]bool, len(tableCols))
+	for _, col := range tableCols {
+		tableColSet[col] = true
+	}
+
+	// Use only columns that exist in BOTH Parquet and DuckLake table.
+	var cols []string
+	for _, col := range parquetCols {
+		if tableColSet[col] {
+			cols = append(cols, col)
+		}
+	}
+	if len(cols) == 0 {
+		return "", fmt.Errorf("no shared columns between parquet and table %s; refusing positional insert", duckTableName)
+	}
+
+	// Quote every column name to handle reserved words like "from", "to".
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = fmt.Sprintf(`"%s"`, c)
+	}
+	colList := strings.Join(quoted, ", ")
+	return fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM read_parquet('%s')", fullTableName, colList, colList, parquetGlob), nil
+}
+
+// hasParquetOutput reports whether bronzeDir holds Parquet output for table.
+func hasParquetOutput(bronzeDir, table string) bool {
+	matches, _ := filepath.Glob(filepath.Join(bronzeDir, table, "*", "*.parquet"))
+	return len(matches) > 0
+}
+
 func (p *DuckLakePusher) shouldSkipTable(sourceName, duckTableName string) bool {
 	if len(p.config.OnlyTables) > 0 && !tableSetIncludes(p.config.OnlyTables, sourceName, duckTableName) {
 		return true
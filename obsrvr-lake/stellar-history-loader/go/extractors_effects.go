@@ -652,6 +652,7 @@ func (e *efx) addCreateClaimableBalanceEffects() {
 			cv0 := c.MustV0()
 			e.addUnmuxed(&cv0.Destination, EffectClaimableBalanceClaimantCreated, map[string]interface{}{
 				"balance_id": id, "amount": amount.String(cb.Amount), "asset": cb.Asset.StringCanonical(),
+				"predicate": cv0.Predicate,
 			})
 		}
 		debitDetails := map[string]interface{}{"amount": amount.String(cb.Amount)}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"path/filepath"
@@ -68,6 +69,51 @@ func (h *HotBufferLoader) Close() error {
 	return h.db.Close()
 }
 
+// hotBufferTable is a PostgreSQL hot buffer table loaded from a bronze
+// Parquet directory.
+type hotBufferTable struct {
+	name      string
+	bronzeDir string
+	columns   string
+	seqFilter string // column name for ledger_sequence filtering
+}
+
+var hotBufferTables = []hotBufferTable{
+	{
+		name:      "transactions_row_v2",
+		bronzeDir: "transactions",
+		columns:   "ledger_sequence, transaction_hash, source_account, fee_charged, max_fee, successful, transaction_result_code, operation_count, memo_type, memo, created_at, account_sequence, ledger_range, signatures_count, tx_envelope, tx_result, tx_meta, tx_fee_meta, tx_signers, new_account, rent_fee_charged, soroban_resources_instructions, soroban_resources_read_bytes, soroban_resources_write_bytes",
+		seqFilter: "ledger_sequence",
+	},
+	{
+		name:      "operations_row_v2",
+		bronzeDir: "operations",
+		// Column names match v3_bronze_schema.sql / streaming PG hot:
+		// the operation type column is `type`, not `op_type`. Loader's
+		// Parquet output also uses `type` (see ParquetOperation tag).
+		columns:   "ledger_sequence, transaction_hash, operation_index, source_account, type, type_string, created_at, transaction_successful, operation_result_code, ledger_range, amount, asset, destination, soroban_operation, soroban_contract_id, soroban_function, soroban_arguments_json, contract_calls_json, max_call_depth",
+		seqFilter: "ledger_sequence",
+	},
+	{
+		name:      "contract_events_stream_v1",
+		bronzeDir: "contract_events",
+		columns:   "event_id, contract_id, ledger_sequence, transaction_hash, closed_at, event_type, in_successful_contract_call, topics_json, topics_decoded, data_xdr, data_decoded, topic_count, topic0_decoded, topic1_decoded, topic2_decoded, topic3_decoded, operation_index, event_index, ledger_range",
+		seqFilter: "ledger_sequence",
+	},
+	{
+		name:      "accounts_snapshot_v1",
+		bronzeDir: "accounts_snapshot",
+		columns:   "account_id, ledger_sequence, closed_at, balance, sequence_number, sequence_ledger, sequence_time, num_subentries, num_sponsoring, num_sponsored, home_domain, master_weight, low_threshold, med_threshold, high_threshold, flags, auth_required, auth_revocable, auth_immutable, auth_clawback_enabled, signers, sponsor_account, ledger_range",
+		seqFilter: "ledger_sequence",
+	},
+	{
+		name:      "native_balances_snapshot_v1",
+		bronzeDir: "native_balances",
+		columns:   "account_id, balance, buying_liabilities, selling_liabilities, num_subentries, num_sponsoring, num_sponsored, sequence_number, last_modified_ledger, ledger_sequence, ledger_range",
+		seqFilter: "ledger_sequence",
+	},
+}
+
 // LoadTail loads the most recent N ledgers from Parquet into PostgreSQL hot buffer.
 func (h *HotBufferLoader) LoadTail(ctx context.Context, tailLedgers uint32, endLedger uint32) error {
 	startLedger := uint32(0)
@@ -77,48 +123,7 @@ func (h *HotBufferLoader) LoadTail(ctx context.Context, tailLedgers uint32, endL
 
 	log.Printf("[HotBuffer] Loading tail: ledgers %d-%d into PostgreSQL", startLedger, endLedger)
 
-	tables := []struct {
-		name      string
-		bronzeDir string
-		columns   string
-		seqFilter string // column name for ledger_sequence filtering
-	}{
-		{
-			name:      "transactions_row_v2",
-			bronzeDir: "transactions",
-			columns:   "ledger_sequence, transaction_hash, source_account, fee_charged, max_fee, successful, transaction_result_code, operation_count, memo_type, memo, created_at, account_sequence, ledger_range, signatures_count, tx_envelope, tx_result, tx_meta, tx_fee_meta, tx_signers, new_account, rent_fee_charged, soroban_resources_instructions, soroban_resources_read_bytes, soroban_resources_write_bytes",
-			seqFilter: "ledger_sequence",
-		},
-		{
-			name:      "operations_row_v2",
-			bronzeDir: "operations",
-			// Column names match v3_bronze_schema.sql / streaming PG hot:
-			// the operation type column is `type`, not `op_type`. Loader's
-			// Parquet output also uses `type` (see ParquetOperation tag).
-			columns:   "ledger_sequence, transaction_hash, operation_index, source_account, type, type_string, created_at, transaction_successful, operation_result_code, ledger_range, amount, asset, destination, soroban_operation, soroban_contract_id, soroban_function, soroban_arguments_json, contract_calls_json, max_call_depth",
-			seqFilter: "ledger_sequence",
-		},
-		{
-			name:      "contract_events_stream_v1",
-			bronzeDir: "contract_events",
-			columns:   "event_id, contract_id, ledger_sequence, transaction_hash, closed_at, event_type, in_successful_contract_call, topics_json, topics_decoded, data_xdr, data_decoded, topic_count, topic0_decoded, topic1_decoded, topic2_decoded, topic3_decoded, operation_index, event_index, ledger_range",
-			seqFilter: "ledger_sequence",
-		},
-		{
-			name:      "accounts_snapshot_v1",
-			bronzeDir: "accounts_snapshot",
-			columns:   "account_id, ledger_sequence, closed_at, balance, sequence_number, sequence_ledger, sequence_time, num_subentries, num_sponsoring, num_sponsored, home_domain, master_weight, low_threshold, med_threshold, high_threshold, flags, auth_required, auth_revocable, auth_immutable, auth_clawback_enabled, signers, sponsor_account, ledger_range",
-			seqFilter: "ledger_sequence",
-		},
-		{
-			name:      "native_balances_snapshot_v1",
-			bronzeDir: "native_balances",
-			columns:   "account_id, balance, buying_liabilities, selling_liabilities, num_subentries, num_sponsoring, num_sponsored, sequence_number, last_modified_ledger, ledger_sequence, ledger_range",
-			seqFilter: "ledger_sequence",
-		},
-	}
-
-	for _, t := range tables {
+	for _, t := range hotBufferTables {
 		start := time.Now()
 		parquetGlob := filepath.Join(h.bronzeDir, t.bronzeDir, "**", "*.parquet")
 
@@ -214,6 +219,18 @@ func (h *HotBufferLoader) copyRows(ctx context.Context, tableName, columns strin
 
 // insertRows is a fallback that uses batch INSERT instead of COPY.
 func (h *HotBufferLoader) insertRows(ctx context.Context, tx *sql.Tx, tableName, columns string, cols []string, rows *sql.Rows) (int64, error) {
+	count, err := h.insertRowsTx(ctx, tx, tableName, columns, cols, rows)
+	if err != nil {
+		return count, err
+	}
+	if err := tx.Commit(); err != nil {
+		return count, fmt.Errorf("commit: %w", err)
+	}
+	return count, nil
+}
+
+// insertRowsTx inserts rows within tx, leaving the commit to the caller.
+func (h *HotBufferLoader) insertRowsTx(ctx context.Context, tx *sql.Tx, tableName, columns string, cols []string, rows *sql.Rows) (int64, error) {
 	values := make([]interface{}, len(cols))
 	scanArgs := make([]interface{}, len(cols))
 	for i := range values {
@@ -247,10 +264,119 @@ func (h *HotBufferLoader) insertRows(ctx context.Context, tx *sql.Tx, tableName,
 		}
 		count++
 	}
+	return count, rows.Err()
+}
 
-	if err := tx.Commit(); err != nil {
-		return count, fmt.Errorf("commit: %w", err)
+// HotBufferReplace is a hot buffer range replacement that has run but not
+// committed, so a repair can commit it right after DuckLake.
+type HotBufferReplace struct {
+	tx     *sql.Tx
+	Counts map[string]int64 // rows inserted, keyed by bronze source table
+}
+
+// Commit makes the replacement visible.
+func (r *HotBufferReplace) Commit() error {
+	if err := r.tx.Commit(); err != nil {
+		return fmt.Errorf("commit hot buffer repair: %w", err)
 	}
+	return nil
+}
 
-	return count, nil
+// Rollback discards the replacement. It is a no-op on a nil or finished
+// replacement.
+func (r *HotBufferReplace) Rollback() {
+	if r == nil {
+		return
+	}
+	if err := r.tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		log.Printf("[HotBuffer] repair rollback failed: %v", err)
+	}
+}
+
+// StageReplaceRange replaces the hot buffer rows of the selected bronze
+// tables between startLedger and endLedger with the Parquet output in
+// bronzeDir, in one transaction it leaves open for the caller to commit or
+// roll back. Only the part of the range a table still holds is touched:
+// writing older ledgers back into the hot buffer would make the flusher push
+// them to DuckLake a second time.
+func (h *HotBufferLoader) StageReplaceRange(ctx context.Context, bronzeDir string, sourceTables []string, startLedger, endLedger uint32) (*HotBufferReplace, error) {
+	selected := map[string]bool{}
+	for _, table := range sourceTables {
+		selected[table] = true
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin hot buffer repair tx: %w", err)
+	}
+	staged := &HotBufferReplace{tx: tx}
+	handedOff := false
+	defer func() {
+		if !handedOff {
+			staged.Rollback()
+		}
+	}()
+
+	counts := map[string]int64{}
+	for _, t := range hotBufferTables {
+		if !selected[t.bronzeDir] {
+			continue
+		}
+		var hotMin, hotMax sql.NullInt64
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM %s", t.seqFilter, t.seqFilter, t.name)).Scan(&hotMin, &hotMax); err != nil {
+			return nil, fmt.Errorf("read hot range of %s: %w", t.name, err)
+		}
+		r, ok := clipLedgerRange(LedgerRange{Start: startLedger, End: endLedger}, hotMin, hotMax)
+		if !ok {
+			log.Printf("[HotBuffer] %s: ledgers %d-%d are not in the hot buffer, skipping", t.name, startLedger, endLedger)
+			continue
+		}
+
+		result, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s BETWEEN $1 AND $2", t.name, t.seqFilter), r.Start, r.End)
+		if err != nil {
+			return nil, fmt.Errorf("delete %s rows in range %d-%d: %w", t.name, r.Start, r.End, err)
+		}
+		deleted, _ := result.RowsAffected()
+
+		var inserted int64
+		if hasParquetOutput(bronzeDir, t.bronzeDir) {
+			query := fmt.Sprintf(
+				"SELECT %s FROM read_parquet('%s') WHERE %s BETWEEN %d AND %d",
+				t.columns, filepath.Join(bronzeDir, t.bronzeDir, "**", "*.parquet"), t.seqFilter, r.Start, r.End,
+			)
+			rows, err := h.duckdb.QueryContext(ctx, query)
+			if err != nil {
+				return nil, fmt.Errorf("read repaired %s: %w", t.bronzeDir, err)
+			}
+			cols, err := rows.Columns()
+			if err == nil {
+				inserted, err = h.insertRowsTx(ctx, tx, t.name, t.columns, cols, rows)
+			}
+			rows.Close()
+			if err != nil {
+				return nil, fmt.Errorf("insert repaired %s rows: %w", t.name, err)
+			}
+		}
+		counts[t.bronzeDir] = inserted
+		log.Printf("[HotBuffer] %s: replaced %d rows with %d in range %d-%d", t.name, deleted, inserted, r.Start, r.End)
+	}
+
+	staged.Counts = counts
+	handedOff = true
+	return staged, nil
+}
+
+// clipLedgerRange limits r to the ledgers between hotMin and hotMax. ok is
+// false when the table is empty or holds none of r.
+func clipLedgerRange(r LedgerRange, hotMin, hotMax sql.NullInt64) (LedgerRange, bool) {
+	if !hotMin.Valid || !hotMax.Valid {
+		return LedgerRange{}, false
+	}
+	if int64(r.Start) < hotMin.Int64 {
+		r.Start = uint32(hotMin.Int64)
+	}
+	if int64(r.End) > hotMax.Int64 {
+		r.End = uint32(hotMax.Int64)
+	}
+	return r, r.Start <= r.End
 }
@@ -23,8 +23,16 @@ type LineageRecord struct {
 	CompletedAt       int64  `parquet:"completed_at,timestamp(microsecond)"`
 	DurationMs        int64  `parquet:"duration_ms"`
 	WorkerID          int32  `parquet:"worker_id"`
+	Operation         string `parquet:"operation"` // "extract" or "repair"
+	RepairID          string `parquet:"repair_id"`
+	RepairReason      string `parquet:"repair_reason"`
 }
 
+const (
+	lineageOperationExtract = "extract"
+	lineageOperationRepair  = "repair"
+)
+
 // LineageWriter collects lineage records and writes them to Parquet.
 type LineageWriter struct {
 	mu              sync.Mutex
@@ -60,9 +68,38 @@ func (lw *LineageWriter) RecordBatch(workerID int, dataset string, ledgerStart,
 		CompletedAt:       now.UnixMicro(),
 		DurationMs:        duration.Milliseconds(),
 		WorkerID:          int32(workerID),
+		Operation:         lineageOperationExtract,
 	})
 }
 
+// newRepairLineageRecord describes the replacement of a table's rows in a
+// ledger range, so downstream consumers know to recompute what they derived
+// from it.
+func newRepairLineageRecord(repairID, reason, dataset string, ledgerStart, ledgerEnd uint32, rowCount int64, startedAt, completedAt time.Time) LineageRecord {
+	return LineageRecord{
+		LineageID:         fmt.Sprintf("repair-%s-%s-%d-%d", repairID, dataset, ledgerStart, ledgerEnd),
+		Dataset:           dataset,
+		SourceLedgerStart: ledgerStart,
+		SourceLedgerEnd:   ledgerEnd,
+		PipelineName:      "stellar-history-loader",
+		PipelineVersion:   Version,
+		RecordsProcessed:  rowCount,
+		StartedAt:         startedAt.UnixMicro(),
+		CompletedAt:       completedAt.UnixMicro(),
+		DurationMs:        completedAt.Sub(startedAt).Milliseconds(),
+		Operation:         lineageOperationRepair,
+		RepairID:          repairID,
+		RepairReason:      reason,
+	}
+}
+
+// Record adds records built elsewhere, such as repair records.
+func (lw *LineageWriter) Record(records ...LineageRecord) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.records = append(lw.records, records...)
+}
+
 // Flush writes all collected lineage records to a Parquet file.
 func (lw *LineageWriter) Flush() error {
 	lw.mu.Lock()
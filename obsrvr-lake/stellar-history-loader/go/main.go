@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -29,6 +30,7 @@ func main() {
 	ledgersPerFile := flag.Uint("ledgers-per-file", 1, "Ledgers per archive file (GCS/S3)")
 	filesPerPartition := flag.Uint("files-per-partition", 64000, "Files per archive partition (GCS/S3)")
 	runValidate := flag.Bool("validate", false, "Run quality validation checks after extraction")
+	validateReport := flag.String("validate-report", "", "Write the validation report, including failing ledger ranges, as JSON to this path")
 	runDuckLake := flag.Bool("ducklake", false, "Push bronze Parquet to DuckLake (B2 + catalog)")
 	onlyTables := flag.String("only-tables", "", "Comma-separated extractor/source/DuckLake table names to extract; empty extracts all")
 	dlCatalog := flag.String("ducklake-catalog", "", "PostgreSQL catalog DSN for DuckLake")
@@ -55,6 +57,11 @@ func main() {
 	enrichmentDryRun := flag.Bool("enrichment-dry-run", false, "Decode and count contract-balance candidates without mutating DuckLake")
 	enrichmentResume := flag.Bool("enrichment-resume", true, "Skip chunks already completed under the enrichment run ID")
 	enrichmentManifestSchema := flag.String("enrichment-manifest-schema", "bronze_operations", "DuckLake schema for the enrichment audit manifest")
+	runRepair := flag.Bool("repair", false, "Re-extract ledger ranges of --only-tables and replace them in DuckLake and the hot buffer")
+	repairRanges := flag.String("repair-ranges", "", "Comma-separated ledger ranges to repair (e.g., 100-200,350)")
+	repairRangesFile := flag.String("repair-ranges-file", "", "Validation report JSON whose failing ranges to repair")
+	repairID := flag.String("repair-id", "", "Repair identifier recorded in lineage (default repair-<unix time>)")
+	repairReason := flag.String("repair-reason", "", "Why the ranges are repaired, recorded in lineage")
 
 	flag.Parse()
 	if *enrichContractBalances {
@@ -107,6 +114,95 @@ func main() {
 		return
 	}
 
+	if *runRepair {
+		if *output == "" || *bucket == "" {
+			log.Fatal("--repair requires --output and --bucket")
+		}
+		tables := parseCSVSet(*onlyTables)
+		if len(tables) == 0 {
+			log.Fatal("--repair requires --only-tables")
+		}
+		if err := validateTableSelection(tables, "only-tables"); err != nil {
+			log.Fatal(err)
+		}
+		ranges, err := parseLedgerRanges(*repairRanges)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *repairRangesFile != "" {
+			fileRanges, err := loadLedgerRangesFile(*repairRangesFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			ranges = append(ranges, fileRanges...)
+		}
+		if len(ranges) == 0 {
+			log.Fatal("--repair requires --repair-ranges or a --repair-ranges-file with failing ranges")
+		}
+		if !*runDuckLake && *pgHost == "" {
+			log.Fatal("--repair requires --ducklake, --pg-host, or both")
+		}
+		id := *repairID
+		if id == "" {
+			id = fmt.Sprintf("repair-%d", time.Now().Unix())
+		}
+
+		cfg := RepairConfig{
+			RepairID: id,
+			Reason:   *repairReason,
+			Ranges:   ranges,
+			Tables:   tables,
+			Extract: OrchestratorConfig{
+				NumWorkers:        *workers,
+				BatchSize:         *batchSize,
+				NetworkPassphrase: *networkPassphrase,
+				StorageType:       *storageType,
+				Bucket:            *bucket,
+				LedgersPerFile:    uint32(*ledgersPerFile),
+				FilesPerPartition: uint32(*filesPerPartition),
+				EraID:             *eraID,
+			},
+			OutputDir: *output,
+		}
+		if *runDuckLake {
+			if *dlCatalog == "" || *dlDataPath == "" || *b2KeyID == "" || *b2KeySecret == "" {
+				log.Fatal("--repair with --ducklake requires --ducklake-catalog, --ducklake-data-path, --b2-key-id, and --b2-key-secret")
+			}
+			cfg.DuckLake = &DuckLakeConfig{
+				CatalogDSN:      *dlCatalog,
+				DataPath:        *dlDataPath,
+				MetadataSchema:  *dlMetaSchema,
+				S3KeyID:         *b2KeyID,
+				S3KeySecret:     *b2KeySecret,
+				S3Endpoint:      *b2Endpoint,
+				S3Region:        *b2Region,
+				BronzeSchemaSQL: *dlSchemaSQL,
+			}
+		}
+		if *pgHost != "" {
+			cfg.HotBuffer = &HotBufferConfig{
+				Host:     *pgHost,
+				Port:     *pgPort,
+				Database: *pgDB,
+				User:     *pgUser,
+				Password: *pgPassword,
+				SSLMode:  *pgSSL,
+			}
+		}
+
+		result, err := RunRepair(context.Background(), cfg)
+		var partial *PartialRepairError
+		if errors.As(err, &partial) {
+			log.Fatalf("repair %s stopped after %d complete range(s) with %v; rerun it to finish the range",
+				id, len(result.Ranges), partial)
+		}
+		if err != nil {
+			log.Fatalf("repair %s failed: %v", id, err)
+		}
+		fmt.Printf("\nRepair %s complete: %d range(s) replaced\n", result.RepairID, len(result.Ranges))
+		return
+	}
+
 	// Validate required flags
 	if *start == 0 {
 		log.Fatal("--start is required and must be > 0")
@@ -117,8 +213,8 @@ func main() {
 	if *output == "" {
 		log.Fatal("--output is required")
 	}
-	if *bucket == "" && !*runDuckLake {
-		log.Fatal("--bucket is required (unless using --ducklake with existing output)")
+	if *bucket == "" && !*runDuckLake && !*runValidate {
+		log.Fatal("--bucket is required (unless using --ducklake or --validate with existing output)")
 	}
 
 	startLedger := uint32(*start)
@@ -263,7 +359,7 @@ func main() {
 	// Run quality validation if requested
 	if *runValidate {
 		fmt.Println()
-		validator, err := NewValidator(*output)
+		validator, err := NewValidator(*output, startLedger, endLedger)
 		if err != nil {
 			log.Printf("Validation setup failed: %v", err)
 		} else {
@@ -271,7 +367,15 @@ func main() {
 			if err := reporter.ReportChunkProgress(ctx, int64(startLedger), int64(endLedger), "validation", nil, nil); err != nil {
 				log.Printf("Flowctl progress report failed: %v", err)
 			}
-			if err := validator.RunAll(ctx); err != nil {
+			report, err := validator.RunAll(ctx)
+			if *validateReport != "" {
+				if writeErr := report.WriteFile(*validateReport); writeErr != nil {
+					log.Printf("Validation report not written: %v", writeErr)
+				} else {
+					fmt.Printf("Validation report:      %s\n", *validateReport)
+				}
+			}
+			if err != nil {
 				_ = reporter.ReportChunkFailed(ctx, int64(startLedger), int64(endLedger), "validation", flowctlpb.FailureClass_FAILURE_CLASS_VERIFICATION, err.Error(), "inspect_output")
 				log.Fatalf("Validation failed: %v", err)
 			}
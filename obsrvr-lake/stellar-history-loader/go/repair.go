@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RepairConfig describes a targeted repair: the ledger ranges and tables to
+// re-extract from the archive and the stores whose rows are replaced.
+type RepairConfig struct {
+	RepairID  string
+	Reason    string
+	Ranges    []LedgerRange
+	Tables    map[string]bool    // extractor, source, or DuckLake table names
+	Extract   OrchestratorConfig // source settings; range, output, and tables are set per range
+	OutputDir string
+	DuckLake  *DuckLakeConfig  // nil leaves DuckLake untouched
+	HotBuffer *HotBufferConfig // nil leaves the hot buffer untouched
+}
+
+// RepairRangeResult holds the rows written for one repaired range, keyed by
+// bronze source table.
+type RepairRangeResult struct {
+	Range         LedgerRange
+	ExtractedRows map[string]int64
+	HotBufferRows map[string]int64
+}
+
+// RepairResult summarizes a repair run.
+type RepairResult struct {
+	RepairID string
+	Ranges   []RepairRangeResult
+}
+
+// PartialRepairError reports a range that a repair left half done: its rows
+// were replaced in one store but not the other, or in both without the
+// DuckLake lineage that tells silver to recompute them. Re-running the
+// repair finishes the range.
+type PartialRepairError struct {
+	Range    LedgerRange
+	Replaced string // stores that hold the repaired rows
+	Missing  string // what the range still lacks
+	Err      error
+}
+
+func (e *PartialRepairError) Error() string {
+	return fmt.Sprintf("ledgers %d-%d partially repaired: replaced in %s, but %s: %v",
+		e.Range.Start, e.Range.End, e.Replaced, e.Missing, e.Err)
+}
+
+func (e *PartialRepairError) Unwrap() error {
+	return e.Err
+}
+
+// RunRepair re-extracts each range into a staging directory under
+// <output>/repair/<id>, then replaces the selected tables' rows in that range
+// in the hot buffer and in DuckLake, each in one transaction. The hot buffer
+// transaction is staged first and committed right after DuckLake's, so a
+// failure before the DuckLake commit leaves both stores untouched. Only
+// once both hold the repaired rows does every replaced table get a repair
+// lineage record, both in DuckLake _meta_repair_lineage and in the repair's
+// local lineage Parquet, so silver can recompute what it derived from those
+// ledgers. A range left half done is reported as a *PartialRepairError.
+// Re-running a repair is safe: each range is replaced wholesale.
+func RunRepair(ctx context.Context, cfg RepairConfig) (*RepairResult, error) {
+	if cfg.RepairID == "" {
+		return nil, fmt.Errorf("repair ID is required")
+	}
+	if len(cfg.Ranges) == 0 {
+		return nil, fmt.Errorf("no ledger ranges to repair")
+	}
+	sourceTables, err := repairSourceTables(cfg.Tables)
+	if err != nil {
+		return nil, err
+	}
+	ranges := mergeLedgerRanges(cfg.Ranges)
+
+	var pusher *DuckLakePusher
+	if cfg.DuckLake != nil {
+		pusher, err = NewDuckLakePusher(*cfg.DuckLake)
+		if err != nil {
+			return nil, err
+		}
+		defer pusher.Close()
+		if err := pusher.Setup(ctx); err != nil {
+			return nil, err
+		}
+		if err := pusher.createTables(ctx); err != nil {
+			return nil, fmt.Errorf("create tables: %w", err)
+		}
+	}
+
+	var hot *HotBufferLoader
+	if cfg.HotBuffer != nil {
+		hot, err = NewHotBufferLoader(cfg.OutputDir, *cfg.HotBuffer)
+		if err != nil {
+			return nil, err
+		}
+		defer hot.Close()
+	}
+
+	counter, err := sql.Open("duckdb", "")
+	if err != nil {
+		return nil, fmt.Errorf("open duckdb: %w", err)
+	}
+	defer counter.Close()
+
+	repairDir := filepath.Join(cfg.OutputDir, "repair", cfg.RepairID)
+	lineage := NewLineageWriter(repairDir, Version)
+	defer func() {
+		if err := lineage.Flush(); err != nil {
+			log.Printf("Warning: repair lineage flush failed: %v", err)
+		}
+	}()
+
+	result := &RepairResult{RepairID: cfg.RepairID}
+	for _, r := range ranges {
+		fmt.Printf("\n=== Repair %s: ledgers %d-%d ===\n", cfg.RepairID, r.Start, r.End)
+		startedAt := time.Now()
+
+		stagingDir := filepath.Join(repairDir, fmt.Sprintf("range_%d_%d", r.Start, r.End))
+		// A leftover checkpoint from an earlier attempt would skip the extraction.
+		if err := os.RemoveAll(stagingDir); err != nil {
+			return result, fmt.Errorf("clear staging dir: %w", err)
+		}
+		if err := os.MkdirAll(stagingDir, 0o755); err != nil {
+			return result, fmt.Errorf("create staging dir: %w", err)
+		}
+
+		extract := cfg.Extract
+		extract.StartLedger = r.Start
+		extract.EndLedger = r.End
+		extract.OutputDir = stagingDir
+		extract.OnlyTables = cfg.Tables
+		if err := NewOrchestrator(extract).Run(); err != nil {
+			return result, fmt.Errorf("re-extract ledgers %d-%d: %w", r.Start, r.End, err)
+		}
+
+		bronzeDir := filepath.Join(stagingDir, "bronze")
+		rangeResult := RepairRangeResult{Range: r, ExtractedRows: map[string]int64{}}
+		for _, table := range sourceTables {
+			if !hasParquetOutput(bronzeDir, table) {
+				rangeResult.ExtractedRows[table] = 0
+				continue
+			}
+			var n int64
+			query := fmt.Sprintf("SELECT COUNT(*) FROM read_parquet('%s')", filepath.Join(bronzeDir, table, "**", "*.parquet"))
+			if err := counter.QueryRowContext(ctx, query).Scan(&n); err != nil {
+				return result, fmt.Errorf("count re-extracted %s rows: %w", table, err)
+			}
+			rangeResult.ExtractedRows[table] = n
+		}
+
+		completedAt := time.Now()
+		records := make([]LineageRecord, 0, len(sourceTables))
+		for _, table := range sourceTables {
+			records = append(records, newRepairLineageRecord(cfg.RepairID, cfg.Reason, table, r.Start, r.End, rangeResult.ExtractedRows[table], startedAt, completedAt))
+		}
+
+		var staged *HotBufferReplace
+		if hot != nil {
+			staged, err = hot.StageReplaceRange(ctx, bronzeDir, sourceTables, r.Start, r.End)
+			if err != nil {
+				return result, fmt.Errorf("replace ledgers %d-%d in hot buffer: %w", r.Start, r.End, err)
+			}
+		}
+		if pusher != nil {
+			if _, err := pusher.ReplaceRange(ctx, stagingDir, sourceTables, r.Start, r.End); err != nil {
+				staged.Rollback()
+				return result, fmt.Errorf("replace ledgers %d-%d in DuckLake: %w", r.Start, r.End, err)
+			}
+		}
+		if staged != nil {
+			if err := staged.Commit(); err != nil {
+				if pusher != nil {
+					return result, &PartialRepairError{Range: r, Replaced: "DuckLake", Missing: "not in the hot buffer", Err: err}
+				}
+				return result, fmt.Errorf("replace ledgers %d-%d in hot buffer: %w", r.Start, r.End, err)
+			}
+			rangeResult.HotBufferRows = staged.Counts
+		}
+		if pusher != nil {
+			if err := pusher.RecordRepairLineage(ctx, records); err != nil {
+				replaced := "DuckLake"
+				if hot != nil {
+					replaced = "DuckLake and the hot buffer"
+				}
+				return result, &PartialRepairError{Range: r, Replaced: replaced, Missing: "no repair lineage was recorded", Err: err}
+			}
+		}
+		lineage.Record(records...)
+		result.Ranges = append(result.Ranges, rangeResult)
+		fmt.Printf("Repaired ledgers %d-%d in %s\n", r.Start, r.End, time.Since(startedAt).Round(time.Millisecond))
+	}
+	return result, nil
+}
+
+// repairSourceTables returns the bronze source tables selected by name, in
+// sorted order. A repair must name its tables: replacing everything in a
+// range is rarely what a repair wants and is easy to ask for explicitly.
+func repairSourceTables(selected map[string]bool) ([]string, error) {
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("repair requires at least one table")
+	}
+	if err := validateTableSelection(selected, "only-tables"); err != nil {
+		return nil, err
+	}
+	var tables []string
+	for extractorName, outputDir := range extractorOutputDirByName {
+		if tableSetIncludes(selected, extractorName, outputDir, mapToDuckLakeTable(outputDir)) {
+			tables = append(tables, outputDir)
+		}
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// parseLedgerRanges parses a comma-separated list of ledger ranges such as
+// "100-200,350". A single ledger stands for a one-ledger range.
+func parseLedgerRanges(value string) ([]LedgerRange, error) {
+	var ranges []LedgerRange
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		startText, endText, isRange := strings.Cut(part, "-")
+		if !isRange {
+			endText = startText
+		}
+		start, err := strconv.ParseUint(strings.TrimSpace(startText), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ledger range %q: %w", part, err)
+		}
+		end, err := strconv.ParseUint(strings.TrimSpace(endText), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ledger range %q: %w", part, err)
+		}
+		if start == 0 || end < start {
+			return nil, fmt.Errorf("invalid ledger range %q: want start > 0 and end >= start", part)
+		}
+		ranges = append(ranges, LedgerRange{Start: uint32(start), End: uint32(end)})
+	}
+	return ranges, nil
+}
+
+// loadLedgerRangesFile reads the failing ranges of a validation report
+// written by --validate-report.
+func loadLedgerRangesFile(path string) ([]LedgerRange, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read ranges file: %w", err)
+	}
+	var report ValidationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse ranges file %s: %w", path, err)
+	}
+	return report.FailingRanges, nil
+}
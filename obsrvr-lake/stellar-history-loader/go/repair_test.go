@@ -0,0 +1,107 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLedgerRanges(t *testing.T) {
+	got, err := parseLedgerRanges(" 100-200, 350 ,,400 - 410")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LedgerRange{{100, 200}, {350, 350}, {400, 410}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ranges = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"0-5", "20-10", "a-b", "5-", "1-99999999999"} {
+		if _, err := parseLedgerRanges(bad); err == nil {
+			t.Errorf("parseLedgerRanges(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestRepairSourceTablesAcceptsAnyTableName(t *testing.T) {
+	got, err := repairSourceTables(parseCSVSet("accounts,contract_events_stream_v1,ledgers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"accounts_snapshot", "contract_events", "ledgers"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("tables = %v, want %v", got, want)
+	}
+
+	if _, err := repairSourceTables(nil); err == nil {
+		t.Fatal("empty selection accepted")
+	}
+	if _, err := repairSourceTables(parseCSVSet("ledgers,nope")); err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Fatalf("err = %v, want it to name the unknown table", err)
+	}
+}
+
+func TestLoadLedgerRangesFileReadsValidationReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "validation.json")
+	report := &ValidationReport{FailingRanges: []LedgerRange{{101, 106}, {300, 300}}}
+	if err := report.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadLedgerRangesFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, report.FailingRanges) {
+		t.Fatalf("ranges = %v, want %v", got, report.FailingRanges)
+	}
+}
+
+func TestClipLedgerRangeToHotBuffer(t *testing.T) {
+	valid := func(v int64) sql.NullInt64 { return sql.NullInt64{Int64: v, Valid: true} }
+	tests := []struct {
+		name     string
+		r        LedgerRange
+		min, max sql.NullInt64
+		want     LedgerRange
+		ok       bool
+	}{
+		{"inside", LedgerRange{150, 160}, valid(100), valid(200), LedgerRange{150, 160}, true},
+		{"overlaps tail", LedgerRange{50, 120}, valid(100), valid(200), LedgerRange{100, 120}, true},
+		{"overlaps head", LedgerRange{190, 250}, valid(100), valid(200), LedgerRange{190, 200}, true},
+		{"before", LedgerRange{10, 99}, valid(100), valid(200), LedgerRange{}, false},
+		{"empty table", LedgerRange{150, 160}, sql.NullInt64{}, sql.NullInt64{}, LedgerRange{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := clipLedgerRange(tt.r, tt.min, tt.max)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("%s: clip = %v, %t; want %v, %t", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPartialRepairErrorNamesRangeAndStores(t *testing.T) {
+	cause := errors.New("connection reset")
+	var err error = fmt.Errorf("wrapped: %w", &PartialRepairError{
+		Range: LedgerRange{100, 200}, Replaced: "DuckLake", Missing: "not in the hot buffer", Err: cause,
+	})
+
+	var partial *PartialRepairError
+	if !errors.As(err, &partial) || partial.Range != (LedgerRange{100, 200}) {
+		t.Fatalf("errors.As(%v) did not find the partial range", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("PartialRepairError does not unwrap to its cause")
+	}
+	want := "ledgers 100-200 partially repaired: replaced in DuckLake, but not in the hot buffer: connection reset"
+	if partial.Error() != want {
+		t.Errorf("Error() = %q, want %q", partial.Error(), want)
+	}
+
+	// A repair without a hot buffer rolls back a nil replacement.
+	var staged *HotBufferReplace
+	staged.Rollback()
+}
@@ -3,26 +3,35 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
 // Validator runs quality checks over Parquet output using DuckDB.
 type Validator struct {
-	db        *sql.DB
-	bronzeDir string
+	db          *sql.DB
+	bronzeDir   string
+	startLedger uint32
+	endLedger   uint32
 }
 
-func NewValidator(outputDir string) (*Validator, error) {
+// NewValidator checks the output of ledgers startLedger through endLedger.
+func NewValidator(outputDir string, startLedger, endLedger uint32) (*Validator, error) {
 	db, err := sql.Open("duckdb", "")
 	if err != nil {
 		return nil, fmt.Errorf("open duckdb for validation: %w", err)
 	}
 	return &Validator{
-		db:        db,
-		bronzeDir: filepath.Join(outputDir, "bronze"),
+		db:          db,
+		bronzeDir:   filepath.Join(outputDir, "bronze"),
+		startLedger: startLedger,
+		endLedger:   endLedger,
 	}, nil
 }
 
@@ -30,48 +39,140 @@ func (v *Validator) Close() error {
 	return v.db.Close()
 }
 
-// RunAll runs all quality checks and reports results.
-func (v *Validator) RunAll(ctx context.Context) error {
-	checks := []struct {
-		name string
-		fn   func(context.Context) (bool, string, error)
-	}{
-		{"monotonic_ledger_sequence", v.checkMonotonicSequence},
-		{"no_empty_tables", v.checkNoEmptyTables},
-		{"transaction_operation_consistency", v.checkTxOpConsistency},
-		{"pipeline_version_present", v.checkPipelineVersion},
+// LedgerRange is an inclusive range of ledger sequences.
+type LedgerRange struct {
+	Start uint32 `json:"start"`
+	End   uint32 `json:"end"`
+}
+
+// ValidationCheckResult is the outcome of one quality check. FailingRanges
+// lists the ledgers the check blames, when it can tell.
+type ValidationCheckResult struct {
+	Name          string        `json:"name"`
+	Passed        bool          `json:"passed"`
+	Skipped       bool          `json:"skipped,omitempty"`
+	Detail        string        `json:"detail,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	FailingRanges []LedgerRange `json:"failing_ranges,omitempty"`
+	DurationMS    int64         `json:"duration_ms"`
+}
+
+// ValidationReport is the machine-readable result of RunAll. FailingRanges
+// merges the ranges of every failed check; re-extracting them with
+// --start/--end repairs the output.
+type ValidationReport struct {
+	BronzeDir     string                  `json:"bronze_dir"`
+	StartLedger   uint32                  `json:"start_ledger"`
+	EndLedger     uint32                  `json:"end_ledger"`
+	Passed        bool                    `json:"passed"`
+	Checks        []ValidationCheckResult `json:"checks"`
+	FailingRanges []LedgerRange           `json:"failing_ranges"`
+	GeneratedAt   time.Time               `json:"generated_at"`
+}
+
+// WriteFile writes the report as indented JSON.
+func (r *ValidationReport) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal validation report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write validation report: %w", err)
+	}
+	return nil
+}
+
+// checkOutcome is what a check found. skipped is set when the tables the
+// check needs were not extracted.
+type checkOutcome struct {
+	ok      bool
+	skipped bool
+	detail  string
+	ranges  []LedgerRange
+}
+
+type validationCheck struct {
+	name string
+	fn   func(context.Context) (checkOutcome, error)
+}
+
+// simpleCheck adapts a pass/fail check that cannot locate failing ledgers.
+func simpleCheck(fn func(context.Context) (bool, string, error)) func(context.Context) (checkOutcome, error) {
+	return func(ctx context.Context) (checkOutcome, error) {
+		ok, detail, err := fn(ctx)
+		return checkOutcome{ok: ok, detail: detail}, err
+	}
+}
+
+// RunAll runs all quality checks, prints the results and returns the
+// report. The error is non-nil when any check failed.
+func (v *Validator) RunAll(ctx context.Context) (*ValidationReport, error) {
+	checks := []validationCheck{
+		{"monotonic_ledger_sequence", simpleCheck(v.checkMonotonicSequence)},
+		{"no_empty_tables", simpleCheck(v.checkNoEmptyTables)},
+		{"transaction_operation_consistency", simpleCheck(v.checkTxOpConsistency)},
+		{"pipeline_version_present", simpleCheck(v.checkPipelineVersion)},
+		{"ledger_hash_chain", v.checkLedgerHashChain},
+		{"ledger_transaction_counts", v.checkLedgerTransactionCounts},
+		{"transaction_operation_counts", v.checkTransactionOperationCounts},
+		{"effects_reference_transactions", v.checkReferencesTransactions("effects")},
+		{"contract_events_reference_transactions", v.checkReferencesTransactions("contract_events")},
+		{"token_transfers_reference_transactions", v.checkReferencesTransactions("token_transfers")},
 	}
 
 	fmt.Println("=== Quality Validation ===")
+	report := &ValidationReport{
+		BronzeDir:   v.bronzeDir,
+		StartLedger: v.startLedger,
+		EndLedger:   v.endLedger,
+		GeneratedAt: time.Now().UTC(),
+	}
 	passed := 0
 	failed := 0
 
 	for _, check := range checks {
 		start := time.Now()
-		ok, detail, err := check.fn(ctx)
+		outcome, err := check.fn(ctx)
 		elapsed := time.Since(start).Round(time.Millisecond)
+		result := ValidationCheckResult{
+			Name:       check.name,
+			Passed:     err == nil && outcome.ok,
+			Skipped:    outcome.skipped,
+			Detail:     outcome.detail,
+			DurationMS: elapsed.Milliseconds(),
+		}
 
-		if err != nil {
+		switch {
+		case err != nil:
 			log.Printf("[Quality] %s: ERROR (%v) [%s]", check.name, err, elapsed)
+			result.Error = err.Error()
 			failed++
-			continue
-		}
-
-		if ok {
-			fmt.Printf("  PASS  %-40s %s [%s]\n", check.name, detail, elapsed)
+		case outcome.skipped:
+			fmt.Printf("  SKIP  %-40s %s [%s]\n", check.name, outcome.detail, elapsed)
 			passed++
-		} else {
-			fmt.Printf("  FAIL  %-40s %s [%s]\n", check.name, detail, elapsed)
+		case outcome.ok:
+			fmt.Printf("  PASS  %-40s %s [%s]\n", check.name, outcome.detail, elapsed)
+			passed++
+		default:
+			fmt.Printf("  FAIL  %-40s %s [%s]\n", check.name, outcome.detail, elapsed)
+			result.FailingRanges = outcome.ranges
 			failed++
 		}
+		report.Checks = append(report.Checks, result)
+		report.FailingRanges = append(report.FailingRanges, result.FailingRanges...)
 	}
+	report.FailingRanges = mergeLedgerRanges(report.FailingRanges)
+	report.Passed = failed == 0
 
 	fmt.Printf("\nResults: %d passed, %d failed\n", passed, failed)
+	if len(report.FailingRanges) > 0 {
+		fmt.Printf("Failing ledger ranges: %s\n", formatLedgerRanges(report.FailingRanges))
+	}
 
 	if failed > 0 {
-		return fmt.Errorf("%d quality check(s) failed", failed)
+		return report, fmt.Errorf("%d quality check(s) failed", failed)
 	}
-	return nil
+	return report, nil
 }
 
 // checkMonotonicSequence verifies ledger sequences have no gaps in transactions.
@@ -165,3 +266,294 @@ func (v *Validator) checkPipelineVersion(ctx context.Context) (bool, string, err
 	}
 	return true, fmt.Sprintf("%d distinct version(s)", versions), nil
 }
+
+// parquetGlob returns the read_parquet glob of a bronze table.
+func (v *Validator) parquetGlob(table string) string {
+	return filepath.Join(v.bronzeDir, table, "**", "*.parquet")
+}
+
+// hasTable reports whether a bronze table was extracted.
+func (v *Validator) hasTable(table string) bool {
+	return hasParquetOutput(v.bronzeDir, table)
+}
+
+// missingTables returns the tables that were not extracted.
+func (v *Validator) missingTables(tables ...string) []string {
+	var missing []string
+	for _, table := range tables {
+		if !v.hasTable(table) {
+			missing = append(missing, table)
+		}
+	}
+	return missing
+}
+
+func skippedCheck(missing []string) checkOutcome {
+	return checkOutcome{ok: true, skipped: true, detail: fmt.Sprintf("not extracted: %s", strings.Join(missing, ", "))}
+}
+
+// ledgerFilter restricts column to the validated range.
+func (v *Validator) ledgerFilter(column string) string {
+	return fmt.Sprintf("%s BETWEEN %d AND %d", column, v.startLedger, v.endLedger)
+}
+
+// failingRanges runs a query returning the distinct failing ledger
+// sequences in a column named ledger_sequence and collapses them into
+// ranges.
+func (v *Validator) failingRanges(ctx context.Context, failingLedgersSQL string) ([]LedgerRange, int64, error) {
+	query := fmt.Sprintf(`
+		SELECT MIN(ledger_sequence), MAX(ledger_sequence), COUNT(*)
+		FROM (
+			SELECT ledger_sequence,
+				ledger_sequence - ROW_NUMBER() OVER (ORDER BY ledger_sequence) AS island
+			FROM (SELECT DISTINCT ledger_sequence FROM (%s))
+		)
+		GROUP BY island
+		ORDER BY 1
+	`, failingLedgersSQL)
+
+	rows, err := v.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var ranges []LedgerRange
+	var total int64
+	for rows.Next() {
+		var r LedgerRange
+		var n int64
+		if err := rows.Scan(&r.Start, &r.End, &n); err != nil {
+			return nil, 0, err
+		}
+		ranges = append(ranges, r)
+		total += n
+	}
+	return ranges, total, rows.Err()
+}
+
+// checkLedgerHashChain verifies every ledger in the range is present once
+// and its previous_ledger_hash is the hash of the ledger before it. A broken
+// link blames both ledgers, since either may be the bad one.
+func (v *Validator) checkLedgerHashChain(ctx context.Context) (checkOutcome, error) {
+	if missing := v.missingTables("ledgers"); len(missing) > 0 {
+		return skippedCheck(missing), nil
+	}
+	glob := v.parquetGlob("ledgers")
+
+	var ledgers int64
+	var minSeq, maxSeq sql.NullInt64
+	if err := v.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(*), MIN(sequence), MAX(sequence)
+		FROM read_parquet('%s') WHERE %s
+	`, glob, v.ledgerFilter("sequence"))).Scan(&ledgers, &minSeq, &maxSeq); err != nil {
+		return checkOutcome{}, err
+	}
+	if ledgers == 0 {
+		return checkOutcome{
+			detail: "no ledgers in range",
+			ranges: []LedgerRange{{Start: v.startLedger, End: v.endLedger}},
+		}, nil
+	}
+
+	var ranges []LedgerRange
+	if uint32(minSeq.Int64) > v.startLedger {
+		ranges = append(ranges, LedgerRange{Start: v.startLedger, End: uint32(minSeq.Int64) - 1})
+	}
+	if uint32(maxSeq.Int64) < v.endLedger {
+		ranges = append(ranges, LedgerRange{Start: uint32(maxSeq.Int64) + 1, End: v.endLedger})
+	}
+
+	// Gaps blame the missing ledgers; duplicates and broken links blame the
+	// ledgers involved.
+	var gaps, breaks int64
+	gapRows, err := v.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT prev_seq + 1, sequence - 1
+		FROM (
+			SELECT sequence, LAG(sequence) OVER (ORDER BY sequence) AS prev_seq
+			FROM (SELECT DISTINCT sequence FROM read_parquet('%s') WHERE %s)
+		)
+		WHERE prev_seq IS NOT NULL AND sequence > prev_seq + 1
+		ORDER BY 1
+	`, glob, v.ledgerFilter("sequence")))
+	if err != nil {
+		return checkOutcome{}, err
+	}
+	for gapRows.Next() {
+		var r LedgerRange
+		if err := gapRows.Scan(&r.Start, &r.End); err != nil {
+			gapRows.Close()
+			return checkOutcome{}, err
+		}
+		ranges = append(ranges, r)
+		gaps++
+	}
+	gapRows.Close()
+	if err := gapRows.Err(); err != nil {
+		return checkOutcome{}, err
+	}
+
+	linkRanges, _, err := v.failingRanges(ctx, fmt.Sprintf(`
+		WITH chain AS (
+			SELECT sequence, ledger_hash, previous_ledger_hash,
+				LAG(sequence) OVER w AS prev_seq,
+				LAG(ledger_hash) OVER w AS prev_hash
+			FROM read_parquet('%s')
+			WHERE %s
+			WINDOW w AS (ORDER BY sequence, ledger_hash)
+		),
+		broken AS (
+			SELECT sequence, prev_seq FROM chain
+			WHERE prev_seq = sequence
+				OR (prev_seq = sequence - 1 AND previous_ledger_hash IS DISTINCT FROM prev_hash)
+		)
+		SELECT sequence AS ledger_sequence FROM broken
+		UNION
+		SELECT prev_seq AS ledger_sequence FROM broken
+	`, glob, v.ledgerFilter("sequence")))
+	if err != nil {
+		return checkOutcome{}, err
+	}
+	breaks = int64(len(linkRanges))
+	ranges = mergeLedgerRanges(append(ranges, linkRanges...))
+
+	if len(ranges) == 0 {
+		return checkOutcome{ok: true, detail: fmt.Sprintf("%d ledgers linked by hash, no gaps", ledgers)}, nil
+	}
+	return checkOutcome{
+		detail: fmt.Sprintf("%d gap(s), %d broken or duplicated link range(s)", gaps, breaks),
+		ranges: ranges,
+	}, nil
+}
+
+// checkLedgerTransactionCounts verifies each ledger header's transaction
+// and successful transaction counts match the transactions extracted for it.
+func (v *Validator) checkLedgerTransactionCounts(ctx context.Context) (checkOutcome, error) {
+	if missing := v.missingTables("ledgers", "transactions"); len(missing) > 0 {
+		return skippedCheck(missing), nil
+	}
+	ranges, count, err := v.failingRanges(ctx, fmt.Sprintf(`
+		WITH l AS (
+			SELECT sequence, transaction_count, successful_tx_count
+			FROM read_parquet('%s') WHERE %s
+		),
+		t AS (
+			SELECT ledger_sequence,
+				COUNT(*) AS txs,
+				COUNT(*) FILTER (WHERE successful) AS successful_txs
+			FROM read_parquet('%s') WHERE %s
+			GROUP BY ledger_sequence
+		)
+		SELECT COALESCE(l.sequence, t.ledger_sequence) AS ledger_sequence
+		FROM l FULL OUTER JOIN t ON t.ledger_sequence = l.sequence
+		WHERE l.sequence IS NULL
+			OR COALESCE(t.txs, 0) != l.transaction_count
+			OR COALESCE(t.successful_txs, 0) != l.successful_tx_count
+	`, v.parquetGlob("ledgers"), v.ledgerFilter("sequence"), v.parquetGlob("transactions"), v.ledgerFilter("ledger_sequence")))
+	if err != nil {
+		return checkOutcome{}, err
+	}
+	if count == 0 {
+		return checkOutcome{ok: true, detail: "transaction counts match ledger headers"}, nil
+	}
+	return checkOutcome{
+		detail: fmt.Sprintf("%d ledger(s) with mismatched transaction counts", count),
+		ranges: ranges,
+	}, nil
+}
+
+// checkTransactionOperationCounts verifies each transaction's
+// operation_count matches the operations extracted for it.
+func (v *Validator) checkTransactionOperationCounts(ctx context.Context) (checkOutcome, error) {
+	if missing := v.missingTables("transactions", "operations"); len(missing) > 0 {
+		return skippedCheck(missing), nil
+	}
+	ranges, count, err := v.failingRanges(ctx, fmt.Sprintf(`
+		WITH t AS (
+			SELECT ledger_sequence, transaction_hash, operation_count
+			FROM read_parquet('%s') WHERE %s
+		),
+		o AS (
+			SELECT ledger_sequence, transaction_hash, COUNT(*) AS ops
+			FROM read_parquet('%s') WHERE %s
+			GROUP BY ledger_sequence, transaction_hash
+		)
+		SELECT COALESCE(t.ledger_sequence, o.ledger_sequence) AS ledger_sequence
+		FROM t FULL OUTER JOIN o
+			ON o.transaction_hash = t.transaction_hash AND o.ledger_sequence = t.ledger_sequence
+		WHERE COALESCE(o.ops, 0) != COALESCE(t.operation_count, -1)
+	`, v.parquetGlob("transactions"), v.ledgerFilter("ledger_sequence"), v.parquetGlob("operations"), v.ledgerFilter("ledger_sequence")))
+	if err != nil {
+		return checkOutcome{}, err
+	}
+	if count == 0 {
+		return checkOutcome{ok: true, detail: "operation counts match transactions"}, nil
+	}
+	return checkOutcome{
+		detail: fmt.Sprintf("%d ledger(s) with mismatched operation counts", count),
+		ranges: ranges,
+	}, nil
+}
+
+// checkReferencesTransactions returns a check that every row of table
+// points at a transaction extracted for the same ledger.
+func (v *Validator) checkReferencesTransactions(table string) func(context.Context) (checkOutcome, error) {
+	return func(ctx context.Context) (checkOutcome, error) {
+		if missing := v.missingTables(table, "transactions"); len(missing) > 0 {
+			return skippedCheck(missing), nil
+		}
+		ranges, count, err := v.failingRanges(ctx, fmt.Sprintf(`
+			SELECT c.ledger_sequence
+			FROM read_parquet('%s') c
+			LEFT JOIN (
+				SELECT DISTINCT ledger_sequence, transaction_hash
+				FROM read_parquet('%s') WHERE %s
+			) t ON t.transaction_hash = c.transaction_hash AND t.ledger_sequence = c.ledger_sequence
+			WHERE %s AND t.transaction_hash IS NULL
+		`, v.parquetGlob(table), v.parquetGlob("transactions"), v.ledgerFilter("ledger_sequence"), v.ledgerFilter("c.ledger_sequence")))
+		if err != nil {
+			return checkOutcome{}, err
+		}
+		if count == 0 {
+			return checkOutcome{ok: true, detail: fmt.Sprintf("all %s reference valid transactions", table)}, nil
+		}
+		return checkOutcome{
+			detail: fmt.Sprintf("%d ledger(s) with orphaned %s", count, table),
+			ranges: ranges,
+		}, nil
+	}
+}
+
+// mergeLedgerRanges sorts ranges and joins overlapping or adjacent ones.
+func mergeLedgerRanges(ranges []LedgerRange) []LedgerRange {
+	if len(ranges) == 0 {
+		return []LedgerRange{}
+	}
+	sorted := append([]LedgerRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	merged := []LedgerRange{sorted[0]}
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if uint64(r.Start) <= uint64(last.End)+1 {
+			if r.End > last.End {
+				last.End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func formatLedgerRanges(ranges []LedgerRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		if r.Start == r.End {
+			parts[i] = fmt.Sprintf("%d", r.Start)
+		} else {
+			parts[i] = fmt.Sprintf("%d-%d", r.Start, r.End)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+)
+
+func TestMergeLedgerRanges(t *testing.T) {
+	got := mergeLedgerRanges([]LedgerRange{{20, 25}, {5, 5}, {6, 9}, {22, 30}, {40, 40}, {31, 31}})
+	want := []LedgerRange{{5, 9}, {20, 31}, {40, 40}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("merged = %v, want %v", got, want)
+	}
+	if got := mergeLedgerRanges(nil); got == nil || len(got) != 0 {
+		t.Fatalf("merged nil = %#v, want an empty slice so the report encodes []", got)
+	}
+	if got := formatLedgerRanges(want); got != "5-9, 20-31, 40" {
+		t.Fatalf("formatted = %q", got)
+	}
+}
+
+// writeBronzeFixture writes one Parquet file for table from a DuckDB query.
+func writeBronzeFixture(t *testing.T, db *sql.DB, outputDir, table, query string) {
+	t.Helper()
+	dir := filepath.Join(outputDir, "bronze", table, "range_0000000")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	copySQL := "COPY (" + query + ") TO '" + filepath.Join(dir, "part.parquet") + "' (FORMAT parquet)"
+	if _, err := db.Exec(copySQL); err != nil {
+		t.Fatalf("write %s fixture: %v", table, err)
+	}
+}
+
+func TestValidatorReportsFailingLedgerRanges(t *testing.T) {
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("open duckdb: %v", err)
+	}
+	defer db.Close()
+	outputDir := t.TempDir()
+
+	// Ledgers 100-105 without 103; 105 does not link to 104. The validated
+	// range runs to 106, which was never extracted.
+	writeBronzeFixture(t, db, outputDir, "ledgers", `
+		SELECT * FROM (VALUES
+			(100, 'h100', 'h99', 0, 0),
+			(101, 'h101', 'h100', 1, 1),
+			(102, 'h102', 'h101', 0, 0),
+			(104, 'h104', 'h103', 0, 0),
+			(105, 'h105', 'bad', 0, 0)
+		) AS l(sequence, ledger_hash, previous_ledger_hash, transaction_count, successful_tx_count)`)
+	// Transaction a claims two operations but only one was extracted.
+	writeBronzeFixture(t, db, outputDir, "transactions", `
+		SELECT 101 AS ledger_sequence, 'a' AS transaction_hash, true AS successful,
+			2 AS operation_count, 'test' AS version_label`)
+	writeBronzeFixture(t, db, outputDir, "operations", `
+		SELECT 101 AS ledger_sequence, 'a' AS transaction_hash, 0 AS operation_index`)
+	// An effect in ledger 102 points at a transaction that does not exist.
+	writeBronzeFixture(t, db, outputDir, "effects", `
+		SELECT * FROM (VALUES (101, 'a'), (102, 'missing')) AS e(ledger_sequence, transaction_hash)`)
+
+	validator, err := NewValidator(outputDir, 100, 106)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer validator.Close()
+
+	report, err := validator.RunAll(context.Background())
+	if err == nil {
+		t.Fatal("RunAll succeeded, want failed checks")
+	}
+
+	results := map[string]ValidationCheckResult{}
+	for _, c := range report.Checks {
+		results[c.Name] = c
+	}
+	wantRanges := map[string][]LedgerRange{
+		"ledger_hash_chain":              {{103, 106}},
+		"transaction_operation_counts":   {{101, 101}},
+		"effects_reference_transactions": {{102, 102}},
+	}
+	for name, want := range wantRanges {
+		got := results[name]
+		if got.Passed || got.Error != "" || !reflect.DeepEqual(got.FailingRanges, want) {
+			t.Errorf("%s = %+v, want failing ranges %v", name, got, want)
+		}
+	}
+	for _, name := range []string{"ledger_transaction_counts", "transaction_operation_consistency"} {
+		if !results[name].Passed {
+			t.Errorf("%s = %+v, want pass", name, results[name])
+		}
+	}
+	for _, name := range []string{"contract_events_reference_transactions", "token_transfers_reference_transactions"} {
+		if !results[name].Passed || !results[name].Skipped {
+			t.Errorf("%s = %+v, want skipped", name, results[name])
+		}
+	}
+	if want := []LedgerRange{{101, 106}}; !reflect.DeepEqual(report.FailingRanges, want) {
+		t.Fatalf("failing ranges = %v, want %v", report.FailingRanges, want)
+	}
+
+	path := filepath.Join(outputDir, "validation.json")
+	if err := report.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ValidationReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Passed || decoded.StartLedger != 100 || decoded.EndLedger != 106 || !reflect.DeepEqual(decoded.FailingRanges, report.FailingRanges) {
+		t.Fatalf("decoded report = %+v", decoded)
+	}
+}
@@ -36,6 +36,12 @@ type Config struct {
 		MaxRetries            int    `yaml:"max_retries"`             // Retry attempts on errors
 	} `yaml:"postgres"`
 
+	// Schema controls the versioned migrations in go/migrations. They extend the
+	// postgres-hot-buffer base schema, which must be applied first.
+	Schema struct {
+		AutoApply bool `yaml:"auto_apply"` // Apply pending migrations at startup
+	} `yaml:"schema"`
+
 	Checkpoint struct {
 		FilePath string `yaml:"file_path"`
 	} `yaml:"checkpoint"`
@@ -6,6 +6,7 @@ require (
 	github.com/jackc/pgx/v5 v5.7.2
 	github.com/stellar/go-stellar-sdk v0.6.0
 	github.com/withObsrvr/flow-proto v0.1.3
+	github.com/withObsrvr/obsrvr-lake/pgmigrate v0.0.0-00010101000000-000000000000
 	github.com/withObsrvr/stellar-extract v0.1.2
 	github.com/withObsrvr/ttp-processor-demo/stellar-live-source-datalake/go v0.0.0-00010101000000-000000000000
 	google.golang.org/grpc v1.79.3
@@ -114,3 +115,4 @@ require (
 )
 
 replace github.com/withObsrvr/ttp-processor-demo/stellar-live-source-datalake/go => ../../../stellar-live-source-datalake/go
+replace github.com/withObsrvr/obsrvr-lake/pgmigrate => ../../pgmigrate
@@ -30,6 +30,14 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// "migrate status|up|down [N]" manages the stellar_hot schema and exits.
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrateCommand(context.Background(), cfg, args[1:]); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
 	log.Printf("Starting %s", cfg.Service.Name)
 	log.Printf("Source endpoint: %s", cfg.Source.Endpoint)
 	log.Printf("PostgreSQL: %s:%d/%s", cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Database)
@@ -66,6 +74,12 @@ func main() {
 	}
 	log.Printf("Connected to PostgreSQL successfully")
 
+	if cfg.Schema.AutoApply {
+		if err := EnsureStellarHotSchema(ctx, dbpool); err != nil {
+			log.Fatalf("Failed to apply stellar_hot migrations: %v", err)
+		}
+	}
+
 	// Start health server
 	healthServer := NewHealthServer(cfg.Service.HealthPort, checkpoint)
 	if err := healthServer.Start(); err != nil {
@@ -126,6 +140,11 @@ func main() {
 		if err := streamLedgers(ctx, client, writer, startLedger, cfg.Source.EndLedger); err != nil {
 			log.Printf("Stream error: %v", err)
 			cancel()
+			return
+		}
+		if cfg.Source.EndLedger > 0 {
+			log.Printf("Reached end ledger %d", cfg.Source.EndLedger)
+			cancel()
 		}
 	}()
 
@@ -154,8 +173,12 @@ func main() {
 // The receiver and writer run in separate goroutines connected by a buffered channel,
 // so slow batch writes don't cause the gRPC stream to time out.
 func streamLedgers(ctx context.Context, client pb.RawLedgerServiceClient, writer *Writer, startLedger, endLedger uint32) error {
+	// The source closes the stream after endLedger, so a bounded backfill
+	// ends with io.EOF instead of being cancelled here.
 	req := &pb.StreamLedgersRequest{
-		StartLedger: startLedger,
+		StartLedger:   startLedger,
+		EndLedger:     endLedger,
+		BatchSizeHint: uint32(writer.config.Postgres.BatchSize),
 	}
 
 	stream, err := client.StreamRawLedgers(ctx, req)
@@ -0,0 +1,2 @@
+ALTER TABLE operations_row_v2 DROP COLUMN IF EXISTS soroban_arguments_json;
+ALTER TABLE operations_row_v2 DROP COLUMN IF EXISTS transaction_index;
@@ -2,8 +2,6 @@
 -- Purpose: Enable extraction of contract invocation arguments and TOID generation
 -- Date: 2026-01-03
 
-BEGIN;
-
 -- Add transaction_index column (for TOID generation)
 ALTER TABLE operations_row_v2
 ADD COLUMN IF NOT EXISTS transaction_index INT;
@@ -33,5 +31,3 @@ BEGIN
 
     RAISE NOTICE 'Successfully added contract invocation fields to operations_row_v2';
 END $$;
-
-COMMIT;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_operations_call_depth;
+DROP INDEX IF EXISTS idx_operations_contract_calls;
+DROP INDEX IF EXISTS idx_operations_contracts_involved;
+
+ALTER TABLE operations_row_v2 DROP COLUMN IF EXISTS max_call_depth;
+ALTER TABLE operations_row_v2 DROP COLUMN IF EXISTS contracts_involved;
+ALTER TABLE operations_row_v2 DROP COLUMN IF EXISTS contract_calls_json;
@@ -2,8 +2,6 @@
 -- Purpose: Enable tracking of cross-contract call hierarchies for Freighter
 -- Date: 2026-01-04
 
-BEGIN;
-
 -- Add contract_calls_json column (JSONB array of call relationships)
 -- Structure: [{"from": "CXXX", "to": "CYYY", "function": "swap", "depth": 1, "order": 0}, ...]
 ALTER TABLE operations_row_v2
@@ -62,5 +60,3 @@ BEGIN
 
     RAISE NOTICE 'Successfully added call graph fields to operations_row_v2';
 END $$;
-
-COMMIT;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_contract_data_token_symbol;
+DROP INDEX IF EXISTS idx_contract_data_token_name;
+
+ALTER TABLE contract_data_snapshot_v1 DROP COLUMN IF EXISTS token_decimals;
+ALTER TABLE contract_data_snapshot_v1 DROP COLUMN IF EXISTS token_symbol;
+ALTER TABLE contract_data_snapshot_v1 DROP COLUMN IF EXISTS token_name;
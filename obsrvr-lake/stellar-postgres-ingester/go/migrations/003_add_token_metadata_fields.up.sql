@@ -2,8 +2,6 @@
 -- Purpose: Store decoded token name/symbol/decimals from Soroban METADATA key
 -- Date: 2026-03-05
 
-BEGIN;
-
 -- Add token_name column (from METADATA.name in contract instance storage)
 ALTER TABLE contract_data_snapshot_v1
 ADD COLUMN IF NOT EXISTS token_name TEXT;
@@ -54,5 +52,3 @@ BEGIN
 
     RAISE NOTICE 'Successfully added token metadata fields to contract_data_snapshot_v1';
 END $$;
-
-COMMIT;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS token_transfers_stream_v1;
+
+DROP INDEX IF EXISTS idx_operations_row_v2_transaction_id;
+DROP INDEX IF EXISTS idx_operations_row_v2_operation_id;
+ALTER TABLE operations_row_v2 DROP COLUMN IF EXISTS operation_id;
+ALTER TABLE operations_row_v2 DROP COLUMN IF EXISTS transaction_id;
+
+DROP INDEX IF EXISTS idx_transactions_row_v2_transaction_id;
+ALTER TABLE transactions_row_v2 DROP COLUMN IF EXISTS transaction_id;
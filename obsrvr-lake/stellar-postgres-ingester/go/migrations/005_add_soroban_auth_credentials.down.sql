@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_operations_soroban_auth_addresses;
+
+ALTER TABLE operations_row_v2 DROP COLUMN IF EXISTS soroban_auth_addresses;
+ALTER TABLE operations_row_v2 DROP COLUMN IF EXISTS soroban_auth_credentials_types;
@@ -14,8 +14,6 @@
 -- one auth entry. NULL for non-Soroban ops and InvokeHostFunction ops with
 -- zero auth entries.
 
-BEGIN;
-
 ALTER TABLE operations_row_v2
   ADD COLUMN IF NOT EXISTS soroban_auth_credentials_types TEXT[];
 
@@ -49,5 +47,3 @@ BEGIN
 
     RAISE NOTICE 'Successfully added soroban auth credential columns to operations_row_v2';
 END $$;
-
-COMMIT;
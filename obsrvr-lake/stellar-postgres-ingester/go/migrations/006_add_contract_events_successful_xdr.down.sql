@@ -0,0 +1,2 @@
+ALTER TABLE contract_events_stream_v1 DROP COLUMN IF EXISTS contract_event_xdr;
+ALTER TABLE contract_events_stream_v1 DROP COLUMN IF EXISTS successful;
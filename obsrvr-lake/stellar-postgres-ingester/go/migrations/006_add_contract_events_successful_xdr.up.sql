@@ -5,8 +5,6 @@
 -- into parity so the ingester's COPY / INSERT succeeds.
 -- Date: 2026-04-15
 
-BEGIN;
-
 ALTER TABLE contract_events_stream_v1
   ADD COLUMN IF NOT EXISTS successful BOOLEAN;
 
@@ -38,5 +36,3 @@ BEGIN
     END IF;
     RAISE NOTICE 'Successfully synced contract_events_stream_v1 columns';
 END $$;
-
-COMMIT;
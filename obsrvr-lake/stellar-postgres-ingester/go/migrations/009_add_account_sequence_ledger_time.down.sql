@@ -0,0 +1,2 @@
+ALTER TABLE accounts_snapshot_v1 DROP COLUMN IF EXISTS sequence_time;
+ALTER TABLE accounts_snapshot_v1 DROP COLUMN IF EXISTS sequence_ledger;
@@ -0,0 +1,9 @@
+-- Drops only the indexes this migration introduced; the others share their
+-- names with the postgres-hot-buffer base schema and stay.
+-- pgmigrate:no-transaction
+
+DROP INDEX CONCURRENTLY IF EXISTS idx_ttl_snapshot_ledger_seq;
+DROP INDEX CONCURRENTLY IF EXISTS idx_offers_snapshot_ledger_seq;
+DROP INDEX CONCURRENTLY IF EXISTS idx_contract_creations_ledger;
+DROP INDEX CONCURRENTLY IF EXISTS idx_trustlines_snapshot_ledger_seq;
+DROP INDEX CONCURRENTLY IF EXISTS idx_accounts_snapshot_ledger_seq;
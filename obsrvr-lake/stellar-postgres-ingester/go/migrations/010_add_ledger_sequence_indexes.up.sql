@@ -1,12 +1,10 @@
--- Phase 4: Performance indexes for bronze hot and silver hot
--- Run these on the respective databases to improve transformer read performance.
--- All use CONCURRENTLY to avoid blocking writes.
-
--- =============================================================================
--- BRONZE HOT (stellar_hot) — Run on bronze hot database
--- =============================================================================
--- These indexes support the WHERE ledger_sequence BETWEEN $1 AND $2 range scans
--- that every transform function uses to read from bronze.
+-- Migration 010: ledger_sequence indexes for the silver transformer's reads.
+-- These support the WHERE ledger_sequence BETWEEN $1 AND $2 range scans that
+-- every silver-realtime-transformer transform function uses to read bronze.
+-- Moved here from silver-realtime-transformer migration 004, which used to be
+-- run by hand against both databases.
+-- Uses CONCURRENTLY to avoid blocking ingestion writes.
+-- pgmigrate:no-transaction
 
 CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_operations_ledger_seq
     ON operations_row_v2 (ledger_sequence);
@@ -55,12 +53,3 @@ CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_config_settings_ledger_seq
 
 CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_trades_ledger_seq
     ON trades_row_v1 (ledger_sequence);
-
--- =============================================================================
--- SILVER HOT (silver_hot) — Run on silver hot database
--- =============================================================================
--- Supports the semantic_entities_contracts Phase 3 fix (incremental upsert)
--- and general semantic transform performance.
-
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_contract_invocations_contract_id
-    ON contract_invocations_raw (contract_id);
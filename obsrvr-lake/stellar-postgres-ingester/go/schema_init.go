@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/withObsrvr/obsrvr-lake/pgmigrate"
+)
+
+// stellarHotMigrations holds the ingester's changes to the stellar_hot schema,
+// applied on top of the postgres-hot-buffer base schema.
+//
+//go:embed migrations/*.sql
+var stellarHotMigrations embed.FS
+
+// migrationComponent identifies this service's rows in obsrvr_lake.schema_migrations.
+const migrationComponent = "stellar-postgres-ingester"
+
+// withStellarHotMigrator runs fn with a migrator over the stellar_hot pool.
+func withStellarHotMigrator(pool *pgxpool.Pool, fn func(*pgmigrate.Migrator) error) error {
+	migrations, err := pgmigrate.Load(stellarHotMigrations, "migrations")
+	if err != nil {
+		return err
+	}
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+	return fn(pgmigrate.New(db, migrationComponent, migrations))
+}
+
+// EnsureStellarHotSchema applies any pending stellar_hot migrations.
+func EnsureStellarHotSchema(ctx context.Context, pool *pgxpool.Pool) error {
+	return withStellarHotMigrator(pool, func(m *pgmigrate.Migrator) error {
+		applied, err := m.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("apply stellar_hot migrations: %w", err)
+		}
+		log.Printf("stellar_hot schema up to date (%d migrations applied)", applied)
+		return nil
+	})
+}
+
+// runMigrateCommand runs "migrate status|up|down [N]" against stellar_hot.
+func runMigrateCommand(ctx context.Context, cfg *Config, args []string) error {
+	pool, err := pgxpool.New(ctx, cfg.GetPostgresConnectionString())
+	if err != nil {
+		return fmt.Errorf("connect to PostgreSQL: %w", err)
+	}
+	defer pool.Close()
+
+	return withStellarHotMigrator(pool, func(m *pgmigrate.Migrator) error {
+		return pgmigrate.Command(ctx, m, args, os.Stdout)
+	})
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultAPIKeyTable            = "api_keys"
+	defaultAPIKeyCacheTTL         = 60 * time.Second
+	apiKeyFileReloadCheckInterval = 10 * time.Second
+)
+
+// apiKey is a key record as stored; the key itself is only ever kept as its
+// SHA-256 hash.
+type apiKey struct {
+	ID       string
+	Name     string
+	Plan     string
+	Disabled bool
+}
+
+// apiKeyStore resolves a presented key to its record. A key that does not
+// exist returns nil and no error.
+type apiKeyStore interface {
+	LookupAPIKey(ctx context.Context, keyHash string) (*apiKey, error)
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func newAPIKeyStore(cfg *APIKeyConfig) (apiKeyStore, error) {
+	ttl := defaultAPIKeyCacheTTL
+	if cfg.CacheTTLSeconds > 0 {
+		ttl = time.Duration(cfg.CacheTTLSeconds) * time.Second
+	}
+	switch cfg.Store {
+	case "", "file":
+		return NewFileAPIKeyStore(cfg.File)
+	case "postgres":
+		if cfg.Postgres == nil {
+			return nil, errors.New("api_keys.postgres is required for the postgres store")
+		}
+		store, err := NewPostgresAPIKeyStore(*cfg.Postgres, cfg.Table)
+		if err != nil {
+			return nil, err
+		}
+		return newCachedAPIKeyStore(store, ttl), nil
+	default:
+		return nil, fmt.Errorf("invalid api_keys.store %q: must be %q or %q", cfg.Store, "file", "postgres")
+	}
+}
+
+// apiKeyFile is the YAML layout of a file key store:
+//
+//	keys:
+//	  - id: acme
+//	    name: Acme Corp
+//	    plan: pro
+//	    key_sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+type apiKeyFile struct {
+	Keys []struct {
+		ID        string `yaml:"id"`
+		Name      string `yaml:"name"`
+		Plan      string `yaml:"plan"`
+		KeySHA256 string `yaml:"key_sha256"`
+		Disabled  bool   `yaml:"disabled"`
+	} `yaml:"keys"`
+}
+
+// FileAPIKeyStore serves keys from a YAML file. The file is re-read when its
+// modification time changes, so keys can be rotated without a restart.
+type FileAPIKeyStore struct {
+	path string
+	now  func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]*apiKey
+	modTime     time.Time
+	lastChecked time.Time
+}
+
+func NewFileAPIKeyStore(path string) (*FileAPIKeyStore, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, errors.New("api_keys.file is required for the file store")
+	}
+	store := &FileAPIKeyStore{path: path, now: time.Now}
+	if err := store.reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *FileAPIKeyStore) LookupAPIKey(ctx context.Context, keyHash string) (*apiKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now := s.now(); now.Sub(s.lastChecked) >= apiKeyFileReloadCheckInterval {
+		s.lastChecked = now
+		if info, err := os.Stat(s.path); err == nil && !info.ModTime().Equal(s.modTime) {
+			// A bad edit keeps serving the previous keys rather than locking
+			// every client out.
+			if err := s.reloadLocked(); err != nil {
+				log.Printf("Warning: keeping previous API keys: %v", err)
+			}
+		}
+	}
+	return s.keys[keyHash], nil
+}
+
+func (s *FileAPIKeyStore) reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastChecked = s.now()
+	return s.reloadLocked()
+}
+
+func (s *FileAPIKeyStore) reloadLocked() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("stat api key file: %w", err)
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("read api key file: %w", err)
+	}
+	var file apiKeyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("parse api key file: %w", err)
+	}
+	keys := make(map[string]*apiKey, len(file.Keys))
+	for i, entry := range file.Keys {
+		hash := strings.ToLower(strings.TrimSpace(entry.KeySHA256))
+		if len(hash) != sha256.Size*2 {
+			return fmt.Errorf("api key %d (%q): key_sha256 must be a hex SHA-256 digest", i, entry.ID)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return fmt.Errorf("api key %d (%q): key_sha256 must be a hex SHA-256 digest", i, entry.ID)
+		}
+		if entry.ID == "" {
+			return fmt.Errorf("api key %d: id is required", i)
+		}
+		keys[hash] = &apiKey{ID: entry.ID, Name: entry.Name, Plan: entry.Plan, Disabled: entry.Disabled}
+	}
+	s.keys = keys
+	s.modTime = info.ModTime()
+	return nil
+}
+
+var sqlTableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// PostgresAPIKeyStore looks keys up in a table with at least these columns:
+//
+//	CREATE TABLE api_keys (
+//	    key_sha256 TEXT PRIMARY KEY,
+//	    id         TEXT NOT NULL,
+//	    name       TEXT NOT NULL DEFAULT '',
+//	    plan       TEXT NOT NULL DEFAULT '',
+//	    disabled   BOOLEAN NOT NULL DEFAULT false
+//	);
+type PostgresAPIKeyStore struct {
+	db    *sql.DB
+	query string
+}
+
+func NewPostgresAPIKeyStore(cfg PostgresConfig, table string) (*PostgresAPIKeyStore, error) {
+	if table == "" {
+		table = defaultAPIKeyTable
+	}
+	if !sqlTableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid api_keys.table %q", table)
+	}
+	db, err := sql.Open("postgres", cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open api key database: %w", err)
+	}
+	if cfg.MaxConnections > 0 {
+		db.SetMaxOpenConns(cfg.MaxConnections)
+	}
+	queryAPIMetrics.registerPool("api_keys", db)
+	return &PostgresAPIKeyStore{
+		db:    db,
+		query: "SELECT id, name, plan, disabled FROM " + table + " WHERE key_sha256 = $1",
+	}, nil
+}
+
+func (s *PostgresAPIKeyStore) LookupAPIKey(ctx context.Context, keyHash string) (*apiKey, error) {
+	var key apiKey
+	err := s.db.QueryRowContext(ctx, s.query, keyHash).Scan(&key.ID, &key.Name, &key.Plan, &key.Disabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *PostgresAPIKeyStore) Close() error {
+	return s.db.Close()
+}
+
+// cachedAPIKeyStore keeps lookups, including misses, for ttl so a remote
+// store is not queried on every request. Revoking a key therefore takes up to
+// ttl to apply.
+type cachedAPIKeyStore struct {
+	next apiKeyStore
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedAPIKey
+}
+
+type cachedAPIKey struct {
+	key     *apiKey
+	expires time.Time
+}
+
+// maxCachedAPIKeys bounds the cache against clients sending random keys.
+const maxCachedAPIKeys = 10000
+
+func newCachedAPIKeyStore(next apiKeyStore, ttl time.Duration) *cachedAPIKeyStore {
+	return &cachedAPIKeyStore{next: next, ttl: ttl, now: time.Now, entries: map[string]cachedAPIKey{}}
+}
+
+func (s *cachedAPIKeyStore) LookupAPIKey(ctx context.Context, keyHash string) (*apiKey, error) {
+	now := s.now()
+	s.mu.Lock()
+	entry, ok := s.entries[keyHash]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.key, nil
+	}
+
+	key, err := s.next.LookupAPIKey(ctx, keyHash)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if len(s.entries) >= maxCachedAPIKeys {
+		for hash, cached := range s.entries {
+			if !now.Before(cached.expires) {
+				delete(s.entries, hash)
+			}
+		}
+		if len(s.entries) >= maxCachedAPIKeys {
+			s.entries = map[string]cachedAPIKey{}
+		}
+	}
+	s.entries[keyHash] = cachedAPIKey{key: key, expires: now.Add(s.ttl)}
+	s.mu.Unlock()
+	return key, nil
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultExpensiveRoutes are the routes that scan cold storage or run large
+// DuckDB queries. They get their own, smaller budget so one scraper cannot
+// starve the cheap hot-path lookups.
+var defaultExpensiveRoutes = []string{
+	"/api/v1/silver/ledger/*/full",
+	"/api/v1/silver/ledgers/*/full",
+	"/api/v1/bronze/*",
+	"/api/v1/gold/*",
+	"/api/v1/horizon-compat/paths/*",
+	"/api/v1/horizon-compat/trade_aggregations",
+	"/graphql",
+}
+
+var defaultExemptRoutes = []string{
+	"/health",
+	"/metrics",
+	"/swagger/*",
+}
+
+// apiKeyClientIdleTTL is how long a client's limiter state is kept after its
+// buckets have refilled, unless it still has quota usage for the current day.
+const (
+	apiKeyClientIdleTTL      = 10 * time.Minute
+	apiKeyClientSweepEvery   = time.Minute
+	apiKeyStoreLookupTimeout = 2 * time.Second
+)
+
+// apiKeyLimiter authenticates requests against an apiKeyStore and enforces
+// each client's plan. Limiter state is held in memory, so with several
+// replicas every replica enforces the full budget on its own.
+type apiKeyLimiter struct {
+	store             apiKeyStore
+	plans             map[string]APIKeyPlan
+	defaultPlan       string
+	anonymousPlan     string
+	expensiveRoutes   []string
+	exemptRoutes      []string
+	trustForwardedFor bool
+	now               func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*apiKeyClientState
+	lastSweep time.Time
+}
+
+type apiKeyClientState struct {
+	general   tokenBucket
+	expensive tokenBucket
+	quotaDay  string
+	quotaUsed int64
+	fullAt    time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	started bool
+}
+
+// apiKeyDecision is the outcome of one rate-limit check and carries what the
+// RateLimit-* headers report: the most constrained of the applicable limits.
+type apiKeyDecision struct {
+	allowed    bool
+	limit      int64
+	remaining  int64
+	reset      time.Duration
+	retryAfter time.Duration
+	policies   []string
+}
+
+func newAPIKeyLimiter(cfg *APIKeyConfig, store apiKeyStore) (*apiKeyLimiter, error) {
+	if cfg.DefaultPlan == "" {
+		return nil, errors.New("api_keys.default_plan is required")
+	}
+	for _, name := range []string{cfg.DefaultPlan, cfg.AnonymousPlan} {
+		if name == "" {
+			continue
+		}
+		if _, ok := cfg.Plans[name]; !ok {
+			return nil, fmt.Errorf("api_keys plan %q is not defined in api_keys.plans", name)
+		}
+	}
+	for name, plan := range cfg.Plans {
+		if plan.RequestsPerSecond < 0 || plan.ExpensiveRequestsPerSecond < 0 || plan.Burst < 0 || plan.ExpensiveBurst < 0 || plan.DailyQuota < 0 {
+			return nil, fmt.Errorf("api_keys plan %q has a negative limit", name)
+		}
+	}
+	expensive := cfg.ExpensiveRoutes
+	if len(expensive) == 0 {
+		expensive = defaultExpensiveRoutes
+	}
+	exempt := cfg.ExemptRoutes
+	if len(exempt) == 0 {
+		exempt = defaultExemptRoutes
+	}
+	return &apiKeyLimiter{
+		store:             store,
+		plans:             cfg.Plans,
+		defaultPlan:       cfg.DefaultPlan,
+		anonymousPlan:     cfg.AnonymousPlan,
+		expensiveRoutes:   expensive,
+		exemptRoutes:      exempt,
+		trustForwardedFor: cfg.TrustForwardedFor,
+		now:               time.Now,
+		clients:           map[string]*apiKeyClientState{},
+	}, nil
+}
+
+// matchRoutePattern reports whether urlPath matches any pattern. A pattern
+// ending in "*" matches that prefix; anything else uses path.Match, where "*"
+// stops at "/".
+func matchRoutePattern(patterns []string, urlPath string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && !strings.ContainsAny(prefix, "*?[") {
+			if strings.HasPrefix(urlPath, prefix) {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, urlPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// apiKeyFromRequest reads the key from "Authorization: Api-Key <key>", the
+// scheme documented in the OpenAPI spec, or from an X-Api-Key header.
+func apiKeyFromRequest(r *http.Request) string {
+	if scheme, key, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Api-Key") {
+		return strings.TrimSpace(key)
+	}
+	return strings.TrimSpace(r.Header.Get("X-Api-Key"))
+}
+
+func (l *apiKeyLimiter) clientIP(r *http.Request) string {
+	if l.trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (l *apiKeyLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions || matchRoutePattern(l.exemptRoutes, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var clientID, planName string
+		if presented := apiKeyFromRequest(r); presented != "" {
+			ctx, cancel := context.WithTimeout(r.Context(), apiKeyStoreLookupTimeout)
+			key, err := l.store.LookupAPIKey(ctx, hashAPIKey(presented))
+			cancel()
+			switch {
+			case err != nil:
+				log.Printf("API key lookup failed: %v", err)
+				writeAPIKeyError(w, r, http.StatusServiceUnavailable, "API key verification is temporarily unavailable")
+				return
+			case key == nil:
+				writeAPIKeyError(w, r, http.StatusUnauthorized, "invalid API key")
+				return
+			case key.Disabled:
+				writeAPIKeyError(w, r, http.StatusForbidden, "API key is disabled")
+				return
+			}
+			clientID, planName = "key:"+key.ID, key.Plan
+			if _, ok := l.plans[planName]; !ok {
+				if planName != "" {
+					log.Printf("Warning: API key %s has unknown plan %q, using %q", key.ID, planName, l.defaultPlan)
+				}
+				planName = l.defaultPlan
+			}
+		} else {
+			if l.anonymousPlan == "" {
+				writeAPIKeyError(w, r, http.StatusUnauthorized, `API key required: send "Authorization: Api-Key <key>"`)
+				return
+			}
+			clientID, planName = "ip:"+l.clientIP(r), l.anonymousPlan
+		}
+
+		decision := l.allow(clientID, l.plans[planName], matchRoutePattern(l.expensiveRoutes, r.URL.Path))
+		setRateLimitHeaders(w.Header(), decision)
+		if !decision.allowed {
+			w.Header().Set("Retry-After", strconv.FormatInt(ceilSeconds(decision.retryAfter), 10))
+			writeAPIKeyError(w, r, http.StatusTooManyRequests, "rate limit exceeded; retry after the Retry-After interval")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allow charges one request to clientID's buckets and daily quota. Nothing is
+// charged unless every applicable limit has room.
+func (l *apiKeyLimiter) allow(clientID string, plan APIKeyPlan, expensive bool) apiKeyDecision {
+	now := l.now()
+	day := now.UTC().Format("2006-01-02")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweepLocked(now, day)
+
+	state := l.clients[clientID]
+	if state == nil {
+		state = &apiKeyClientState{}
+		l.clients[clientID] = state
+	}
+	if state.quotaDay != day {
+		state.quotaDay, state.quotaUsed = day, 0
+	}
+
+	type limitCheck struct {
+		bucket *tokenBucket
+		rate   float64
+		burst  int
+	}
+	checks := []limitCheck{{&state.general, plan.RequestsPerSecond, plan.Burst}}
+	if expensive {
+		checks = append(checks, limitCheck{&state.expensive, plan.ExpensiveRequestsPerSecond, plan.ExpensiveBurst})
+	}
+
+	decision := apiKeyDecision{allowed: true, remaining: -1}
+	consider := func(limit, remaining int64, reset time.Duration) {
+		if decision.remaining < 0 || remaining < decision.remaining {
+			decision.limit, decision.remaining, decision.reset = limit, remaining, reset
+		}
+	}
+
+	for _, check := range checks {
+		if check.rate <= 0 {
+			continue
+		}
+		burst := bucketBurst(check.rate, check.burst)
+		check.bucket.refill(now, check.rate, burst)
+		if check.bucket.tokens < 1 {
+			decision.allowed = false
+			decision.retryAfter = max(decision.retryAfter, secondsDuration((1-check.bucket.tokens)/check.rate))
+		}
+	}
+	if plan.DailyQuota > 0 && state.quotaUsed >= plan.DailyQuota {
+		decision.allowed = false
+		decision.retryAfter = max(decision.retryAfter, untilNextUTCDay(now))
+	}
+
+	for _, check := range checks {
+		if check.rate <= 0 {
+			continue
+		}
+		burst := bucketBurst(check.rate, check.burst)
+		if decision.allowed {
+			check.bucket.tokens--
+		}
+		toFull := secondsDuration((float64(burst) - check.bucket.tokens) / check.rate)
+		consider(int64(burst), int64(math.Floor(check.bucket.tokens)), toFull)
+		decision.policies = append(decision.policies, fmt.Sprintf("%d;w=%d", burst, ceilSeconds(secondsDuration(float64(burst)/check.rate))))
+		if fullAt := now.Add(toFull); fullAt.After(state.fullAt) {
+			state.fullAt = fullAt
+		}
+	}
+	if plan.DailyQuota > 0 {
+		if decision.allowed {
+			state.quotaUsed++
+		}
+		consider(plan.DailyQuota, plan.DailyQuota-state.quotaUsed, untilNextUTCDay(now))
+		decision.policies = append(decision.policies, fmt.Sprintf("%d;w=86400", plan.DailyQuota))
+	}
+	return decision
+}
+
+// sweepLocked drops idle clients whose buckets are full again and who have no
+// quota usage today, so per-IP anonymous state does not grow without bound.
+func (l *apiKeyLimiter) sweepLocked(now time.Time, day string) {
+	if now.Sub(l.lastSweep) < apiKeyClientSweepEvery {
+		return
+	}
+	l.lastSweep = now
+	for id, state := range l.clients {
+		if now.Sub(state.fullAt) < apiKeyClientIdleTTL {
+			continue
+		}
+		if state.quotaDay == day && state.quotaUsed > 0 {
+			continue
+		}
+		delete(l.clients, id)
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time, rate float64, burst int) {
+	if !b.started {
+		b.tokens, b.updated, b.started = float64(burst), now, true
+		return
+	}
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+	}
+	b.updated = now
+}
+
+// bucketBurst defaults an unset burst to one second's worth of requests.
+func bucketBurst(rate float64, burst int) int {
+	if burst > 0 {
+		return burst
+	}
+	return max(1, int(math.Ceil(rate)))
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return max(1, int64(math.Ceil(d.Seconds())))
+}
+
+func untilNextUTCDay(now time.Time) time.Duration {
+	utc := now.UTC()
+	return time.Date(utc.Year(), utc.Month(), utc.Day()+1, 0, 0, 0, 0, time.UTC).Sub(utc)
+}
+
+// setRateLimitHeaders writes the IETF RateLimit header fields. Plans with no
+// limits get none.
+func setRateLimitHeaders(h http.Header, d apiKeyDecision) {
+	if len(d.policies) == 0 {
+		return
+	}
+	remaining := max(d.remaining, 0)
+	if !d.allowed {
+		remaining = 0
+	}
+	reset := d.reset
+	if !d.allowed && d.retryAfter > reset {
+		reset = d.retryAfter
+	}
+	h.Set("RateLimit-Limit", strconv.FormatInt(d.limit, 10))
+	h.Set("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+	h.Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(reset), 10))
+	h.Set("RateLimit-Policy", strings.Join(d.policies, ", "))
+}
+
+// writeAPIKeyError answers in the Horizon problem format on Horizon-compat
+// routes, so Horizon SDKs surface the error, and in the API error envelope
+// everywhere else.
+func writeAPIKeyError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Api-Key")
+	}
+	if !strings.HasPrefix(r.URL.Path, "/api/v1/horizon-compat/") {
+		writeError(w, status, message)
+		return
+	}
+	switch status {
+	case http.StatusTooManyRequests:
+		renderHorizonProblem(w, r, horizonProblem(status, "rate_limit_exceeded", "Rate Limit Exceeded", message))
+	case http.StatusServiceUnavailable:
+		renderHorizonProblem(w, r, horizonProblem(status, "service_unavailable", "Service Unavailable", message))
+	case http.StatusForbidden:
+		renderHorizonProblem(w, r, horizonProblem(status, "forbidden", "Forbidden", message))
+	default:
+		renderHorizonProblem(w, r, horizonProblem(status, "unauthorized", "Unauthorized", message))
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testAPIKey = "test-key-1"
+
+type fakeAPIKeyStore struct {
+	keys    map[string]*apiKey
+	lookups int
+	err     error
+}
+
+func (s *fakeAPIKeyStore) LookupAPIKey(ctx context.Context, keyHash string) (*apiKey, error) {
+	s.lookups++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.keys[keyHash], nil
+}
+
+func newTestAPIKeyLimiter(t *testing.T, cfg *APIKeyConfig) (*apiKeyLimiter, *time.Time) {
+	t.Helper()
+	store := &fakeAPIKeyStore{keys: map[string]*apiKey{
+		hashAPIKey(testAPIKey): {ID: "acme", Plan: "pro"},
+		hashAPIKey("disabled"): {ID: "old", Plan: "pro", Disabled: true},
+	}}
+	limiter, err := newAPIKeyLimiter(cfg, store)
+	if err != nil {
+		t.Fatalf("newAPIKeyLimiter: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func serveAPIKeyRequest(limiter *apiKeyLimiter, target, key string) *httptest.ResponseRecorder {
+	handler := limiter.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if key != "" {
+		req.Header.Set("Authorization", "Api-Key "+key)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIKeyMiddlewareRejectsMissingInvalidAndDisabledKeys(t *testing.T) {
+	limiter, _ := newTestAPIKeyLimiter(t, &APIKeyConfig{
+		Plans:       map[string]APIKeyPlan{"pro": {}},
+		DefaultPlan: "pro",
+	})
+
+	for _, tc := range []struct {
+		target, key string
+		want        int
+	}{
+		{"/api/v1/silver/stats/network", "", http.StatusUnauthorized},
+		{"/api/v1/silver/stats/network", "wrong", http.StatusUnauthorized},
+		{"/api/v1/silver/stats/network", "disabled", http.StatusForbidden},
+		{"/api/v1/silver/stats/network", testAPIKey, http.StatusNoContent},
+		{"/health", "", http.StatusNoContent},
+		{"/swagger/index.html", "", http.StatusNoContent},
+	} {
+		if rec := serveAPIKeyRequest(limiter, tc.target, tc.key); rec.Code != tc.want {
+			t.Fatalf("%s key=%q status = %d, want %d", tc.target, tc.key, rec.Code, tc.want)
+		}
+	}
+
+	rec := serveAPIKeyRequest(limiter, "/api/v1/horizon-compat/ledgers", "")
+	var problem struct {
+		Type   string `json:"type"`
+		Status int    `json:"status"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if problem.Status != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != "Api-Key" {
+		t.Fatalf("horizon problem = %+v, headers = %v", problem, rec.Header())
+	}
+}
+
+func TestAPIKeyMiddlewareRateLimitsWithHeaders(t *testing.T) {
+	limiter, now := newTestAPIKeyLimiter(t, &APIKeyConfig{
+		Plans:       map[string]APIKeyPlan{"pro": {RequestsPerSecond: 1, Burst: 2}},
+		DefaultPlan: "pro",
+	})
+
+	rec := serveAPIKeyRequest(limiter, "/api/v1/silver/stats/network", testAPIKey)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("RateLimit-Limit") != "2" ||
+		rec.Header().Get("RateLimit-Remaining") != "1" || rec.Header().Get("RateLimit-Policy") != "2;w=2" {
+		t.Fatalf("first: status = %d, headers = %v", rec.Code, rec.Header())
+	}
+	serveAPIKeyRequest(limiter, "/api/v1/silver/stats/network", testAPIKey)
+
+	rec = serveAPIKeyRequest(limiter, "/api/v1/silver/stats/network", testAPIKey)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("third: status = %d, headers = %v", rec.Code, rec.Header())
+	}
+
+	*now = now.Add(time.Second)
+	if rec := serveAPIKeyRequest(limiter, "/api/v1/silver/stats/network", testAPIKey); rec.Code != http.StatusNoContent {
+		t.Fatalf("after refill status = %d", rec.Code)
+	}
+}
+
+func TestAPIKeyMiddlewareSeparatesExpensiveBudget(t *testing.T) {
+	limiter, _ := newTestAPIKeyLimiter(t, &APIKeyConfig{
+		Plans:       map[string]APIKeyPlan{"pro": {RequestsPerSecond: 100, ExpensiveRequestsPerSecond: 1, ExpensiveBurst: 1}},
+		DefaultPlan: "pro",
+	})
+
+	if rec := serveAPIKeyRequest(limiter, "/api/v1/silver/ledger/100/full", testAPIKey); rec.Code != http.StatusNoContent {
+		t.Fatalf("first expensive status = %d", rec.Code)
+	}
+	if rec := serveAPIKeyRequest(limiter, "/api/v1/silver/ledgers/101/full", testAPIKey); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second expensive status = %d, want 429", rec.Code)
+	}
+	if rec := serveAPIKeyRequest(limiter, "/api/v1/silver/ledgers/101", testAPIKey); rec.Code != http.StatusNoContent {
+		t.Fatalf("cheap route status = %d", rec.Code)
+	}
+}
+
+func TestAPIKeyMiddlewareEnforcesDailyQuota(t *testing.T) {
+	limiter, now := newTestAPIKeyLimiter(t, &APIKeyConfig{
+		Plans:         map[string]APIKeyPlan{"pro": {DailyQuota: 2}, "anon": {DailyQuota: 1}},
+		DefaultPlan:   "pro",
+		AnonymousPlan: "anon",
+	})
+
+	for i := 0; i < 2; i++ {
+		if rec := serveAPIKeyRequest(limiter, "/api/v1/silver/stats/network", testAPIKey); rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d status = %d", i, rec.Code)
+		}
+	}
+	rec := serveAPIKeyRequest(limiter, "/api/v1/silver/stats/network", testAPIKey)
+	// The test clock is 23:59 UTC, so the quota resets in a minute.
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" || rec.Header().Get("RateLimit-Policy") != "2;w=86400" {
+		t.Fatalf("over quota: status = %d, headers = %v", rec.Code, rec.Header())
+	}
+	// Anonymous clients are limited per IP on their own plan.
+	if rec := serveAPIKeyRequest(limiter, "/api/v1/silver/stats/network", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("anonymous status = %d", rec.Code)
+	}
+
+	*now = now.Add(time.Minute)
+	if rec := serveAPIKeyRequest(limiter, "/api/v1/silver/stats/network", testAPIKey); rec.Code != http.StatusNoContent {
+		t.Fatalf("next day status = %d", rec.Code)
+	}
+}
+
+func TestNewAPIKeyLimiterValidatesPlans(t *testing.T) {
+	for _, cfg := range []*APIKeyConfig{
+		{Plans: map[string]APIKeyPlan{"pro": {}}},
+		{Plans: map[string]APIKeyPlan{"pro": {}}, DefaultPlan: "free"},
+		{Plans: map[string]APIKeyPlan{"pro": {}}, DefaultPlan: "pro", AnonymousPlan: "anon"},
+		{Plans: map[string]APIKeyPlan{"pro": {DailyQuota: -1}}, DefaultPlan: "pro"},
+	} {
+		if _, err := newAPIKeyLimiter(cfg, &fakeAPIKeyStore{}); err == nil {
+			t.Fatalf("newAPIKeyLimiter(%+v) succeeded", cfg)
+		}
+	}
+}
+
+func TestFileAPIKeyStoreReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	write := func(id string, modTime time.Time) {
+		content := "keys:\n  - id: " + id + "\n    plan: pro\n    key_sha256: " + hashAPIKey(testAPIKey) + "\n"
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Unix(1_700_000_000, 0)
+	write("first", start)
+
+	store, err := NewFileAPIKeyStore(path)
+	if err != nil {
+		t.Fatalf("NewFileAPIKeyStore: %v", err)
+	}
+	now := start
+	store.now = func() time.Time { return now }
+	store.lastChecked = now
+
+	key, err := store.LookupAPIKey(context.Background(), hashAPIKey(testAPIKey))
+	if err != nil || key == nil || key.ID != "first" || key.Plan != "pro" {
+		t.Fatalf("LookupAPIKey = %+v, %v", key, err)
+	}
+
+	write("second", start.Add(time.Minute))
+	now = now.Add(apiKeyFileReloadCheckInterval)
+	if key, _ := store.LookupAPIKey(context.Background(), hashAPIKey(testAPIKey)); key == nil || key.ID != "second" {
+		t.Fatalf("after reload key = %+v", key)
+	}
+
+	if err := os.WriteFile(path, []byte("keys:\n  - id: bad\n    key_sha256: nothex\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(apiKeyFileReloadCheckInterval)
+	if key, _ := store.LookupAPIKey(context.Background(), hashAPIKey(testAPIKey)); key == nil || key.ID != "second" {
+		t.Fatalf("bad file should keep previous keys, got %+v", key)
+	}
+}
+
+func TestCachedAPIKeyStoreCachesMisses(t *testing.T) {
+	backing := &fakeAPIKeyStore{keys: map[string]*apiKey{hashAPIKey(testAPIKey): {ID: "acme"}}}
+	store := newCachedAPIKeyStore(backing, time.Minute)
+	now := time.Unix(0, 0)
+	store.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		store.LookupAPIKey(context.Background(), hashAPIKey(testAPIKey))
+		store.LookupAPIKey(context.Background(), hashAPIKey("missing"))
+	}
+	if backing.lookups != 2 {
+		t.Fatalf("lookups = %d, want 2", backing.lookups)
+	}
+	now = now.Add(time.Minute)
+	if key, _ := store.LookupAPIKey(context.Background(), hashAPIKey(testAPIKey)); key == nil || backing.lookups != 3 {
+		t.Fatalf("expired lookup key = %+v, lookups = %d", key, backing.lookups)
+	}
+}
@@ -28,12 +28,20 @@ type application struct {
 	contractIndexHandlers *ContractIndexHandlers
 	contractIndexReader   *ContractIndexReader
 	contractArtifacts     ContractArtifactResolver
+	apiKeys               *apiKeyLimiter
+	responseCache         *responseCache
+	complianceArchive     *ArchiveProcessor
 	readerMode            ReaderMode
 }
 
 func (app *application) routes() http.Handler {
 	router := mux.NewRouter()
+	router.Use(routeTemplateMiddleware)
+	if app.responseCache != nil {
+		router.Use(app.responseCache.middleware)
+	}
 
+	router.HandleFunc("/metrics", handleMetrics).Methods("GET")
 	router.HandleFunc("/health", handleHealthWithSilverAndIndexAndContractIndex(
 		app.config.DuckLakeSilver != nil,
 		app.config.Index != nil && app.config.Index.Enabled && app.indexHandlers != nil,
@@ -102,13 +110,21 @@ func (app *application) routes() http.Handler {
 
 	app.registerHorizonCompatRoutes(router)
 
-	return chainMiddleware(
-		router,
+	if app.config.GraphQL != nil && app.config.GraphQL.Enabled {
+		app.registerGraphQLRoutes(router)
+	}
+
+	middlewares := []middleware{
+		metricsMiddleware,
 		recoverPanicMiddleware,
 		requestIDMiddleware,
 		requestLoggingMiddleware,
 		corsMiddleware,
-	)
+	}
+	if app.apiKeys != nil {
+		middlewares = append(middlewares, app.apiKeys.middleware)
+	}
+	return chainMiddleware(router, middlewares...)
 }
 
 func (app *application) serve() error {
@@ -144,6 +160,10 @@ func (app *application) serve() error {
 		log.Printf("Server forced to shutdown: %v", err)
 		return err
 	}
+	if app.complianceArchive != nil {
+		// Interrupted archive jobs stay processing and resume on restart.
+		app.complianceArchive.Stop()
+	}
 
 	log.Println("✅ Server exited gracefully")
 	return nil
@@ -17,7 +17,7 @@ type ColdReader struct {
 
 func NewColdReader(config DuckLakeConfig) (*ColdReader, error) {
 	// Open DuckDB connection (in-memory)
-	db, err := sql.Open("duckdb", "")
+	db, err := openInstrumentedDB("duckdb", "", "bronze_cold", dataTierCold)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open DuckDB: %w", err)
 	}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var errArchiveArtifactNotFound = errors.New("archive artifact not found")
+
+// archiveArtifactStore holds the files of each archive under its ID.
+type archiveArtifactStore interface {
+	PutArtifact(ctx context.Context, archiveID, name string, data []byte) error
+	// GetArtifact returns errArchiveArtifactNotFound for a missing file.
+	GetArtifact(ctx context.Context, archiveID, name string) ([]byte, error)
+	// DeleteArtifacts removes the named files; missing files are ignored.
+	DeleteArtifacts(ctx context.Context, archiveID string, names []string) error
+}
+
+// newArchiveArtifactStore returns nil when no artifact directory is
+// configured, in which case archives keep checksums only.
+func newArchiveArtifactStore(cfg *ComplianceArchiveConfig) (archiveArtifactStore, error) {
+	if cfg == nil || strings.TrimSpace(cfg.ArtifactDirectory) == "" {
+		return nil, nil
+	}
+	directory := strings.TrimSpace(cfg.ArtifactDirectory)
+	if strings.HasPrefix(directory, "s3://") {
+		return newS3ArchiveArtifactStore(directory, cfg.S3)
+	}
+	return NewFileArchiveArtifactStore(directory)
+}
+
+// validArchivePathElement rejects IDs and names that could escape the
+// archive's directory or key prefix.
+func validArchivePathElement(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, `/\`)
+}
+
+func checkArchivePath(archiveID, name string) error {
+	if !validArchivePathElement(archiveID) {
+		return fmt.Errorf("invalid archive id %q", archiveID)
+	}
+	if !validArchivePathElement(name) {
+		return fmt.Errorf("invalid artifact name %q", name)
+	}
+	return nil
+}
+
+// FileArchiveArtifactStore writes artifacts to <directory>/<archive id>/<name>.
+// Files are published atomically, so a crash never leaves a partial artifact
+// behind a name.
+type FileArchiveArtifactStore struct {
+	directory string
+}
+
+func NewFileArchiveArtifactStore(directory string) (*FileArchiveArtifactStore, error) {
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return nil, fmt.Errorf("create compliance archive directory: %w", err)
+	}
+	return &FileArchiveArtifactStore{directory: directory}, nil
+}
+
+func (s *FileArchiveArtifactStore) PutArtifact(ctx context.Context, archiveID, name string, data []byte) error {
+	if err := checkArchivePath(archiveID, name); err != nil {
+		return err
+	}
+	directory := filepath.Join(s.directory, archiveID)
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return fmt.Errorf("create archive directory: %w", err)
+	}
+	return atomicWriteFile(directory, name, data, 0o644)
+}
+
+func (s *FileArchiveArtifactStore) GetArtifact(ctx context.Context, archiveID, name string) ([]byte, error) {
+	if err := checkArchivePath(archiveID, name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(s.directory, archiveID, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errArchiveArtifactNotFound
+	}
+	return data, err
+}
+
+func (s *FileArchiveArtifactStore) DeleteArtifacts(ctx context.Context, archiveID string, names []string) error {
+	for _, name := range names {
+		if err := checkArchivePath(archiveID, name); err != nil {
+			return err
+		}
+		if err := os.Remove(filepath.Join(s.directory, archiveID, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	// Fails harmlessly when a leftover temporary file keeps the directory.
+	os.Remove(filepath.Join(s.directory, archiveID))
+	return nil
+}
+
+// s3ArchiveArtifactStore keeps artifacts in an S3 bucket. Like the Redis
+// response cache it speaks the protocol directly: three object calls signed
+// with Signature Version 4 do not justify the AWS SDK.
+type s3ArchiveArtifactStore struct {
+	client       *http.Client
+	endpoint     *url.URL
+	bucket       string
+	prefix       string
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	pathStyle    bool
+	now          func() time.Time
+}
+
+func newS3ArchiveArtifactStore(location string, cfg *ComplianceArchiveS3Config) (*s3ArchiveArtifactStore, error) {
+	if cfg == nil {
+		cfg = &ComplianceArchiveS3Config{}
+	}
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid compliance_archive.artifact_directory %q: missing bucket", location)
+	}
+	s := &s3ArchiveArtifactStore{
+		client:       &http.Client{Timeout: 5 * time.Minute},
+		bucket:       bucket,
+		prefix:       strings.Trim(prefix, "/"),
+		region:       firstNonEmpty(cfg.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1"),
+		accessKey:    firstNonEmpty(cfg.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID")),
+		secretKey:    firstNonEmpty(cfg.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		sessionToken: firstNonEmpty(cfg.SessionToken, os.Getenv("AWS_SESSION_TOKEN")),
+		pathStyle:    cfg.PathStyle,
+		now:          time.Now,
+	}
+	if s.accessKey == "" || s.secretKey == "" {
+		return nil, errors.New("compliance_archive.s3 credentials are required for an s3:// artifact directory")
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + s.region + ".amazonaws.com"
+	} else if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid compliance_archive.s3.endpoint %q", cfg.Endpoint)
+	}
+	s.endpoint = u
+	return s, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (s *s3ArchiveArtifactStore) objectURL(archiveID, name string) *url.URL {
+	key := archiveID + "/" + name
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = awsURIEscape(u.Path, false)
+	return &u
+}
+
+func (s *s3ArchiveArtifactStore) do(ctx context.Context, method, archiveID, name string, body []byte) (*http.Response, error) {
+	if err := checkArchivePath(archiveID, name); err != nil {
+		return nil, err
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(archiveID, name).String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+	signS3Request(req, hex.EncodeToString(sum[:]), s.accessKey, s.secretKey, s.region, s.now())
+	return s.client.Do(req)
+}
+
+func (s *s3ArchiveArtifactStore) PutArtifact(ctx context.Context, archiveID, name string, data []byte) error {
+	if data == nil {
+		data = []byte{}
+	}
+	resp, err := s.do(ctx, http.MethodPut, archiveID, name, data)
+	if err != nil {
+		return fmt.Errorf("upload archive artifact: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("upload", resp)
+	}
+	return nil
+}
+
+func (s *s3ArchiveArtifactStore) GetArtifact(ctx context.Context, archiveID, name string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, archiveID, name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("download archive artifact: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, errArchiveArtifactNotFound
+	default:
+		return nil, s3Error("download", resp)
+	}
+}
+
+func (s *s3ArchiveArtifactStore) DeleteArtifacts(ctx context.Context, archiveID string, names []string) error {
+	for _, name := range names {
+		resp, err := s.do(ctx, http.MethodDelete, archiveID, name, nil)
+		if err != nil {
+			return fmt.Errorf("delete archive artifact: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+			return s3Error("delete", resp)
+		}
+	}
+	return nil
+}
+
+func s3Error(action string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s archive artifact: s3 returned %s: %s", action, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// signS3Request adds AWS Signature Version 4 headers to req. payloadHash is
+// the hex SHA-256 of the body. Host, Content-Type, Range and every X-Amz-*
+// header are signed.
+func signS3Request(req *http.Request, payloadHash, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			params = append(params, awsURIEscape(k, true)+"="+awsURIEscape(v, true))
+		}
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEscape(req.URL.Path, false),
+		strings.Join(params, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		",SignedHeaders="+signedHeaders+",Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsURIEscape percent-encodes everything except RFC 3986 unreserved
+// characters, keeping '/' in object paths as SigV4 requires for S3.
+func awsURIEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
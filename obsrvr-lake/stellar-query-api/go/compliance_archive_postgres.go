@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const defaultArchiveTablePrefix = "compliance_archive"
+
+// PostgresArchiveStore keeps archive jobs in <prefix>_jobs and lineage in
+// <prefix>_lineage, creating both tables if they do not exist. Lineage rows
+// are never deleted, so the audit trail outlives expired artifacts.
+type PostgresArchiveStore struct {
+	db      *sql.DB
+	jobs    string
+	lineage string
+	now     func() time.Time
+}
+
+const archiveJobColumns = "id, request, status, asset, period, artifacts, checksums, error, created_at, completed_at, callback_deliveries, callback_done"
+
+func NewPostgresArchiveStore(cfg PostgresConfig, prefix string) (*PostgresArchiveStore, error) {
+	if prefix == "" {
+		prefix = defaultArchiveTablePrefix
+	}
+	if !sqlTableNamePattern.MatchString(prefix) {
+		return nil, fmt.Errorf("invalid compliance_archive.table_prefix %q", prefix)
+	}
+	db, err := sql.Open("postgres", cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open compliance archive database: %w", err)
+	}
+	if cfg.MaxConnections > 0 {
+		db.SetMaxOpenConns(cfg.MaxConnections)
+	}
+	s := &PostgresArchiveStore{
+		db:      db,
+		jobs:    prefix + "_jobs",
+		lineage: prefix + "_lineage",
+		now:     time.Now,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.ensureSchema(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	queryAPIMetrics.registerPool("compliance_archive", db)
+	return s, nil
+}
+
+func (s *PostgresArchiveStore) ensureSchema(ctx context.Context) error {
+	// Index names take the table's schema and cannot be qualified.
+	statusIndex := s.jobs[strings.LastIndexByte(s.jobs, '.')+1:] + "_status_idx"
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS ` + s.jobs + ` (
+			id           TEXT PRIMARY KEY,
+			request      JSONB NOT NULL,
+			status       TEXT NOT NULL,
+			asset        JSONB NOT NULL,
+			period       JSONB NOT NULL,
+			artifacts    JSONB NOT NULL DEFAULT '[]',
+			checksums    JSONB NOT NULL DEFAULT '{}',
+			error        TEXT NOT NULL DEFAULT '',
+			created_at   TIMESTAMPTZ NOT NULL,
+			completed_at TIMESTAMPTZ
+		)`,
+		`ALTER TABLE ` + s.jobs + ` ADD COLUMN IF NOT EXISTS callback_deliveries JSONB NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE ` + s.jobs + ` ADD COLUMN IF NOT EXISTS callback_done BOOLEAN NOT NULL DEFAULT false`,
+		`CREATE INDEX IF NOT EXISTS ` + statusIndex + ` ON ` + s.jobs + ` (status, completed_at)`,
+		`CREATE TABLE IF NOT EXISTS ` + s.lineage + ` (
+			archive_id          TEXT PRIMARY KEY,
+			asset_code          TEXT NOT NULL,
+			asset_issuer        TEXT NOT NULL DEFAULT '',
+			period_start        TEXT NOT NULL,
+			period_end          TEXT NOT NULL,
+			artifacts_count     INTEGER NOT NULL,
+			methodology_version TEXT NOT NULL,
+			status              TEXT NOT NULL,
+			checksums           JSONB NOT NULL,
+			generated_at        TIMESTAMPTZ NOT NULL
+		)`,
+	}
+	for _, stmt := range statements {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("create compliance archive tables: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *PostgresArchiveStore) CreateJob(ctx context.Context, req FullArchiveRequest) (*ArchiveJob, error) {
+	job := newArchiveJob(req, s.now())
+	request, err := json.Marshal(job.Request)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := json.Marshal(job.Asset)
+	if err != nil {
+		return nil, err
+	}
+	period, err := json.Marshal(job.Period)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO `+s.jobs+` (id, request, status, asset, period, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		job.ID, string(request), job.Status, string(asset), string(period), job.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert archive job: %w", err)
+	}
+	return job, nil
+}
+
+func (s *PostgresArchiveStore) GetJob(ctx context.Context, id string) (*ArchiveJob, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+archiveJobColumns+` FROM `+s.jobs+` WHERE id = $1`, id)
+	job, err := scanArchiveJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (s *PostgresArchiveStore) ListJobs(ctx context.Context, statuses ...ArchiveStatus) ([]*ArchiveJob, error) {
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = string(status)
+	}
+	return s.queryJobs(ctx,
+		`SELECT `+archiveJobColumns+` FROM `+s.jobs+` WHERE status = ANY($1) ORDER BY created_at`,
+		pq.Array(names))
+}
+
+func (s *PostgresArchiveStore) ListJobsFinishedBefore(ctx context.Context, t time.Time) ([]*ArchiveJob, error) {
+	return s.queryJobs(ctx,
+		`SELECT `+archiveJobColumns+` FROM `+s.jobs+`
+		 WHERE status IN ('complete', 'failed', 'cancelled') AND completed_at < $1 ORDER BY created_at`,
+		t)
+}
+
+func (s *PostgresArchiveStore) queryJobs(ctx context.Context, query string, args ...interface{}) ([]*ArchiveJob, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var jobs []*ArchiveJob
+	for rows.Next() {
+		job, err := scanArchiveJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (s *PostgresArchiveStore) StartJob(ctx context.Context, id string) (bool, error) {
+	return s.update(ctx, id,
+		`UPDATE `+s.jobs+` SET status = 'processing', artifacts = '[]', checksums = '{}', error = ''
+		 WHERE id = $1 AND status IN ('pending', 'processing')`, id)
+}
+
+func (s *PostgresArchiveStore) AddArtifact(ctx context.Context, id string, artifact ArchiveArtifact) error {
+	encoded, err := json.Marshal([]ArchiveArtifact{artifact})
+	if err != nil {
+		return err
+	}
+	_, err = s.update(ctx, id,
+		`UPDATE `+s.jobs+` SET artifacts = artifacts || $2::jsonb,
+		 checksums = checksums || jsonb_build_object($3::text, $4::text) WHERE id = $1`,
+		id, string(encoded), artifact.Type, artifact.Checksum)
+	return err
+}
+
+func (s *PostgresArchiveStore) FinishJob(ctx context.Context, id string, status ArchiveStatus, message string) (bool, error) {
+	return s.update(ctx, id,
+		`UPDATE `+s.jobs+` SET status = $2, error = $3, completed_at = $4
+		 WHERE id = $1 AND status IN ('pending', 'processing')`,
+		id, status, message, s.now().UTC())
+}
+
+func (s *PostgresArchiveStore) DeleteJob(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM `+s.jobs+` WHERE id = $1 AND status = 'pending'`, id)
+	return err
+}
+
+func (s *PostgresArchiveStore) CompleteJob(ctx context.Context, id string) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx,
+		`UPDATE `+s.jobs+` SET status = 'complete', completed_at = $2
+		 WHERE id = $1 AND status = 'processing' RETURNING `+archiveJobColumns,
+		id, s.now().UTC())
+	job, err := scanArchiveJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		if exists, err := archiveJobExists(ctx, tx, s.jobs, id); err != nil || !exists {
+			return false, orNotFound(err)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	entry := newArchiveLineageEntry(job)
+	checksums, err := json.Marshal(entry.Checksums)
+	if err != nil {
+		return false, err
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO `+s.lineage+` (archive_id, asset_code, asset_issuer, period_start, period_end,
+		 artifacts_count, methodology_version, status, checksums, generated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		entry.ArchiveID, entry.AssetCode, entry.AssetIssuer, entry.PeriodStart, entry.PeriodEnd,
+		entry.ArtifactsCount, entry.MethodologyVersion, entry.Status, string(checksums), *job.CompletedAt)
+	if err != nil {
+		return false, fmt.Errorf("insert archive lineage: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *PostgresArchiveStore) ExpireJob(ctx context.Context, id string) error {
+	_, err := s.update(ctx, id,
+		`UPDATE `+s.jobs+` SET status = 'expired'
+		 WHERE id = $1 AND status IN ('complete', 'failed', 'cancelled', 'expired')`, id)
+	return err
+}
+
+func (s *PostgresArchiveStore) GetLineage(ctx context.Context, assetCode, assetIssuer string, limit int) ([]ArchiveLineageEntry, error) {
+	query := `SELECT archive_id, asset_code, asset_issuer, period_start, period_end, artifacts_count,
+		methodology_version, status, checksums, generated_at FROM ` + s.lineage + `
+		WHERE ($1 = '' OR asset_code = $1) AND ($2 = '' OR asset_issuer = $2)
+		ORDER BY generated_at DESC, archive_id`
+	args := []interface{}{assetCode, assetIssuer}
+	if limit > 0 {
+		query += " LIMIT $3"
+		args = append(args, limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []ArchiveLineageEntry
+	for rows.Next() {
+		var entry ArchiveLineageEntry
+		var checksums []byte
+		var generatedAt time.Time
+		if err := rows.Scan(&entry.ArchiveID, &entry.AssetCode, &entry.AssetIssuer, &entry.PeriodStart,
+			&entry.PeriodEnd, &entry.ArtifactsCount, &entry.MethodologyVersion, &entry.Status,
+			&checksums, &generatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(checksums, &entry.Checksums); err != nil {
+			return nil, fmt.Errorf("decode lineage checksums for %s: %w", entry.ArchiveID, err)
+		}
+		entry.GeneratedAt = generatedAt.UTC().Format(time.RFC3339)
+		results = append(results, entry)
+	}
+	return results, rows.Err()
+}
+
+func (s *PostgresArchiveStore) ListPendingCallbacks(ctx context.Context) ([]*ArchiveJob, error) {
+	return s.queryJobs(ctx,
+		`SELECT `+archiveJobColumns+` FROM `+s.jobs+`
+		 WHERE status IN ('complete', 'failed', 'cancelled') AND NOT callback_done
+		 AND request->>'callback_url' <> '' ORDER BY created_at`)
+}
+
+func (s *PostgresArchiveStore) RecordCallbackDelivery(ctx context.Context, id string, delivery CallbackDelivery, done bool) error {
+	encoded, err := json.Marshal([]CallbackDelivery{delivery})
+	if err != nil {
+		return err
+	}
+	_, err = s.update(ctx, id,
+		`UPDATE `+s.jobs+` SET callback_deliveries = callback_deliveries || $2::jsonb, callback_done = $3 WHERE id = $1`,
+		id, string(encoded), done)
+	return err
+}
+
+func (s *PostgresArchiveStore) Close() error {
+	return s.db.Close()
+}
+
+// update runs a conditional UPDATE keyed by id and reports whether a row
+// changed, telling a missing job apart from one whose status did not match.
+func (s *PostgresArchiveStore) update(ctx context.Context, id, query string, args ...interface{}) (bool, error) {
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return true, nil
+	}
+	exists, err := archiveJobExists(ctx, s.db, s.jobs, id)
+	if err != nil || !exists {
+		return false, orNotFound(err)
+	}
+	return false, nil
+}
+
+func archiveJobExists(ctx context.Context, q interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, table, id string) (bool, error) {
+	var exists bool
+	err := q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id).Scan(&exists)
+	return exists, err
+}
+
+func orNotFound(err error) error {
+	if err != nil {
+		return err
+	}
+	return errArchiveJobNotFound
+}
+
+type archiveJobScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanArchiveJob(row archiveJobScanner) (*ArchiveJob, error) {
+	var job ArchiveJob
+	var status string
+	var request, asset, period, artifacts, checksums, deliveries []byte
+	var completedAt sql.NullTime
+	if err := row.Scan(&job.ID, &request, &status, &asset, &period, &artifacts, &checksums,
+		&job.Error, &job.CreatedAt, &completedAt, &deliveries, &job.CallbackDone); err != nil {
+		return nil, err
+	}
+	job.Status = ArchiveStatus(status)
+	for _, field := range []struct {
+		name string
+		data []byte
+		dest interface{}
+	}{
+		{"request", request, &job.Request},
+		{"asset", asset, &job.Asset},
+		{"period", period, &job.Period},
+		{"artifacts", artifacts, &job.Artifacts},
+		{"checksums", checksums, &job.Checksums},
+		{"callback_deliveries", deliveries, &job.CallbackDeliveries},
+	} {
+		if err := json.Unmarshal(field.data, field.dest); err != nil {
+			return nil, fmt.Errorf("decode archive job %s %s: %w", job.ID, field.name, err)
+		}
+	}
+	job.CreatedAt = job.CreatedAt.UTC()
+	if completedAt.Valid {
+		t := completedAt.Time.UTC()
+		job.CompletedAt = &t
+	}
+	return &job, nil
+}
@@ -2,50 +2,85 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"errors"
+	"sort"
 	"sync"
 	"time"
 )
 
 // ============================================
-// COMPLIANCE ARCHIVE STORE - In-Memory Job Tracking
+// COMPLIANCE ARCHIVE STORE - Job and Lineage Tracking
 // ============================================
 
 // ArchiveJob represents an in-progress or completed archive job
 type ArchiveJob struct {
-	ID              string
-	Request         FullArchiveRequest
-	Status          ArchiveStatus
-	Asset           AssetInfo
-	Period          PeriodInfo
-	Artifacts       []ArchiveArtifact
-	Checksums       map[string]string
-	Error           string
-	CreatedAt       time.Time
-	CompletedAt     *time.Time
+	ID          string
+	Request     FullArchiveRequest
+	Status      ArchiveStatus
+	Asset       AssetInfo
+	Period      PeriodInfo
+	Artifacts   []ArchiveArtifact
+	Checksums   map[string]string
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	// CallbackDeliveries logs every callback attempt; CallbackDone is set
+	// once one succeeds or the attempts run out.
+	CallbackDeliveries []CallbackDelivery
+	CallbackDone       bool
 }
 
-// ArchiveStore manages archive jobs in memory
-type ArchiveStore struct {
-	mu      sync.RWMutex
-	jobs    map[string]*ArchiveJob
-	lineage []ArchiveLineageEntry // ordered list for lineage queries
+// finished reports whether the job reached a terminal status.
+func (j *ArchiveJob) finished() bool {
+	switch j.Status {
+	case ArchiveStatusPending, ArchiveStatusProcessing:
+		return false
+	}
+	return true
 }
 
-// NewArchiveStore creates a new archive store
-func NewArchiveStore() *ArchiveStore {
-	return &ArchiveStore{
-		jobs:    make(map[string]*ArchiveJob),
-		lineage: make([]ArchiveLineageEntry, 0),
-	}
+// ArchiveStore keeps archive jobs and the lineage of completed archives.
+// Status changes are conditional so a cancellation can never be overwritten
+// by a worker that is still finishing: each transition reports whether it
+// applied. GetJob returns nil and no error for an unknown job, and every job
+// returned is a copy.
+type ArchiveStore interface {
+	CreateJob(ctx context.Context, req FullArchiveRequest) (*ArchiveJob, error)
+	GetJob(ctx context.Context, id string) (*ArchiveJob, error)
+	// ListJobs returns jobs in any of the given statuses, oldest first.
+	ListJobs(ctx context.Context, statuses ...ArchiveStatus) ([]*ArchiveJob, error)
+	// ListJobsFinishedBefore returns complete, failed and cancelled jobs
+	// that finished before t.
+	ListJobsFinishedBefore(ctx context.Context, t time.Time) ([]*ArchiveJob, error)
+	// StartJob moves a pending or interrupted job to processing and drops
+	// the artifacts of any earlier attempt.
+	StartJob(ctx context.Context, id string) (bool, error)
+	AddArtifact(ctx context.Context, id string, artifact ArchiveArtifact) error
+	// FinishJob moves a pending or processing job to failed or cancelled.
+	FinishJob(ctx context.Context, id string, status ArchiveStatus, message string) (bool, error)
+	// DeleteJob removes a pending job that never reached a worker, such as
+	// one the queue turned away. Jobs in any other status are kept.
+	DeleteJob(ctx context.Context, id string) error
+	// CompleteJob marks a processing job complete and records its lineage.
+	CompleteJob(ctx context.Context, id string) (bool, error)
+	// ExpireJob marks a finished job expired once its artifacts are gone.
+	ExpireJob(ctx context.Context, id string) error
+	GetLineage(ctx context.Context, assetCode, assetIssuer string, limit int) ([]ArchiveLineageEntry, error)
+	// ListPendingCallbacks returns finished, unexpired jobs with a callback
+	// URL whose delivery is not done.
+	ListPendingCallbacks(ctx context.Context) ([]*ArchiveJob, error)
+	RecordCallbackDelivery(ctx context.Context, id string, delivery CallbackDelivery, done bool) error
 }
 
-// CreateJob creates a new archive job and returns its ID
-func (s *ArchiveStore) CreateJob(req FullArchiveRequest) (*ArchiveJob, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func newArchiveStore(cfg *ComplianceArchiveConfig) (ArchiveStore, error) {
+	if cfg == nil || cfg.Postgres == nil {
+		return NewMemoryArchiveStore(), nil
+	}
+	return NewPostgresArchiveStore(*cfg.Postgres, cfg.TablePrefix)
+}
 
+// newArchiveJob builds the pending job for a validated request.
+func newArchiveJob(req FullArchiveRequest, now time.Time) *ArchiveJob {
 	// Build asset info
 	asset := AssetInfo{
 		Code: req.AssetCode,
@@ -58,7 +93,8 @@ func (s *ArchiveStore) CreateJob(req FullArchiveRequest) (*ArchiveJob, error) {
 			asset.Type = "credit_alphanum12"
 		}
 		if req.AssetIssuer != "" {
-			asset.Issuer = &req.AssetIssuer
+			issuer := req.AssetIssuer
+			asset.Issuer = &issuer
 		}
 	}
 
@@ -71,99 +107,222 @@ func (s *ArchiveStore) CreateJob(req FullArchiveRequest) (*ArchiveJob, error) {
 		End:   endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second).Format(time.RFC3339),
 	}
 
-	// Generate archive ID
-	archiveID := GenerateArchiveID("archive", req.AssetCode, time.Now())
-
-	job := &ArchiveJob{
-		ID:        archiveID,
+	return &ArchiveJob{
+		ID:        GenerateArchiveID("archive", req.AssetCode, now),
 		Request:   req,
 		Status:    ArchiveStatusPending,
 		Asset:     asset,
 		Period:    period,
 		Artifacts: make([]ArchiveArtifact, 0),
 		Checksums: make(map[string]string),
-		CreatedAt: time.Now().UTC(),
+		CreatedAt: now.UTC(),
+	}
+}
+
+// newArchiveLineageEntry describes a job that has just completed.
+func newArchiveLineageEntry(job *ArchiveJob) ArchiveLineageEntry {
+	issuer := ""
+	if job.Asset.Issuer != nil {
+		issuer = *job.Asset.Issuer
 	}
+	checksums := make(map[string]string, len(job.Checksums))
+	for k, v := range job.Checksums {
+		checksums[k] = v
+	}
+	return ArchiveLineageEntry{
+		ArchiveID:          job.ID,
+		AssetCode:          job.Asset.Code,
+		AssetIssuer:        issuer,
+		PeriodStart:        job.Period.Start,
+		PeriodEnd:          job.Period.End,
+		ArtifactsCount:     len(job.Artifacts),
+		MethodologyVersion: MethodologyArchiveV1,
+		Status:             string(ArchiveStatusComplete),
+		Checksums:          checksums,
+		GeneratedAt:        job.CompletedAt.Format(time.RFC3339),
+	}
+}
+
+func cloneArchiveJob(job *ArchiveJob) *ArchiveJob {
+	c := *job
+	c.Request.Include = append([]string(nil), job.Request.Include...)
+	c.Request.BalanceSnapshots = append([]string(nil), job.Request.BalanceSnapshots...)
+	if job.Asset.Issuer != nil {
+		issuer := *job.Asset.Issuer
+		c.Asset.Issuer = &issuer
+	}
+	c.Artifacts = append(make([]ArchiveArtifact, 0, len(job.Artifacts)), job.Artifacts...)
+	c.Checksums = make(map[string]string, len(job.Checksums))
+	for k, v := range job.Checksums {
+		c.Checksums[k] = v
+	}
+	if job.CompletedAt != nil {
+		completedAt := *job.CompletedAt
+		c.CompletedAt = &completedAt
+	}
+	c.CallbackDeliveries = append([]CallbackDelivery(nil), job.CallbackDeliveries...)
+	return &c
+}
+
+var errArchiveJobNotFound = errors.New("archive job not found")
+
+// MemoryArchiveStore keeps archive jobs in memory; everything is lost on
+// restart. It is used when compliance_archive.postgres is not configured.
+type MemoryArchiveStore struct {
+	mu      sync.RWMutex
+	jobs    map[string]*ArchiveJob
+	lineage []ArchiveLineageEntry // ordered list for lineage queries
+	now     func() time.Time
+}
+
+// NewMemoryArchiveStore creates a new in-memory archive store
+func NewMemoryArchiveStore() *MemoryArchiveStore {
+	return &MemoryArchiveStore{
+		jobs:    make(map[string]*ArchiveJob),
+		lineage: make([]ArchiveLineageEntry, 0),
+		now:     time.Now,
+	}
+}
 
-	s.jobs[archiveID] = job
-	return job, nil
+func (s *MemoryArchiveStore) CreateJob(ctx context.Context, req FullArchiveRequest) (*ArchiveJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job := newArchiveJob(req, s.now())
+	s.jobs[job.ID] = job
+	return cloneArchiveJob(job), nil
 }
 
-// GetJob retrieves a job by ID
-func (s *ArchiveStore) GetJob(id string) (*ArchiveJob, bool) {
+func (s *MemoryArchiveStore) GetJob(ctx context.Context, id string) (*ArchiveJob, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	job, ok := s.jobs[id]
-	return job, ok
+	if !ok {
+		return nil, nil
+	}
+	return cloneArchiveJob(job), nil
 }
 
-// UpdateJobStatus updates the status of a job
-func (s *ArchiveStore) UpdateJobStatus(id string, status ArchiveStatus) {
+func (s *MemoryArchiveStore) ListJobs(ctx context.Context, statuses ...ArchiveStatus) ([]*ArchiveJob, error) {
+	return s.list(func(job *ArchiveJob) bool {
+		for _, status := range statuses {
+			if job.Status == status {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (s *MemoryArchiveStore) ListJobsFinishedBefore(ctx context.Context, t time.Time) ([]*ArchiveJob, error) {
+	return s.list(func(job *ArchiveJob) bool {
+		return job.finished() && job.Status != ArchiveStatusExpired &&
+			job.CompletedAt != nil && job.CompletedAt.Before(t)
+	}), nil
+}
+
+func (s *MemoryArchiveStore) list(match func(*ArchiveJob) bool) []*ArchiveJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var jobs []*ArchiveJob
+	for _, job := range s.jobs {
+		if match(job) {
+			jobs = append(jobs, cloneArchiveJob(job))
+		}
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].CreatedAt.Before(jobs[k].CreatedAt) })
+	return jobs
+}
+
+func (s *MemoryArchiveStore) StartJob(ctx context.Context, id string) (bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if job, ok := s.jobs[id]; ok {
-		job.Status = status
-		if status == ArchiveStatusComplete || status == ArchiveStatusFailed {
-			now := time.Now().UTC()
-			job.CompletedAt = &now
-		}
+	job, ok := s.jobs[id]
+	if !ok {
+		return false, errArchiveJobNotFound
 	}
+	if job.finished() {
+		return false, nil
+	}
+	job.Status = ArchiveStatusProcessing
+	job.Artifacts = make([]ArchiveArtifact, 0)
+	job.Checksums = make(map[string]string)
+	job.Error = ""
+	return true, nil
 }
 
-// AddArtifact adds an artifact to a job
-func (s *ArchiveStore) AddArtifact(id string, artifact ArchiveArtifact) {
+func (s *MemoryArchiveStore) AddArtifact(ctx context.Context, id string, artifact ArchiveArtifact) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if job, ok := s.jobs[id]; ok {
-		job.Artifacts = append(job.Artifacts, artifact)
-		job.Checksums[artifact.Type] = artifact.Checksum
+	job, ok := s.jobs[id]
+	if !ok {
+		return errArchiveJobNotFound
 	}
+	job.Artifacts = append(job.Artifacts, artifact)
+	job.Checksums[artifact.Type] = artifact.Checksum
+	return nil
 }
 
-// SetJobError sets an error on a job
-func (s *ArchiveStore) SetJobError(id string, err error) {
+func (s *MemoryArchiveStore) FinishJob(ctx context.Context, id string, status ArchiveStatus, message string) (bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if job, ok := s.jobs[id]; ok {
-		job.Error = err.Error()
-		job.Status = ArchiveStatusFailed
-		now := time.Now().UTC()
-		job.CompletedAt = &now
+	job, ok := s.jobs[id]
+	if !ok {
+		return false, errArchiveJobNotFound
 	}
+	if job.finished() {
+		return false, nil
+	}
+	now := s.now().UTC()
+	job.Status = status
+	job.Error = message
+	job.CompletedAt = &now
+	return true, nil
 }
 
-// CompleteJob marks a job as complete and adds to lineage
-func (s *ArchiveStore) CompleteJob(id string) {
+func (s *MemoryArchiveStore) DeleteJob(ctx context.Context, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if job, ok := s.jobs[id]; ok {
-		job.Status = ArchiveStatusComplete
-		now := time.Now().UTC()
-		job.CompletedAt = &now
+	job, ok := s.jobs[id]
+	if !ok {
+		return errArchiveJobNotFound
+	}
+	if job.Status == ArchiveStatusPending {
+		delete(s.jobs, id)
+	}
+	return nil
+}
 
-		// Add to lineage
-		issuer := ""
-		if job.Asset.Issuer != nil {
-			issuer = *job.Asset.Issuer
-		}
-		entry := ArchiveLineageEntry{
-			ArchiveID:          job.ID,
-			AssetCode:          job.Asset.Code,
-			AssetIssuer:        issuer,
-			PeriodStart:        job.Period.Start,
-			PeriodEnd:          job.Period.End,
-			ArtifactsCount:     len(job.Artifacts),
-			MethodologyVersion: MethodologyArchiveV1,
-			Status:             string(job.Status),
-			Checksums:          job.Checksums,
-			GeneratedAt:        job.CompletedAt.Format(time.RFC3339),
-		}
-		s.lineage = append([]ArchiveLineageEntry{entry}, s.lineage...) // prepend for recent-first
+func (s *MemoryArchiveStore) CompleteJob(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return false, errArchiveJobNotFound
 	}
+	if job.Status != ArchiveStatusProcessing {
+		return false, nil
+	}
+	now := s.now().UTC()
+	job.Status = ArchiveStatusComplete
+	job.CompletedAt = &now
+	s.lineage = append([]ArchiveLineageEntry{newArchiveLineageEntry(job)}, s.lineage...) // prepend for recent-first
+	return true, nil
 }
 
-// GetLineage returns archive lineage entries with optional filtering
-func (s *ArchiveStore) GetLineage(assetCode, assetIssuer string, limit int) []ArchiveLineageEntry {
+func (s *MemoryArchiveStore) ExpireJob(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return errArchiveJobNotFound
+	}
+	if job.finished() {
+		job.Status = ArchiveStatusExpired
+	}
+	return nil
+}
+
+func (s *MemoryArchiveStore) GetLineage(ctx context.Context, assetCode, assetIssuer string, limit int) ([]ArchiveLineageEntry, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -181,269 +340,24 @@ func (s *ArchiveStore) GetLineage(assetCode, assetIssuer string, limit int) []Ar
 			break
 		}
 	}
-	return results
+	return results, nil
 }
 
-// ============================================
-// ARCHIVE JOB PROCESSOR
-// ============================================
-
-// ArchiveProcessor handles async archive generation
-type ArchiveProcessor struct {
-	store  *ArchiveStore
-	reader *UnifiedSilverReader
+func (s *MemoryArchiveStore) ListPendingCallbacks(ctx context.Context) ([]*ArchiveJob, error) {
+	return s.list(func(job *ArchiveJob) bool {
+		return job.finished() && job.Status != ArchiveStatusExpired &&
+			job.Request.CallbackURL != "" && !job.CallbackDone
+	}), nil
 }
 
-// NewArchiveProcessor creates a new archive processor
-func NewArchiveProcessor(store *ArchiveStore, reader *UnifiedSilverReader) *ArchiveProcessor {
-	return &ArchiveProcessor{
-		store:  store,
-		reader: reader,
-	}
-}
-
-// ProcessJob processes an archive job in the background
-func (p *ArchiveProcessor) ProcessJob(ctx context.Context, jobID string) {
-	job, ok := p.store.GetJob(jobID)
+func (s *MemoryArchiveStore) RecordCallbackDelivery(ctx context.Context, id string, delivery CallbackDelivery, done bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
 	if !ok {
-		log.Printf("Archive job %s not found", jobID)
-		return
-	}
-
-	log.Printf("Starting archive job %s for asset %s", jobID, job.Asset.Code)
-	p.store.UpdateJobStatus(jobID, ArchiveStatusProcessing)
-
-	req := job.Request
-
-	// Parse dates
-	startDate, err := time.Parse("2006-01-02", req.StartDate)
-	if err != nil {
-		p.store.SetJobError(jobID, fmt.Errorf("invalid start_date: %w", err))
-		return
-	}
-	endDate, err := time.Parse("2006-01-02", req.EndDate)
-	if err != nil {
-		p.store.SetJobError(jobID, fmt.Errorf("invalid end_date: %w", err))
-		return
-	}
-	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
-
-	// Determine what to include
-	includeTransactions := contains(req.Include, "transactions")
-	includeBalances := contains(req.Include, "balances")
-	includeSupply := contains(req.Include, "supply")
-
-	// Default to all if none specified
-	if !includeTransactions && !includeBalances && !includeSupply {
-		includeTransactions = true
-		includeBalances = true
-		includeSupply = true
-	}
-
-	// Generate transactions artifact
-	if includeTransactions {
-		log.Printf("Archive %s: generating transactions artifact", jobID)
-
-		// Paginate through ALL transactions for complete compliance archive
-		var allTransactions []ComplianceTransaction
-		const txBatchSize = 10000
-		offset := 0
-
-		for {
-			txResp, err := p.reader.GetAssetTransactionsWithOffset(ctx, req.AssetCode, req.AssetIssuer, startDate, endDate, false, txBatchSize, offset)
-			if err != nil {
-				p.store.SetJobError(jobID, fmt.Errorf("failed to get transactions: %w", err))
-				return
-			}
-
-			allTransactions = append(allTransactions, txResp.Transactions...)
-
-			// If we got fewer than batch size, we've fetched all records
-			if len(txResp.Transactions) < txBatchSize {
-				break
-			}
-
-			offset += txBatchSize
-			log.Printf("Archive %s: fetched %d transactions so far...", jobID, len(allTransactions))
-
-			// Safety limit to prevent runaway queries (1 million transactions max)
-			if len(allTransactions) >= 1000000 {
-				log.Printf("Archive %s: WARNING - reached 1M transaction limit, archive may be incomplete", jobID)
-				break
-			}
-		}
-
-		// Generate checksum for complete transaction set
-		txChecksum, _ := GenerateTransactionsChecksum(
-			AssetInfo{Code: req.AssetCode},
-			PeriodInfo{Start: startDate.Format(time.RFC3339), End: endDate.Format(time.RFC3339)},
-			allTransactions,
-		)
-
-		artifact := ArchiveArtifact{
-			Name:        "transactions.json",
-			Type:        "transactions",
-			RowCount:    len(allTransactions),
-			Checksum:    txChecksum,
-			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
-		}
-		p.store.AddArtifact(jobID, artifact)
-		log.Printf("Archive %s: transactions artifact complete with %d records", jobID, len(allTransactions))
-	}
-
-	// Generate balance snapshot artifacts
-	if includeBalances {
-		snapshots := req.BalanceSnapshots
-		if len(snapshots) == 0 {
-			// Default to end date
-			snapshots = []string{req.EndDate}
-		}
-
-		for _, snapDate := range snapshots {
-			log.Printf("Archive %s: generating balance snapshot for %s", jobID, snapDate)
-			snapTime, err := time.Parse("2006-01-02", snapDate)
-			if err != nil {
-				continue
-			}
-			snapTime = snapTime.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
-
-			// Paginate through ALL holders for complete compliance archive
-			var allHolders []ComplianceHolder
-			const balBatchSize = 10000
-			balOffset := 0
-
-			for {
-				holders, err := p.reader.GetComplianceBalancesWithOffset(ctx, req.AssetCode, req.AssetIssuer, snapTime, "", balBatchSize, balOffset)
-				if err != nil {
-					log.Printf("Archive %s: failed to get balances for %s: %v", jobID, snapDate, err)
-					break
-				}
-
-				allHolders = append(allHolders, holders...)
-
-				// If we got fewer than batch size, we've fetched all records
-				if len(holders) < balBatchSize {
-					break
-				}
-
-				balOffset += balBatchSize
-				log.Printf("Archive %s: fetched %d holders so far for %s...", jobID, len(allHolders), snapDate)
-
-				// Safety limit to prevent runaway queries (10 million holders max)
-				if len(allHolders) >= 10000000 {
-					log.Printf("Archive %s: WARNING - reached 10M holder limit for %s, archive may be incomplete", jobID, snapDate)
-					break
-				}
-			}
-
-			// Generate checksum for complete holder set
-			balChecksum, _ := GenerateBalancesChecksum(
-				AssetInfo{Code: req.AssetCode},
-				snapTime.Format(time.RFC3339),
-				allHolders,
-			)
-
-			artifact := ArchiveArtifact{
-				Name:        fmt.Sprintf("balances_%s.json", snapDate),
-				Type:        "balance_snapshot",
-				SnapshotAt:  snapTime.Format(time.RFC3339),
-				RowCount:    len(allHolders),
-				Checksum:    balChecksum,
-				GeneratedAt: time.Now().UTC().Format(time.RFC3339),
-			}
-			p.store.AddArtifact(jobID, artifact)
-			log.Printf("Archive %s: balance snapshot for %s complete with %d holders", jobID, snapDate, len(allHolders))
-		}
-	}
-
-	// Generate supply timeline artifact
-	if includeSupply {
-		log.Printf("Archive %s: generating supply timeline artifact", jobID)
-		supplyResp, err := p.reader.GetSupplyTimeline(ctx, req.AssetCode, req.AssetIssuer, startDate, endDate, "1d")
-		if err != nil {
-			p.store.SetJobError(jobID, fmt.Errorf("failed to get supply timeline: %w", err))
-			return
-		}
-		artifact := ArchiveArtifact{
-			Name:        "supply_timeline.json",
-			Type:        "supply_timeline",
-			RowCount:    len(supplyResp.Timeline),
-			Checksum:    supplyResp.Checksum,
-			GeneratedAt: supplyResp.GeneratedAt,
-		}
-		p.store.AddArtifact(jobID, artifact)
-	}
-
-	// Generate methodology document
-	log.Printf("Archive %s: generating methodology document", jobID)
-	job, _ = p.store.GetJob(jobID) // Refresh to get all artifacts
-	methodologyDoc := MethodologyDoc{
-		ArchiveID:          job.ID,
-		AssetCode:          job.Asset.Code,
-		AssetType:          job.Asset.Type,
-		PeriodStart:        job.Period.Start,
-		PeriodEnd:          job.Period.End,
-		GeneratedAt:        time.Now().UTC().Format(time.RFC3339),
-		MethodologyVersion: MethodologyArchiveV1,
-	}
-	if job.Asset.Issuer != nil {
-		methodologyDoc.AssetIssuer = *job.Asset.Issuer
-	}
-
-	// Add artifact info for methodology
-	for _, a := range job.Artifacts {
-		methodologyDoc.Artifacts = append(methodologyDoc.Artifacts, ArtifactInfo{
-			Name:     a.Name,
-			Type:     a.Type,
-			Checksum: a.Checksum,
-			RowCount: a.RowCount,
-		})
-	}
-
-	methodologyContent := GenerateMethodologyDocument(methodologyDoc)
-	methodologyChecksum, _ := GenerateChecksum(methodologyContent)
-
-	methodologyArtifact := ArchiveArtifact{
-		Name:        "methodology.md",
-		Type:        "documentation",
-		Checksum:    methodologyChecksum,
-		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
-	}
-	p.store.AddArtifact(jobID, methodologyArtifact)
-
-	// Generate manifest
-	log.Printf("Archive %s: generating manifest", jobID)
-	job, _ = p.store.GetJob(jobID) // Refresh again to include methodology
-	now := time.Now().UTC()
-	job.CompletedAt = &now // Set completion time for manifest
-
-	manifest, err := GenerateManifest(job)
-	if err != nil {
-		log.Printf("Archive %s: failed to generate manifest: %v", jobID, err)
-	} else {
-		manifestJSON, _ := GenerateManifestJSON(manifest)
-		manifestChecksum, _ := GenerateChecksum(string(manifestJSON))
-
-		manifestArtifact := ArchiveArtifact{
-			Name:        "manifest.json",
-			Type:        "manifest",
-			Checksum:    manifestChecksum,
-			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
-		}
-		p.store.AddArtifact(jobID, manifestArtifact)
-	}
-
-	// Complete the job
-	p.store.CompleteJob(jobID)
-	log.Printf("Archive job %s completed successfully", jobID)
-}
-
-// Helper function
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
+		return errArchiveJobNotFound
 	}
-	return false
+	job.CallbackDeliveries = append(job.CallbackDeliveries, delivery)
+	job.CallbackDone = done
+	return nil
 }
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ============================================
+// ARCHIVE CALLBACKS - Signed Webhook Delivery
+// ============================================
+
+const (
+	defaultArchiveWebhookAttempts   = 8
+	defaultArchiveWebhookBackoff    = 10 * time.Second
+	defaultArchiveWebhookMaxBackoff = time.Hour
+	defaultArchiveWebhookTimeout    = 10 * time.Second
+
+	archiveSignatureHeader = "X-Archive-Signature"
+	archiveEventHeader     = "X-Archive-Event"
+	archiveDeliveryHeader  = "X-Archive-Delivery"
+)
+
+// archiveCallbackEvents maps a finished job status to its callback event.
+var archiveCallbackEvents = map[ArchiveStatus]string{
+	ArchiveStatusComplete:  "archive.completed",
+	ArchiveStatusFailed:    "archive.failed",
+	ArchiveStatusCancelled: "archive.cancelled",
+}
+
+// archiveWebhook POSTs signed callbacks. The signature header is
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">", so receivers can
+// reject replays by checking t.
+type archiveWebhook struct {
+	client         *http.Client
+	secret         []byte
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	allowHTTP      bool
+	allowPrivate   bool
+}
+
+// newArchiveWebhook returns nil when callbacks are not configured.
+func newArchiveWebhook(cfg *ComplianceArchiveWebhookConfig) *archiveWebhook {
+	if cfg == nil || cfg.Secret == "" {
+		return nil
+	}
+	w := &archiveWebhook{
+		secret:         []byte(cfg.Secret),
+		maxAttempts:    defaultArchiveWebhookAttempts,
+		initialBackoff: defaultArchiveWebhookBackoff,
+		maxBackoff:     defaultArchiveWebhookMaxBackoff,
+		allowHTTP:      cfg.AllowHTTP,
+		allowPrivate:   cfg.AllowPrivateNetworks,
+	}
+	timeout := defaultArchiveWebhookTimeout
+	if cfg.MaxAttempts > 0 {
+		w.maxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialBackoffSeconds > 0 {
+		w.initialBackoff = time.Duration(cfg.InitialBackoffSeconds) * time.Second
+	}
+	if cfg.MaxBackoffSeconds > 0 {
+		w.maxBackoff = time.Duration(cfg.MaxBackoffSeconds) * time.Second
+	}
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	if !w.allowPrivate {
+		// Checked on the resolved address of every connection, so a host
+		// that passed validateURL cannot be rebound to an internal one.
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			return checkCallbackAddr(address)
+		}
+	}
+	w.client = &http.Client{
+		Timeout: timeout,
+		// No proxy: the dialer must see the receiver's address, not the
+		// proxy's.
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// A redirect would resend the signed body somewhere the caller
+		// did not ask for.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return w
+}
+
+// validateURL checks a callback URL when the archive is requested.
+func (w *archiveWebhook) validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return errors.New("callback_url must be an absolute URL")
+	}
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && w.allowHTTP:
+	default:
+		return errors.New("callback_url must use https")
+	}
+	if u.User != nil {
+		return errors.New("callback_url must not contain credentials")
+	}
+	if !w.allowPrivate {
+		host := u.Hostname()
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return errors.New("callback_url must not point to a private address")
+		}
+		if ip, err := netip.ParseAddr(host); err == nil && !publicCallbackIP(ip) {
+			return errors.New("callback_url must not point to a private address")
+		}
+	}
+	return nil
+}
+
+// nonPublicPrefixes are ranges publicCallbackIP rejects beyond what netip
+// classifies: "this network", carrier-grade NAT, benchmarking and the
+// reserved block.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// publicCallbackIP reports whether a callback may be delivered to ip:
+// loopback, RFC 1918 and ULA, link-local (including the 169.254.169.254
+// metadata service), multicast and unspecified addresses are refused.
+func publicCallbackIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkCallbackAddr rejects a dial to a non-public "ip:port".
+func checkCallbackAddr(address string) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("callback address %q: %w", address, err)
+	}
+	if !publicCallbackIP(ap.Addr()) {
+		return fmt.Errorf("callback address %s is not public", ap.Addr())
+	}
+	return nil
+}
+
+// backoff is the wait before the given attempt (attempt 2 waits the initial
+// backoff, each later attempt twice as long), capped at maxBackoff.
+func (w *archiveWebhook) backoff(attempt int) time.Duration {
+	d := w.initialBackoff
+	for i := 2; i < attempt && d < w.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > w.maxBackoff {
+		d = w.maxBackoff
+	}
+	return d
+}
+
+// sign returns the signature header value for body at t.
+func (w *archiveWebhook) sign(body []byte, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, w.secret)
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// post makes one delivery attempt and returns the response status; any
+// non-2xx status is an error.
+func (w *archiveWebhook) post(ctx context.Context, target, archiveID, event string, body []byte, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "obsrvr-stellar-query-api")
+	req.Header.Set(archiveEventHeader, event)
+	req.Header.Set(archiveDeliveryHeader, archiveID+":"+event)
+	req.Header.Set(archiveSignatureHeader, w.sign(body, now))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// newArchiveCallbackPayload describes a finished job. The manifest lists
+// whatever artifacts were written, so a failed job's payload shows how far
+// it got.
+func newArchiveCallbackPayload(job *ArchiveJob) (*ArchiveCallbackPayload, error) {
+	event, ok := archiveCallbackEvents[job.Status]
+	if !ok {
+		return nil, fmt.Errorf("archive job %s has no callback for status %s", job.ID, job.Status)
+	}
+	payload := &ArchiveCallbackPayload{
+		Event:     event,
+		ArchiveID: job.ID,
+		Status:    string(job.Status),
+		Error:     job.Error,
+		StatusURL: "/api/v1/gold/compliance/archive/" + job.ID,
+	}
+	if job.CompletedAt != nil {
+		manifest, err := GenerateManifest(job)
+		if err != nil {
+			return nil, err
+		}
+		payload.Manifest = manifest
+	}
+	return payload, nil
+}
+
+// deliverCallback sends the callback of a finished job, retrying until the
+// receiver accepts it, the attempts run out or ctx is cancelled. Every
+// attempt is recorded on the job, so a restart resumes the schedule where
+// it stopped.
+func (p *ArchiveProcessor) deliverCallback(ctx context.Context, id string) {
+	job, err := p.store.GetJob(ctx, id)
+	if err != nil || job == nil {
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Archive job %s: failed to load for callback: %v", id, err)
+		}
+		return
+	}
+	if job.Request.CallbackURL == "" || job.CallbackDone || !job.finished() {
+		return
+	}
+	payload, err := newArchiveCallbackPayload(job)
+	if err != nil {
+		log.Printf("Archive job %s: cannot build callback: %v", id, err)
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Archive job %s: cannot encode callback: %v", id, err)
+		return
+	}
+
+	attempt := len(job.CallbackDeliveries) + 1
+	var last time.Time
+	if n := len(job.CallbackDeliveries); n > 0 {
+		last, _ = time.Parse(time.RFC3339Nano, job.CallbackDeliveries[n-1].AttemptedAt)
+	}
+
+	for ; attempt <= p.webhook.maxAttempts; attempt++ {
+		if !last.IsZero() {
+			wait := p.webhook.backoff(attempt) - p.now().Sub(last)
+			if wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+		}
+
+		start := p.now()
+		status, err := p.webhook.post(ctx, job.Request.CallbackURL, id, payload.Event, body, start)
+		if err != nil && ctx.Err() != nil {
+			// Interrupted by shutdown; the attempt is retried on restart.
+			return
+		}
+		delivery := CallbackDelivery{
+			Attempt:     attempt,
+			Event:       payload.Event,
+			AttemptedAt: start.UTC().Format(time.RFC3339Nano),
+			StatusCode:  status,
+			DurationMS:  p.now().Sub(start).Milliseconds(),
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		done := err == nil || attempt == p.webhook.maxAttempts
+		p.recordCallback(id, delivery, done)
+
+		switch {
+		case err == nil:
+			log.Printf("Archive job %s: %s callback delivered (attempt %d)", id, payload.Event, attempt)
+			return
+		case done:
+			log.Printf("Archive job %s: giving up on %s callback after %d attempts: %v", id, payload.Event, attempt, err)
+			return
+		}
+		log.Printf("Archive job %s: %s callback attempt %d failed: %v", id, payload.Event, attempt, err)
+		last = start
+	}
+}
+
+// recordCallback stores a delivery attempt even while shutting down, so
+// the attempt is not repeated on restart.
+func (p *ArchiveProcessor) recordCallback(id string, delivery CallbackDelivery, done bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), archiveStoreUpdateTimeout)
+	defer cancel()
+	if err := p.store.RecordCallbackDelivery(ctx, id, delivery, done); err != nil {
+		log.Printf("Archive job %s: failed to record callback delivery: %v", id, err)
+	}
+}
@@ -131,6 +131,8 @@ const (
 	ArchiveStatusProcessing ArchiveStatus = "processing"
 	ArchiveStatusComplete   ArchiveStatus = "complete"
 	ArchiveStatusFailed     ArchiveStatus = "failed"
+	ArchiveStatusCancelled  ArchiveStatus = "cancelled"
+	ArchiveStatusExpired    ArchiveStatus = "expired" // artifacts deleted; lineage is kept
 )
 
 // FullArchiveRequest for POST /gold/compliance/archive
@@ -142,6 +144,9 @@ type FullArchiveRequest struct {
 	Include          []string `json:"include"`           // ["transactions", "balances", "supply"]
 	BalanceSnapshots []string `json:"balance_snapshots"` // specific dates for balance snapshots
 	Format           string   `json:"format"`            // "json" or "csv"
+	// CallbackURL receives a signed ArchiveCallbackPayload when the job
+	// completes, fails or is cancelled.
+	CallbackURL string `json:"callback_url,omitempty"`
 }
 
 // ArchiveArtifact represents a single file in an archive
@@ -152,6 +157,10 @@ type ArchiveArtifact struct {
 	RowCount    int    `json:"row_count,omitempty"`
 	Checksum    string `json:"checksum"`
 	GeneratedAt string `json:"generated_at"`
+	// SizeBytes and FileSHA256 describe the stored file, when artifact
+	// storage is configured; Checksum covers the data itself.
+	SizeBytes  int64  `json:"size_bytes,omitempty"`
+	FileSHA256 string `json:"file_sha256,omitempty"`
 }
 
 // FullArchiveResponse for POST /gold/compliance/archive (initial response)
@@ -165,15 +174,39 @@ type FullArchiveResponse struct {
 
 // FullArchiveStatusResponse for GET /gold/compliance/archive/{id}
 type FullArchiveStatusResponse struct {
-	ArchiveID          string            `json:"archive_id"`
-	Status             string            `json:"status"`
-	Asset              AssetInfo         `json:"asset"`
-	Period             PeriodInfo        `json:"period"`
-	Artifacts          []ArchiveArtifact `json:"artifacts,omitempty"`
-	Error              string            `json:"error,omitempty"`
-	MethodologyVersion string            `json:"methodology_version"`
-	CreatedAt          string            `json:"created_at"`
-	CompletedAt        string            `json:"completed_at,omitempty"`
+	ArchiveID          string             `json:"archive_id"`
+	Status             string             `json:"status"`
+	Asset              AssetInfo          `json:"asset"`
+	Period             PeriodInfo         `json:"period"`
+	Artifacts          []ArchiveArtifact  `json:"artifacts,omitempty"`
+	Error              string             `json:"error,omitempty"`
+	MethodologyVersion string             `json:"methodology_version"`
+	CreatedAt          string             `json:"created_at"`
+	CompletedAt        string             `json:"completed_at,omitempty"`
+	ExpiresAt          string             `json:"expires_at,omitempty"`
+	CallbackDeliveries []CallbackDelivery `json:"callback_deliveries,omitempty"`
+}
+
+// ArchiveCallbackPayload is POSTed to FullArchiveRequest.CallbackURL when an
+// archive job finishes. Manifest covers the artifacts written before a
+// failure or cancellation.
+type ArchiveCallbackPayload struct {
+	Event     string           `json:"event"` // archive.completed, archive.failed or archive.cancelled
+	ArchiveID string           `json:"archive_id"`
+	Status    string           `json:"status"`
+	Error     string           `json:"error,omitempty"`
+	StatusURL string           `json:"status_url"`
+	Manifest  *ArchiveManifest `json:"manifest,omitempty"`
+}
+
+// CallbackDelivery records one attempt to deliver an archive callback
+type CallbackDelivery struct {
+	Attempt     int    `json:"attempt"`
+	Event       string `json:"event"`
+	AttemptedAt string `json:"attempted_at"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	Error       string `json:"error,omitempty"`
+	DurationMS  int64  `json:"duration_ms"`
 }
 
 // ============================================
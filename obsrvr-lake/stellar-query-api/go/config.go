@@ -11,17 +11,21 @@ import (
 )
 
 type Config struct {
-	Service           ServiceConfig           `yaml:"service"`
-	Postgres          PostgresConfig          `yaml:"postgres"`
-	PostgresSilver    *PostgresConfig         `yaml:"postgres_silver,omitempty"`
-	DuckLake          DuckLakeConfig          `yaml:"ducklake"`
-	DuckLakeSilver    *DuckLakeConfig         `yaml:"ducklake_silver,omitempty"`
-	Index             *IndexConfig            `yaml:"index,omitempty"`
-	ContractIndex     *ContractIndexConfig    `yaml:"contract_index,omitempty"`
-	Query             QueryConfig             `yaml:"query"`
-	Unified           *UnifiedReaderConfig    `yaml:"unified,omitempty"` // Config for DuckDB ATTACH unified reader
-	RPCFallback       *RPCFallbackConfig      `yaml:"rpc_fallback,omitempty"`
-	ContractArtifacts *ContractArtifactConfig `yaml:"contract_artifacts,omitempty"`
+	Service           ServiceConfig            `yaml:"service"`
+	Postgres          PostgresConfig           `yaml:"postgres"`
+	PostgresSilver    *PostgresConfig          `yaml:"postgres_silver,omitempty"`
+	DuckLake          DuckLakeConfig           `yaml:"ducklake"`
+	DuckLakeSilver    *DuckLakeConfig          `yaml:"ducklake_silver,omitempty"`
+	Index             *IndexConfig             `yaml:"index,omitempty"`
+	ContractIndex     *ContractIndexConfig     `yaml:"contract_index,omitempty"`
+	Query             QueryConfig              `yaml:"query"`
+	Unified           *UnifiedReaderConfig     `yaml:"unified,omitempty"` // Config for DuckDB ATTACH unified reader
+	RPCFallback       *RPCFallbackConfig       `yaml:"rpc_fallback,omitempty"`
+	ContractArtifacts *ContractArtifactConfig  `yaml:"contract_artifacts,omitempty"`
+	APIKeys           *APIKeyConfig            `yaml:"api_keys,omitempty"`
+	ResponseCache     *ResponseCacheConfig     `yaml:"response_cache,omitempty"`
+	GraphQL           *GraphQLConfig           `yaml:"graphql,omitempty"`
+	ComplianceArchive *ComplianceArchiveConfig `yaml:"compliance_archive,omitempty"`
 }
 
 type ServiceConfig struct {
@@ -55,10 +59,27 @@ type DuckLakeConfig struct {
 }
 
 type QueryConfig struct {
-	DefaultLimit    int        `yaml:"default_limit"`
-	MaxLimit        int        `yaml:"max_limit"`
+	DefaultLimit int `yaml:"default_limit"`
+	MaxLimit     int `yaml:"max_limit"`
+	// CacheTTLSeconds is the response cache TTL for endpoints that follow the
+	// chain tip, such as recent ledgers and network stats.
 	CacheTTLSeconds int        `yaml:"cache_ttl_seconds"`
 	ReaderMode      ReaderMode `yaml:"reader_mode"` // legacy, unified, or hybrid
+	// Hybrid tunes the background comparison used when reader_mode is hybrid.
+	Hybrid *HybridShadowConfig `yaml:"hybrid,omitempty"`
+}
+
+// HybridShadowConfig controls how hybrid mode checks the unified reader.
+// Responses always come from the legacy reader; a sample of requests is
+// replayed against the unified reader in the background and diffed.
+type HybridShadowConfig struct {
+	// SampleRate is the fraction of requests compared, 0 to 1 (default 0.1).
+	SampleRate *float64 `yaml:"sample_rate,omitempty"`
+	// MaxConcurrent bounds in-flight comparisons; samples beyond it are
+	// dropped (default 4).
+	MaxConcurrent int `yaml:"max_concurrent,omitempty"`
+	// TimeoutSeconds bounds each unified read (default 10).
+	TimeoutSeconds int `yaml:"timeout_seconds,omitempty"`
 }
 
 // ReaderMode determines which reader implementation to use for queries
@@ -69,7 +90,8 @@ const (
 	ReaderModeLegacy ReaderMode = "legacy"
 	// ReaderModeUnified uses the new DuckDB ATTACH model (UnifiedDuckDBReader)
 	ReaderModeUnified ReaderMode = "unified"
-	// ReaderModeHybrid runs both and compares results for validation
+	// ReaderModeHybrid serves legacy results and compares a sample against
+	// the unified reader in the background
 	ReaderModeHybrid ReaderMode = "hybrid"
 )
 
@@ -154,6 +176,123 @@ type ContractArtifactConfig struct {
 	MaxWASMBytes   int64  `yaml:"max_wasm_bytes"`
 }
 
+// ResponseCacheConfig enables the response cache. Responses that only cover
+// ledgers at or below the latest closed ledger never change and are cached for
+// ImmutableTTLSeconds; responses that follow the chain tip use
+// query.cache_ttl_seconds. The in-memory LRU is always used; Redis, when set,
+// is shared between replicas.
+type ResponseCacheConfig struct {
+	Enabled             bool   `yaml:"enabled"`
+	MaxMemoryMB         int    `yaml:"max_memory_mb"`
+	MaxBodyKB           int    `yaml:"max_body_kb"`
+	ImmutableTTLSeconds int    `yaml:"immutable_ttl_seconds"`
+	KeyPrefix           string `yaml:"key_prefix"` // change on deploy to drop Redis entries written by an older build
+	// FinalityMarginLedgers is how far below the latest closed ledger a
+	// response must stay to count as immutable, covering projectors that
+	// trail the ledger stats table.
+	FinalityMarginLedgers *int64            `yaml:"finality_margin_ledgers,omitempty"`
+	Redis                 *RedisCacheConfig `yaml:"redis,omitempty"`
+}
+
+// RedisCacheConfig points the response cache at any server speaking the
+// Redis protocol (Redis, Valkey, KeyDB, Dragonfly).
+type RedisCacheConfig struct {
+	Address   string `yaml:"address"`
+	Password  string `yaml:"password"`
+	DB        int    `yaml:"db"`
+	PoolSize  int    `yaml:"pool_size"`
+	TimeoutMS int    `yaml:"timeout_ms"`
+}
+
+// GraphQLConfig enables the /graphql endpoint. Queries are costed before
+// they run: MaxCost bounds the worst-case number of reader lookups and
+// MaxDepth the selection nesting. Zero uses the defaults (1000 and 10).
+type GraphQLConfig struct {
+	Enabled  bool `yaml:"enabled"`
+	MaxCost  int  `yaml:"max_cost"`
+	MaxDepth int  `yaml:"max_depth"`
+}
+
+// ComplianceArchiveConfig makes /gold/compliance/archive jobs durable. Jobs
+// and lineage are kept in PostgreSQL when Postgres is set and in memory
+// otherwise. Artifact files are written to ArtifactDirectory, a local path or
+// an s3://bucket/prefix URL; without one only checksums are kept. Jobs left
+// pending or processing by a restart are resumed, so only one query API
+// instance should point at a given database.
+type ComplianceArchiveConfig struct {
+	Postgres          *PostgresConfig            `yaml:"postgres,omitempty"`
+	TablePrefix       string                     `yaml:"table_prefix"` // default compliance_archive
+	ArtifactDirectory string                     `yaml:"artifact_directory"`
+	S3                *ComplianceArchiveS3Config `yaml:"s3,omitempty"`
+	Workers           int                        `yaml:"workers"`    // default 2
+	QueueSize         int                        `yaml:"queue_size"` // default 100
+	// JobTTLHours is how long finished jobs keep their artifacts before
+	// they are deleted and the job is marked expired (default 720).
+	JobTTLHours int                             `yaml:"job_ttl_hours"`
+	Webhook     *ComplianceArchiveWebhookConfig `yaml:"webhook,omitempty"`
+}
+
+// ComplianceArchiveWebhookConfig enables callback_url on archive requests.
+// Callbacks are signed with HMAC-SHA256 using Secret and retried with
+// exponential backoff until the receiver answers 2xx or MaxAttempts is
+// reached.
+type ComplianceArchiveWebhookConfig struct {
+	Secret                string `yaml:"secret"`
+	MaxAttempts           int    `yaml:"max_attempts"`            // default 8
+	InitialBackoffSeconds int    `yaml:"initial_backoff_seconds"` // default 10, doubled per attempt
+	MaxBackoffSeconds     int    `yaml:"max_backoff_seconds"`     // default 3600
+	TimeoutSeconds        int    `yaml:"timeout_seconds"`         // default 10
+	AllowHTTP             bool   `yaml:"allow_http"`              // accept http:// callback URLs
+	// AllowPrivateNetworks lets callbacks reach loopback, private,
+	// link-local and other non-public addresses; for local development only.
+	AllowPrivateNetworks bool `yaml:"allow_private_networks"`
+}
+
+// ComplianceArchiveS3Config holds credentials for an s3:// artifact
+// directory. Empty credentials fall back to AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION. Endpoint selects an
+// S3-compatible service (MinIO, R2, B2), which usually needs PathStyle.
+type ComplianceArchiveS3Config struct {
+	Region          string `yaml:"region"`
+	Endpoint        string `yaml:"endpoint"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	SessionToken    string `yaml:"session_token"`
+	PathStyle       bool   `yaml:"path_style"`
+}
+
+// APIKeyConfig enables API-key authentication with per-key rate limits and
+// daily quotas. Keys are looked up in a file or PostgreSQL store and each key
+// is assigned a plan; requests without a key use AnonymousPlan, or are
+// rejected when it is empty.
+type APIKeyConfig struct {
+	Enabled         bool                  `yaml:"enabled"`
+	Store           string                `yaml:"store"` // file or postgres
+	File            string                `yaml:"file"`
+	Postgres        *PostgresConfig       `yaml:"postgres,omitempty"`
+	Table           string                `yaml:"table"`
+	CacheTTLSeconds int                   `yaml:"cache_ttl_seconds"`
+	Plans           map[string]APIKeyPlan `yaml:"plans"`
+	DefaultPlan     string                `yaml:"default_plan"`
+	AnonymousPlan   string                `yaml:"anonymous_plan"`
+	// ExpensiveRoutes and ExemptRoutes are path patterns: a trailing "*"
+	// matches any suffix, otherwise path.Match syntax applies.
+	ExpensiveRoutes   []string `yaml:"expensive_routes"`
+	ExemptRoutes      []string `yaml:"exempt_routes"`
+	TrustForwardedFor bool     `yaml:"trust_forwarded_for"`
+}
+
+// APIKeyPlan is the budget shared by every key on a plan. Zero values are
+// unlimited. Expensive routes draw from both the general and the expensive
+// bucket.
+type APIKeyPlan struct {
+	RequestsPerSecond          float64 `yaml:"requests_per_second"`
+	Burst                      int     `yaml:"burst"`
+	ExpensiveRequestsPerSecond float64 `yaml:"expensive_requests_per_second"`
+	ExpensiveBurst             int     `yaml:"expensive_burst"`
+	DailyQuota                 int64   `yaml:"daily_quota"`
+}
+
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -222,6 +361,15 @@ func applyRuntimeEnvOverrides(config *Config) error {
 		}
 		config.ContractArtifacts.MaxWASMBytes = limit
 	}
+	if secret := strings.TrimSpace(os.Getenv("COMPLIANCE_ARCHIVE_WEBHOOK_SECRET")); secret != "" {
+		if config.ComplianceArchive == nil {
+			config.ComplianceArchive = &ComplianceArchiveConfig{}
+		}
+		if config.ComplianceArchive.Webhook == nil {
+			config.ComplianceArchive.Webhook = &ComplianceArchiveWebhookConfig{}
+		}
+		config.ComplianceArchive.Webhook.Secret = secret
+	}
 	return nil
 }
 
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	defaultGraphQLMaxCost  = 1000
+	defaultGraphQLMaxDepth = 10
+	// graphQLCostCeiling keeps nested list multipliers from overflowing; any
+	// query that reaches it is far past every sane limit anyway.
+	graphQLCostCeiling = 1 << 40
+)
+
+// graphQLListLimit is the page size a list field returns when the query does
+// not pass limit, and the most it will return when it does. Resolvers and the
+// cost estimate both read these, so the estimate is the real worst case.
+type graphQLListLimit struct {
+	Default int
+	Max     int
+}
+
+var graphQLListLimits = map[string]graphQLListLimit{
+	"Account.transactions":   {Default: 10, Max: 100},
+	"Account.operations":     {Default: 10, Max: 100},
+	"Transaction.operations": {Default: 25, Max: 100},
+	"Contract.operations":    {Default: 10, Max: 100},
+}
+
+// graphQLMaxBatchIDs caps list arguments such as accounts(ids:).
+const graphQLMaxBatchIDs = 50
+
+func clampGraphQLLimit(field string, requested int, set bool) int {
+	limits := graphQLListLimits[field]
+	if !set || requested <= 0 {
+		return limits.Default
+	}
+	if requested > limits.Max {
+		return limits.Max
+	}
+	return requested
+}
+
+// graphQLQueryCost is the static estimate for one operation.
+type graphQLQueryCost struct {
+	Cost  int `json:"requested"`
+	Depth int `json:"depth"`
+}
+
+// estimateGraphQLCost walks the selected operation before it runs. Every
+// field that returns an object (a reader lookup) costs 1, scalars are free,
+// and the selections under a list field are multiplied by how many items it
+// can return: its clamped limit argument, or the length of an ids argument.
+// The document must already have passed validation, which rejects fragment
+// cycles and unknown fields.
+func estimateGraphQLCost(schema *graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}) (graphQLQueryCost, error) {
+	var operation *ast.OperationDefinition
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range doc.Definitions {
+		switch def := definition.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				if operation != nil && operationName == "" {
+					return graphQLQueryCost{}, fmt.Errorf("operationName is required when the document has several operations")
+				}
+				operation = def
+			}
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		}
+	}
+	if operation == nil {
+		return graphQLQueryCost{}, fmt.Errorf("unknown operation %q", operationName)
+	}
+	if operation.Operation != ast.OperationTypeQuery {
+		return graphQLQueryCost{}, fmt.Errorf("only query operations are supported")
+	}
+
+	walker := graphQLCostWalker{
+		fragments: fragments,
+		variables: variables,
+		defaults:  map[string]ast.Value{},
+	}
+	for _, definition := range operation.VariableDefinitions {
+		if definition.DefaultValue != nil {
+			walker.defaults[definition.Variable.Name.Value] = definition.DefaultValue
+		}
+	}
+	cost := walker.selectionSet(operation.SelectionSet, schema.QueryType(), 1)
+	return graphQLQueryCost{Cost: cost, Depth: walker.maxDepth}, nil
+}
+
+type graphQLCostWalker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	defaults  map[string]ast.Value
+	maxDepth  int
+}
+
+func (w *graphQLCostWalker) selectionSet(set *ast.SelectionSet, parent *graphql.Object, depth int) int {
+	if set == nil {
+		return 0
+	}
+	if depth > w.maxDepth {
+		w.maxDepth = depth
+	}
+	total := 0
+	for _, selection := range set.Selections {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			total = addGraphQLCost(total, w.field(sel, parent, depth))
+		case *ast.InlineFragment:
+			total = addGraphQLCost(total, w.selectionSet(sel.SelectionSet, parent, depth))
+		case *ast.FragmentSpread:
+			if fragment := w.fragments[sel.Name.Value]; fragment != nil {
+				total = addGraphQLCost(total, w.selectionSet(fragment.SelectionSet, parent, depth))
+			}
+		}
+	}
+	return total
+}
+
+func (w *graphQLCostWalker) field(field *ast.Field, parent *graphql.Object, depth int) int {
+	definition, ok := parent.Fields()[field.Name.Value]
+	if !ok {
+		// Introspection (__typename, __schema) and anything validation let
+		// through without a definition costs nothing.
+		return 0
+	}
+	fieldType := definition.Type
+	isList := false
+	for {
+		switch t := fieldType.(type) {
+		case *graphql.NonNull:
+			fieldType = t.OfType
+			continue
+		case *graphql.List:
+			isList = true
+			fieldType = t.OfType
+			continue
+		}
+		break
+	}
+	object, ok := fieldType.(*graphql.Object)
+	if !ok {
+		return 0
+	}
+
+	multiplier := 1
+	if isList {
+		multiplier = w.listSize(parent.Name()+"."+field.Name.Value, field.Arguments)
+	}
+	return addGraphQLCost(1, mulGraphQLCost(multiplier, w.selectionSet(field.SelectionSet, object, depth+1)))
+}
+
+// listSize is the number of items a list field may return.
+func (w *graphQLCostWalker) listSize(name string, arguments []*ast.Argument) int {
+	for _, argument := range arguments {
+		switch argument.Name.Value {
+		case "ids":
+			if values, ok := w.value(argument.Value).([]interface{}); ok {
+				return len(values)
+			}
+		case "limit":
+			requested, ok := graphQLIntValue(w.value(argument.Value))
+			return clampGraphQLLimit(name, requested, ok)
+		}
+	}
+	if _, ok := graphQLListLimits[name]; ok {
+		return clampGraphQLLimit(name, 0, false)
+	}
+	return 1
+}
+
+// value turns an argument literal or variable into a plain Go value.
+func (w *graphQLCostWalker) value(value ast.Value) interface{} {
+	switch v := value.(type) {
+	case *ast.Variable:
+		if provided, ok := w.variables[v.Name.Value]; ok {
+			return provided
+		}
+		if def, ok := w.defaults[v.Name.Value]; ok {
+			return w.value(def)
+		}
+		return nil
+	case *ast.IntValue:
+		n, _ := strconv.Atoi(v.Value)
+		return n
+	case *ast.ListValue:
+		values := make([]interface{}, len(v.Values))
+		for i, item := range v.Values {
+			values[i] = w.value(item)
+		}
+		return values
+	default:
+		return value.GetValue()
+	}
+}
+
+func graphQLIntValue(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+func addGraphQLCost(a, b int) int {
+	if a+b > graphQLCostCeiling {
+		return graphQLCostCeiling
+	}
+	return a + b
+}
+
+func mulGraphQLCost(a, b int) int {
+	if a != 0 && b > graphQLCostCeiling/a {
+		return graphQLCostCeiling
+	}
+	return a * b
+}
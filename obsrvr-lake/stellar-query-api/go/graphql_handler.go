@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// GraphQLHandler serves /graphql. Every query is parsed, validated and costed
+// before any reader runs; queries over the cost or depth limit are rejected
+// with 400 so a client learns to page instead of timing out.
+type GraphQLHandler struct {
+	schema   graphql.Schema
+	source   graphQLDataSource
+	maxCost  int
+	maxDepth int
+}
+
+func NewGraphQLHandler(source graphQLDataSource, cfg *GraphQLConfig) (*GraphQLHandler, error) {
+	schema, err := newGraphQLSchema()
+	if err != nil {
+		return nil, fmt.Errorf("build graphql schema: %w", err)
+	}
+	h := &GraphQLHandler{
+		schema:   schema,
+		source:   source,
+		maxCost:  defaultGraphQLMaxCost,
+		maxDepth: defaultGraphQLMaxDepth,
+	}
+	if cfg != nil && cfg.MaxCost > 0 {
+		h.maxCost = cfg.MaxCost
+	}
+	if cfg != nil && cfg.MaxDepth > 0 {
+		h.maxDepth = cfg.MaxDepth
+	}
+	return h, nil
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions"`
+}
+
+type graphQLResponse struct {
+	Data       interface{}                `json:"data,omitempty"`
+	Errors     []gqlerrors.FormattedError `json:"errors,omitempty"`
+	Extensions map[string]interface{}     `json:"extensions,omitempty"`
+}
+
+// HandleGraphQL serves GET /graphql?query=... and POST /graphql with a JSON
+// body of {query, operationName, variables}.
+func (h *GraphQLHandler) HandleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if raw := r.URL.Query().Get("variables"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &req.Variables); err != nil {
+				writeGraphQLError(w, http.StatusBadRequest, "variables must be a JSON object", nil)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := readJSON(w, r, &req); err != nil {
+			writeGraphQLError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeGraphQLError(w, http.StatusMethodNotAllowed, "use GET or POST", nil)
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeGraphQLError(w, http.StatusBadRequest, "query is required", nil)
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, graphQLResponse{Errors: gqlerrors.FormatErrors(err)}, nil)
+		return
+	}
+	if validation := graphql.ValidateDocument(&h.schema, doc, nil); !validation.IsValid {
+		writeJSON(w, http.StatusBadRequest, graphQLResponse{Errors: validation.Errors}, nil)
+		return
+	}
+	cost, err := estimateGraphQLCost(&h.schema, doc, req.OperationName, req.Variables)
+	if err != nil {
+		writeGraphQLError(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	costExtension := map[string]interface{}{"cost": map[string]int{
+		"requested": cost.Cost,
+		"limit":     h.maxCost,
+		"depth":     cost.Depth,
+		"max_depth": h.maxDepth,
+	}}
+	if cost.Depth > h.maxDepth {
+		writeGraphQLError(w, http.StatusBadRequest,
+			fmt.Sprintf("query depth %d exceeds the limit of %d", cost.Depth, h.maxDepth), costExtension)
+		return
+	}
+	if cost.Cost > h.maxCost {
+		writeGraphQLError(w, http.StatusBadRequest,
+			fmt.Sprintf("query cost %d exceeds the limit of %d; lower list limits or select fewer nested objects", cost.Cost, h.maxCost), costExtension)
+		return
+	}
+
+	ctx, cancel := withGraphQLQueryTimeout(r.Context())
+	defer cancel()
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withGraphQLLoaders(ctx, newGraphQLLoaders(h.source)),
+	})
+	for i := range result.Errors {
+		if isQueryTimeout(errors.New(result.Errors[i].Message)) {
+			result.Errors[i].Message = "query timed out; retry with smaller limits or fewer fields"
+		}
+	}
+	writeJSON(w, http.StatusOK, graphQLResponse{
+		Data:       result.Data,
+		Errors:     result.Errors,
+		Extensions: costExtension,
+	}, nil)
+}
+
+// writeGraphQLError reports a request-level failure in the GraphQL response
+// shape rather than the REST error envelope, so GraphQL clients can parse it.
+func writeGraphQLError(w http.ResponseWriter, status int, message string, extensions map[string]interface{}) {
+	writeJSON(w, status, graphQLResponse{
+		Errors:     []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)},
+		Extensions: extensions,
+	}, nil)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// graphQLFanoutConcurrency bounds how many single-key reader calls a batch
+// fetch runs at once when the underlying reader has no batch query.
+const graphQLFanoutConcurrency = 8
+
+// batchLoader collects the keys requested by sibling resolvers and fetches
+// them in one call, dataloader style. load only queues the key and returns a
+// thunk; graphql-go runs thunks breadth-first after every resolver at the same
+// depth has been called, so the first thunk to run sees the whole level's
+// keys. Results (including errors) are cached for the rest of the request, so
+// a key requested at several depths is fetched once. A loader lives for one
+// request.
+type batchLoader[K comparable, V any] struct {
+	fetch func(context.Context, []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	results map[K]batchResult[V]
+	batches int
+}
+
+type batchResult[V any] struct {
+	value V
+	err   error
+}
+
+func newBatchLoader[K comparable, V any](fetch func(context.Context, []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{
+		fetch:   fetch,
+		queued:  map[K]bool{},
+		results: map[K]batchResult[V]{},
+	}
+}
+
+// load queues key for the next batch and returns a graphql-go thunk that
+// resolves to the loaded value, or a nil V when the key does not exist.
+func (l *batchLoader[K, V]) load(ctx context.Context, key K) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, done := l.results[key]; !done && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+	return func() (interface{}, error) {
+		return l.get(ctx, key)
+	}
+}
+
+func (l *batchLoader[K, V]) get(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if result, ok := l.results[key]; ok {
+		return result.value, result.err
+	}
+	keys := l.pending
+	l.pending = nil
+	if !l.queued[key] {
+		keys = append(keys, key)
+	}
+	l.batches++
+	values, err := l.fetch(ctx, keys)
+	for _, k := range keys {
+		delete(l.queued, k)
+		l.results[k] = batchResult[V]{value: values[k], err: err}
+	}
+	result := l.results[key]
+	return result.value, result.err
+}
+
+// fanoutLoad adapts a single-key reader call into a batch fetch, running at
+// most graphQLFanoutConcurrency lookups at a time. Keys for which get returns
+// a nil value are left out of the result. The first error cancels the rest.
+func fanoutLoad[K comparable, V any](ctx context.Context, keys []K, get func(context.Context, K) (*V, error)) (map[K]*V, error) {
+	values := make([]*V, len(keys))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(graphQLFanoutConcurrency)
+	for i, key := range keys {
+		group.Go(func() error {
+			value, err := get(groupCtx, key)
+			values[i] = value
+			return err
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	out := make(map[K]*V, len(keys))
+	for i, key := range keys {
+		if values[i] != nil {
+			out[key] = values[i]
+		}
+	}
+	return out, nil
+}
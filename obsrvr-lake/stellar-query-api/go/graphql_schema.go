@@ -0,0 +1,580 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// graphQLLoaders holds the per-request batch loaders. Resolvers find them in
+// the request context so the schema itself can be built once and shared.
+type graphQLLoaders struct {
+	source       graphQLDataSource
+	accounts     *batchLoader[string, *AccountCurrent]
+	transactions *batchLoader[string, *graphQLTransaction]
+	ledgers      *batchLoader[int64, *graphQLLedger]
+	contracts    *batchLoader[string, *SemanticContract]
+	tokens       *batchLoader[string, *SemanticTokenSummary]
+	smartWallets *batchLoader[string, *SmartWalletInfo]
+}
+
+func newGraphQLLoaders(source graphQLDataSource) *graphQLLoaders {
+	return &graphQLLoaders{
+		source:       source,
+		accounts:     newBatchLoader(source.AccountsByID),
+		transactions: newBatchLoader(source.TransactionsByHash),
+		ledgers:      newBatchLoader(source.LedgersBySequence),
+		contracts:    newBatchLoader(source.ContractsByID),
+		tokens:       newBatchLoader(source.TokensByContractID),
+		smartWallets: newBatchLoader(source.SmartWalletsByContractID),
+	}
+}
+
+type graphQLLoadersKey struct{}
+
+func withGraphQLLoaders(ctx context.Context, loaders *graphQLLoaders) context.Context {
+	return context.WithValue(ctx, graphQLLoadersKey{}, loaders)
+}
+
+func graphQLLoadersFrom(ctx context.Context) *graphQLLoaders {
+	loaders, _ := ctx.Value(graphQLLoadersKey{}).(*graphQLLoaders)
+	return loaders
+}
+
+// graphQLSource returns the parent object for a resolver. List items arrive
+// as values and single objects as pointers, so accept both.
+func graphQLSource[T any](source interface{}) *T {
+	switch s := source.(type) {
+	case *T:
+		return s
+	case T:
+		return &s
+	}
+	return nil
+}
+
+// graphQLField resolves a field through get on the parent object.
+func graphQLField[T any](typ graphql.Output, get func(*T) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			parent := graphQLSource[T](p.Source)
+			if parent == nil {
+				return nil, nil
+			}
+			return get(parent), nil
+		},
+	}
+}
+
+// graphQLLimitArg is the limit argument shared by list fields; the default and
+// maximum come from graphQLListLimits.
+func graphQLLimitArg(name string) graphql.FieldConfigArgument {
+	limits := graphQLListLimits[name]
+	return graphql.FieldConfigArgument{
+		"limit": &graphql.ArgumentConfig{
+			Type:        graphql.Int,
+			Description: fmt.Sprintf("Page size (default %d, max %d).", limits.Default, limits.Max),
+		},
+	}
+}
+
+func graphQLLimit(p graphql.ResolveParams, name string) int {
+	requested, ok := p.Args["limit"].(int)
+	return clampGraphQLLimit(name, requested, ok)
+}
+
+// graphQLLong carries 64-bit counters and ledger numbers that can outgrow the
+// spec's 32-bit Int.
+var graphQLLong = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Long",
+	Description: "A 64-bit signed integer.",
+	Serialize:   coerceGraphQLLong,
+	ParseValue:  coerceGraphQLLong,
+	ParseLiteral: func(value ast.Value) interface{} {
+		if v, ok := value.(*ast.IntValue); ok {
+			if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+				return n
+			}
+		}
+		return nil
+	},
+})
+
+func coerceGraphQLLong(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case *int64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	}
+	return nil
+}
+
+func optionalString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// newGraphQLSchema builds the explorer schema over graphQLDataSource. Object
+// fields that point at another entity (a transaction's source account, an
+// operation's contract) go through the batch loaders so a page of N rows
+// costs one lookup per entity type rather than N.
+func newGraphQLSchema() (graphql.Schema, error) {
+	var accountType, transactionType, operationType, ledgerType, contractType, tokenType, smartWalletType *graphql.Object
+
+	loadAccount := func(p graphql.ResolveParams, id string) (interface{}, error) {
+		if id == "" {
+			return nil, nil
+		}
+		return graphQLLoadersFrom(p.Context).accounts.load(p.Context, id), nil
+	}
+	loadTransaction := func(p graphql.ResolveParams, hash string) (interface{}, error) {
+		if hash == "" {
+			return nil, nil
+		}
+		return graphQLLoadersFrom(p.Context).transactions.load(p.Context, hash), nil
+	}
+	loadLedger := func(p graphql.ResolveParams, sequence int64) (interface{}, error) {
+		if sequence <= 0 {
+			return nil, nil
+		}
+		return graphQLLoadersFrom(p.Context).ledgers.load(p.Context, sequence), nil
+	}
+	loadContract := func(p graphql.ResolveParams, id string) (interface{}, error) {
+		if id == "" {
+			return nil, nil
+		}
+		return graphQLLoadersFrom(p.Context).contracts.load(p.Context, id), nil
+	}
+	loadToken := func(p graphql.ResolveParams, id string) (interface{}, error) {
+		if id == "" {
+			return nil, nil
+		}
+		return graphQLLoadersFrom(p.Context).tokens.load(p.Context, id), nil
+	}
+	loadSmartWallet := func(p graphql.ResolveParams, id string) (interface{}, error) {
+		if id == "" {
+			return nil, nil
+		}
+		return graphQLLoadersFrom(p.Context).smartWallets.load(p.Context, id), nil
+	}
+	sourceOf := func(p graphql.ResolveParams) graphQLDataSource {
+		return graphQLLoadersFrom(p.Context).source
+	}
+
+	balanceType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Balance",
+		Fields: graphql.Fields{
+			"assetType":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"assetCode":      graphQLField(graphql.String, func(b *Balance) interface{} { return optionalString(b.AssetCode) }),
+			"assetIssuer":    &graphql.Field{Type: graphql.String},
+			"balance":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"balanceStroops": &graphql.Field{Type: graphQLLong},
+			"limit":          &graphql.Field{Type: graphql.String},
+			"isAuthorized":   &graphql.Field{Type: graphql.Boolean},
+			"sponsor":        &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	accountSummaryType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AccountSummary",
+		Description: "Activity rollup from the semantic layer.",
+		Fields: graphql.Fields{
+			"totalOperations":       &graphql.Field{Type: graphQLLong},
+			"totalPaymentsSent":     &graphql.Field{Type: graphQLLong},
+			"totalPaymentsReceived": graphQLField(graphQLLong, func(s *SemanticAccountSummary) interface{} { return s.TotalPaymentsRecvd }),
+			"totalContractCalls":    &graphql.Field{Type: graphQLLong},
+			"uniqueContractsCalled": &graphql.Field{Type: graphQLLong},
+			"topContractId":         graphQLField(graphql.ID, func(s *SemanticAccountSummary) interface{} { return s.TopContractID }),
+			"topContractFunction":   &graphql.Field{Type: graphql.String},
+			"isContractDeployer":    &graphql.Field{Type: graphql.Boolean},
+			"contractsDeployed":     &graphql.Field{Type: graphql.Int},
+			"firstActivity":         &graphql.Field{Type: graphql.String},
+			"lastActivity":          &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	walletSignerType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "WalletSigner",
+		Fields: graphql.Fields{
+			"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"keyType": &graphql.Field{Type: graphql.String},
+			"weight":  &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	accountType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Account",
+		Description: "A classic Stellar account (G...) and its current state.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":                  graphQLField(graphql.NewNonNull(graphql.ID), func(a *AccountCurrent) interface{} { return a.AccountID }),
+				"balance":             &graphql.Field{Type: graphql.String, Description: "Native balance in XLM."},
+				"sequenceNumber":      &graphql.Field{Type: graphql.String},
+				"numSubentries":       &graphql.Field{Type: graphQLLong},
+				"numSponsoring":       &graphql.Field{Type: graphQLLong},
+				"numSponsored":        &graphql.Field{Type: graphQLLong},
+				"lastModifiedLedger":  &graphql.Field{Type: graphQLLong},
+				"homeDomain":          &graphql.Field{Type: graphql.String},
+				"sponsor":             &graphql.Field{Type: graphql.String},
+				"createdAt":           &graphql.Field{Type: graphql.String},
+				"updatedAt":           &graphql.Field{Type: graphql.String},
+				"authRequired":        &graphql.Field{Type: graphql.Boolean},
+				"authRevocable":       &graphql.Field{Type: graphql.Boolean},
+				"authImmutable":       &graphql.Field{Type: graphql.Boolean},
+				"authClawbackEnabled": &graphql.Field{Type: graphql.Boolean},
+				"balances": &graphql.Field{
+					Type: graphql.NewList(graphql.NewNonNull(balanceType)),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						account := graphQLSource[AccountCurrent](p.Source)
+						return sourceOf(p).AccountBalances(p.Context, account.AccountID)
+					},
+				},
+				"summary": &graphql.Field{
+					Type: accountSummaryType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						account := graphQLSource[AccountCurrent](p.Source)
+						return sourceOf(p).AccountSummary(p.Context, account.AccountID)
+					},
+				},
+				"transactions": &graphql.Field{
+					Type:        graphql.NewList(graphql.NewNonNull(transactionType)),
+					Description: "Most recent transactions involving the account, newest first.",
+					Args:        graphQLLimitArg("Account.transactions"),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						account := graphQLSource[AccountCurrent](p.Source)
+						return sourceOf(p).AccountTransactions(p.Context, account.AccountID, graphQLLimit(p, "Account.transactions"))
+					},
+				},
+				"operations": &graphql.Field{
+					Type:        graphql.NewList(graphql.NewNonNull(operationType)),
+					Description: "Most recent operations involving the account, newest first.",
+					Args:        graphQLLimitArg("Account.operations"),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						account := graphQLSource[AccountCurrent](p.Source)
+						return sourceOf(p).AccountOperations(p.Context, account.AccountID, graphQLLimit(p, "Account.operations"))
+					},
+				},
+			}
+		}),
+	})
+
+	transactionType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Transaction",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"hash":           &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"ledgerSequence": &graphql.Field{Type: graphQLLong},
+				"closedAt":       &graphql.Field{Type: graphql.String},
+				"sourceAccount":  &graphql.Field{Type: graphql.String},
+				"feeCharged":     &graphql.Field{Type: graphql.String},
+				"operationCount": &graphql.Field{Type: graphql.Int},
+				"successful":     &graphql.Field{Type: graphql.Boolean},
+				"memoType":       &graphql.Field{Type: graphql.String},
+				"memo":           &graphql.Field{Type: graphql.String},
+				"source": &graphql.Field{
+					Type: accountType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadAccount(p, graphQLSource[graphQLTransaction](p.Source).SourceAccount)
+					},
+				},
+				"ledger": &graphql.Field{
+					Type: ledgerType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadLedger(p, graphQLSource[graphQLTransaction](p.Source).LedgerSequence)
+					},
+				},
+				"operations": &graphql.Field{
+					Type: graphql.NewList(graphql.NewNonNull(operationType)),
+					Args: graphQLLimitArg("Transaction.operations"),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						tx := graphQLSource[graphQLTransaction](p.Source)
+						return sourceOf(p).TransactionOperations(p.Context, tx.Hash, graphQLLimit(p, "Transaction.operations"))
+					},
+				},
+			}
+		}),
+	})
+
+	operationType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Operation",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":              graphQLField(graphql.NewNonNull(graphql.ID), func(op *EnrichedOperation) interface{} { return strconv.FormatInt(op.OperationID, 10) }),
+				"transactionHash": &graphql.Field{Type: graphql.String},
+				"ledgerSequence":  &graphql.Field{Type: graphQLLong},
+				"closedAt":        graphQLField(graphql.String, func(op *EnrichedOperation) interface{} { return op.LedgerClosedAt }),
+				"sourceAccount":   &graphql.Field{Type: graphql.String},
+				"type":            &graphql.Field{Type: graphql.Int},
+				"typeName":        &graphql.Field{Type: graphql.String},
+				"destination":     &graphql.Field{Type: graphql.String},
+				"assetCode":       &graphql.Field{Type: graphql.String},
+				"assetIssuer":     &graphql.Field{Type: graphql.String},
+				"amount":          &graphql.Field{Type: graphql.String},
+				"successful":      graphQLField(graphql.Boolean, func(op *EnrichedOperation) interface{} { return op.TxSuccessful }),
+				"isPayment":       graphQLField(graphql.Boolean, func(op *EnrichedOperation) interface{} { return op.IsPaymentOp }),
+				"isSoroban":       graphQLField(graphql.Boolean, func(op *EnrichedOperation) interface{} { return op.IsSorobanOp }),
+				"contractId":      graphQLField(graphql.ID, func(op *EnrichedOperation) interface{} { return op.SorobanContractID }),
+				"function":        graphQLField(graphql.String, func(op *EnrichedOperation) interface{} { return op.SorobanFunction }),
+				"argumentsJson":   graphQLField(graphql.String, func(op *EnrichedOperation) interface{} { return op.SorobanArgsJSON }),
+				"transaction": &graphql.Field{
+					Type: transactionType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadTransaction(p, graphQLSource[EnrichedOperation](p.Source).TransactionHash)
+					},
+				},
+				"ledger": &graphql.Field{
+					Type: ledgerType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadLedger(p, graphQLSource[EnrichedOperation](p.Source).LedgerSequence)
+					},
+				},
+				"source": &graphql.Field{
+					Type: accountType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadAccount(p, graphQLSource[EnrichedOperation](p.Source).SourceAccount)
+					},
+				},
+				"contract": &graphql.Field{
+					Type: contractType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						op := graphQLSource[EnrichedOperation](p.Source)
+						if op.SorobanContractID == nil {
+							return nil, nil
+						}
+						return loadContract(p, *op.SorobanContractID)
+					},
+				},
+			}
+		}),
+	})
+
+	ledgerType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Ledger",
+		Fields: graphql.Fields{
+			"sequence":                   &graphql.Field{Type: graphql.NewNonNull(graphQLLong)},
+			"hash":                       &graphql.Field{Type: graphql.String},
+			"previousHash":               &graphql.Field{Type: graphql.String},
+			"closedAt":                   &graphql.Field{Type: graphql.String},
+			"successfulTransactionCount": &graphql.Field{Type: graphql.Int},
+			"failedTransactionCount":     &graphql.Field{Type: graphql.Int},
+			"operationCount":             &graphql.Field{Type: graphql.Int},
+			"baseFee":                    &graphql.Field{Type: graphql.Int},
+			"protocolVersion":            &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	contractType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Contract",
+		Description: "A Soroban contract as classified by the semantic layer.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":                graphQLField(graphql.NewNonNull(graphql.ID), func(c *SemanticContract) interface{} { return c.ContractID }),
+				"contractType":      &graphql.Field{Type: graphql.String},
+				"tokenName":         &graphql.Field{Type: graphql.String},
+				"tokenSymbol":       &graphql.Field{Type: graphql.String},
+				"tokenDecimals":     &graphql.Field{Type: graphql.Int},
+				"deployerAccount":   &graphql.Field{Type: graphql.String},
+				"deployedAt":        &graphql.Field{Type: graphql.String},
+				"deployedLedger":    &graphql.Field{Type: graphQLLong},
+				"totalInvocations":  &graphql.Field{Type: graphQLLong},
+				"uniqueCallers":     &graphql.Field{Type: graphQLLong},
+				"lastActivity":      &graphql.Field{Type: graphql.String},
+				"observedFunctions": &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+				"walletType":        &graphql.Field{Type: graphql.String},
+				"deployer": &graphql.Field{
+					Type: accountType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						contract := graphQLSource[SemanticContract](p.Source)
+						if contract.DeployerAccount == nil {
+							return nil, nil
+						}
+						return loadAccount(p, *contract.DeployerAccount)
+					},
+				},
+				"token": &graphql.Field{
+					Type: tokenType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadToken(p, graphQLSource[SemanticContract](p.Source).ContractID)
+					},
+				},
+				"smartWallet": &graphql.Field{
+					Type: smartWalletType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadSmartWallet(p, graphQLSource[SemanticContract](p.Source).ContractID)
+					},
+				},
+				"operations": &graphql.Field{
+					Type: graphql.NewList(graphql.NewNonNull(operationType)),
+					Args: graphQLLimitArg("Contract.operations"),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						contract := graphQLSource[SemanticContract](p.Source)
+						return sourceOf(p).ContractOperations(p.Context, contract.ContractID, graphQLLimit(p, "Contract.operations"))
+					},
+				},
+			}
+		}),
+	})
+
+	tokenType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Token",
+		Description: "A SEP-41 token (SAC or custom Soroban token).",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"contractId":    graphQLField(graphql.NewNonNull(graphql.ID), func(t *SemanticTokenSummary) interface{} { return t.ContractID }),
+				"name":          graphQLField(graphql.String, func(t *SemanticTokenSummary) interface{} { return t.TokenName }),
+				"symbol":        graphQLField(graphql.String, func(t *SemanticTokenSummary) interface{} { return t.TokenSymbol }),
+				"decimals":      graphQLField(graphql.Int, func(t *SemanticTokenSummary) interface{} { return t.TokenDecimals }),
+				"tokenType":     &graphql.Field{Type: graphql.String},
+				"holderCount":   &graphql.Field{Type: graphQLLong},
+				"transferCount": &graphql.Field{Type: graphQLLong},
+				"firstSeen":     &graphql.Field{Type: graphql.String},
+				"lastActivity":  &graphql.Field{Type: graphql.String},
+				"balance": &graphql.Field{
+					Type:        graphql.String,
+					Description: "Balance held by address; 0.0000000 when it holds none.",
+					Args: graphql.FieldConfigArgument{
+						"address": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						token := graphQLSource[SemanticTokenSummary](p.Source)
+						address, _ := p.Args["address"].(string)
+						return sourceOf(p).TokenBalance(p.Context, token.ContractID, address)
+					},
+				},
+				"contract": &graphql.Field{
+					Type: contractType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadContract(p, graphQLSource[SemanticTokenSummary](p.Source).ContractID)
+					},
+				},
+			}
+		}),
+	})
+
+	smartWalletType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "SmartWallet",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"contractId":     graphQLField(graphql.NewNonNull(graphql.ID), func(w *SmartWalletInfo) interface{} { return w.ContractID }),
+				"isSmartWallet":  &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+				"walletType":     graphQLField(graphql.String, func(w *SmartWalletInfo) interface{} { return optionalString(w.WalletType) }),
+				"implementation": graphQLField(graphql.String, func(w *SmartWalletInfo) interface{} { return optionalString(w.Implementation) }),
+				"hasCheckAuth":   &graphql.Field{Type: graphql.Boolean},
+				"confidence":     &graphql.Field{Type: graphql.Float},
+				"signerCount":    &graphql.Field{Type: graphql.Int},
+				"signers":        &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(walletSignerType))},
+				"policies":       &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+				"partial":        &graphql.Field{Type: graphql.Boolean},
+				"warnings":       &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+				"contract": &graphql.Field{
+					Type: contractType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadContract(p, graphQLSource[SmartWalletInfo](p.Source).ContractID)
+					},
+				},
+			}
+		}),
+	})
+
+	idArg := func(name string) graphql.FieldConfigArgument {
+		return graphql.FieldConfigArgument{name: &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}}
+	}
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"account": &graphql.Field{
+				Type: accountType,
+				Args: idArg("id"),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, _ := p.Args["id"].(string)
+					return loadAccount(p, id)
+				},
+			},
+			"accounts": &graphql.Field{
+				Type:        graphql.NewList(accountType),
+				Description: fmt.Sprintf("Accounts in the order requested (null for unknown IDs), at most %d.", graphQLMaxBatchIDs),
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ids, _ := p.Args["ids"].([]interface{})
+					if len(ids) > graphQLMaxBatchIDs {
+						return nil, fmt.Errorf("accounts accepts at most %d ids", graphQLMaxBatchIDs)
+					}
+					thunks := make([]interface{}, len(ids))
+					for i, id := range ids {
+						thunks[i], _ = loadAccount(p, fmt.Sprint(id))
+					}
+					return thunks, nil
+				},
+			},
+			"transaction": &graphql.Field{
+				Type: transactionType,
+				Args: idArg("hash"),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					hash, _ := p.Args["hash"].(string)
+					return loadTransaction(p, hash)
+				},
+			},
+			"ledger": &graphql.Field{
+				Type: ledgerType,
+				Args: graphql.FieldConfigArgument{
+					"sequence": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					sequence, _ := p.Args["sequence"].(int)
+					return loadLedger(p, int64(sequence))
+				},
+			},
+			"contract": &graphql.Field{
+				Type: contractType,
+				Args: idArg("id"),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, _ := p.Args["id"].(string)
+					return loadContract(p, id)
+				},
+			},
+			"token": &graphql.Field{
+				Type: tokenType,
+				Args: idArg("contractId"),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, _ := p.Args["contractId"].(string)
+					return loadToken(p, id)
+				},
+			},
+			"smartWallet": &graphql.Field{
+				Type: smartWalletType,
+				Args: idArg("contractId"),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, _ := p.Args["contractId"].(string)
+					return loadSmartWallet(p, id)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
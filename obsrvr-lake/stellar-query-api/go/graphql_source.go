@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+)
+
+// graphQLDataSource is everything the GraphQL resolvers read. The *ByID style
+// methods are batch fetches behind the per-request loaders; they return only
+// the keys that exist. List methods are per parent and already bounded by the
+// caller's clamped limit.
+type graphQLDataSource interface {
+	AccountsByID(ctx context.Context, accountIDs []string) (map[string]*AccountCurrent, error)
+	AccountBalances(ctx context.Context, accountID string) ([]Balance, error)
+	AccountSummary(ctx context.Context, accountID string) (*SemanticAccountSummary, error)
+	AccountTransactions(ctx context.Context, accountID string, limit int) ([]graphQLTransaction, error)
+	AccountOperations(ctx context.Context, accountID string, limit int) ([]EnrichedOperation, error)
+	TransactionsByHash(ctx context.Context, hashes []string) (map[string]*graphQLTransaction, error)
+	TransactionOperations(ctx context.Context, hash string, limit int) ([]EnrichedOperation, error)
+	LedgersBySequence(ctx context.Context, sequences []int64) (map[int64]*graphQLLedger, error)
+	ContractsByID(ctx context.Context, contractIDs []string) (map[string]*SemanticContract, error)
+	ContractOperations(ctx context.Context, contractID string, limit int) ([]EnrichedOperation, error)
+	TokensByContractID(ctx context.Context, contractIDs []string) (map[string]*SemanticTokenSummary, error)
+	TokenBalance(ctx context.Context, contractID, address string) (*string, error)
+	SmartWalletsByContractID(ctx context.Context, contractIDs []string) (map[string]*SmartWalletInfo, error)
+}
+
+// graphQLTransaction is the Transaction type's source. Account history rows
+// and Horizon transaction lookups both map onto it.
+type graphQLTransaction struct {
+	Hash           string
+	LedgerSequence int64
+	ClosedAt       string
+	SourceAccount  string
+	FeeCharged     *string
+	OperationCount *int32
+	Successful     *bool
+	MemoType       *string
+	Memo           *string
+}
+
+type graphQLLedger struct {
+	Sequence                   int64
+	Hash                       string
+	PreviousHash               string
+	ClosedAt                   string
+	SuccessfulTransactionCount int32
+	FailedTransactionCount     *int32
+	OperationCount             int32
+	BaseFee                    int32
+	ProtocolVersion            int32
+}
+
+// graphQLReaders implements graphQLDataSource over the same readers the REST
+// handlers use: serving projections first, then the unified silver reader.
+type graphQLReaders struct {
+	silver       *UnifiedSilverReader
+	duckdb       *UnifiedDuckDBReader
+	accountTxs   *HorizonAccountTransactionReader
+	transactions *HorizonTransactionReader
+	ledgers      *HorizonLedgerReader
+	smartWallets *SmartWalletHandlers
+}
+
+var errGraphQLUnavailable = errors.New("not available on this deployment")
+
+func (app *application) newGraphQLReaders() *graphQLReaders {
+	readers := &graphQLReaders{
+		silver:       app.unifiedSilverReader,
+		duckdb:       app.unifiedDuckDBReader,
+		accountTxs:   NewHorizonAccountTransactionReader(app.silverHotReader, app.unifiedDuckDBReader),
+		transactions: NewHorizonTransactionReader(app.hotReader, app.coldReader, app.indexReader, app.silverHotReader),
+		ledgers:      NewHorizonLedgerReader(app.queryService, app.unifiedDuckDBReader),
+	}
+	if app.unifiedSilverReader != nil {
+		readers.smartWallets = NewSmartWalletHandlers(app.silverHotReader, app.unifiedSilverReader.cold, app.coldReader)
+		if app.unifiedDuckDBReader != nil {
+			readers.smartWallets.SetUnifiedDuckDBReader(app.unifiedDuckDBReader)
+		}
+	}
+	return readers
+}
+
+func (r *graphQLReaders) AccountsByID(ctx context.Context, accountIDs []string) (map[string]*AccountCurrent, error) {
+	if r.silver == nil {
+		return nil, fmt.Errorf("accounts: %w", errGraphQLUnavailable)
+	}
+	accounts, err := r.silver.hot.GetServingAccountsCurrent(ctx, accountIDs)
+	if err != nil {
+		log.Printf("graphql: serving accounts lookup failed, using unified reader: %v", err)
+		accounts = map[string]*AccountCurrent{}
+	}
+	var missing []string
+	for _, id := range accountIDs {
+		if accounts[id] == nil {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return accounts, nil
+	}
+	fallback, err := fanoutLoad(ctx, missing, r.silver.GetAccountCurrent)
+	if err != nil {
+		return nil, err
+	}
+	for id, account := range fallback {
+		accounts[id] = account
+	}
+	return accounts, nil
+}
+
+func (r *graphQLReaders) AccountBalances(ctx context.Context, accountID string) ([]Balance, error) {
+	if r.silver != nil {
+		if response, err := r.silver.hot.GetServingAccountBalances(ctx, accountID); err == nil && response != nil {
+			return response.Balances, nil
+		}
+	}
+	if r.duckdb == nil {
+		return nil, fmt.Errorf("balances: %w", errGraphQLUnavailable)
+	}
+	response, err := r.duckdb.GetAccountBalances(ctx, accountID)
+	if err != nil || response == nil {
+		return nil, err
+	}
+	return response.Balances, nil
+}
+
+func (r *graphQLReaders) AccountSummary(ctx context.Context, accountID string) (*SemanticAccountSummary, error) {
+	if r.silver == nil {
+		return nil, fmt.Errorf("account summary: %w", errGraphQLUnavailable)
+	}
+	return r.silver.hot.GetSemanticAccountSummary(ctx, accountID)
+}
+
+func (r *graphQLReaders) AccountTransactions(ctx context.Context, accountID string, limit int) ([]graphQLTransaction, error) {
+	rows, _, _, _, err := r.accountTxs.GetAccountTransactions(ctx, AccountTransactionsFilters{
+		AccountID: accountID,
+		Limit:     limit,
+		Order:     "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]graphQLTransaction, len(rows))
+	for i, row := range rows {
+		out[i] = graphQLTransaction{
+			Hash:           row.TransactionHash,
+			LedgerSequence: row.LedgerSequence,
+			ClosedAt:       row.ClosedAt,
+			FeeCharged:     row.FeeCharged,
+			Successful:     row.Successful,
+			MemoType:       row.MemoType,
+			Memo:           row.Memo,
+		}
+		if row.SourceAccount != nil {
+			out[i].SourceAccount = *row.SourceAccount
+		}
+	}
+	return out, nil
+}
+
+func (r *graphQLReaders) AccountOperations(ctx context.Context, accountID string, limit int) ([]EnrichedOperation, error) {
+	return r.operations(ctx, OperationFilters{AccountID: accountID, Limit: limit}, (*SilverHotReader).GetServingAccountOperations)
+}
+
+func (r *graphQLReaders) TransactionOperations(ctx context.Context, hash string, limit int) ([]EnrichedOperation, error) {
+	return r.operations(ctx, OperationFilters{TxHash: hash, Limit: limit, Order: "asc"}, (*SilverHotReader).GetServingTransactionOperations)
+}
+
+func (r *graphQLReaders) ContractOperations(ctx context.Context, contractID string, limit int) ([]EnrichedOperation, error) {
+	return r.operations(ctx, OperationFilters{ContractID: contractID, Limit: limit}, nil)
+}
+
+// operations reads from the serving operation feed when it covers the
+// filters and falls back to the unified silver reader otherwise.
+func (r *graphQLReaders) operations(ctx context.Context, filters OperationFilters, serving func(*SilverHotReader, context.Context, OperationFilters) ([]EnrichedOperation, string, bool, bool, error)) ([]EnrichedOperation, error) {
+	if r.silver == nil {
+		return nil, fmt.Errorf("operations: %w", errGraphQLUnavailable)
+	}
+	if serving != nil {
+		ops, _, _, served, err := serving(r.silver.hot, ctx, filters)
+		if err == nil && served {
+			return ops, nil
+		}
+		if err != nil {
+			log.Printf("graphql: serving operations unavailable, using unified reader: %v", err)
+		}
+	}
+	ops, _, _, err := r.silver.GetEnrichedOperationsWithCursor(ctx, filters)
+	return ops, err
+}
+
+func (r *graphQLReaders) TransactionsByHash(ctx context.Context, hashes []string) (map[string]*graphQLTransaction, error) {
+	if !r.transactions.Available() {
+		return nil, fmt.Errorf("transactions: %w", errGraphQLUnavailable)
+	}
+	return fanoutLoad(ctx, hashes, func(ctx context.Context, hash string) (*graphQLTransaction, error) {
+		tx, err := r.transactions.GetTransactionByHash(ctx, hash)
+		if errors.Is(err, errHorizonTransactionNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return graphQLTransactionFromHorizon(tx), nil
+	})
+}
+
+func graphQLTransactionFromHorizon(tx *protocol.Transaction) *graphQLTransaction {
+	fee := strconv.FormatInt(tx.FeeCharged, 10)
+	operationCount := tx.OperationCount
+	successful := tx.Successful
+	out := &graphQLTransaction{
+		Hash:           tx.Hash,
+		LedgerSequence: int64(tx.Ledger),
+		ClosedAt:       tx.LedgerCloseTime.UTC().Format(time.RFC3339),
+		SourceAccount:  tx.Account,
+		FeeCharged:     &fee,
+		OperationCount: &operationCount,
+		Successful:     &successful,
+	}
+	if tx.MemoType != "" {
+		memoType := tx.MemoType
+		out.MemoType = &memoType
+	}
+	if tx.Memo != "" {
+		memo := tx.Memo
+		out.Memo = &memo
+	}
+	return out
+}
+
+func (r *graphQLReaders) LedgersBySequence(ctx context.Context, sequences []int64) (map[int64]*graphQLLedger, error) {
+	if r.ledgers == nil {
+		return nil, fmt.Errorf("ledgers: %w", errGraphQLUnavailable)
+	}
+	return fanoutLoad(ctx, sequences, func(ctx context.Context, sequence int64) (*graphQLLedger, error) {
+		ledger, err := r.ledgers.GetLedger(ctx, sequence)
+		if errors.Is(err, errHorizonLedgerNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &graphQLLedger{
+			Sequence:                   int64(ledger.Sequence),
+			Hash:                       ledger.Hash,
+			PreviousHash:               ledger.PrevHash,
+			ClosedAt:                   ledger.ClosedAt.UTC().Format(time.RFC3339),
+			SuccessfulTransactionCount: ledger.SuccessfulTransactionCount,
+			FailedTransactionCount:     ledger.FailedTransactionCount,
+			OperationCount:             ledger.OperationCount,
+			BaseFee:                    ledger.BaseFee,
+			ProtocolVersion:            ledger.ProtocolVersion,
+		}, nil
+	})
+}
+
+func (r *graphQLReaders) ContractsByID(ctx context.Context, contractIDs []string) (map[string]*SemanticContract, error) {
+	if r.silver == nil {
+		return nil, fmt.Errorf("contracts: %w", errGraphQLUnavailable)
+	}
+	contracts, err := r.silver.hot.GetSemanticContractsByID(ctx, contractIDs)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]*SemanticContract, len(contracts))
+	for i := range contracts {
+		out[contracts[i].ContractID] = &contracts[i]
+	}
+	return out, nil
+}
+
+func (r *graphQLReaders) TokensByContractID(ctx context.Context, contractIDs []string) (map[string]*SemanticTokenSummary, error) {
+	if r.duckdb == nil {
+		return nil, fmt.Errorf("tokens: %w", errGraphQLUnavailable)
+	}
+	return fanoutLoad(ctx, contractIDs, func(ctx context.Context, contractID string) (*SemanticTokenSummary, error) {
+		meta, err := r.duckdb.GetSEP41TokenMetadata(ctx, contractID)
+		if err != nil {
+			return nil, err
+		}
+		return semanticTokenSummary(contractID, meta), nil
+	})
+}
+
+func (r *graphQLReaders) TokenBalance(ctx context.Context, contractID, address string) (*string, error) {
+	if r.duckdb == nil {
+		return nil, fmt.Errorf("token balances: %w", errGraphQLUnavailable)
+	}
+	return semanticTokenBalance(ctx, r.duckdb, contractID, address)
+}
+
+func (r *graphQLReaders) SmartWalletsByContractID(ctx context.Context, contractIDs []string) (map[string]*SmartWalletInfo, error) {
+	if r.smartWallets == nil {
+		return nil, fmt.Errorf("smart wallets: %w", errGraphQLUnavailable)
+	}
+	return fanoutLoad(ctx, contractIDs, r.smartWallets.GetSmartWalletInfo)
+}
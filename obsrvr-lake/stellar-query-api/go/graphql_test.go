@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// fakeGraphQLSource serves fixed data and records every batch it is asked for.
+type fakeGraphQLSource struct {
+	accounts     map[string]*AccountCurrent
+	transactions map[string][]graphQLTransaction
+	operations   map[string][]EnrichedOperation
+	contracts    map[string]*SemanticContract
+	err          error
+
+	accountBatches  [][]string
+	ledgerBatches   [][]int64
+	contractBatches [][]string
+}
+
+func (f *fakeGraphQLSource) AccountsByID(_ context.Context, ids []string) (map[string]*AccountCurrent, error) {
+	f.accountBatches = append(f.accountBatches, sortedCopy(ids))
+	out := map[string]*AccountCurrent{}
+	for _, id := range ids {
+		if account, ok := f.accounts[id]; ok {
+			out[id] = account
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeGraphQLSource) AccountBalances(context.Context, string) ([]Balance, error) {
+	return []Balance{{AssetType: "native", Balance: "10.0000000"}}, nil
+}
+
+func (f *fakeGraphQLSource) AccountSummary(context.Context, string) (*SemanticAccountSummary, error) {
+	return nil, nil
+}
+
+func (f *fakeGraphQLSource) AccountTransactions(_ context.Context, id string, limit int) ([]graphQLTransaction, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	txs := f.transactions[id]
+	if len(txs) > limit {
+		txs = txs[:limit]
+	}
+	return txs, nil
+}
+
+func (f *fakeGraphQLSource) AccountOperations(_ context.Context, id string, limit int) ([]EnrichedOperation, error) {
+	ops := f.operations[id]
+	if len(ops) > limit {
+		ops = ops[:limit]
+	}
+	return ops, nil
+}
+
+func (f *fakeGraphQLSource) TransactionsByHash(context.Context, []string) (map[string]*graphQLTransaction, error) {
+	return nil, nil
+}
+
+func (f *fakeGraphQLSource) TransactionOperations(context.Context, string, int) ([]EnrichedOperation, error) {
+	return nil, nil
+}
+
+func (f *fakeGraphQLSource) LedgersBySequence(_ context.Context, sequences []int64) (map[int64]*graphQLLedger, error) {
+	sorted := append([]int64(nil), sequences...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	f.ledgerBatches = append(f.ledgerBatches, sorted)
+	out := map[int64]*graphQLLedger{}
+	for _, sequence := range sequences {
+		out[sequence] = &graphQLLedger{Sequence: sequence, Hash: fmt.Sprintf("h%d", sequence)}
+	}
+	return out, nil
+}
+
+func (f *fakeGraphQLSource) ContractsByID(_ context.Context, ids []string) (map[string]*SemanticContract, error) {
+	f.contractBatches = append(f.contractBatches, sortedCopy(ids))
+	out := map[string]*SemanticContract{}
+	for _, id := range ids {
+		if contract, ok := f.contracts[id]; ok {
+			out[id] = contract
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeGraphQLSource) ContractOperations(context.Context, string, int) ([]EnrichedOperation, error) {
+	return nil, nil
+}
+
+func (f *fakeGraphQLSource) TokensByContractID(context.Context, []string) (map[string]*SemanticTokenSummary, error) {
+	return nil, nil
+}
+
+func (f *fakeGraphQLSource) TokenBalance(context.Context, string, string) (*string, error) {
+	return nil, nil
+}
+
+func (f *fakeGraphQLSource) SmartWalletsByContractID(context.Context, []string) (map[string]*SmartWalletInfo, error) {
+	return nil, nil
+}
+
+func sortedCopy(values []string) []string {
+	out := append([]string(nil), values...)
+	sort.Strings(out)
+	return out
+}
+
+func newFakeGraphQLSource() *fakeGraphQLSource {
+	contractID := "CCONTRACT"
+	return &fakeGraphQLSource{
+		accounts: map[string]*AccountCurrent{
+			"GA": {AccountID: "GA", Balance: "100.0000000", NumSubentries: 3},
+			"GB": {AccountID: "GB", Balance: "5.0000000"},
+		},
+		transactions: map[string][]graphQLTransaction{
+			"GA": {
+				{Hash: "t1", LedgerSequence: 10, SourceAccount: "GA"},
+				{Hash: "t2", LedgerSequence: 11, SourceAccount: "GB"},
+				{Hash: "t3", LedgerSequence: 11, SourceAccount: "GC"},
+			},
+		},
+		operations: map[string][]EnrichedOperation{
+			"GA": {
+				{OperationID: 1 << 40, TransactionHash: "t1", SourceAccount: "GA", SorobanContractID: &contractID},
+				{OperationID: 2, TransactionHash: "t2", SourceAccount: "GB", SorobanContractID: &contractID},
+			},
+		},
+		contracts: map[string]*SemanticContract{
+			contractID: {ContractID: contractID, ContractType: "token", TotalInvocations: 1 << 33},
+		},
+	}
+}
+
+func postGraphQL(t *testing.T, h *GraphQLHandler, query string, variables map[string]interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	rec := httptest.NewRecorder()
+	h.HandleGraphQL(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	var out map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("json.Unmarshal(%q): %v", rec.Body.String(), err)
+	}
+	return rec, out
+}
+
+func TestGraphQLBatchesNestedLookups(t *testing.T) {
+	source := newFakeGraphQLSource()
+	h, err := NewGraphQLHandler(source, nil)
+	if err != nil {
+		t.Fatalf("NewGraphQLHandler: %v", err)
+	}
+	rec, out := postGraphQL(t, h, `query($id: ID!) {
+		account(id: $id) {
+			id balance numSubentries
+			transactions(limit: 3) { hash source { id balance } ledger { sequence hash } }
+			operations { id contract { id totalInvocations } source { id } }
+		}
+	}`, map[string]interface{}{"id": "GA"})
+	if rec.Code != http.StatusOK || out["errors"] != nil {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	// One batch for the root account, then one for every source account the
+	// transactions and operations mention that is not already loaded.
+	if want := [][]string{{"GA"}, {"GB", "GC"}}; !reflect.DeepEqual(source.accountBatches, want) {
+		t.Fatalf("account batches = %v, want %v", source.accountBatches, want)
+	}
+	if want := [][]int64{{10, 11}}; !reflect.DeepEqual(source.ledgerBatches, want) {
+		t.Fatalf("ledger batches = %v, want %v", source.ledgerBatches, want)
+	}
+	if want := [][]string{{"CCONTRACT"}}; !reflect.DeepEqual(source.contractBatches, want) {
+		t.Fatalf("contract batches = %v, want %v", source.contractBatches, want)
+	}
+
+	account := out["data"].(map[string]interface{})["account"].(map[string]interface{})
+	txs := account["transactions"].([]interface{})
+	if len(txs) != 3 || txs[2].(map[string]interface{})["source"] != nil ||
+		txs[1].(map[string]interface{})["source"].(map[string]interface{})["balance"] != "5.0000000" ||
+		txs[1].(map[string]interface{})["ledger"].(map[string]interface{})["hash"] != "h11" {
+		t.Fatalf("transactions = %v", txs)
+	}
+	ops := account["operations"].([]interface{})
+	first := ops[0].(map[string]interface{})
+	if first["id"] != "1099511627776" || first["contract"].(map[string]interface{})["totalInvocations"] != float64(1<<33) {
+		t.Fatalf("operations = %v", ops)
+	}
+	cost := out["extensions"].(map[string]interface{})["cost"].(map[string]interface{})
+	if cost["requested"] != float64(1+1+3*2+1+10*2) || cost["limit"] != float64(defaultGraphQLMaxCost) {
+		t.Fatalf("cost = %v", cost)
+	}
+}
+
+func TestGraphQLRejectsQueriesOverLimits(t *testing.T) {
+	source := newFakeGraphQLSource()
+	h, err := NewGraphQLHandler(source, &GraphQLConfig{MaxCost: 100, MaxDepth: 4})
+	if err != nil {
+		t.Fatalf("NewGraphQLHandler: %v", err)
+	}
+
+	rec, out := postGraphQL(t, h, `{ account(id: "GA") { transactions(limit: 100) { source { id } ledger { sequence } } } }`, nil)
+	errs, _ := out["errors"].([]interface{})
+	if rec.Code != http.StatusBadRequest || len(errs) != 1 ||
+		!strings.Contains(errs[0].(map[string]interface{})["message"].(string), "query cost 202 exceeds the limit of 100") {
+		t.Fatalf("cost: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	rec, _ = postGraphQL(t, h, `{ account(id: "GA") { operations(limit: 1) { transaction { source { id } } } } }`, nil)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "query depth 5 exceeds the limit of 4") {
+		t.Fatalf("depth: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	rec, _ = postGraphQL(t, h, `{ account(id: "GA") { nope } }`, nil)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `Cannot query field \"nope\"`) {
+		t.Fatalf("validation: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if len(source.accountBatches) != 0 {
+		t.Fatalf("rejected queries reached the data source: %v", source.accountBatches)
+	}
+}
+
+func TestGraphQLReportsReaderTimeouts(t *testing.T) {
+	source := newFakeGraphQLSource()
+	source.err = fmt.Errorf("query account transactions: %w", context.DeadlineExceeded)
+	h, err := NewGraphQLHandler(source, nil)
+	if err != nil {
+		t.Fatalf("NewGraphQLHandler: %v", err)
+	}
+	rec, out := postGraphQL(t, h, `{ account(id: "GA") { id transactions { hash } } }`, nil)
+	errs, _ := out["errors"].([]interface{})
+	if rec.Code != http.StatusOK || len(errs) != 1 ||
+		errs[0].(map[string]interface{})["message"] != "query timed out; retry with smaller limits or fewer fields" {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	account := out["data"].(map[string]interface{})["account"].(map[string]interface{})
+	if account["id"] != "GA" || account["transactions"] != nil {
+		t.Fatalf("partial data = %v", account)
+	}
+}
+
+func TestEstimateGraphQLCost(t *testing.T) {
+	schema, err := newGraphQLSchema()
+	if err != nil {
+		t.Fatalf("newGraphQLSchema: %v", err)
+	}
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		cost      int
+		depth     int
+	}{
+		{"scalars are free", `{ account(id: "GA") { id balance __typename } }`, nil, 1, 2},
+		{"list pages cost one lookup", `{ account(id: "GA") { transactions { hash } } }`, nil, 1 + 1, 3},
+		{"default limit multiplies nested lookups", `{ account(id: "GA") { transactions { source { id } } } }`, nil, 1 + 1 + 10, 4},
+		{"limit is clamped", `{ account(id: "GA") { operations(limit: 5000) { source { id } } } }`, nil, 1 + 1 + 100, 4},
+		{"limit from variable default", `query($n: Int = 4) { account(id: "GA") { transactions(limit: $n) { ledger { sequence } } } }`, nil, 1 + 1 + 4, 4},
+		{"limit from variable", `query($n: Int) { account(id: "GA") { transactions(limit: $n) { ledger { sequence } } } }`, map[string]interface{}{"n": float64(7)}, 1 + 1 + 7, 4},
+		{"ids multiply", `{ accounts(ids: ["GA", "GB", "GC"]) { balances { balance } } }`, nil, 1 + 3, 3},
+		{"fragments", `{ contract(id: "C") { ...c } } fragment c on Contract { deployer { id } token { ... on Token { name } } }`, nil, 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got, err := estimateGraphQLCost(&schema, doc, "", tt.variables)
+			if err != nil {
+				t.Fatalf("estimateGraphQLCost: %v", err)
+			}
+			if got.Cost != tt.cost || got.Depth != tt.depth {
+				t.Fatalf("cost = %+v, want cost %d depth %d", got, tt.cost, tt.depth)
+			}
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
@@ -11,17 +11,16 @@ import (
 // ComplianceHandlers contains HTTP handlers for Compliance Archive API
 type ComplianceHandlers struct {
 	reader    *UnifiedSilverReader
-	store     *ArchiveStore
+	store     ArchiveStore
 	processor *ArchiveProcessor
 }
 
-// NewComplianceHandlers creates new Compliance Archive API handlers
-func NewComplianceHandlers(reader *UnifiedSilverReader) *ComplianceHandlers {
-	store := NewArchiveStore()
-	processor := NewArchiveProcessor(store, reader)
+// NewComplianceHandlers creates new Compliance Archive API handlers. The
+// processor must already be started.
+func NewComplianceHandlers(reader *UnifiedSilverReader, processor *ArchiveProcessor) *ComplianceHandlers {
 	return &ComplianceHandlers{
 		reader:    reader,
-		store:     store,
+		store:     processor.store,
 		processor: processor,
 	}
 }
@@ -280,6 +279,7 @@ func (h *ComplianceHandlers) HandleSupplyTimeline(w http.ResponseWriter, r *http
 // @Failure 400 {object} map[string]interface{} "Invalid parameters"
 // @Failure 405 {object} map[string]interface{} "Method not allowed"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 503 {object} map[string]interface{} "Archive queue full"
 // @Router /api/v1/gold/compliance/archive [post]
 func (h *ComplianceHandlers) HandleFullArchive(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -318,17 +318,38 @@ func (h *ComplianceHandlers) HandleFullArchive(w http.ResponseWriter, r *http.Re
 		respondError(w, "invalid end_date format, use YYYY-MM-DD", http.StatusBadRequest)
 		return
 	}
+	if req.Format != "" && req.Format != "json" && req.Format != "csv" {
+		respondError(w, "invalid format, use json or csv", http.StatusBadRequest)
+		return
+	}
+	if req.CallbackURL != "" {
+		if err := h.processor.ValidateCallbackURL(req.CallbackURL); err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
 	// Create the job
-	job, err := h.store.CreateJob(req)
+	job, err := h.store.CreateJob(r.Context(), req)
 	if err != nil {
 		log.Printf("Failed to create archive job: %v", err)
 		respondError(w, "failed to create archive job", http.StatusInternalServerError)
 		return
 	}
 
-	// Start processing in background
-	go h.processor.ProcessJob(context.Background(), job.ID)
+	// Queue for the background workers
+	if err := h.processor.Enqueue(job.ID); err != nil {
+		log.Printf("Archive job %s rejected: %v", job.ID, err)
+		// The client is told to retry, which creates a new job, so this one
+		// is dropped rather than failed: it must not show up as an archive
+		// or announce itself to the callback URL
+		if err := h.store.DeleteJob(r.Context(), job.ID); err != nil {
+			log.Printf("Archive job %s: failed to delete rejected job: %v", job.ID, err)
+		}
+		w.Header().Set("Retry-After", "60")
+		respondError(w, "archive queue is full, retry later", http.StatusServiceUnavailable)
+		return
+	}
 
 	// Return initial response
 	response := FullArchiveResponse{
@@ -356,22 +377,19 @@ func (h *ComplianceHandlers) HandleFullArchive(w http.ResponseWriter, r *http.Re
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/gold/compliance/archive/{id} [get]
 func (h *ComplianceHandlers) HandleArchiveStatus(w http.ResponseWriter, r *http.Request) {
-	// Extract archive ID from path
-	path := r.URL.Path
-	parts := strings.Split(path, "/")
-	if len(parts) < 2 {
+	archiveID := archiveIDFromPath(r)
+	if archiveID == "" {
 		respondError(w, "archive_id required", http.StatusBadRequest)
 		return
 	}
-	archiveID := parts[len(parts)-1]
 
-	if archiveID == "" || archiveID == "archive" {
-		respondError(w, "archive_id required", http.StatusBadRequest)
+	job, err := h.store.GetJob(r.Context(), archiveID)
+	if err != nil {
+		log.Printf("Failed to load archive job %s: %v", archiveID, err)
+		respondError(w, "failed to load archive job", http.StatusInternalServerError)
 		return
 	}
-
-	job, ok := h.store.GetJob(archiveID)
-	if !ok {
+	if job == nil {
 		respondError(w, "archive not found", http.StatusNotFound)
 		return
 	}
@@ -385,15 +403,67 @@ func (h *ComplianceHandlers) HandleArchiveStatus(w http.ResponseWriter, r *http.
 		Error:              job.Error,
 		MethodologyVersion: MethodologyArchiveV1,
 		CreatedAt:          job.CreatedAt.Format(time.RFC3339),
+		CallbackDeliveries: job.CallbackDeliveries,
 	}
 
 	if job.CompletedAt != nil {
 		response.CompletedAt = job.CompletedAt.Format(time.RFC3339)
 	}
+	if expiresAt := h.processor.ExpiresAt(job); expiresAt != nil {
+		response.ExpiresAt = expiresAt.Format(time.RFC3339)
+	}
 
 	respondJSON(w, response)
 }
 
+// HandleCancelArchive cancels a pending or processing archive job
+// @Summary Cancel archive job
+// @Description Cancels a pending or processing archive job. Finished jobs cannot be cancelled.
+// @Tags Compliance
+// @Accept json
+// @Produce json
+// @Param id path string true "Archive job ID"
+// @Success 200 {object} FullArchiveStatusResponse "Cancelled archive job"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 404 {object} map[string]interface{} "Archive not found"
+// @Failure 409 {object} map[string]interface{} "Archive already finished"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/gold/compliance/archive/{id} [delete]
+func (h *ComplianceHandlers) HandleCancelArchive(w http.ResponseWriter, r *http.Request) {
+	archiveID := archiveIDFromPath(r)
+	if archiveID == "" {
+		respondError(w, "archive_id required", http.StatusBadRequest)
+		return
+	}
+
+	cancelled, err := h.processor.Cancel(r.Context(), archiveID)
+	if errors.Is(err, errArchiveJobNotFound) {
+		respondError(w, "archive not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to cancel archive job %s: %v", archiveID, err)
+		respondError(w, "failed to cancel archive job", http.StatusInternalServerError)
+		return
+	}
+	if !cancelled {
+		respondError(w, "archive already finished", http.StatusConflict)
+		return
+	}
+
+	h.HandleArchiveStatus(w, r)
+}
+
+// archiveIDFromPath returns the {id} segment of /archive/{id}.
+func archiveIDFromPath(r *http.Request) string {
+	parts := strings.Split(r.URL.Path, "/")
+	archiveID := parts[len(parts)-1]
+	if archiveID == "archive" {
+		return ""
+	}
+	return archiveID
+}
+
 // HandleLineage returns the audit trail of completed archives
 // @Summary Get archive lineage
 // @Description Returns audit trail of completed archives for an asset
@@ -413,7 +483,12 @@ func (h *ComplianceHandlers) HandleLineage(w http.ResponseWriter, r *http.Reques
 
 	log.Printf("Compliance API: GetLineage asset=%s:%s limit=%d", assetCode, assetIssuer, limit)
 
-	archives := h.store.GetLineage(assetCode, assetIssuer, limit)
+	archives, err := h.store.GetLineage(r.Context(), assetCode, assetIssuer, limit)
+	if err != nil {
+		log.Printf("Compliance API: GetLineage failed: %v", err)
+		respondError(w, "failed to load archive lineage", http.StatusInternalServerError)
+		return
+	}
 
 	response := LineageResponse{
 		Archives:    archives,
@@ -424,17 +499,20 @@ func (h *ComplianceHandlers) HandleLineage(w http.ResponseWriter, r *http.Reques
 	respondJSON(w, response)
 }
 
-// HandleArchiveDownload serves archive artifacts (methodology.md, manifest.json)
+// HandleArchiveDownload serves archive artifacts. Every artifact is served from
+// artifact storage when it is configured; without it only methodology.md and
+// manifest.json are available, regenerated from the job.
 // @Summary Download archive artifact
-// @Description Downloads specific artifacts from a completed archive (methodology.md, manifest.json)
+// @Description Downloads an artifact from an archive. Without artifact storage only methodology.md and manifest.json are available.
 // @Tags Compliance
 // @Accept json
 // @Produce application/octet-stream
 // @Param id path string true "Archive job ID"
-// @Param artifact path string true "Artifact name (methodology.md, manifest.json)"
+// @Param artifact path string true "Artifact name (e.g. transactions.json, methodology.md, manifest.json)"
 // @Success 200 {file} file "Artifact file download"
 // @Failure 400 {object} map[string]interface{} "Invalid parameters"
 // @Failure 404 {object} map[string]interface{} "Archive or artifact not found"
+// @Failure 410 {object} map[string]interface{} "Archive expired"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/gold/compliance/archive/{id}/download/{artifact} [get]
 func (h *ComplianceHandlers) HandleArchiveDownload(w http.ResponseWriter, r *http.Request) {
@@ -458,11 +536,20 @@ func (h *ComplianceHandlers) HandleArchiveDownload(w http.ResponseWriter, r *htt
 		return
 	}
 
-	job, ok := h.store.GetJob(archiveID)
-	if !ok {
+	job, err := h.store.GetJob(r.Context(), archiveID)
+	if err != nil {
+		log.Printf("Failed to load archive job %s: %v", archiveID, err)
+		respondError(w, "failed to load archive job", http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
 		respondError(w, "archive not found", http.StatusNotFound)
 		return
 	}
+	if job.Status == ArchiveStatusExpired {
+		respondError(w, "archive expired; its artifacts have been deleted", http.StatusGone)
+		return
+	}
 
 	// Find the requested artifact
 	var foundArtifact *ArchiveArtifact
@@ -480,6 +567,26 @@ func (h *ComplianceHandlers) HandleArchiveDownload(w http.ResponseWriter, r *htt
 
 	log.Printf("Compliance API: Downloading artifact %s from archive %s", artifactName, archiveID)
 
+	if h.processor.artifacts != nil {
+		data, err := h.processor.artifacts.GetArtifact(r.Context(), archiveID, foundArtifact.Name)
+		if errors.Is(err, errArchiveArtifactNotFound) {
+			respondError(w, "artifact file not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to read artifact %s of archive %s: %v", artifactName, archiveID, err)
+			respondError(w, "failed to read artifact", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", archiveArtifactContentType(foundArtifact.Name))
+		w.Header().Set("Content-Disposition", "attachment; filename="+foundArtifact.Name)
+		if foundArtifact.FileSHA256 != "" {
+			w.Header().Set("X-Content-SHA256", foundArtifact.FileSHA256)
+		}
+		w.Write(data)
+		return
+	}
+
 	// Generate the content based on artifact type
 	switch foundArtifact.Type {
 	case "documentation":
@@ -528,3 +635,14 @@ func (h *ComplianceHandlers) HandleArchiveDownload(w http.ResponseWriter, r *htt
 		respondError(w, "artifact type not downloadable directly, use the GET endpoints with format parameter", http.StatusBadRequest)
 	}
 }
+
+func archiveArtifactContentType(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return "text/csv"
+	case strings.HasSuffix(name, ".md"):
+		return "text/markdown"
+	default:
+		return "application/json"
+	}
+}
@@ -0,0 +1,674 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stellar/go-stellar-sdk/amount"
+	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+	"github.com/stellar/go-stellar-sdk/strkey"
+	"github.com/stellar/go-stellar-sdk/support/render/hal"
+)
+
+const (
+	defaultHorizonOrderBookLimit        = 20
+	defaultHorizonTradeAggregationLimit = uint64(200)
+	horizonHourMillis                   = int64(time.Hour / time.Millisecond)
+)
+
+// horizonTradeResolutions are the bucket widths Horizon accepts for
+// /trade_aggregations: 1m, 5m, 15m, 1h, 1d and 1w.
+var horizonTradeResolutions = map[int64]bool{
+	60000:     true,
+	300000:    true,
+	900000:    true,
+	3600000:   true,
+	86400000:  true,
+	604800000: true,
+}
+
+func (h *HorizonCompatHandlers) HandleOffers(w http.ResponseWriter, r *http.Request) {
+	h.handleOfferCollection(w, r, r.URL.Query().Get("seller"))
+}
+
+func (h *HorizonCompatHandlers) HandleAccountOffers(w http.ResponseWriter, r *http.Request) {
+	h.handleOfferCollection(w, r, mux.Vars(r)["id"])
+}
+
+func (h *HorizonCompatHandlers) handleOfferCollection(w http.ResponseWriter, r *http.Request, seller string) {
+	if h.offerReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, "offer collections")
+		return
+	}
+
+	page, err := parseHorizonPageQuery(r)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	cursor, err := decodeHorizonOfferCursor(page.Cursor)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	selling, err := parseHorizonAssetParam(r, "selling", "selling")
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	buying, err := parseHorizonAssetParam(r, "buying", "buying")
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+
+	filters := OfferFilters{
+		SellerID: seller,
+		Sponsor:  r.URL.Query().Get("sponsor"),
+		Limit:    int(page.Limit),
+		Cursor:   cursor,
+		Order:    page.Order,
+	}
+	if selling != nil {
+		filters.SellingAssetCode = selling.Code
+		filters.SellingAssetIssuer = derefString(selling.Issuer)
+	}
+	if buying != nil {
+		filters.BuyingAssetCode = buying.Code
+		filters.BuyingAssetIssuer = derefString(buying.Issuer)
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	offers, _, _, err := h.offerReader.GetOffers(ctx, filters)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+
+	var out protocol.OffersPage
+	out.Embedded.Records = make([]protocol.Offer, 0, len(offers))
+	for _, offer := range offers {
+		out.Embedded.Records = append(out.Embedded.Records, horizonOfferRecord(r, offer))
+	}
+	var firstCursor, lastCursor string
+	if len(out.Embedded.Records) > 0 {
+		firstCursor = out.Embedded.Records[0].PT
+		lastCursor = out.Embedded.Records[len(out.Embedded.Records)-1].PT
+	}
+	out.Links = horizonCompatCollectionLinks(r, page, firstCursor, lastCursor)
+
+	if err := writeHorizonJSON(w, http.StatusOK, out); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+func (h *HorizonCompatHandlers) HandleOffer(w http.ResponseWriter, r *http.Request) {
+	if h.offerReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, "offer lookups")
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id <= 0 {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", "offer id must be a positive integer"))
+		return
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	offer, err := h.offerReader.GetOfferByID(ctx, id)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+	if offer == nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusNotFound, "not_found", "Resource Missing", "Offer not found."))
+		return
+	}
+
+	if err := writeHorizonJSON(w, http.StatusOK, horizonOfferRecord(r, *offer)); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+func (h *HorizonCompatHandlers) HandleTrades(w http.ResponseWriter, r *http.Request) {
+	h.handleTradeCollection(w, r, "")
+}
+
+func (h *HorizonCompatHandlers) HandleAccountTrades(w http.ResponseWriter, r *http.Request) {
+	h.handleTradeCollection(w, r, mux.Vars(r)["id"])
+}
+
+func (h *HorizonCompatHandlers) handleTradeCollection(w http.ResponseWriter, r *http.Request, accountID string) {
+	if h.tradeReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, "trade collections")
+		return
+	}
+
+	q := r.URL.Query()
+	// Silver trades are keyed by transaction and operation index; they do not
+	// carry the maker offer id or pool id needed to answer these filters.
+	for _, unsupported := range []string{"offer_id", "liquidity_pool_id"} {
+		if q.Get(unsupported) != "" {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", unsupported+" filter is not supported"))
+			return
+		}
+	}
+
+	page, err := parseHorizonPageQuery(r)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	cursor, err := DecodeTradeCursor(page.Cursor)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	base, err := parseHorizonAssetParam(r, "", "base")
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	counter, err := parseHorizonAssetParam(r, "", "counter")
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	if (base == nil) != (counter == nil) {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", "base and counter assets must be given together"))
+		return
+	}
+
+	tradeType := strings.ToLower(strings.TrimSpace(q.Get("trade_type")))
+	switch tradeType {
+	case "", "all":
+		tradeType = ""
+	case "orderbook", "liquidity_pool":
+	default:
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", "trade_type must be all, orderbook or liquidity_pool"))
+		return
+	}
+
+	filters := TradeFilters{
+		AccountID: accountID,
+		TradeType: tradeType,
+		// Horizon trade collections span all history; without an explicit
+		// start the reader would clamp to the last 24h of indexed trades.
+		StartTime: time.Unix(0, 0).UTC(),
+		Limit:     int(page.Limit),
+		Cursor:    cursor,
+		Order:     page.Order,
+	}
+	if base != nil {
+		filters.SellingAssetCode = base.Code
+		filters.SellingAssetIssuer = derefString(base.Issuer)
+		filters.BuyingAssetCode = counter.Code
+		filters.BuyingAssetIssuer = derefString(counter.Issuer)
+		filters.BothDirections = true
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	trades, _, _, err := h.tradeReader.GetTrades(ctx, filters)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+
+	var out protocol.TradesPage
+	out.Embedded.Records = make([]protocol.Trade, 0, len(trades))
+	for _, trade := range trades {
+		out.Embedded.Records = append(out.Embedded.Records, horizonTradeRecord(r, trade, base, page.Order))
+	}
+	var firstCursor, lastCursor string
+	if len(out.Embedded.Records) > 0 {
+		firstCursor = out.Embedded.Records[0].PT
+		lastCursor = out.Embedded.Records[len(out.Embedded.Records)-1].PT
+	}
+	out.Links = horizonCompatCollectionLinks(r, page, firstCursor, lastCursor)
+
+	if err := writeHorizonJSON(w, http.StatusOK, out); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+func (h *HorizonCompatHandlers) HandleOrderBook(w http.ResponseWriter, r *http.Request) {
+	if h.offerReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, "order books")
+		return
+	}
+
+	selling, err := parseHorizonAssetParam(r, "selling", "selling")
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	buying, err := parseHorizonAssetParam(r, "buying", "buying")
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	if selling == nil || buying == nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", "selling and buying assets are required"))
+		return
+	}
+
+	limit := defaultHorizonOrderBookLimit
+	if rawLimit := strings.TrimSpace(r.URL.Query().Get("limit")); rawLimit != "" {
+		parsed, err := strconv.ParseUint(rawLimit, 10, 64)
+		if err != nil || parsed == 0 {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", "limit must be a positive integer"))
+			return
+		}
+		if parsed > maxHorizonLimit {
+			parsed = maxHorizonLimit
+		}
+		limit = int(parsed)
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	asks, err := h.offerReader.GetOrderBookLevels(ctx, *selling, *buying, limit)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+	bids, err := h.offerReader.GetOrderBookLevels(ctx, *buying, *selling, limit)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+
+	out := protocol.OrderBookSummary{
+		Bids:    make([]protocol.PriceLevel, 0, len(bids)),
+		Asks:    make([]protocol.PriceLevel, 0, len(asks)),
+		Selling: protocol.Asset(horizonAssetInfo(selling)),
+		Buying:  protocol.Asset(horizonAssetInfo(buying)),
+	}
+	for _, level := range asks {
+		out.Asks = append(out.Asks, protocol.PriceLevel{
+			PriceR: protocol.Price{N: int32(level.PriceN), D: int32(level.PriceD)},
+			Price:  big.NewRat(level.PriceN, level.PriceD).FloatString(7),
+			Amount: level.Amount,
+		})
+	}
+	// Bids are offers selling the counter asset; Horizon reports them at the
+	// inverted price (counter per base) with the amount left in counter units.
+	for _, level := range bids {
+		out.Bids = append(out.Bids, protocol.PriceLevel{
+			PriceR: protocol.Price{N: int32(level.PriceD), D: int32(level.PriceN)},
+			Price:  big.NewRat(level.PriceD, level.PriceN).FloatString(7),
+			Amount: level.Amount,
+		})
+	}
+
+	if err := writeHorizonJSON(w, http.StatusOK, out); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+func (h *HorizonCompatHandlers) HandleTradeAggregations(w http.ResponseWriter, r *http.Request) {
+	if h.tradeReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, "trade aggregations")
+		return
+	}
+
+	filters, page, err := parseHorizonTradeAggregationQuery(r)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	buckets, err := h.tradeReader.GetTradeAggregations(ctx, filters)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+
+	var out protocol.TradeAggregationsPage
+	out.Embedded.Records = make([]protocol.TradeAggregation, 0, len(buckets))
+	for _, bucket := range buckets {
+		out.Embedded.Records = append(out.Embedded.Records, horizonTradeAggregationRecord(bucket))
+	}
+
+	// Trade aggregations page by time window rather than by cursor: the next
+	// page starts after the last bucket (asc) or ends at it (desc).
+	out.Links.Self = horizonCompatQueryLink(r, nil)
+	next := map[string]string{"limit": strconv.FormatUint(page.Limit, 10)}
+	if n := len(buckets); n > 0 {
+		last := buckets[n-1].TimestampMS
+		if page.Order == "desc" {
+			next["end_time"] = strconv.FormatInt(last, 10)
+		} else {
+			next["start_time"] = strconv.FormatInt(last+filters.ResolutionMS, 10)
+		}
+	}
+	out.Links.Next = horizonCompatQueryLink(r, next)
+
+	if err := writeHorizonJSON(w, http.StatusOK, out); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+// parseHorizonTradeAggregationQuery validates resolution and offset the way
+// Horizon does and snaps start_time/end_time onto bucket boundaries, so a
+// partially covered first or last bucket is never returned.
+func parseHorizonTradeAggregationQuery(r *http.Request) (TradeAggregationFilters, horizonPageQuery, error) {
+	var filters TradeAggregationFilters
+	q := r.URL.Query()
+
+	page, err := parseHorizonPageQuery(r)
+	if err != nil {
+		return filters, page, err
+	}
+	if strings.TrimSpace(q.Get("limit")) == "" {
+		page.Limit = defaultHorizonTradeAggregationLimit
+	}
+
+	base, err := parseHorizonAssetParam(r, "", "base")
+	if err != nil {
+		return filters, page, err
+	}
+	counter, err := parseHorizonAssetParam(r, "", "counter")
+	if err != nil {
+		return filters, page, err
+	}
+	if base == nil || counter == nil {
+		return filters, page, fmt.Errorf("base and counter assets are required")
+	}
+
+	resolution, err := parseHorizonMillis(q.Get("resolution"), "resolution")
+	if err != nil {
+		return filters, page, err
+	}
+	if !horizonTradeResolutions[resolution] {
+		return filters, page, fmt.Errorf("resolution %d is not supported", resolution)
+	}
+
+	var offset int64
+	if raw := strings.TrimSpace(q.Get("offset")); raw != "" {
+		if offset, err = parseHorizonMillis(raw, "offset"); err != nil {
+			return filters, page, err
+		}
+	}
+	switch {
+	case offset%horizonHourMillis != 0:
+		return filters, page, fmt.Errorf("offset must be in whole hours")
+	case offset > 24*horizonHourMillis:
+		return filters, page, fmt.Errorf("offset must not exceed 24 hours")
+	case offset != 0 && offset >= resolution:
+		return filters, page, fmt.Errorf("offset must be smaller than resolution")
+	}
+
+	filters = TradeAggregationFilters{
+		BaseAssetCode:      base.Code,
+		BaseAssetIssuer:    derefString(base.Issuer),
+		CounterAssetCode:   counter.Code,
+		CounterAssetIssuer: derefString(counter.Issuer),
+		ResolutionMS:       resolution,
+		OffsetMS:           offset,
+		Limit:              int(page.Limit),
+		Order:              page.Order,
+	}
+
+	if raw := strings.TrimSpace(q.Get("start_time")); raw != "" {
+		start, err := parseHorizonMillis(raw, "start_time")
+		if err != nil {
+			return filters, page, err
+		}
+		if rem := (start - offset) % resolution; rem != 0 {
+			start += resolution - rem
+		}
+		filters.StartTime = time.UnixMilli(start).UTC()
+	}
+	if raw := strings.TrimSpace(q.Get("end_time")); raw != "" {
+		end, err := parseHorizonMillis(raw, "end_time")
+		if err != nil {
+			return filters, page, err
+		}
+		end -= (end - offset) % resolution
+		filters.EndTime = time.UnixMilli(end).UTC()
+	}
+	if !filters.StartTime.IsZero() && !filters.EndTime.IsZero() && !filters.StartTime.Before(filters.EndTime) {
+		return filters, page, fmt.Errorf("end_time must cover at least one full resolution bucket after start_time")
+	}
+	return filters, page, nil
+}
+
+func parseHorizonMillis(raw, name string) (int64, error) {
+	value, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer of milliseconds", name)
+	}
+	return value, nil
+}
+
+// parseHorizonAssetParam reads an asset from either the canonical form
+// (`selling=native`, `selling=USDC:G...`) or Horizon's older
+// `<prefix>_asset_type/_code/_issuer` triplet. It returns nil when neither
+// form is present.
+func parseHorizonAssetParam(r *http.Request, name, prefix string) (*AssetInfo, error) {
+	q := r.URL.Query()
+	assetType := strings.TrimSpace(q.Get(prefix + "_asset_type"))
+	code := strings.TrimSpace(q.Get(prefix + "_asset_code"))
+	issuer := strings.TrimSpace(q.Get(prefix + "_asset_issuer"))
+
+	if name != "" {
+		if canonical := strings.TrimSpace(q.Get(name)); canonical != "" {
+			if assetType != "" || code != "" || issuer != "" {
+				return nil, fmt.Errorf("%s cannot be combined with %s_asset_* parameters", name, prefix)
+			}
+			if canonical == "native" {
+				assetType = "native"
+			} else {
+				var ok bool
+				code, issuer, ok = strings.Cut(canonical, ":")
+				if !ok {
+					return nil, fmt.Errorf("%s must be native or CODE:ISSUER", name)
+				}
+				assetType = "credit_alphanum4"
+				if len(code) > 4 {
+					assetType = "credit_alphanum12"
+				}
+			}
+		}
+	}
+
+	switch assetType {
+	case "":
+		if code != "" || issuer != "" {
+			return nil, fmt.Errorf("%s_asset_type is required", prefix)
+		}
+		return nil, nil
+	case "native":
+		if code != "" || issuer != "" {
+			return nil, fmt.Errorf("native %s asset cannot have a code or issuer", prefix)
+		}
+		asset := buildAssetInfo("native", "", "")
+		return &asset, nil
+	case "credit_alphanum4", "credit_alphanum12":
+		maxLen := 4
+		if assetType == "credit_alphanum12" {
+			maxLen = 12
+		}
+		if code == "" || len(code) > maxLen {
+			return nil, fmt.Errorf("invalid %s asset code %q", prefix, code)
+		}
+		if !strkey.IsValidEd25519PublicKey(issuer) {
+			return nil, fmt.Errorf("invalid %s asset issuer %q", prefix, issuer)
+		}
+		asset := buildAssetInfo(assetType, code, issuer)
+		return &asset, nil
+	default:
+		return nil, fmt.Errorf("invalid %s asset type %q", prefix, assetType)
+	}
+}
+
+// decodeHorizonOfferCursor accepts the numeric offer id Horizon uses as the
+// offer paging token.
+func decodeHorizonOfferCursor(raw string) (*OfferCursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return nil, fmt.Errorf("cursor %q is not a Horizon offer paging token", raw)
+	}
+	return &OfferCursor{OfferID: id}, nil
+}
+
+func horizonOfferRecord(r *http.Request, offer OfferCurrent) protocol.Offer {
+	links := newHorizonCompatLinkBuilder(r)
+	id := strconv.FormatInt(offer.OfferID, 10)
+	record := protocol.Offer{
+		ID:                 offer.OfferID,
+		PT:                 id,
+		Seller:             offer.SellerID,
+		Selling:            protocol.Asset(horizonAssetInfo(&offer.Selling)),
+		Buying:             protocol.Asset(horizonAssetInfo(&offer.Buying)),
+		Amount:             offer.Amount,
+		PriceR:             protocol.Price{N: int32(offer.PriceR.N), D: int32(offer.PriceR.D)},
+		Price:              offer.Price,
+		LastModifiedLedger: int32(offer.LastModifiedLedger),
+		Sponsor:            derefString(offer.Sponsor),
+	}
+	if offer.PriceR.D > 0 {
+		record.Price = big.NewRat(int64(offer.PriceR.N), int64(offer.PriceR.D)).FloatString(7)
+	}
+	record.Links.Self = links.Link("/offers", id)
+	record.Links.OfferMaker = links.Link("/accounts", offer.SellerID)
+	return record
+}
+
+// horizonTradeRecord orients a silver trade as base/counter. With a pair
+// filter the requested base asset is the base side; otherwise the seller's
+// asset is, and base_is_seller is true.
+func horizonTradeRecord(r *http.Request, trade SilverTrade, base *AssetInfo, order string) protocol.Trade {
+	links := newHorizonCompatLinkBuilder(r)
+	baseIsSeller := base == nil || sameAssetInfo(trade.Selling.Asset, *base)
+
+	baseAccount, baseAsset, baseAmount := trade.Seller.AccountID, trade.Selling.Asset, trade.Selling.Amount
+	counterAccount, counterAsset, counterAmount := trade.Buyer.AccountID, trade.Buying.Asset, trade.Buying.Amount
+	if !baseIsSeller {
+		baseAccount, counterAccount = counterAccount, baseAccount
+		baseAsset, counterAsset = counterAsset, baseAsset
+		baseAmount, counterAmount = counterAmount, baseAmount
+	}
+	baseHorizon := horizonAssetInfo(&baseAsset)
+	counterHorizon := horizonAssetInfo(&counterAsset)
+
+	record := protocol.Trade{
+		ID: fmt.Sprintf("%d-%s-%d-%d", trade.LedgerSequence, trade.TransactionHash, trade.OperationIndex, trade.TradeIndex),
+		PT: TradeCursor{
+			LedgerSequence:  trade.LedgerSequence,
+			TransactionHash: trade.TransactionHash,
+			OperationIndex:  trade.OperationIndex,
+			TradeIndex:      trade.TradeIndex,
+			Order:           order,
+		}.Encode(),
+		LedgerCloseTime:    trade.Timestamp.UTC(),
+		TradeType:          trade.TradeType,
+		BaseAmount:         baseAmount,
+		BaseAssetType:      baseHorizon.Type,
+		BaseAssetCode:      baseHorizon.Code,
+		BaseAssetIssuer:    baseHorizon.Issuer,
+		CounterAmount:      counterAmount,
+		CounterAssetType:   counterHorizon.Type,
+		CounterAssetCode:   counterHorizon.Code,
+		CounterAssetIssuer: counterHorizon.Issuer,
+		BaseIsSeller:       baseIsSeller,
+		Price:              horizonTradePrice(counterAmount, baseAmount),
+	}
+
+	// Pool fills record the pool id in the account column; everything that is
+	// not an account strkey is rendered as the pool side.
+	if trade.TradeType == "liquidity_pool" && !strkey.IsValidEd25519PublicKey(baseAccount) {
+		record.BaseLiquidityPoolID = baseAccount
+		record.Links.Base = links.Link("/liquidity_pools", baseAccount)
+	} else {
+		record.BaseAccount = baseAccount
+		record.Links.Base = links.Link("/accounts", baseAccount)
+	}
+	if trade.TradeType == "liquidity_pool" && !strkey.IsValidEd25519PublicKey(counterAccount) {
+		record.CounterLiquidityPoolID = counterAccount
+		record.Links.Counter = links.Link("/liquidity_pools", counterAccount)
+	} else {
+		record.CounterAccount = counterAccount
+		record.Links.Counter = links.Link("/accounts", counterAccount)
+	}
+	return record
+}
+
+// horizonTradePrice is the exact counter/base ratio of the traded amounts.
+func horizonTradePrice(counterAmount, baseAmount string) protocol.TradePrice {
+	n, errN := amount.ParseInt64(counterAmount)
+	d, errD := amount.ParseInt64(baseAmount)
+	if errN != nil || errD != nil || d == 0 {
+		return protocol.TradePrice{}
+	}
+	g := new(big.Int).GCD(nil, nil, big.NewInt(n), big.NewInt(d)).Int64()
+	if g > 1 {
+		n /= g
+		d /= g
+	}
+	return protocol.TradePrice{N: n, D: d}
+}
+
+func horizonTradeAggregationRecord(bucket TradeAggregationBucket) protocol.TradeAggregation {
+	price := func(ratio TradeRatio) (string, protocol.TradePrice) {
+		return big.NewRat(ratio.N, ratio.D).FloatString(7), protocol.TradePrice{N: ratio.N, D: ratio.D}
+	}
+	record := protocol.TradeAggregation{
+		Timestamp:     bucket.TimestampMS,
+		TradeCount:    bucket.TradeCount,
+		BaseVolume:    bucket.BaseVolume,
+		CounterVolume: bucket.CounterVolume,
+		Average:       bucket.Average,
+	}
+	record.High, record.HighR = price(bucket.High)
+	record.Low, record.LowR = price(bucket.Low)
+	record.Open, record.OpenR = price(bucket.Open)
+	record.Close, record.CloseR = price(bucket.Close)
+	return record
+}
+
+func sameAssetInfo(a, b AssetInfo) bool {
+	if a.Type == "native" || b.Type == "native" {
+		return a.Type == b.Type
+	}
+	return a.Code == b.Code && derefString(a.Issuer) == derefString(b.Issuer)
+}
+
+// horizonCompatQueryLink links to the current request with query parameters
+// replaced by set.
+func horizonCompatQueryLink(r *http.Request, set map[string]string) hal.Link {
+	parsed, err := url.Parse(horizonCompatRelativeRequest(r))
+	if err != nil {
+		parsed = &url.URL{Path: "/"}
+	}
+	q := parsed.Query()
+	for key, value := range set {
+		q.Set(key, value)
+	}
+	parsed.RawQuery = q.Encode()
+	return hal.NewLink(newHorizonCompatLinkBuilder(r).href(parsed.String()))
+}
+
+func renderHorizonUnifiedUnavailable(w http.ResponseWriter, r *http.Request, what string) {
+	renderHorizonProblem(w, r, horizonProblem(
+		http.StatusServiceUnavailable,
+		"data_unavailable",
+		"Data Unavailable",
+		"Horizon compatibility "+what+" require the unified DuckDB reader.",
+	))
+}
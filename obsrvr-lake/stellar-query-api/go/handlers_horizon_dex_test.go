@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const testHorizonUSDCIssuer = "GBTORQK3ZR3RPJF4WTTSH5KVDOAZ4BJI7PD2ECLSBDNHRG4ICNC4JJZV"
+
+type fakeHorizonOfferReader struct {
+	filters OfferFilters
+	offers  []OfferCurrent
+	offer   *OfferCurrent
+	levels  map[string][]OrderBookLevel
+	err     error
+}
+
+func (f *fakeHorizonOfferReader) GetOffers(ctx context.Context, filters OfferFilters) ([]OfferCurrent, string, bool, error) {
+	f.filters = filters
+	return f.offers, "", false, f.err
+}
+
+func (f *fakeHorizonOfferReader) GetOfferByID(ctx context.Context, id int64) (*OfferCurrent, error) {
+	return f.offer, f.err
+}
+
+func (f *fakeHorizonOfferReader) GetOrderBookLevels(ctx context.Context, selling, buying AssetInfo, limit int) ([]OrderBookLevel, error) {
+	return f.levels[selling.Code+"/"+buying.Code], f.err
+}
+
+type fakeHorizonTradeReader struct {
+	filters    TradeFilters
+	trades     []SilverTrade
+	aggFilters TradeAggregationFilters
+	buckets    []TradeAggregationBucket
+	err        error
+}
+
+func (f *fakeHorizonTradeReader) GetTrades(ctx context.Context, filters TradeFilters) ([]SilverTrade, string, bool, error) {
+	f.filters = filters
+	return f.trades, "", false, f.err
+}
+
+func (f *fakeHorizonTradeReader) GetTradeAggregations(ctx context.Context, filters TradeAggregationFilters) ([]TradeAggregationBucket, error) {
+	f.aggFilters = filters
+	return f.buckets, f.err
+}
+
+func serveHorizonDEX(t *testing.T, handlers *HorizonCompatHandlers, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := mux.NewRouter()
+	sub := router.PathPrefix("/api/v1/horizon-compat").Subrouter()
+	sub.HandleFunc("/offers/{id:[0-9]+}", handlers.HandleOffer).Methods("GET")
+	sub.HandleFunc("/offers", handlers.HandleOffers).Methods("GET")
+	sub.HandleFunc("/accounts/{id}/offers", handlers.HandleAccountOffers).Methods("GET")
+	sub.HandleFunc("/trades", handlers.HandleTrades).Methods("GET")
+	sub.HandleFunc("/accounts/{id}/trades", handlers.HandleAccountTrades).Methods("GET")
+	sub.HandleFunc("/order_book", handlers.HandleOrderBook).Methods("GET")
+	sub.HandleFunc("/trade_aggregations", handlers.HandleTradeAggregations).Methods("GET")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func usdcAssetInfo() AssetInfo {
+	return buildAssetInfo("credit_alphanum4", "USDC", testHorizonUSDCIssuer)
+}
+
+func TestHorizonOffersParsesFiltersAndRendersRecords(t *testing.T) {
+	t.Setenv("HORIZON_COMPAT_BASE_URL", "https://gateway.withobsrvr.com/lake/v1/testnet/api/v1/horizon-compat")
+	reader := &fakeHorizonOfferReader{offers: []OfferCurrent{{
+		OfferID:            42,
+		SellerID:           "GSELLER",
+		Selling:            buildAssetInfo("native", "", ""),
+		Buying:             usdcAssetInfo(),
+		Amount:             "100.0000000",
+		Price:              "0.3333333",
+		PriceR:             PriceR{N: 1, D: 3},
+		LastModifiedLedger: 900,
+	}}}
+	handlers := &HorizonCompatHandlers{offerReader: reader}
+
+	rec := serveHorizonDEX(t, handlers, "/api/v1/horizon-compat/accounts/GSELLER/offers?selling=native&buying=USDC:"+testHorizonUSDCIssuer+"&cursor=41&order=desc&limit=5")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	f := reader.filters
+	if f.SellerID != "GSELLER" || f.SellingAssetCode != "XLM" || f.BuyingAssetCode != "USDC" || f.BuyingAssetIssuer != testHorizonUSDCIssuer {
+		t.Fatalf("filters = %+v", f)
+	}
+	if f.Cursor == nil || f.Cursor.OfferID != 41 || f.Order != "desc" || f.Limit != 5 {
+		t.Fatalf("paging filters = %+v", f)
+	}
+
+	var body struct {
+		Embedded struct {
+			Records []map[string]any `json:"records"`
+		} `json:"_embedded"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if len(body.Embedded.Records) != 1 {
+		t.Fatalf("records len = %d", len(body.Embedded.Records))
+	}
+	record := body.Embedded.Records[0]
+	if record["id"] != "42" || record["paging_token"] != "42" || record["seller"] != "GSELLER" {
+		t.Fatalf("record = %#v", record)
+	}
+	if selling := record["selling"].(map[string]any); selling["asset_type"] != "native" {
+		t.Fatalf("selling = %#v", selling)
+	}
+	if buying := record["buying"].(map[string]any); buying["asset_code"] != "USDC" || buying["asset_issuer"] != testHorizonUSDCIssuer {
+		t.Fatalf("buying = %#v", buying)
+	}
+	links := record["_links"].(map[string]any)
+	if self := links["self"].(map[string]any)["href"]; self != "https://gateway.withobsrvr.com/lake/v1/testnet/api/v1/horizon-compat/offers/42" {
+		t.Fatalf("self href = %v", self)
+	}
+}
+
+func TestHorizonOffersAcceptsLegacyAssetParams(t *testing.T) {
+	reader := &fakeHorizonOfferReader{}
+	handlers := &HorizonCompatHandlers{offerReader: reader}
+
+	rec := serveHorizonDEX(t, handlers, "/api/v1/horizon-compat/offers?selling_asset_type=credit_alphanum4&selling_asset_code=USDC&selling_asset_issuer="+testHorizonUSDCIssuer+"&sponsor=GSPONSOR")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if reader.filters.SellingAssetCode != "USDC" || reader.filters.Sponsor != "GSPONSOR" {
+		t.Fatalf("filters = %+v", reader.filters)
+	}
+
+	rec = serveHorizonDEX(t, handlers, "/api/v1/horizon-compat/offers?selling=USDC:not-an-issuer")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid issuer status = %d", rec.Code)
+	}
+}
+
+func TestHorizonOfferNotFound(t *testing.T) {
+	handlers := &HorizonCompatHandlers{offerReader: &fakeHorizonOfferReader{}}
+	rec := serveHorizonDEX(t, handlers, "/api/v1/horizon-compat/offers/7")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHorizonTradesOrientToRequestedBase(t *testing.T) {
+	trade := SilverTrade{
+		LedgerSequence:  500,
+		TransactionHash: "txhash",
+		OperationIndex:  1,
+		TradeIndex:      0,
+		TradeType:       "orderbook",
+		Timestamp:       time.Date(2026, 7, 9, 15, 4, 0, 0, time.UTC),
+	}
+	trade.Seller.AccountID = "GSELLER"
+	trade.Selling.Asset = usdcAssetInfo()
+	trade.Selling.Amount = "25.0000000"
+	trade.Buyer.AccountID = "GBUYER"
+	trade.Buying.Asset = buildAssetInfo("", "", "")
+	trade.Buying.Amount = "100.0000000"
+
+	reader := &fakeHorizonTradeReader{trades: []SilverTrade{trade}}
+	handlers := &HorizonCompatHandlers{tradeReader: reader}
+
+	rec := serveHorizonDEX(t, handlers, "/api/v1/horizon-compat/trades?base_asset_type=native&counter_asset_type=credit_alphanum4&counter_asset_code=USDC&counter_asset_issuer="+testHorizonUSDCIssuer+"&trade_type=orderbook")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	f := reader.filters
+	if !f.BothDirections || f.SellingAssetCode != "XLM" || f.BuyingAssetCode != "USDC" || f.TradeType != "orderbook" {
+		t.Fatalf("filters = %+v", f)
+	}
+	if !f.StartTime.Equal(time.Unix(0, 0)) {
+		t.Fatalf("start time = %v, want full history", f.StartTime)
+	}
+
+	var body struct {
+		Embedded struct {
+			Records []map[string]any `json:"records"`
+		} `json:"_embedded"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	record := body.Embedded.Records[0]
+	if record["base_asset_type"] != "native" || record["base_amount"] != "100.0000000" || record["base_account"] != "GBUYER" {
+		t.Fatalf("base side = %#v", record)
+	}
+	if record["counter_asset_code"] != "USDC" || record["counter_amount"] != "25.0000000" || record["base_is_seller"] != false {
+		t.Fatalf("counter side = %#v", record)
+	}
+	price := record["price"].(map[string]any)
+	if price["n"] != "1" || price["d"] != "4" {
+		t.Fatalf("price = %#v, want 1/4", price)
+	}
+	cursor, err := DecodeTradeCursor(record["paging_token"].(string))
+	if err != nil || cursor.LedgerSequence != 500 || cursor.TransactionHash != "txhash" {
+		t.Fatalf("paging token decodes to %+v, %v", cursor, err)
+	}
+}
+
+func TestHorizonTradesRejectUnsupportedFilters(t *testing.T) {
+	handlers := &HorizonCompatHandlers{tradeReader: &fakeHorizonTradeReader{}}
+	for _, target := range []string{
+		"/api/v1/horizon-compat/trades?offer_id=12",
+		"/api/v1/horizon-compat/trades?base_asset_type=native",
+		"/api/v1/horizon-compat/accounts/GA/trades?trade_type=swap",
+	} {
+		rec := serveHorizonDEX(t, handlers, target)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, body = %s", target, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestHorizonOrderBookInvertsBids(t *testing.T) {
+	reader := &fakeHorizonOfferReader{levels: map[string][]OrderBookLevel{
+		"XLM/USDC": {{PriceN: 1, PriceD: 4, Amount: "1000.0000000"}},
+		"USDC/XLM": {{PriceN: 5, PriceD: 1, Amount: "30.0000000"}},
+	}}
+	handlers := &HorizonCompatHandlers{offerReader: reader}
+
+	rec := serveHorizonDEX(t, handlers, "/api/v1/horizon-compat/order_book?selling=native&buying=USDC:"+testHorizonUSDCIssuer)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`"asks":[{"price_r":{"n":1,"d":4},"price":"0.2500000","amount":"1000.0000000"}]`,
+		`"bids":[{"price_r":{"n":1,"d":5},"price":"0.2000000","amount":"30.0000000"}]`,
+		`"base":{"asset_type":"native"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("order book missing %s: %s", want, body)
+		}
+	}
+
+	rec = serveHorizonDEX(t, handlers, "/api/v1/horizon-compat/order_book?selling=native")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("missing buying asset status = %d", rec.Code)
+	}
+}
+
+func TestHorizonTradeAggregationsSnapWindowAndLinkNextPage(t *testing.T) {
+	t.Setenv("HORIZON_COMPAT_BASE_URL", "https://gateway.withobsrvr.com/lake/v1/testnet/api/v1/horizon-compat")
+	reader := &fakeHorizonTradeReader{buckets: []TradeAggregationBucket{{
+		TimestampMS:   7200000,
+		TradeCount:    2,
+		BaseVolume:    "150.0000000",
+		CounterVolume: "40.0000000",
+		Average:       "0.2666667",
+		High:          TradeRatio{N: 3, D: 10},
+		Low:           TradeRatio{N: 1, D: 4},
+		Open:          TradeRatio{N: 1, D: 4},
+		Close:         TradeRatio{N: 3, D: 10},
+	}}}
+	handlers := &HorizonCompatHandlers{tradeReader: reader}
+
+	pair := "base_asset_type=native&counter_asset_type=credit_alphanum4&counter_asset_code=USDC&counter_asset_issuer=" + testHorizonUSDCIssuer
+	rec := serveHorizonDEX(t, handlers, "/api/v1/horizon-compat/trade_aggregations?"+pair+"&resolution=3600000&start_time=1000&end_time=10000000")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	f := reader.aggFilters
+	if f.StartTime.UnixMilli() != 3600000 || f.EndTime.UnixMilli() != 7200000 || f.Limit != 200 {
+		t.Fatalf("window = %v..%v limit %d, want snapped to whole buckets", f.StartTime.UnixMilli(), f.EndTime.UnixMilli(), f.Limit)
+	}
+
+	var body struct {
+		Links struct {
+			Next struct {
+				Href string `json:"href"`
+			} `json:"next"`
+		} `json:"_links"`
+		Embedded struct {
+			Records []map[string]any `json:"records"`
+		} `json:"_embedded"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	record := body.Embedded.Records[0]
+	if record["timestamp"] != "7200000" || record["high"] != "0.3000000" || record["avg"] != "0.2666667" {
+		t.Fatalf("record = %#v", record)
+	}
+	if !strings.Contains(body.Links.Next.Href, "start_time=10800000") {
+		t.Fatalf("next href = %q", body.Links.Next.Href)
+	}
+
+	for _, bad := range []string{"&resolution=1000", "&resolution=3600000&offset=1800000", "&resolution=60000&offset=3600000"} {
+		rec := serveHorizonDEX(t, handlers, "/api/v1/horizon-compat/trade_aggregations?"+pair+bad)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d", bad, rec.Code)
+		}
+	}
+}
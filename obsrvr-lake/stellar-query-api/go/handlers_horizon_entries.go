@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+	"github.com/stellar/go-stellar-sdk/strkey"
+)
+
+// maxHorizonPoolReserves is the most assets /liquidity_pools?reserves= takes;
+// a constant-product pool holds exactly two.
+const maxHorizonPoolReserves = 2
+
+func (h *HorizonCompatHandlers) HandleAssets(w http.ResponseWriter, r *http.Request) {
+	if h.assetReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, "asset collections")
+		return
+	}
+
+	page, err := parseHorizonPageQuery(r)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	if page.Cursor != "" {
+		if _, _, err := parseHorizonAssetCursor(page.Cursor); err != nil {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+			return
+		}
+	}
+	q := r.URL.Query()
+	filters := HorizonAssetFilters{
+		Code:   strings.TrimSpace(q.Get("asset_code")),
+		Issuer: strings.TrimSpace(q.Get("asset_issuer")),
+	}
+	if len(filters.Code) > 12 || strings.ContainsAny(filters.Code, "_:") {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", fmt.Sprintf("invalid asset_code %q", filters.Code)))
+		return
+	}
+	if filters.Issuer != "" && !strkey.IsValidEd25519PublicKey(filters.Issuer) {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", fmt.Sprintf("invalid asset_issuer %q", filters.Issuer)))
+		return
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	records, err := h.assetReader.GetAssetStats(ctx, filters, page)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+
+	var out protocol.AssetsPage
+	out.Embedded.Records = records
+	var firstCursor, lastCursor string
+	if len(records) > 0 {
+		firstCursor = records[0].PT
+		lastCursor = records[len(records)-1].PT
+	}
+	out.Links = horizonCompatCollectionLinks(r, page, firstCursor, lastCursor)
+	if err := writeHorizonJSON(w, http.StatusOK, out); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+func (h *HorizonCompatHandlers) HandleClaimableBalances(w http.ResponseWriter, r *http.Request) {
+	if h.claimableBalanceReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, "claimable balance collections")
+		return
+	}
+
+	page, err := parseHorizonPageQuery(r)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	if page.Cursor != "" {
+		if _, _, err := parseHorizonClaimableBalanceCursor(page.Cursor); err != nil {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+			return
+		}
+	}
+	q := r.URL.Query()
+	filters := HorizonClaimableBalanceFilters{
+		Sponsor:  strings.TrimSpace(q.Get("sponsor")),
+		Claimant: strings.TrimSpace(q.Get("claimant")),
+	}
+	for _, param := range []struct{ name, value string }{{"sponsor", filters.Sponsor}, {"claimant", filters.Claimant}} {
+		if param.value != "" && !strkey.IsValidEd25519PublicKey(param.value) {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", fmt.Sprintf("invalid %s %q", param.name, param.value)))
+			return
+		}
+	}
+	if raw := strings.TrimSpace(q.Get("asset")); raw != "" {
+		assets, err := parseHorizonCanonicalAssets(raw, "asset", 1)
+		if err != nil {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+			return
+		}
+		filters.Asset = &assets[0]
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	records, err := h.claimableBalanceReader.GetClaimableBalances(ctx, filters, page)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+
+	var out protocol.ClaimableBalances
+	out.Embedded.Records = records
+	for i := range out.Embedded.Records {
+		populateHorizonClaimableBalanceLinks(r, &out.Embedded.Records[i])
+	}
+	var firstCursor, lastCursor string
+	if len(records) > 0 {
+		firstCursor = records[0].PT
+		lastCursor = records[len(records)-1].PT
+	}
+	out.Links = horizonCompatCollectionLinks(r, page, firstCursor, lastCursor)
+	if err := writeHorizonJSON(w, http.StatusOK, out); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+func (h *HorizonCompatHandlers) HandleClaimableBalance(w http.ResponseWriter, r *http.Request) {
+	if h.claimableBalanceReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, "claimable balance lookups")
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if !isHorizonClaimableBalanceID(id) {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", fmt.Sprintf("invalid claimable balance id %q", id)))
+		return
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	balance, err := h.claimableBalanceReader.GetClaimableBalance(ctx, id)
+	if err != nil {
+		if errors.Is(err, errHorizonClaimableBalanceNotFound) {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusNotFound, "not_found", "Resource Missing", "Claimable balance not found."))
+			return
+		}
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+
+	populateHorizonClaimableBalanceLinks(r, balance)
+	if err := writeHorizonJSON(w, http.StatusOK, balance); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+func (h *HorizonCompatHandlers) HandleLiquidityPools(w http.ResponseWriter, r *http.Request) {
+	if h.liquidityPoolReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, "liquidity pool collections")
+		return
+	}
+
+	page, err := parseHorizonPageQuery(r)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	if page.Cursor != "" && !isHorizonLiquidityPoolID(page.Cursor) {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", fmt.Sprintf("cursor %q is not a Horizon liquidity pool paging token", page.Cursor)))
+		return
+	}
+	q := r.URL.Query()
+	var filters HorizonLiquidityPoolFilters
+	if raw := strings.TrimSpace(q.Get("reserves")); raw != "" {
+		filters.Reserves, err = parseHorizonCanonicalAssets(raw, "reserves", maxHorizonPoolReserves)
+		if err != nil {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+			return
+		}
+	}
+	if filters.Account = strings.TrimSpace(q.Get("account")); filters.Account != "" && !strkey.IsValidEd25519PublicKey(filters.Account) {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", fmt.Sprintf("invalid account %q", filters.Account)))
+		return
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	records, err := h.liquidityPoolReader.GetLiquidityPools(ctx, filters, page)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+
+	var out protocol.LiquidityPoolsPage
+	out.Embedded.Records = records
+	for i := range out.Embedded.Records {
+		populateHorizonLiquidityPoolLinks(r, &out.Embedded.Records[i])
+	}
+	var firstCursor, lastCursor string
+	if len(records) > 0 {
+		firstCursor = records[0].PT
+		lastCursor = records[len(records)-1].PT
+	}
+	out.Links = horizonCompatCollectionLinks(r, page, firstCursor, lastCursor)
+	if err := writeHorizonJSON(w, http.StatusOK, out); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+func (h *HorizonCompatHandlers) HandleLiquidityPool(w http.ResponseWriter, r *http.Request) {
+	if h.liquidityPoolReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, "liquidity pool lookups")
+		return
+	}
+
+	id := strings.ToLower(mux.Vars(r)["id"])
+	if !isHorizonLiquidityPoolID(id) {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", fmt.Sprintf("invalid liquidity pool id %q", id)))
+		return
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	pool, err := h.liquidityPoolReader.GetLiquidityPool(ctx, id)
+	if err != nil {
+		if errors.Is(err, errHorizonLiquidityPoolNotFound) {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusNotFound, "not_found", "Resource Missing", "Liquidity pool not found."))
+			return
+		}
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+
+	populateHorizonLiquidityPoolLinks(r, pool)
+	if err := writeHorizonJSON(w, http.StatusOK, pool); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+func populateHorizonClaimableBalanceLinks(r *http.Request, balance *protocol.ClaimableBalance) {
+	links := newHorizonCompatLinkBuilder(r)
+	balance.Links.Self = links.Link("/claimable_balances", balance.BalanceID)
+	balance.Links.Transactions = links.PagedLink("/claimable_balances", balance.BalanceID, "transactions")
+	balance.Links.Operations = links.PagedLink("/claimable_balances", balance.BalanceID, "operations")
+}
+
+func populateHorizonLiquidityPoolLinks(r *http.Request, pool *protocol.LiquidityPool) {
+	links := newHorizonCompatLinkBuilder(r)
+	pool.Links.Self = links.Link("/liquidity_pools", pool.ID)
+	pool.Links.Transactions = links.PagedLink("/liquidity_pools", pool.ID, "transactions")
+	pool.Links.Operations = links.PagedLink("/liquidity_pools", pool.ID, "operations")
+}
+
+// parseHorizonCanonicalAssets parses a comma-separated list of assets in
+// Horizon's canonical form (`native` or `CODE:ISSUER`).
+func parseHorizonCanonicalAssets(raw, name string, max int) ([]AssetInfo, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) > max {
+		return nil, fmt.Errorf("%s accepts at most %d assets", name, max)
+	}
+	assets := make([]AssetInfo, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "native" {
+			assets = append(assets, buildAssetInfo("native", "", ""))
+			continue
+		}
+		code, issuer, ok := strings.Cut(part, ":")
+		if !ok || code == "" || len(code) > 12 || !strkey.IsValidEd25519PublicKey(issuer) {
+			return nil, fmt.Errorf("%s must be native or CODE:ISSUER, got %q", name, part)
+		}
+		assetType := "credit_alphanum4"
+		if len(code) > 4 {
+			assetType = "credit_alphanum12"
+		}
+		assets = append(assets, buildAssetInfo(assetType, code, issuer))
+	}
+	return assets, nil
+}
+
+func isHorizonLiquidityPoolID(id string) bool {
+	if len(id) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
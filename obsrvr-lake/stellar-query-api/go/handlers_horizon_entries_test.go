@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+)
+
+type fakeHorizonAssetReader struct {
+	filters HorizonAssetFilters
+	page    horizonPageQuery
+	stats   []protocol.AssetStat
+}
+
+func (f *fakeHorizonAssetReader) GetAssetStats(ctx context.Context, filters HorizonAssetFilters, page horizonPageQuery) ([]protocol.AssetStat, error) {
+	f.filters, f.page = filters, page
+	return f.stats, nil
+}
+
+type fakeHorizonClaimableBalanceReader struct {
+	filters  HorizonClaimableBalanceFilters
+	balances []protocol.ClaimableBalance
+	err      error
+}
+
+func (f *fakeHorizonClaimableBalanceReader) GetClaimableBalances(ctx context.Context, filters HorizonClaimableBalanceFilters, page horizonPageQuery) ([]protocol.ClaimableBalance, error) {
+	f.filters = filters
+	return f.balances, f.err
+}
+
+func (f *fakeHorizonClaimableBalanceReader) GetClaimableBalance(ctx context.Context, id string) (*protocol.ClaimableBalance, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &f.balances[0], nil
+}
+
+type fakeHorizonLiquidityPoolReader struct {
+	filters HorizonLiquidityPoolFilters
+	page    horizonPageQuery
+	pools   []protocol.LiquidityPool
+}
+
+func (f *fakeHorizonLiquidityPoolReader) GetLiquidityPools(ctx context.Context, filters HorizonLiquidityPoolFilters, page horizonPageQuery) ([]protocol.LiquidityPool, error) {
+	f.filters, f.page = filters, page
+	return f.pools, nil
+}
+
+func (f *fakeHorizonLiquidityPoolReader) GetLiquidityPool(ctx context.Context, id string) (*protocol.LiquidityPool, error) {
+	return &f.pools[0], nil
+}
+
+func serveHorizonEntries(t *testing.T, handlers *HorizonCompatHandlers, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := mux.NewRouter()
+	sub := router.PathPrefix("/api/v1/horizon-compat").Subrouter()
+	sub.HandleFunc("/assets", handlers.HandleAssets).Methods("GET")
+	sub.HandleFunc("/claimable_balances/{id}", handlers.HandleClaimableBalance).Methods("GET")
+	sub.HandleFunc("/claimable_balances", handlers.HandleClaimableBalances).Methods("GET")
+	sub.HandleFunc("/liquidity_pools/{id}", handlers.HandleLiquidityPool).Methods("GET")
+	sub.HandleFunc("/liquidity_pools", handlers.HandleLiquidityPools).Methods("GET")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestHorizonAssetsParsesFiltersAndLinksNextPage(t *testing.T) {
+	t.Setenv("HORIZON_COMPAT_BASE_URL", "https://gateway.withobsrvr.com/lake/v1/testnet/api/v1/horizon-compat")
+	pt := "USDC_" + testHorizonUSDCIssuer + "_credit_alphanum4"
+	reader := &fakeHorizonAssetReader{stats: []protocol.AssetStat{{PT: pt}}}
+	handlers := &HorizonCompatHandlers{assetReader: reader}
+
+	rec := serveHorizonEntries(t, handlers, "/api/v1/horizon-compat/assets?asset_code=USDC&asset_issuer="+testHorizonUSDCIssuer+"&limit=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if reader.filters.Code != "USDC" || reader.filters.Issuer != testHorizonUSDCIssuer || reader.page.Limit != 1 {
+		t.Fatalf("filters = %+v, page = %+v", reader.filters, reader.page)
+	}
+	var body struct {
+		Links struct {
+			Next struct {
+				Href string `json:"href"`
+			} `json:"next"`
+		} `json:"_links"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	next, err := url.Parse(body.Links.Next.Href)
+	if err != nil || next.Query().Get("cursor") != pt || next.Query().Get("asset_code") != "USDC" {
+		t.Fatalf("next = %q, want cursor %q", body.Links.Next.Href, pt)
+	}
+
+	for _, target := range []string{
+		"/api/v1/horizon-compat/assets?asset_issuer=GNOTAKEY",
+		"/api/v1/horizon-compat/assets?cursor=12345",
+	} {
+		if rec := serveHorizonEntries(t, handlers, target); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want 400", target, rec.Code)
+		}
+	}
+}
+
+func TestHorizonClaimableBalancesFiltersAndLinks(t *testing.T) {
+	t.Setenv("HORIZON_COMPAT_BASE_URL", "https://gateway.withobsrvr.com/lake/v1/testnet/api/v1/horizon-compat")
+	id := horizonClaimableBalanceIDPrefix + testHorizonBalanceHash
+	reader := &fakeHorizonClaimableBalanceReader{balances: []protocol.ClaimableBalance{{BalanceID: id, PT: "100-" + id}}}
+	handlers := &HorizonCompatHandlers{claimableBalanceReader: reader}
+
+	rec := serveHorizonEntries(t, handlers, "/api/v1/horizon-compat/claimable_balances?claimant="+testHorizonUSDCIssuer+"&asset=USDC:"+testHorizonUSDCIssuer)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if reader.filters.Claimant != testHorizonUSDCIssuer || reader.filters.Asset == nil || reader.filters.Asset.Code != "USDC" {
+		t.Fatalf("filters = %+v", reader.filters)
+	}
+	var body struct {
+		Embedded struct {
+			Records []struct {
+				Links struct {
+					Self struct {
+						Href string `json:"href"`
+					} `json:"self"`
+					Operations struct {
+						Href      string `json:"href"`
+						Templated bool   `json:"templated"`
+					} `json:"operations"`
+				} `json:"_links"`
+			} `json:"records"`
+		} `json:"_embedded"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	links := body.Embedded.Records[0].Links
+	if links.Self.Href != "https://gateway.withobsrvr.com/lake/v1/testnet/api/v1/horizon-compat/claimable_balances/"+id {
+		t.Fatalf("self = %q", links.Self.Href)
+	}
+	if !links.Operations.Templated {
+		t.Fatalf("operations link = %+v", links.Operations)
+	}
+
+	for _, target := range []string{
+		"/api/v1/horizon-compat/claimable_balances?claimant=GNOTAKEY",
+		"/api/v1/horizon-compat/claimable_balances?asset=USDC",
+		"/api/v1/horizon-compat/claimable_balances?cursor=" + id,
+		"/api/v1/horizon-compat/claimable_balances/not-hex",
+	} {
+		if rec := serveHorizonEntries(t, handlers, target); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want 400", target, rec.Code)
+		}
+	}
+
+	reader.err = errHorizonClaimableBalanceNotFound
+	if rec := serveHorizonEntries(t, handlers, "/api/v1/horizon-compat/claimable_balances/"+id); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing balance status = %d, want 404", rec.Code)
+	}
+}
+
+func TestHorizonLiquidityPoolsParsesReserves(t *testing.T) {
+	reader := &fakeHorizonLiquidityPoolReader{pools: []protocol.LiquidityPool{{ID: testHorizonPoolID, PT: testHorizonPoolID}}}
+	handlers := &HorizonCompatHandlers{liquidityPoolReader: reader}
+
+	rec := serveHorizonEntries(t, handlers, "/api/v1/horizon-compat/liquidity_pools?reserves=native,USDC:"+testHorizonUSDCIssuer+"&cursor="+testHorizonPoolID+"&order=desc")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	reserves := reader.filters.Reserves
+	if len(reserves) != 2 || reserves[0].Type != "native" || reserves[1].Code != "USDC" || derefString(reserves[1].Issuer) != testHorizonUSDCIssuer {
+		t.Fatalf("reserves = %+v", reserves)
+	}
+	if reader.page.Cursor != testHorizonPoolID || reader.page.Order != "desc" {
+		t.Fatalf("page = %+v", reader.page)
+	}
+
+	rec = serveHorizonEntries(t, handlers, "/api/v1/horizon-compat/liquidity_pools/"+testHorizonPoolID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("detail status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var pool map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &pool); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if pool["paging_token"] != testHorizonPoolID {
+		t.Fatalf("pool = %#v", pool)
+	}
+
+	for _, target := range []string{
+		"/api/v1/horizon-compat/liquidity_pools?reserves=native,native,native",
+		"/api/v1/horizon-compat/liquidity_pools?reserves=USDC:GNOTAKEY",
+		"/api/v1/horizon-compat/liquidity_pools?account=GNOTAKEY",
+		"/api/v1/horizon-compat/liquidity_pools/123",
+	} {
+		if rec := serveHorizonEntries(t, handlers, target); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want 400", target, rec.Code)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -98,37 +99,31 @@ func (h *HorizonCompatHandlers) handleOperationCollection(w http.ResponseWriter,
 		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
 		return
 	}
-	cursor, err := decodeHorizonOperationCursor(page.Cursor)
-	if err != nil {
+	if _, err := decodeHorizonOperationCursor(page.Cursor); err != nil {
 		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
 		return
 	}
 
-	filters.Limit = int(page.Limit)
-	filters.Order = page.Order
-	filters.Cursor = cursor
+	if isHorizonStreamRequest(r) {
+		h.streamHorizonCollection(w, r, page, horizonTOIDNowCursor, func(ctx context.Context, page horizonPageQuery) ([]horizonPageable, error) {
+			records, err := h.operationRecords(ctx, r, filters, page)
+			return horizonPageables(records), err
+		})
+		return
+	}
 
 	ctx, cancel := withInteractiveQueryTimeout(r.Context())
 	defer cancel()
-	ops, _, _, err := h.operationReader.GetEnrichedOperationsWithCursor(ctx, filters)
+	records, err := h.operationRecords(ctx, r, filters, page)
 	if err != nil {
-		if isQueryTimeout(err) {
-			renderHorizonProblem(w, r, horizonProblem(http.StatusGatewayTimeout, "timeout", "Timeout", err.Error()))
-			return
-		}
-		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
 		return
 	}
 
-	records := make([]hoperations.Operation, 0, len(ops))
-	for _, op := range ops {
-		records = append(records, horizonOperationRecord(r, op, page.Order))
-	}
-
 	var firstCursor, lastCursor string
-	if len(ops) > 0 {
-		firstCursor = horizonOperationPagingToken(ops[0], page.Order)
-		lastCursor = horizonOperationPagingToken(ops[len(ops)-1], page.Order)
+	if len(records) > 0 {
+		firstCursor = records[0].PagingToken()
+		lastCursor = records[len(records)-1].PagingToken()
 	}
 
 	var out hoperations.OperationsPage
@@ -140,6 +135,26 @@ func (h *HorizonCompatHandlers) handleOperationCollection(w http.ResponseWriter,
 	}
 }
 
+func (h *HorizonCompatHandlers) operationRecords(ctx context.Context, r *http.Request, filters OperationFilters, page horizonPageQuery) ([]hoperations.Operation, error) {
+	cursor, err := decodeHorizonOperationCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	filters.Limit = int(page.Limit)
+	filters.Order = page.Order
+	filters.Cursor = cursor
+
+	ops, _, _, err := h.operationReader.GetEnrichedOperationsWithCursor(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]hoperations.Operation, 0, len(ops))
+	for _, op := range ops {
+		records = append(records, horizonOperationRecord(r, op, page.Order))
+	}
+	return records, nil
+}
+
 func (h *HorizonCompatHandlers) HandleEffects(w http.ResponseWriter, r *http.Request) {
 	h.handleEffectCollection(w, r, EffectFilters{})
 }
@@ -196,39 +211,31 @@ func (h *HorizonCompatHandlers) handleEffectCollection(w http.ResponseWriter, r
 		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
 		return
 	}
-	cursor, err := decodeHorizonEffectCursor(page.Cursor)
-	if err != nil {
+	if _, err := decodeHorizonEffectCursor(page.Cursor); err != nil {
 		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
 		return
 	}
 
-	filters.Limit = int(page.Limit)
-	filters.Order = page.Order
-	filters.Cursor = cursor
-	filters.HorizonOrder = true
-	filters.MaxEffectType = maxHorizonEffectType
+	if isHorizonStreamRequest(r) {
+		h.streamHorizonCollection(w, r, page, horizonEffectNowCursor, func(ctx context.Context, page horizonPageQuery) ([]horizonPageable, error) {
+			records, err := h.effectRecords(ctx, r, filters, page)
+			return horizonPageables(records), err
+		})
+		return
+	}
 
 	ctx, cancel := withInteractiveQueryTimeout(r.Context())
 	defer cancel()
-	effects, _, _, err := h.effectReader.GetEffects(ctx, filters)
+	records, err := h.effectRecords(ctx, r, filters, page)
 	if err != nil {
-		if isQueryTimeout(err) {
-			renderHorizonProblem(w, r, horizonProblem(http.StatusGatewayTimeout, "timeout", "Timeout", err.Error()))
-			return
-		}
-		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
 		return
 	}
 
-	records := make([]heffects.Effect, 0, len(effects))
-	for _, effect := range effects {
-		records = append(records, horizonEffectRecord(r, effect, page.Order))
-	}
-
 	var firstCursor, lastCursor string
-	if len(effects) > 0 {
-		firstCursor = horizonEffectPagingToken(effects[0], page.Order)
-		lastCursor = horizonEffectPagingToken(effects[len(effects)-1], page.Order)
+	if len(records) > 0 {
+		firstCursor = records[0].PagingToken()
+		lastCursor = records[len(records)-1].PagingToken()
 	}
 
 	var out heffects.EffectsPage
@@ -240,6 +247,28 @@ func (h *HorizonCompatHandlers) handleEffectCollection(w http.ResponseWriter, r
 	}
 }
 
+func (h *HorizonCompatHandlers) effectRecords(ctx context.Context, r *http.Request, filters EffectFilters, page horizonPageQuery) ([]heffects.Effect, error) {
+	cursor, err := decodeHorizonEffectCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	filters.Limit = int(page.Limit)
+	filters.Order = page.Order
+	filters.Cursor = cursor
+	filters.HorizonOrder = true
+	filters.MaxEffectType = maxHorizonEffectType
+
+	effects, _, _, err := h.effectReader.GetEffects(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]heffects.Effect, 0, len(effects))
+	for _, effect := range effects {
+		records = append(records, horizonEffectRecord(r, effect, page.Order))
+	}
+	return records, nil
+}
+
 func decodeHorizonOperationCursor(raw string) (*OperationCursor, error) {
 	if raw == "" {
 		return nil, nil
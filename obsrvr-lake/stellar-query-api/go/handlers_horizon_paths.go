@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/stellar/go-stellar-sdk/amount"
+	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+	hbase "github.com/stellar/go-stellar-sdk/protocols/horizon/base"
+	"github.com/stellar/go-stellar-sdk/strkey"
+)
+
+// horizonPathsPage is the body of both path endpoints. Horizon renders paths
+// as an unpaged collection, so there are no _links.
+type horizonPathsPage struct {
+	Embedded struct {
+		Records []protocol.Path `json:"records"`
+	} `json:"_embedded"`
+}
+
+func (h *HorizonCompatHandlers) HandleStrictSendPaths(w http.ResponseWriter, r *http.Request) {
+	if h.pathFinder == nil {
+		renderHorizonUnifiedUnavailable(w, r, "path finding endpoints")
+		return
+	}
+
+	source, err := parseHorizonAssetParam(r, "", "source")
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	if source == nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", "source asset is required"))
+		return
+	}
+	sourceAmount, err := parseHorizonPathAmount(r, "source_amount")
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	destinations, _, ok := h.horizonPathAssets(ctx, w, r, "destination_assets", "destination_account")
+	if !ok {
+		return
+	}
+
+	paths, err := h.pathFinder.FindStrictSendPaths(ctx, horizonAssetString(*source), sourceAmount, destinations)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+	writeHorizonPaths(w, r, paths)
+}
+
+func (h *HorizonCompatHandlers) HandleStrictReceivePaths(w http.ResponseWriter, r *http.Request) {
+	if h.pathFinder == nil {
+		renderHorizonUnifiedUnavailable(w, r, "path finding endpoints")
+		return
+	}
+
+	destination, err := parseHorizonAssetParam(r, "", "destination")
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+	if destination == nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", "destination asset is required"))
+		return
+	}
+	destinationAmount, err := parseHorizonPathAmount(r, "destination_amount")
+	if err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+		return
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	sources, balances, ok := h.horizonPathAssets(ctx, w, r, "source_assets", "source_account")
+	if !ok {
+		return
+	}
+
+	paths, err := h.pathFinder.FindStrictReceivePaths(ctx, sources, horizonAssetString(*destination), destinationAmount)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
+		return
+	}
+	// With source_account, Horizon only offers paths the sender can fund.
+	if balances != nil {
+		funded := paths[:0]
+		for _, path := range paths {
+			if path.SourceAmount <= balances[path.Source] {
+				funded = append(funded, path)
+			}
+		}
+		paths = funded
+	}
+	writeHorizonPaths(w, r, paths)
+}
+
+// horizonPathAssets resolves the other end of a path query from exactly one
+// of an explicit asset list or an account's balances. Balances, in stroops by
+// canonical asset, are returned only for the account form. On failure the
+// problem has already been written and ok is false.
+func (h *HorizonCompatHandlers) horizonPathAssets(ctx context.Context, w http.ResponseWriter, r *http.Request, listParam, accountParam string) ([]string, map[string]int64, bool) {
+	q := r.URL.Query()
+	rawList := strings.TrimSpace(q.Get(listParam))
+	accountID := strings.TrimSpace(q.Get(accountParam))
+	if (rawList == "") == (accountID == "") {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request",
+			fmt.Sprintf("exactly one of %s or %s is required", listParam, accountParam)))
+		return nil, nil, false
+	}
+
+	if rawList != "" {
+		assets, err := parseHorizonCanonicalAssets(rawList, listParam, maxHorizonPathAssets)
+		if err != nil {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
+			return nil, nil, false
+		}
+		out := make([]string, 0, len(assets))
+		for _, asset := range assets {
+			out = append(out, horizonAssetString(asset))
+		}
+		return out, nil, true
+	}
+
+	if !strkey.IsValidEd25519PublicKey(accountID) {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", fmt.Sprintf("invalid %s %q", accountParam, accountID)))
+		return nil, nil, false
+	}
+	if h.accountReader == nil {
+		renderHorizonUnifiedUnavailable(w, r, accountParam+" lookups")
+		return nil, nil, false
+	}
+	account, err := h.accountReader.GetHorizonAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, errHorizonAccountNotFound) {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusNotFound, "not_found", "Resource Missing", "Account not found."))
+		} else {
+			renderHorizonProblem(w, r, horizonQueryProblem(err))
+		}
+		return nil, nil, false
+	}
+	assets := []string{}
+	balances := map[string]int64{}
+	for _, balance := range account.Balances {
+		var asset string
+		switch balance.Asset.Type {
+		case "native":
+			asset = "native"
+		case "credit_alphanum4", "credit_alphanum12":
+			asset = balance.Asset.Code + ":" + balance.Asset.Issuer
+		default:
+			// Pool-share balances cannot be sent along a path.
+			continue
+		}
+		stroops, err := amount.ParseInt64(balance.Balance)
+		if err != nil {
+			renderHorizonProblem(w, r, horizonQueryProblem(fmt.Errorf("%s balance %q: %w", asset, balance.Balance, err)))
+			return nil, nil, false
+		}
+		if _, seen := balances[asset]; !seen {
+			assets = append(assets, asset)
+		}
+		balances[asset] = stroops
+	}
+	if len(assets) > maxHorizonPathAssets {
+		assets = assets[:maxHorizonPathAssets]
+	}
+	return assets, balances, true
+}
+
+func parseHorizonPathAmount(r *http.Request, name string) (int64, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(name))
+	if raw == "" {
+		return 0, fmt.Errorf("%s is required", name)
+	}
+	stroops, err := amount.ParseInt64(raw)
+	if err != nil || stroops <= 0 {
+		return 0, fmt.Errorf("%s must be a positive amount", name)
+	}
+	return stroops, nil
+}
+
+func writeHorizonPaths(w http.ResponseWriter, r *http.Request, paths []horizonPathResult) {
+	var out horizonPathsPage
+	out.Embedded.Records = make([]protocol.Path, 0, len(paths))
+	for _, path := range paths {
+		source := horizonPathAsset(path.Source)
+		destination := horizonPathAsset(path.Destination)
+		record := protocol.Path{
+			SourceAssetType:        source.Type,
+			SourceAssetCode:        source.Code,
+			SourceAssetIssuer:      source.Issuer,
+			SourceAmount:           formatStroops(path.SourceAmount),
+			DestinationAssetType:   destination.Type,
+			DestinationAssetCode:   destination.Code,
+			DestinationAssetIssuer: destination.Issuer,
+			DestinationAmount:      formatStroops(path.DestinationAmount),
+			Path:                   make([]protocol.Asset, 0, len(path.Path)),
+		}
+		for _, hop := range path.Path {
+			record.Path = append(record.Path, protocol.Asset(horizonPathAsset(hop)))
+		}
+		out.Embedded.Records = append(out.Embedded.Records, record)
+	}
+	if err := writeHorizonJSON(w, http.StatusOK, out); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+// horizonPathAsset expands a canonical asset string back into Horizon's
+// type/code/issuer form.
+func horizonPathAsset(canonical string) hbase.Asset {
+	code, issuer, ok := strings.Cut(canonical, ":")
+	if !ok {
+		return hbase.Asset{Type: "native"}
+	}
+	assetType := "credit_alphanum4"
+	if len(code) > 4 {
+		assetType = "credit_alphanum12"
+	}
+	return hbase.Asset{Type: assetType, Code: code, Issuer: issuer}
+}
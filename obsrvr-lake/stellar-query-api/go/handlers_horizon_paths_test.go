@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+	hbase "github.com/stellar/go-stellar-sdk/protocols/horizon/base"
+)
+
+func serveHorizonPaths(t *testing.T, handlers *HorizonCompatHandlers, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := mux.NewRouter()
+	sub := router.PathPrefix("/api/v1/horizon-compat").Subrouter()
+	sub.HandleFunc("/paths/strict-send", handlers.HandleStrictSendPaths).Methods("GET")
+	sub.HandleFunc("/paths/strict-receive", handlers.HandleStrictReceivePaths).Methods("GET")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestHorizonStrictSendPathsRendersHorizonShape(t *testing.T) {
+	handlers := &HorizonCompatHandlers{pathFinder: newTestHorizonPathFinder(testPathFixture())}
+
+	rec := serveHorizonPaths(t, handlers, "/api/v1/horizon-compat/paths/strict-send?source_asset_type=native&source_amount=0.0000300&destination_assets="+testPathEUR+","+testPathBTC)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Embedded struct {
+			Records []protocol.Path `json:"records"`
+		} `json:"_embedded"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	records := body.Embedded.Records
+	if len(records) != 2 {
+		t.Fatalf("records = %+v", records)
+	}
+	first := records[0]
+	if first.SourceAssetType != "native" || first.SourceAmount != "0.0000300" ||
+		first.DestinationAssetCode != "EUR" || first.DestinationAssetIssuer != testHorizonUSDCIssuer ||
+		first.DestinationAmount != "0.0000266" {
+		t.Fatalf("first = %+v", first)
+	}
+	if len(first.Path) != 1 || first.Path[0].Code != "USDC" || first.Path[0].Type != "credit_alphanum4" {
+		t.Fatalf("path = %+v", first.Path)
+	}
+	if records[1].DestinationAssetCode != "BTC" || records[1].DestinationAmount != "0.0000117" {
+		t.Fatalf("second = %+v", records[1])
+	}
+}
+
+func TestHorizonStrictReceivePathsFilterBySourceAccountBalance(t *testing.T) {
+	accounts := &fakeHorizonAccountReader{account: &protocol.Account{Balances: []protocol.Balance{
+		{Balance: "0.0000298", Asset: hbase.Asset{Type: "native"}},
+	}}}
+	handlers := &HorizonCompatHandlers{pathFinder: newTestHorizonPathFinder(testPathFixture()), accountReader: accounts}
+	target := "/api/v1/horizon-compat/paths/strict-receive?destination_asset_type=credit_alphanum4&destination_asset_code=EUR&destination_asset_issuer=" +
+		testHorizonUSDCIssuer + "&destination_amount=0.0000266&source_account=" + testHorizonUSDCIssuer
+
+	// The only path costs 299 stroops of XLM, one more than the account holds.
+	rec := serveHorizonPaths(t, handlers, target)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if accounts.accountID != testHorizonUSDCIssuer {
+		t.Fatalf("accountID = %q", accounts.accountID)
+	}
+	var body horizonPathsPage
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if len(body.Embedded.Records) != 0 {
+		t.Fatalf("records = %+v", body.Embedded.Records)
+	}
+
+	accounts.account.Balances[0].Balance = "0.0000299"
+	rec = serveHorizonPaths(t, handlers, target)
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if len(body.Embedded.Records) != 1 || body.Embedded.Records[0].SourceAmount != "0.0000299" {
+		t.Fatalf("records = %+v", body.Embedded.Records)
+	}
+}
+
+func TestHorizonPathsRejectBadParameters(t *testing.T) {
+	handlers := &HorizonCompatHandlers{pathFinder: newTestHorizonPathFinder(testPathFixture())}
+	for _, target := range []string{
+		"/api/v1/horizon-compat/paths/strict-send?source_amount=1&destination_assets=native",
+		"/api/v1/horizon-compat/paths/strict-send?source_asset_type=native&destination_assets=native",
+		"/api/v1/horizon-compat/paths/strict-send?source_asset_type=native&source_amount=-1&destination_assets=native",
+		"/api/v1/horizon-compat/paths/strict-send?source_asset_type=native&source_amount=1",
+		"/api/v1/horizon-compat/paths/strict-send?source_asset_type=native&source_amount=1&destination_assets=native&destination_account=" + testHorizonUSDCIssuer,
+		"/api/v1/horizon-compat/paths/strict-receive?destination_asset_type=native&destination_amount=1&source_assets=USDC:GNOTAKEY",
+		"/api/v1/horizon-compat/paths/strict-receive?destination_asset_type=native&destination_amount=1&source_account=GNOTAKEY",
+	} {
+		if rec := serveHorizonPaths(t, handlers, target); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want 400", target, rec.Code)
+		}
+	}
+
+	if rec := serveHorizonPaths(t, &HorizonCompatHandlers{}, "/api/v1/horizon-compat/paths/strict-send"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("no finder status = %d, want 503", rec.Code)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/gorilla/mux"
 	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+	"github.com/stellar/go-stellar-sdk/support/render/problem"
 )
 
 func (h *HorizonCompatHandlers) HandleAccount(w http.ResponseWriter, r *http.Request) {
@@ -69,13 +70,69 @@ func (h *HorizonCompatHandlers) HandleAccountTransactions(w http.ResponseWriter,
 		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
 		return
 	}
-	cursor, err := decodeHorizonHistoryCursor(page.Cursor)
-	if err != nil {
+	if _, err := decodeHorizonHistoryCursor(page.Cursor); err != nil {
 		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", err.Error()))
 		return
 	}
 
 	accountID := mux.Vars(r)["id"]
+	if isHorizonStreamRequest(r) {
+		h.streamHorizonCollection(w, r, page, horizonTOIDNowCursor, func(ctx context.Context, page horizonPageQuery) ([]horizonPageable, error) {
+			records, _, err := h.accountTransactionRecords(ctx, r, accountID, page)
+			return horizonPageables(records), err
+		})
+		return
+	}
+
+	ctx, cancel := withInteractiveQueryTimeout(r.Context())
+	defer cancel()
+	records, rows, err := h.accountTransactionRecords(ctx, r, accountID, page)
+	if err != nil {
+		renderHorizonProblem(w, r, horizonAccountTransactionsProblem(err))
+		return
+	}
+
+	var firstCursor, lastCursor string
+	if len(rows) > 0 {
+		firstCursor = horizonAccountTransactionPagingToken(rows[0], page.Order)
+		lastCursor = horizonAccountTransactionPagingToken(rows[len(rows)-1], page.Order)
+	}
+
+	var out protocol.TransactionsPage
+	out.Links = horizonCompatCollectionLinks(r, page, firstCursor, lastCursor)
+	out.Embedded.Records = records
+	if err := writeHorizonJSON(w, http.StatusOK, out); err != nil {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+	}
+}
+
+// horizonTransactionHydrationError marks failures that happened while loading
+// a transaction's XDR after the account history row was found.
+type horizonTransactionHydrationError struct {
+	err error
+}
+
+func (e horizonTransactionHydrationError) Error() string { return e.err.Error() }
+func (e horizonTransactionHydrationError) Unwrap() error { return e.err }
+
+func horizonAccountTransactionsProblem(err error) problem.P {
+	var hydrateErr horizonTransactionHydrationError
+	if !errors.As(err, &hydrateErr) {
+		return horizonQueryProblem(err)
+	}
+	switch {
+	case isQueryTimeout(err), errors.Is(err, errHorizonTransactionXDRUnavailable), errors.Is(err, errHorizonTransactionReaderUnavailable), errors.Is(err, errHorizonTransactionNotFound):
+		return horizonProblem(http.StatusServiceUnavailable, "data_unavailable", "Data Unavailable", err.Error())
+	default:
+		return horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error())
+	}
+}
+
+func (h *HorizonCompatHandlers) accountTransactionRecords(ctx context.Context, r *http.Request, accountID string, page horizonPageQuery) ([]protocol.Transaction, []AccountTransaction, error) {
+	cursor, err := decodeHorizonHistoryCursor(page.Cursor)
+	if err != nil {
+		return nil, nil, err
+	}
 	filters := AccountTransactionsFilters{
 		AccountID: accountID,
 		Limit:     int(page.Limit),
@@ -83,16 +140,9 @@ func (h *HorizonCompatHandlers) HandleAccountTransactions(w http.ResponseWriter,
 		Cursor:    cursor,
 	}
 
-	ctx, cancel := withInteractiveQueryTimeout(r.Context())
-	defer cancel()
 	rows, _, _, _, err := h.accountTransactionReader.GetAccountTransactions(ctx, filters)
 	if err != nil {
-		if isQueryTimeout(err) {
-			renderHorizonProblem(w, r, horizonProblem(http.StatusGatewayTimeout, "timeout", "Timeout", err.Error()))
-			return
-		}
-		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
-		return
+		return nil, nil, err
 	}
 
 	records := make([]protocol.Transaction, 0, len(rows))
@@ -107,30 +157,12 @@ func (h *HorizonCompatHandlers) HandleAccountTransactions(w http.ResponseWriter,
 		}
 		hydrateCancel()
 		if err != nil {
-			switch {
-			case isQueryTimeout(err), errors.Is(err, errHorizonTransactionXDRUnavailable), errors.Is(err, errHorizonTransactionReaderUnavailable), errors.Is(err, errHorizonTransactionNotFound):
-				renderHorizonProblem(w, r, horizonProblem(http.StatusServiceUnavailable, "data_unavailable", "Data Unavailable", err.Error()))
-			default:
-				renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
-			}
-			return
+			return nil, nil, horizonTransactionHydrationError{err: err}
 		}
 		populateHorizonTransactionLinks(r, tx)
 		records = append(records, *tx)
 	}
-
-	var firstCursor, lastCursor string
-	if len(rows) > 0 {
-		firstCursor = horizonAccountTransactionPagingToken(rows[0], page.Order)
-		lastCursor = horizonAccountTransactionPagingToken(rows[len(rows)-1], page.Order)
-	}
-
-	var out protocol.TransactionsPage
-	out.Links = horizonCompatCollectionLinks(r, page, firstCursor, lastCursor)
-	out.Embedded.Records = records
-	if err := writeHorizonJSON(w, http.StatusOK, out); err != nil {
-		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
-	}
+	return records, rows, nil
 }
 
 func horizonAccountTransactionHydrationTimeout() time.Duration {
@@ -193,21 +225,22 @@ func (h *HorizonCompatHandlers) HandleLedgers(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if isHorizonStreamRequest(r) {
+		h.streamHorizonCollection(w, r, page, horizonLedgerNowCursor, func(ctx context.Context, page horizonPageQuery) ([]horizonPageable, error) {
+			records, err := h.ledgerRecords(ctx, r, page)
+			return horizonPageables(records), err
+		})
+		return
+	}
+
 	ctx, cancel := withInteractiveQueryTimeout(r.Context())
 	defer cancel()
-	records, err := h.ledgerReader.GetLedgers(ctx, page)
+	records, err := h.ledgerRecords(ctx, r, page)
 	if err != nil {
-		if isQueryTimeout(err) {
-			renderHorizonProblem(w, r, horizonProblem(http.StatusGatewayTimeout, "timeout", "Timeout", err.Error()))
-			return
-		}
-		renderHorizonProblem(w, r, horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error()))
+		renderHorizonProblem(w, r, horizonQueryProblem(err))
 		return
 	}
 
-	for i := range records {
-		populateHorizonLedgerLinks(r, &records[i])
-	}
 	var firstCursor, lastCursor string
 	if len(records) > 0 {
 		firstCursor = records[0].PT
@@ -222,6 +255,17 @@ func (h *HorizonCompatHandlers) HandleLedgers(w http.ResponseWriter, r *http.Req
 	}
 }
 
+func (h *HorizonCompatHandlers) ledgerRecords(ctx context.Context, r *http.Request, page horizonPageQuery) ([]protocol.Ledger, error) {
+	records, err := h.ledgerReader.GetLedgers(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		populateHorizonLedgerLinks(r, &records[i])
+	}
+	return records, nil
+}
+
 func (h *HorizonCompatHandlers) HandleFeeStats(w http.ResponseWriter, r *http.Request) {
 	if h.feeStatsReader == nil {
 		renderHorizonProblem(w, r, horizonProblem(
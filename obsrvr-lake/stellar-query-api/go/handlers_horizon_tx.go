@@ -18,6 +18,13 @@ type HorizonCompatHandlers struct {
 	feeStatsReader           horizonFeeStatsReader
 	operationReader          horizonOperationReader
 	effectReader             horizonEffectReader
+	offerReader              horizonOfferReader
+	tradeReader              horizonTradeReader
+	assetReader              horizonAssetReader
+	claimableBalanceReader   horizonClaimableBalanceReader
+	liquidityPoolReader      horizonLiquidityPoolReader
+	pathFinder               *horizonPathFinder
+	ledgerWatcher            *horizonLedgerWatcher
 }
 
 type horizonTransactionReader interface {
@@ -36,6 +43,31 @@ type horizonEffectReader interface {
 	GetEffects(context.Context, EffectFilters) ([]SilverEffect, string, bool, error)
 }
 
+type horizonOfferReader interface {
+	GetOffers(context.Context, OfferFilters) ([]OfferCurrent, string, bool, error)
+	GetOfferByID(context.Context, int64) (*OfferCurrent, error)
+	GetOrderBookLevels(context.Context, AssetInfo, AssetInfo, int) ([]OrderBookLevel, error)
+}
+
+type horizonTradeReader interface {
+	GetTrades(context.Context, TradeFilters) ([]SilverTrade, string, bool, error)
+	GetTradeAggregations(context.Context, TradeAggregationFilters) ([]TradeAggregationBucket, error)
+}
+
+type horizonAssetReader interface {
+	GetAssetStats(context.Context, HorizonAssetFilters, horizonPageQuery) ([]protocol.AssetStat, error)
+}
+
+type horizonClaimableBalanceReader interface {
+	GetClaimableBalances(context.Context, HorizonClaimableBalanceFilters, horizonPageQuery) ([]protocol.ClaimableBalance, error)
+	GetClaimableBalance(context.Context, string) (*protocol.ClaimableBalance, error)
+}
+
+type horizonLiquidityPoolReader interface {
+	GetLiquidityPools(context.Context, HorizonLiquidityPoolFilters, horizonPageQuery) ([]protocol.LiquidityPool, error)
+	GetLiquidityPool(context.Context, string) (*protocol.LiquidityPool, error)
+}
+
 type horizonAccountReader interface {
 	GetHorizonAccount(context.Context, string) (*protocol.Account, error)
 }
@@ -54,6 +86,24 @@ type horizonFeeStatsReader interface {
 }
 
 func NewHorizonCompatHandlers(app *application) *HorizonCompatHandlers {
+	var latestLedgerSource horizonLatestLedgerSource
+	if app.hotReader != nil {
+		latestLedgerSource = app.hotReader
+	}
+	var offerReader horizonOfferReader
+	var tradeReader horizonTradeReader
+	var assetReader horizonAssetReader
+	var claimableBalanceReader horizonClaimableBalanceReader
+	var liquidityPoolReader horizonLiquidityPoolReader
+	var pathFinder *horizonPathFinder
+	if app.unifiedDuckDBReader != nil {
+		offerReader = app.unifiedDuckDBReader
+		tradeReader = app.unifiedDuckDBReader
+		assetReader = NewHorizonAssetReader(app.unifiedDuckDBReader)
+		claimableBalanceReader = NewHorizonClaimableBalanceReader(app.unifiedDuckDBReader)
+		liquidityPoolReader = NewHorizonLiquidityPoolReader(app.unifiedDuckDBReader)
+		pathFinder = newHorizonPathFinder(app.unifiedDuckDBReader)
+	}
 	return &HorizonCompatHandlers{
 		txReader:                 NewHorizonTransactionReader(app.hotReader, app.coldReader, app.indexReader, app.silverHotReader),
 		accountReader:            NewHorizonAccountReader(app.silverHotReader, app.unifiedDuckDBReader),
@@ -62,6 +112,13 @@ func NewHorizonCompatHandlers(app *application) *HorizonCompatHandlers {
 		feeStatsReader:           NewHorizonFeeStatsReader(app.hotReader, app.coldReader),
 		operationReader:          NewHorizonOperationReader(app.unifiedDuckDBReader, app.silverHotReader),
 		effectReader:             NewHorizonEffectReader(app.unifiedDuckDBReader, app.silverHotReader),
+		offerReader:              offerReader,
+		tradeReader:              tradeReader,
+		assetReader:              assetReader,
+		claimableBalanceReader:   claimableBalanceReader,
+		liquidityPoolReader:      liquidityPoolReader,
+		pathFinder:               pathFinder,
+		ledgerWatcher:            newHorizonLedgerWatcher(latestLedgerSource, horizonStreamPollInterval()),
 	}
 }
 
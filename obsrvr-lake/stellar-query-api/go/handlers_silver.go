@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -20,6 +19,9 @@ type SilverHandlers struct {
 	legacyReader  *UnifiedSilverReader
 	unifiedReader *UnifiedDuckDBReader
 	readerMode    ReaderMode
+	// shadow compares a sample of hybrid-mode requests against the unified
+	// reader in the background; nil outside hybrid mode.
+	shadow *hybridShadow
 }
 
 // NewSilverHandlers creates new Silver API handlers with reader mode support
@@ -45,16 +47,6 @@ func normalizeTTLEntriesForCurrentLedger(entries []TTLEntry, currentLedger int64
 	}
 }
 
-// logMismatch logs when legacy and unified reader results differ (for hybrid mode validation)
-func logMismatch(endpoint string, legacyCount, unifiedCount int, details string) {
-	log.Printf("⚠️ HYBRID MISMATCH [%s]: legacy=%d, unified=%d | %s", endpoint, legacyCount, unifiedCount, details)
-}
-
-// logHybridMatch logs when legacy and unified reader results match (for hybrid mode validation)
-func logHybridMatch(endpoint string, count int) {
-	log.Printf("✅ HYBRID MATCH [%s]: count=%d", endpoint, count)
-}
-
 // ============================================
 // ACCOUNT ENDPOINTS
 // ============================================
@@ -95,29 +87,11 @@ func (h *SilverHandlers) HandleAccountCurrent(w http.ResponseWriter, r *http.Req
 	case ReaderModeUnified:
 		account, err = h.unifiedReader.GetAccountCurrent(r.Context(), accountID)
 	case ReaderModeHybrid:
-		// Run both, compare, return legacy
-		legacyAccount, legacyErr := h.legacyReader.GetAccountCurrent(r.Context(), accountID)
-		unifiedAccount, unifiedErr := h.unifiedReader.GetAccountCurrent(r.Context(), accountID)
-
-		if legacyErr != nil && unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleAccountCurrent]: both failed - legacy: %v, unified: %v", legacyErr, unifiedErr)
-		} else if legacyErr != nil {
-			log.Printf("⚠️ HYBRID [HandleAccountCurrent]: legacy failed: %v, unified succeeded", legacyErr)
-		} else if unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleAccountCurrent]: unified failed: %v, legacy succeeded", unifiedErr)
-		} else {
-			// Both succeeded, compare results
-			legacyNil := legacyAccount == nil
-			unifiedNil := unifiedAccount == nil
-			if legacyNil != unifiedNil {
-				logMismatch("HandleAccountCurrent", boolToInt(!legacyNil), boolToInt(!unifiedNil), "account_id="+accountID)
-			} else if !legacyNil && !reflect.DeepEqual(legacyAccount, unifiedAccount) {
-				logMismatch("HandleAccountCurrent", 1, 1, "account_id="+accountID+" (content differs)")
-			} else {
-				logHybridMatch("HandleAccountCurrent", boolToInt(!legacyNil))
-			}
-		}
-		account, err = legacyAccount, legacyErr
+		account, err = h.legacyReader.GetAccountCurrent(r.Context(), accountID)
+		shadowCompare(h.shadow, "HandleAccountCurrent", "account_id="+accountID, account, err,
+			func(ctx context.Context) (*AccountCurrent, error) {
+				return h.unifiedReader.GetAccountCurrent(ctx, accountID)
+			})
 	default: // ReaderModeLegacy
 		account, err = h.legacyReader.GetAccountCurrent(r.Context(), accountID)
 	}
@@ -180,23 +154,13 @@ func (h *SilverHandlers) HandleAccountHistory(w http.ResponseWriter, r *http.Req
 		history, nextCursor, hasMore, coverage, err = h.unifiedReader.GetAccountHistoryWithCursorAndCoverage(queryCtx, accountID, limit, cursor)
 		accountIndexCoverage = &coverage
 	case ReaderModeHybrid:
-		legacyHistory, legacyNextCursor, legacyHasMore, legacyErr := h.legacyReader.GetAccountHistoryWithCursor(queryCtx, accountID, limit, cursor)
-		unifiedHistory, _, _, unifiedErr := h.unifiedReader.GetAccountHistoryWithCursor(queryCtx, accountID, limit, cursor)
-
-		if legacyErr != nil && unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleAccountHistory]: both failed - legacy: %v, unified: %v", legacyErr, unifiedErr)
-		} else if legacyErr != nil {
-			log.Printf("⚠️ HYBRID [HandleAccountHistory]: legacy failed: %v, unified succeeded with %d results", legacyErr, len(unifiedHistory))
-		} else if unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleAccountHistory]: unified failed: %v, legacy succeeded with %d results", unifiedErr, len(legacyHistory))
-		} else {
-			if len(legacyHistory) != len(unifiedHistory) {
-				logMismatch("HandleAccountHistory", len(legacyHistory), len(unifiedHistory), "account_id="+accountID)
-			} else {
-				logHybridMatch("HandleAccountHistory", len(legacyHistory))
-			}
-		}
-		history, nextCursor, hasMore, err = legacyHistory, legacyNextCursor, legacyHasMore, legacyErr
+		history, nextCursor, hasMore, err = h.legacyReader.GetAccountHistoryWithCursor(queryCtx, accountID, limit, cursor)
+		shadowCompare(h.shadow, "HandleAccountHistory", "account_id="+accountID,
+			hybridPage[[]AccountSnapshot]{history, nextCursor, hasMore}, err,
+			func(ctx context.Context) (hybridPage[[]AccountSnapshot], error) {
+				items, next, more, err := h.unifiedReader.GetAccountHistoryWithCursor(ctx, accountID, limit, cursor)
+				return hybridPage[[]AccountSnapshot]{items, next, more}, err
+			})
 	default: // ReaderModeLegacy
 		history, nextCursor, hasMore, err = h.legacyReader.GetAccountHistoryWithCursor(queryCtx, accountID, limit, cursor)
 	}
@@ -275,23 +239,11 @@ func (h *SilverHandlers) HandleTopAccounts(w http.ResponseWriter, r *http.Reques
 	case ReaderModeUnified:
 		accounts, err = h.unifiedReader.GetTopAccounts(r.Context(), limit)
 	case ReaderModeHybrid:
-		legacyAccounts, legacyErr := h.legacyReader.GetTopAccounts(r.Context(), limit)
-		unifiedAccounts, unifiedErr := h.unifiedReader.GetTopAccounts(r.Context(), limit)
-
-		if legacyErr != nil && unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleTopAccounts]: both failed - legacy: %v, unified: %v", legacyErr, unifiedErr)
-		} else if legacyErr != nil {
-			log.Printf("⚠️ HYBRID [HandleTopAccounts]: legacy failed: %v, unified succeeded with %d results", legacyErr, len(unifiedAccounts))
-		} else if unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleTopAccounts]: unified failed: %v, legacy succeeded with %d results", unifiedErr, len(legacyAccounts))
-		} else {
-			if len(legacyAccounts) != len(unifiedAccounts) {
-				logMismatch("HandleTopAccounts", len(legacyAccounts), len(unifiedAccounts), "limit="+strconv.Itoa(limit))
-			} else {
-				logHybridMatch("HandleTopAccounts", len(legacyAccounts))
-			}
-		}
-		accounts, err = legacyAccounts, legacyErr
+		accounts, err = h.legacyReader.GetTopAccounts(r.Context(), limit)
+		shadowCompare(h.shadow, "HandleTopAccounts", "limit="+strconv.Itoa(limit), accounts, err,
+			func(ctx context.Context) ([]AccountCurrent, error) {
+				return h.unifiedReader.GetTopAccounts(ctx, limit)
+			})
 	default: // ReaderModeLegacy
 		accounts, err = h.legacyReader.GetTopAccounts(r.Context(), limit)
 	}
@@ -378,23 +330,14 @@ func (h *SilverHandlers) HandleListAccounts(w http.ResponseWriter, r *http.Reque
 	case ReaderModeUnified:
 		accounts, nextCursor, hasMore, err = h.unifiedReader.GetAccountsListWithCursor(queryCtx, filters)
 	case ReaderModeHybrid:
-		legacyAccounts, legacyNextCursor, legacyHasMore, legacyErr := h.legacyReader.GetAccountsListWithCursor(queryCtx, filters)
-		unifiedAccounts, _, _, unifiedErr := h.unifiedReader.GetAccountsListWithCursor(queryCtx, filters)
-
-		if legacyErr != nil && unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleListAccounts]: both failed - legacy: %v, unified: %v", legacyErr, unifiedErr)
-		} else if legacyErr != nil {
-			log.Printf("⚠️ HYBRID [HandleListAccounts]: legacy failed: %v, unified succeeded with %d results", legacyErr, len(unifiedAccounts))
-		} else if unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleListAccounts]: unified failed: %v, legacy succeeded with %d results", unifiedErr, len(legacyAccounts))
-		} else {
-			if len(legacyAccounts) != len(unifiedAccounts) {
-				logMismatch("HandleListAccounts", len(legacyAccounts), len(unifiedAccounts), "sort="+filters.SortBy+":"+filters.SortOrder)
-			} else {
-				logHybridMatch("HandleListAccounts", len(legacyAccounts))
-			}
-		}
-		accounts, nextCursor, hasMore, err = legacyAccounts, legacyNextCursor, legacyHasMore, legacyErr
+		accounts, nextCursor, hasMore, err = h.legacyReader.GetAccountsListWithCursor(queryCtx, filters)
+		shadowFilters := filters
+		shadowCompare(h.shadow, "HandleListAccounts", "sort="+filters.SortBy+":"+filters.SortOrder,
+			hybridPage[[]AccountCurrent]{accounts, nextCursor, hasMore}, err,
+			func(ctx context.Context) (hybridPage[[]AccountCurrent], error) {
+				items, next, more, err := h.unifiedReader.GetAccountsListWithCursor(ctx, shadowFilters)
+				return hybridPage[[]AccountCurrent]{items, next, more}, err
+			})
 	default: // ReaderModeLegacy
 		accounts, nextCursor, hasMore, err = h.legacyReader.GetAccountsListWithCursor(queryCtx, filters)
 	}
@@ -1255,23 +1198,14 @@ func (h *SilverHandlers) HandleEnrichedOperations(w http.ResponseWriter, r *http
 	case ReaderModeUnified:
 		operations, nextCursor, hasMore, err = h.unifiedReader.GetEnrichedOperationsWithCursor(queryCtx, filters)
 	case ReaderModeHybrid:
-		legacyOps, legacyNextCursor, legacyHasMore, legacyErr := h.legacyReader.GetEnrichedOperationsWithCursor(queryCtx, filters)
-		unifiedOps, _, _, unifiedErr := h.unifiedReader.GetEnrichedOperationsWithCursor(queryCtx, filters)
-
-		if legacyErr != nil && unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleEnrichedOperations]: both failed - legacy: %v, unified: %v", legacyErr, unifiedErr)
-		} else if legacyErr != nil {
-			log.Printf("⚠️ HYBRID [HandleEnrichedOperations]: legacy failed: %v, unified succeeded with %d results", legacyErr, len(unifiedOps))
-		} else if unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleEnrichedOperations]: unified failed: %v, legacy succeeded with %d results", unifiedErr, len(legacyOps))
-		} else {
-			if len(legacyOps) != len(unifiedOps) {
-				logMismatch("HandleEnrichedOperations", len(legacyOps), len(unifiedOps), "account_id="+filters.AccountID)
-			} else {
-				logHybridMatch("HandleEnrichedOperations", len(legacyOps))
-			}
-		}
-		operations, nextCursor, hasMore, err = legacyOps, legacyNextCursor, legacyHasMore, legacyErr
+		operations, nextCursor, hasMore, err = h.legacyReader.GetEnrichedOperationsWithCursor(queryCtx, filters)
+		shadowFilters := filters
+		shadowCompare(h.shadow, "HandleEnrichedOperations", "account_id="+filters.AccountID,
+			hybridPage[[]EnrichedOperation]{operations, nextCursor, hasMore}, err,
+			func(ctx context.Context) (hybridPage[[]EnrichedOperation], error) {
+				items, next, more, err := h.unifiedReader.GetEnrichedOperationsWithCursor(ctx, shadowFilters)
+				return hybridPage[[]EnrichedOperation]{items, next, more}, err
+			})
 	default: // ReaderModeLegacy
 		operations, nextCursor, hasMore, err = h.legacyReader.GetEnrichedOperationsWithCursor(queryCtx, filters)
 	}
@@ -1763,29 +1697,18 @@ func (h *SilverHandlers) HandleTokenTransfers(w http.ResponseWriter, r *http.Req
 			transfers, nextCursor, hasMore, err = h.unifiedReader.GetTokenTransfersWithCursor(r.Context(), filters)
 		}
 	case ReaderModeHybrid:
-		legacyTransfers, legacyNextCursor, legacyHasMore, legacyErr := h.legacyReader.GetTokenTransfersWithCursor(r.Context(), filters)
-		var unifiedTransfers []TokenTransfer
-		var unifiedErr error
-		if !recentQuery {
-			unifiedTransfers, _, _, unifiedErr = h.unifiedReader.GetTokenTransfersWithCursor(r.Context(), filters)
-		}
-
+		transfers, nextCursor, hasMore, err = h.legacyReader.GetTokenTransfersWithCursor(r.Context(), filters)
+		// Recent windows are served from silver_hot in every mode, so there
+		// is nothing to compare.
 		if !recentQuery {
-			if legacyErr != nil && unifiedErr != nil {
-				log.Printf("⚠️ HYBRID [HandleTokenTransfers]: both failed - legacy: %v, unified: %v", legacyErr, unifiedErr)
-			} else if legacyErr != nil {
-				log.Printf("⚠️ HYBRID [HandleTokenTransfers]: legacy failed: %v, unified succeeded with %d results", legacyErr, len(unifiedTransfers))
-			} else if unifiedErr != nil {
-				log.Printf("⚠️ HYBRID [HandleTokenTransfers]: unified failed: %v, legacy succeeded with %d results", unifiedErr, len(legacyTransfers))
-			} else {
-				if len(legacyTransfers) != len(unifiedTransfers) {
-					logMismatch("HandleTokenTransfers", len(legacyTransfers), len(unifiedTransfers), "asset="+filters.AssetCode)
-				} else {
-					logHybridMatch("HandleTokenTransfers", len(legacyTransfers))
-				}
-			}
+			shadowFilters := filters
+			shadowCompare(h.shadow, "HandleTokenTransfers", "asset="+filters.AssetCode,
+				hybridPage[[]TokenTransfer]{transfers, nextCursor, hasMore}, err,
+				func(ctx context.Context) (hybridPage[[]TokenTransfer], error) {
+					items, next, more, err := h.unifiedReader.GetTokenTransfersWithCursor(ctx, shadowFilters)
+					return hybridPage[[]TokenTransfer]{items, next, more}, err
+				})
 		}
-		transfers, nextCursor, hasMore, err = legacyTransfers, legacyNextCursor, legacyHasMore, legacyErr
 	default: // ReaderModeLegacy
 		transfers, nextCursor, hasMore, err = h.legacyReader.GetTokenTransfersWithCursor(r.Context(), filters)
 	}
@@ -1876,23 +1799,11 @@ func (h *SilverHandlers) HandleTokenTransferStats(w http.ResponseWriter, r *http
 	case ReaderModeUnified:
 		stats, err = h.unifiedReader.GetTokenTransferStats(ctx, groupBy, startTime, endTime)
 	case ReaderModeHybrid:
-		legacyStats, legacyErr := h.legacyReader.GetTokenTransferStats(ctx, groupBy, startTime, endTime)
-		unifiedStats, unifiedErr := h.unifiedReader.GetTokenTransferStats(ctx, groupBy, startTime, endTime)
-
-		if legacyErr != nil && unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleTokenTransferStats]: both failed - legacy: %v, unified: %v", legacyErr, unifiedErr)
-		} else if legacyErr != nil {
-			log.Printf("⚠️ HYBRID [HandleTokenTransferStats]: legacy failed: %v, unified succeeded with %d results", legacyErr, len(unifiedStats))
-		} else if unifiedErr != nil {
-			log.Printf("⚠️ HYBRID [HandleTokenTransferStats]: unified failed: %v, legacy succeeded with %d results", unifiedErr, len(legacyStats))
-		} else {
-			if len(legacyStats) != len(unifiedStats) {
-				logMismatch("HandleTokenTransferStats", len(legacyStats), len(unifiedStats), "group_by="+groupBy)
-			} else {
-				logHybridMatch("HandleTokenTransferStats", len(legacyStats))
-			}
-		}
-		stats, err = legacyStats, legacyErr
+		stats, err = h.legacyReader.GetTokenTransferStats(ctx, groupBy, startTime, endTime)
+		shadowCompare(h.shadow, "HandleTokenTransferStats", "group_by="+groupBy, stats, err,
+			func(ctx context.Context) ([]TransferStats, error) {
+				return h.unifiedReader.GetTokenTransferStats(ctx, groupBy, startTime, endTime)
+			})
 	default: // ReaderModeLegacy
 		stats, err = h.legacyReader.GetTokenTransferStats(ctx, groupBy, startTime, endTime)
 	}
@@ -2013,19 +1924,11 @@ func (h *SilverHandlers) HandleAccountOverview(w http.ResponseWriter, r *http.Re
 		}
 
 	case ReaderModeHybrid:
-		// Run both for validation, return legacy
-		legacyAccount, legacyErr := h.legacyReader.GetAccountCurrent(ctx, accountID)
-		unifiedAccount, unifiedErr := h.unifiedReader.GetAccountCurrent(ctx, accountID)
-		if legacyErr == nil && unifiedErr == nil {
-			legacyNil := legacyAccount == nil
-			unifiedNil := unifiedAccount == nil
-			if legacyNil != unifiedNil {
-				logMismatch("HandleAccountOverview", boolToInt(!legacyNil), boolToInt(!unifiedNil), "account_id="+accountID)
-			} else {
-				logHybridMatch("HandleAccountOverview", boolToInt(!legacyNil))
-			}
-		}
-		account, err = legacyAccount, legacyErr
+		account, err = h.legacyReader.GetAccountCurrent(ctx, accountID)
+		shadowCompare(h.shadow, "HandleAccountOverview", "account_id="+accountID, account, err,
+			func(ctx context.Context) (*AccountCurrent, error) {
+				return h.unifiedReader.GetAccountCurrent(ctx, accountID)
+			})
 		if err != nil {
 			if isQueryTimeout(err) {
 				respondQueryTimeout(w, "account overview")
@@ -2278,23 +2181,15 @@ func (h *SilverHandlers) HandleTransactionDetails(w http.ResponseWriter, r *http
 		})
 
 	case ReaderModeHybrid:
-		// Run both for validation, return legacy
-		legacyOps, legacyErr := h.legacyReader.GetEnrichedOperations(r.Context(), OperationFilters{
+		txFilters := OperationFilters{
 			TxHash: txHash,
 			Limit:  100,
-		})
-		unifiedOps, unifiedErr := h.unifiedReader.GetEnrichedOperations(r.Context(), OperationFilters{
-			TxHash: txHash,
-			Limit:  100,
-		})
-		if legacyErr == nil && unifiedErr == nil {
-			if len(legacyOps) != len(unifiedOps) {
-				logMismatch("HandleTransactionDetails", len(legacyOps), len(unifiedOps), "tx_hash="+txHash)
-			} else {
-				logHybridMatch("HandleTransactionDetails", len(legacyOps))
-			}
 		}
-		operations, err = legacyOps, legacyErr
+		operations, err = h.legacyReader.GetEnrichedOperations(r.Context(), txFilters)
+		shadowCompare(h.shadow, "HandleTransactionDetails", "tx_hash="+txHash, operations, err,
+			func(ctx context.Context) ([]EnrichedOperation, error) {
+				return h.unifiedReader.GetEnrichedOperations(ctx, txFilters)
+			})
 		if err != nil {
 			respondError(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -2394,27 +2289,18 @@ func (h *SilverHandlers) HandleAssetOverview(w http.ResponseWriter, r *http.Requ
 		}
 
 	case ReaderModeHybrid:
-		// Run both for validation, return legacy
-		legacyTransfers, legacyErr := h.legacyReader.GetTokenTransfers(r.Context(), TransferFilters{
+		// Both readers see the same window, fixed before the legacy read.
+		assetFilters := TransferFilters{
 			AssetCode: assetCode,
 			StartTime: time.Now().Add(-24 * time.Hour),
 			EndTime:   time.Now(),
 			Limit:     100,
-		})
-		unifiedTransfers, unifiedErr := h.unifiedReader.GetTokenTransfers(r.Context(), TransferFilters{
-			AssetCode: assetCode,
-			StartTime: time.Now().Add(-24 * time.Hour),
-			EndTime:   time.Now(),
-			Limit:     100,
-		})
-		if legacyErr == nil && unifiedErr == nil {
-			if len(legacyTransfers) != len(unifiedTransfers) {
-				logMismatch("HandleAssetOverview", len(legacyTransfers), len(unifiedTransfers), "asset_code="+assetCode)
-			} else {
-				logHybridMatch("HandleAssetOverview", len(legacyTransfers))
-			}
 		}
-		transfers, err = legacyTransfers, legacyErr
+		transfers, err = h.legacyReader.GetTokenTransfers(r.Context(), assetFilters)
+		shadowCompare(h.shadow, "HandleAssetOverview", "asset_code="+assetCode, transfers, err,
+			func(ctx context.Context) ([]TokenTransfer, error) {
+				return h.unifiedReader.GetTokenTransfers(ctx, assetFilters)
+			})
 		if err != nil {
 			respondError(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -5062,11 +4948,3 @@ func respondJSON(w http.ResponseWriter, data interface{}) {
 func respondError(w http.ResponseWriter, message string, statusCode int) {
 	writeError(w, statusCode, message)
 }
-
-// boolToInt converts bool to int for logging purposes
-func boolToInt(b bool) int {
-	if b {
-		return 1
-	}
-	return 0
-}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+	hbase "github.com/stellar/go-stellar-sdk/protocols/horizon/base"
+	"github.com/stellar/go-stellar-sdk/support/render/hal"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// HorizonAssetFilters mirrors the /assets query parameters.
+type HorizonAssetFilters struct {
+	Code   string
+	Issuer string
+}
+
+type HorizonAssetReader struct {
+	unified *UnifiedDuckDBReader
+}
+
+func NewHorizonAssetReader(unified *UnifiedDuckDBReader) *HorizonAssetReader {
+	if unified == nil {
+		return nil
+	}
+	return &HorizonAssetReader{unified: unified}
+}
+
+// GetAssetStats builds Horizon asset stats from current trustlines, claimable
+// balances, liquidity pool reserves and the issuer account. Assets are paged
+// by (code, issuer), the order behind Horizon's "CODE_ISSUER_TYPE" token.
+// Contract (SAC) balances are not tracked in silver and are left out.
+func (r *HorizonAssetReader) GetAssetStats(ctx context.Context, filters HorizonAssetFilters, page horizonPageQuery) ([]protocol.AssetStat, error) {
+	if r == nil || r.unified == nil {
+		return nil, fmt.Errorf("horizon asset reader unavailable")
+	}
+
+	conditions := []string{"asset_type IN ('credit_alphanum4', 'credit_alphanum12')"}
+	args := []interface{}{}
+	argNum := 1
+	if filters.Code != "" {
+		conditions = append(conditions, fmt.Sprintf("asset_code = $%d", argNum))
+		args = append(args, filters.Code)
+		argNum++
+	}
+	if filters.Issuer != "" {
+		conditions = append(conditions, fmt.Sprintf("asset_issuer = $%d", argNum))
+		args = append(args, filters.Issuer)
+		argNum++
+	}
+	if page.Cursor != "" {
+		code, issuer, err := parseHorizonAssetCursor(page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		op := ">"
+		if page.Order == "desc" {
+			op = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("(asset_code %s $%d OR (asset_code = $%d AND asset_issuer %s $%d))",
+			op, argNum, argNum, op, argNum+1))
+		args = append(args, code, issuer)
+		argNum += 2
+	}
+	orderDir := "ASC"
+	if page.Order == "desc" {
+		orderDir = "DESC"
+	}
+	args = append(args, int(page.Limit))
+	trustlineWhere := strings.Join(conditions, " AND ")
+
+	build := func(schemas []string) string {
+		return fmt.Sprintf(`
+			WITH trustlines AS (
+				SELECT DISTINCT ON (account_id, asset_code, asset_issuer)
+				       asset_type, asset_code, asset_issuer, balance, COALESCE(flags, 0) AS flags
+				FROM (%s) combined
+				ORDER BY account_id, asset_code, asset_issuer, source ASC, last_modified_ledger DESC
+			),
+			page AS (
+				SELECT asset_type, asset_code, asset_issuer,
+				       COUNT(*) FILTER (WHERE (flags & 1) = 1) AS accounts_authorized,
+				       COUNT(*) FILTER (WHERE (flags & 3) = 2) AS accounts_maintain,
+				       COUNT(*) FILTER (WHERE (flags & 3) = 0) AS accounts_unauthorized,
+				       CAST(COALESCE(SUM(balance) FILTER (WHERE (flags & 1) = 1), 0) AS VARCHAR) AS balance_authorized,
+				       CAST(COALESCE(SUM(balance) FILTER (WHERE (flags & 3) = 2), 0) AS VARCHAR) AS balance_maintain,
+				       CAST(COALESCE(SUM(balance) FILTER (WHERE (flags & 3) = 0), 0) AS VARCHAR) AS balance_unauthorized
+				FROM trustlines
+				GROUP BY asset_type, asset_code, asset_issuer
+				ORDER BY asset_code %s, asset_issuer %s
+				LIMIT $%d
+			),
+			claimable AS (
+				SELECT asset_code, asset_issuer, COUNT(*) AS num, CAST(SUM(amount) AS VARCHAR) AS total
+				FROM (
+					SELECT DISTINCT ON (balance_id) asset_code, asset_issuer, amount
+					FROM (%s) combined
+					ORDER BY balance_id, source ASC, last_modified_ledger DESC
+				) balances
+				GROUP BY asset_code, asset_issuer
+			),
+			pools AS (
+				SELECT DISTINCT ON (liquidity_pool_id)
+				       asset_a_code, asset_a_issuer, asset_a_amount, asset_b_code, asset_b_issuer, asset_b_amount
+				FROM (%s) combined
+				ORDER BY liquidity_pool_id, source ASC, last_modified_ledger DESC
+			),
+			pool_reserves AS (
+				SELECT asset_code, asset_issuer, COUNT(*) AS num, CAST(SUM(amount) AS VARCHAR) AS total
+				FROM (
+					SELECT asset_a_code AS asset_code, asset_a_issuer AS asset_issuer, asset_a_amount AS amount FROM pools
+					UNION ALL
+					SELECT asset_b_code, asset_b_issuer, asset_b_amount FROM pools
+				) reserves
+				GROUP BY asset_code, asset_issuer
+			),
+			issuers AS (
+				SELECT DISTINCT ON (account_id) account_id, home_domain, COALESCE(flags, 0) AS flags
+				FROM (%s) combined
+				ORDER BY account_id, source ASC, last_modified_ledger DESC
+			)
+			SELECT p.asset_type, p.asset_code, p.asset_issuer,
+			       p.accounts_authorized, p.accounts_maintain, p.accounts_unauthorized,
+			       p.balance_authorized, p.balance_maintain, p.balance_unauthorized,
+			       COALESCE(c.num, 0), COALESCE(c.total, '0'),
+			       COALESCE(l.num, 0), COALESCE(l.total, '0'),
+			       i.home_domain, COALESCE(i.flags, 0)
+			FROM page p
+			LEFT JOIN claimable c ON c.asset_code = p.asset_code AND c.asset_issuer = p.asset_issuer
+			LEFT JOIN pool_reserves l ON l.asset_code = p.asset_code AND l.asset_issuer = p.asset_issuer
+			LEFT JOIN issuers i ON i.account_id = p.asset_issuer
+			ORDER BY p.asset_code %s, p.asset_issuer %s
+		`,
+			horizonTierUnion(schemas, "trustlines_current",
+				"account_id, asset_type, asset_code, asset_issuer, balance, flags, last_modified_ledger", trustlineWhere),
+			orderDir, orderDir, argNum,
+			horizonTierUnion(schemas, "claimable_balances_current",
+				"balance_id, asset_code, asset_issuer, amount, last_modified_ledger",
+				"asset_code IN (SELECT asset_code FROM page) AND asset_issuer IN (SELECT asset_issuer FROM page)"),
+			horizonTierUnion(schemas, "liquidity_pools_current",
+				"liquidity_pool_id, asset_a_code, asset_a_issuer, asset_a_amount, asset_b_code, asset_b_issuer, asset_b_amount, last_modified_ledger",
+				"(asset_a_code IN (SELECT asset_code FROM page) AND asset_a_issuer IN (SELECT asset_issuer FROM page)) OR "+
+					"(asset_b_code IN (SELECT asset_code FROM page) AND asset_b_issuer IN (SELECT asset_issuer FROM page))"),
+			horizonTierUnion(schemas, "accounts_current",
+				"account_id, home_domain, flags, last_modified_ledger",
+				"account_id IN (SELECT asset_issuer FROM page)"),
+			orderDir, orderDir)
+	}
+
+	rows, err := queryHorizonTiers(ctx, r.unified, "horizon GetAssetStats", build, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []protocol.AssetStat{}
+	for rows.Next() {
+		stat, err := scanHorizonAssetStat(rows)
+		if err != nil {
+			return nil, fmt.Errorf("horizon GetAssetStats scan: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+	return stats, rows.Err()
+}
+
+func scanHorizonAssetStat(rows *sql.Rows) (protocol.AssetStat, error) {
+	var stat protocol.AssetStat
+	var assetType, code, issuer string
+	var authorized, maintain, unauthorized, numClaimable, numPools, issuerFlags int64
+	var balanceAuthorized, balanceMaintain, balanceUnauthorized, claimableTotal, poolTotal string
+	var homeDomain sql.NullString
+	if err := rows.Scan(
+		&assetType, &code, &issuer,
+		&authorized, &maintain, &unauthorized,
+		&balanceAuthorized, &balanceMaintain, &balanceUnauthorized,
+		&numClaimable, &claimableTotal,
+		&numPools, &poolTotal,
+		&homeDomain, &issuerFlags,
+	); err != nil {
+		return stat, err
+	}
+
+	stat.Asset = hbase.Asset{Type: assetType, Code: code, Issuer: issuer}
+	stat.PT = code + "_" + issuer + "_" + assetType
+	stat.NumClaimableBalances = int32(numClaimable)
+	stat.NumLiquidityPools = int32(numPools)
+	stat.Accounts = protocol.AssetStatAccounts{
+		Authorized:                      int32(authorized),
+		AuthorizedToMaintainLiabilities: int32(maintain),
+		Unauthorized:                    int32(unauthorized),
+	}
+
+	var err error
+	for _, amount := range []struct {
+		raw string
+		dst *string
+	}{
+		{balanceAuthorized, &stat.Balances.Authorized},
+		{balanceMaintain, &stat.Balances.AuthorizedToMaintainLiabilities},
+		{balanceUnauthorized, &stat.Balances.Unauthorized},
+		{claimableTotal, &stat.ClaimableBalancesAmount},
+		{poolTotal, &stat.LiquidityPoolsAmount},
+	} {
+		if *amount.dst, err = formatStroopsSum(amount.raw); err != nil {
+			return stat, err
+		}
+	}
+
+	stat.Flags = protocol.AccountFlags{
+		AuthRequired:        issuerFlags&int64(xdr.AccountFlagsAuthRequiredFlag) != 0,
+		AuthRevocable:       issuerFlags&int64(xdr.AccountFlagsAuthRevocableFlag) != 0,
+		AuthImmutable:       issuerFlags&int64(xdr.AccountFlagsAuthImmutableFlag) != 0,
+		AuthClawbackEnabled: issuerFlags&int64(xdr.AccountFlagsAuthClawbackEnabledFlag) != 0,
+	}
+	// The toml link points at the issuer's own domain, not at this API, so it
+	// is filled here rather than by the handler's link builder.
+	if homeDomain.Valid && homeDomain.String != "" {
+		stat.Links.Toml = hal.NewLink("https://" + homeDomain.String + "/.well-known/stellar.toml")
+	}
+	return stat, nil
+}
+
+// parseHorizonAssetCursor splits Horizon's "CODE_ISSUER_TYPE" asset paging
+// token. Asset codes cannot contain '_', so the first two separators are
+// unambiguous.
+func parseHorizonAssetCursor(raw string) (string, string, error) {
+	parts := strings.SplitN(raw, "_", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" ||
+		(parts[2] != "credit_alphanum4" && parts[2] != "credit_alphanum12") {
+		return "", "", fmt.Errorf("cursor %q is not a Horizon asset paging token", raw)
+	}
+	return parts[0], parts[1], nil
+}
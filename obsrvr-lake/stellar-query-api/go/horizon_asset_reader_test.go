@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestHorizonAssetReaderMapsAssetStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("FROM hot.trustlines_current").
+		WithArgs("USDC", "USDC", "GAAAA", int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"asset_type", "asset_code", "asset_issuer",
+			"accounts_authorized", "accounts_maintain", "accounts_unauthorized",
+			"balance_authorized", "balance_maintain", "balance_unauthorized",
+			"num_claimable", "claimable_total", "num_pools", "pool_total",
+			"home_domain", "issuer_flags",
+		}).AddRow("credit_alphanum4", "USDC", testHorizonUSDCIssuer,
+			int64(40), int64(2), int64(1),
+			"92233720368547758070", "5000000", "0",
+			int64(3), "30000000", int64(1), "250000000",
+			"centre.io", int64(10)))
+
+	reader := &HorizonAssetReader{unified: &UnifiedDuckDBReader{db: db, hotSchema: "hot", coldSchema: "cold"}}
+	stats, err := reader.GetAssetStats(context.Background(), HorizonAssetFilters{Code: "USDC"},
+		horizonPageQuery{Cursor: "USDC_GAAAA_credit_alphanum4", Order: "asc", Limit: 2})
+	if err != nil {
+		t.Fatalf("GetAssetStats: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("stats = %#v", stats)
+	}
+	stat := stats[0]
+	if stat.Type != "credit_alphanum4" || stat.Code != "USDC" || stat.Issuer != testHorizonUSDCIssuer {
+		t.Fatalf("asset = %#v", stat.Asset)
+	}
+	if stat.PT != "USDC_"+testHorizonUSDCIssuer+"_credit_alphanum4" {
+		t.Fatalf("paging_token = %q", stat.PT)
+	}
+	if stat.Accounts.Authorized != 40 || stat.Accounts.AuthorizedToMaintainLiabilities != 2 || stat.Accounts.Unauthorized != 1 {
+		t.Fatalf("accounts = %#v", stat.Accounts)
+	}
+	// Authorized balances are summed as HUGEINT and must not overflow int64.
+	if stat.Balances.Authorized != "9223372036854.7758070" || stat.Balances.AuthorizedToMaintainLiabilities != "0.5000000" || stat.Balances.Unauthorized != "0.0000000" {
+		t.Fatalf("balances = %#v", stat.Balances)
+	}
+	if stat.NumClaimableBalances != 3 || stat.ClaimableBalancesAmount != "3.0000000" || stat.NumLiquidityPools != 1 || stat.LiquidityPoolsAmount != "25.0000000" {
+		t.Fatalf("claimable/pool stats = %#v", stat)
+	}
+	if stat.Flags.AuthRequired || !stat.Flags.AuthRevocable || stat.Flags.AuthImmutable || !stat.Flags.AuthClawbackEnabled {
+		t.Fatalf("flags = %#v", stat.Flags)
+	}
+	if stat.Links.Toml.Href != "https://centre.io/.well-known/stellar.toml" {
+		t.Fatalf("toml link = %#v", stat.Links.Toml)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestParseHorizonAssetCursor(t *testing.T) {
+	code, issuer, err := parseHorizonAssetCursor("USDC_" + testHorizonUSDCIssuer + "_credit_alphanum4")
+	if err != nil || code != "USDC" || issuer != testHorizonUSDCIssuer {
+		t.Fatalf("cursor = (%q, %q, %v)", code, issuer, err)
+	}
+	for _, raw := range []string{"USDC", "USDC_" + testHorizonUSDCIssuer, "USDC_" + testHorizonUSDCIssuer + "_native"} {
+		if _, _, err := parseHorizonAssetCursor(raw); err == nil {
+			t.Fatalf("cursor %q accepted", raw)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+var errHorizonClaimableBalanceNotFound = errors.New("horizon claimable balance not found")
+
+// horizonClaimableBalanceIDPrefix is the hex-encoded ClaimableBalanceIdType
+// (V0) that Horizon prepends to the 32-byte balance hash. Silver stores only
+// the hash; effect details carry the full XDR hex.
+const horizonClaimableBalanceIDPrefix = "00000000"
+
+const (
+	horizonClaimableBalanceColumns = `balance_id, sponsor, asset_type, asset_code, asset_issuer, amount, flags, last_modified_ledger`
+	// effectClaimableBalanceClaimantCreated is the effect emitted once per
+	// claimant when a balance is created; it is the only place silver records
+	// claimant destinations and predicates.
+	effectClaimableBalanceClaimantCreated = 51
+)
+
+// HorizonClaimableBalanceFilters mirrors the /claimable_balances query
+// parameters.
+type HorizonClaimableBalanceFilters struct {
+	Sponsor  string
+	Asset    *AssetInfo
+	Claimant string
+}
+
+type HorizonClaimableBalanceReader struct {
+	unified *UnifiedDuckDBReader
+}
+
+func NewHorizonClaimableBalanceReader(unified *UnifiedDuckDBReader) *HorizonClaimableBalanceReader {
+	if unified == nil {
+		return nil
+	}
+	return &HorizonClaimableBalanceReader{unified: unified}
+}
+
+// GetClaimableBalances pages balances by (last_modified_ledger, id), the
+// order behind Horizon's "<ledger>-<id>" paging token.
+func (r *HorizonClaimableBalanceReader) GetClaimableBalances(ctx context.Context, filters HorizonClaimableBalanceFilters, page horizonPageQuery) ([]protocol.ClaimableBalance, error) {
+	if r == nil || r.unified == nil {
+		return nil, fmt.Errorf("horizon claimable balance reader unavailable")
+	}
+
+	conditions := []string{}
+	args := []interface{}{}
+	argNum := 1
+	if filters.Sponsor != "" {
+		conditions = append(conditions, fmt.Sprintf("sponsor = $%d", argNum))
+		args = append(args, filters.Sponsor)
+		argNum++
+	}
+	if filters.Asset != nil {
+		if filters.Asset.Type == "native" {
+			conditions = append(conditions, "asset_type = 'native'")
+		} else {
+			conditions = append(conditions, fmt.Sprintf("asset_code = $%d AND asset_issuer = $%d", argNum, argNum+1))
+			args = append(args, filters.Asset.Code, derefString(filters.Asset.Issuer))
+			argNum += 2
+		}
+	}
+	claimantArg := 0
+	if filters.Claimant != "" {
+		claimantArg = argNum
+		args = append(args, filters.Claimant)
+		argNum++
+	}
+
+	cursorClause := "1=1"
+	if page.Cursor != "" {
+		ledger, id, err := parseHorizonClaimableBalanceCursor(page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		op := ">"
+		if page.Order == "desc" {
+			op = "<"
+		}
+		cursorClause = fmt.Sprintf("(last_modified_ledger %s $%d OR (last_modified_ledger = $%d AND right(balance_id, 64) %s $%d))",
+			op, argNum, argNum, op, argNum+1)
+		args = append(args, ledger, strings.TrimPrefix(id, horizonClaimableBalanceIDPrefix))
+		argNum += 2
+	}
+	orderDir := "ASC"
+	if page.Order == "desc" {
+		orderDir = "DESC"
+	}
+	args = append(args, int(page.Limit))
+
+	build := func(schemas []string) string {
+		where := append([]string{}, conditions...)
+		if claimantArg > 0 {
+			where = append(where, fmt.Sprintf(
+				"right(balance_id, 64) IN (SELECT right(json_extract_string(details_json, '$.balance_id'), 64) FROM (%s) claimants)",
+				horizonTierUnion(schemas, "effects", "details_json",
+					fmt.Sprintf("effect_type = %d AND account_id = $%d", effectClaimableBalanceClaimantCreated, claimantArg))))
+		}
+		whereClause := "1=1"
+		if len(where) > 0 {
+			whereClause = strings.Join(where, " AND ")
+		}
+		return fmt.Sprintf(`
+			WITH deduplicated AS (
+				SELECT DISTINCT ON (balance_id) %s
+				FROM (%s) combined
+				ORDER BY balance_id, source ASC, last_modified_ledger DESC
+			)
+			SELECT %s FROM deduplicated
+			WHERE %s
+			ORDER BY last_modified_ledger %s, right(balance_id, 64) %s
+			LIMIT $%d
+		`, horizonClaimableBalanceColumns,
+			horizonTierUnion(schemas, "claimable_balances_current", horizonClaimableBalanceColumns, whereClause),
+			horizonClaimableBalanceColumns, cursorClause, orderDir, orderDir, argNum)
+	}
+
+	rows, err := queryHorizonTiers(ctx, r.unified, "horizon GetClaimableBalances", build, args...)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := scanHorizonClaimableBalances(rows)
+	if err != nil {
+		return nil, fmt.Errorf("horizon GetClaimableBalances scan: %w", err)
+	}
+	if err := r.attachClaimants(ctx, balances); err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// GetClaimableBalance looks a balance up by its Horizon id (the full XDR hex)
+// or by the bare 32-byte hash.
+func (r *HorizonClaimableBalanceReader) GetClaimableBalance(ctx context.Context, id string) (*protocol.ClaimableBalance, error) {
+	if r == nil || r.unified == nil {
+		return nil, fmt.Errorf("horizon claimable balance reader unavailable")
+	}
+	hash := strings.TrimPrefix(strings.ToLower(id), horizonClaimableBalanceIDPrefix)
+	build := func(schemas []string) string {
+		return fmt.Sprintf(`
+			SELECT %s FROM (%s) combined
+			ORDER BY source ASC, last_modified_ledger DESC
+			LIMIT 1
+		`, horizonClaimableBalanceColumns,
+			horizonTierUnion(schemas, "claimable_balances_current", horizonClaimableBalanceColumns, "balance_id IN ($1, $2)"))
+	}
+	rows, err := queryHorizonTiers(ctx, r.unified, "horizon GetClaimableBalance", build, hash, horizonClaimableBalanceIDPrefix+hash)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := scanHorizonClaimableBalances(rows)
+	if err != nil {
+		return nil, fmt.Errorf("horizon GetClaimableBalance scan: %w", err)
+	}
+	if len(balances) == 0 {
+		return nil, errHorizonClaimableBalanceNotFound
+	}
+	if err := r.attachClaimants(ctx, balances); err != nil {
+		return nil, err
+	}
+	return &balances[0], nil
+}
+
+func scanHorizonClaimableBalances(rows *sql.Rows) ([]protocol.ClaimableBalance, error) {
+	defer rows.Close()
+	balances := []protocol.ClaimableBalance{}
+	for rows.Next() {
+		var b protocol.ClaimableBalance
+		var balanceID string
+		var sponsor, assetType, assetCode, assetIssuer sql.NullString
+		var amount, flags, lastModified int64
+		if err := rows.Scan(&balanceID, &sponsor, &assetType, &assetCode, &assetIssuer, &amount, &flags, &lastModified); err != nil {
+			return nil, err
+		}
+		b.BalanceID = horizonClaimableBalanceID(balanceID)
+		b.Asset = horizonAssetString(buildAssetInfo(assetType.String, assetCode.String, assetIssuer.String))
+		b.Amount = formatStroops(amount)
+		b.Sponsor = sponsor.String
+		b.LastModifiedLedger = uint32(lastModified)
+		b.Flags.ClawbackEnabled = flags&int64(xdr.ClaimableBalanceFlagsClaimableBalanceClawbackEnabledFlag) != 0
+		b.PT = fmt.Sprintf("%d-%s", lastModified, b.BalanceID)
+		b.Claimants = []protocol.Claimant{}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+// attachClaimants fills claimants from claimant_created effects. Balances are
+// immutable apart from sponsorship transfers, so the effects are looked up at
+// last_modified_ledger first and only the misses are searched unbounded.
+func (r *HorizonClaimableBalanceReader) attachClaimants(ctx context.Context, balances []protocol.ClaimableBalance) error {
+	if len(balances) == 0 {
+		return nil
+	}
+	byID := make(map[string]*protocol.ClaimableBalance, len(balances))
+	seenLedgers := map[uint32]bool{}
+	var args []interface{}
+	var placeholders []string
+	for i := range balances {
+		byID[balances[i].BalanceID] = &balances[i]
+		if ledger := balances[i].LastModifiedLedger; !seenLedgers[ledger] {
+			seenLedgers[ledger] = true
+			args = append(args, int64(ledger))
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+	}
+	if err := r.loadClaimants(ctx, byID, "ledger_sequence IN ("+strings.Join(placeholders, ", ")+")", args); err != nil {
+		return err
+	}
+
+	args = args[:0]
+	placeholders = placeholders[:0]
+	for _, balance := range balances {
+		if len(balance.Claimants) == 0 {
+			args = append(args, balance.BalanceID)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return r.loadClaimants(ctx, byID, "json_extract_string(details_json, '$.balance_id') IN ("+strings.Join(placeholders, ", ")+")", args)
+}
+
+func (r *HorizonClaimableBalanceReader) loadClaimants(ctx context.Context, byID map[string]*protocol.ClaimableBalance, where string, args []interface{}) error {
+	build := func(schemas []string) string {
+		return fmt.Sprintf(`
+			SELECT balance_id, account_id, predicate FROM (
+				SELECT DISTINCT ON (balance_id, account_id) balance_id, account_id, predicate, operation_id, effect_index
+				FROM (
+					SELECT json_extract_string(details_json, '$.balance_id') AS balance_id, account_id,
+					       CAST(json_extract(details_json, '$.predicate') AS VARCHAR) AS predicate,
+					       operation_id, effect_index, source
+					FROM (%s) combined
+				) claimants
+				ORDER BY balance_id, account_id, source ASC
+			) deduplicated
+			ORDER BY operation_id, effect_index
+		`, horizonTierUnion(schemas, "effects", "details_json, account_id, operation_id, effect_index",
+			fmt.Sprintf("effect_type = %d AND %s", effectClaimableBalanceClaimantCreated, where)))
+	}
+	rows, err := queryHorizonTiers(ctx, r.unified, "horizon claimable balance claimants", build, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var balanceID, destination string
+		var rawPredicate sql.NullString
+		if err := rows.Scan(&balanceID, &destination, &rawPredicate); err != nil {
+			return fmt.Errorf("horizon claimable balance claimants scan: %w", err)
+		}
+		balance := byID[horizonClaimableBalanceID(balanceID)]
+		if balance == nil {
+			continue
+		}
+		predicate, err := horizonClaimPredicate(rawPredicate)
+		if err != nil {
+			return fmt.Errorf("claimable balance %s claimant %s: %w", balance.BalanceID, destination, err)
+		}
+		balance.Claimants = append(balance.Claimants, protocol.Claimant{Destination: destination, Predicate: predicate})
+	}
+	return rows.Err()
+}
+
+// horizonClaimPredicate decodes the predicate recorded in effect details.
+// Effects ingested before predicates were recorded carry none; those are
+// served as unconditional.
+func horizonClaimPredicate(raw sql.NullString) (xdr.ClaimPredicate, error) {
+	if !raw.Valid || raw.String == "" || raw.String == "null" {
+		return xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateUnconditional}, nil
+	}
+	var predicate xdr.ClaimPredicate
+	if err := json.Unmarshal([]byte(raw.String), &predicate); err != nil {
+		return predicate, fmt.Errorf("decode claim predicate: %w", err)
+	}
+	return predicate, nil
+}
+
+// horizonClaimableBalanceID renders a stored balance id as Horizon does: the
+// lower-case hex of the XDR ClaimableBalanceId, type prefix included.
+func horizonClaimableBalanceID(stored string) string {
+	stored = strings.ToLower(stored)
+	if len(stored) == 64 {
+		return horizonClaimableBalanceIDPrefix + stored
+	}
+	return stored
+}
+
+// isHorizonClaimableBalanceID accepts the 72-character Horizon id or the bare
+// 64-character hash.
+func isHorizonClaimableBalanceID(id string) bool {
+	id = strings.TrimPrefix(strings.ToLower(id), horizonClaimableBalanceIDPrefix)
+	if len(id) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+func parseHorizonClaimableBalanceCursor(raw string) (int64, string, error) {
+	ledgerRaw, id, ok := strings.Cut(raw, "-")
+	ledger, err := strconv.ParseInt(ledgerRaw, 10, 64)
+	if !ok || err != nil || ledger < 0 || !isHorizonClaimableBalanceID(id) {
+		return 0, "", fmt.Errorf("cursor %q is not a Horizon claimable balance paging token", raw)
+	}
+	return ledger, horizonClaimableBalanceID(strings.TrimPrefix(strings.ToLower(id), horizonClaimableBalanceIDPrefix)), nil
+}
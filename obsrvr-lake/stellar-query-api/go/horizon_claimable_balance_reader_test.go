@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+const (
+	testHorizonBalanceHash  = "da0d57da7d4850e7fc10d2a9d0ebc731f7afb40574c03395b17d49149b91f5be"
+	testHorizonBalanceHash2 = "929b20b72e5890ab51c24f1cc46fa01c4f318d8d33367d24dd614cfdf5491072"
+)
+
+var horizonClaimableBalanceTestColumns = []string{
+	"balance_id", "sponsor", "asset_type", "asset_code", "asset_issuer", "amount", "flags", "last_modified_ledger",
+}
+
+func TestHorizonClaimableBalanceReaderMapsBalancesAndClaimants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	id := horizonClaimableBalanceIDPrefix + testHorizonBalanceHash
+	id2 := horizonClaimableBalanceIDPrefix + testHorizonBalanceHash2
+	mock.ExpectQuery("FROM hot.claimable_balances_current").
+		WithArgs("GCLAIMANT", int64(90), testHorizonBalanceHash2, int64(10)).
+		WillReturnRows(sqlmock.NewRows(horizonClaimableBalanceTestColumns).
+			AddRow(testHorizonBalanceHash, "GSPONSOR", "credit_alphanum4", "USDC", testHorizonUSDCIssuer, int64(125000000), int64(1), int64(100)).
+			AddRow(testHorizonBalanceHash2, nil, "native", nil, nil, int64(10000000), int64(0), int64(120)))
+	// Claimants are looked up at each balance's last_modified_ledger first...
+	mock.ExpectQuery("effect_type = 51 AND ledger_sequence IN").
+		WithArgs(int64(100), int64(120)).
+		WillReturnRows(sqlmock.NewRows([]string{"balance_id", "account_id", "predicate"}).
+			AddRow(id, "GCLAIMANT", `{"unconditional":true}`).
+			AddRow(id, "GOTHER", nil))
+	// ...and balances whose sponsorship moved since creation by id.
+	mock.ExpectQuery("effect_type = 51 AND json_extract_string").
+		WithArgs(id2).
+		WillReturnRows(sqlmock.NewRows([]string{"balance_id", "account_id", "predicate"}).
+			AddRow(id2, "GCLAIMANT", `{"unconditional":true}`))
+
+	reader := &HorizonClaimableBalanceReader{unified: &UnifiedDuckDBReader{db: db, hotSchema: "hot", coldSchema: "cold"}}
+	balances, err := reader.GetClaimableBalances(context.Background(), HorizonClaimableBalanceFilters{Claimant: "GCLAIMANT"},
+		horizonPageQuery{Cursor: "90-" + id2, Order: "asc", Limit: 10})
+	if err != nil {
+		t.Fatalf("GetClaimableBalances: %v", err)
+	}
+	if len(balances) != 2 {
+		t.Fatalf("balances = %#v", balances)
+	}
+	first := balances[0]
+	if first.BalanceID != id || first.PT != "100-"+id || first.Asset != "USDC:"+testHorizonUSDCIssuer || first.Amount != "12.5000000" {
+		t.Fatalf("first balance = %#v", first)
+	}
+	if first.Sponsor != "GSPONSOR" || !first.Flags.ClawbackEnabled || first.LastModifiedLedger != 100 {
+		t.Fatalf("first balance metadata = %#v", first)
+	}
+	if len(first.Claimants) != 2 || first.Claimants[0].Destination != "GCLAIMANT" || first.Claimants[1].Destination != "GOTHER" {
+		t.Fatalf("first claimants = %#v", first.Claimants)
+	}
+	for _, claimant := range first.Claimants {
+		if claimant.Predicate.Type != xdr.ClaimPredicateTypeClaimPredicateUnconditional {
+			t.Fatalf("claimant predicate = %#v", claimant.Predicate)
+		}
+	}
+	second := balances[1]
+	if second.Asset != "native" || second.Sponsor != "" || second.Flags.ClawbackEnabled || len(second.Claimants) != 1 {
+		t.Fatalf("second balance = %#v", second)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestHorizonClaimableBalanceReaderRefusesCorruptPredicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	id := horizonClaimableBalanceIDPrefix + testHorizonBalanceHash
+	mock.ExpectQuery("FROM hot.claimable_balances_current").
+		WithArgs(testHorizonBalanceHash, id).
+		WillReturnRows(sqlmock.NewRows(horizonClaimableBalanceTestColumns).
+			AddRow(testHorizonBalanceHash, nil, "native", nil, nil, int64(10000000), int64(0), int64(100)))
+	mock.ExpectQuery("effect_type = 51").
+		WithArgs(int64(100)).
+		WillReturnRows(sqlmock.NewRows([]string{"balance_id", "account_id", "predicate"}).
+			AddRow(id, "GCLAIMANT", `{"abs_before":`))
+
+	// A claimant whose predicate cannot be decoded must not be served as
+	// unconditional: wallets would offer a claim the network rejects.
+	reader := &HorizonClaimableBalanceReader{unified: &UnifiedDuckDBReader{db: db, hotSchema: "hot", coldSchema: "cold"}}
+	if _, err := reader.GetClaimableBalance(context.Background(), id); err == nil {
+		t.Fatal("corrupt predicate must fail the lookup")
+	}
+}
+
+func TestHorizonClaimableBalanceReaderNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("FROM hot.claimable_balances_current").
+		WithArgs(testHorizonBalanceHash, horizonClaimableBalanceIDPrefix+testHorizonBalanceHash).
+		WillReturnRows(sqlmock.NewRows(horizonClaimableBalanceTestColumns))
+
+	reader := &HorizonClaimableBalanceReader{unified: &UnifiedDuckDBReader{db: db, hotSchema: "hot", coldSchema: "cold"}}
+	_, err = reader.GetClaimableBalance(context.Background(), testHorizonBalanceHash)
+	if !errors.Is(err, errHorizonClaimableBalanceNotFound) {
+		t.Fatalf("error = %v, want errHorizonClaimableBalanceNotFound", err)
+	}
+}
+
+func TestParseHorizonClaimableBalanceCursor(t *testing.T) {
+	ledger, id, err := parseHorizonClaimableBalanceCursor("100-" + horizonClaimableBalanceIDPrefix + testHorizonBalanceHash)
+	if err != nil || ledger != 100 || id != horizonClaimableBalanceIDPrefix+testHorizonBalanceHash {
+		t.Fatalf("cursor = (%d, %q, %v)", ledger, id, err)
+	}
+	for _, raw := range []string{"100", "x-" + testHorizonBalanceHash, "100-zz"} {
+		if _, _, err := parseHorizonClaimableBalanceCursor(raw); err == nil {
+			t.Fatalf("cursor %q accepted", raw)
+		}
+	}
+}
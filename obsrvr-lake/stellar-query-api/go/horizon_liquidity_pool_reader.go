@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+)
+
+var errHorizonLiquidityPoolNotFound = errors.New("horizon liquidity pool not found")
+
+const horizonLiquidityPoolColumns = `liquidity_pool_id, pool_type, fee, trustline_count, total_pool_shares,
+			       asset_a_type, asset_a_code, asset_a_issuer, asset_a_amount,
+			       asset_b_type, asset_b_code, asset_b_issuer, asset_b_amount,
+			       last_modified_ledger`
+
+// HorizonLiquidityPoolFilters mirrors the /liquidity_pools query parameters.
+// A pool matches when it holds every asset in Reserves and, if Account is
+// set, when that account has a pool-share trustline to it.
+type HorizonLiquidityPoolFilters struct {
+	Reserves []AssetInfo
+	Account  string
+}
+
+type HorizonLiquidityPoolReader struct {
+	unified *UnifiedDuckDBReader
+}
+
+func NewHorizonLiquidityPoolReader(unified *UnifiedDuckDBReader) *HorizonLiquidityPoolReader {
+	if unified == nil {
+		return nil
+	}
+	return &HorizonLiquidityPoolReader{unified: unified}
+}
+
+// GetLiquidityPools pages pools by id, which is also Horizon's paging token.
+func (r *HorizonLiquidityPoolReader) GetLiquidityPools(ctx context.Context, filters HorizonLiquidityPoolFilters, page horizonPageQuery) ([]protocol.LiquidityPool, error) {
+	if r == nil || r.unified == nil {
+		return nil, fmt.Errorf("horizon liquidity pool reader unavailable")
+	}
+
+	conditions := []string{}
+	args := []interface{}{}
+	argNum := 1
+	for _, asset := range filters.Reserves {
+		if asset.Type == "native" {
+			conditions = append(conditions, "(asset_a_type = 'native' OR asset_b_type = 'native')")
+			continue
+		}
+		conditions = append(conditions, fmt.Sprintf(
+			"((asset_a_code = $%d AND asset_a_issuer = $%d) OR (asset_b_code = $%d AND asset_b_issuer = $%d))",
+			argNum, argNum+1, argNum, argNum+1))
+		args = append(args, asset.Code, derefString(asset.Issuer))
+		argNum += 2
+	}
+	accountArg := 0
+	if filters.Account != "" {
+		accountArg = argNum
+		args = append(args, filters.Account)
+		argNum++
+	}
+	if page.Cursor != "" {
+		op := ">"
+		if page.Order == "desc" {
+			op = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("liquidity_pool_id %s $%d", op, argNum))
+		args = append(args, page.Cursor)
+		argNum++
+	}
+	orderDir := "ASC"
+	if page.Order == "desc" {
+		orderDir = "DESC"
+	}
+	args = append(args, int(page.Limit))
+
+	build := func(schemas []string) string {
+		where := append([]string{}, conditions...)
+		if accountArg > 0 {
+			where = append(where, fmt.Sprintf("liquidity_pool_id IN (SELECT liquidity_pool_id FROM (%s) shares)",
+				horizonTierUnion(schemas, "trustlines_current", "liquidity_pool_id",
+					fmt.Sprintf("account_id = $%d AND liquidity_pool_id IS NOT NULL", accountArg))))
+		}
+		whereClause := "1=1"
+		if len(where) > 0 {
+			whereClause = strings.Join(where, " AND ")
+		}
+		return fmt.Sprintf(`
+			WITH deduplicated AS (
+				SELECT DISTINCT ON (liquidity_pool_id) %s
+				FROM (%s) combined
+				ORDER BY liquidity_pool_id, source ASC, last_modified_ledger DESC
+			)
+			SELECT %s FROM deduplicated
+			ORDER BY liquidity_pool_id %s
+			LIMIT $%d
+		`, horizonLiquidityPoolColumns,
+			horizonTierUnion(schemas, "liquidity_pools_current", horizonLiquidityPoolColumns, whereClause),
+			horizonLiquidityPoolColumns, orderDir, argNum)
+	}
+
+	rows, err := queryHorizonTiers(ctx, r.unified, "horizon GetLiquidityPools", build, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pools := []protocol.LiquidityPool{}
+	for rows.Next() {
+		pool, err := scanHorizonLiquidityPool(rows)
+		if err != nil {
+			return nil, fmt.Errorf("horizon GetLiquidityPools scan: %w", err)
+		}
+		pools = append(pools, pool)
+	}
+	return pools, rows.Err()
+}
+
+func (r *HorizonLiquidityPoolReader) GetLiquidityPool(ctx context.Context, poolID string) (*protocol.LiquidityPool, error) {
+	if r == nil || r.unified == nil {
+		return nil, fmt.Errorf("horizon liquidity pool reader unavailable")
+	}
+	build := func(schemas []string) string {
+		return fmt.Sprintf(`
+			SELECT %s FROM (%s) combined
+			ORDER BY source ASC, last_modified_ledger DESC
+			LIMIT 1
+		`, horizonLiquidityPoolColumns,
+			horizonTierUnion(schemas, "liquidity_pools_current", horizonLiquidityPoolColumns, "liquidity_pool_id = $1"))
+	}
+	rows, err := queryHorizonTiers(ctx, r.unified, "horizon GetLiquidityPool", build, poolID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errHorizonLiquidityPoolNotFound
+	}
+	pool, err := scanHorizonLiquidityPool(rows)
+	if err != nil {
+		return nil, fmt.Errorf("horizon GetLiquidityPool scan: %w", err)
+	}
+	return &pool, nil
+}
+
+func scanHorizonLiquidityPool(rows *sql.Rows) (protocol.LiquidityPool, error) {
+	var pool protocol.LiquidityPool
+	var poolType sql.NullString
+	var fee, trustlines, totalShares, lastModified int64
+	var assetAType, assetACode, assetAIssuer, assetBType, assetBCode, assetBIssuer sql.NullString
+	var assetAAmount, assetBAmount int64
+	if err := rows.Scan(
+		&pool.ID, &poolType, &fee, &trustlines, &totalShares,
+		&assetAType, &assetACode, &assetAIssuer, &assetAAmount,
+		&assetBType, &assetBCode, &assetBIssuer, &assetBAmount,
+		&lastModified,
+	); err != nil {
+		return pool, err
+	}
+	pool.PT = pool.ID
+	pool.FeeBP = uint32(fee)
+	pool.Type = horizonLiquidityPoolType(poolType.String)
+	pool.TotalTrustlines = uint64(trustlines)
+	pool.TotalShares = formatStroops(totalShares)
+	pool.Reserves = []protocol.LiquidityPoolReserve{
+		{Asset: horizonAssetString(buildAssetInfo(assetAType.String, assetACode.String, assetAIssuer.String)), Amount: formatStroops(assetAAmount)},
+		{Asset: horizonAssetString(buildAssetInfo(assetBType.String, assetBCode.String, assetBIssuer.String)), Amount: formatStroops(assetBAmount)},
+	}
+	pool.LastModifiedLedger = uint32(lastModified)
+	return pool, nil
+}
+
+// horizonLiquidityPoolType maps the stored XDR pool type name onto Horizon's
+// "constant_product"; it is the only pool type the protocol defines.
+func horizonLiquidityPoolType(raw string) string {
+	if raw == "" || strings.Contains(strings.ToLower(raw), "constant") {
+		return "constant_product"
+	}
+	return raw
+}
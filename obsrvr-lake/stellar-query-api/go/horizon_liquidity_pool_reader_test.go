@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const testHorizonPoolID = "dd7b1ab831c273310ddbec6f97870aa83c2fbd78ce22aded37ecbf4f3380fac7"
+
+var horizonLiquidityPoolTestColumns = []string{
+	"liquidity_pool_id", "pool_type", "fee", "trustline_count", "total_pool_shares",
+	"asset_a_type", "asset_a_code", "asset_a_issuer", "asset_a_amount",
+	"asset_b_type", "asset_b_code", "asset_b_issuer", "asset_b_amount",
+	"last_modified_ledger",
+}
+
+func TestHorizonLiquidityPoolReaderMapsPoolsAndFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("FROM hot.liquidity_pools_current").
+		WithArgs("USDC", testHorizonUSDCIssuer, "GACCOUNT", int64(10)).
+		WillReturnRows(sqlmock.NewRows(horizonLiquidityPoolTestColumns).
+			AddRow(testHorizonPoolID, "LiquidityPoolConstantProduct", int64(30), int64(12), int64(50000000000),
+				"native", nil, nil, int64(1000000000),
+				"credit_alphanum4", "USDC", testHorizonUSDCIssuer, int64(250000000),
+				int64(700)))
+
+	reader := &HorizonLiquidityPoolReader{unified: &UnifiedDuckDBReader{db: db, hotSchema: "hot", coldSchema: "cold"}}
+	pools, err := reader.GetLiquidityPools(context.Background(), HorizonLiquidityPoolFilters{
+		Reserves: []AssetInfo{buildAssetInfo("native", "", ""), usdcAssetInfo()},
+		Account:  "GACCOUNT",
+	}, horizonPageQuery{Order: "asc", Limit: 10})
+	if err != nil {
+		t.Fatalf("GetLiquidityPools: %v", err)
+	}
+	if len(pools) != 1 {
+		t.Fatalf("pools = %#v", pools)
+	}
+	pool := pools[0]
+	if pool.ID != testHorizonPoolID || pool.PT != testHorizonPoolID || pool.Type != "constant_product" || pool.FeeBP != 30 {
+		t.Fatalf("pool identity = %#v", pool)
+	}
+	if pool.TotalTrustlines != 12 || pool.TotalShares != "5000.0000000" || pool.LastModifiedLedger != 700 {
+		t.Fatalf("pool totals = %#v", pool)
+	}
+	if len(pool.Reserves) != 2 ||
+		pool.Reserves[0].Asset != "native" || pool.Reserves[0].Amount != "100.0000000" ||
+		pool.Reserves[1].Asset != "USDC:"+testHorizonUSDCIssuer || pool.Reserves[1].Amount != "25.0000000" {
+		t.Fatalf("reserves = %#v", pool.Reserves)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestHorizonLiquidityPoolReaderFallsBackToHotOnColdSchemaGap(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("FROM cold.liquidity_pools_current").
+		WithArgs(testHorizonPoolID).
+		WillReturnError(errors.New("Catalog Error: Table with name liquidity_pools_current does not exist!"))
+	mock.ExpectQuery("FROM hot.liquidity_pools_current").
+		WithArgs(testHorizonPoolID).
+		WillReturnRows(sqlmock.NewRows(horizonLiquidityPoolTestColumns))
+
+	reader := &HorizonLiquidityPoolReader{unified: &UnifiedDuckDBReader{db: db, hotSchema: "hot", coldSchema: "cold"}}
+	_, err = reader.GetLiquidityPool(context.Background(), testHorizonPoolID)
+	if !errors.Is(err, errHorizonLiquidityPoolNotFound) {
+		t.Fatalf("error = %v, want errHorizonLiquidityPoolNotFound", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -25,6 +25,11 @@ func parseHorizonPageQuery(r *http.Request) (horizonPageQuery, error) {
 		Order:  strings.ToLower(strings.TrimSpace(q.Get("order"))),
 		Limit:  defaultHorizonLimit,
 	}
+	// Horizon SDK streams reconnect with Last-Event-ID set to the last paging
+	// token they received; it takes precedence over the original cursor.
+	if lastEventID := strings.TrimSpace(r.Header.Get("Last-Event-ID")); lastEventID != "" {
+		out.Cursor = lastEventID
+	}
 	if out.Order == "" {
 		out.Order = "asc"
 	}
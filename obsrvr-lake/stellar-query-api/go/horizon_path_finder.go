@@ -0,0 +1,477 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultHorizonMaxPathLength is Horizon's default --max-path-length: the
+	// number of intermediate assets a path may hop through.
+	defaultHorizonMaxPathLength = 3
+	// maxHorizonPathAssets caps the source_assets / destination_assets lists,
+	// as Horizon does.
+	maxHorizonPathAssets = 15
+	// maxHorizonPathsPerAsset bounds how many alternatives are returned for
+	// each source or destination asset.
+	maxHorizonPathsPerAsset = 5
+
+	defaultHorizonPathsGraphTTL = 5 * time.Second
+	horizonPoolFeeDenominator   = 10000
+)
+
+// horizonPathStateSource loads the offers and pools path finding runs over.
+// UnifiedDuckDBReader satisfies it.
+type horizonPathStateSource interface {
+	GetPathFindingState(context.Context) ([]PathOffer, []PathPool, error)
+}
+
+// horizonPathResult is one path found between a source and destination asset.
+// Assets are canonical strings; Path lists the intermediate assets in the
+// order the payment hops through them.
+type horizonPathResult struct {
+	Source            string
+	SourceAmount      int64
+	Destination       string
+	DestinationAmount int64
+	Path              []string
+}
+
+// horizonPathFinder answers strict-send and strict-receive queries over an
+// in-memory order book graph. Loading every offer and pool is too expensive to
+// repeat per request, so the graph is rebuilt at most once per TTL, which by
+// default is roughly one ledger close.
+type horizonPathFinder struct {
+	source        horizonPathStateSource
+	ttl           time.Duration
+	maxPathLength int
+	now           func() time.Time
+
+	mu       sync.Mutex
+	graph    *horizonPathGraph
+	loadedAt time.Time
+}
+
+func newHorizonPathFinder(source horizonPathStateSource) *horizonPathFinder {
+	if source == nil {
+		return nil
+	}
+	return &horizonPathFinder{
+		source:        source,
+		ttl:           durationEnv("HORIZON_PATHS_GRAPH_TTL", defaultHorizonPathsGraphTTL),
+		maxPathLength: defaultHorizonMaxPathLength,
+		now:           time.Now,
+	}
+}
+
+func (f *horizonPathFinder) currentGraph(ctx context.Context) (*horizonPathGraph, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.graph != nil && f.now().Sub(f.loadedAt) < f.ttl {
+		return f.graph, nil
+	}
+	offers, pools, err := f.source.GetPathFindingState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f.graph = newHorizonPathGraph(offers, pools)
+	f.loadedAt = f.now()
+	return f.graph, nil
+}
+
+// FindStrictSendPaths returns paths that deliver the most of each destination
+// asset for exactly amount of source, best first.
+func (f *horizonPathFinder) FindStrictSendPaths(ctx context.Context, source string, amount int64, destinations []string) ([]horizonPathResult, error) {
+	graph, err := f.currentGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	found := graph.search(source, amount, destinations, f.maxPathLength, true)
+	results := make([]horizonPathResult, 0, len(found))
+	for _, p := range found {
+		results = append(results, horizonPathResult{
+			Source:            source,
+			SourceAmount:      amount,
+			Destination:       p.end,
+			DestinationAmount: p.amount,
+			Path:              p.intermediate(),
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].DestinationAmount != results[j].DestinationAmount {
+			return results[i].DestinationAmount > results[j].DestinationAmount
+		}
+		return len(results[i].Path) < len(results[j].Path)
+	})
+	return capHorizonPathsPerAsset(results, func(p horizonPathResult) string { return p.Destination }), nil
+}
+
+// FindStrictReceivePaths returns paths that cost the least of each source
+// asset to deliver exactly amount of destination, best first. The search runs
+// backwards from the destination.
+func (f *horizonPathFinder) FindStrictReceivePaths(ctx context.Context, sources []string, destination string, amount int64) ([]horizonPathResult, error) {
+	graph, err := f.currentGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	found := graph.search(destination, amount, sources, f.maxPathLength, false)
+	results := make([]horizonPathResult, 0, len(found))
+	for _, p := range found {
+		path := p.intermediate()
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+		results = append(results, horizonPathResult{
+			Source:            p.end,
+			SourceAmount:      p.amount,
+			Destination:       destination,
+			DestinationAmount: amount,
+			Path:              path,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].SourceAmount != results[j].SourceAmount {
+			return results[i].SourceAmount < results[j].SourceAmount
+		}
+		return len(results[i].Path) < len(results[j].Path)
+	})
+	return capHorizonPathsPerAsset(results, func(p horizonPathResult) string { return p.Source }), nil
+}
+
+func capHorizonPathsPerAsset(results []horizonPathResult, key func(horizonPathResult) string) []horizonPathResult {
+	counts := map[string]int{}
+	out := results[:0]
+	for _, result := range results {
+		if counts[key(result)] >= maxHorizonPathsPerAsset {
+			continue
+		}
+		counts[key(result)]++
+		out = append(out, result)
+	}
+	return out
+}
+
+// horizonPathGraph indexes offers and pools by the asset pair they exchange.
+// offers[selling][buying] is sorted best price first.
+type horizonPathGraph struct {
+	offers map[string]map[string][]PathOffer
+	pools  map[string]map[string]PathPool
+	// next[a] lists the assets a taker holding a can exchange into; prev[b]
+	// lists the assets that can be exchanged into b. Both are sorted so the
+	// search is deterministic.
+	next map[string][]string
+	prev map[string][]string
+}
+
+func newHorizonPathGraph(offers []PathOffer, pools []PathPool) *horizonPathGraph {
+	g := &horizonPathGraph{
+		offers: map[string]map[string][]PathOffer{},
+		pools:  map[string]map[string]PathPool{},
+		next:   map[string][]string{},
+		prev:   map[string][]string{},
+	}
+	edges := map[[2]string]bool{}
+	addEdge := func(from, to string) {
+		if from == to || edges[[2]string{from, to}] {
+			return
+		}
+		edges[[2]string{from, to}] = true
+		g.next[from] = append(g.next[from], to)
+		g.prev[to] = append(g.prev[to], from)
+	}
+
+	for _, offer := range offers {
+		if offer.Amount <= 0 || offer.PriceN <= 0 || offer.PriceD <= 0 || offer.Selling == offer.Buying {
+			continue
+		}
+		if g.offers[offer.Selling] == nil {
+			g.offers[offer.Selling] = map[string][]PathOffer{}
+		}
+		g.offers[offer.Selling][offer.Buying] = append(g.offers[offer.Selling][offer.Buying], offer)
+		// A taker gives the offer's buying asset and receives its selling asset.
+		addEdge(offer.Buying, offer.Selling)
+	}
+	for _, byBuying := range g.offers {
+		for _, book := range byBuying {
+			sort.SliceStable(book, func(i, j int) bool {
+				// n1/d1 < n2/d2 without floating point.
+				return new(big.Int).Mul(big.NewInt(book[i].PriceN), big.NewInt(book[j].PriceD)).Cmp(
+					new(big.Int).Mul(big.NewInt(book[j].PriceN), big.NewInt(book[i].PriceD))) < 0
+			})
+		}
+	}
+
+	for _, pool := range pools {
+		if pool.ReserveA <= 0 || pool.ReserveB <= 0 || pool.AssetA == pool.AssetB {
+			continue
+		}
+		for _, pair := range [][2]string{{pool.AssetA, pool.AssetB}, {pool.AssetB, pool.AssetA}} {
+			if g.pools[pair[0]] == nil {
+				g.pools[pair[0]] = map[string]PathPool{}
+			}
+			// Only constant-product pools at the protocol fee exist today, so a
+			// pair has at most one pool; keep the deepest if that changes.
+			if existing, ok := g.pools[pair[0]][pair[1]]; !ok || existing.ReserveA < pool.ReserveA {
+				g.pools[pair[0]][pair[1]] = pool
+			}
+			addEdge(pair[0], pair[1])
+		}
+	}
+
+	for _, adjacency := range []map[string][]string{g.next, g.prev} {
+		for _, assets := range adjacency {
+			sort.Strings(assets)
+		}
+	}
+	return g
+}
+
+// horizonPathState is a partial path during the search. assets runs from the
+// search origin to end; amount is what the path yields at end (strict send)
+// or what it costs at end (strict receive).
+type horizonPathState struct {
+	assets []string
+	end    string
+	amount int64
+}
+
+func (s horizonPathState) intermediate() []string {
+	if len(s.assets) <= 2 {
+		return []string{}
+	}
+	return append([]string{}, s.assets[1:len(s.assets)-1]...)
+}
+
+func (s horizonPathState) visited(asset string) bool {
+	for _, a := range s.assets {
+		if a == asset {
+			return true
+		}
+	}
+	return false
+}
+
+// search expands paths hop by hop from origin for up to maxPathLength+1 hops.
+// Forward searches (strict send) follow next and maximise the amount
+// received; backward searches (strict receive) follow prev and minimise the
+// amount spent. Every path that reaches a target is recorded; only the best
+// state per asset is expanded further, since a worse amount at the same asset
+// with no fewer hops cannot lead to a better path.
+func (g *horizonPathGraph) search(origin string, amount int64, targets []string, maxPathLength int, forward bool) []horizonPathState {
+	isTarget := map[string]bool{}
+	for _, target := range targets {
+		isTarget[target] = true
+	}
+	better := func(a, b int64) bool {
+		if forward {
+			return a > b
+		}
+		return a < b
+	}
+
+	found := []horizonPathState{}
+	if isTarget[origin] {
+		found = append(found, horizonPathState{assets: []string{origin}, end: origin, amount: amount})
+	}
+	best := map[string]int64{origin: amount}
+	frontier := []horizonPathState{{assets: []string{origin}, end: origin, amount: amount}}
+	for hop := 0; hop <= maxPathLength && len(frontier) > 0; hop++ {
+		candidates := map[string]horizonPathState{}
+		for _, state := range frontier {
+			neighbours := g.next[state.end]
+			if !forward {
+				neighbours = g.prev[state.end]
+			}
+			for _, asset := range neighbours {
+				if state.visited(asset) {
+					continue
+				}
+				var nextAmount int64
+				var ok bool
+				if forward {
+					nextAmount, ok = g.sendAmount(state.end, asset, state.amount)
+				} else {
+					nextAmount, ok = g.receiveAmount(asset, state.end, state.amount)
+				}
+				if !ok {
+					continue
+				}
+				next := horizonPathState{
+					assets: append(append([]string{}, state.assets...), asset),
+					end:    asset,
+					amount: nextAmount,
+				}
+				if isTarget[asset] {
+					found = append(found, next)
+				}
+				if current, ok := candidates[asset]; !ok || better(nextAmount, current.amount) {
+					candidates[asset] = next
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(candidates))
+		for asset := range candidates {
+			keys = append(keys, asset)
+		}
+		sort.Strings(keys)
+		frontier = frontier[:0:0]
+		for _, asset := range keys {
+			candidate := candidates[asset]
+			if prior, ok := best[asset]; ok && !better(candidate.amount, prior) {
+				continue
+			}
+			best[asset] = candidate.amount
+			frontier = append(frontier, candidate)
+		}
+	}
+	return found
+}
+
+// sendAmount is how much of to a taker receives for exactly amount of from,
+// using whichever of the order book or the pool pays more, as Horizon does.
+func (g *horizonPathGraph) sendAmount(from, to string, amount int64) (int64, bool) {
+	bookOut, bookOK := strictSendOffers(g.offers[to][from], amount)
+	pool, hasPool := g.pools[from][to]
+	if !hasPool {
+		return bookOut, bookOK
+	}
+	poolOut, poolOK := strictSendPool(pool, from, amount)
+	if !bookOK || (poolOK && poolOut > bookOut) {
+		return poolOut, poolOK
+	}
+	return bookOut, bookOK
+}
+
+// receiveAmount is how much of from a taker spends to receive exactly amount
+// of to, using whichever of the order book or the pool is cheaper.
+func (g *horizonPathGraph) receiveAmount(from, to string, amount int64) (int64, bool) {
+	bookIn, bookOK := strictReceiveOffers(g.offers[to][from], amount)
+	pool, hasPool := g.pools[from][to]
+	if !hasPool {
+		return bookIn, bookOK
+	}
+	poolIn, poolOK := strictReceivePool(pool, from, amount)
+	if !bookOK || (poolOK && poolIn < bookIn) {
+		return poolIn, poolOK
+	}
+	return bookIn, bookOK
+}
+
+// strictSendOffers crosses offers (best price first) with amount of their
+// buying asset and returns the selling asset received. Whole offers cost
+// ceil(amount * n / d); the last, partially taken offer yields
+// floor(remaining * d / n). The book must absorb the full amount.
+func strictSendOffers(book []PathOffer, amount int64) (int64, bool) {
+	if amount <= 0 {
+		return 0, false
+	}
+	remaining := big.NewInt(amount)
+	received := new(big.Int)
+	for _, offer := range book {
+		cost := ceilDiv(new(big.Int).Mul(big.NewInt(offer.Amount), big.NewInt(offer.PriceN)), big.NewInt(offer.PriceD))
+		if cost.Cmp(remaining) <= 0 {
+			received.Add(received, big.NewInt(offer.Amount))
+			remaining.Sub(remaining, cost)
+			if remaining.Sign() == 0 {
+				break
+			}
+			continue
+		}
+		partial := new(big.Int).Quo(new(big.Int).Mul(remaining, big.NewInt(offer.PriceD)), big.NewInt(offer.PriceN))
+		received.Add(received, partial)
+		remaining.SetInt64(0)
+		break
+	}
+	if remaining.Sign() != 0 {
+		return 0, false
+	}
+	return horizonPathAmount(received)
+}
+
+// strictReceiveOffers crosses offers (best price first) until amount of their
+// selling asset is bought and returns the buying asset spent, rounding each
+// offer's cost up in the offer owner's favour.
+func strictReceiveOffers(book []PathOffer, amount int64) (int64, bool) {
+	if amount <= 0 {
+		return 0, false
+	}
+	needed := big.NewInt(amount)
+	spent := new(big.Int)
+	for _, offer := range book {
+		take := big.NewInt(offer.Amount)
+		if take.Cmp(needed) > 0 {
+			take.Set(needed)
+		}
+		spent.Add(spent, ceilDiv(new(big.Int).Mul(take, big.NewInt(offer.PriceN)), big.NewInt(offer.PriceD)))
+		needed.Sub(needed, take)
+		if needed.Sign() == 0 {
+			break
+		}
+	}
+	if needed.Sign() != 0 {
+		return 0, false
+	}
+	return horizonPathAmount(spent)
+}
+
+// strictSendPool deposits amount of from into a constant-product pool and
+// returns the other reserve paid out, net of the pool fee:
+// out = floor(y * in * (1 - f) / (x + in * (1 - f))).
+func strictSendPool(pool PathPool, from string, amount int64) (int64, bool) {
+	if amount <= 0 {
+		return 0, false
+	}
+	x, y := horizonPoolReserves(pool, from)
+	feeFactor := big.NewInt(horizonPoolFeeDenominator - pool.FeeBP)
+	in := new(big.Int).Mul(big.NewInt(amount), feeFactor)
+	numerator := new(big.Int).Mul(big.NewInt(y), in)
+	denominator := new(big.Int).Add(new(big.Int).Mul(big.NewInt(x), big.NewInt(horizonPoolFeeDenominator)), in)
+	out := new(big.Int).Quo(numerator, denominator)
+	if out.Sign() <= 0 {
+		return 0, false
+	}
+	return horizonPathAmount(out)
+}
+
+// strictReceivePool returns the deposit of from needed to withdraw exactly
+// amount of the other reserve: in = ceil(x * out / ((y - out) * (1 - f))).
+func strictReceivePool(pool PathPool, from string, amount int64) (int64, bool) {
+	x, y := horizonPoolReserves(pool, from)
+	if amount <= 0 || amount >= y {
+		return 0, false
+	}
+	numerator := new(big.Int).Mul(new(big.Int).Mul(big.NewInt(x), big.NewInt(amount)), big.NewInt(horizonPoolFeeDenominator))
+	denominator := new(big.Int).Mul(big.NewInt(y-amount), big.NewInt(horizonPoolFeeDenominator-pool.FeeBP))
+	if denominator.Sign() <= 0 {
+		return 0, false
+	}
+	return horizonPathAmount(ceilDiv(numerator, denominator))
+}
+
+// horizonPoolReserves orders a pool's reserves as (deposited, withdrawn) for a
+// taker depositing from.
+func horizonPoolReserves(pool PathPool, from string) (int64, int64) {
+	if pool.AssetA == from {
+		return pool.ReserveA, pool.ReserveB
+	}
+	return pool.ReserveB, pool.ReserveA
+}
+
+func horizonPathAmount(v *big.Int) (int64, bool) {
+	if v.Sign() <= 0 || !v.IsInt64() {
+		return 0, false
+	}
+	return v.Int64(), true
+}
+
+func ceilDiv(n, d *big.Int) *big.Int {
+	q, m := new(big.Int).QuoRem(n, d, new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	testPathUSDC = "USDC:" + testHorizonUSDCIssuer
+	testPathEUR  = "EUR:" + testHorizonUSDCIssuer
+	testPathBTC  = "BTC:" + testHorizonUSDCIssuer
+)
+
+type fakeHorizonPathStateSource struct {
+	offers []PathOffer
+	pools  []PathPool
+	loads  int
+}
+
+func (f *fakeHorizonPathStateSource) GetPathFindingState(ctx context.Context) ([]PathOffer, []PathPool, error) {
+	f.loads++
+	return f.offers, f.pools, nil
+}
+
+// testPathFixture is a small order book, amounts in stroops:
+//
+//   - USDC for XLM: 100 at 2 and 100 at 3 (XLM per USDC)
+//   - EUR for USDC: 1000 at 0.5 (USDC per EUR)
+//   - EUR for XLM: 50 at 5, too shallow to fill the test payments directly
+//   - BTC for USDC: 1000 at 2, beaten by a 1000/1000 USDC/BTC pool at 30bp
+func testPathFixture() *fakeHorizonPathStateSource {
+	return &fakeHorizonPathStateSource{
+		offers: []PathOffer{
+			{Selling: testPathUSDC, Buying: "native", Amount: 100, PriceN: 3, PriceD: 1},
+			{Selling: testPathUSDC, Buying: "native", Amount: 100, PriceN: 2, PriceD: 1},
+			{Selling: testPathEUR, Buying: testPathUSDC, Amount: 1000, PriceN: 1, PriceD: 2},
+			{Selling: testPathEUR, Buying: "native", Amount: 50, PriceN: 5, PriceD: 1},
+			{Selling: testPathBTC, Buying: testPathUSDC, Amount: 1000, PriceN: 2, PriceD: 1},
+		},
+		pools: []PathPool{
+			{AssetA: testPathUSDC, AssetB: testPathBTC, ReserveA: 1000, ReserveB: 1000, FeeBP: 30},
+		},
+	}
+}
+
+// testPathChain is XLM followed by assets A1..A5, each sold 1:1 for the one
+// before it.
+func testPathChain() []string {
+	chain := []string{"native"}
+	for _, code := range []string{"A1", "A2", "A3", "A4", "A5"} {
+		chain = append(chain, code+":"+testHorizonUSDCIssuer)
+	}
+	return chain
+}
+
+func testPathChainSource(chain []string) *fakeHorizonPathStateSource {
+	source := &fakeHorizonPathStateSource{}
+	for i := 1; i < len(chain); i++ {
+		source.offers = append(source.offers, PathOffer{Selling: chain[i], Buying: chain[i-1], Amount: 1000, PriceN: 1, PriceD: 1})
+	}
+	return source
+}
+
+func newTestHorizonPathFinder(source horizonPathStateSource) *horizonPathFinder {
+	finder := newHorizonPathFinder(source)
+	finder.now = func() time.Time { return time.Unix(0, 0) }
+	return finder
+}
+
+func TestHorizonStrictSendPathsCrossBookAndPool(t *testing.T) {
+	finder := newTestHorizonPathFinder(testPathFixture())
+
+	paths, err := finder.FindStrictSendPaths(context.Background(), "native", 300, []string{testPathEUR, testPathBTC})
+	if err != nil {
+		t.Fatalf("FindStrictSendPaths: %v", err)
+	}
+	// 300 XLM buys 100 USDC at 2 and 33 at 3. 133 USDC then buys 266 EUR, or
+	// 117 BTC from the pool, which beats the 66 the BTC offers would give.
+	want := []horizonPathResult{
+		{Source: "native", SourceAmount: 300, Destination: testPathEUR, DestinationAmount: 266, Path: []string{testPathUSDC}},
+		{Source: "native", SourceAmount: 300, Destination: testPathBTC, DestinationAmount: 117, Path: []string{testPathUSDC}},
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("paths = %+v, want %+v", paths, want)
+	}
+}
+
+func TestHorizonStrictReceivePathsCrossBook(t *testing.T) {
+	finder := newTestHorizonPathFinder(testPathFixture())
+
+	paths, err := finder.FindStrictReceivePaths(context.Background(), []string{"native"}, testPathEUR, 266)
+	if err != nil {
+		t.Fatalf("FindStrictReceivePaths: %v", err)
+	}
+	// 266 EUR costs 133 USDC, which costs 100*2 + 33*3 = 299 XLM. The direct
+	// EUR/XLM offer only has 50 EUR and cannot fill the payment.
+	want := []horizonPathResult{
+		{Source: "native", SourceAmount: 299, Destination: testPathEUR, DestinationAmount: 266, Path: []string{testPathUSDC}},
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("paths = %+v, want %+v", paths, want)
+	}
+}
+
+func TestHorizonStrictReceivePathsReportSourceFirst(t *testing.T) {
+	chain := testPathChain()
+	finder := newTestHorizonPathFinder(testPathChainSource(chain))
+
+	paths, err := finder.FindStrictReceivePaths(context.Background(), []string{"native"}, chain[3], 10)
+	if err != nil {
+		t.Fatalf("FindStrictReceivePaths: %v", err)
+	}
+	// The search walks back from A3, but the path must read XLM -> A1 -> A2.
+	if len(paths) != 1 || paths[0].SourceAmount != 10 || !reflect.DeepEqual(paths[0].Path, chain[1:3]) {
+		t.Fatalf("paths = %+v", paths)
+	}
+}
+
+func TestHorizonPathsRespectMaxPathLength(t *testing.T) {
+	chain := testPathChain()
+	finder := newTestHorizonPathFinder(testPathChainSource(chain))
+
+	paths, err := finder.FindStrictSendPaths(context.Background(), "native", 10, []string{chain[4], chain[5]})
+	if err != nil {
+		t.Fatalf("FindStrictSendPaths: %v", err)
+	}
+	// A4 is three intermediate assets away; A5 would need four.
+	if len(paths) != 1 || paths[0].Destination != chain[4] || !reflect.DeepEqual(paths[0].Path, chain[1:4]) {
+		t.Fatalf("paths = %+v", paths)
+	}
+}
+
+func TestHorizonPathFinderCachesGraphForTTL(t *testing.T) {
+	source := testPathFixture()
+	finder := newHorizonPathFinder(source)
+	now := time.Unix(100, 0)
+	finder.now = func() time.Time { return now }
+	finder.ttl = 5 * time.Second
+
+	for i := 0; i < 2; i++ {
+		if _, err := finder.FindStrictSendPaths(context.Background(), "native", 300, []string{testPathEUR}); err != nil {
+			t.Fatalf("FindStrictSendPaths: %v", err)
+		}
+	}
+	if source.loads != 1 {
+		t.Fatalf("loads = %d, want 1", source.loads)
+	}
+	now = now.Add(5 * time.Second)
+	if _, err := finder.FindStrictSendPaths(context.Background(), "native", 300, []string{testPathEUR}); err != nil {
+		t.Fatalf("FindStrictSendPaths: %v", err)
+	}
+	if source.loads != 2 {
+		t.Fatalf("loads = %d, want 2", source.loads)
+	}
+}
+
+func TestHorizonPathExchangeRounding(t *testing.T) {
+	pool := PathPool{AssetA: testPathUSDC, AssetB: testPathBTC, ReserveA: 1000, ReserveB: 1000, FeeBP: 30}
+	if out, ok := strictSendPool(pool, testPathUSDC, 133); !ok || out != 117 {
+		t.Fatalf("strictSendPool = %d, %v", out, ok)
+	}
+	if in, ok := strictReceivePool(pool, testPathUSDC, 117); !ok || in != 133 {
+		t.Fatalf("strictReceivePool = %d, %v", in, ok)
+	}
+	if _, ok := strictReceivePool(pool, testPathUSDC, 1000); ok {
+		t.Fatal("strictReceivePool drained the pool")
+	}
+
+	book := []PathOffer{{Amount: 10, PriceN: 1, PriceD: 3}}
+	// Buying 10 at 1/3 costs ceil(10/3) = 4; 2 buys floor(2*3) = 6.
+	if in, ok := strictReceiveOffers(book, 10); !ok || in != 4 {
+		t.Fatalf("strictReceiveOffers = %d, %v", in, ok)
+	}
+	if out, ok := strictSendOffers(book, 2); !ok || out != 6 {
+		t.Fatalf("strictSendOffers = %d, %v", out, ok)
+	}
+	if _, ok := strictSendOffers(book, 5); ok {
+		t.Fatal("strictSendOffers accepted more than the book can absorb")
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// horizonTierUnion unions the same projection of a silver table across the
+// given schemas, tagging each arm with its position (1 = hot, 2 = cold) as
+// `source` so callers can DISTINCT ON an entity key and prefer the hot copy.
+func horizonTierUnion(schemas []string, table, columns, where string) string {
+	arms := make([]string, 0, len(schemas))
+	for i, schema := range schemas {
+		arms = append(arms, fmt.Sprintf("SELECT %s, %d as source FROM %s.%s WHERE %s", columns, i+1, schema, table, where))
+	}
+	return strings.Join(arms, "\n\t\t\tUNION ALL\n\t\t\t")
+}
+
+// queryHorizonTiers runs a query built over hot and cold silver and retries
+// hot-only when the cold catalog is missing a table or column. build receives
+// the schemas to read from, hot first.
+func queryHorizonTiers(ctx context.Context, unified *UnifiedDuckDBReader, name string, build func(schemas []string) string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := unified.db.QueryContext(ctx, build([]string{unified.hotSchema, unified.coldSchema}), args...)
+	if err == nil {
+		return rows, nil
+	}
+	if !isSchemaGapError(err) {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	logTierFallback(name, "unified", "hot", err)
+	rows, err = unified.db.QueryContext(ctx, build([]string{unified.hotSchema}), args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s (hot-only): %w", name, err)
+	}
+	return rows, nil
+}
+
+// horizonAssetString renders an asset in Horizon's canonical string form, as
+// used by claimable balance and liquidity pool resources.
+func horizonAssetString(asset AssetInfo) string {
+	if asset.Type == "native" || asset.Issuer == nil || *asset.Issuer == "" {
+		return "native"
+	}
+	return asset.Code + ":" + *asset.Issuer
+}
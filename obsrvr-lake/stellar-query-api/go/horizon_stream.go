@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellar/go-stellar-sdk/support/render/problem"
+	"github.com/stellar/go-stellar-sdk/toid"
+)
+
+const (
+	defaultHorizonStreamPollInterval = time.Second
+	defaultHorizonStreamHeartbeat    = 15 * time.Second
+	horizonStreamRetryMillis         = 1000
+)
+
+// horizonPageable is satisfied by every Horizon protocol record (ledgers,
+// transactions, operations, effects); the paging token doubles as the SSE
+// event id so clients can resume with Last-Event-ID.
+type horizonPageable interface {
+	PagingToken() string
+}
+
+// horizonStreamFetch loads the next page of records strictly after
+// page.Cursor in ascending order.
+type horizonStreamFetch func(ctx context.Context, page horizonPageQuery) ([]horizonPageable, error)
+
+// horizonLatestLedgerSource reports the newest ledger written to the hot
+// PostgreSQL tables. HotReader satisfies it.
+type horizonLatestLedgerSource interface {
+	GetHighWatermark() (int64, error)
+}
+
+func horizonStreamPollInterval() time.Duration {
+	return durationEnv("HORIZON_STREAM_POLL_INTERVAL", defaultHorizonStreamPollInterval)
+}
+
+func horizonStreamHeartbeat() time.Duration {
+	return durationEnv("HORIZON_STREAM_HEARTBEAT", defaultHorizonStreamHeartbeat)
+}
+
+func isHorizonStreamRequest(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, _, _ := strings.Cut(part, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), "text/event-stream") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// horizonLedgerWatcher polls the hot high watermark once for all open streams
+// and wakes waiters when a new ledger lands. Polling starts with the first
+// stream and runs for the lifetime of the process.
+type horizonLedgerWatcher struct {
+	source   horizonLatestLedgerSource
+	interval time.Duration
+
+	startOnce sync.Once
+	mu        sync.Mutex
+	latest    int64
+	err       error
+	advanced  chan struct{}
+}
+
+func newHorizonLedgerWatcher(source horizonLatestLedgerSource, interval time.Duration) *horizonLedgerWatcher {
+	if source == nil {
+		return nil
+	}
+	if interval <= 0 {
+		interval = defaultHorizonStreamPollInterval
+	}
+	return &horizonLedgerWatcher{source: source, interval: interval, advanced: make(chan struct{})}
+}
+
+func (lw *horizonLedgerWatcher) start() {
+	lw.startOnce.Do(func() {
+		lw.poll()
+		go func() {
+			ticker := time.NewTicker(lw.interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				lw.poll()
+			}
+		}()
+	})
+}
+
+func (lw *horizonLedgerWatcher) poll() {
+	latest, err := lw.source.GetHighWatermark()
+
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if err != nil {
+		if lw.err == nil {
+			log.Printf("horizon_stream path=ledger_watermark_error err=%v", err)
+		}
+		lw.err = err
+		return
+	}
+	lw.err = nil
+	if latest > lw.latest {
+		lw.latest = latest
+		close(lw.advanced)
+		lw.advanced = make(chan struct{})
+	}
+}
+
+// Latest returns the newest observed ledger. The error is only reported until
+// the first successful poll; later failures keep serving the last good value.
+func (lw *horizonLedgerWatcher) Latest() (int64, error) {
+	lw.start()
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.latest == 0 && lw.err != nil {
+		return 0, lw.err
+	}
+	return lw.latest, nil
+}
+
+func (lw *horizonLedgerWatcher) snapshot() (int64, <-chan struct{}) {
+	lw.start()
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.latest, lw.advanced
+}
+
+// horizonLedgerNowCursor maps cursor=now onto the ledger collection's paging
+// token for the latest ledger.
+func horizonLedgerNowCursor(latest int64) string {
+	return horizonLedgerPagingToken(latest)
+}
+
+// horizonTOIDNowCursor maps cursor=now onto the last TOID of the latest
+// ledger, so operations and transactions resume with the next ledger.
+func horizonTOIDNowCursor(latest int64) string {
+	return strconv.FormatInt(horizonAfterLedgerTOID(latest), 10)
+}
+
+// horizonEffectNowCursor is horizonTOIDNowCursor in the effect "op-order"
+// paging token form.
+func horizonEffectNowCursor(latest int64) string {
+	return fmt.Sprintf("%d-0", horizonAfterLedgerTOID(latest))
+}
+
+func horizonAfterLedgerTOID(sequence int64) int64 {
+	return toid.New(int32(sequence+1), 0, 0).ToInt64() - 1
+}
+
+func horizonPageables[T horizonPageable](records []T) []horizonPageable {
+	out := make([]horizonPageable, 0, len(records))
+	for _, record := range records {
+		out = append(out, record)
+	}
+	return out
+}
+
+func horizonQueryProblem(err error) problem.P {
+	if isQueryTimeout(err) {
+		return horizonProblem(http.StatusGatewayTimeout, "timeout", "Timeout", err.Error())
+	}
+	return horizonProblem(http.StatusInternalServerError, "server_error", "Internal Server Error", err.Error())
+}
+
+// streamHorizonCollection serves a Horizon collection as Server-Sent Events in
+// the shape the Horizon SDKs' stream() expects: an "open" event, then one event
+// per record with the paging token as its id. Once the backlog is drained it
+// follows new ledgers as they arrive in the hot tables.
+func (h *HorizonCompatHandlers) streamHorizonCollection(w http.ResponseWriter, r *http.Request, page horizonPageQuery, nowCursor func(int64) string, fetch horizonStreamFetch) {
+	if h.ledgerWatcher == nil {
+		renderHorizonProblem(w, r, horizonProblem(
+			http.StatusServiceUnavailable,
+			"data_unavailable",
+			"Data Unavailable",
+			"Horizon compatibility streaming requires the hot PostgreSQL reader.",
+		))
+		return
+	}
+	if page.Order != "asc" {
+		renderHorizonProblem(w, r, horizonProblem(http.StatusBadRequest, "bad_request", "Bad Request", "streaming requires order=asc"))
+		return
+	}
+	if page.Cursor == "now" {
+		latest, err := h.ledgerWatcher.Latest()
+		if err != nil {
+			renderHorizonProblem(w, r, horizonProblem(http.StatusServiceUnavailable, "data_unavailable", "Data Unavailable", err.Error()))
+			return
+		}
+		page.Cursor = nowCursor(latest)
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams outlive Service.WriteTimeoutSeconds by design.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := horizonEventStream{w: w, rc: rc}
+	if err := stream.open(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(horizonStreamHeartbeat())
+	defer heartbeat.Stop()
+
+	ctx := r.Context()
+	for ctx.Err() == nil {
+		observed, _ := h.ledgerWatcher.Latest()
+
+		fetchCtx, cancel := withInteractiveQueryTimeout(ctx)
+		records, err := fetch(fetchCtx, page)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil {
+				_ = stream.problem(horizonQueryProblem(err))
+			}
+			return
+		}
+		for _, record := range records {
+			if err := stream.record(record); err != nil {
+				return
+			}
+			page.Cursor = record.PagingToken()
+		}
+		if err := stream.flush(); err != nil {
+			return
+		}
+		if uint64(len(records)) >= page.Limit {
+			continue
+		}
+
+		if !h.waitForHorizonLedger(ctx, observed, heartbeat.C, &stream) {
+			return
+		}
+	}
+}
+
+// waitForHorizonLedger blocks until a ledger newer than observed is visible,
+// writing SSE comments on each heartbeat so idle proxies keep the connection.
+func (h *HorizonCompatHandlers) waitForHorizonLedger(ctx context.Context, observed int64, heartbeat <-chan time.Time, stream *horizonEventStream) bool {
+	for {
+		latest, advanced := h.ledgerWatcher.snapshot()
+		if latest > observed {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-advanced:
+		case <-heartbeat:
+			if err := stream.comment("keepalive"); err != nil {
+				return false
+			}
+		}
+	}
+}
+
+type horizonEventStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s *horizonEventStream) open() error {
+	if _, err := fmt.Fprintf(s.w, "retry: %d\nevent: open\ndata: \"hello\"\n\n", horizonStreamRetryMillis); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *horizonEventStream) record(record horizonPageable) error {
+	js, err := marshalHorizonJSON(record)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "id: %s\ndata: %s\n\n", record.PagingToken(), js)
+	return err
+}
+
+func (s *horizonEventStream) problem(p problem.P) error {
+	js, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "event: error\ndata: %s\n\n", js); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *horizonEventStream) comment(text string) error {
+	if _, err := fmt.Fprintf(s.w, ": %s\n\n", text); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *horizonEventStream) flush() error {
+	return s.rc.Flush()
+}
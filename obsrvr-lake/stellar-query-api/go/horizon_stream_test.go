@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	protocol "github.com/stellar/go-stellar-sdk/protocols/horizon"
+	"github.com/stellar/go-stellar-sdk/toid"
+)
+
+type fakeHorizonLatestLedgerSource struct {
+	mu     sync.Mutex
+	latest int64
+	err    error
+}
+
+func (f *fakeHorizonLatestLedgerSource) GetHighWatermark() (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.latest, f.err
+}
+
+func (f *fakeHorizonLatestLedgerSource) set(latest int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latest = latest
+}
+
+func serveHorizonStream(t *testing.T, router *mux.Router, target string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIsHorizonStreamRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/horizon-compat/ledgers", nil)
+	if isHorizonStreamRequest(req) {
+		t.Fatal("request without Accept should not stream")
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream;q=0.9")
+	if !isHorizonStreamRequest(req) {
+		t.Fatal("text/event-stream in Accept list should stream")
+	}
+}
+
+func TestHorizonLedgerStreamResolvesNowCursor(t *testing.T) {
+	source := &fakeHorizonLatestLedgerSource{latest: 500}
+	reader := &fakeHorizonLedgerReader{ledgers: []protocol.Ledger{{
+		ID:       "hash-501",
+		PT:       horizonLedgerPagingToken(501),
+		Hash:     "hash-501",
+		Sequence: 501,
+	}}}
+	handlers := &HorizonCompatHandlers{
+		ledgerReader:  reader,
+		ledgerWatcher: newHorizonLedgerWatcher(source, time.Hour),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/horizon-compat/ledgers", handlers.HandleLedgers).Methods("GET")
+
+	rec := serveHorizonStream(t, router, "/api/v1/horizon-compat/ledgers?cursor=now", nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("content type = %q", got)
+	}
+	if reader.page.Cursor != horizonLedgerPagingToken(500) || reader.page.Order != "asc" {
+		t.Fatalf("page = %+v, want cursor at latest ledger", reader.page)
+	}
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "retry: 1000\nevent: open\ndata: \"hello\"\n\n") {
+		t.Fatalf("stream did not open with hello event: %q", body)
+	}
+	if !strings.Contains(body, "id: "+horizonLedgerPagingToken(501)+"\ndata: {") {
+		t.Fatalf("stream missing ledger event: %q", body)
+	}
+	if !strings.Contains(body, `"sequence":501`) {
+		t.Fatalf("ledger event missing sequence: %q", body)
+	}
+}
+
+func TestHorizonOperationStreamResumesFromLastEventID(t *testing.T) {
+	source := &fakeHorizonLatestLedgerSource{latest: 123}
+	opID := toid.New(123, 1, 1).ToInt64()
+	reader := &fakeHorizonOperationReader{ops: []EnrichedOperation{{
+		TransactionHash: "txhash",
+		OperationID:     opID,
+		LedgerSequence:  123,
+		SourceAccount:   "GSOURCE",
+		Type:            1,
+		TxSuccessful:    true,
+	}}}
+	handlers := &HorizonCompatHandlers{
+		operationReader: reader,
+		ledgerWatcher:   newHorizonLedgerWatcher(source, time.Hour),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/horizon-compat/accounts/{id}/payments", handlers.HandleAccountPayments).Methods("GET")
+
+	resumeFrom := toid.New(122, 0, 0).String()
+	rec := serveHorizonStream(t, router, "/api/v1/horizon-compat/accounts/GSOURCE/payments?cursor=now", http.Header{
+		"Last-Event-ID": []string{resumeFrom},
+	})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if reader.filters.Cursor == nil || reader.filters.Cursor.LedgerSequence != 122 {
+		t.Fatalf("cursor = %+v, want Last-Event-ID to override cursor=now", reader.filters.Cursor)
+	}
+	if reader.filters.AccountID != "GSOURCE" || !reader.filters.PaymentsOnly {
+		t.Fatalf("filters = %+v", reader.filters)
+	}
+	if !strings.Contains(rec.Body.String(), "id: "+toid.New(123, 1, 1).String()+"\n") {
+		t.Fatalf("stream missing operation event: %q", rec.Body.String())
+	}
+}
+
+func TestHorizonStreamRejectsDescendingOrder(t *testing.T) {
+	handlers := &HorizonCompatHandlers{
+		effectReader:  &fakeHorizonEffectReader{},
+		ledgerWatcher: newHorizonLedgerWatcher(&fakeHorizonLatestLedgerSource{latest: 1}, time.Hour),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/horizon-compat/effects", handlers.HandleEffects).Methods("GET")
+
+	rec := serveHorizonStream(t, router, "/api/v1/horizon-compat/effects?order=desc", nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHorizonStreamWithoutHotReaderIsUnavailable(t *testing.T) {
+	handlers := &HorizonCompatHandlers{effectReader: &fakeHorizonEffectReader{}}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/horizon-compat/effects", handlers.HandleEffects).Methods("GET")
+
+	rec := serveHorizonStream(t, router, "/api/v1/horizon-compat/effects", nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHorizonLedgerWatcherWakesOnNewLedger(t *testing.T) {
+	source := &fakeHorizonLatestLedgerSource{latest: 10}
+	watcher := newHorizonLedgerWatcher(source, time.Hour)
+
+	latest, advanced := watcher.snapshot()
+	if latest != 10 {
+		t.Fatalf("latest = %d, want 10", latest)
+	}
+
+	source.set(11)
+	watcher.poll()
+	select {
+	case <-advanced:
+	default:
+		t.Fatal("watcher did not signal the new ledger")
+	}
+	if got, err := watcher.Latest(); err != nil || got != 11 {
+		t.Fatalf("Latest() = (%d, %v), want 11", got, err)
+	}
+}
+
+func TestHorizonNowCursorsSortAfterLatestLedger(t *testing.T) {
+	lastOfLedger := horizonAfterLedgerTOID(500)
+	if parsed := toid.Parse(lastOfLedger); parsed.LedgerSequence != 500 {
+		t.Fatalf("after-ledger toid parses to ledger %d", parsed.LedgerSequence)
+	}
+	if lastOfLedger+1 != toid.New(501, 0, 0).ToInt64() {
+		t.Fatalf("after-ledger toid %d is not the last id of ledger 500", lastOfLedger)
+	}
+
+	cursor, err := decodeHorizonEffectCursor(horizonEffectNowCursor(500))
+	if err != nil || cursor == nil || cursor.OperationID == nil || *cursor.OperationID != lastOfLedger {
+		t.Fatalf("effect now cursor = %+v, %v", cursor, err)
+	}
+}
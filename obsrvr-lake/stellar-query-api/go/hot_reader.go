@@ -14,7 +14,7 @@ type HotReader struct {
 
 func NewHotReader(config PostgresConfig) (*HotReader, error) {
 	dsn := config.DSN()
-	db, err := sql.Open("postgres", dsn)
+	db, err := openInstrumentedDB("postgres", dsn, "bronze_hot", dataTierHot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open PostgreSQL connection: %w", err)
 	}
@@ -0,0 +1,451 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHybridShadowSampleRate    = 0.1
+	defaultHybridShadowMaxConcurrent = 4
+	defaultHybridShadowTimeout       = 10 * time.Second
+	// hybridShadowMaxDiffs caps the field diffs kept per mismatch; a missing
+	// row near the top of a page shifts every row after it.
+	hybridShadowMaxDiffs = 20
+	// hybridShadowRecentMismatches is how many mismatches each handler keeps
+	// for the admin report.
+	hybridShadowRecentMismatches = 10
+	hybridShadowMaxValueLen      = 200
+)
+
+// hybridShadow runs the unified reader behind a sample of hybrid-mode
+// requests. The response is always served from the legacy reader; the
+// unified read happens in a background goroutine with its own timeout, so it
+// adds no latency. At most maxConcurrent comparisons run at once and samples
+// arriving while every slot is busy are dropped, not queued.
+type hybridShadow struct {
+	sampleRate float64
+	timeout    time.Duration
+	slots      chan struct{}
+	random     func() float64
+	now        func() time.Time
+	started    time.Time
+
+	// wg tracks in-flight comparisons so tests can wait for them.
+	wg sync.WaitGroup
+
+	mu       sync.Mutex
+	handlers map[string]*hybridHandlerStats
+}
+
+type hybridHandlerStats struct {
+	Requests         uint64           `json:"requests"`
+	Sampled          uint64           `json:"sampled"`
+	Dropped          uint64           `json:"dropped"`
+	Compared         uint64           `json:"compared"`
+	Matched          uint64           `json:"matched"`
+	Mismatched       uint64           `json:"mismatched"`
+	LegacyErrors     uint64           `json:"legacy_errors"`
+	UnifiedErrors    uint64           `json:"unified_errors"`
+	LastUnifiedError string           `json:"last_unified_error,omitempty"`
+	recent           []hybridMismatch // newest last
+}
+
+// hybridMismatch is one sampled request whose legacy and unified results
+// differ.
+type hybridMismatch struct {
+	At        time.Time         `json:"at"`
+	Details   string            `json:"details"`
+	Diffs     []hybridFieldDiff `json:"diffs"`
+	Truncated bool              `json:"truncated,omitempty"`
+}
+
+// hybridFieldDiff describes one differing JSON field. Path uses dots for
+// object keys and [i] for array elements, rooted at the value the reader
+// returned. Nested objects and arrays are summarised rather than copied.
+type hybridFieldDiff struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"` // changed, length, missing_in_legacy, missing_in_unified
+	Legacy  any    `json:"legacy,omitempty"`
+	Unified any    `json:"unified,omitempty"`
+}
+
+// hybridPage is the comparison shape for cursor-paginated reader methods.
+type hybridPage[T any] struct {
+	Items      T      `json:"items"`
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+func newHybridShadow(cfg *HybridShadowConfig) *hybridShadow {
+	s := &hybridShadow{
+		sampleRate: defaultHybridShadowSampleRate,
+		timeout:    defaultHybridShadowTimeout,
+		random:     rand.Float64,
+		now:        time.Now,
+		handlers:   make(map[string]*hybridHandlerStats),
+	}
+	maxConcurrent := defaultHybridShadowMaxConcurrent
+	if cfg != nil {
+		if cfg.SampleRate != nil {
+			s.sampleRate = min(max(*cfg.SampleRate, 0), 1)
+		}
+		if cfg.MaxConcurrent > 0 {
+			maxConcurrent = cfg.MaxConcurrent
+		}
+		if cfg.TimeoutSeconds > 0 {
+			s.timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+		}
+	}
+	s.slots = make(chan struct{}, maxConcurrent)
+	s.started = s.now()
+	return s
+}
+
+// shadowCompare records a hybrid-mode request and, if it is sampled and a
+// slot is free, compares the legacy result the handler is about to serve with
+// a background call to unified. The legacy value is snapshotted before this
+// returns, so the handler may go on to modify it. unified must not use the
+// request context; it receives a fresh context bounded by the shadow timeout.
+func shadowCompare[T any](s *hybridShadow, endpoint, details string, legacy T, legacyErr error, unified func(context.Context) (T, error)) {
+	if s == nil || !s.admit(endpoint) {
+		return
+	}
+	var legacySnapshot any
+	if legacyErr == nil {
+		var err error
+		if legacySnapshot, err = normalizeForDiff(legacy); err != nil {
+			s.release()
+			log.Printf("⚠️ HYBRID [%s]: cannot snapshot legacy result: %v", endpoint, err)
+			return
+		}
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.release()
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		var unifiedResult T
+		var unifiedErr error
+		func() {
+			// No request middleware guards this goroutine, so a panic in the
+			// unified reader is recorded instead of taking the process down.
+			defer func() {
+				if p := recover(); p != nil {
+					unifiedErr = fmt.Errorf("panic: %v", p)
+				}
+			}()
+			unifiedResult, unifiedErr = unified(ctx)
+		}()
+		s.record(endpoint, details, legacySnapshot, legacyErr, unifiedResult, unifiedErr)
+	}()
+}
+
+// admit counts the request and reports whether it was sampled and got a slot.
+// A true result must be paired with release.
+func (s *hybridShadow) admit(endpoint string) bool {
+	sampled := s.sampleRate >= 1 || (s.sampleRate > 0 && s.random() < s.sampleRate)
+	acquired := false
+	if sampled {
+		select {
+		case s.slots <- struct{}{}:
+			acquired = true
+		default:
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.statsLocked(endpoint)
+	stats.Requests++
+	if sampled {
+		stats.Sampled++
+		if !acquired {
+			stats.Dropped++
+		}
+	}
+	return acquired
+}
+
+func (s *hybridShadow) release() { <-s.slots }
+
+func (s *hybridShadow) statsLocked(endpoint string) *hybridHandlerStats {
+	stats, ok := s.handlers[endpoint]
+	if !ok {
+		stats = &hybridHandlerStats{}
+		s.handlers[endpoint] = stats
+	}
+	return stats
+}
+
+func (s *hybridShadow) record(endpoint, details string, legacy any, legacyErr error, unified any, unifiedErr error) {
+	var diffs []hybridFieldDiff
+	truncated := false
+	if legacyErr == nil && unifiedErr == nil {
+		unifiedSnapshot, err := normalizeForDiff(unified)
+		if err != nil {
+			unifiedErr = fmt.Errorf("snapshot unified result: %w", err)
+		} else {
+			diffs, truncated = diffJSONValues(legacy, unifiedSnapshot, hybridShadowMaxDiffs)
+		}
+	}
+
+	s.mu.Lock()
+	stats := s.statsLocked(endpoint)
+	switch {
+	case legacyErr != nil:
+		// Nothing to compare against; the client saw the legacy error.
+		stats.LegacyErrors++
+		if unifiedErr != nil {
+			stats.UnifiedErrors++
+			stats.LastUnifiedError = unifiedErr.Error()
+		}
+	case unifiedErr != nil:
+		stats.UnifiedErrors++
+		stats.LastUnifiedError = unifiedErr.Error()
+	case len(diffs) == 0:
+		stats.Compared++
+		stats.Matched++
+	default:
+		stats.Compared++
+		stats.Mismatched++
+		stats.recent = append(stats.recent, hybridMismatch{
+			At:        s.now().UTC(),
+			Details:   details,
+			Diffs:     diffs,
+			Truncated: truncated,
+		})
+		if len(stats.recent) > hybridShadowRecentMismatches {
+			stats.recent = stats.recent[len(stats.recent)-hybridShadowRecentMismatches:]
+		}
+	}
+	s.mu.Unlock()
+
+	switch {
+	case unifiedErr != nil && legacyErr == nil:
+		log.Printf("⚠️ HYBRID [%s]: unified failed: %v | %s", endpoint, unifiedErr, details)
+	case len(diffs) > 0:
+		logMismatch(endpoint, details, diffs)
+	}
+}
+
+// logMismatch logs when legacy and unified reader results differ (for hybrid mode validation)
+func logMismatch(endpoint, details string, diffs []hybridFieldDiff) {
+	queryAPIMetrics.incHybridMismatch(endpoint)
+	first := ""
+	if len(diffs) > 0 {
+		first = fmt.Sprintf(" first=%s (%s)", diffs[0].Path, diffs[0].Kind)
+	}
+	log.Printf("⚠️ HYBRID MISMATCH [%s]: %d field diffs%s | %s", endpoint, len(diffs), first, details)
+}
+
+// normalizeForDiff round-trips v through JSON so both readers' results are
+// compared exactly as a client would see them.
+func normalizeForDiff(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var out any
+	if err := decoder.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// diffJSONValues walks two decoded JSON values and returns up to limit
+// differences in document order. The bool reports whether more were found.
+func diffJSONValues(legacy, unified any, limit int) ([]hybridFieldDiff, bool) {
+	d := jsonDiffer{limit: limit}
+	d.walk("$", legacy, unified)
+	return d.diffs, d.truncated
+}
+
+type jsonDiffer struct {
+	limit     int
+	diffs     []hybridFieldDiff
+	truncated bool
+}
+
+func (d *jsonDiffer) add(diff hybridFieldDiff) {
+	if len(d.diffs) >= d.limit {
+		d.truncated = true
+		return
+	}
+	d.diffs = append(d.diffs, diff)
+}
+
+func (d *jsonDiffer) walk(path string, legacy, unified any) {
+	if d.truncated {
+		return
+	}
+	switch l := legacy.(type) {
+	case map[string]any:
+		u, ok := unified.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(l)+len(u))
+		for key := range l {
+			keys = append(keys, key)
+		}
+		for key := range u {
+			if _, ok := l[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			lv, inLegacy := l[key]
+			uv, inUnified := u[key]
+			child := path + "." + key
+			switch {
+			case !inUnified:
+				d.add(hybridFieldDiff{Path: child, Kind: "missing_in_unified", Legacy: summarizeDiffValue(lv)})
+			case !inLegacy:
+				d.add(hybridFieldDiff{Path: child, Kind: "missing_in_legacy", Unified: summarizeDiffValue(uv)})
+			default:
+				d.walk(child, lv, uv)
+			}
+		}
+		return
+	case []any:
+		u, ok := unified.([]any)
+		if !ok {
+			break
+		}
+		if len(l) != len(u) {
+			d.add(hybridFieldDiff{Path: path, Kind: "length", Legacy: len(l), Unified: len(u)})
+		}
+		for i := 0; i < min(len(l), len(u)); i++ {
+			d.walk(path+"["+strconv.Itoa(i)+"]", l[i], u[i])
+		}
+		return
+	default:
+		if legacy == unified {
+			return
+		}
+	}
+	d.add(hybridFieldDiff{Path: path, Kind: "changed", Legacy: summarizeDiffValue(legacy), Unified: summarizeDiffValue(unified)})
+}
+
+// summarizeDiffValue keeps scalars (long strings truncated) and replaces
+// objects and arrays with a short description, so one diff cannot carry a
+// whole page of results.
+func summarizeDiffValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		return fmt.Sprintf("object(%d fields)", len(t))
+	case []any:
+		return fmt.Sprintf("array(%d items)", len(t))
+	case string:
+		if len(t) > hybridShadowMaxValueLen {
+			return t[:hybridShadowMaxValueLen] + "…"
+		}
+		return t
+	case nil:
+		return "null"
+	default:
+		return t
+	}
+}
+
+type hybridHandlerReport struct {
+	Handler string `json:"handler"`
+	hybridHandlerStats
+	MismatchRate     float64          `json:"mismatch_rate"`
+	RecentMismatches []hybridMismatch `json:"recent_mismatches"`
+}
+
+// report snapshots per-handler stats, highest mismatch rate first. An empty
+// handler returns every handler.
+func (s *hybridShadow) report(handler string) []hybridHandlerReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]hybridHandlerReport, 0, len(s.handlers))
+	for name, stats := range s.handlers {
+		if handler != "" && name != handler {
+			continue
+		}
+		entry := hybridHandlerReport{
+			Handler:            name,
+			hybridHandlerStats: *stats,
+			RecentMismatches:   append([]hybridMismatch{}, stats.recent...),
+		}
+		entry.recent = nil
+		if stats.Compared > 0 {
+			entry.MismatchRate = float64(stats.Mismatched) / float64(stats.Compared)
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].MismatchRate != out[j].MismatchRate {
+			return out[i].MismatchRate > out[j].MismatchRate
+		}
+		return out[i].Handler < out[j].Handler
+	})
+	return out
+}
+
+// HandleHybridMismatches reports how often the unified reader disagrees with
+// the legacy reader, per handler, with the most recent field-level diffs.
+// Use it to decide when reader_mode can move from hybrid to unified.
+func (h *SilverHandlers) HandleHybridMismatches(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"reader_mode": string(h.readerMode),
+		"enabled":     h.shadow != nil,
+	}
+	if h.shadow == nil {
+		response["handlers"] = []hybridHandlerReport{}
+		respondJSON(w, response)
+		return
+	}
+
+	handlers := h.shadow.report(r.URL.Query().Get("handler"))
+	var totals hybridHandlerStats
+	for _, entry := range handlers {
+		totals.Requests += entry.Requests
+		totals.Sampled += entry.Sampled
+		totals.Dropped += entry.Dropped
+		totals.Compared += entry.Compared
+		totals.Matched += entry.Matched
+		totals.Mismatched += entry.Mismatched
+		totals.LegacyErrors += entry.LegacyErrors
+		totals.UnifiedErrors += entry.UnifiedErrors
+	}
+	totalRate := 0.0
+	if totals.Compared > 0 {
+		totalRate = float64(totals.Mismatched) / float64(totals.Compared)
+	}
+
+	response["sample_rate"] = h.shadow.sampleRate
+	response["max_concurrent"] = cap(h.shadow.slots)
+	response["timeout_ms"] = h.shadow.timeout.Milliseconds()
+	response["since"] = h.shadow.started.UTC()
+	response["totals"] = map[string]interface{}{
+		"requests":       totals.Requests,
+		"sampled":        totals.Sampled,
+		"dropped":        totals.Dropped,
+		"compared":       totals.Compared,
+		"matched":        totals.Matched,
+		"mismatched":     totals.Mismatched,
+		"legacy_errors":  totals.LegacyErrors,
+		"unified_errors": totals.UnifiedErrors,
+		"mismatch_rate":  totalRate,
+	}
+	response["handlers"] = handlers
+	respondJSON(w, response)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestHybridShadow(sampleRate float64, maxConcurrent int) *hybridShadow {
+	s := newHybridShadow(&HybridShadowConfig{SampleRate: &sampleRate, MaxConcurrent: maxConcurrent})
+	s.random = func() float64 { return 0.5 }
+	return s
+}
+
+func TestShadowCompareRecordsFieldDiffs(t *testing.T) {
+	s := newTestHybridShadow(1, 4)
+	legacy := []AccountCurrent{{AccountID: "GA", Balance: "10"}, {AccountID: "GB", Balance: "5"}}
+	shadowCompare(s, "HandleTopAccounts", "limit=2", legacy, nil,
+		func(context.Context) ([]AccountCurrent, error) {
+			return []AccountCurrent{{AccountID: "GA", Balance: "11"}}, nil
+		})
+	// The legacy value was snapshotted, so later edits do not leak into the
+	// comparison.
+	legacy[0].Balance = "11"
+	shadowCompare(s, "HandleTopAccounts", "limit=2", []AccountCurrent{}, nil,
+		func(context.Context) ([]AccountCurrent, error) { return []AccountCurrent{}, nil })
+	s.wg.Wait()
+
+	report := s.report("")
+	if len(report) != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	got := report[0]
+	if got.Requests != 2 || got.Compared != 2 || got.Matched != 1 || got.Mismatched != 1 || got.MismatchRate != 0.5 {
+		t.Fatalf("stats = %+v", got)
+	}
+	if len(got.RecentMismatches) != 1 || got.RecentMismatches[0].Details != "limit=2" {
+		t.Fatalf("recent = %+v", got.RecentMismatches)
+	}
+	diffs := got.RecentMismatches[0].Diffs
+	if len(diffs) != 2 ||
+		diffs[0] != (hybridFieldDiff{Path: "$", Kind: "length", Legacy: 2, Unified: 1}) ||
+		diffs[1] != (hybridFieldDiff{Path: "$[0].balance", Kind: "changed", Legacy: "10", Unified: "11"}) {
+		t.Fatalf("diffs = %+v", diffs)
+	}
+}
+
+func TestShadowCompareSamplesAndDropsWhenBusy(t *testing.T) {
+	s := newTestHybridShadow(0.25, 1)
+	shadowCompare(s, "HandleAccountCurrent", "", 1, nil,
+		func(context.Context) (int, error) { t.Fatal("unsampled request ran unified"); return 0, nil })
+
+	s.sampleRate = 1
+	release := make(chan struct{})
+	shadowCompare(s, "HandleAccountCurrent", "", 1, nil,
+		func(context.Context) (int, error) { <-release; return 1, nil })
+	shadowCompare(s, "HandleAccountCurrent", "", 1, nil,
+		func(context.Context) (int, error) { t.Fatal("dropped sample ran unified"); return 0, nil })
+	close(release)
+	s.wg.Wait()
+
+	got := s.report("HandleAccountCurrent")[0]
+	if got.Requests != 3 || got.Sampled != 2 || got.Dropped != 1 || got.Compared != 1 || got.Matched != 1 {
+		t.Fatalf("stats = %+v", got)
+	}
+}
+
+func TestShadowCompareCountsErrorsAndPanics(t *testing.T) {
+	s := newTestHybridShadow(1, 4)
+	s.timeout = 10 * time.Millisecond
+	shadowCompare(s, "HandleAccountHistory", "", 1, nil, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	shadowCompare(s, "HandleAccountHistory", "", 1, nil, func(context.Context) (int, error) {
+		var reader *UnifiedDuckDBReader
+		return 0, reader.db.PingContext(context.Background())
+	})
+	shadowCompare(s, "HandleAccountHistory", "", 0, errors.New("legacy down"),
+		func(context.Context) (int, error) { return 1, nil })
+	s.wg.Wait()
+
+	got := s.report("")[0]
+	if got.UnifiedErrors != 2 || got.LegacyErrors != 1 || got.Compared != 0 || got.LastUnifiedError == "" {
+		t.Fatalf("stats = %+v", got)
+	}
+}
+
+func TestDiffJSONValuesReportsMissingKeysAndTruncates(t *testing.T) {
+	legacy, _ := normalizeForDiff(map[string]any{"a": 1, "b": map[string]any{"x": 1}, "long": []int{1, 2, 3}})
+	unified, _ := normalizeForDiff(map[string]any{"a": 1, "c": "new", "long": []int{1, 5, 6}})
+	diffs, truncated := diffJSONValues(legacy, unified, 3)
+	want := []hybridFieldDiff{
+		{Path: "$.b", Kind: "missing_in_unified", Legacy: "object(1 fields)"},
+		{Path: "$.c", Kind: "missing_in_legacy", Unified: "new"},
+		{Path: "$.long[1]", Kind: "changed", Legacy: json.Number("2"), Unified: json.Number("5")},
+	}
+	if !truncated || len(diffs) != len(want) {
+		t.Fatalf("diffs = %+v, truncated = %v", diffs, truncated)
+	}
+	for i := range want {
+		if diffs[i] != want[i] {
+			t.Fatalf("diff %d = %+v, want %+v", i, diffs[i], want[i])
+		}
+	}
+}
+
+func TestHandleHybridMismatchesReportsRates(t *testing.T) {
+	h := &SilverHandlers{readerMode: ReaderModeHybrid, shadow: newTestHybridShadow(1, 4)}
+	shadowCompare(h.shadow, "HandleTopAccounts", "", 1, nil, func(context.Context) (int, error) { return 2, nil })
+	shadowCompare(h.shadow, "HandleAccountCurrent", "", 1, nil, func(context.Context) (int, error) { return 1, nil })
+	h.shadow.wg.Wait()
+
+	rec := httptest.NewRecorder()
+	h.HandleHybridMismatches(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/hybrid/mismatches", nil))
+	var body struct {
+		ReaderMode string `json:"reader_mode"`
+		Enabled    bool   `json:"enabled"`
+		Totals     struct {
+			Compared     uint64  `json:"compared"`
+			MismatchRate float64 `json:"mismatch_rate"`
+		} `json:"totals"`
+		Handlers []struct {
+			Handler      string  `json:"handler"`
+			MismatchRate float64 `json:"mismatch_rate"`
+		} `json:"handlers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if rec.Code != http.StatusOK || body.ReaderMode != "hybrid" || !body.Enabled ||
+		body.Totals.Compared != 2 || body.Totals.MismatchRate != 0.5 ||
+		len(body.Handlers) != 2 || body.Handlers[0].Handler != "HandleTopAccounts" || body.Handlers[0].MismatchRate != 1 {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	(&SilverHandlers{readerMode: ReaderModeLegacy}).HandleHybridMismatches(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "{\"enabled\":false,\"handlers\":[],\"reader_mode\":\"legacy\"}\n" {
+		t.Fatalf("legacy mode: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 
@@ -156,6 +157,11 @@ func mainWithSilver() {
 		// Create handlers based on reader mode
 		silverHandlers = NewSilverHandlers(unifiedSilverReader, unifiedDuckDBReader, readerMode)
 		log.Printf("✅ Silver API handlers initialized (reader_mode: %s)", readerMode)
+		if readerMode == ReaderModeHybrid && unifiedDuckDBReader != nil {
+			silverHandlers.shadow = newHybridShadow(config.Query.Hybrid)
+			log.Printf("✅ Hybrid shadow comparison enabled (sample_rate: %g, max_concurrent: %d)",
+				silverHandlers.shadow.sampleRate, cap(silverHandlers.shadow.slots))
+		}
 	} else {
 		log.Println("⚠️  Silver layer not fully configured - Silver endpoints disabled")
 		log.Println("     Requires both postgres_silver and ducklake_silver in config")
@@ -199,6 +205,63 @@ func mainWithSilver() {
 		log.Println("ℹ️  Contract Event Index not configured - contract event lookups disabled")
 	}
 
+	var apiKeys *apiKeyLimiter
+	if config.APIKeys != nil && config.APIKeys.Enabled {
+		store, err := newAPIKeyStore(config.APIKeys)
+		if err != nil {
+			log.Fatalf("Failed to create API key store: %v", err)
+		}
+		apiKeys, err = newAPIKeyLimiter(config.APIKeys, store)
+		if err != nil {
+			log.Fatalf("Invalid api_keys config: %v", err)
+		}
+		if config.APIKeys.AnonymousPlan == "" {
+			log.Println("✅ API keys required on all non-exempt routes")
+		} else {
+			log.Printf("✅ API keys enabled; anonymous requests use plan %q", config.APIKeys.AnonymousPlan)
+		}
+	}
+
+	var cache *responseCache
+	if config.ResponseCache != nil && config.ResponseCache.Enabled {
+		latestLedger := func(ctx context.Context) (int64, error) {
+			return hotReader.GetHighWatermark()
+		}
+		if silverHotReader != nil {
+			latestLedger = silverHotReader.GetServingLatestLedgerSequence
+		}
+		cache, err = newResponseCache(config.ResponseCache, config.Query, latestLedger)
+		if err != nil {
+			log.Fatalf("Failed to create response cache: %v", err)
+		}
+		if config.ResponseCache.Redis != nil && config.ResponseCache.Redis.Address != "" {
+			log.Printf("✅ Response cache enabled (in-memory LRU + Redis at %s)", config.ResponseCache.Redis.Address)
+		} else {
+			log.Println("✅ Response cache enabled (in-memory LRU)")
+		}
+	}
+
+	var complianceArchive *ArchiveProcessor
+	if unifiedSilverReader != nil {
+		archiveStore, err := newArchiveStore(config.ComplianceArchive)
+		if err != nil {
+			log.Fatalf("Failed to create compliance archive store: %v", err)
+		}
+		artifactStore, err := newArchiveArtifactStore(config.ComplianceArchive)
+		if err != nil {
+			log.Fatalf("Failed to create compliance archive artifact store: %v", err)
+		}
+		complianceArchive = NewArchiveProcessor(archiveStore, unifiedSilverReader, artifactStore, config.ComplianceArchive)
+		if err := complianceArchive.Start(context.Background()); err != nil {
+			log.Fatalf("Failed to start compliance archive workers: %v", err)
+		}
+		if config.ComplianceArchive != nil && config.ComplianceArchive.Postgres != nil {
+			log.Printf("✅ Compliance archive jobs persisted in PostgreSQL (%d workers)", complianceArchive.workers)
+		} else {
+			log.Printf("ℹ️  Compliance archive jobs kept in memory (%d workers) - configure compliance_archive.postgres to survive restarts", complianceArchive.workers)
+		}
+	}
+
 	app := &application{
 		config:                config,
 		queryService:          queryService,
@@ -213,6 +276,9 @@ func mainWithSilver() {
 		contractIndexHandlers: contractIndexHandlers,
 		contractIndexReader:   contractIndexReader,
 		contractArtifacts:     contractArtifacts,
+		apiKeys:               apiKeys,
+		responseCache:         cache,
+		complianceArchive:     complianceArchive,
 		readerMode:            readerMode,
 	}
 
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Data tiers a request can be served from. They label request metrics and
+// query timeouts; a request that touches several tiers is labelled with all
+// of them joined by "+", e.g. "hot+cold".
+const (
+	dataTierServing = "serving"
+	dataTierHot     = "hot"
+	dataTierCold    = "cold"
+	dataTierUnified = "unified"
+	dataTierNone    = "none"
+
+	unmatchedRouteLabel = "unmatched"
+)
+
+// httpLatencyBuckets are the Prometheus client default buckets, in seconds.
+var httpLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var httpRequestMetricLabels = []string{"route", "method", "status", "tier"}
+
+// queryAPIMetrics is the process-wide registry served at /metrics. It is a
+// package variable so leaf helpers such as logMismatch and the instrumented
+// database connections can record without threading it through every reader.
+var queryAPIMetrics = newMetricsRegistry()
+
+type metricsRegistry struct {
+	mu             sync.Mutex
+	requests       map[string]*latencyHistogram
+	queryTimeouts  map[string]uint64
+	hybridMismatch map[string]uint64
+	responseCache  map[string]uint64
+	pools          map[string]*sql.DB
+	openStreams    int64
+	startTime      time.Time
+}
+
+type latencyHistogram struct {
+	labels  []string
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		requests:       map[string]*latencyHistogram{},
+		queryTimeouts:  map[string]uint64{},
+		hybridMismatch: map[string]uint64{},
+		responseCache:  map[string]uint64{},
+		pools:          map[string]*sql.DB{},
+		startTime:      time.Now(),
+	}
+}
+
+func (m *metricsRegistry) observeRequest(route, method string, status int, tier string, elapsed time.Duration) {
+	labels := []string{route, method, strconv.Itoa(status), tier}
+	key := strings.Join(labels, "\xff")
+	seconds := elapsed.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.requests[key]
+	if !ok {
+		h = &latencyHistogram{labels: labels, buckets: make([]uint64, len(httpLatencyBuckets))}
+		m.requests[key] = h
+	}
+	for i, bound := range httpLatencyBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// addOpenStreams moves the open SSE stream gauge by delta.
+func (m *metricsRegistry) addOpenStreams(delta int64) {
+	m.mu.Lock()
+	m.openStreams += delta
+	m.mu.Unlock()
+}
+
+func (m *metricsRegistry) incQueryTimeout(tier string) {
+	m.mu.Lock()
+	m.queryTimeouts[tier]++
+	m.mu.Unlock()
+}
+
+func (m *metricsRegistry) incHybridMismatch(endpoint string) {
+	m.mu.Lock()
+	m.hybridMismatch[endpoint]++
+	m.mu.Unlock()
+}
+
+func (m *metricsRegistry) incResponseCache(result string) {
+	m.mu.Lock()
+	m.responseCache[result]++
+	m.mu.Unlock()
+}
+
+// registerPool exposes database/sql pool statistics for db under the given
+// pool name. Registering the same name again replaces the earlier pool.
+func (m *metricsRegistry) registerPool(name string, db *sql.DB) {
+	m.mu.Lock()
+	m.pools[name] = db
+	m.mu.Unlock()
+}
+
+// writeTo renders every metric in the Prometheus text exposition format.
+func (m *metricsRegistry) writeTo(w io.Writer) {
+	m.mu.Lock()
+	requests := make([]latencyHistogram, 0, len(m.requests))
+	for _, h := range m.requests {
+		copied := *h
+		copied.buckets = append([]uint64(nil), h.buckets...)
+		requests = append(requests, copied)
+	}
+	timeouts := copyCounterMap(m.queryTimeouts)
+	mismatches := copyCounterMap(m.hybridMismatch)
+	cacheResults := copyCounterMap(m.responseCache)
+	pools := make(map[string]*sql.DB, len(m.pools))
+	for name, db := range m.pools {
+		pools[name] = db
+	}
+	openStreams := m.openStreams
+	m.mu.Unlock()
+
+	sort.Slice(requests, func(i, j int) bool {
+		return strings.Join(requests[i].labels, "\xff") < strings.Join(requests[j].labels, "\xff")
+	})
+	fmt.Fprintln(w, "# HELP stellar_query_api_http_request_duration_seconds HTTP request latency by mux route template, status code and data tier.")
+	fmt.Fprintln(w, "# TYPE stellar_query_api_http_request_duration_seconds histogram")
+	for _, h := range requests {
+		labels := formatMetricLabels(httpRequestMetricLabels, h.labels)
+		for i, bound := range httpLatencyBuckets {
+			fmt.Fprintf(w, "stellar_query_api_http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				labels, strconv.FormatFloat(bound, 'g', -1, 64), h.buckets[i])
+		}
+		fmt.Fprintf(w, "stellar_query_api_http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(w, "stellar_query_api_http_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "stellar_query_api_http_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+
+	fmt.Fprintln(w, "# HELP stellar_query_api_http_open_streams Server-sent event streams currently open. Streams are not in the request latency histogram.")
+	fmt.Fprintln(w, "# TYPE stellar_query_api_http_open_streams gauge")
+	fmt.Fprintf(w, "stellar_query_api_http_open_streams %d\n", openStreams)
+
+	writeCounterFamily(w, "stellar_query_api_query_timeouts_total",
+		"Database queries that failed with a query timeout, by data tier.", "tier", timeouts)
+	writeCounterFamily(w, "stellar_query_api_hybrid_mismatches_total",
+		"Hybrid reader-mode comparisons where legacy and unified results differed, by endpoint.", "endpoint", mismatches)
+	writeCounterFamily(w, "stellar_query_api_response_cache_requests_total",
+		"Requests to cacheable routes by result: hit, miss, or bypass for ranges without a final end_ledger.", "result", cacheResults)
+
+	poolNames := make([]string, 0, len(pools))
+	for name := range pools {
+		poolNames = append(poolNames, name)
+	}
+	sort.Strings(poolNames)
+	stats := make(map[string]sql.DBStats, len(pools))
+	for _, name := range poolNames {
+		stats[name] = pools[name].Stats()
+	}
+	for _, family := range []struct {
+		name, help, kind string
+		value            func(sql.DBStats) string
+	}{
+		{"stellar_query_api_db_pool_max_open_connections", "Configured connection limit per database pool (0 is unlimited).", "gauge",
+			func(s sql.DBStats) string { return strconv.Itoa(s.MaxOpenConnections) }},
+		{"stellar_query_api_db_pool_open_connections", "Open connections per database pool.", "gauge",
+			func(s sql.DBStats) string { return strconv.Itoa(s.OpenConnections) }},
+		{"stellar_query_api_db_pool_in_use_connections", "Connections currently running a query per database pool.", "gauge",
+			func(s sql.DBStats) string { return strconv.Itoa(s.InUse) }},
+		{"stellar_query_api_db_pool_idle_connections", "Idle connections per database pool.", "gauge",
+			func(s sql.DBStats) string { return strconv.Itoa(s.Idle) }},
+		{"stellar_query_api_db_pool_wait_count_total", "Queries that had to wait for a free connection per database pool.", "counter",
+			func(s sql.DBStats) string { return strconv.FormatInt(s.WaitCount, 10) }},
+		{"stellar_query_api_db_pool_wait_seconds_total", "Time spent waiting for a free connection per database pool.", "counter",
+			func(s sql.DBStats) string { return strconv.FormatFloat(s.WaitDuration.Seconds(), 'g', -1, 64) }},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", family.name, family.help, family.name, family.kind)
+		for _, name := range poolNames {
+			fmt.Fprintf(w, "%s{pool=\"%s\"} %s\n", family.name, escapeMetricLabel(name), family.value(stats[name]))
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP stellar_query_api_start_time_seconds Unix time the process started.")
+	fmt.Fprintln(w, "# TYPE stellar_query_api_start_time_seconds gauge")
+	fmt.Fprintf(w, "stellar_query_api_start_time_seconds %d\n", m.startTime.Unix())
+}
+
+func copyCounterMap(in map[string]uint64) map[string]uint64 {
+	out := make(map[string]uint64, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+func writeCounterFamily(w io.Writer, name, help, label string, values map[string]uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %d\n", name, label, escapeMetricLabel(k), values[k])
+	}
+}
+
+func formatMetricLabels(names, values []string) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=\"" + escapeMetricLabel(values[i]) + "\""
+	}
+	return strings.Join(parts, ",")
+}
+
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeMetricLabel(value string) string {
+	return metricLabelEscaper.Replace(value)
+}
+
+// handleMetrics serves the registry in the Prometheus text format.
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	queryAPIMetrics.writeTo(w)
+}
+
+const requestObservationContextKey contextKey = "request_observation"
+
+// requestObservation collects what a request touched while it is served: the
+// mux route it matched and the data tiers its queries ran against.
+type requestObservation struct {
+	mu    sync.Mutex
+	route string
+	tiers map[string]bool
+}
+
+func (o *requestObservation) tierLabel() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.tiers) == 0 {
+		return dataTierNone
+	}
+	tiers := make([]string, 0, len(o.tiers))
+	for tier := range o.tiers {
+		tiers = append(tiers, tier)
+	}
+	sort.Strings(tiers)
+	return strings.Join(tiers, "+")
+}
+
+// observeDataTier records that the request behind ctx read from tier. It is a
+// no-op outside an instrumented request, e.g. for startup and warmup queries.
+func observeDataTier(ctx context.Context, tier string) {
+	o, _ := ctx.Value(requestObservationContextKey).(*requestObservation)
+	if o == nil || tier == "" {
+		return
+	}
+	o.mu.Lock()
+	if o.tiers == nil {
+		o.tiers = map[string]bool{}
+	}
+	o.tiers[tier] = true
+	o.mu.Unlock()
+}
+
+// metricsMiddleware times every request and records it once the handler
+// returns. It wraps the router, so the route template is filled in from inside
+// by routeTemplateMiddleware after mux has matched. SSE streams last as long
+// as the client stays connected, which says nothing about latency, so they
+// are counted as open streams instead.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isHorizonStreamRequest(r) {
+			queryAPIMetrics.addOpenStreams(1)
+			defer queryAPIMetrics.addOpenStreams(-1)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		o := &requestObservation{route: unmatchedRouteLabel}
+		sr := newStatusRecorder(w)
+		next.ServeHTTP(sr, r.WithContext(context.WithValue(r.Context(), requestObservationContextKey, o)))
+
+		o.mu.Lock()
+		route := o.route
+		o.mu.Unlock()
+		queryAPIMetrics.observeRequest(route, r.Method, sr.statusCode, o.tierLabel(), time.Since(start))
+	})
+}
+
+// routeTemplateMiddleware is installed with router.Use, which mux only runs
+// for matched routes, and labels the request with the route's path template
+// so ids and hashes do not explode metric cardinality.
+func routeTemplateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if o, _ := r.Context().Value(requestObservationContextKey).(*requestObservation); o != nil {
+			if route := mux.CurrentRoute(r); route != nil {
+				template, err := route.GetPathTemplate()
+				if err == nil {
+					o.mu.Lock()
+					o.route = template
+					o.mu.Unlock()
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+
+	duckdb "github.com/duckdb/duckdb-go/v2"
+	"github.com/lib/pq"
+)
+
+// openInstrumentedDB opens a database/sql pool whose connections attribute
+// each query to a data tier: the request serving the query is labelled with
+// that tier, and queries that time out are counted against it. The pool is
+// also registered for the /metrics pool gauges under the given name.
+//
+// Queries against the serving schema of a hot PostgreSQL pool are attributed
+// to the serving tier.
+func openInstrumentedDB(driverName, dsn, pool, tier string) (*sql.DB, error) {
+	var connector driver.Connector
+	var err error
+	switch driverName {
+	case "duckdb":
+		connector, err = duckdb.NewConnector(dsn, nil)
+	case "postgres":
+		connector, err = pq.NewConnector(dsn)
+	default:
+		return nil, fmt.Errorf("unsupported driver %q", driverName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(&tierConnector{Connector: connector, tier: tier})
+	queryAPIMetrics.registerPool(pool, db)
+	return db, nil
+}
+
+type tierConnector struct {
+	driver.Connector
+	tier string
+}
+
+func (c *tierConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tierConn{Conn: conn, tier: c.tier}, nil
+}
+
+// Close releases the underlying connector; sql.DB.Close calls it. DuckDB's
+// connector owns the database instance.
+func (c *tierConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// tierConn forwards every optional driver interface to the wrapped connection
+// so database/sql behaves exactly as it would with the driver directly.
+type tierConn struct {
+	driver.Conn
+	tier string
+}
+
+func (c *tierConn) tierFor(query string) string {
+	if c.tier == dataTierHot && strings.Contains(query, "serving.") {
+		return dataTierServing
+	}
+	return c.tier
+}
+
+func (c *tierConn) observe(ctx context.Context, query string, err error) {
+	tier := c.tierFor(query)
+	observeDataTier(ctx, tier)
+	if err != nil && isQueryTimeout(err) {
+		queryAPIMetrics.incQueryTimeout(tier)
+	}
+}
+
+func (c *tierConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.observe(ctx, query, err)
+	}
+	return rows, err
+}
+
+func (c *tierConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	result, err := execer.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.observe(ctx, query, err)
+	}
+	return result, err
+}
+
+func (c *tierConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	observeDataTier(ctx, c.tierFor(query))
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *tierConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck // fallback for drivers without BeginTx
+}
+
+func (c *tierConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tierConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tierConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *tierConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+)
+
+// dsnConnector adapts a registered driver to driver.Connector so tests can
+// put sqlmock connections behind tierConnector.
+type dsnConnector struct {
+	drv driver.Driver
+	dsn string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.drv.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.drv }
+
+func useTestMetricsRegistry(t *testing.T) *metricsRegistry {
+	t.Helper()
+	previous := queryAPIMetrics
+	queryAPIMetrics = newMetricsRegistry()
+	t.Cleanup(func() { queryAPIMetrics = previous })
+	return queryAPIMetrics
+}
+
+func scrapeMetrics(t *testing.T) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	return rec.Body.String()
+}
+
+func TestMetricsLabelRequestsByRouteTemplateAndTier(t *testing.T) {
+	useTestMetricsRegistry(t)
+	mockDB, mock, err := sqlmock.NewWithDSN("metrics-tier-test")
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer mockDB.Close()
+	db := sql.OpenDB(&tierConnector{Connector: dsnConnector{drv: mockDB.Driver(), dsn: "metrics-tier-test"}, tier: dataTierHot})
+	defer db.Close()
+
+	mock.ExpectQuery("FROM serving.sv_accounts_current").WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+	mock.ExpectQuery("FROM accounts_snapshot").WillReturnRows(sqlmock.NewRows([]string{"n"}).AddRow(1))
+	mock.ExpectQuery("FROM accounts_snapshot").WillReturnError(context.DeadlineExceeded)
+
+	router := mux.NewRouter()
+	router.Use(routeTemplateMiddleware)
+	router.HandleFunc("/api/v1/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		for _, query := range []string{"SELECT 1 FROM serving.sv_accounts_current", "SELECT 1 FROM accounts_snapshot"} {
+			rows, err := db.QueryContext(r.Context(), query)
+			if err != nil {
+				t.Errorf("QueryContext: %v", err)
+				return
+			}
+			rows.Close()
+		}
+		if _, err := db.QueryContext(r.Context(), "SELECT 1 FROM accounts_snapshot"); err == nil {
+			t.Error("expected timeout")
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}).Methods("GET")
+	handler := chainMiddleware(router, metricsMiddleware)
+
+	for _, target := range []string{"/api/v1/accounts/GA1", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+	logMismatch("HandleAccountCurrent", "test", []hybridFieldDiff{{Path: "$", Kind: "changed"}})
+
+	body := scrapeMetrics(t)
+	for _, want := range []string{
+		`stellar_query_api_http_request_duration_seconds_count{route="/api/v1/accounts/{id}",method="GET",status="503",tier="hot+serving"} 1`,
+		`stellar_query_api_http_request_duration_seconds_bucket{route="/api/v1/accounts/{id}",method="GET",status="503",tier="hot+serving",le="+Inf"} 1`,
+		`stellar_query_api_http_request_duration_seconds_count{route="unmatched",method="GET",status="404",tier="none"} 1`,
+		`stellar_query_api_query_timeouts_total{tier="hot"} 1`,
+		`stellar_query_api_hybrid_mismatches_total{endpoint="HandleAccountCurrent"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics missing %q:\n%s", want, body)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMetricsCountStreamsOutsideLatencyHistogram(t *testing.T) {
+	useTestMetricsRegistry(t)
+	var during string
+	router := mux.NewRouter()
+	router.Use(routeTemplateMiddleware)
+	router.HandleFunc("/ledgers", func(w http.ResponseWriter, r *http.Request) {
+		during = scrapeMetrics(t)
+	}).Methods("GET")
+	handler := chainMiddleware(router, metricsMiddleware)
+
+	req := httptest.NewRequest(http.MethodGet, "/ledgers", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if want := "stellar_query_api_http_open_streams 1\n"; !strings.Contains(during, want) {
+		t.Fatalf("metrics during the stream missing %q:\n%s", want, during)
+	}
+	after := scrapeMetrics(t)
+	if want := "stellar_query_api_http_open_streams 0\n"; !strings.Contains(after, want) {
+		t.Fatalf("metrics after the stream missing %q:\n%s", want, after)
+	}
+	if strings.Contains(after, "stellar_query_api_http_request_duration_seconds_count") {
+		t.Fatalf("stream recorded in the latency histogram:\n%s", after)
+	}
+}
+
+func TestMetricsExposeRegisteredPoolStats(t *testing.T) {
+	registry := useTestMetricsRegistry(t)
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(7)
+	registry.registerPool("unified", db)
+
+	body := scrapeMetrics(t)
+	for _, want := range []string{
+		"# TYPE stellar_query_api_db_pool_in_use_connections gauge",
+		`stellar_query_api_db_pool_max_open_connections{pool="unified"} 7`,
+		`stellar_query_api_db_pool_wait_count_total{pool="unified"} 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestEscapeMetricLabel(t *testing.T) {
+	if got := escapeMetricLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Fatalf("escapeMetricLabel = %q", got)
+	}
+}
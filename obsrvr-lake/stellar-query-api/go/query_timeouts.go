@@ -15,6 +15,7 @@ const (
 	defaultHomeSummaryQueryTimeout = 2500 * time.Millisecond
 	defaultSmartWalletQueryTimeout = 1500 * time.Millisecond
 	defaultOptionalQueryTimeout    = 500 * time.Millisecond
+	defaultGraphQLQueryTimeout     = 8 * time.Second
 	defaultRecentLedgerWindow      = int64(2000)
 )
 
@@ -38,6 +39,13 @@ func smartWalletQueryTimeout() time.Duration {
 	return durationEnv("QUERY_API_SMART_WALLET_TIMEOUT", defaultSmartWalletQueryTimeout)
 }
 
+// graphQLQueryTimeout bounds a whole GraphQL request. It is longer than the
+// interactive budget because one query replaces several REST calls, and the
+// cost limit, not the timeout, is what keeps queries small.
+func graphQLQueryTimeout() time.Duration {
+	return durationEnv("QUERY_API_GRAPHQL_TIMEOUT", defaultGraphQLQueryTimeout)
+}
+
 func recentLedgerWindow() int64 {
 	value := strings.TrimSpace(envOrDefault("QUERY_API_RECENT_LEDGER_WINDOW", ""))
 	if value == "" {
@@ -80,6 +88,10 @@ func withSmartWalletQueryTimeout(ctx context.Context) (context.Context, context.
 	return context.WithTimeout(ctx, smartWalletQueryTimeout())
 }
 
+func withGraphQLQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, graphQLQueryTimeout())
+}
+
 func isQueryTimeout(err error) bool {
 	if err == nil {
 		return false
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultResponseCacheMemoryMB      = 256
+	defaultResponseCacheMaxBodyKB     = 1024
+	defaultResponseCacheImmutableTTL  = 24 * time.Hour
+	defaultResponseCacheRecentTTL     = 5 * time.Second
+	defaultResponseCacheFinalityLag   = 12
+	responseCacheLatestLedgerInterval = 2 * time.Second
+	responseCacheLatestLedgerTimeout  = 500 * time.Millisecond
+)
+
+// responseCacheKind says how a route's responses relate to ledger finality.
+type responseCacheKind int
+
+const (
+	// cacheRecent responses follow the chain tip and get the short TTL.
+	cacheRecent responseCacheKind = iota
+	// cacheLedgerVar responses describe the ledger in a path variable and are
+	// immutable once that ledger is final.
+	cacheLedgerVar
+	// cacheOperationVar responses describe the operation in a path variable;
+	// Horizon operation ids carry their ledger in the high 32 bits.
+	cacheOperationVar
+	// cacheCommitted responses describe a transaction by hash. A 200 means it
+	// is in a closed ledger, so they are always immutable. Only routes served
+	// from the transaction row itself qualify; derived views such as call
+	// graphs can be served before their projector has caught up.
+	cacheCommitted
+	// cacheLedgerRange responses cover start_ledger..end_ledger and are only
+	// cached, as immutable, when end_ledger is final.
+	cacheLedgerRange
+)
+
+type responseCachePolicy struct {
+	kind  responseCacheKind
+	param string
+}
+
+// responseCachePolicies lists the cacheable routes by mux path template.
+// Routes that are not listed, including account state and anything that can
+// change between ledgers without a ledger bound, are never cached.
+var responseCachePolicies = map[string]responseCachePolicy{
+	"/api/v1/silver/ledgers/recent":                         {kind: cacheRecent},
+	"/api/v1/silver/transactions/recent":                    {kind: cacheRecent},
+	"/api/v1/silver/stats/network":                          {kind: cacheRecent},
+	"/api/v1/bronze/stats/network":                          {kind: cacheRecent},
+	"/api/v1/silver/stats/fees":                             {kind: cacheRecent},
+	"/api/v1/silver/stats/soroban":                          {kind: cacheRecent},
+	"/api/v1/silver/stats/contracts":                        {kind: cacheRecent},
+	"/api/v1/silver/contracts/top":                          {kind: cacheRecent},
+	"/api/v1/silver/accounts/top":                           {kind: cacheRecent},
+	"/api/v1/silver/smart-accounts/stats":                   {kind: cacheRecent},
+	"/api/v1/home/summary":                                  {kind: cacheRecent},
+	"/api/v1/explorer/summary":                              {kind: cacheRecent},
+	"/api/v1/horizon-compat/fee_stats":                      {kind: cacheRecent},
+	"/api/v1/silver/ledgers/{seq:[0-9]+}":                   {kind: cacheLedgerVar, param: "seq"},
+	"/api/v1/silver/ledger/{seq:[0-9]+}":                    {kind: cacheLedgerVar, param: "seq"},
+	"/api/v1/silver/ledgers/{seq:[0-9]+}/summary":           {kind: cacheLedgerVar, param: "seq"},
+	"/api/v1/silver/ledger/{seq:[0-9]+}/summary":            {kind: cacheLedgerVar, param: "seq"},
+	"/api/v1/silver/ledgers/{seq:[0-9]+}/full":              {kind: cacheLedgerVar, param: "seq"},
+	"/api/v1/silver/ledger/{seq:[0-9]+}/full":               {kind: cacheLedgerVar, param: "seq"},
+	"/api/v1/silver/ledgers/{seq}/fees":                     {kind: cacheLedgerVar, param: "seq"},
+	"/api/v1/silver/ledgers/{seq}/soroban":                  {kind: cacheLedgerVar, param: "seq"},
+	"/api/v1/horizon-compat/ledgers/{sequence:[0-9]+}":      {kind: cacheLedgerVar, param: "sequence"},
+	"/api/v1/horizon-compat/operations/{id:[0-9]+}":         {kind: cacheOperationVar, param: "id"},
+	"/api/v1/horizon-compat/operations/{id:[0-9]+}/effects": {kind: cacheOperationVar, param: "id"},
+	"/api/v1/horizon-compat/transactions/{hash}":            {kind: cacheCommitted},
+	"/api/v1/horizon-compat/transactions/{hash}/operations": {kind: cacheCommitted},
+	"/api/v1/horizon-compat/transactions/{hash}/payments":   {kind: cacheCommitted},
+	"/api/v1/horizon-compat/transactions/{hash}/effects":    {kind: cacheCommitted},
+	"/api/v1/silver/tx/{hash}/decoded":                      {kind: cacheCommitted},
+	"/api/v1/silver/tx/{hash}/full":                         {kind: cacheCommitted},
+	"/transactions/{hash}":                                  {kind: cacheCommitted},
+	"/api/v1/index/transactions/{hash}":                     {kind: cacheCommitted},
+	"/api/v1/silver/events":                                 {kind: cacheLedgerRange},
+	"/api/v1/silver/events/generic":                         {kind: cacheLedgerRange},
+	"/api/v1/silver/events/contract/{contract_id}":          {kind: cacheLedgerRange},
+	"/api/v1/explorer/events":                               {kind: cacheLedgerRange},
+	"/api/v1/silver/operations/enriched":                    {kind: cacheLedgerRange},
+	"/api/v1/silver/accounts/{id}/transactions":             {kind: cacheLedgerRange},
+	"/api/v1/silver/addresses/{addr}/balances/history":      {kind: cacheLedgerRange},
+	"/api/v1/index/contracts/{contract_id}/ledgers":         {kind: cacheLedgerRange},
+}
+
+// cachedResponse is a stored 200 response. Expires is absolute so an entry
+// copied from Redis into the LRU keeps its remaining lifetime.
+type cachedResponse struct {
+	Header    http.Header `json:"header"`
+	Body      []byte      `json:"body"`
+	ETag      string      `json:"etag"`
+	Immutable bool        `json:"immutable"`
+	Expires   time.Time   `json:"expires"`
+}
+
+// responseCacheStore is a cache backend. Backends treat their own failures
+// as misses; the cache must never fail a request.
+type responseCacheStore interface {
+	Get(ctx context.Context, key string) (*cachedResponse, bool)
+	Set(ctx context.Context, key string, entry *cachedResponse)
+}
+
+type responseCache struct {
+	store          responseCacheStore
+	policies       map[string]responseCachePolicy
+	keyPrefix      string
+	maxBodyBytes   int
+	immutableTTL   time.Duration
+	recentTTL      time.Duration
+	finalityMargin int64
+	latestLedger   func(context.Context) (int64, error)
+	now            func() time.Time
+
+	mu             sync.Mutex
+	finalized      int64
+	finalizedAt    time.Time
+	latestErrorLog time.Time
+}
+
+func newResponseCache(cfg *ResponseCacheConfig, query QueryConfig, latestLedger func(context.Context) (int64, error)) (*responseCache, error) {
+	memoryMB := cfg.MaxMemoryMB
+	if memoryMB <= 0 {
+		memoryMB = defaultResponseCacheMemoryMB
+	}
+	maxBodyKB := cfg.MaxBodyKB
+	if maxBodyKB <= 0 {
+		maxBodyKB = defaultResponseCacheMaxBodyKB
+	}
+	immutableTTL := defaultResponseCacheImmutableTTL
+	if cfg.ImmutableTTLSeconds > 0 {
+		immutableTTL = time.Duration(cfg.ImmutableTTLSeconds) * time.Second
+	}
+	recentTTL := defaultResponseCacheRecentTTL
+	if query.CacheTTLSeconds > 0 {
+		recentTTL = time.Duration(query.CacheTTLSeconds) * time.Second
+	}
+	margin := int64(defaultResponseCacheFinalityLag)
+	if cfg.FinalityMarginLedgers != nil && *cfg.FinalityMarginLedgers >= 0 {
+		margin = *cfg.FinalityMarginLedgers
+	}
+
+	var store responseCacheStore = newLRUResponseCache(int64(memoryMB) << 20)
+	if cfg.Redis != nil && cfg.Redis.Address != "" {
+		remote, err := newRedisResponseCache(*cfg.Redis)
+		if err != nil {
+			return nil, err
+		}
+		store = &tieredResponseCache{local: store, remote: remote}
+	}
+	return &responseCache{
+		store:          store,
+		policies:       responseCachePolicies,
+		keyPrefix:      cfg.KeyPrefix,
+		maxBodyBytes:   maxBodyKB << 10,
+		immutableTTL:   immutableTTL,
+		recentTTL:      recentTTL,
+		finalityMargin: margin,
+		latestLedger:   latestLedger,
+		now:            time.Now,
+	}, nil
+}
+
+// middleware is installed with router.Use so the matched route template is
+// known. It runs inside the API-key middleware, so cached responses still
+// count against rate limits and quotas.
+func (c *responseCache) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		policy, ok := c.policies[template]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if policy.kind == cacheLedgerRange && r.URL.Query().Get("end_ledger") == "" {
+			queryAPIMetrics.incResponseCache("bypass")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := c.key(template, r)
+		if entry, ok := c.store.Get(r.Context(), key); ok && c.now().Before(entry.Expires) {
+			queryAPIMetrics.incResponseCache("hit")
+			c.writeEntry(w, r, entry, "HIT")
+			return
+		}
+		queryAPIMetrics.incResponseCache("miss")
+
+		before := make(map[string]bool, len(w.Header()))
+		for name := range w.Header() {
+			before[name] = true
+		}
+		rec := &cachingResponseWriter{ResponseWriter: w, status: http.StatusOK, limit: c.maxBodyBytes}
+		next.ServeHTTP(rec, r)
+		if rec.passthrough {
+			return
+		}
+		if rec.status != http.StatusOK || w.Header().Get("Cache-Control") != "" || w.Header().Get("ETag") != "" {
+			rec.flush()
+			return
+		}
+
+		ttl, immutable, cacheable := c.lifetime(r.Context(), policy, r)
+		if !cacheable {
+			rec.flush()
+			return
+		}
+		header := http.Header{}
+		for name, values := range w.Header() {
+			if !before[name] {
+				header[name] = append([]string(nil), values...)
+			}
+		}
+		sum := sha256.Sum256(rec.body.Bytes())
+		entry := &cachedResponse{
+			Header:    header,
+			Body:      rec.body.Bytes(),
+			ETag:      `"` + hex.EncodeToString(sum[:]) + `"`,
+			Immutable: immutable,
+			Expires:   c.now().Add(ttl),
+		}
+		c.store.Set(r.Context(), key, entry)
+		c.writeEntry(w, r, entry, "MISS")
+	})
+}
+
+// key normalizes the request to its route template, path and sorted query, so
+// parameter order does not split entries.
+func (c *responseCache) key(template string, r *http.Request) string {
+	sum := sha256.Sum256([]byte(template + "\n" + r.URL.Path + "?" + r.URL.Query().Encode()))
+	return c.keyPrefix + "resp:" + hex.EncodeToString(sum[:])
+}
+
+// lifetime decides how long a 200 response for policy may be kept and
+// whether it is immutable.
+func (c *responseCache) lifetime(ctx context.Context, policy responseCachePolicy, r *http.Request) (time.Duration, bool, bool) {
+	var ledger int64
+	switch policy.kind {
+	case cacheRecent:
+		return c.recentTTL, false, true
+	case cacheCommitted:
+		return c.immutableTTL, true, true
+	case cacheLedgerVar:
+		ledger, _ = strconv.ParseInt(mux.Vars(r)[policy.param], 10, 64)
+	case cacheOperationVar:
+		id, _ := strconv.ParseInt(mux.Vars(r)[policy.param], 10, 64)
+		ledger = id >> 32
+	case cacheLedgerRange:
+		ledger, _ = strconv.ParseInt(r.URL.Query().Get("end_ledger"), 10, 64)
+		if ledger <= 0 || ledger > c.finalizedLedger(ctx) {
+			return 0, false, false
+		}
+		return c.immutableTTL, true, true
+	}
+	if ledger > 0 && ledger <= c.finalizedLedger(ctx) {
+		return c.immutableTTL, true, true
+	}
+	return c.recentTTL, false, true
+}
+
+// finalizedLedger is the latest closed ledger less the finality margin,
+// refreshed at most every couple of seconds. It is 0, making nothing
+// immutable, while the latest ledger cannot be read.
+func (c *responseCache) finalizedLedger(ctx context.Context) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.latestLedger == nil || now.Sub(c.finalizedAt) < responseCacheLatestLedgerInterval {
+		return c.finalized
+	}
+	c.finalizedAt = now
+	lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), responseCacheLatestLedgerTimeout)
+	defer cancel()
+	latest, err := c.latestLedger(lookupCtx)
+	if err != nil {
+		if now.Sub(c.latestErrorLog) >= time.Minute {
+			c.latestErrorLog = now
+			log.Printf("Warning: response cache cannot read latest ledger, caching nothing as immutable: %v", err)
+		}
+		c.finalized = 0
+		return 0
+	}
+	c.finalized = max(0, latest-c.finalityMargin)
+	return c.finalized
+}
+
+func (c *responseCache) writeEntry(w http.ResponseWriter, r *http.Request, entry *cachedResponse, result string) {
+	for name, values := range entry.Header {
+		w.Header()[name] = values
+	}
+	maxAge := int64(entry.Expires.Sub(c.now()).Seconds())
+	if entry.Immutable {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.FormatInt(max(maxAge, 0), 10)+", immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.FormatInt(max(maxAge, 0), 10))
+	}
+	w.Header().Set("ETag", entry.ETag)
+	w.Header().Set("X-Cache", result)
+	if ifNoneMatchMatches(r.Header.Get("If-None-Match"), entry.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(entry.Body)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(entry.Body)
+}
+
+// cachingResponseWriter buffers a response so ETag and Cache-Control can be
+// set from the body. Once the body outgrows limit it gives up and streams the
+// rest straight through. It deliberately has no Unwrap: a Flush from the
+// handler would otherwise send headers before the cache could add its own.
+type cachingResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	limit       int
+	passthrough bool
+}
+
+func (w *cachingResponseWriter) WriteHeader(status int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+}
+
+func (w *cachingResponseWriter) Write(p []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(p)
+	}
+	w.wroteHeader = true
+	if w.body.Len()+len(p) > w.limit {
+		w.flush()
+		return w.ResponseWriter.Write(p)
+	}
+	return w.body.Write(p)
+}
+
+// flush sends what has been buffered and switches to pass-through.
+func (w *cachingResponseWriter) flush() {
+	if w.passthrough {
+		return
+	}
+	w.passthrough = true
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+// lruResponseCache is an in-memory LRU bounded by the bytes it holds.
+type lruResponseCache struct {
+	maxBytes int64
+
+	mu      sync.Mutex
+	bytes   int64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruResponseEntry struct {
+	key   string
+	value *cachedResponse
+	size  int64
+}
+
+func newLRUResponseCache(maxBytes int64) *lruResponseCache {
+	return &lruResponseCache{maxBytes: maxBytes, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *lruResponseCache) Get(ctx context.Context, key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruResponseEntry).value, true
+}
+
+func (c *lruResponseCache) Set(ctx context.Context, key string, entry *cachedResponse) {
+	size := int64(len(key) + len(entry.Body) + len(entry.ETag))
+	for name, values := range entry.Header {
+		size += int64(len(name))
+		for _, value := range values {
+			size += int64(len(value))
+		}
+	}
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.removeLocked(element)
+	}
+	c.entries[key] = c.order.PushFront(&lruResponseEntry{key: key, value: entry, size: size})
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+func (c *lruResponseCache) removeLocked(element *list.Element) {
+	entry := element.Value.(*lruResponseEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+}
+
+// tieredResponseCache reads the local LRU first and falls back to the shared
+// remote cache, copying remote hits into the LRU.
+type tieredResponseCache struct {
+	local  responseCacheStore
+	remote responseCacheStore
+}
+
+func (c *tieredResponseCache) Get(ctx context.Context, key string) (*cachedResponse, bool) {
+	if entry, ok := c.local.Get(ctx, key); ok {
+		return entry, true
+	}
+	entry, ok := c.remote.Get(ctx, key)
+	if ok {
+		c.local.Set(ctx, key, entry)
+	}
+	return entry, ok
+}
+
+func (c *tieredResponseCache) Set(ctx context.Context, key string, entry *cachedResponse) {
+	c.local.Set(ctx, key, entry)
+	c.remote.Set(ctx, key, entry)
+}
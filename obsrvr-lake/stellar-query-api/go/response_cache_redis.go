@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRedisCachePoolSize = 8
+	defaultRedisCacheTimeout  = 100 * time.Millisecond
+	// maxRedisBulkBytes guards against a corrupt length prefix.
+	maxRedisBulkBytes = 64 << 20
+)
+
+// redisResponseCache stores responses in a Redis-protocol server. It speaks
+// just enough RESP for AUTH, SELECT, GET and SET so the service does not need
+// a client library for three commands. Every failure is a miss; a slow or
+// absent Redis costs at most the configured timeout per request.
+type redisResponseCache struct {
+	address  string
+	password string
+	db       int
+	timeout  time.Duration
+	now      func() time.Time
+
+	idle chan *redisConn
+
+	mu          sync.Mutex
+	lastErrorAt time.Time
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newRedisResponseCache(cfg RedisCacheConfig) (*redisResponseCache, error) {
+	if strings.TrimSpace(cfg.Address) == "" {
+		return nil, errors.New("response_cache.redis.address is required")
+	}
+	poolSize := cfg.PoolSize
+	if poolSize <= 0 {
+		poolSize = defaultRedisCachePoolSize
+	}
+	timeout := defaultRedisCacheTimeout
+	if cfg.TimeoutMS > 0 {
+		timeout = time.Duration(cfg.TimeoutMS) * time.Millisecond
+	}
+	return &redisResponseCache{
+		address:  cfg.Address,
+		password: cfg.Password,
+		db:       cfg.DB,
+		timeout:  timeout,
+		now:      time.Now,
+		idle:     make(chan *redisConn, poolSize),
+	}, nil
+}
+
+func (c *redisResponseCache) Get(ctx context.Context, key string) (*cachedResponse, bool) {
+	reply, err := c.do(ctx, "GET", key)
+	if err != nil {
+		c.logError(err)
+		return nil, false
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return nil, false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		c.logError(fmt.Errorf("decode cached response: %w", err))
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (c *redisResponseCache) Set(ctx context.Context, key string, entry *cachedResponse) {
+	ttl := entry.Expires.Sub(c.now())
+	if ttl < time.Millisecond {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		c.logError(fmt.Errorf("encode cached response: %w", err))
+		return
+	}
+	if _, err := c.do(ctx, "SET", key, string(data), "PX", strconv.FormatInt(ttl.Milliseconds(), 10)); err != nil {
+		c.logError(err)
+	}
+}
+
+// do runs one command on a pooled connection. A connection that saw any error
+// is closed rather than returned, since its read position is unknown.
+func (c *redisResponseCache) do(ctx context.Context, args ...string) (any, error) {
+	conn, err := c.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.conn.SetDeadline(deadline)
+
+	reply, err := conn.command(args...)
+	if err != nil {
+		conn.conn.Close()
+		return nil, err
+	}
+	select {
+	case c.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+	if replyErr, ok := reply.(redisError); ok {
+		return nil, replyErr
+	}
+	return reply, nil
+}
+
+func (c *redisResponseCache) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+	dialer := net.Dialer{Timeout: c.timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{conn: netConn, reader: bufio.NewReader(netConn)}
+	_ = netConn.SetDeadline(time.Now().Add(c.timeout))
+	if c.password != "" {
+		if err := conn.expectOK("AUTH", c.password); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("redis AUTH: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if err := conn.expectOK("SELECT", strconv.Itoa(c.db)); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("redis SELECT: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *redisResponseCache) logError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now := c.now(); now.Sub(c.lastErrorAt) >= time.Minute {
+		c.lastErrorAt = now
+		log.Printf("Warning: response cache redis error (treated as a miss): %v", err)
+	}
+}
+
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func (c *redisConn) expectOK(args ...string) error {
+	reply, err := c.command(args...)
+	if err != nil {
+		return err
+	}
+	if replyErr, ok := reply.(redisError); ok {
+		return replyErr
+	}
+	return nil
+}
+
+// command writes args as a RESP array and reads one reply: a string for
+// simple strings, []byte for bulk strings, nil for a null bulk string, int64
+// for integers and redisError for error replies.
+func (c *redisConn) command(args ...string) (any, error) {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return redisError(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: bad bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if n > maxRedisBulkBytes {
+			return nil, fmt.Errorf("redis: bulk reply of %d bytes exceeds limit", n)
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	default:
+		return nil, fmt.Errorf("redis: unsupported reply %q", line)
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func newTestResponseCache(t *testing.T, latest int64) *responseCache {
+	t.Helper()
+	margin := int64(0)
+	cache, err := newResponseCache(&ResponseCacheConfig{FinalityMarginLedgers: &margin, MaxBodyKB: 1}, QueryConfig{CacheTTLSeconds: 5},
+		func(context.Context) (int64, error) { return latest, nil })
+	if err != nil {
+		t.Fatalf("newResponseCache: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	cache.now = func() time.Time { return now }
+	return cache
+}
+
+// serveCachedRoutes mounts test handlers on templates from
+// responseCachePolicies and counts how often each handler really runs.
+func serveCachedRoutes(cache *responseCache, calls map[string]int) http.Handler {
+	router := mux.NewRouter()
+	router.Use(cache.middleware)
+	handle := func(template string, status int, body string) {
+		router.HandleFunc(template, func(w http.ResponseWriter, r *http.Request) {
+			calls[template]++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			io.WriteString(w, body)
+		}).Methods("GET")
+	}
+	handle("/api/v1/silver/ledgers/{seq:[0-9]+}", http.StatusOK, `{"sequence":1}`)
+	handle("/api/v1/silver/ledgers/recent", http.StatusOK, `{"ledgers":[]}`)
+	handle("/api/v1/silver/events", http.StatusOK, `{"events":[]}`)
+	handle("/api/v1/horizon-compat/transactions/{hash}", http.StatusNotFound, `{"status":404}`)
+	handle("/api/v1/silver/tx/{hash}/full", http.StatusOK, strings.Repeat("x", 2048))
+	return router
+}
+
+func getCached(handler http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestResponseCacheServesFinalLedgersAsImmutable(t *testing.T) {
+	cache := newTestResponseCache(t, 100)
+	calls := map[string]int{}
+	handler := serveCachedRoutes(cache, calls)
+
+	first := getCached(handler, "/api/v1/silver/ledgers/50", nil)
+	if first.Code != http.StatusOK || first.Header().Get("X-Cache") != "MISS" ||
+		first.Header().Get("Cache-Control") != "public, max-age=86400, immutable" || first.Header().Get("ETag") == "" {
+		t.Fatalf("first: status = %d, headers = %v", first.Code, first.Header())
+	}
+	second := getCached(handler, "/api/v1/silver/ledgers/50", nil)
+	if second.Header().Get("X-Cache") != "HIT" || second.Body.String() != `{"sequence":1}` ||
+		second.Header().Get("Content-Type") != "application/json" || second.Header().Get("ETag") != first.Header().Get("ETag") {
+		t.Fatalf("second: headers = %v, body = %q", second.Header(), second.Body.String())
+	}
+	if calls["/api/v1/silver/ledgers/{seq:[0-9]+}"] != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls["/api/v1/silver/ledgers/{seq:[0-9]+}"])
+	}
+
+	notModified := getCached(handler, "/api/v1/silver/ledgers/50", http.Header{"If-None-Match": {first.Header().Get("ETag")}})
+	if notModified.Code != http.StatusNotModified || notModified.Body.Len() != 0 {
+		t.Fatalf("If-None-Match: status = %d, body = %q", notModified.Code, notModified.Body.String())
+	}
+
+	// A ledger past the latest closed one may still appear, so it only gets
+	// the short TTL.
+	ahead := getCached(handler, "/api/v1/silver/ledgers/101", nil)
+	if ahead.Header().Get("Cache-Control") != "public, max-age=5" {
+		t.Fatalf("unclosed ledger Cache-Control = %q", ahead.Header().Get("Cache-Control"))
+	}
+	recent := getCached(handler, "/api/v1/silver/ledgers/recent", nil)
+	if recent.Header().Get("Cache-Control") != "public, max-age=5" {
+		t.Fatalf("recent Cache-Control = %q", recent.Header().Get("Cache-Control"))
+	}
+}
+
+func TestResponseCacheOnlyCachesFinalRanges(t *testing.T) {
+	cache := newTestResponseCache(t, 100)
+	calls := map[string]int{}
+	handler := serveCachedRoutes(cache, calls)
+
+	for _, target := range []string{
+		"/api/v1/silver/events?start_ledger=1",
+		"/api/v1/silver/events?start_ledger=1",
+		"/api/v1/silver/events?start_ledger=1&end_ledger=101",
+		"/api/v1/silver/events?start_ledger=1&end_ledger=101",
+	} {
+		if rec := getCached(handler, target, nil); rec.Header().Get("X-Cache") != "" || rec.Header().Get("Cache-Control") != "" {
+			t.Fatalf("%s cached: headers = %v", target, rec.Header())
+		}
+	}
+	getCached(handler, "/api/v1/silver/events?start_ledger=1&end_ledger=100", nil)
+	// Query parameter order does not matter.
+	rec := getCached(handler, "/api/v1/silver/events?end_ledger=100&start_ledger=1", nil)
+	if rec.Header().Get("X-Cache") != "HIT" || !strings.HasSuffix(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Fatalf("final range: headers = %v", rec.Header())
+	}
+	if calls["/api/v1/silver/events"] != 5 {
+		t.Fatalf("handler calls = %d, want 5", calls["/api/v1/silver/events"])
+	}
+}
+
+func TestResponseCacheSkipsErrorsAndLargeBodies(t *testing.T) {
+	cache := newTestResponseCache(t, 100)
+	calls := map[string]int{}
+	handler := serveCachedRoutes(cache, calls)
+
+	for i := 0; i < 2; i++ {
+		if rec := getCached(handler, "/api/v1/horizon-compat/transactions/abc", nil); rec.Code != http.StatusNotFound || rec.Body.String() != `{"status":404}` {
+			t.Fatalf("404: status = %d, body = %q", rec.Code, rec.Body.String())
+		}
+		if rec := getCached(handler, "/api/v1/silver/tx/abc/full", nil); rec.Code != http.StatusOK || rec.Body.Len() != 2048 || rec.Header().Get("ETag") != "" {
+			t.Fatalf("large body: status = %d, len = %d, headers = %v", rec.Code, rec.Body.Len(), rec.Header())
+		}
+	}
+	if calls["/api/v1/horizon-compat/transactions/{hash}"] != 2 || calls["/api/v1/silver/tx/{hash}/full"] != 2 {
+		t.Fatalf("calls = %v", calls)
+	}
+}
+
+func TestLRUResponseCacheEvictsByBytes(t *testing.T) {
+	cache := newLRUResponseCache(30)
+	entry := func(body string) *cachedResponse { return &cachedResponse{Body: []byte(body)} }
+	ctx := context.Background()
+
+	cache.Set(ctx, "a", entry("0123456789"))
+	cache.Set(ctx, "b", entry("0123456789"))
+	cache.Get(ctx, "a")
+	cache.Set(ctx, "c", entry("0123456789"))
+	if _, ok := cache.Get(ctx, "b"); ok {
+		t.Fatal("least recently used entry was kept")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(ctx, key); !ok {
+			t.Fatalf("entry %q was evicted", key)
+		}
+	}
+	cache.Set(ctx, "huge", entry(strings.Repeat("x", 64)))
+	if _, ok := cache.Get(ctx, "huge"); ok || cache.bytes > 30 {
+		t.Fatalf("oversized entry stored, bytes = %d", cache.bytes)
+	}
+}
+
+// fakeRedis implements AUTH, GET and SET over RESP for one password.
+type fakeRedis struct {
+	password string
+	mu       sync.Mutex
+	values   map[string]string
+	ttls     map[string]string
+}
+
+func startFakeRedis(t *testing.T, password string) (*fakeRedis, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on loopback: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	server := &fakeRedis{password: password, values: map[string]string{}, ttls: map[string]string{}}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server, listener.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authed := s.password == ""
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		args := make([]string, n)
+		for i := range args {
+			lengthLine, _ := reader.ReadString('\n')
+			length, _ := strconv.Atoi(strings.TrimSpace(lengthLine[1:]))
+			buf := make([]byte, length+2)
+			io.ReadFull(reader, buf)
+			args[i] = string(buf[:length])
+		}
+		s.mu.Lock()
+		switch {
+		case args[0] == "AUTH" && args[1] == s.password:
+			authed = true
+			io.WriteString(conn, "+OK\r\n")
+		case !authed:
+			io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+		case args[0] == "SET":
+			s.values[args[1]], s.ttls[args[1]] = args[2], args[4]
+			io.WriteString(conn, "+OK\r\n")
+		case args[0] == "GET":
+			if value, ok := s.values[args[1]]; ok {
+				io.WriteString(conn, "$"+strconv.Itoa(len(value))+"\r\n"+value+"\r\n")
+			} else {
+				io.WriteString(conn, "$-1\r\n")
+			}
+		default:
+			io.WriteString(conn, "-ERR unknown command\r\n")
+		}
+		s.mu.Unlock()
+	}
+}
+
+func TestRedisResponseCacheRoundTrip(t *testing.T) {
+	server, addr := startFakeRedis(t, "secret")
+	cache, err := newRedisResponseCache(RedisCacheConfig{Address: addr, Password: "secret", TimeoutMS: 1000})
+	if err != nil {
+		t.Fatalf("newRedisResponseCache: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, ok := cache.Get(ctx, "missing"); ok {
+		t.Fatal("Get(missing) hit")
+	}
+	entry := &cachedResponse{
+		Header:    http.Header{"Content-Type": {"application/json"}},
+		Body:      []byte(`{"ok":true}`),
+		ETag:      `"abc"`,
+		Immutable: true,
+		Expires:   now.Add(90 * time.Second),
+	}
+	cache.Set(ctx, "k", entry)
+	got, ok := cache.Get(ctx, "k")
+	if !ok || string(got.Body) != `{"ok":true}` || got.ETag != `"abc"` || !got.Immutable ||
+		got.Header.Get("Content-Type") != "application/json" || !got.Expires.Equal(entry.Expires) {
+		t.Fatalf("Get(k) = %+v, %v", got, ok)
+	}
+	server.mu.Lock()
+	ttl := server.ttls["k"]
+	server.mu.Unlock()
+	if ttl != "90000" {
+		t.Fatalf("PX = %q, want 90000", ttl)
+	}
+
+	wrong, _ := newRedisResponseCache(RedisCacheConfig{Address: addr, Password: "nope", TimeoutMS: 1000})
+	if _, ok := wrong.Get(ctx, "k"); ok {
+		t.Fatal("Get with a bad password hit")
+	}
+}
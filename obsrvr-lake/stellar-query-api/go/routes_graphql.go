@@ -0,0 +1,21 @@
+package main
+
+import (
+	"log"
+
+	"github.com/gorilla/mux"
+)
+
+func (app *application) registerGraphQLRoutes(router *mux.Router) {
+	if app.unifiedSilverReader == nil {
+		log.Println("⚠️  graphql.enabled is set but the silver layer is not configured - /graphql disabled")
+		return
+	}
+	log.Println("Registering GraphQL endpoint:")
+	graphQLHandler, err := NewGraphQLHandler(app.newGraphQLReaders(), app.config.GraphQL)
+	if err != nil {
+		log.Fatalf("Failed to create GraphQL handler: %v", err)
+	}
+	router.HandleFunc("/graphql", graphQLHandler.HandleGraphQL).Methods("GET", "POST")
+	log.Printf("  ✓ /graphql (max_cost: %d, max_depth: %d)", graphQLHandler.maxCost, graphQLHandler.maxDepth)
+}
@@ -22,11 +22,25 @@ func (app *application) registerHorizonCompatRoutes(router *mux.Router) {
 	sub.HandleFunc("/accounts/{id}/operations", handlers.HandleAccountOperations).Methods("GET")
 	sub.HandleFunc("/accounts/{id}/payments", handlers.HandleAccountPayments).Methods("GET")
 	sub.HandleFunc("/accounts/{id}/effects", handlers.HandleAccountEffects).Methods("GET")
+	sub.HandleFunc("/accounts/{id}/offers", handlers.HandleAccountOffers).Methods("GET")
+	sub.HandleFunc("/accounts/{id}/trades", handlers.HandleAccountTrades).Methods("GET")
 	sub.HandleFunc("/operations/{id:[0-9]+}/effects", handlers.HandleOperationEffects).Methods("GET")
 	sub.HandleFunc("/operations/{id:[0-9]+}", handlers.HandleOperation).Methods("GET")
 	sub.HandleFunc("/operations", handlers.HandleOperations).Methods("GET")
 	sub.HandleFunc("/payments", handlers.HandlePayments).Methods("GET")
 	sub.HandleFunc("/effects", handlers.HandleEffects).Methods("GET")
+	sub.HandleFunc("/offers/{id:[0-9]+}", handlers.HandleOffer).Methods("GET")
+	sub.HandleFunc("/offers", handlers.HandleOffers).Methods("GET")
+	sub.HandleFunc("/trades", handlers.HandleTrades).Methods("GET")
+	sub.HandleFunc("/order_book", handlers.HandleOrderBook).Methods("GET")
+	sub.HandleFunc("/trade_aggregations", handlers.HandleTradeAggregations).Methods("GET")
+	sub.HandleFunc("/assets", handlers.HandleAssets).Methods("GET")
+	sub.HandleFunc("/claimable_balances/{id}", handlers.HandleClaimableBalance).Methods("GET")
+	sub.HandleFunc("/claimable_balances", handlers.HandleClaimableBalances).Methods("GET")
+	sub.HandleFunc("/liquidity_pools/{id}", handlers.HandleLiquidityPool).Methods("GET")
+	sub.HandleFunc("/liquidity_pools", handlers.HandleLiquidityPools).Methods("GET")
+	sub.HandleFunc("/paths/strict-send", handlers.HandleStrictSendPaths).Methods("GET")
+	sub.HandleFunc("/paths/strict-receive", handlers.HandleStrictReceivePaths).Methods("GET")
 
 	log.Println("Registering Horizon compatibility endpoints:")
 	log.Println("  ✓ /api/v1/horizon-compat/fee_stats")
@@ -41,9 +55,24 @@ func (app *application) registerHorizonCompatRoutes(router *mux.Router) {
 	log.Println("  ✓ /api/v1/horizon-compat/accounts/{id}/operations")
 	log.Println("  ✓ /api/v1/horizon-compat/accounts/{id}/payments")
 	log.Println("  ✓ /api/v1/horizon-compat/accounts/{id}/effects")
+	log.Println("  ✓ /api/v1/horizon-compat/accounts/{id}/offers")
+	log.Println("  ✓ /api/v1/horizon-compat/accounts/{id}/trades")
 	log.Println("  ✓ /api/v1/horizon-compat/operations/{id}")
 	log.Println("  ✓ /api/v1/horizon-compat/operations/{id}/effects")
 	log.Println("  ✓ /api/v1/horizon-compat/operations")
 	log.Println("  ✓ /api/v1/horizon-compat/payments")
 	log.Println("  ✓ /api/v1/horizon-compat/effects")
+	log.Println("  ✓ /api/v1/horizon-compat/offers")
+	log.Println("  ✓ /api/v1/horizon-compat/offers/{id}")
+	log.Println("  ✓ /api/v1/horizon-compat/trades")
+	log.Println("  ✓ /api/v1/horizon-compat/order_book")
+	log.Println("  ✓ /api/v1/horizon-compat/trade_aggregations")
+	log.Println("  ✓ /api/v1/horizon-compat/assets")
+	log.Println("  ✓ /api/v1/horizon-compat/claimable_balances")
+	log.Println("  ✓ /api/v1/horizon-compat/claimable_balances/{id}")
+	log.Println("  ✓ /api/v1/horizon-compat/liquidity_pools")
+	log.Println("  ✓ /api/v1/horizon-compat/liquidity_pools/{id}")
+	log.Println("  ✓ /api/v1/horizon-compat/paths/strict-send")
+	log.Println("  ✓ /api/v1/horizon-compat/paths/strict-receive")
+	log.Println("  ✓ Accept: text/event-stream on ledger, operation, payment, effect and account transaction collections")
 }
@@ -118,6 +118,9 @@ func (app *application) registerSilverRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/silver/relationships/{address_a}/{address_b}", silverHandlers.HandleRelationship).Methods("GET")
 	log.Println("  ✓ /api/v1/silver/relationships/{address_a}/{address_b}")
 
+	router.HandleFunc("/api/v1/admin/hybrid/mismatches", requireAdmin(silverHandlers.HandleHybridMismatches)).Methods("GET")
+	log.Println("  ✓ /api/v1/admin/hybrid/mismatches (admin)")
+
 	app.registerSilverContractRoutes(router)
 	app.registerSilverAnalyticsRoutes(router)
 	app.registerExplorerRoutes(router)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -248,11 +249,7 @@ func (h *SemanticHandlers) HandleSemanticContracts(w http.ResponseWriter, r *htt
 	walletType := r.URL.Query().Get("wallet_type")
 	functionAny := r.URL.Query().Get("function_any")
 
-	query := `SELECT contract_id, contract_type,
-		token_name, token_symbol, token_decimals,
-		deployer_account, deployed_at, deployed_ledger,
-		total_invocations, last_activity, unique_callers,
-		observed_functions, wallet_type
+	query := `SELECT ` + semanticContractColumns + `
 		FROM semantic_entities_contracts WHERE 1=1`
 
 	args := []any{}
@@ -740,51 +737,18 @@ func (h *SemanticHandlers) HandleSemanticAccountSummary(w http.ResponseWriter, r
 		return
 	}
 
-	query := `SELECT account_id, total_operations,
-		total_payments_sent, total_payments_received,
-		total_contract_calls, unique_contracts_called,
-		top_contract_id, top_contract_function,
-		is_contract_deployer, contracts_deployed,
-		first_activity, last_activity
-		FROM semantic_account_summary
-		WHERE account_id = $1`
-
-	var s SemanticAccountSummary
-	var tcid, tcf, fa, la sql.NullString
-	var isDeployer sql.NullBool
-	err := h.unified.hot.db.QueryRowContext(ctx, query, accountID).Scan(
-		&s.AccountID, &s.TotalOperations,
-		&s.TotalPaymentsSent, &s.TotalPaymentsRecvd,
-		&s.TotalContractCalls, &s.UniqueContractsCalled,
-		&tcid, &tcf,
-		&isDeployer, &s.ContractsDeployed,
-		&fa, &la,
-	)
-	if err == sql.ErrNoRows {
+	s, err := h.unified.hot.GetSemanticAccountSummary(ctx, accountID)
+	if err != nil {
+		respondSemanticError(w, "query failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if s == nil {
 		respondSemanticJSON(w, map[string]any{
 			"account": nil,
 			"found":   false,
 		})
 		return
 	}
-	if err != nil {
-		respondSemanticError(w, "query failed: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	s.IsContractDeployer = isDeployer.Valid && isDeployer.Bool
-	if tcid.Valid {
-		s.TopContractID = &tcid.String
-	}
-	if tcf.Valid {
-		s.TopContractFunction = &tcf.String
-	}
-	if fa.Valid {
-		s.FirstActivity = &fa.String
-	}
-	if la.Valid {
-		s.LastActivity = &la.String
-	}
 
 	respondSemanticJSON(w, map[string]any{
 		"account": s,
@@ -836,9 +800,8 @@ func (h *SemanticHandlers) HandleSemanticTokenSummary(w http.ResponseWriter, r *
 		return
 	}
 
-	// Token is "found" if it has registry data (name/symbol) or transfer history
-	found := meta.Name != nil || meta.Symbol != nil || meta.TransferCount > 0
-	if !found {
+	result := semanticTokenSummary(contractID, meta)
+	if result == nil {
 		respondSemanticJSON(w, map[string]any{
 			"token": nil,
 			"found": false,
@@ -846,8 +809,30 @@ func (h *SemanticHandlers) HandleSemanticTokenSummary(w http.ResponseWriter, r *
 		return
 	}
 
-	// Build response from metadata
-	result := SemanticTokenSummary{
+	// If address provided, get balance too
+	if address != "" {
+		balance, err := semanticTokenBalance(ctx, h.duckdb, contractID, address)
+		if err != nil {
+			respondSemanticError(w, "balance query failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result.Balance = balance
+	}
+
+	respondSemanticJSON(w, map[string]any{
+		"token": result,
+		"found": true,
+	})
+}
+
+// semanticTokenSummary shapes SEP-41 metadata for the token summary, or
+// returns nil when the token is unknown. A token is "found" if it has registry
+// data (name/symbol) or transfer history.
+func semanticTokenSummary(contractID string, meta *SEP41TokenMetadata) *SemanticTokenSummary {
+	if meta == nil || (meta.Name == nil && meta.Symbol == nil && meta.TransferCount == 0) {
+		return nil
+	}
+	result := &SemanticTokenSummary{
 		ContractID:    contractID,
 		TokenName:     meta.Name,
 		TokenSymbol:   meta.Symbol,
@@ -866,25 +851,21 @@ func (h *SemanticHandlers) HandleSemanticTokenSummary(w http.ResponseWriter, r *
 	if meta.LastActivity != "" {
 		result.LastActivity = &meta.LastActivity
 	}
+	return result
+}
 
-	// If address provided, get balance too
-	if address != "" {
-		balance, err := h.duckdb.GetSEP41SingleBalance(ctx, contractID, address)
-		if err == nil {
-			result.Balance = &balance.Balance
-		} else if errors.Is(err, sql.ErrNoRows) {
-			zero := "0.0000000"
-			result.Balance = &zero
-		} else {
-			respondSemanticError(w, "balance query failed: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+// semanticTokenBalance returns address's balance of a token, reporting an
+// address with no transfers as a zero balance rather than an error.
+func semanticTokenBalance(ctx context.Context, duckdb *UnifiedDuckDBReader, contractID, address string) (*string, error) {
+	balance, err := duckdb.GetSEP41SingleBalance(ctx, contractID, address)
+	if errors.Is(err, sql.ErrNoRows) {
+		zero := "0.0000000"
+		return &zero, nil
 	}
-
-	respondSemanticJSON(w, map[string]any{
-		"token": result,
-		"found": true,
-	})
+	if err != nil {
+		return nil, err
+	}
+	return &balance.Balance, nil
 }
 
 // ============================================
@@ -929,6 +910,13 @@ func scanActivities(rows *sql.Rows) ([]SemanticActivity, error) {
 	return results, rows.Err()
 }
 
+// semanticContractColumns is the select list scanContracts expects.
+const semanticContractColumns = `contract_id, contract_type,
+		token_name, token_symbol, token_decimals,
+		deployer_account, deployed_at, deployed_ledger,
+		total_invocations, last_activity, unique_callers,
+		observed_functions, wallet_type`
+
 func scanContracts(rows *sql.Rows) ([]SemanticContract, error) {
 	var results []SemanticContract
 	for rows.Next() {
@@ -154,7 +154,7 @@ func servingFeedFreshEnough(completeThru, latestLedger int64) bool {
 // NewSilverHotReader creates a new Silver hot layer reader
 func NewSilverHotReader(config PostgresConfig, network string) (*SilverHotReader, error) {
 	dsn := config.DSN()
-	db, err := sql.Open("postgres", dsn)
+	db, err := openInstrumentedDB("postgres", dsn, "silver_hot", dataTierHot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open PostgreSQL connection: %w", err)
 	}
@@ -426,12 +426,9 @@ func (h *SilverHotReader) GetServingContractActivitySummary(ctx context.Context,
 	return &summary, nil
 }
 
-// GetServingAccountCurrent returns current account state from serving schema.
-// This is faster than the legacy/unified paths because it reads the compact
-// serving projection instead of federating or scanning broader silver tables.
-func (h *SilverHotReader) GetServingAccountCurrent(ctx context.Context, accountID string) (*AccountCurrent, error) {
-	query := `
-		SELECT
+// servingAccountCurrentColumns is the select list scanServingAccountCurrent
+// expects from serving.sv_accounts_current.
+const servingAccountCurrentColumns = `
 			account_id,
 			balance_stroops,
 			sequence_number,
@@ -448,11 +445,84 @@ func (h *SilverHotReader) GetServingAccountCurrent(ctx context.Context, accountI
 			auth_required,
 			auth_revocable,
 			auth_immutable,
-			auth_clawback_enabled
+			auth_clawback_enabled`
+
+// GetServingAccountCurrent returns current account state from serving schema.
+// This is faster than the legacy/unified paths because it reads the compact
+// serving projection instead of federating or scanning broader silver tables.
+func (h *SilverHotReader) GetServingAccountCurrent(ctx context.Context, accountID string) (*AccountCurrent, error) {
+	query := `SELECT` + servingAccountCurrentColumns + `
 		FROM serving.sv_accounts_current
 		WHERE account_id = $1
 	`
 
+	acc, err := scanServingAccountCurrent(h.db.QueryRowContext(ctx, query, accountID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		if !isSchemaGapError(err) {
+			// Only a genuinely missing column/table may reroute to the legacy
+			// (narrower) query; degrading on transient errors would silently drop
+			// the newer account fields the legacy projection lacks.
+			return nil, err
+		}
+		logTierFallback("serving GetServingAccountCurrent", "serving", "serving(legacy columns)", err)
+		return h.getServingAccountCurrentLegacy(ctx, accountID)
+	}
+	return acc, nil
+}
+
+// GetServingAccountsCurrent is the batch form of GetServingAccountCurrent: one
+// query for many accounts, keyed by account ID. Accounts without a row are
+// absent from the map. If the serving table predates the newer columns it
+// falls back to per-account legacy lookups.
+func (h *SilverHotReader) GetServingAccountsCurrent(ctx context.Context, accountIDs []string) (map[string]*AccountCurrent, error) {
+	accounts := make(map[string]*AccountCurrent, len(accountIDs))
+	if len(accountIDs) == 0 {
+		return accounts, nil
+	}
+	query := `SELECT` + servingAccountCurrentColumns + `
+		FROM serving.sv_accounts_current
+		WHERE account_id = ANY($1)
+	`
+
+	rows, err := h.db.QueryContext(ctx, query, pq.Array(accountIDs))
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			acc, scanErr := scanServingAccountCurrent(rows)
+			if scanErr != nil {
+				return nil, scanErr
+			}
+			accounts[acc.AccountID] = acc
+		}
+		err = rows.Err()
+		if err == nil {
+			return accounts, nil
+		}
+	}
+	if !isSchemaGapError(err) {
+		return nil, err
+	}
+	logTierFallback("serving GetServingAccountsCurrent", "serving", "serving(legacy columns)", err)
+	for _, accountID := range accountIDs {
+		acc, err := h.getServingAccountCurrentLegacy(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		if acc != nil {
+			accounts[accountID] = acc
+		}
+	}
+	return accounts, nil
+}
+
+type servingAccountCurrentScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanServingAccountCurrent(scanner servingAccountCurrentScanner) (*AccountCurrent, error) {
 	var acc AccountCurrent
 	var balance sql.NullInt64
 	var seq sql.NullInt64
@@ -464,7 +534,7 @@ func (h *SilverHotReader) GetServingAccountCurrent(ctx context.Context, accountI
 	var updatedAt sql.NullTime
 	var homeDomain, createdAt, sponsor sql.NullString
 	var authRequired, authRevocable, authImmutable, authClawback sql.NullBool
-	err := h.db.QueryRowContext(ctx, query, accountID).Scan(
+	if err := scanner.Scan(
 		&acc.AccountID,
 		&balance,
 		&seq,
@@ -482,19 +552,8 @@ func (h *SilverHotReader) GetServingAccountCurrent(ctx context.Context, accountI
 		&authRevocable,
 		&authImmutable,
 		&authClawback,
-	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-		if !isSchemaGapError(err) {
-			// Only a genuinely missing column/table may reroute to the legacy
-			// (narrower) query; degrading on transient errors would silently drop
-			// the newer account fields the legacy projection lacks.
-			return nil, err
-		}
-		logTierFallback("serving GetServingAccountCurrent", "serving", "serving(legacy columns)", err)
-		return h.getServingAccountCurrentLegacy(ctx, accountID)
+	); err != nil {
+		return nil, err
 	}
 
 	acc.NumSubentries = numSubentries.Int64
@@ -6287,3 +6346,68 @@ func (h *SilverHotReader) GetSemanticFlows(ctx context.Context, filters Semantic
 
 	return scanFlows(rows)
 }
+
+// GetSemanticContractsByID loads semantic_entities_contracts rows for a set of
+// contract IDs in one query. IDs without a row are simply absent.
+func (h *SilverHotReader) GetSemanticContractsByID(ctx context.Context, contractIDs []string) ([]SemanticContract, error) {
+	if len(contractIDs) == 0 {
+		return nil, nil
+	}
+	query := `SELECT ` + semanticContractColumns + `
+		FROM semantic_entities_contracts
+		WHERE contract_id = ANY($1)`
+
+	rows, err := h.db.QueryContext(ctx, query, pq.Array(contractIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanContracts(rows)
+}
+
+// GetSemanticAccountSummary returns the semantic_account_summary row for an
+// account, or nil when the account has none.
+func (h *SilverHotReader) GetSemanticAccountSummary(ctx context.Context, accountID string) (*SemanticAccountSummary, error) {
+	query := `SELECT account_id, total_operations,
+		total_payments_sent, total_payments_received,
+		total_contract_calls, unique_contracts_called,
+		top_contract_id, top_contract_function,
+		is_contract_deployer, contracts_deployed,
+		first_activity, last_activity
+		FROM semantic_account_summary
+		WHERE account_id = $1`
+
+	var s SemanticAccountSummary
+	var tcid, tcf, fa, la sql.NullString
+	var isDeployer sql.NullBool
+	err := h.db.QueryRowContext(ctx, query, accountID).Scan(
+		&s.AccountID, &s.TotalOperations,
+		&s.TotalPaymentsSent, &s.TotalPaymentsRecvd,
+		&s.TotalContractCalls, &s.UniqueContractsCalled,
+		&tcid, &tcf,
+		&isDeployer, &s.ContractsDeployed,
+		&fa, &la,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.IsContractDeployer = isDeployer.Valid && isDeployer.Bool
+	if tcid.Valid {
+		s.TopContractID = &tcid.String
+	}
+	if tcf.Valid {
+		s.TopContractFunction = &tcf.String
+	}
+	if fa.Valid {
+		s.FirstActivity = &fa.String
+	}
+	if la.Valid {
+		s.LastActivity = &la.String
+	}
+	return &s, nil
+}
@@ -21,7 +21,7 @@ type SilverColdReader struct {
 
 // NewSilverColdReader creates a new Silver cold layer reader
 func NewSilverColdReader(config DuckLakeConfig) (*SilverColdReader, error) {
-	db, err := sql.Open("duckdb", "")
+	db, err := openInstrumentedDB("duckdb", "", "silver_cold", dataTierCold)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open duckdb: %w", err)
 	}
@@ -1282,8 +1282,10 @@ type OfferFilters struct {
 	SellingAssetIssuer string
 	BuyingAssetCode    string
 	BuyingAssetIssuer  string
+	Sponsor            string
 	Limit              int
 	Cursor             *OfferCursor
+	Order              string // "asc" or "desc" by offer_id (default: "asc")
 }
 
 // LiquidityPoolCurrent represents the current state of a liquidity pool
@@ -1369,11 +1371,15 @@ type TradeFilters struct {
 	SellingAssetIssuer string
 	BuyingAssetCode    string
 	BuyingAssetIssuer  string
-	StartTime          time.Time
-	EndTime            time.Time
-	Limit              int
-	Cursor             *TradeCursor
-	Order              string // "asc" or "desc" (default: "asc" for backward compatibility)
+	// BothDirections matches the selling/buying pair in either direction,
+	// as Horizon's base/counter trade filter does.
+	BothDirections bool
+	TradeType      string // "orderbook" or "liquidity_pool"; empty matches both
+	StartTime      time.Time
+	EndTime        time.Time
+	Limit          int
+	Cursor         *TradeCursor
+	Order          string // "asc" or "desc" (default: "asc" for backward compatibility)
 }
 
 // TradeStats represents aggregated trade statistics
@@ -1387,6 +1393,71 @@ type TradeStats struct {
 	AvgPrice      *string `json:"avg_price,omitempty"`
 }
 
+// OrderBookLevel is the summed amount of open offers at one price on one side
+// of an order book. PriceN/PriceD is the offer price (buying per selling) and
+// Amount is in the offers' selling asset.
+type OrderBookLevel struct {
+	PriceN int64  `json:"price_n"`
+	PriceD int64  `json:"price_d"`
+	Amount string `json:"amount"`
+}
+
+// TradeRatio is an exact counter/base price taken from a single trade's amounts
+type TradeRatio struct {
+	N int64 `json:"n"`
+	D int64 `json:"d"`
+}
+
+// TradeAggregationFilters selects trades for a base/counter pair and buckets
+// them by ResolutionMS, shifted by OffsetMS
+type TradeAggregationFilters struct {
+	BaseAssetCode      string
+	BaseAssetIssuer    string
+	CounterAssetCode   string
+	CounterAssetIssuer string
+	StartTime          time.Time
+	EndTime            time.Time
+	ResolutionMS       int64
+	OffsetMS           int64
+	Limit              int
+	Order              string // "asc" or "desc" by bucket (default: "asc")
+}
+
+// TradeAggregationBucket is one time bucket of trades, normalized so amounts
+// are in base/counter terms regardless of which side sold
+type TradeAggregationBucket struct {
+	TimestampMS   int64      `json:"timestamp"`
+	TradeCount    int64      `json:"trade_count"`
+	BaseVolume    string     `json:"base_volume"`
+	CounterVolume string     `json:"counter_volume"`
+	Average       string     `json:"avg"`
+	High          TradeRatio `json:"high"`
+	Low           TradeRatio `json:"low"`
+	Open          TradeRatio `json:"open"`
+	Close         TradeRatio `json:"close"`
+}
+
+// PathOffer is an open offer as seen by path finding. Assets are in Horizon's
+// canonical form ("native" or "CODE:ISSUER"), Amount is in stroops of the
+// selling asset and PriceN/PriceD is buying per selling.
+type PathOffer struct {
+	Selling string
+	Buying  string
+	Amount  int64
+	PriceN  int64
+	PriceD  int64
+}
+
+// PathPool is a constant-product liquidity pool as seen by path finding.
+// Reserves are in stroops.
+type PathPool struct {
+	AssetA   string
+	AssetB   string
+	ReserveA int64
+	ReserveB int64
+	FeeBP    int64
+}
+
 // ============================================
 // PRICE DATA TYPES
 // ============================================
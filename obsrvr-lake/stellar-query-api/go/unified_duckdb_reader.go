@@ -54,7 +54,7 @@ type UnifiedDuckDBReader struct {
 // PostgreSQL (hot) and DuckLake (cold) databases to a single DuckDB instance.
 func NewUnifiedDuckDBReader(config UnifiedReaderConfig) (*UnifiedDuckDBReader, error) {
 	// Open in-memory DuckDB instance
-	db, err := sql.Open("duckdb", "")
+	db, err := openInstrumentedDB("duckdb", "", "unified", dataTierUnified)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open DuckDB: %w", err)
 	}
@@ -1982,8 +1982,21 @@ func (r *UnifiedDuckDBReader) GetOffers(ctx context.Context, filters OfferFilter
 		}
 	}
 
+	if filters.Sponsor != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("sponsor = $%d", argNum))
+		args = append(args, filters.Sponsor)
+		argNum++
+	}
+
+	orderDir := "ASC"
+	cursorOp := ">"
+	if filters.Order == "desc" {
+		orderDir = "DESC"
+		cursorOp = "<"
+	}
+
 	if filters.Cursor != nil {
-		whereConditions = append(whereConditions, fmt.Sprintf("offer_id > $%d", argNum))
+		whereConditions = append(whereConditions, fmt.Sprintf("offer_id %s $%d", cursorOp, argNum))
 		args = append(args, filters.Cursor.OfferID)
 		argNum++
 	}
@@ -2023,9 +2036,9 @@ func (r *UnifiedDuckDBReader) GetOffers(ctx context.Context, filters OfferFilter
 			ORDER BY offer_id, source ASC, last_modified_ledger DESC
 		)
 		SELECT * FROM deduplicated
-		ORDER BY offer_id ASC
+		ORDER BY offer_id %s
 		LIMIT $%d
-	`, r.hotSchema, whereClause, r.coldSchema, whereClause, argNum)
+	`, r.hotSchema, whereClause, r.coldSchema, whereClause, orderDir, argNum)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -2560,33 +2573,28 @@ func (r *UnifiedDuckDBReader) GetTrades(ctx context.Context, filters TradeFilter
 	}
 
 	// Asset pair filters
-	if filters.SellingAssetCode != "" {
-		if filters.SellingAssetCode == "XLM" {
-			conditions = append(conditions, "(selling_asset_code IS NULL OR selling_asset_code = '')")
-		} else {
-			conditions = append(conditions, fmt.Sprintf("selling_asset_code = $%d", argNum))
-			args = append(args, filters.SellingAssetCode)
-			argNum++
-			if filters.SellingAssetIssuer != "" {
-				conditions = append(conditions, fmt.Sprintf("selling_asset_issuer = $%d", argNum))
-				args = append(args, filters.SellingAssetIssuer)
-				argNum++
-			}
+	if filters.BothDirections && filters.SellingAssetCode != "" && filters.BuyingAssetCode != "" {
+		forward := []string{
+			tradeAssetCondition("selling", filters.SellingAssetCode, filters.SellingAssetIssuer, &args, &argNum),
+			tradeAssetCondition("buying", filters.BuyingAssetCode, filters.BuyingAssetIssuer, &args, &argNum),
 		}
-	}
-	if filters.BuyingAssetCode != "" {
-		if filters.BuyingAssetCode == "XLM" {
-			conditions = append(conditions, "(buying_asset_code IS NULL OR buying_asset_code = '')")
-		} else {
-			conditions = append(conditions, fmt.Sprintf("buying_asset_code = $%d", argNum))
-			args = append(args, filters.BuyingAssetCode)
-			argNum++
-			if filters.BuyingAssetIssuer != "" {
-				conditions = append(conditions, fmt.Sprintf("buying_asset_issuer = $%d", argNum))
-				args = append(args, filters.BuyingAssetIssuer)
-				argNum++
-			}
+		reverse := []string{
+			tradeAssetCondition("selling", filters.BuyingAssetCode, filters.BuyingAssetIssuer, &args, &argNum),
+			tradeAssetCondition("buying", filters.SellingAssetCode, filters.SellingAssetIssuer, &args, &argNum),
+		}
+		conditions = append(conditions, fmt.Sprintf("((%s) OR (%s))", strings.Join(forward, " AND "), strings.Join(reverse, " AND ")))
+	} else {
+		if filters.SellingAssetCode != "" {
+			conditions = append(conditions, tradeAssetCondition("selling", filters.SellingAssetCode, filters.SellingAssetIssuer, &args, &argNum))
 		}
+		if filters.BuyingAssetCode != "" {
+			conditions = append(conditions, tradeAssetCondition("buying", filters.BuyingAssetCode, filters.BuyingAssetIssuer, &args, &argNum))
+		}
+	}
+	if filters.TradeType != "" {
+		conditions = append(conditions, fmt.Sprintf("COALESCE(trade_type, 'orderbook') = $%d", argNum))
+		args = append(args, filters.TradeType)
+		argNum++
 	}
 
 	// Determine order direction (default: asc for backward compatibility)
@@ -2712,6 +2720,24 @@ func (r *UnifiedDuckDBReader) GetTrades(ctx context.Context, filters TradeFilter
 	return trades, nextCursor, hasMore, nil
 }
 
+// tradeAssetCondition matches one side ("selling" or "buying") of a silver
+// trade against an asset. XLM matches the native asset, which silver trades
+// store with a NULL or empty code.
+func tradeAssetCondition(side, code, issuer string, args *[]interface{}, argNum *int) string {
+	if code == "XLM" {
+		return fmt.Sprintf("(%s_asset_code IS NULL OR %s_asset_code = '')", side, side)
+	}
+	cond := fmt.Sprintf("%s_asset_code = $%d", side, *argNum)
+	*args = append(*args, code)
+	*argNum++
+	if issuer != "" {
+		cond += fmt.Sprintf(" AND %s_asset_issuer = $%d", side, *argNum)
+		*args = append(*args, issuer)
+		*argNum++
+	}
+	return "(" + cond + ")"
+}
+
 // GetTradeStats returns aggregated trade statistics from unified storage
 func (r *UnifiedDuckDBReader) GetTradeStats(ctx context.Context, groupBy string, startTime, endTime time.Time) ([]TradeStats, error) {
 	var groupExpr, selectGroup string
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// tradeAggregationSource is the per-tier projection GetTradeAggregations
+// unions; the trade filter is applied in each arm so DuckDB can push it down
+// into the PostgreSQL and DuckLake scans.
+const tradeAggregationSource = `
+	SELECT ledger_sequence, trade_timestamp,
+	       selling_asset_code, selling_asset_issuer, selling_amount,
+	       buying_asset_code, buying_asset_issuer, buying_amount
+	FROM %s.trades WHERE %s`
+
+// GetOrderBookLevels returns open offers selling `selling` for `buying`,
+// summed per price and ordered from the best (lowest) offer price.
+// Hot rows take precedence over cold rows for the same offer_id.
+func (r *UnifiedDuckDBReader) GetOrderBookLevels(ctx context.Context, selling, buying AssetInfo, limit int) ([]OrderBookLevel, error) {
+	args := []interface{}{}
+	argNum := 1
+	whereClause := offerAssetCondition("selling", selling, &args, &argNum) + " AND " +
+		offerAssetCondition("buying", buying, &args, &argNum)
+
+	if limit <= 0 {
+		limit = 20
+	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		WITH combined AS (
+			SELECT offer_id, amount, price_n, price_d, last_modified_ledger, 1 as source
+			FROM %s.offers_current
+			WHERE %s
+			UNION ALL
+			SELECT offer_id, amount, price_n, price_d, last_modified_ledger, 2 as source
+			FROM %s.offers_current
+			WHERE %s
+		),
+		deduplicated AS (
+			SELECT DISTINCT ON (offer_id) offer_id, amount, price_n, price_d
+			FROM combined
+			ORDER BY offer_id, source ASC, last_modified_ledger DESC
+		)
+		SELECT price_n, price_d, CAST(SUM(amount) AS VARCHAR) as amount
+		FROM deduplicated
+		WHERE amount > 0 AND price_n > 0 AND price_d > 0
+		GROUP BY price_n, price_d
+		ORDER BY CAST(price_n AS DOUBLE) / price_d ASC, price_n ASC
+		LIMIT $%d
+	`, r.hotSchema, whereClause, r.coldSchema, whereClause, argNum)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unified GetOrderBookLevels: %w", err)
+	}
+	defer rows.Close()
+
+	levels := []OrderBookLevel{}
+	for rows.Next() {
+		var level OrderBookLevel
+		var rawAmount string
+		if err := rows.Scan(&level.PriceN, &level.PriceD, &rawAmount); err != nil {
+			return nil, fmt.Errorf("unified GetOrderBookLevels scan: %w", err)
+		}
+		level.Amount, err = formatStroopsSum(rawAmount)
+		if err != nil {
+			return nil, fmt.Errorf("unified GetOrderBookLevels: %w", err)
+		}
+		levels = append(levels, level)
+	}
+	return levels, rows.Err()
+}
+
+// GetTradeAggregations buckets trades between the base and counter assets
+// (in either direction) into fixed-width time windows. Prices are
+// counter/base ratios taken from trade amounts, so they stay exact.
+// Trades are append-only, so hot and cold are unioned without dedup.
+func (r *UnifiedDuckDBReader) GetTradeAggregations(ctx context.Context, filters TradeAggregationFilters) ([]TradeAggregationBucket, error) {
+	if filters.ResolutionMS <= 0 {
+		return nil, fmt.Errorf("resolution must be positive")
+	}
+
+	var conditions []string
+	var args []interface{}
+	argNum := 1
+
+	forward := tradeAssetCondition("selling", filters.BaseAssetCode, filters.BaseAssetIssuer, &args, &argNum) + " AND " +
+		tradeAssetCondition("buying", filters.CounterAssetCode, filters.CounterAssetIssuer, &args, &argNum)
+	reverse := tradeAssetCondition("selling", filters.CounterAssetCode, filters.CounterAssetIssuer, &args, &argNum) + " AND " +
+		tradeAssetCondition("buying", filters.BaseAssetCode, filters.BaseAssetIssuer, &args, &argNum)
+	conditions = append(conditions, fmt.Sprintf("((%s) OR (%s))", forward, reverse))
+
+	if !filters.StartTime.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("trade_timestamp >= $%d", argNum))
+		args = append(args, filters.StartTime)
+		argNum++
+	}
+	if !filters.EndTime.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("trade_timestamp < $%d", argNum))
+		args = append(args, filters.EndTime)
+		argNum++
+	}
+	whereClause := strings.Join(conditions, " AND ")
+
+	// Orientation is decided on the selling side only; the WHERE clause above
+	// already guarantees the row is one of the two directions.
+	baseIsSelling := tradeAssetCondition("selling", filters.BaseAssetCode, filters.BaseAssetIssuer, &args, &argNum)
+
+	orderDir := "ASC"
+	if filters.Order == "desc" {
+		orderDir = "DESC"
+	}
+	limit := filters.Limit
+	if limit <= 0 {
+		limit = 200
+	}
+
+	buildQuery := func(sources string) string {
+		return fmt.Sprintf(`
+			WITH pair_trades AS (
+				SELECT ledger_sequence, trade_timestamp,
+				       CASE WHEN %s THEN selling_amount ELSE buying_amount END AS base_amount,
+				       CASE WHEN %s THEN buying_amount ELSE selling_amount END AS counter_amount
+				FROM (%s) combined
+				WHERE selling_amount > 0 AND buying_amount > 0
+			),
+			bucketed AS (
+				SELECT ((epoch_ms(trade_timestamp) - $%d) // $%d) * $%d + $%d AS bucket_ms,
+				       ledger_sequence, base_amount, counter_amount,
+				       CAST(counter_amount AS DOUBLE) / base_amount AS price,
+				       CAST(counter_amount AS VARCHAR) || '/' || CAST(base_amount AS VARCHAR) AS ratio
+				FROM pair_trades
+			)
+			SELECT bucket_ms, COUNT(*) as trade_count,
+			       CAST(SUM(base_amount) AS VARCHAR) as base_volume,
+			       CAST(SUM(counter_amount) AS VARCHAR) as counter_volume,
+			       arg_max(ratio, price) as high,
+			       arg_min(ratio, price) as low,
+			       arg_min(ratio, ledger_sequence) as open,
+			       arg_max(ratio, ledger_sequence) as close
+			FROM bucketed
+			GROUP BY bucket_ms
+			ORDER BY bucket_ms %s
+			LIMIT $%d
+		`, baseIsSelling, baseIsSelling, sources, argNum, argNum+1, argNum+1, argNum, orderDir, argNum+2)
+	}
+	args = append(args, filters.OffsetMS, filters.ResolutionMS, limit)
+
+	unified := fmt.Sprintf(tradeAggregationSource, r.hotSchema, whereClause) +
+		"\n\tUNION ALL" + fmt.Sprintf(tradeAggregationSource, r.coldSchema, whereClause)
+	rows, err := r.db.QueryContext(ctx, buildQuery(unified), args...)
+	if err != nil {
+		// Check if cold table doesn't exist, fall back to hot-only
+		if strings.Contains(err.Error(), "does not exist") && strings.Contains(err.Error(), "trades") {
+			hotOnly := fmt.Sprintf(tradeAggregationSource, r.hotSchema, whereClause)
+			rows, err = r.db.QueryContext(ctx, buildQuery(hotOnly), args...)
+			if err != nil {
+				return nil, fmt.Errorf("unified GetTradeAggregations (hot-only fallback): %w", err)
+			}
+		} else {
+			return nil, fmt.Errorf("unified GetTradeAggregations: %w", err)
+		}
+	}
+	defer rows.Close()
+
+	buckets := []TradeAggregationBucket{}
+	for rows.Next() {
+		var b TradeAggregationBucket
+		var baseVolume, counterVolume, high, low, open, closeRatio string
+		if err := rows.Scan(&b.TimestampMS, &b.TradeCount, &baseVolume, &counterVolume, &high, &low, &open, &closeRatio); err != nil {
+			return nil, fmt.Errorf("unified GetTradeAggregations scan: %w", err)
+		}
+
+		base, ok := new(big.Int).SetString(baseVolume, 10)
+		if !ok {
+			return nil, fmt.Errorf("unified GetTradeAggregations: invalid base volume %q", baseVolume)
+		}
+		counter, ok := new(big.Int).SetString(counterVolume, 10)
+		if !ok {
+			return nil, fmt.Errorf("unified GetTradeAggregations: invalid counter volume %q", counterVolume)
+		}
+		b.BaseVolume = new(big.Rat).SetFrac(base, big.NewInt(stroopsPerUnit)).FloatString(7)
+		b.CounterVolume = new(big.Rat).SetFrac(counter, big.NewInt(stroopsPerUnit)).FloatString(7)
+		b.Average = new(big.Rat).SetFrac(counter, base).FloatString(7)
+
+		for _, ratio := range []struct {
+			raw string
+			dst *TradeRatio
+		}{{high, &b.High}, {low, &b.Low}, {open, &b.Open}, {closeRatio, &b.Close}} {
+			*ratio.dst, err = parseTradeRatio(ratio.raw)
+			if err != nil {
+				return nil, fmt.Errorf("unified GetTradeAggregations: %w", err)
+			}
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+// GetPathFindingState loads every open offer and funded liquidity pool, the
+// state path finding searches over. Hot rows take precedence over cold rows
+// for the same offer or pool, as in GetOrderBookLevels.
+func (r *UnifiedDuckDBReader) GetPathFindingState(ctx context.Context) ([]PathOffer, []PathPool, error) {
+	offerColumns := `offer_id, selling_asset_type, selling_asset_code, selling_asset_issuer,
+				       buying_asset_type, buying_asset_code, buying_asset_issuer,
+				       amount, price_n, price_d, last_modified_ledger`
+	rows, err := queryHorizonTiers(ctx, r, "unified GetPathFindingState offers", func(schemas []string) string {
+		return fmt.Sprintf(`
+			SELECT selling_asset_type, selling_asset_code, selling_asset_issuer,
+			       buying_asset_type, buying_asset_code, buying_asset_issuer,
+			       amount, price_n, price_d
+			FROM (
+				SELECT DISTINCT ON (offer_id) *
+				FROM (%s) combined
+				ORDER BY offer_id, source ASC, last_modified_ledger DESC
+			) deduplicated
+			WHERE amount > 0 AND price_n > 0 AND price_d > 0
+		`, horizonTierUnion(schemas, "offers_current", offerColumns, "1=1"))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	offers := []PathOffer{}
+	for rows.Next() {
+		var sellingType, sellingCode, sellingIssuer, buyingType, buyingCode, buyingIssuer sql.NullString
+		var offer PathOffer
+		if err := rows.Scan(&sellingType, &sellingCode, &sellingIssuer, &buyingType, &buyingCode, &buyingIssuer,
+			&offer.Amount, &offer.PriceN, &offer.PriceD); err != nil {
+			return nil, nil, fmt.Errorf("unified GetPathFindingState offers scan: %w", err)
+		}
+		offer.Selling = horizonAssetString(buildAssetInfo(sellingType.String, sellingCode.String, sellingIssuer.String))
+		offer.Buying = horizonAssetString(buildAssetInfo(buyingType.String, buyingCode.String, buyingIssuer.String))
+		offers = append(offers, offer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("unified GetPathFindingState offers: %w", err)
+	}
+
+	poolColumns := `liquidity_pool_id, fee, asset_a_type, asset_a_code, asset_a_issuer, asset_a_amount,
+				       asset_b_type, asset_b_code, asset_b_issuer, asset_b_amount, last_modified_ledger`
+	poolRows, err := queryHorizonTiers(ctx, r, "unified GetPathFindingState pools", func(schemas []string) string {
+		return fmt.Sprintf(`
+			SELECT fee, asset_a_type, asset_a_code, asset_a_issuer, asset_a_amount,
+			       asset_b_type, asset_b_code, asset_b_issuer, asset_b_amount
+			FROM (
+				SELECT DISTINCT ON (liquidity_pool_id) *
+				FROM (%s) combined
+				ORDER BY liquidity_pool_id, source ASC, last_modified_ledger DESC
+			) deduplicated
+			WHERE asset_a_amount > 0 AND asset_b_amount > 0
+		`, horizonTierUnion(schemas, "liquidity_pools_current", poolColumns, "1=1"))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer poolRows.Close()
+
+	pools := []PathPool{}
+	for poolRows.Next() {
+		var aType, aCode, aIssuer, bType, bCode, bIssuer sql.NullString
+		var pool PathPool
+		if err := poolRows.Scan(&pool.FeeBP, &aType, &aCode, &aIssuer, &pool.ReserveA,
+			&bType, &bCode, &bIssuer, &pool.ReserveB); err != nil {
+			return nil, nil, fmt.Errorf("unified GetPathFindingState pools scan: %w", err)
+		}
+		pool.AssetA = horizonAssetString(buildAssetInfo(aType.String, aCode.String, aIssuer.String))
+		pool.AssetB = horizonAssetString(buildAssetInfo(bType.String, bCode.String, bIssuer.String))
+		pools = append(pools, pool)
+	}
+	if err := poolRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("unified GetPathFindingState pools: %w", err)
+	}
+	return offers, pools, nil
+}
+
+const stroopsPerUnit = 10000000
+
+// offerAssetCondition matches one side ("selling" or "buying") of an
+// offers_current row against an asset. Offers carry an explicit asset type,
+// so the native asset is matched on type rather than on an empty code.
+func offerAssetCondition(side string, asset AssetInfo, args *[]interface{}, argNum *int) string {
+	if asset.Type == "native" || asset.Code == "XLM" && asset.Issuer == nil {
+		return fmt.Sprintf("%s_asset_type = 'native'", side)
+	}
+	cond := fmt.Sprintf("%s_asset_code = $%d", side, *argNum)
+	*args = append(*args, asset.Code)
+	*argNum++
+	if asset.Issuer != nil {
+		cond += fmt.Sprintf(" AND %s_asset_issuer = $%d", side, *argNum)
+		*args = append(*args, *asset.Issuer)
+		*argNum++
+	}
+	return "(" + cond + ")"
+}
+
+// formatStroopsSum formats a decimal stroop total that may exceed int64
+// (SUM over BIGINT is a HUGEINT in DuckDB) with 7 decimal places.
+func formatStroopsSum(raw string) (string, error) {
+	total, ok := new(big.Int).SetString(strings.TrimSpace(raw), 10)
+	if !ok {
+		return "", fmt.Errorf("invalid stroop amount %q", raw)
+	}
+	return new(big.Rat).SetFrac(total, big.NewInt(stroopsPerUnit)).FloatString(7), nil
+}
+
+// parseTradeRatio parses a "counter/base" stroop ratio and reduces it.
+func parseTradeRatio(raw string) (TradeRatio, error) {
+	nRaw, dRaw, ok := strings.Cut(raw, "/")
+	if !ok {
+		return TradeRatio{}, fmt.Errorf("invalid trade ratio %q", raw)
+	}
+	n, err := strconv.ParseInt(nRaw, 10, 64)
+	if err != nil {
+		return TradeRatio{}, fmt.Errorf("invalid trade ratio %q: %w", raw, err)
+	}
+	d, err := strconv.ParseInt(dRaw, 10, 64)
+	if err != nil || d == 0 {
+		return TradeRatio{}, fmt.Errorf("invalid trade ratio %q", raw)
+	}
+	g := new(big.Int).GCD(nil, nil, big.NewInt(n), big.NewInt(d)).Int64()
+	if g > 1 {
+		n /= g
+		d /= g
+	}
+	return TradeRatio{N: n, D: d}, nil
+}
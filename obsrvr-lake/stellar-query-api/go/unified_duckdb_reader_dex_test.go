@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/duckdb/duckdb-go/v2"
+)
+
+func newDEXDuckDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("duckdb", "")
+	if err != nil {
+		t.Fatalf("open duckdb: %v", err)
+	}
+	for _, schema := range []string{"hot", "cold"} {
+		stmts := []string{
+			`CREATE SCHEMA ` + schema,
+			`CREATE TABLE memory.` + schema + `.offers_current (offer_id BIGINT, seller_id VARCHAR, selling_asset_type VARCHAR, selling_asset_code VARCHAR, selling_asset_issuer VARCHAR, buying_asset_type VARCHAR, buying_asset_code VARCHAR, buying_asset_issuer VARCHAR, amount BIGINT, price_n INTEGER, price_d INTEGER, price DECIMAL(20,7), last_modified_ledger BIGINT, sponsor VARCHAR)`,
+			`CREATE TABLE memory.` + schema + `.trades (ledger_sequence BIGINT, transaction_hash VARCHAR, operation_index INTEGER, trade_index INTEGER, trade_type VARCHAR, trade_timestamp TIMESTAMP, seller_account VARCHAR, selling_asset_code VARCHAR, selling_asset_issuer VARCHAR, selling_amount BIGINT, buyer_account VARCHAR, buying_asset_code VARCHAR, buying_asset_issuer VARCHAR, buying_amount BIGINT, price DECIMAL(20,7))`,
+		}
+		for _, stmt := range stmts {
+			if _, err := db.Exec(stmt); err != nil {
+				t.Fatalf("create: %v\n%s", err, stmt)
+			}
+		}
+	}
+	return db
+}
+
+func TestGetOrderBookLevelsAggregatesAndPrefersHot(t *testing.T) {
+	db := newDEXDuckDB(t)
+	defer db.Close()
+	reader := &UnifiedDuckDBReader{db: db, hotSchema: "memory.hot", coldSchema: "memory.cold"}
+
+	insert := `INSERT INTO memory.%s.offers_current VALUES (?, 'GS', 'native', NULL, NULL, 'credit_alphanum4', 'USDC', '` + testHorizonUSDCIssuer + `', ?, ?, ?, 0, ?, NULL)`
+	for _, row := range []struct {
+		schema       string
+		id, amount   int64
+		n, d         int
+		lastModified int64
+	}{
+		{"hot", 1, 100000000, 1, 4, 20},
+		{"hot", 2, 50000000, 1, 4, 21},
+		{"hot", 3, 10000000, 1, 2, 22},
+		// Stale cold copy of offer 1 must not double count.
+		{"cold", 1, 900000000, 1, 4, 10},
+		{"cold", 4, 30000000, 1, 5, 9},
+	} {
+		if _, err := db.Exec(fmt.Sprintf(insert, row.schema), row.id, row.amount, row.n, row.d, row.lastModified); err != nil {
+			t.Fatalf("insert offer: %v", err)
+		}
+	}
+
+	levels, err := reader.GetOrderBookLevels(context.Background(), buildAssetInfo("native", "", ""), usdcAssetInfo(), 10)
+	if err != nil {
+		t.Fatalf("GetOrderBookLevels: %v", err)
+	}
+	want := []OrderBookLevel{
+		{PriceN: 1, PriceD: 5, Amount: "3.0000000"},
+		{PriceN: 1, PriceD: 4, Amount: "15.0000000"},
+		{PriceN: 1, PriceD: 2, Amount: "1.0000000"},
+	}
+	if len(levels) != len(want) {
+		t.Fatalf("levels = %+v", levels)
+	}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Fatalf("level %d = %+v, want %+v", i, levels[i], want[i])
+		}
+	}
+}
+
+func TestGetTradeAggregationsNormalizesDirection(t *testing.T) {
+	db := newDEXDuckDB(t)
+	defer db.Close()
+	reader := &UnifiedDuckDBReader{db: db, hotSchema: "memory.hot", coldSchema: "memory.cold"}
+
+	base := time.Date(2026, 7, 9, 10, 0, 0, 0, time.UTC)
+	insert := `INSERT INTO memory.%s.trades VALUES (?, ?, 0, 0, 'orderbook', ?, 'GS', ?, ?, ?, 'GB', ?, ?, ?, 0)`
+	usdc := testHorizonUSDCIssuer
+	for _, row := range []struct {
+		schema               string
+		ledger               int64
+		ts                   time.Time
+		sellCode, sellIssuer any
+		sellAmount           int64
+		buyCode, buyIssuer   any
+		buyAmount            int64
+	}{
+		// XLM sold for USDC at 0.25 USDC/XLM.
+		{"cold", 10, base.Add(5 * time.Minute), nil, nil, 1000000000, "USDC", usdc, 250000000},
+		// USDC sold for XLM: 30 USDC for 100 XLM = 0.30 USDC/XLM.
+		{"hot", 11, base.Add(20 * time.Minute), "USDC", usdc, 300000000, nil, nil, 1000000000},
+		// Next hour bucket.
+		{"hot", 12, base.Add(70 * time.Minute), nil, nil, 500000000, "USDC", usdc, 100000000},
+		// Different pair is ignored.
+		{"hot", 13, base.Add(10 * time.Minute), nil, nil, 500000000, "EURC", usdc, 100000000},
+	} {
+		if _, err := db.Exec(fmt.Sprintf(insert, row.schema), row.ledger, "tx", row.ts, row.sellCode, row.sellIssuer, row.sellAmount, row.buyCode, row.buyIssuer, row.buyAmount); err != nil {
+			t.Fatalf("insert trade: %v", err)
+		}
+	}
+
+	buckets, err := reader.GetTradeAggregations(context.Background(), TradeAggregationFilters{
+		BaseAssetCode:      "XLM",
+		CounterAssetCode:   "USDC",
+		CounterAssetIssuer: usdc,
+		ResolutionMS:       3600000,
+	})
+	if err != nil {
+		t.Fatalf("GetTradeAggregations: %v", err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("buckets = %+v", buckets)
+	}
+	first := buckets[0]
+	if first.TimestampMS != base.UnixMilli() || first.TradeCount != 2 {
+		t.Fatalf("first bucket = %+v", first)
+	}
+	if first.BaseVolume != "200.0000000" || first.CounterVolume != "55.0000000" || first.Average != "0.2750000" {
+		t.Fatalf("first bucket volumes = %+v", first)
+	}
+	if first.Open != (TradeRatio{N: 1, D: 4}) || first.Close != (TradeRatio{N: 3, D: 10}) || first.High != (TradeRatio{N: 3, D: 10}) || first.Low != (TradeRatio{N: 1, D: 4}) {
+		t.Fatalf("first bucket prices = %+v", first)
+	}
+	if buckets[1].TimestampMS != base.Add(time.Hour).UnixMilli() || buckets[1].Open != (TradeRatio{N: 1, D: 5}) {
+		t.Fatalf("second bucket = %+v", buckets[1])
+	}
+}
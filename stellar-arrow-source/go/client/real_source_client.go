@@ -43,7 +43,7 @@ type RealArrowSourceClient struct {
 }
 
 // NewRealArrowSourceClient creates a new real Arrow source client
-func NewRealArrowSourceClient(endpoint string, logger *logging.ComponentLogger) (*RealArrowSourceClient, error) {
+func NewRealArrowSourceClient(endpoint, networkPassphrase string, logger *logging.ComponentLogger) (*RealArrowSourceClient, error) {
 	allocator := memory.NewGoAllocator()
 	schemaManager := schema.NewSchemaManager()
 	
@@ -70,7 +70,7 @@ func NewRealArrowSourceClient(endpoint string, logger *logging.ComponentLogger)
 	sourceClient := rawledger.NewRawLedgerServiceClient(conn)
 
 	// Create XDR to Arrow converter
-	arrowConverter := converter.NewXDRToArrowConverter(allocator, schemaManager)
+	arrowConverter := converter.NewXDRToArrowConverter(allocator, schemaManager, networkPassphrase)
 
 	client := &RealArrowSourceClient{
 		allocator:     allocator,
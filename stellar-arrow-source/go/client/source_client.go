@@ -33,7 +33,7 @@ type NativeArrowSourceClient struct {
 }
 
 // NewNativeArrowSourceClient creates a new Arrow source client
-func NewNativeArrowSourceClient(endpoint string, logger *logging.ComponentLogger) (*NativeArrowSourceClient, error) {
+func NewNativeArrowSourceClient(endpoint, networkPassphrase string, logger *logging.ComponentLogger) (*NativeArrowSourceClient, error) {
 	allocator := memory.NewGoAllocator()
 	schemaManager := schema.NewSchemaManager()
 	
@@ -54,7 +54,7 @@ func NewNativeArrowSourceClient(endpoint string, logger *logging.ComponentLogger
 	}
 
 	// Create XDR to Arrow converter
-	arrowConverter := converter.NewXDRToArrowConverter(allocator, schemaManager)
+	arrowConverter := converter.NewXDRToArrowConverter(allocator, schemaManager, networkPassphrase)
 
 	client := &NativeArrowSourceClient{
 		allocator:     allocator,
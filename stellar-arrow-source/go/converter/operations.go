@@ -0,0 +1,516 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// ttpAmountPrecision is the number of decimal places in every classic
+// amount: values are stroops, 10^-7 of a unit.
+const ttpAmountPrecision = 7
+
+func (c *XDRToArrowConverter) appendOperationRows(w *rowWriter, ledger ledgerInput) error {
+	transactions, err := c.readTransactions(ledger.meta)
+	if err != nil {
+		return err
+	}
+	for i := range transactions {
+		tx := &transactions[i]
+		for opIndex, op := range tx.Envelope.Operations() {
+			if err := appendOperationRow(w, ledger.meta, tx, opIndex, op); err != nil {
+				return fmt.Errorf("transaction %d operation %d: %w", tx.Index, opIndex, err)
+			}
+		}
+	}
+	return nil
+}
+
+func appendOperationRow(w *rowWriter, lcm xdr.LedgerCloseMeta, tx *ingest.LedgerTransaction, opIndex int, op xdr.Operation) error {
+	opXDR, err := op.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation: %w", err)
+	}
+	// Results and meta are absent for transactions that failed before
+	// applying their operations; those rows carry empty XDR.
+	resultXDR := []byte{}
+	opResult, hasResult := operationResult(tx, opIndex)
+	if hasResult {
+		if resultXDR, err = opResult.MarshalBinary(); err != nil {
+			return fmt.Errorf("failed to marshal operation result: %w", err)
+		}
+	}
+	metaXDR, err := operationMetaXDR(tx.UnsafeMeta, opIndex)
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation meta: %w", err)
+	}
+
+	txHash := tx.Result.TransactionHash
+	txSource := tx.Envelope.SourceAccount()
+	source := txSource
+	if op.SourceAccount != nil {
+		source = *op.SourceAccount
+	}
+	successful := tx.Result.Successful()
+
+	w.Uint32("ledger_sequence", lcm.LedgerSequence())
+	w.FixedBinary("transaction_hash", txHash[:])
+	w.Uint32("transaction_index", tx.Index)
+	w.Uint32("operation_index", uint32(opIndex))
+	w.Int64("operation_id", toid.New(int32(lcm.LedgerSequence()), int32(tx.Index), int32(opIndex+1)).ToInt64())
+	w.Timestamp("ledger_close_time", lcm.ClosedAt())
+
+	w.Uint32("operation_type", uint32(op.Body.Type))
+	w.String("operation_type_string", op.Body.Type.String())
+	// The source columns are only set when the operation overrides the
+	// transaction source.
+	if op.SourceAccount != nil {
+		writeAccount(w, "source_account", *op.SourceAccount)
+	}
+
+	w.Bool("transaction_successful", successful)
+	w.String("transaction_source_account", txSource.ToAccountId().Address())
+
+	// As in Horizon, an operation is successful exactly when its transaction
+	// is: a failed transaction rolls back every operation in it.
+	w.Bool("successful", successful)
+	if hasResult {
+		w.Int32("result_code", int32(opResult.Code))
+		w.String("result_code_string", opResult.Code.String())
+	} else {
+		w.Int32("result_code", int32(tx.Result.Result.Result.Code))
+		w.String("result_code_string", tx.Result.Result.Result.Code.String())
+	}
+
+	writeOperationDetails(w, op, source, opResult, hasResult && successful)
+
+	w.Binary("operation_xdr", opXDR)
+	w.Binary("operation_result_xdr", resultXDR)
+	w.Binary("operation_meta_xdr", metaXDR)
+	return w.endRow()
+}
+
+// writeOperationDetails fills the type-specific columns. source is the
+// effective source account of the operation.
+func writeOperationDetails(w *rowWriter, op xdr.Operation, source xdr.MuxedAccount, result xdr.OperationResult, applied bool) {
+	body := op.Body
+	switch body.Type {
+	case xdr.OperationTypeCreateAccount:
+		create := body.MustCreateAccountOp()
+		w.String("destination_account", create.Destination.Address())
+		w.Int64("starting_balance", int64(create.StartingBalance))
+
+	case xdr.OperationTypePayment:
+		payment := body.MustPaymentOp()
+		w.String("destination_account", payment.Destination.ToAccountId().Address())
+		w.Int64("amount", int64(payment.Amount))
+		writeAsset(w, "", payment.Asset)
+
+	case xdr.OperationTypePathPaymentStrictReceive:
+		payment := body.MustPathPaymentStrictReceiveOp()
+		w.String("destination_account", payment.Destination.ToAccountId().Address())
+		w.Int64("amount", int64(payment.DestAmount))
+		writeAsset(w, "", payment.DestAsset)
+		writeAsset(w, "send_", payment.SendAsset)
+		w.Int64("send_amount", int64(payment.SendMax))
+		w.Uint32("path_length", uint32(len(payment.Path)))
+
+	case xdr.OperationTypePathPaymentStrictSend:
+		payment := body.MustPathPaymentStrictSendOp()
+		w.String("destination_account", payment.Destination.ToAccountId().Address())
+		if amount, ok := strictSendAmount(result, applied); ok {
+			w.Int64("amount", amount)
+		}
+		writeAsset(w, "", payment.DestAsset)
+		writeAsset(w, "send_", payment.SendAsset)
+		w.Int64("send_amount", int64(payment.SendAmount))
+		w.Int64("dest_min", int64(payment.DestMin))
+		w.Uint32("path_length", uint32(len(payment.Path)))
+
+	case xdr.OperationTypeManageSellOffer:
+		offer := body.MustManageSellOfferOp()
+		writeOffer(w, offer.Selling, offer.Buying, int64(offer.Amount), offer.Price)
+		w.Int64("offer_id", int64(offer.OfferId))
+
+	case xdr.OperationTypeManageBuyOffer:
+		offer := body.MustManageBuyOfferOp()
+		writeOffer(w, offer.Selling, offer.Buying, int64(offer.BuyAmount), offer.Price)
+		w.Int64("offer_id", int64(offer.OfferId))
+
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		offer := body.MustCreatePassiveSellOfferOp()
+		writeOffer(w, offer.Selling, offer.Buying, int64(offer.Amount), offer.Price)
+
+	case xdr.OperationTypeSetOptions:
+		options := body.MustSetOptionsOp()
+		if options.InflationDest != nil {
+			w.String("inflation_dest", options.InflationDest.Address())
+		}
+		if options.MasterWeight != nil {
+			w.Uint32("master_weight", uint32(*options.MasterWeight))
+		}
+		if options.LowThreshold != nil {
+			w.Uint32("threshold_low", uint32(*options.LowThreshold))
+		}
+		if options.MedThreshold != nil {
+			w.Uint32("threshold_medium", uint32(*options.MedThreshold))
+		}
+		if options.HighThreshold != nil {
+			w.Uint32("threshold_high", uint32(*options.HighThreshold))
+		}
+		if options.HomeDomain != nil {
+			w.String("home_domain", string(*options.HomeDomain))
+		}
+		if options.Signer != nil {
+			w.String("signer_key", options.Signer.Key.Address())
+			w.Uint32("signer_weight", uint32(options.Signer.Weight))
+		}
+
+	case xdr.OperationTypeChangeTrust:
+		trust := body.MustChangeTrustOp()
+		w.String("trustor", source.ToAccountId().Address())
+		w.Int64("trust_limit", int64(trust.Limit))
+		if trust.Line.Type == xdr.AssetTypeAssetTypePoolShare {
+			w.Uint8("asset_type", uint8(trust.Line.Type))
+			if params, ok := trust.Line.MustLiquidityPool().GetConstantProduct(); ok {
+				if poolID, err := xdr.NewPoolId(params.AssetA, params.AssetB, params.Fee); err == nil {
+					w.FixedBinary("liquidity_pool_id", poolID[:])
+				}
+			}
+		} else {
+			writeAsset(w, "", trust.Line.ToAsset())
+		}
+
+	case xdr.OperationTypeAllowTrust:
+		allow := body.MustAllowTrustOp()
+		sourceAccount := source.ToAccountId()
+		w.String("trustor", allow.Trustor.Address())
+		w.String("trustee", sourceAccount.Address())
+		writeAsset(w, "", allow.Asset.ToAsset(sourceAccount))
+		w.Bool("authorize_to_maintain_liabilities",
+			allow.Authorize&xdr.Uint32(xdr.TrustLineFlagsAuthorizedToMaintainLiabilitiesFlag) != 0)
+
+	case xdr.OperationTypeAccountMerge:
+		destination := body.MustDestination()
+		w.String("destination_account", destination.ToAccountId().Address())
+
+	case xdr.OperationTypeManageData:
+		data := body.MustManageDataOp()
+		w.String("data_name", string(data.DataName))
+		if data.DataValue != nil {
+			w.Binary("data_value", *data.DataValue)
+		}
+
+	case xdr.OperationTypeCreateClaimableBalance:
+		balance := body.MustCreateClaimableBalanceOp()
+		writeAsset(w, "", balance.Asset)
+		w.Int64("amount", int64(balance.Amount))
+		w.Uint32("claimant_count", uint32(len(balance.Claimants)))
+
+	case xdr.OperationTypeClaimClaimableBalance:
+		writeBalanceID(w, body.MustClaimClaimableBalanceOp().BalanceId)
+
+	case xdr.OperationTypeClawbackClaimableBalance:
+		writeBalanceID(w, body.MustClawbackClaimableBalanceOp().BalanceId)
+
+	case xdr.OperationTypeBeginSponsoringFutureReserves:
+		sponsoring := body.MustBeginSponsoringFutureReservesOp()
+		w.String("sponsored_account", sponsoring.SponsoredId.Address())
+		w.String("sponsor_account", source.ToAccountId().Address())
+
+	case xdr.OperationTypeClawback:
+		clawback := body.MustClawbackOp()
+		writeAsset(w, "", clawback.Asset)
+		w.Int64("amount", int64(clawback.Amount))
+		w.String("trustor", clawback.From.ToAccountId().Address())
+
+	case xdr.OperationTypeSetTrustLineFlags:
+		flags := body.MustSetTrustLineFlagsOp()
+		w.String("trustor", flags.Trustor.Address())
+		w.String("trustee", source.ToAccountId().Address())
+		writeAsset(w, "", flags.Asset)
+		writeTrustLineFlag(w, "authorize_to_maintain_liabilities", flags,
+			xdr.Uint32(xdr.TrustLineFlagsAuthorizedToMaintainLiabilitiesFlag))
+		writeTrustLineFlag(w, "clawback_enabled", flags,
+			xdr.Uint32(xdr.TrustLineFlagsTrustlineClawbackEnabledFlag))
+
+	case xdr.OperationTypeLiquidityPoolDeposit:
+		deposit := body.MustLiquidityPoolDepositOp()
+		w.FixedBinary("liquidity_pool_id", deposit.LiquidityPoolId[:])
+		w.Int64("reserve_a_deposit", int64(deposit.MaxAmountA))
+		w.Int64("reserve_b_deposit", int64(deposit.MaxAmountB))
+		w.Int32("min_price_n", int32(deposit.MinPrice.N))
+		w.Int32("min_price_d", int32(deposit.MinPrice.D))
+		w.Int32("max_price_n", int32(deposit.MaxPrice.N))
+		w.Int32("max_price_d", int32(deposit.MaxPrice.D))
+
+	case xdr.OperationTypeLiquidityPoolWithdraw:
+		withdraw := body.MustLiquidityPoolWithdrawOp()
+		w.FixedBinary("liquidity_pool_id", withdraw.LiquidityPoolId[:])
+		w.Int64("amount", int64(withdraw.Amount))
+
+	case xdr.OperationTypeInvokeHostFunction:
+		invoke := body.MustInvokeHostFunctionOp()
+		function := invoke.HostFunction
+		w.Uint32("host_function_type", uint32(function.Type))
+		w.Uint32("soroban_auth_count", uint32(len(invoke.Auth)))
+		switch function.Type {
+		case xdr.HostFunctionTypeHostFunctionTypeInvokeContract:
+			args := function.MustInvokeContract()
+			if contractID, ok := args.ContractAddress.GetContractId(); ok {
+				w.FixedBinary("contract_id", contractID[:])
+			}
+			w.String("function_name", string(args.FunctionName))
+		case xdr.HostFunctionTypeHostFunctionTypeCreateContract:
+			writeExecutable(w, function.MustCreateContract().Executable)
+		case xdr.HostFunctionTypeHostFunctionTypeCreateContractV2:
+			writeExecutable(w, function.MustCreateContractV2().Executable)
+		case xdr.HostFunctionTypeHostFunctionTypeUploadContractWasm:
+			codeHash := sha256.Sum256(function.MustWasm())
+			w.FixedBinary("contract_code_hash", codeHash[:])
+		}
+	}
+}
+
+// writeAsset writes <prefix>asset_type, and for issued assets the code and
+// issuer columns.
+func writeAsset(w *rowWriter, prefix string, asset xdr.Asset) {
+	w.Uint8(prefix+"asset_type", uint8(asset.Type))
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		return
+	}
+	w.String(prefix+"asset_code", asset.GetCode())
+	w.String(prefix+"asset_issuer", asset.GetIssuer())
+}
+
+func writeOffer(w *rowWriter, selling, buying xdr.Asset, amount int64, price xdr.Price) {
+	writeAsset(w, "selling_", selling)
+	writeAsset(w, "buying_", buying)
+	w.Int64("amount", amount)
+	w.Int32("price_n", int32(price.N))
+	w.Int32("price_d", int32(price.D))
+}
+
+func writeBalanceID(w *rowWriter, balanceID xdr.ClaimableBalanceId) {
+	if hash, ok := balanceID.GetV0(); ok {
+		w.FixedBinary("balance_id", hash[:])
+	}
+}
+
+func writeExecutable(w *rowWriter, executable xdr.ContractExecutable) {
+	if hash, ok := executable.GetWasmHash(); ok {
+		w.FixedBinary("contract_code_hash", hash[:])
+	}
+}
+
+// writeTrustLineFlag records whether the operation sets or clears flag; the
+// column stays null when the operation leaves it alone.
+func writeTrustLineFlag(w *rowWriter, column string, op xdr.SetTrustLineFlagsOp, flag xdr.Uint32) {
+	switch {
+	case op.SetFlags&flag != 0:
+		w.Bool(column, true)
+	case op.ClearFlags&flag != 0:
+		w.Bool(column, false)
+	}
+}
+
+// strictSendAmount is the amount a strict-send path payment delivered, which
+// only the operation result records.
+func strictSendAmount(result xdr.OperationResult, applied bool) (int64, bool) {
+	if !applied || result.Tr == nil {
+		return 0, false
+	}
+	sendResult, ok := result.Tr.GetPathPaymentStrictSendResult()
+	if !ok {
+		return 0, false
+	}
+	success, ok := sendResult.GetSuccess()
+	if !ok {
+		return 0, false
+	}
+	return int64(success.Last.Amount), true
+}
+
+func operationResult(tx *ingest.LedgerTransaction, opIndex int) (xdr.OperationResult, bool) {
+	results, ok := tx.Result.OperationResults()
+	if !ok || opIndex >= len(results) {
+		return xdr.OperationResult{}, false
+	}
+	return results[opIndex], true
+}
+
+// operationMetaXDR returns the ledger changes of one operation for every
+// TransactionMeta version, or empty bytes when the meta has none for it.
+func operationMetaXDR(meta xdr.TransactionMeta, opIndex int) ([]byte, error) {
+	var operations []xdr.OperationMeta
+	switch meta.V {
+	case 0:
+		operations, _ = meta.GetOperations()
+	case 1:
+		operations = meta.MustV1().Operations
+	case 2:
+		operations = meta.MustV2().Operations
+	case 3:
+		operations = meta.MustV3().Operations
+	case 4:
+		operationsV2 := meta.MustV4().Operations
+		if opIndex >= len(operationsV2) {
+			return []byte{}, nil
+		}
+		return operationsV2[opIndex].MarshalBinary()
+	default:
+		return nil, fmt.Errorf("unsupported TransactionMeta version: %d", meta.V)
+	}
+	if opIndex >= len(operations) {
+		return []byte{}, nil
+	}
+	return operations[opIndex].MarshalBinary()
+}
+
+// ttpTransfer is one classic value movement.
+type ttpTransfer struct {
+	eventType  string
+	to         xdr.MuxedAccount
+	asset      xdr.Asset
+	amount     int64
+	pathLength *uint32
+}
+
+// ttpTransferFor returns the transfer an applied operation made, if it is a
+// payment, path payment or account creation.
+func ttpTransferFor(op xdr.Operation, result xdr.OperationResult) (ttpTransfer, bool) {
+	body := op.Body
+	switch body.Type {
+	case xdr.OperationTypePayment:
+		payment := body.MustPaymentOp()
+		return ttpTransfer{
+			eventType: "payment",
+			to:        payment.Destination,
+			asset:     payment.Asset,
+			amount:    int64(payment.Amount),
+		}, true
+	case xdr.OperationTypePathPaymentStrictReceive:
+		payment := body.MustPathPaymentStrictReceiveOp()
+		pathLength := uint32(len(payment.Path))
+		return ttpTransfer{
+			eventType:  "path_payment_strict_receive",
+			to:         payment.Destination,
+			asset:      payment.DestAsset,
+			amount:     int64(payment.DestAmount),
+			pathLength: &pathLength,
+		}, true
+	case xdr.OperationTypePathPaymentStrictSend:
+		payment := body.MustPathPaymentStrictSendOp()
+		amount, ok := strictSendAmount(result, true)
+		if !ok {
+			return ttpTransfer{}, false
+		}
+		pathLength := uint32(len(payment.Path))
+		return ttpTransfer{
+			eventType:  "path_payment_strict_send",
+			to:         payment.Destination,
+			asset:      payment.DestAsset,
+			amount:     amount,
+			pathLength: &pathLength,
+		}, true
+	case xdr.OperationTypeCreateAccount:
+		create := body.MustCreateAccountOp()
+		return ttpTransfer{
+			eventType: "create_account",
+			to:        create.Destination.ToMuxedAccount(),
+			asset:     xdr.MustNewNativeAsset(),
+			amount:    int64(create.StartingBalance),
+		}, true
+	}
+	return ttpTransfer{}, false
+}
+
+func (c *XDRToArrowConverter) appendTTPEventRows(w *rowWriter, ledger ledgerInput) error {
+	transactions, err := c.readTransactions(ledger.meta)
+	if err != nil {
+		return err
+	}
+	for i := range transactions {
+		tx := &transactions[i]
+		if !tx.Result.Successful() {
+			continue
+		}
+		for opIndex, op := range tx.Envelope.Operations() {
+			result, _ := operationResult(tx, opIndex)
+			transfer, ok := ttpTransferFor(op, result)
+			if !ok {
+				continue
+			}
+			if err := appendTTPEventRow(w, ledger.meta, tx, opIndex, op, transfer); err != nil {
+				return fmt.Errorf("transaction %d operation %d: %w", tx.Index, opIndex, err)
+			}
+		}
+	}
+	return nil
+}
+
+func appendTTPEventRow(w *rowWriter, lcm xdr.LedgerCloseMeta, tx *ingest.LedgerTransaction, opIndex int, op xdr.Operation, transfer ttpTransfer) error {
+	envelope := tx.Envelope
+	from := envelope.SourceAccount()
+	if op.SourceAccount != nil {
+		from = *op.SourceAccount
+		w.String("operation_source_account", from.ToAccountId().Address())
+	}
+
+	txHash := tx.Result.TransactionHash
+	w.Uint32("ledger_sequence", lcm.LedgerSequence())
+	w.Timestamp("ledger_close_time", lcm.ClosedAt())
+	w.FixedBinary("transaction_hash", txHash[:])
+	w.Uint32("operation_index", uint32(opIndex))
+
+	w.String("event_type", transfer.eventType)
+	w.String("from_account", from.ToAccountId().Address())
+	w.String("to_account", transfer.to.ToAccountId().Address())
+	isNative := transfer.asset.Type == xdr.AssetTypeAssetTypeNative
+	if !isNative {
+		w.String("asset_code", transfer.asset.GetCode())
+		w.String("asset_issuer", transfer.asset.GetIssuer())
+	}
+	w.Int64("amount", transfer.amount)
+	w.Uint32("amount_precision", ttpAmountPrecision)
+
+	if memo, memoType, ok := memoString(envelope.Memo()); ok {
+		w.String("memo", memo)
+		w.String("memo_type", memoType)
+	}
+
+	w.Bool("is_native_asset", isNative)
+	w.Bool("is_path_payment", transfer.pathLength != nil)
+	if transfer.pathLength != nil {
+		w.Uint32("path_length", *transfer.pathLength)
+	}
+
+	w.Int64("fee_charged", int64(tx.Result.Result.FeeCharged))
+	if envelope.IsFeeBump() {
+		w.Int64("max_fee", envelope.FeeBumpFee())
+	} else {
+		w.Int64("max_fee", int64(envelope.Fee()))
+	}
+	return w.endRow()
+}
+
+// memoString renders a memo as text: ids in decimal, hashes in hex.
+func memoString(memo xdr.Memo) (value, memoType string, ok bool) {
+	switch memo.Type {
+	case xdr.MemoTypeMemoText:
+		return memo.MustText(), "text", true
+	case xdr.MemoTypeMemoId:
+		return strconv.FormatUint(uint64(memo.MustId()), 10), "id", true
+	case xdr.MemoTypeMemoHash:
+		hash := memo.MustHash()
+		return hash.HexString(), "hash", true
+	case xdr.MemoTypeMemoReturn:
+		hash := memo.MustRetHash()
+		return hash.HexString(), "return", true
+	}
+	return "", "", false
+}
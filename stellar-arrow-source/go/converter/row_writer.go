@@ -0,0 +1,115 @@
+package converter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+)
+
+// rowWriter appends rows to a RecordBuilder by column name. Columns a row
+// does not set are appended as null, so extractors only describe the fields
+// that apply to them; leaving a non-nullable column unset is an error.
+type rowWriter struct {
+	builder *array.RecordBuilder
+	fields  []arrow.Field
+	index   map[string]int
+	set     []bool
+	err     error
+}
+
+func newRowWriter(builder *array.RecordBuilder) *rowWriter {
+	fields := builder.Schema().Fields()
+	index := make(map[string]int, len(fields))
+	for i, field := range fields {
+		index[field.Name] = i
+	}
+	return &rowWriter{
+		builder: builder,
+		fields:  fields,
+		index:   index,
+		set:     make([]bool, len(fields)),
+	}
+}
+
+// writeColumn marks the named column as set for the current row and hands
+// its builder to appendValue.
+func writeColumn[B array.Builder](w *rowWriter, name string, appendValue func(B)) {
+	if w.err != nil {
+		return
+	}
+	i, ok := w.index[name]
+	if !ok {
+		w.err = fmt.Errorf("unknown column %q", name)
+		return
+	}
+	if w.set[i] {
+		w.err = fmt.Errorf("column %q set twice in one row", name)
+		return
+	}
+	builder, ok := w.builder.Field(i).(B)
+	if !ok {
+		w.err = fmt.Errorf("column %q is %s, not %T", name, w.fields[i].Type, builder)
+		return
+	}
+	w.set[i] = true
+	appendValue(builder)
+}
+
+func (w *rowWriter) Bool(name string, v bool) {
+	writeColumn(w, name, func(b *array.BooleanBuilder) { b.Append(v) })
+}
+
+func (w *rowWriter) Uint8(name string, v uint8) {
+	writeColumn(w, name, func(b *array.Uint8Builder) { b.Append(v) })
+}
+
+func (w *rowWriter) Uint32(name string, v uint32) {
+	writeColumn(w, name, func(b *array.Uint32Builder) { b.Append(v) })
+}
+
+func (w *rowWriter) Uint64(name string, v uint64) {
+	writeColumn(w, name, func(b *array.Uint64Builder) { b.Append(v) })
+}
+
+func (w *rowWriter) Int32(name string, v int32) {
+	writeColumn(w, name, func(b *array.Int32Builder) { b.Append(v) })
+}
+
+func (w *rowWriter) Int64(name string, v int64) {
+	writeColumn(w, name, func(b *array.Int64Builder) { b.Append(v) })
+}
+
+func (w *rowWriter) String(name string, v string) {
+	writeColumn(w, name, func(b *array.StringBuilder) { b.Append(v) })
+}
+
+func (w *rowWriter) Binary(name string, v []byte) {
+	writeColumn(w, name, func(b *array.BinaryBuilder) { b.Append(v) })
+}
+
+func (w *rowWriter) FixedBinary(name string, v []byte) {
+	writeColumn(w, name, func(b *array.FixedSizeBinaryBuilder) { b.Append(v) })
+}
+
+// Timestamp writes t in the microsecond unit every schema here uses.
+func (w *rowWriter) Timestamp(name string, t time.Time) {
+	writeColumn(w, name, func(b *array.TimestampBuilder) { b.Append(arrow.Timestamp(t.UnixMicro())) })
+}
+
+// endRow nulls out every column the row left unset and reports the first
+// error seen while writing it.
+func (w *rowWriter) endRow() error {
+	for i, set := range w.set {
+		if set {
+			w.set[i] = false
+			continue
+		}
+		if w.err == nil && !w.fields[i].Nullable {
+			w.err = fmt.Errorf("non-nullable column %q was not set", w.fields[i].Name)
+		}
+		w.builder.Field(i).AppendNull()
+	}
+	return w.err
+}
@@ -1,191 +1,442 @@
 package converter
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"sync"
 	"time"
 
 	"github.com/apache/arrow/go/v17/arrow"
 	"github.com/apache/arrow/go/v17/arrow/array"
 	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
 	"github.com/withObsrvr/ttp-processor-demo/stellar-arrow-source/schema"
+	rawledger "github.com/withObsrvr/ttp-processor-demo/stellar-live-source-datalake/gen/raw_ledger_service"
 )
 
 // XDRToArrowConverter converts Stellar XDR data to native Arrow records
 type XDRToArrowConverter struct {
-	allocator     memory.Allocator
-	schemaManager *schema.SchemaManager
+	allocator         memory.Allocator
+	schemaManager     *schema.SchemaManager
+	networkPassphrase string
+
+	statsMu          sync.Mutex
+	recordsConverted int64
+	conversionErrors int64
+	conversions      int64
+	conversionTime   time.Duration
 }
 
-// NewXDRToArrowConverter creates a new XDR to Arrow converter
-func NewXDRToArrowConverter(allocator memory.Allocator, schemaManager *schema.SchemaManager) *XDRToArrowConverter {
+// NewXDRToArrowConverter creates a new XDR to Arrow converter. The network
+// passphrase is needed to hash envelopes so they can be matched to their
+// results; ledger-level conversion works without it.
+func NewXDRToArrowConverter(allocator memory.Allocator, schemaManager *schema.SchemaManager, networkPassphrase string) *XDRToArrowConverter {
 	return &XDRToArrowConverter{
-		allocator:     allocator,
-		schemaManager: schemaManager,
+		allocator:         allocator,
+		schemaManager:     schemaManager,
+		networkPassphrase: networkPassphrase,
 	}
 }
 
-// ConvertLedgerToArrow converts a Stellar ledger to an Arrow record
-func (c *XDRToArrowConverter) ConvertLedgerToArrow(ledgerData interface{}) (arrow.Record, error) {
-	// This is a placeholder implementation for Phase 1
-	// In the actual implementation, this would:
-	// 1. Parse the XDR LedgerCloseMeta
-	// 2. Extract all relevant fields
-	// 3. Build an Arrow record with proper data types
-	// 4. Handle different LedgerCloseMeta versions (V0, V1, V2)
-
-	schema := c.schemaManager.GetStellarLedgerSchema()
-	builder := array.NewRecordBuilder(c.allocator, schema)
-	defer builder.Release()
+// Every Convert method accepts the same ledger inputs: an xdr.LedgerCloseMeta
+// (value, pointer or slice), raw LedgerCloseMeta XDR ([]byte or [][]byte), or
+// *rawledger.RawLedger messages (single or slice). LedgerCloseMeta V0, V1 and
+// V2 are supported.
 
-	// For now, create a single mock record
-	// This will be replaced with actual XDR parsing
-	record := c.createMockLedgerRecord(builder)
-	
-	return record, nil
+// ConvertLedgerToArrow converts ledgers to a record with one row per ledger
+// in the stellar ledger schema.
+func (c *XDRToArrowConverter) ConvertLedgerToArrow(ledgerData interface{}) (arrow.Record, error) {
+	return c.convert(c.schemaManager.GetStellarLedgerSchema(), ledgerData, c.appendLedgerRow)
 }
 
-// ConvertTransactionToArrow converts transaction data to Arrow record
+// ConvertTransactionToArrow converts ledgers to a record with one row per
+// transaction, in apply order.
 func (c *XDRToArrowConverter) ConvertTransactionToArrow(txData interface{}) (arrow.Record, error) {
-	schema := c.schemaManager.GetTransactionSchema()
-	builder := array.NewRecordBuilder(c.allocator, schema)
-	defer builder.Release()
+	return c.convert(c.schemaManager.GetTransactionSchema(), txData, c.appendTransactionRows)
+}
 
-	// Placeholder implementation
-	record := c.createMockTransactionRecord(builder)
-	
-	return record, nil
+// ConvertOperationsToArrow converts ledgers to a record with one row per
+// operation, failed transactions included.
+func (c *XDRToArrowConverter) ConvertOperationsToArrow(ledgerData interface{}) (arrow.Record, error) {
+	return c.convert(c.schemaManager.GetOperationSchema(), ledgerData, c.appendOperationRows)
 }
 
-// ConvertTTPEventsToArrow converts TTP events to Arrow records
+// ConvertTTPEventsToArrow converts ledgers to a record with one row per
+// classic value transfer (payments, path payments and account creation) in
+// a successful transaction.
 func (c *XDRToArrowConverter) ConvertTTPEventsToArrow(events interface{}) (arrow.Record, error) {
-	schema := c.schemaManager.GetTTPEventSchema()
-	builder := array.NewRecordBuilder(c.allocator, schema)
+	return c.convert(c.schemaManager.GetTTPEventSchema(), events, c.appendTTPEventRows)
+}
+
+// ledgerInput is one decoded ledger together with the XDR it came from.
+type ledgerInput struct {
+	meta xdr.LedgerCloseMeta
+	raw  []byte
+}
+
+func (c *XDRToArrowConverter) convert(
+	arrowSchema *arrow.Schema,
+	data interface{},
+	appendRows func(*rowWriter, ledgerInput) error,
+) (arrow.Record, error) {
+	start := time.Now()
+	record, err := c.buildRecord(arrowSchema, data, appendRows)
+	c.recordConversion(record, err, time.Since(start))
+	return record, err
+}
+
+func (c *XDRToArrowConverter) buildRecord(
+	arrowSchema *arrow.Schema,
+	data interface{},
+	appendRows func(*rowWriter, ledgerInput) error,
+) (arrow.Record, error) {
+	ledgers, err := decodeLedgerInputs(data)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := array.NewRecordBuilder(c.allocator, arrowSchema)
 	defer builder.Release()
+	writer := newRowWriter(builder)
+	for _, ledger := range ledgers {
+		if err := appendRows(writer, ledger); err != nil {
+			return nil, fmt.Errorf("ledger %d: %w", ledger.meta.LedgerSequence(), err)
+		}
+	}
+	return builder.NewRecord(), nil
+}
 
-	// Placeholder implementation
-	record := c.createMockTTPEventRecord(builder)
-	
-	return record, nil
-}
-
-// createMockLedgerRecord creates a mock ledger record for testing
-func (c *XDRToArrowConverter) createMockLedgerRecord(builder *array.RecordBuilder) arrow.Record {
-	// This creates a single mock ledger record
-	// In the real implementation, this would parse actual XDR data
-
-	ledgerSeqBuilder := builder.Field(0).(*array.Uint32Builder)
-	closeTimeBuilder := builder.Field(1).(*array.TimestampBuilder)
-	ledgerHashBuilder := builder.Field(2).(*array.FixedSizeBinaryBuilder)
-	prevHashBuilder := builder.Field(3).(*array.FixedSizeBinaryBuilder)
-	txCountBuilder := builder.Field(4).(*array.Uint32Builder)
-	opCountBuilder := builder.Field(5).(*array.Uint32Builder)
-	successTxBuilder := builder.Field(6).(*array.Uint32Builder)
-	failedTxBuilder := builder.Field(7).(*array.Uint32Builder)
-	protocolBuilder := builder.Field(8).(*array.Uint32Builder)
-	baseFeeBuilder := builder.Field(9).(*array.Uint32Builder)
-	baseReserveBuilder := builder.Field(10).(*array.Uint32Builder)
-	maxTxSetBuilder := builder.Field(11).(*array.Uint32Builder)
-	closeResBuilder := builder.Field(12).(*array.Uint32Builder)
-	xdrBuilder := builder.Field(13).(*array.BinaryBuilder)
-
-	// Mock data
-	ledgerSeqBuilder.Append(12345)
-	closeTimeBuilder.Append(arrow.Timestamp(time.Now().UnixMicro()))
-	
-	// Mock 32-byte hashes
-	ledgerHash := make([]byte, 32)
-	prevHash := make([]byte, 32)
-	for i := range ledgerHash {
-		ledgerHash[i] = byte(i)
-		prevHash[i] = byte(i + 1)
-	}
-	ledgerHashBuilder.Append(ledgerHash)
-	prevHashBuilder.Append(prevHash)
-	
-	txCountBuilder.Append(42)
-	opCountBuilder.Append(84)
-	successTxBuilder.Append(40)
-	failedTxBuilder.Append(2)
-	protocolBuilder.Append(23)
-	baseFeeBuilder.Append(100)
-	baseReserveBuilder.Append(5000000)
-	maxTxSetBuilder.Append(1000)
-	closeResBuilder.Append(1)
-	xdrBuilder.Append([]byte("mock_xdr_data"))
-
-	return builder.NewRecord()
-}
-
-// createMockTransactionRecord creates a mock transaction record
-func (c *XDRToArrowConverter) createMockTransactionRecord(builder *array.RecordBuilder) arrow.Record {
-	ledgerSeqBuilder := builder.Field(0).(*array.Uint32Builder)
-	txHashBuilder := builder.Field(1).(*array.FixedSizeBinaryBuilder)
-	txIndexBuilder := builder.Field(2).(*array.Uint32Builder)
-	sourceAccountBuilder := builder.Field(3).(*array.StringBuilder)
-	seqNumBuilder := builder.Field(4).(*array.Int64Builder)
-	feeChargedBuilder := builder.Field(5).(*array.Int64Builder)
-	maxFeeBuilder := builder.Field(6).(*array.Int64Builder)
-	successfulBuilder := builder.Field(7).(*array.BooleanBuilder)
-	resultCodeBuilder := builder.Field(8).(*array.StringBuilder)
-	opCountBuilder := builder.Field(9).(*array.Uint32Builder)
-	// Additional fields...
-
-	// Mock transaction data
-	ledgerSeqBuilder.Append(12345)
-	
-	txHash := make([]byte, 32)
-	for i := range txHash {
-		txHash[i] = byte(i)
-	}
-	txHashBuilder.Append(txHash)
-	
-	txIndexBuilder.Append(0)
-	sourceAccountBuilder.Append("GABC123...")
-	seqNumBuilder.Append(123456789)
-	feeChargedBuilder.Append(1000)
-	maxFeeBuilder.Append(1000)
-	successfulBuilder.Append(true)
-	resultCodeBuilder.Append("tx_success")
-	opCountBuilder.Append(2)
-
-	return builder.NewRecord()
-}
-
-// createMockTTPEventRecord creates a mock TTP event record
-func (c *XDRToArrowConverter) createMockTTPEventRecord(builder *array.RecordBuilder) arrow.Record {
-	ledgerSeqBuilder := builder.Field(0).(*array.Uint32Builder)
-	closeTimeBuilder := builder.Field(1).(*array.TimestampBuilder)
-	txHashBuilder := builder.Field(2).(*array.FixedSizeBinaryBuilder)
-	opIndexBuilder := builder.Field(3).(*array.Uint32Builder)
-	eventTypeBuilder := builder.Field(4).(*array.StringBuilder)
-	fromAccountBuilder := builder.Field(5).(*array.StringBuilder)
-	toAccountBuilder := builder.Field(6).(*array.StringBuilder)
-	assetCodeBuilder := builder.Field(7).(*array.StringBuilder)
-	assetIssuerBuilder := builder.Field(8).(*array.StringBuilder)
-	amountBuilder := builder.Field(9).(*array.Int64Builder)
-	precisionBuilder := builder.Field(10).(*array.Uint32Builder)
-	// Additional fields...
-
-	// Mock TTP event data
-	ledgerSeqBuilder.Append(12345)
-	closeTimeBuilder.Append(arrow.Timestamp(time.Now().UnixMicro()))
-	
-	txHash := make([]byte, 32)
-	for i := range txHash {
-		txHash[i] = byte(i)
-	}
-	txHashBuilder.Append(txHash)
-	
-	opIndexBuilder.Append(0)
-	eventTypeBuilder.Append("payment")
-	fromAccountBuilder.Append("GABC123...")
-	toAccountBuilder.Append("GDEF456...")
-	assetCodeBuilder.Append("XLM")
-	assetIssuerBuilder.Append("")
-	amountBuilder.Append(1000000000) // 100 XLM in stroops
-	precisionBuilder.Append(7)
-
-	return builder.NewRecord()
+func decodeLedgerInputs(data interface{}) ([]ledgerInput, error) {
+	var ledgers []ledgerInput
+	add := func(meta *xdr.LedgerCloseMeta, raw []byte) error {
+		if meta == nil {
+			var decoded xdr.LedgerCloseMeta
+			if err := xdr.SafeUnmarshal(raw, &decoded); err != nil {
+				return fmt.Errorf("failed to unmarshal LedgerCloseMeta XDR: %w", err)
+			}
+			meta = &decoded
+		} else {
+			encoded, err := meta.MarshalBinary()
+			if err != nil {
+				return fmt.Errorf("failed to marshal LedgerCloseMeta: %w", err)
+			}
+			raw = encoded
+		}
+		if meta.V < 0 || meta.V > 2 {
+			return fmt.Errorf("unsupported LedgerCloseMeta version: %d", meta.V)
+		}
+		ledgers = append(ledgers, ledgerInput{meta: *meta, raw: raw})
+		return nil
+	}
+
+	var err error
+	switch v := data.(type) {
+	case xdr.LedgerCloseMeta:
+		err = add(&v, nil)
+	case *xdr.LedgerCloseMeta:
+		if v == nil {
+			return nil, errors.New("nil LedgerCloseMeta")
+		}
+		err = add(v, nil)
+	case []xdr.LedgerCloseMeta:
+		for i := range v {
+			if err = add(&v[i], nil); err != nil {
+				break
+			}
+		}
+	case []byte:
+		err = add(nil, v)
+	case [][]byte:
+		for _, raw := range v {
+			if err = add(nil, raw); err != nil {
+				break
+			}
+		}
+	case *rawledger.RawLedger:
+		if v == nil {
+			return nil, errors.New("nil RawLedger")
+		}
+		err = add(nil, v.LedgerCloseMetaXdr)
+	case []*rawledger.RawLedger:
+		for _, raw := range v {
+			if raw == nil {
+				return nil, errors.New("nil RawLedger")
+			}
+			if err = add(nil, raw.LedgerCloseMetaXdr); err != nil {
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported ledger input type %T", data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ledgers, nil
+}
+
+// readTransactions returns the ledger's transactions in apply order, each
+// paired with its envelope, result and meta by the SDK reader.
+func (c *XDRToArrowConverter) readTransactions(lcm xdr.LedgerCloseMeta) ([]ingest.LedgerTransaction, error) {
+	if c.networkPassphrase == "" {
+		return nil, errors.New("network passphrase is required to match envelopes to results")
+	}
+	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(c.networkPassphrase, lcm)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	transactions := make([]ingest.LedgerTransaction, 0, lcm.CountTransactions())
+	for {
+		tx, err := reader.Read()
+		if err == io.EOF {
+			return transactions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, tx)
+	}
+}
+
+func (c *XDRToArrowConverter) appendLedgerRow(w *rowWriter, ledger ledgerInput) error {
+	lcm := ledger.meta
+	header := lcm.LedgerHeaderHistoryEntry().Header
+
+	// Counting needs no envelope-to-result matching, so this works without
+	// a network passphrase.
+	var opCount, successTxCount, failedTxCount uint32
+	for _, envelope := range lcm.TransactionEnvelopes() {
+		opCount += envelope.OperationsCount()
+	}
+	for i := 0; i < lcm.CountTransactions(); i++ {
+		if lcm.TransactionResultPair(i).Successful() {
+			successTxCount++
+		} else {
+			failedTxCount++
+		}
+	}
+
+	ledgerHash := lcm.LedgerHash()
+	prevHash := header.PreviousLedgerHash
+
+	w.Uint32("ledger_sequence", lcm.LedgerSequence())
+	w.Timestamp("ledger_close_time", lcm.ClosedAt())
+	w.FixedBinary("ledger_hash", ledgerHash[:])
+	w.FixedBinary("previous_ledger_hash", prevHash[:])
+	w.Uint32("transaction_count", uint32(lcm.CountTransactions()))
+	w.Uint32("operation_count", opCount)
+	w.Uint32("successful_transaction_count", successTxCount)
+	w.Uint32("failed_transaction_count", failedTxCount)
+	w.Uint32("protocol_version", uint32(header.LedgerVersion))
+	w.Uint32("base_fee", uint32(header.BaseFee))
+	w.Uint32("base_reserve", uint32(header.BaseReserve))
+	w.Uint32("max_tx_set_size", uint32(header.MaxTxSetSize))
+	// Close time resolution is always 1 second for Stellar
+	w.Uint32("close_time_resolution", 1)
+	w.Binary("ledger_close_meta_xdr", ledger.raw)
+	return w.endRow()
+}
+
+func (c *XDRToArrowConverter) appendTransactionRows(w *rowWriter, ledger ledgerInput) error {
+	transactions, err := c.readTransactions(ledger.meta)
+	if err != nil {
+		return err
+	}
+	for i := range transactions {
+		if err := appendTransactionRow(w, ledger.meta, &transactions[i]); err != nil {
+			return fmt.Errorf("transaction %d: %w", transactions[i].Index, err)
+		}
+	}
+	return nil
+}
+
+func appendTransactionRow(w *rowWriter, lcm xdr.LedgerCloseMeta, tx *ingest.LedgerTransaction) error {
+	envelope := tx.Envelope
+	result := tx.Result.Result
+	operations := envelope.Operations()
+
+	envelopeXDR, err := envelope.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	resultXDR, err := tx.Result.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	metaXDR, err := tx.UnsafeMeta.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to marshal meta: %w", err)
+	}
+
+	txHash := tx.Result.TransactionHash
+	w.Uint32("ledger_sequence", lcm.LedgerSequence())
+	w.FixedBinary("transaction_hash", txHash[:])
+	// Transaction indexes are 1-based, as in the SDK and in TOIDs.
+	w.Uint32("transaction_index", tx.Index)
+	w.Timestamp("ledger_close_time", lcm.ClosedAt())
+
+	writeAccount(w, "source_account", envelope.SourceAccount())
+	w.Int64("sequence_number", envelope.SeqNum())
+
+	w.Int64("fee_charged", int64(result.FeeCharged))
+	if envelope.IsFeeBump() {
+		w.Int64("max_fee", envelope.FeeBumpFee())
+	} else {
+		w.Int64("max_fee", int64(envelope.Fee()))
+	}
+	sorobanData, isSoroban := tx.GetSorobanData()
+	if isSoroban {
+		w.Int64("resource_fee", int64(sorobanData.ResourceFee))
+		if charged, ok := sorobanResourceFeeCharged(tx.UnsafeMeta); ok {
+			w.Int64("soroban_resource_fee", charged)
+		}
+	}
+
+	w.Bool("successful", tx.Result.Successful())
+	w.Int32("result_code", int32(result.Result.Code))
+	w.String("result_code_string", result.Result.Code.String())
+	w.Uint32("operation_count", uint32(len(operations)))
+
+	if bounds := envelope.TimeBounds(); bounds != nil {
+		w.Timestamp("time_bounds_min", time.Unix(int64(bounds.MinTime), 0))
+		// A zero max time means the transaction never expires
+		if bounds.MaxTime != 0 {
+			w.Timestamp("time_bounds_max", time.Unix(int64(bounds.MaxTime), 0))
+		}
+	}
+	if bounds := envelope.LedgerBounds(); bounds != nil {
+		w.Uint32("ledger_bounds_min", uint32(bounds.MinLedger))
+		if bounds.MaxLedger != 0 {
+			w.Uint32("ledger_bounds_max", uint32(bounds.MaxLedger))
+		}
+	}
+	if minSeq := envelope.MinSeqNum(); minSeq != nil {
+		w.Int64("min_account_sequence", *minSeq)
+	}
+	if minAge := envelope.MinSeqAge(); minAge != nil {
+		w.Uint64("min_account_sequence_age", uint64(*minAge))
+	}
+	if minGap := envelope.MinSeqLedgerGap(); minGap != nil {
+		w.Uint32("min_account_sequence_ledger_gap", uint32(*minGap))
+	}
+	if envelope.Preconditions().Type == xdr.PreconditionTypePrecondV2 {
+		w.Uint32("extra_signers_count", uint32(len(envelope.ExtraSigners())))
+	}
+
+	signatureCount := len(envelope.Signatures())
+	if envelope.IsFeeBump() {
+		signatureCount += len(envelope.FeeBumpSignatures())
+		innerHash := tx.Result.InnerHash()
+		w.FixedBinary("inner_transaction_hash", innerHash[:])
+		feeSource := envelope.FeeBumpAccount()
+		w.String("fee_source", feeSource.ToAccountId().Address())
+	}
+	w.Uint32("signature_count", uint32(signatureCount))
+
+	memo := envelope.Memo()
+	w.Uint8("memo_type", uint8(memo.Type))
+	switch memo.Type {
+	case xdr.MemoTypeMemoText:
+		w.String("memo_text", memo.MustText())
+	case xdr.MemoTypeMemoId:
+		w.Uint64("memo_id", uint64(memo.MustId()))
+	case xdr.MemoTypeMemoHash:
+		hash := memo.MustHash()
+		w.FixedBinary("memo_hash", hash[:])
+	case xdr.MemoTypeMemoReturn:
+		hash := memo.MustRetHash()
+		w.FixedBinary("memo_return_hash", hash[:])
+	}
+
+	w.Uint32("protocol_version", tx.LedgerVersion)
+	w.Uint32("transaction_envelope_type", uint32(envelope.Type))
+
+	if isSoroban {
+		var sorobanOps, createdContracts uint32
+		for _, op := range operations {
+			switch op.Body.Type {
+			case xdr.OperationTypeInvokeHostFunction:
+				sorobanOps++
+				switch op.Body.MustInvokeHostFunctionOp().HostFunction.Type {
+				case xdr.HostFunctionTypeHostFunctionTypeCreateContract, xdr.HostFunctionTypeHostFunctionTypeCreateContractV2:
+					createdContracts++
+				}
+			case xdr.OperationTypeExtendFootprintTtl, xdr.OperationTypeRestoreFootprint:
+				sorobanOps++
+			}
+		}
+		w.Uint32("soroban_operations_count", sorobanOps)
+		w.Uint32("created_contract_count", createdContracts)
+		w.Uint32("soroban_resources_instructions", uint32(sorobanData.Resources.Instructions))
+		w.Uint32("soroban_resources_read_bytes", uint32(sorobanData.Resources.DiskReadBytes))
+		w.Uint32("soroban_resources_write_bytes", uint32(sorobanData.Resources.WriteBytes))
+		// soroban_resources_metadata_size_bytes stays null: the field was
+		// dropped from SorobanResources before protocol 20 shipped.
+	}
+
+	w.Binary("transaction_envelope_xdr", envelopeXDR)
+	w.Binary("transaction_result_xdr", resultXDR)
+	w.Binary("transaction_meta_xdr", metaXDR)
+	return w.endRow()
+}
+
+// sorobanResourceFeeCharged is the refundable plus non-refundable resource
+// fee actually charged, when the meta records it.
+func sorobanResourceFeeCharged(meta xdr.TransactionMeta) (int64, bool) {
+	var ext xdr.SorobanTransactionMetaExt
+	switch meta.V {
+	case 3:
+		v3 := meta.MustV3()
+		if v3.SorobanMeta == nil {
+			return 0, false
+		}
+		ext = v3.SorobanMeta.Ext
+	case 4:
+		v4 := meta.MustV4()
+		if v4.SorobanMeta == nil {
+			return 0, false
+		}
+		ext = v4.SorobanMeta.Ext
+	default:
+		return 0, false
+	}
+	v1, ok := ext.GetV1()
+	if !ok {
+		return 0, false
+	}
+	return int64(v1.TotalNonRefundableResourceFeeCharged) + int64(v1.TotalRefundableResourceFeeCharged), true
+}
+
+// writeAccount writes <prefix>, <prefix>_ed25519 and, for muxed accounts,
+// <prefix>_muxed. The address column always holds the underlying G address.
+func writeAccount(w *rowWriter, prefix string, account xdr.MuxedAccount) {
+	w.String(prefix, account.ToAccountId().Address())
+	switch account.Type {
+	case xdr.CryptoKeyTypeKeyTypeEd25519:
+		key := account.MustEd25519()
+		w.FixedBinary(prefix+"_ed25519", key[:])
+	case xdr.CryptoKeyTypeKeyTypeMuxedEd25519:
+		muxed := account.MustMed25519()
+		w.FixedBinary(prefix+"_ed25519", muxed.Ed25519[:])
+		w.Uint64(prefix+"_muxed", uint64(muxed.Id))
+	}
+}
+
+// recordConversion updates the counters behind GetConversionStats.
+func (c *XDRToArrowConverter) recordConversion(record arrow.Record, err error, elapsed time.Duration) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	c.conversions++
+	c.conversionTime += elapsed
+	if err != nil {
+		c.conversionErrors++
+		return
+	}
+	c.recordsConverted += record.NumRows()
 }
 
 // ValidateArrowRecord validates that an Arrow record matches expected schema
@@ -193,29 +444,36 @@ func (c *XDRToArrowConverter) ValidateArrowRecord(record arrow.Record, expectedS
 	if !record.Schema().Equal(expectedSchema) {
 		return fmt.Errorf("schema mismatch: got %v, expected %v", record.Schema(), expectedSchema)
 	}
-	
-	// Additional validation logic can be added here
-	// - Check for null values in non-nullable fields
-	// - Validate data ranges
-	// - Check string field lengths
-	
+
+	for i, field := range expectedSchema.Fields() {
+		if !field.Nullable && record.Column(i).NullN() > 0 {
+			return fmt.Errorf("non-nullable column %q has %d nulls", field.Name, record.Column(i).NullN())
+		}
+	}
+
 	return nil
 }
 
 // GetConversionStats returns statistics about the conversion process
 func (c *XDRToArrowConverter) GetConversionStats() ConversionStats {
-	return ConversionStats{
-		RecordsConverted: 0, // This would be tracked in real implementation
-		ConversionErrors: 0,
-		AvgConversionTime: 0,
-		MemoryEfficiency: 0.0,
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	stats := ConversionStats{
+		RecordsConverted: c.recordsConverted,
+		ConversionErrors: c.conversionErrors,
 	}
+	if c.conversions > 0 {
+		stats.AvgConversionTime = c.conversionTime / time.Duration(c.conversions)
+	}
+	return stats
 }
 
-// ConversionStats represents conversion performance statistics
+// ConversionStats represents conversion performance statistics. RecordsConverted
+// counts rows across all Convert calls.
 type ConversionStats struct {
 	RecordsConverted  int64
 	ConversionErrors  int64
 	AvgConversionTime time.Duration
 	MemoryEfficiency  float64
-}
\ No newline at end of file
+}
@@ -0,0 +1,524 @@
+package converter
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/withObsrvr/ttp-processor-demo/stellar-arrow-source/schema"
+)
+
+const testPassphrase = network.TestNetworkPassphrase
+
+var (
+	alice  = keypair.Master("converter test alice").(*keypair.Full)
+	bob    = keypair.Master("converter test bob").(*keypair.Full)
+	issuer = keypair.Master("converter test issuer").(*keypair.Full)
+)
+
+func testEnvelope(t *testing.T, source *keypair.Full, seq int64, memo xdr.Memo, ops ...xdr.Operation) (xdr.TransactionEnvelope, xdr.Hash) {
+	t.Helper()
+	envelope := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{
+			Tx: xdr.Transaction{
+				SourceAccount: xdr.MustMuxedAddress(source.Address()),
+				Fee:           xdr.Uint32(100 * len(ops)),
+				SeqNum:        xdr.SequenceNumber(seq),
+				Cond: xdr.Preconditions{
+					Type:       xdr.PreconditionTypePrecondTime,
+					TimeBounds: &xdr.TimeBounds{MinTime: 0, MaxTime: 1700000600},
+				},
+				Memo:       memo,
+				Operations: ops,
+			},
+			Signatures: []xdr.DecoratedSignature{{
+				Hint:      source.Hint(),
+				Signature: make([]byte, 64),
+			}},
+		},
+	}
+	hash, err := network.HashTransactionInEnvelope(envelope, testPassphrase)
+	if err != nil {
+		t.Fatalf("hash envelope: %v", err)
+	}
+	return envelope, hash
+}
+
+func creditAsset(code string) xdr.Asset {
+	return xdr.MustNewCreditAsset(code, issuer.Address())
+}
+
+// testLedgerTransactions builds two transactions: a successful payment plus
+// strict-send path payment, and a failed account creation.
+func testLedgerTransactions(t *testing.T) ([]xdr.TransactionEnvelope, []xdr.TransactionResultPair, []xdr.TransactionMeta) {
+	t.Helper()
+	bobAccount := xdr.MustMuxedAddress(bob.Address())
+	aliceAccount := xdr.MustMuxedAddress(alice.Address())
+	text := "invoice 42"
+
+	paymentEnvelope, paymentHash := testEnvelope(t, alice, 1001, xdr.Memo{Type: xdr.MemoTypeMemoText, Text: &text},
+		xdr.Operation{Body: xdr.OperationBody{
+			Type: xdr.OperationTypePayment,
+			PaymentOp: &xdr.PaymentOp{
+				Destination: bobAccount,
+				Asset:       xdr.MustNewNativeAsset(),
+				Amount:      1000000000,
+			},
+		}},
+		xdr.Operation{
+			SourceAccount: &bobAccount,
+			Body: xdr.OperationBody{
+				Type: xdr.OperationTypePathPaymentStrictSend,
+				PathPaymentStrictSendOp: &xdr.PathPaymentStrictSendOp{
+					SendAsset:   creditAsset("USDC"),
+					SendAmount:  50000000,
+					Destination: aliceAccount,
+					DestAsset:   creditAsset("EURC"),
+					DestMin:     40000000,
+					Path:        []xdr.Asset{xdr.MustNewNativeAsset()},
+				},
+			},
+		},
+	)
+	paymentResult := xdr.TransactionResultPair{
+		TransactionHash: paymentHash,
+		Result: xdr.TransactionResult{
+			FeeCharged: 200,
+			Result: xdr.TransactionResultResult{
+				Code: xdr.TransactionResultCodeTxSuccess,
+				Results: &[]xdr.OperationResult{
+					{Code: xdr.OperationResultCodeOpInner, Tr: &xdr.OperationResultTr{
+						Type:          xdr.OperationTypePayment,
+						PaymentResult: &xdr.PaymentResult{Code: xdr.PaymentResultCodePaymentSuccess},
+					}},
+					{Code: xdr.OperationResultCodeOpInner, Tr: &xdr.OperationResultTr{
+						Type: xdr.OperationTypePathPaymentStrictSend,
+						PathPaymentStrictSendResult: &xdr.PathPaymentStrictSendResult{
+							Code: xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSuccess,
+							Success: &xdr.PathPaymentStrictSendResultSuccess{
+								Last: xdr.SimplePaymentResult{
+									Destination: aliceAccount.ToAccountId(),
+									Asset:       creditAsset("EURC"),
+									Amount:      45500000,
+								},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	createEnvelope, createHash := testEnvelope(t, bob, 2002, xdr.Memo{Type: xdr.MemoTypeMemoNone},
+		xdr.Operation{Body: xdr.OperationBody{
+			Type: xdr.OperationTypeCreateAccount,
+			CreateAccountOp: &xdr.CreateAccountOp{
+				Destination:     xdr.MustAddress(issuer.Address()),
+				StartingBalance: 9990000000000,
+			},
+		}},
+	)
+	createResult := xdr.TransactionResultPair{
+		TransactionHash: createHash,
+		Result: xdr.TransactionResult{
+			FeeCharged: 100,
+			Result: xdr.TransactionResultResult{
+				Code: xdr.TransactionResultCodeTxFailed,
+				Results: &[]xdr.OperationResult{
+					{Code: xdr.OperationResultCodeOpInner, Tr: &xdr.OperationResultTr{
+						Type:                xdr.OperationTypeCreateAccount,
+						CreateAccountResult: &xdr.CreateAccountResult{Code: xdr.CreateAccountResultCodeCreateAccountUnderfunded},
+					}},
+				},
+			},
+		},
+	}
+
+	metas := []xdr.TransactionMeta{
+		{V: 3, V3: &xdr.TransactionMetaV3{Operations: []xdr.OperationMeta{{}, {}}}},
+		{V: 3, V3: &xdr.TransactionMetaV3{}},
+	}
+	// Envelopes are listed in a different order than they were applied, as
+	// tx sets are, so the converter has to match them by hash.
+	return []xdr.TransactionEnvelope{createEnvelope, paymentEnvelope},
+		[]xdr.TransactionResultPair{paymentResult, createResult},
+		metas
+}
+
+func testLedgerCloseMeta(t *testing.T, version int32) xdr.LedgerCloseMeta {
+	t.Helper()
+	envelopes, results, metas := testLedgerTransactions(t)
+	header := xdr.LedgerHeaderHistoryEntry{
+		Hash: xdr.Hash{1, 2, 3},
+		Header: xdr.LedgerHeader{
+			LedgerVersion:      23,
+			PreviousLedgerHash: xdr.Hash{4, 5, 6},
+			ScpValue: xdr.StellarValue{
+				CloseTime: 1700000000,
+				Ext:       xdr.StellarValueExt{V: xdr.StellarValueTypeStellarValueBasic},
+			},
+			LedgerSeq:    52000000,
+			BaseFee:      100,
+			BaseReserve:  5000000,
+			MaxTxSetSize: 1000,
+		},
+	}
+	generalized := xdr.GeneralizedTransactionSet{
+		V: 1,
+		V1TxSet: &xdr.TransactionSetV1{
+			Phases: []xdr.TransactionPhase{{
+				V: 0,
+				V0Components: &[]xdr.TxSetComponent{{
+					Type:                  xdr.TxSetComponentTypeTxsetCompTxsMaybeDiscountedFee,
+					TxsMaybeDiscountedFee: &xdr.TxSetComponentTxsMaybeDiscountedFee{Txs: envelopes},
+				}},
+			}},
+		},
+	}
+
+	switch version {
+	case 0:
+		processing := make([]xdr.TransactionResultMeta, len(results))
+		for i := range results {
+			processing[i] = xdr.TransactionResultMeta{Result: results[i], TxApplyProcessing: metas[i]}
+		}
+		return xdr.LedgerCloseMeta{V: 0, V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: header,
+			TxSet:        xdr.TransactionSet{Txs: envelopes},
+			TxProcessing: processing,
+		}}
+	case 1:
+		processing := make([]xdr.TransactionResultMeta, len(results))
+		for i := range results {
+			processing[i] = xdr.TransactionResultMeta{Result: results[i], TxApplyProcessing: metas[i]}
+		}
+		return xdr.LedgerCloseMeta{V: 1, V1: &xdr.LedgerCloseMetaV1{
+			LedgerHeader: header,
+			TxSet:        generalized,
+			TxProcessing: processing,
+		}}
+	default:
+		processing := make([]xdr.TransactionResultMetaV1, len(results))
+		for i := range results {
+			processing[i] = xdr.TransactionResultMetaV1{Result: results[i], TxApplyProcessing: metas[i]}
+		}
+		return xdr.LedgerCloseMeta{V: 2, V2: &xdr.LedgerCloseMetaV2{
+			LedgerHeader: header,
+			TxSet:        generalized,
+			TxProcessing: processing,
+		}}
+	}
+}
+
+// sdkTransactions decodes raw LedgerCloseMeta XDR the way SDK consumers do.
+func sdkTransactions(t *testing.T, raw []byte) (xdr.LedgerCloseMeta, []ingest.LedgerTransaction) {
+	t.Helper()
+	var lcm xdr.LedgerCloseMeta
+	if err := xdr.SafeUnmarshal(raw, &lcm); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(testPassphrase, lcm)
+	if err != nil {
+		t.Fatalf("reader: %v", err)
+	}
+	defer reader.Close()
+	var txs []ingest.LedgerTransaction
+	for {
+		tx, err := reader.Read()
+		if err == io.EOF {
+			return lcm, txs
+		}
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		txs = append(txs, tx)
+	}
+}
+
+func column(t *testing.T, record arrow.Record, name string) arrow.Array {
+	t.Helper()
+	indices := record.Schema().FieldIndices(name)
+	if len(indices) != 1 {
+		t.Fatalf("column %q not in schema", name)
+	}
+	return record.Column(indices[0])
+}
+
+func newTestConverter(t *testing.T) (*XDRToArrowConverter, *schema.SchemaManager) {
+	t.Helper()
+	allocator := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	t.Cleanup(func() { allocator.AssertSize(t, 0) })
+	schemaManager := schema.NewSchemaManager()
+	return NewXDRToArrowConverter(allocator, schemaManager, testPassphrase), schemaManager
+}
+
+func TestConvertLedgerToArrow(t *testing.T) {
+	for _, version := range []int32{0, 1, 2} {
+		converter, schemaManager := newTestConverter(t)
+		raw, err := testLedgerCloseMeta(t, version).MarshalBinary()
+		if err != nil {
+			t.Fatalf("V%d: marshal: %v", version, err)
+		}
+		lcm, txs := sdkTransactions(t, raw)
+
+		record, err := converter.ConvertLedgerToArrow(raw)
+		if err != nil {
+			t.Fatalf("V%d: convert: %v", version, err)
+		}
+		if err := converter.ValidateArrowRecord(record, schemaManager.GetStellarLedgerSchema()); err != nil {
+			t.Fatalf("V%d: %v", version, err)
+		}
+		if record.NumRows() != 1 {
+			t.Fatalf("V%d: expected 1 row, got %d", version, record.NumRows())
+		}
+
+		var ops, successful uint32
+		for _, tx := range txs {
+			ops += tx.OperationCount()
+			if tx.Successful() {
+				successful++
+			}
+		}
+		ledgerHash := lcm.LedgerHash()
+		prevHash := lcm.PreviousLedgerHash()
+		if got := column(t, record, "ledger_sequence").(*array.Uint32).Value(0); got != lcm.LedgerSequence() {
+			t.Errorf("V%d: ledger_sequence = %d, want %d", version, got, lcm.LedgerSequence())
+		}
+		if got := column(t, record, "ledger_close_time").(*array.Timestamp).Value(0); int64(got) != lcm.ClosedAt().UnixMicro() {
+			t.Errorf("V%d: ledger_close_time = %d, want %d", version, got, lcm.ClosedAt().UnixMicro())
+		}
+		if got := column(t, record, "ledger_hash").(*array.FixedSizeBinary).Value(0); !bytes.Equal(got, ledgerHash[:]) {
+			t.Errorf("V%d: ledger_hash mismatch", version)
+		}
+		if got := column(t, record, "previous_ledger_hash").(*array.FixedSizeBinary).Value(0); !bytes.Equal(got, prevHash[:]) {
+			t.Errorf("V%d: previous_ledger_hash mismatch", version)
+		}
+		counts := map[string]uint32{
+			"transaction_count":            uint32(len(txs)),
+			"operation_count":              ops,
+			"successful_transaction_count": successful,
+			"failed_transaction_count":     uint32(len(txs)) - successful,
+			"protocol_version":             lcm.ProtocolVersion(),
+		}
+		for name, want := range counts {
+			if got := column(t, record, name).(*array.Uint32).Value(0); got != want {
+				t.Errorf("V%d: %s = %d, want %d", version, name, got, want)
+			}
+		}
+		if got := column(t, record, "ledger_close_meta_xdr").(*array.Binary).Value(0); !bytes.Equal(got, raw) {
+			t.Errorf("V%d: ledger_close_meta_xdr does not round-trip", version)
+		}
+		record.Release()
+	}
+}
+
+func TestConvertTransactionToArrow(t *testing.T) {
+	for _, version := range []int32{0, 1, 2} {
+		converter, schemaManager := newTestConverter(t)
+		lcm := testLedgerCloseMeta(t, version)
+		raw, err := lcm.MarshalBinary()
+		if err != nil {
+			t.Fatalf("V%d: marshal: %v", version, err)
+		}
+		_, txs := sdkTransactions(t, raw)
+
+		record, err := converter.ConvertTransactionToArrow(lcm)
+		if err != nil {
+			t.Fatalf("V%d: convert: %v", version, err)
+		}
+		if err := converter.ValidateArrowRecord(record, schemaManager.GetTransactionSchema()); err != nil {
+			t.Fatalf("V%d: %v", version, err)
+		}
+		if int(record.NumRows()) != len(txs) {
+			t.Fatalf("V%d: expected %d rows, got %d", version, len(txs), record.NumRows())
+		}
+
+		for row, tx := range txs {
+			account, err := tx.Account()
+			if err != nil {
+				t.Fatal(err)
+			}
+			envelopeXDR, err := tx.Envelope.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := column(t, record, "transaction_hash").(*array.FixedSizeBinary).Value(row); !bytes.Equal(got, tx.Hash[:]) {
+				t.Errorf("V%d row %d: transaction_hash mismatch", version, row)
+			}
+			if got := column(t, record, "transaction_index").(*array.Uint32).Value(row); got != tx.Index {
+				t.Errorf("V%d row %d: transaction_index = %d, want %d", version, row, got, tx.Index)
+			}
+			if got := column(t, record, "source_account").(*array.String).Value(row); got != account {
+				t.Errorf("V%d row %d: source_account = %s, want %s", version, row, got, account)
+			}
+			if got := column(t, record, "sequence_number").(*array.Int64).Value(row); got != tx.AccountSequence() {
+				t.Errorf("V%d row %d: sequence_number = %d, want %d", version, row, got, tx.AccountSequence())
+			}
+			if got := column(t, record, "fee_charged").(*array.Int64).Value(row); got != int64(tx.Result.Result.FeeCharged) {
+				t.Errorf("V%d row %d: fee_charged = %d, want %d", version, row, got, tx.Result.Result.FeeCharged)
+			}
+			if got := column(t, record, "max_fee").(*array.Int64).Value(row); got != int64(tx.MaxFee()) {
+				t.Errorf("V%d row %d: max_fee = %d, want %d", version, row, got, tx.MaxFee())
+			}
+			if got := column(t, record, "successful").(*array.Boolean).Value(row); got != tx.Successful() {
+				t.Errorf("V%d row %d: successful = %v, want %v", version, row, got, tx.Successful())
+			}
+			if got := column(t, record, "result_code_string").(*array.String).Value(row); got != tx.ResultCode() {
+				t.Errorf("V%d row %d: result_code_string = %s, want %s", version, row, got, tx.ResultCode())
+			}
+			if got := column(t, record, "operation_count").(*array.Uint32).Value(row); got != tx.OperationCount() {
+				t.Errorf("V%d row %d: operation_count = %d, want %d", version, row, got, tx.OperationCount())
+			}
+			memoText := column(t, record, "memo_text").(*array.String)
+			if memo := tx.Memo(); memo != "" {
+				if memoText.IsNull(row) || memoText.Value(row) != memo {
+					t.Errorf("V%d row %d: memo_text does not match %q", version, row, memo)
+				}
+			} else if !memoText.IsNull(row) {
+				t.Errorf("V%d row %d: memo_text should be null", version, row)
+			}
+			if !column(t, record, "time_bounds_max").IsValid(row) {
+				t.Errorf("V%d row %d: time_bounds_max should be set", version, row)
+			}
+			if column(t, record, "soroban_operations_count").IsValid(row) {
+				t.Errorf("V%d row %d: soroban columns should be null for classic transactions", version, row)
+			}
+			if got := column(t, record, "transaction_envelope_xdr").(*array.Binary).Value(row); !bytes.Equal(got, envelopeXDR) {
+				t.Errorf("V%d row %d: transaction_envelope_xdr does not round-trip", version, row)
+			}
+		}
+		record.Release()
+	}
+}
+
+func TestConvertOperationsToArrow(t *testing.T) {
+	converter, schemaManager := newTestConverter(t)
+	raw, err := testLedgerCloseMeta(t, 2).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lcm, txs := sdkTransactions(t, raw)
+
+	record, err := converter.ConvertOperationsToArrow(raw)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	defer record.Release()
+	if err := converter.ValidateArrowRecord(record, schemaManager.GetOperationSchema()); err != nil {
+		t.Fatal(err)
+	}
+
+	row := 0
+	for _, tx := range txs {
+		for opIndex, op := range tx.Envelope.Operations() {
+			if got := column(t, record, "operation_type_string").(*array.String).Value(row); got != op.Body.Type.String() {
+				t.Errorf("row %d: operation_type_string = %s, want %s", row, got, op.Body.Type.String())
+			}
+			if got := column(t, record, "operation_index").(*array.Uint32).Value(row); got != uint32(opIndex) {
+				t.Errorf("row %d: operation_index = %d, want %d", row, got, opIndex)
+			}
+			if got := column(t, record, "successful").(*array.Boolean).Value(row); got != tx.Successful() {
+				t.Errorf("row %d: successful = %v, want %v", row, got, tx.Successful())
+			}
+			if got := column(t, record, "ledger_sequence").(*array.Uint32).Value(row); got != lcm.LedgerSequence() {
+				t.Errorf("row %d: ledger_sequence = %d", row, got)
+			}
+			if op.SourceAccount == nil && column(t, record, "source_account").IsValid(row) {
+				t.Errorf("row %d: source_account should be null without an operation source", row)
+			}
+			row++
+		}
+	}
+	if int(record.NumRows()) != row {
+		t.Fatalf("expected %d rows, got %d", row, record.NumRows())
+	}
+
+	// Row 1 is the strict-send path payment; the delivered amount comes from
+	// its result, not the envelope.
+	if got := column(t, record, "amount").(*array.Int64).Value(1); got != 45500000 {
+		t.Errorf("strict send amount = %d, want 45500000", got)
+	}
+	if got := column(t, record, "send_asset_code").(*array.String).Value(1); got != "USDC" {
+		t.Errorf("send_asset_code = %s, want USDC", got)
+	}
+	if got := column(t, record, "path_length").(*array.Uint32).Value(1); got != 1 {
+		t.Errorf("path_length = %d, want 1", got)
+	}
+	if got := column(t, record, "starting_balance").(*array.Int64).Value(2); got != 9990000000000 {
+		t.Errorf("starting_balance = %d", got)
+	}
+}
+
+func TestConvertTTPEventsToArrow(t *testing.T) {
+	converter, schemaManager := newTestConverter(t)
+	lcm := testLedgerCloseMeta(t, 1)
+
+	record, err := converter.ConvertTTPEventsToArrow([]xdr.LedgerCloseMeta{lcm})
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	defer record.Release()
+	if err := converter.ValidateArrowRecord(record, schemaManager.GetTTPEventSchema()); err != nil {
+		t.Fatal(err)
+	}
+	// The failed create_account transaction produces no event.
+	if record.NumRows() != 2 {
+		t.Fatalf("expected 2 events, got %d", record.NumRows())
+	}
+
+	eventTypes := column(t, record, "event_type").(*array.String)
+	from := column(t, record, "from_account").(*array.String)
+	to := column(t, record, "to_account").(*array.String)
+	amounts := column(t, record, "amount").(*array.Int64)
+	native := column(t, record, "is_native_asset").(*array.Boolean)
+	assetCodes := column(t, record, "asset_code").(*array.String)
+	memos := column(t, record, "memo").(*array.String)
+
+	if eventTypes.Value(0) != "payment" || from.Value(0) != alice.Address() || to.Value(0) != bob.Address() {
+		t.Errorf("payment event = %s %s -> %s", eventTypes.Value(0), from.Value(0), to.Value(0))
+	}
+	if amounts.Value(0) != 1000000000 || !native.Value(0) || !assetCodes.IsNull(0) {
+		t.Errorf("payment amount/asset mismatch")
+	}
+	if memos.Value(0) != "invoice 42" {
+		t.Errorf("memo = %q, want invoice 42", memos.Value(0))
+	}
+
+	if eventTypes.Value(1) != "path_payment_strict_send" || from.Value(1) != bob.Address() || to.Value(1) != alice.Address() {
+		t.Errorf("path payment event = %s %s -> %s", eventTypes.Value(1), from.Value(1), to.Value(1))
+	}
+	if amounts.Value(1) != 45500000 || native.Value(1) || assetCodes.Value(1) != "EURC" {
+		t.Errorf("path payment amount/asset mismatch")
+	}
+	if got := column(t, record, "operation_source_account").(*array.String); got.IsNull(1) || got.Value(1) != bob.Address() {
+		t.Errorf("operation_source_account should be bob")
+	}
+}
+
+func TestConvertRejectsBadInput(t *testing.T) {
+	converter, _ := newTestConverter(t)
+	if _, err := converter.ConvertLedgerToArrow("not a ledger"); err == nil {
+		t.Error("expected an error for an unsupported input type")
+	}
+	if _, err := converter.ConvertLedgerToArrow([]byte{1, 2, 3}); err == nil {
+		t.Error("expected an error for truncated XDR")
+	}
+
+	noNetwork := NewXDRToArrowConverter(memory.NewGoAllocator(), schema.NewSchemaManager(), "")
+	if _, err := noNetwork.ConvertTransactionToArrow(testLedgerCloseMeta(t, 1)); err == nil {
+		t.Error("expected an error converting transactions without a network passphrase")
+	}
+
+	stats := converter.GetConversionStats()
+	if stats.ConversionErrors != 2 {
+		t.Errorf("ConversionErrors = %d, want 2", stats.ConversionErrors)
+	}
+}
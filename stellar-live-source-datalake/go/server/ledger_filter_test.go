@@ -0,0 +1,222 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/strkey"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/withObsrvr/ttp-processor-demo/stellar-live-source-datalake/gen/raw_ledger_service"
+)
+
+func testAccount(b byte) xdr.AccountId {
+	key := xdr.Uint256{b}
+	return xdr.AccountId{Type: xdr.PublicKeyTypePublicKeyTypeEd25519, Ed25519: &key}
+}
+
+func testContract(b byte) xdr.ContractId {
+	return xdr.ContractId{b}
+}
+
+func contractAddress(id xdr.ContractId) xdr.ScAddress {
+	return xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &id}
+}
+
+func testTx(source xdr.AccountId, ops ...xdr.Operation) xdr.TransactionEnvelope {
+	return xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{
+			Tx: xdr.Transaction{SourceAccount: source.ToMuxedAccount(), Operations: ops},
+		},
+	}
+}
+
+// ledgerWithTxs builds a V0 ledger whose envelopes and metas pair up by index
+func ledgerWithTxs(seq uint32, envs []xdr.TransactionEnvelope, metas []xdr.TransactionMeta) xdr.LedgerCloseMeta {
+	lcm := testLedger(seq)
+	lcm.V0.TxSet.Txs = envs
+	for _, meta := range metas {
+		lcm.V0.TxProcessing = append(lcm.V0.TxProcessing, xdr.TransactionResultMeta{
+			Result: xdr.TransactionResultPair{
+				Result: xdr.TransactionResult{
+					Result: xdr.TransactionResultResult{Code: xdr.TransactionResultCodeTxSuccess, Results: &[]xdr.OperationResult{}},
+				},
+			},
+			TxApplyProcessing: meta,
+		})
+	}
+	return lcm
+}
+
+func metaV3(changes xdr.LedgerEntryChanges, events ...xdr.ContractEvent) xdr.TransactionMeta {
+	meta := xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{TxChangesAfter: changes}}
+	if len(events) > 0 {
+		meta.V3.SorobanMeta = &xdr.SorobanTransactionMeta{Events: events}
+	}
+	return meta
+}
+
+func removed(key xdr.LedgerKey) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &key}
+}
+
+func mustFilter(t *testing.T, f *pb.LedgerFilter) *ledgerFilter {
+	t.Helper()
+	lf, err := newLedgerFilter(f)
+	if err != nil {
+		t.Fatalf("newLedgerFilter: %v", err)
+	}
+	return lf
+}
+
+func TestLedgerFilterMatches(t *testing.T) {
+	alice, bob := testAccount(1), testAccount(2)
+	token, other := testContract(1), testContract(2)
+	aliceID := alice.Address()
+	tokenID := strkey.MustEncode(strkey.VersionByteContract, token[:])
+
+	invoke := func(contract xdr.ContractId) xdr.Operation {
+		return xdr.Operation{Body: xdr.OperationBody{
+			Type: xdr.OperationTypeInvokeHostFunction,
+			InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{HostFunction: xdr.HostFunction{
+				Type:           xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+				InvokeContract: &xdr.InvokeContractArgs{ContractAddress: contractAddress(contract)},
+			}},
+		}}
+	}
+	muxedAlice := xdr.MuxedAccount{
+		Type:     xdr.CryptoKeyTypeKeyTypeMuxedEd25519,
+		Med25519: &xdr.MuxedAccountMed25519{Id: 7, Ed25519: *alice.Ed25519},
+	}
+	feeBump := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTxFeeBump,
+		FeeBump: &xdr.FeeBumpTransactionEnvelope{Tx: xdr.FeeBumpTransaction{
+			FeeSource: alice.ToMuxedAccount(),
+			InnerTx:   xdr.FeeBumpTransactionInnerTx{Type: xdr.EnvelopeTypeEnvelopeTypeTx, V1: testTx(bob).V1},
+		}},
+	}
+	aliceTrustline := removed(xdr.LedgerKey{
+		Type:      xdr.LedgerEntryTypeTrustline,
+		TrustLine: &xdr.LedgerKeyTrustLine{AccountId: alice},
+	})
+	tokenData := removed(xdr.LedgerKey{
+		Type:         xdr.LedgerEntryTypeContractData,
+		ContractData: &xdr.LedgerKeyContractData{Contract: contractAddress(token)},
+	})
+	bobAccount := removed(xdr.LedgerKey{
+		Type:    xdr.LedgerEntryTypeAccount,
+		Account: &xdr.LedgerKeyAccount{AccountId: bob},
+	})
+
+	byAlice := &pb.LedgerFilter{AccountIds: []string{aliceID}}
+	byToken := &pb.LedgerFilter{ContractIds: []string{tokenID}}
+	bobOnly := []xdr.TransactionEnvelope{testTx(bob)}
+
+	tests := []struct {
+		name   string
+		filter *pb.LedgerFilter
+		ledger xdr.LedgerCloseMeta
+		want   bool
+	}{
+		{"no filter sends empty ledgers", nil, testLedger(1), true},
+		{"empty filter sends empty ledgers", &pb.LedgerFilter{}, testLedger(1), true},
+		{"skip_empty drops empty ledgers", &pb.LedgerFilter{SkipEmpty: true}, testLedger(1), false},
+		{"skip_empty keeps ledgers with transactions", &pb.LedgerFilter{SkipEmpty: true},
+			ledgerWithTxs(1, bobOnly, []xdr.TransactionMeta{metaV3(nil)}), true},
+		{"accounts drop empty ledgers", byAlice, testLedger(1), false},
+		{"transaction source", byAlice,
+			ledgerWithTxs(1, []xdr.TransactionEnvelope{testTx(alice)}, []xdr.TransactionMeta{metaV3(nil)}), true},
+		{"fee-bump source", byAlice,
+			ledgerWithTxs(1, []xdr.TransactionEnvelope{feeBump}, []xdr.TransactionMeta{metaV3(nil)}), true},
+		{"muxed operation source", byAlice,
+			ledgerWithTxs(1, []xdr.TransactionEnvelope{testTx(bob, xdr.Operation{SourceAccount: &muxedAlice})}, []xdr.TransactionMeta{metaV3(nil)}), true},
+		{"trustline change", byAlice,
+			ledgerWithTxs(1, bobOnly, []xdr.TransactionMeta{metaV3(xdr.LedgerEntryChanges{aliceTrustline})}), true},
+		{"untouched account", byAlice,
+			ledgerWithTxs(1, bobOnly, []xdr.TransactionMeta{metaV3(xdr.LedgerEntryChanges{bobAccount})}), false},
+		{"contract invocation", byToken,
+			ledgerWithTxs(1, []xdr.TransactionEnvelope{testTx(bob, invoke(token))}, []xdr.TransactionMeta{metaV3(nil)}), true},
+		{"V3 contract event", byToken,
+			ledgerWithTxs(1, []xdr.TransactionEnvelope{testTx(bob, invoke(other))}, []xdr.TransactionMeta{metaV3(nil, xdr.ContractEvent{ContractId: &token})}), true},
+		{"V4 operation event", byToken,
+			ledgerWithTxs(1, bobOnly, []xdr.TransactionMeta{{V: 4, V4: &xdr.TransactionMetaV4{
+				Operations: []xdr.OperationMetaV2{{Events: []xdr.ContractEvent{{ContractId: &token}}}},
+			}}}), true},
+		{"V4 transaction event", byToken,
+			ledgerWithTxs(1, bobOnly, []xdr.TransactionMeta{{V: 4, V4: &xdr.TransactionMetaV4{
+				Events: []xdr.TransactionEvent{{Event: xdr.ContractEvent{ContractId: &token}}},
+			}}}), true},
+		{"contract data change", byToken,
+			ledgerWithTxs(1, bobOnly, []xdr.TransactionMeta{metaV3(xdr.LedgerEntryChanges{tokenData})}), true},
+		{"other contract", byToken,
+			ledgerWithTxs(1, []xdr.TransactionEnvelope{testTx(alice, invoke(other))}, []xdr.TransactionMeta{metaV3(nil, xdr.ContractEvent{ContractId: &other})}), false},
+		{"accounts or contracts", &pb.LedgerFilter{AccountIds: []string{aliceID}, ContractIds: []string{tokenID}},
+			ledgerWithTxs(1, []xdr.TransactionEnvelope{testTx(bob, invoke(token))}, []xdr.TransactionMeta{metaV3(nil)}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustFilter(t, tt.filter).matches(tt.ledger); got != tt.want {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLedgerFilterRejectsBadIDs(t *testing.T) {
+	token := testContract(1)
+	tokenID := strkey.MustEncode(strkey.VersionByteContract, token[:])
+	aliceID := testAccount(1).Address()
+
+	for _, f := range []*pb.LedgerFilter{
+		{AccountIds: []string{"GABC"}},
+		{AccountIds: []string{tokenID}},
+		{ContractIds: []string{aliceID}},
+	} {
+		if _, err := newLedgerFilter(f); err == nil {
+			t.Errorf("newLedgerFilter(%+v) succeeded", f)
+		}
+	}
+}
+
+func TestStreamRawLedgersFiltersLedgers(t *testing.T) {
+	alice, bob := testAccount(1), testAccount(2)
+	ledgers := []xdr.LedgerCloseMeta{testLedger(100), testLedger(101), testLedger(102), testLedger(103), testLedger(104)}
+	ledgers[1] = ledgerWithTxs(101, []xdr.TransactionEnvelope{testTx(bob)}, []xdr.TransactionMeta{metaV3(nil)})
+	ledgers[3] = ledgerWithTxs(103, []xdr.TransactionEnvelope{testTx(alice)}, []xdr.TransactionMeta{metaV3(nil)})
+	path := writeLedgers(t, ledgers)
+
+	s := newTestServer(&LedgerBackendConfig{BackendType: "XDR", XDRFilePath: path})
+	stream := &fakeLedgerStream{ctx: context.Background()}
+	req := &pb.StreamLedgersRequest{
+		StartLedger: 100,
+		EndLedger:   104,
+		Filter:      &pb.LedgerFilter{AccountIds: []string{alice.Address()}},
+	}
+	if err := s.StreamRawLedgers(req, stream); err != nil {
+		t.Fatalf("stream ended with %v, want clean EOF", err)
+	}
+	if got := stream.sequences(); len(got) != 1 || got[0] != 103 {
+		t.Fatalf("streamed %v, want [103]", got)
+	}
+	if seq, err := decodeResumeToken(testPassphrase, stream.sent[0].ResumeToken); err != nil || seq != 103 {
+		t.Errorf("resume token decodes to %d (%v), want 103", seq, err)
+	}
+
+	stream = &fakeLedgerStream{ctx: context.Background()}
+	req.Filter = &pb.LedgerFilter{SkipEmpty: true}
+	if err := s.StreamRawLedgers(req, stream); err != nil {
+		t.Fatalf("skip_empty stream ended with %v", err)
+	}
+	if got := stream.sequences(); len(got) != 2 || got[0] != 101 || got[1] != 103 {
+		t.Errorf("skip_empty streamed %v, want [101 103]", got)
+	}
+
+	req.Filter = &pb.LedgerFilter{ContractIds: []string{"CNOTACONTRACT"}}
+	if err := s.StreamRawLedgers(req, &fakeLedgerStream{ctx: context.Background()}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("bad contract id: err = %v, want InvalidArgument", err)
+	}
+}
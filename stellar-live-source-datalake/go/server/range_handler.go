@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/stellar/go-stellar-sdk/ingest/ledgerbackend"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/withObsrvr/ttp-processor-demo/stellar-live-source-datalake/gen/raw_ledger_service"
+)
+
+// GetLedgerRange implements the batch API for retrieving a specific range of ledgers
+// Range semantics: [start_ledger, end_ledger) - start inclusive, end exclusive,
+// the same as stellar-live-source so clients can switch between the two.
+func (s *RawLedgerServer) GetLedgerRange(ctx context.Context, req *pb.GetLedgerRangeRequest) (*pb.GetLedgerRangeResponse, error) {
+	s.logger.Info("GetLedgerRange request",
+		zap.Uint32("start_ledger", req.StartLedger),
+		zap.Uint32("end_ledger", req.EndLedger),
+		zap.String("backend_type", s.config.BackendType),
+	)
+
+	// Validate range
+	if req.EndLedger <= req.StartLedger {
+		return nil, status.Error(codes.InvalidArgument, "end_ledger must be greater than start_ledger")
+	}
+	rangeSize := req.EndLedger - req.StartLedger
+	if rangeSize > s.config.MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"range too large: %d ledgers requested (max: %d)",
+			rangeSize, s.config.MaxBatchSize)
+	}
+
+	// Check circuit breaker
+	if !s.circuitBreaker.Allow() {
+		s.logger.Warn("Circuit breaker open - service temporarily unavailable",
+			zap.String("state", s.circuitBreaker.state),
+		)
+		return nil, status.Error(codes.Unavailable, "service temporarily unavailable")
+	}
+
+	backend, lastAvailable, err := s.createLedgerBackend(ctx, rangeSize)
+	if err != nil {
+		s.logger.Error("Failed to create ledger backend",
+			zap.String("backend_type", s.config.BackendType),
+			zap.Error(err),
+		)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to create ledger backend: %v", err))
+	}
+	defer backend.Close()
+
+	lastLedger := req.EndLedger - 1
+	if lastAvailable != 0 && lastLedger > lastAvailable {
+		return nil, status.Errorf(codes.OutOfRange,
+			"end ledger %d is past the last available ledger %d", lastLedger, lastAvailable)
+	}
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(req.StartLedger, lastLedger)); err != nil {
+		s.logger.Error("Failed to prepare ledger range",
+			zap.Uint32("start_ledger", req.StartLedger),
+			zap.Uint32("end_ledger", req.EndLedger),
+			zap.Error(err),
+		)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to prepare range: %v", err))
+	}
+
+	startTime := time.Now()
+	ledgers := make([]*pb.RawLedger, 0, rangeSize)
+	for seq := req.StartLedger; seq <= lastLedger; seq++ {
+		ledgerStartTime := time.Now()
+		ledgerCtx, cancel := context.WithTimeout(ctx, ledgerReadTimeout)
+		lcm, err := backend.GetLedger(ledgerCtx, seq)
+		cancel()
+		if err != nil {
+			s.handleLedgerError(err, seq)
+			if ctx.Err() != nil {
+				return nil, status.FromContextError(ctx.Err()).Err()
+			}
+			return nil, status.Errorf(codes.Unavailable, "failed to get ledger %d: %v", seq, err)
+		}
+
+		rawLedger, err := s.convertLedgerToProto(lcm)
+		if err != nil {
+			s.handleLedgerError(err, seq)
+			return nil, status.Errorf(codes.Internal, "failed to convert ledger %d: %v", seq, err)
+		}
+		ledgers = append(ledgers, rawLedger)
+
+		s.updateSuccessMetrics(lcm, time.Since(ledgerStartTime), len(rawLedger.LedgerCloseMetaXdr))
+		s.circuitBreaker.RecordSuccess()
+	}
+
+	latestLedger := lastAvailable
+	if latestLedger == 0 {
+		latestLedger, err = backend.GetLatestLedgerSequence(ctx)
+		if err != nil {
+			s.logger.Warn("Failed to get latest ledger from backend", zap.Error(err))
+		}
+	}
+
+	s.logger.Info("GetLedgerRange completed",
+		zap.Int("ledgers_returned", len(ledgers)),
+		zap.Duration("latency", time.Since(startTime)),
+	)
+
+	return &pb.GetLedgerRangeResponse{
+		Ledgers:      ledgers,
+		LatestLedger: latestLedger,
+	}, nil
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Resume tokens are opaque to clients. Inside they name the network and the
+// last ledger delivered, so a token minted on testnet cannot silently resume
+// a pubnet stream at the same sequence number.
+const resumeTokenVersion = "v1"
+
+func networkTag(networkPassphrase string) string {
+	sum := sha256.Sum256([]byte(networkPassphrase))
+	return hex.EncodeToString(sum[:4])
+}
+
+func encodeResumeToken(networkPassphrase string, sequence uint32) string {
+	raw := fmt.Sprintf("%s:%s:%d", resumeTokenVersion, networkTag(networkPassphrase), sequence)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeResumeToken returns the last ledger delivered before the token was
+// issued; the stream resumes at the ledger after it.
+func decodeResumeToken(networkPassphrase, token string) (uint32, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("malformed resume token")
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 || parts[0] != resumeTokenVersion {
+		return 0, fmt.Errorf("malformed resume token")
+	}
+	if parts[1] != networkTag(networkPassphrase) {
+		return 0, fmt.Errorf("resume token was issued for a different network")
+	}
+	sequence, err := strconv.ParseUint(parts[2], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("malformed resume token")
+	}
+	return uint32(sequence), nil
+}
@@ -150,6 +150,16 @@ type LedgerBackendConfig struct {
 	ArchiveStorageType string
 	ArchiveBucketName  string
 	ArchivePath        string
+
+	// XDR specific: framed LedgerCloseMeta file written by `nebu fetch`
+	XDRFilePath string
+
+	// EndLedger stops every stream after this ledger (0 = follow the backend).
+	// Local FS and XDR backends stop at their last ledger when it is unset.
+	EndLedger uint32
+
+	// MaxBatchSize caps the number of ledgers one GetLedgerRange call returns
+	MaxBatchSize uint32
 }
 
 type RawLedgerServer struct {
@@ -175,10 +185,8 @@ func loadConfiguration() (*LedgerBackendConfig, error) {
 		// Check for old STORAGE_TYPE configuration
 		if oldStorageType := os.Getenv("STORAGE_TYPE"); oldStorageType != "" {
 			switch oldStorageType {
-			case "GCS", "S3":
+			case "GCS", "S3", "FS":
 				backendType = "ARCHIVE"
-			case "FS":
-				backendType = "CAPTIVE_CORE" // Default fallback
 			default:
 				backendType = "CAPTIVE_CORE" // Default
 			}
@@ -191,6 +199,8 @@ func loadConfiguration() (*LedgerBackendConfig, error) {
 		BackendType:       backendType,
 		NetworkPassphrase: getEnvOrDefault("NETWORK_PASSPHRASE", "Public Network; September 2015"),
 		HistoryArchiveURLs: strings.Split(getEnvOrDefault("HISTORY_ARCHIVE_URLS", ""), ","),
+		EndLedger:          uint32(getEnvAsUint("END_LEDGER", 0)),
+		MaxBatchSize:       uint32(getEnvAsUint("MAX_BATCH_SIZE", 1000)),
 	}
 	
 	switch config.BackendType {
@@ -219,9 +229,19 @@ func loadConfiguration() (*LedgerBackendConfig, error) {
 			config.ArchiveBucketName = os.Getenv("BUCKET_NAME") // Fallback to old config
 		}
 		
-		if config.ArchiveStorageType == "" || config.ArchiveBucketName == "" {
+		if config.ArchiveStorageType == "FS" {
+			// Local directory in datastore layout; there is no bucket.
+			if config.ArchivePath == "" {
+				return nil, fmt.Errorf("ARCHIVE_PATH required for ARCHIVE_STORAGE_TYPE=FS")
+			}
+		} else if config.ArchiveStorageType == "" || config.ArchiveBucketName == "" {
 			return nil, fmt.Errorf("ARCHIVE_STORAGE_TYPE (or STORAGE_TYPE) and ARCHIVE_BUCKET_NAME (or BUCKET_NAME) required for ARCHIVE backend")
 		}
+	case "XDR":
+		config.XDRFilePath = os.Getenv("XDR_FILE_PATH")
+		if config.XDRFilePath == "" {
+			return nil, fmt.Errorf("XDR_FILE_PATH required for XDR backend")
+		}
 	}
 	
 	return config, nil
@@ -231,7 +251,7 @@ func (s *RawLedgerServer) migrateOldConfiguration() {
 	// Map old STORAGE_TYPE to new BACKEND_TYPE
 	if oldStorageType := os.Getenv("STORAGE_TYPE"); oldStorageType != "" && os.Getenv("BACKEND_TYPE") == "" {
 		switch oldStorageType {
-		case "GCS", "S3":
+		case "GCS", "S3", "FS":
 			// Update server config directly
 			s.config.BackendType = "ARCHIVE"
 			s.config.ArchiveStorageType = oldStorageType
@@ -247,9 +267,6 @@ func (s *RawLedgerServer) migrateOldConfiguration() {
 				zap.String("bucket_name", s.config.ArchiveBucketName),
 				zap.String("archive_path", s.config.ArchivePath),
 			)
-		case "FS":
-			// FS storage type is not supported in official datastore, fallback to RPC
-			s.logger.Warn("FS storage type not supported, please use BACKEND_TYPE=RPC or BACKEND_TYPE=CAPTIVE_CORE")
 		}
 	}
 	
@@ -305,16 +322,33 @@ func NewRawLedgerServer() (*RawLedgerServer, error) {
 }
 
 // Backend creation methods
-func (s *RawLedgerServer) createLedgerBackend(ctx context.Context) (ledgerbackend.LedgerBackend, error) {
+
+// createLedgerBackend returns the configured backend and, for the local FS and
+// XDR backends, the last ledger they hold (0 when the backend keeps growing).
+// batchSizeHint is the client's batch size hint, 0 when it gave none.
+func (s *RawLedgerServer) createLedgerBackend(ctx context.Context, batchSizeHint uint32) (ledgerbackend.LedgerBackend, uint32, error) {
 	switch s.config.BackendType {
 	case "CAPTIVE_CORE":
-		return s.createCaptiveCore()
+		backend, err := s.createCaptiveCore()
+		return backend, 0, err
 	case "RPC":
-		return s.createRPCBackend()
+		backend, err := s.createRPCBackend()
+		return backend, 0, err
 	case "ARCHIVE":
-		return s.createArchiveBackend()
+		return s.createArchiveBackend(ctx, batchSizeHint)
+	case "XDR":
+		backend, err := newXDRFileBackend(s.config.XDRFilePath)
+		if err != nil {
+			return nil, 0, err
+		}
+		s.logger.Info("Created XDR file backend",
+			zap.String("xdr_file_path", s.config.XDRFilePath),
+			zap.Uint32("first_ledger", backend.first),
+			zap.Uint32("last_ledger", backend.last),
+		)
+		return backend, backend.last, nil
 	default:
-		return nil, fmt.Errorf("unsupported backend type: %s", s.config.BackendType)
+		return nil, 0, fmt.Errorf("unsupported backend type: %s", s.config.BackendType)
 	}
 }
 
@@ -373,7 +407,7 @@ func (s *RawLedgerServer) createRPCBackend() (ledgerbackend.LedgerBackend, error
 	return ledgerbackend.NewRPCLedgerBackend(options), nil
 }
 
-func (s *RawLedgerServer) createArchiveBackend() (ledgerbackend.LedgerBackend, error) {
+func (s *RawLedgerServer) createArchiveBackend(ctx context.Context, batchSizeHint uint32) (ledgerbackend.LedgerBackend, uint32, error) {
 	// Create datastore configuration
 	schema := datastore.DataStoreSchema{
 		LedgersPerFile:    uint32(getEnvAsUint("LEDGERS_PER_FILE", 64)),
@@ -404,8 +438,12 @@ func (s *RawLedgerServer) createArchiveBackend() (ledgerbackend.LedgerBackend, e
 			dsParams["force_path_style"] = os.Getenv("S3_FORCE_PATH_STYLE")
 		}
 		dsConfig = datastore.DataStoreConfig{Type: "S3", Schema: schema, Params: dsParams}
+	case "FS":
+		// Local directory with the same ledger batch layout as GCS/S3
+		dsParams["destination_path"] = s.config.ArchivePath
+		dsConfig = datastore.DataStoreConfig{Type: "Filesystem", Schema: schema, Params: dsParams}
 	default:
-		return nil, fmt.Errorf("unsupported ARCHIVE_STORAGE_TYPE: %s (supported: GCS, S3)", s.config.ArchiveStorageType)
+		return nil, 0, fmt.Errorf("unsupported ARCHIVE_STORAGE_TYPE: %s (supported: GCS, S3, FS)", s.config.ArchiveStorageType)
 	}
 
 	// Create the datastore
@@ -421,7 +459,7 @@ func (s *RawLedgerServer) createArchiveBackend() (ledgerbackend.LedgerBackend, e
 			zap.String("bucket_name", s.config.ArchiveBucketName),
 			zap.Error(err),
 		)
-		return nil, fmt.Errorf("failed to create datastore: %w", err)
+		return nil, 0, fmt.Errorf("failed to create datastore: %w", err)
 	}
 	
 	s.logger.Info("Successfully created datastore connection",
@@ -429,11 +467,33 @@ func (s *RawLedgerServer) createArchiveBackend() (ledgerbackend.LedgerBackend, e
 		zap.String("bucket_name", s.config.ArchiveBucketName),
 	)
 
+	// A local directory does not grow while we stream it, so streams stop at
+	// its last ledger instead of waiting for files that never arrive.
+	var lastLedger uint32
+	if s.config.ArchiveStorageType == "FS" {
+		lastLedger, err = datastore.FindLatestLedgerSequence(ctx, dataStore)
+		if err != nil {
+			dataStore.Close()
+			return nil, 0, fmt.Errorf("failed to find ledger files under %s: %w", s.config.ArchivePath, err)
+		}
+	}
+
 	// Create BufferedStorageBackend configuration
 	// BufferSize controls read-ahead: lower = less latency at tip, higher = faster catch-up.
 	// At 1 ledger/5s, BufferSize=5 adds ~25s max latency; BufferSize=100 adds ~500s.
 	bufferSize := uint32(getEnvAsUint("BUFFER_SIZE", 5))
 	numWorkers := uint32(getEnvAsUint("NUM_WORKERS", 2))
+	// A client that takes small batches gains nothing from reading far ahead,
+	// so don't buffer more files than one of its batches spans.
+	if batchSizeHint > 0 {
+		hintFiles := (batchSizeHint + schema.LedgersPerFile - 1) / schema.LedgersPerFile
+		if hintFiles < bufferSize {
+			bufferSize = hintFiles
+		}
+	}
+	if numWorkers > bufferSize {
+		numWorkers = bufferSize
+	}
 	s.logger.Info("Archive backend buffer config",
 		zap.Uint32("buffer_size", bufferSize),
 		zap.Uint32("num_workers", numWorkers),
@@ -449,7 +509,7 @@ func (s *RawLedgerServer) createArchiveBackend() (ledgerbackend.LedgerBackend, e
 	backend, err := ledgerbackend.NewBufferedStorageBackend(bufferedConfig, dataStore, schema)
 	if err != nil {
 		dataStore.Close()
-		return nil, fmt.Errorf("failed to create buffered storage backend: %w", err)
+		return nil, 0, fmt.Errorf("failed to create buffered storage backend: %w", err)
 	}
 
 	s.logger.Info("Created archive backend",
@@ -460,7 +520,7 @@ func (s *RawLedgerServer) createArchiveBackend() (ledgerbackend.LedgerBackend, e
 		zap.Uint32("files_per_partition", schema.FilesPerPartition),
 	)
 
-	return backend, nil
+	return backend, lastLedger, nil
 }
 
 // Protocol 23 upgrade constants
@@ -473,12 +533,36 @@ func (s *RawLedgerServer) StreamRawLedgers(req *pb.StreamLedgersRequest, stream
 	ctx := stream.Context()
 	s.logger.Info("Starting enterprise ledger stream with official Stellar backend",
 		zap.Uint32("start_sequence", req.StartLedger),
+		zap.Uint32("end_ledger", req.EndLedger),
+		zap.Uint32("batch_size_hint", req.BatchSizeHint),
+		zap.Bool("resuming", req.ResumeToken != ""),
+		zap.Bool("filtered", req.Filter != nil),
 		zap.String("backend_type", s.config.BackendType),
 		zap.Duration("max_latency_p99", MaxLatencyP99),
 	)
 
+	startLedger := req.StartLedger
+	if req.ResumeToken != "" {
+		lastDelivered, err := decodeResumeToken(s.config.NetworkPassphrase, req.ResumeToken)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		startLedger = lastDelivered + 1
+	}
+
+	filter, err := newLedgerFilter(req.Filter)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// The client's end ledger can narrow the operator's END_LEDGER, not widen it
+	endLedger := req.EndLedger
+	if endLedger == 0 || (s.config.EndLedger != 0 && s.config.EndLedger < endLedger) {
+		endLedger = s.config.EndLedger
+	}
+
 	// Create backend based on configuration
-	backend, err := s.createLedgerBackend(ctx)
+	backend, lastAvailable, err := s.createLedgerBackend(ctx, req.BatchSizeHint)
 	if err != nil {
 		s.logger.Error("Failed to create ledger backend",
 			zap.String("backend_type", s.config.BackendType),
@@ -489,29 +573,56 @@ func (s *RawLedgerServer) StreamRawLedgers(req *pb.StreamLedgersRequest, stream
 	defer backend.Close()
 
 	// Prepare range for processing
-	ledgerRange := ledgerbackend.UnboundedRange(req.StartLedger)
+	ledgerRange, err := streamRange(startLedger, endLedger, lastAvailable)
+	if err != nil {
+		return status.Error(codes.OutOfRange, err.Error())
+	}
 	if err := backend.PrepareRange(ctx, ledgerRange); err != nil {
 		s.logger.Error("Failed to prepare ledger range",
-			zap.Uint32("start_ledger", req.StartLedger),
+			zap.Uint32("start_ledger", startLedger),
 			zap.Error(err),
 		)
 		return status.Error(codes.Internal, fmt.Sprintf("failed to prepare range: %v", err))
 	}
 
 	s.logger.Info("Backend prepared successfully",
-		zap.Uint32("start_ledger", req.StartLedger),
+		zap.Uint32("start_ledger", startLedger),
+		zap.Uint32("end_ledger", ledgerRange.To()),
 		zap.String("backend_type", s.config.BackendType),
 	)
 
 	// Stream ledgers
-	return s.streamLedgersFromBackend(ctx, backend, req.StartLedger, stream)
+	return s.streamLedgersFromBackend(ctx, backend, startLedger, ledgerRange.To(), filter, stream)
+}
+
+// streamRange picks the range a stream covers: up to endLedger when set,
+// otherwise up to the last ledger of a local backend, otherwise unbounded.
+func streamRange(start, endLedger, lastAvailable uint32) (ledgerbackend.Range, error) {
+	end := endLedger
+	if end == 0 {
+		end = lastAvailable
+	}
+	if end == 0 {
+		return ledgerbackend.UnboundedRange(start), nil
+	}
+	if start > end {
+		return ledgerbackend.Range{}, fmt.Errorf("start ledger %d is after end ledger %d", start, end)
+	}
+	if lastAvailable != 0 && end > lastAvailable {
+		return ledgerbackend.Range{}, fmt.Errorf("end ledger %d is past the last available ledger %d", end, lastAvailable)
+	}
+	return ledgerbackend.BoundedRange(start, end), nil
 }
 
-func (s *RawLedgerServer) streamLedgersFromBackend(ctx context.Context, backend ledgerbackend.LedgerBackend, startSeq uint32, stream pb.RawLedgerService_StreamRawLedgersServer) error {
+// streamLedgersFromBackend sends ledgers from startSeq on. A non-zero endSeq
+// closes the stream cleanly once that ledger has been sent; a bounded stream
+// fails on a ledger it cannot read instead of skipping it, so the client can
+// resume without a gap. Ledgers the filter rejects are read but not sent.
+func (s *RawLedgerServer) streamLedgersFromBackend(ctx context.Context, backend ledgerbackend.LedgerBackend, startSeq, endSeq uint32, filter *ledgerFilter, stream pb.RawLedgerService_StreamRawLedgersServer) error {
 	processedCount := 0
 	startTime := time.Now()
 
-	for seq := startSeq; ; seq++ {
+	for seq := startSeq; endSeq == 0 || seq <= endSeq; seq++ {
 		select {
 		case <-ctx.Done():
 			s.logger.Info("Context cancelled during streaming",
@@ -576,6 +687,9 @@ func (s *RawLedgerServer) streamLedgersFromBackend(ctx context.Context, backend
 				// Return error to force client reconnection with fresh backend
 				return status.Error(codes.Unavailable, fmt.Sprintf("backend state corrupted at ledger %d, please reconnect", seq))
 			}
+			if endSeq != 0 {
+				return status.Error(codes.Unavailable, fmt.Sprintf("failed to read ledger %d: %v", seq, err))
+			}
 			continue
 		}
 
@@ -604,6 +718,11 @@ func (s *RawLedgerServer) streamLedgersFromBackend(ctx context.Context, backend
 			return status.Error(codes.Internal, fmt.Sprintf("protocol 23 validation failed: %v", err))
 		}
 
+		if !filter.matches(lcm) {
+			s.circuitBreaker.RecordSuccess()
+			continue
+		}
+
 		// Convert to protobuf and stream
 		rawLedger, err := s.convertLedgerToProto(lcm)
 		if err != nil {
@@ -612,8 +731,12 @@ func (s *RawLedgerServer) streamLedgersFromBackend(ctx context.Context, backend
 				zap.Error(err),
 			)
 			s.handleLedgerError(err, lcm.LedgerSequence())
+			if endSeq != 0 {
+				return status.Error(codes.Internal, fmt.Sprintf("failed to convert ledger %d: %v", seq, err))
+			}
 			continue
 		}
+		rawLedger.ResumeToken = encodeResumeToken(s.config.NetworkPassphrase, rawLedger.Sequence)
 
 		if err := stream.Send(rawLedger); err != nil {
 			s.logger.Error("Failed to send ledger to stream",
@@ -637,6 +760,13 @@ func (s *RawLedgerServer) streamLedgersFromBackend(ctx context.Context, backend
 			)
 		}
 	}
+
+	s.logger.Info("Reached end ledger, closing stream",
+		zap.Uint32("end_ledger", endSeq),
+		zap.Int("total_processed", processedCount),
+		zap.Duration("elapsed_time", time.Since(startTime)),
+	)
+	return nil
 }
 
 func (s *RawLedgerServer) handleLedgerError(err error, seq uint32) {
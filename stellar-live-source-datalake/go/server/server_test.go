@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/ingest/ledgerbackend"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/withObsrvr/ttp-processor-demo/stellar-live-source-datalake/gen/raw_ledger_service"
+)
+
+func seqRange(first, last uint32) []uint32 {
+	var seqs []uint32
+	for seq := first; seq <= last; seq++ {
+		seqs = append(seqs, seq)
+	}
+	return seqs
+}
+
+func equalSeqs(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStreamRawLedgersEndLedger(t *testing.T) {
+	path := writeXDRFile(t, 100, 109)
+
+	tests := []struct {
+		name          string
+		configEnd     uint32
+		requestEnd    uint32
+		wantFirst     uint32
+		wantLast      uint32
+		wantErrorCode codes.Code
+	}{
+		{"defaults to the last ledger on disk", 0, 0, 100, 109, codes.OK},
+		{"client end ledger", 0, 103, 100, 103, codes.OK},
+		{"END_LEDGER", 105, 0, 100, 105, codes.OK},
+		{"client narrows END_LEDGER", 105, 102, 100, 102, codes.OK},
+		{"client cannot widen END_LEDGER", 105, 107, 100, 105, codes.OK},
+		{"past the last ledger on disk", 0, 115, 0, 0, codes.OutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(&LedgerBackendConfig{BackendType: "XDR", XDRFilePath: path, EndLedger: tt.configEnd})
+			stream := &fakeLedgerStream{ctx: context.Background()}
+			err := s.StreamRawLedgers(&pb.StreamLedgersRequest{StartLedger: 100, EndLedger: tt.requestEnd}, stream)
+			if status.Code(err) != tt.wantErrorCode {
+				t.Fatalf("err = %v, want code %v", err, tt.wantErrorCode)
+			}
+			if tt.wantErrorCode != codes.OK {
+				return
+			}
+			if got, want := stream.sequences(), seqRange(tt.wantFirst, tt.wantLast); !equalSeqs(got, want) {
+				t.Errorf("streamed %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestStreamRawLedgersResumeToken(t *testing.T) {
+	s := newTestServer(&LedgerBackendConfig{BackendType: "XDR", XDRFilePath: writeXDRFile(t, 100, 109)})
+
+	stream := &fakeLedgerStream{ctx: context.Background()}
+	req := &pb.StreamLedgersRequest{StartLedger: 100, ResumeToken: encodeResumeToken(testPassphrase, 104)}
+	if err := s.StreamRawLedgers(req, stream); err != nil {
+		t.Fatalf("stream ended with %v", err)
+	}
+	if got, want := stream.sequences(), seqRange(105, 109); !equalSeqs(got, want) {
+		t.Errorf("resumed stream sent %v, want %v", got, want)
+	}
+
+	for _, token := range []string{
+		encodeResumeToken("Public Global Stellar Network ; September 2015", 104),
+		"not-a-token",
+	} {
+		req.ResumeToken = token
+		err := s.StreamRawLedgers(req, &fakeLedgerStream{ctx: context.Background()})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("token %q: err = %v, want InvalidArgument", token, err)
+		}
+	}
+
+	// Resuming after the last ledger on disk is out of range
+	req.ResumeToken = encodeResumeToken(testPassphrase, 109)
+	if err := s.StreamRawLedgers(req, &fakeLedgerStream{ctx: context.Background()}); status.Code(err) != codes.OutOfRange {
+		t.Errorf("resume past the end: err = %v, want OutOfRange", err)
+	}
+}
+
+func TestStreamRange(t *testing.T) {
+	tests := []struct {
+		name                            string
+		start, endLedger, lastAvailable uint32
+		want                            ledgerbackend.Range
+		wantErr                         bool
+	}{
+		{"unbounded remote backend", 100, 0, 0, ledgerbackend.UnboundedRange(100), false},
+		{"bounded remote backend", 100, 200, 0, ledgerbackend.BoundedRange(100, 200), false},
+		{"local backend defaults to its last ledger", 100, 0, 150, ledgerbackend.BoundedRange(100, 150), false},
+		{"end ledger within local backend", 100, 120, 150, ledgerbackend.BoundedRange(100, 120), false},
+		{"single ledger", 150, 150, 150, ledgerbackend.BoundedRange(150, 150), false},
+		{"end ledger past local backend", 100, 151, 150, ledgerbackend.Range{}, true},
+		{"start after end ledger", 121, 120, 0, ledgerbackend.Range{}, true},
+		{"start after local backend", 151, 0, 150, ledgerbackend.Range{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := streamRange(tt.start, tt.endLedger, tt.lastAvailable)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: range = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetLedgerRange(t *testing.T) {
+	s := newTestServer(&LedgerBackendConfig{BackendType: "XDR", XDRFilePath: writeXDRFile(t, 100, 109), MaxBatchSize: 5})
+	ctx := context.Background()
+
+	resp, err := s.GetLedgerRange(ctx, &pb.GetLedgerRangeRequest{StartLedger: 100, EndLedger: 103})
+	if err != nil {
+		t.Fatalf("GetLedgerRange: %v", err)
+	}
+	var got []uint32
+	for _, l := range resp.Ledgers {
+		got = append(got, l.Sequence)
+	}
+	if want := seqRange(100, 102); !equalSeqs(got, want) {
+		t.Errorf("returned %v, want %v (end ledger is exclusive)", got, want)
+	}
+	if resp.LatestLedger != 109 {
+		t.Errorf("LatestLedger = %d, want 109", resp.LatestLedger)
+	}
+
+	if _, err := s.GetLedgerRange(ctx, &pb.GetLedgerRangeRequest{StartLedger: 105, EndLedger: 110}); err != nil {
+		t.Errorf("range ending at the last ledger on disk: %v", err)
+	}
+
+	for _, tt := range []struct {
+		start, end uint32
+		want       codes.Code
+	}{
+		{103, 103, codes.InvalidArgument},
+		{103, 100, codes.InvalidArgument},
+		{100, 106, codes.InvalidArgument},
+		{106, 111, codes.OutOfRange},
+	} {
+		_, err := s.GetLedgerRange(ctx, &pb.GetLedgerRangeRequest{StartLedger: tt.start, EndLedger: tt.end})
+		if status.Code(err) != tt.want {
+			t.Errorf("[%d, %d): err = %v, want %v", tt.start, tt.end, err, tt.want)
+		}
+	}
+}
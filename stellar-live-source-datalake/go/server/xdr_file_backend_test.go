@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stellar/go-stellar-sdk/ingest/ledgerbackend"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	pb "github.com/withObsrvr/ttp-processor-demo/stellar-live-source-datalake/gen/raw_ledger_service"
+)
+
+const testPassphrase = "Test SDF Network ; September 2015"
+
+func testLedger(seq uint32) xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(seq)},
+			},
+		},
+	}
+}
+
+// writeXDRFile writes back-to-back LedgerCloseMeta records for first..last,
+// the layout `nebu fetch` produces.
+func writeXDRFile(t *testing.T, first, last uint32) string {
+	t.Helper()
+	var ledgers []xdr.LedgerCloseMeta
+	for seq := first; seq <= last; seq++ {
+		ledgers = append(ledgers, testLedger(seq))
+	}
+	return writeLedgers(t, ledgers)
+}
+
+func writeLedgers(t *testing.T, ledgers []xdr.LedgerCloseMeta) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ledgers.xdr")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for i := range ledgers {
+		if _, err := xdr.Marshal(f, &ledgers[i]); err != nil {
+			t.Fatalf("marshal ledger %d: %v", ledgers[i].LedgerSequence(), err)
+		}
+	}
+	return path
+}
+
+func newTestServer(config *LedgerBackendConfig) *RawLedgerServer {
+	if config.NetworkPassphrase == "" {
+		config.NetworkPassphrase = testPassphrase
+	}
+	return &RawLedgerServer{
+		logger:         zap.NewNop(),
+		metrics:        NewEnterpriseMetrics(),
+		circuitBreaker: NewCircuitBreaker(5, 30*time.Second),
+		config:         config,
+	}
+}
+
+// fakeLedgerStream records what the server sends
+type fakeLedgerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*pb.RawLedger
+}
+
+func (s *fakeLedgerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeLedgerStream) Send(ledger *pb.RawLedger) error {
+	s.sent = append(s.sent, ledger)
+	return nil
+}
+
+func (s *fakeLedgerStream) sequences() []uint32 {
+	seqs := make([]uint32, len(s.sent))
+	for i, l := range s.sent {
+		seqs[i] = l.Sequence
+	}
+	return seqs
+}
+
+func TestXDRFileBackendStreamsToEndLedger(t *testing.T) {
+	path := writeXDRFile(t, 100, 104)
+	backend, err := newXDRFileBackend(path)
+	if err != nil {
+		t.Fatalf("newXDRFileBackend: %v", err)
+	}
+	if backend.first != 100 || backend.last != 104 {
+		t.Fatalf("file range = [%d, %d], want [100, 104]", backend.first, backend.last)
+	}
+
+	ctx := context.Background()
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(100, 104)); err != nil {
+		t.Fatalf("PrepareRange: %v", err)
+	}
+
+	s := newTestServer(&LedgerBackendConfig{BackendType: "XDR", XDRFilePath: path})
+	stream := &fakeLedgerStream{ctx: ctx}
+	if err := s.streamLedgersFromBackend(ctx, backend, 100, 104, nil, stream); err != nil {
+		t.Fatalf("stream ended with %v, want clean EOF", err)
+	}
+
+	want := []uint32{100, 101, 102, 103, 104}
+	got := stream.sequences()
+	if len(got) != len(want) {
+		t.Fatalf("streamed %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("streamed %v, want %v", got, want)
+		}
+		var lcm xdr.LedgerCloseMeta
+		if err := lcm.UnmarshalBinary(stream.sent[i].LedgerCloseMetaXdr); err != nil || lcm.LedgerSequence() != want[i] {
+			t.Errorf("ledger %d: payload decodes to %d (%v)", want[i], lcm.LedgerSequence(), err)
+		}
+		if seq, err := decodeResumeToken(testPassphrase, stream.sent[i].ResumeToken); err != nil || seq != want[i] {
+			t.Errorf("ledger %d: resume token decodes to %d (%v)", want[i], seq, err)
+		}
+	}
+}
+
+func TestXDRFileBackendRejectsOutOfRange(t *testing.T) {
+	backend, err := newXDRFileBackend(writeXDRFile(t, 100, 104))
+	if err != nil {
+		t.Fatalf("newXDRFileBackend: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := backend.GetLedger(ctx, 100); err == nil {
+		t.Error("GetLedger before PrepareRange succeeded")
+	}
+	for _, r := range []ledgerbackend.Range{
+		ledgerbackend.BoundedRange(99, 104),
+		ledgerbackend.BoundedRange(100, 105),
+		ledgerbackend.UnboundedRange(105),
+	} {
+		if err := backend.PrepareRange(ctx, r); err == nil {
+			t.Errorf("PrepareRange(%v) succeeded", r)
+		}
+	}
+}
+
+func TestXDRFileBackendRejectsEmptyAndTruncatedFiles(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.xdr")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newXDRFileBackend(empty); err == nil || !strings.Contains(err.Error(), "no ledgers") {
+		t.Errorf("empty file: err = %v, want no ledgers", err)
+	}
+
+	path := writeXDRFile(t, 100, 101)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[:len(data)-3], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newXDRFileBackend(path); err == nil || !strings.Contains(err.Error(), "ledger 2") {
+		t.Errorf("truncated file: err = %v, want a decode error for ledger 2", err)
+	}
+}
@@ -3,7 +3,7 @@ module github.com/stellar/stellar-live-source
 go 1.25.0
 
 require (
-	github.com/stellar/go v0.0.0-20251210100531-aab2ea4aca88 // indirect; For xdr types if needed, and support/errors
+	github.com/stellar/go v0.0.0-20251210100531-aab2ea4aca88
 	github.com/stellar/stellar-rpc v0.9.6-0.20251007212330-3095aa4d2c52
 	go.uber.org/zap v1.27.1
 	google.golang.org/grpc v1.79.3
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+
+	rawledger "github.com/stellar/stellar-live-source/gen/raw_ledger_service"
+)
+
+// ledgerFilter decides which ledgers a filtered stream sends. A nil filter
+// sends every ledger; a filter without accounts or contracts sends every
+// ledger with a transaction.
+type ledgerFilter struct {
+	accounts  map[[32]byte]bool
+	contracts map[[32]byte]bool
+}
+
+// newLedgerFilter compiles a request's filter. It returns nil when the
+// filter is absent or empty.
+func newLedgerFilter(f *rawledger.LedgerFilter) (*ledgerFilter, error) {
+	if f == nil || (!f.SkipEmpty && len(f.AccountIds) == 0 && len(f.ContractIds) == 0) {
+		return nil, nil
+	}
+
+	lf := &ledgerFilter{
+		accounts:  make(map[[32]byte]bool, len(f.AccountIds)),
+		contracts: make(map[[32]byte]bool, len(f.ContractIds)),
+	}
+	for _, id := range f.AccountIds {
+		raw, err := strkey.Decode(strkey.VersionByteAccountID, id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account id %q in filter", id)
+		}
+		lf.accounts[[32]byte(raw)] = true
+	}
+	for _, id := range f.ContractIds {
+		raw, err := strkey.Decode(strkey.VersionByteContract, id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid contract id %q in filter", id)
+		}
+		lf.contracts[[32]byte(raw)] = true
+	}
+	return lf, nil
+}
+
+// matches reports whether a ledger should be sent. With accounts or
+// contracts set, a ledger is sent when any of its transactions, successful
+// or not, touches one of them:
+//
+//   - an account is touched when it is the transaction source, the fee-bump
+//     source or an operation source, or when the transaction changes its
+//     account entry or one of its trustlines;
+//   - a contract is touched when an operation invokes it, when it emits an
+//     event, or when the transaction changes its contract data.
+func (f *ledgerFilter) matches(lcm xdr.LedgerCloseMeta) bool {
+	if f == nil {
+		return true
+	}
+	count := lcm.CountTransactions()
+	if len(f.accounts) == 0 && len(f.contracts) == 0 {
+		return count > 0
+	}
+
+	// Envelopes are in apply order only for V0 ledgers, so envelopes and
+	// metas are checked separately; any hit sends the whole ledger.
+	for _, env := range lcm.TransactionEnvelopes() {
+		if f.touchesEnvelope(env) {
+			return true
+		}
+	}
+	for i := 0; i < count; i++ {
+		if f.touchesMeta(lcm.TxApplyProcessing(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *ledgerFilter) touchesEnvelope(env xdr.TransactionEnvelope) bool {
+	if f.hasAccount(env.SourceAccount().ToAccountId()) {
+		return true
+	}
+	if env.IsFeeBump() && f.hasAccount(env.FeeBumpAccount().ToAccountId()) {
+		return true
+	}
+	for _, op := range env.Operations() {
+		if op.SourceAccount != nil && f.hasAccount(op.SourceAccount.ToAccountId()) {
+			return true
+		}
+		invoke, ok := op.Body.GetInvokeHostFunctionOp()
+		if !ok {
+			continue
+		}
+		if args, ok := invoke.HostFunction.GetInvokeContract(); ok && f.hasAddress(args.ContractAddress) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *ledgerFilter) touchesMeta(meta xdr.TransactionMeta) bool {
+	var changes []xdr.LedgerEntryChanges
+	var events []xdr.ContractEvent
+	switch meta.V {
+	case 0:
+		if meta.Operations != nil {
+			for _, op := range *meta.Operations {
+				changes = append(changes, op.Changes)
+			}
+		}
+	case 1:
+		changes = append(changes, meta.V1.TxChanges)
+		for _, op := range meta.V1.Operations {
+			changes = append(changes, op.Changes)
+		}
+	case 2:
+		changes = append(changes, meta.V2.TxChangesBefore, meta.V2.TxChangesAfter)
+		for _, op := range meta.V2.Operations {
+			changes = append(changes, op.Changes)
+		}
+	case 3:
+		changes = append(changes, meta.V3.TxChangesBefore, meta.V3.TxChangesAfter)
+		for _, op := range meta.V3.Operations {
+			changes = append(changes, op.Changes)
+		}
+		if meta.V3.SorobanMeta != nil {
+			events = append(events, meta.V3.SorobanMeta.Events...)
+		}
+	case 4:
+		changes = append(changes, meta.V4.TxChangesBefore, meta.V4.TxChangesAfter)
+		for _, op := range meta.V4.Operations {
+			changes = append(changes, op.Changes)
+			events = append(events, op.Events...)
+		}
+		for _, ev := range meta.V4.Events {
+			events = append(events, ev.Event)
+		}
+	}
+
+	for _, ev := range events {
+		if ev.ContractId != nil && f.contracts[[32]byte(*ev.ContractId)] {
+			return true
+		}
+	}
+	for _, group := range changes {
+		for i := range group {
+			key, err := group[i].LedgerKey()
+			if err != nil {
+				continue
+			}
+			if f.touchesKey(key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *ledgerFilter) touchesKey(key xdr.LedgerKey) bool {
+	switch key.Type {
+	case xdr.LedgerEntryTypeAccount:
+		return f.hasAccount(key.Account.AccountId)
+	case xdr.LedgerEntryTypeTrustline:
+		return f.hasAccount(key.TrustLine.AccountId)
+	case xdr.LedgerEntryTypeContractData:
+		return f.hasAddress(key.ContractData.Contract)
+	}
+	return false
+}
+
+func (f *ledgerFilter) hasAccount(id xdr.AccountId) bool {
+	return id.Ed25519 != nil && f.accounts[[32]byte(*id.Ed25519)]
+}
+
+func (f *ledgerFilter) hasAddress(addr xdr.ScAddress) bool {
+	switch addr.Type {
+	case xdr.ScAddressTypeScAddressTypeAccount:
+		return addr.AccountId != nil && f.hasAccount(*addr.AccountId)
+	case xdr.ScAddressTypeScAddressTypeContract:
+		return addr.ContractId != nil && f.contracts[[32]byte(*addr.ContractId)]
+	}
+	return false
+}
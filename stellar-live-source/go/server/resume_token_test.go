@@ -0,0 +1,31 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResumeTokenRoundTrip(t *testing.T) {
+	const testnet = "Test SDF Network ; September 2015"
+	token := encodeResumeToken(testnet, 123456)
+
+	seq, err := decodeResumeToken(testnet, token)
+	if err != nil {
+		t.Fatalf("decodeResumeToken: %v", err)
+	}
+	if seq != 123456 {
+		t.Fatalf("sequence = %d, want 123456", seq)
+	}
+}
+
+func TestResumeTokenRejectsOtherNetworkAndGarbage(t *testing.T) {
+	token := encodeResumeToken("Test SDF Network ; September 2015", 10)
+	if _, err := decodeResumeToken("Public Global Stellar Network ; September 2015", token); err == nil || !strings.Contains(err.Error(), "different network") {
+		t.Fatalf("err = %v, want a different network error", err)
+	}
+	for _, bad := range []string{"", "!!", "djE6eA", "djI6eDox"} {
+		if _, err := decodeResumeToken("Test SDF Network ; September 2015", bad); err == nil {
+			t.Errorf("decodeResumeToken(%q) succeeded", bad)
+		}
+	}
+}
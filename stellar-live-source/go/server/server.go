@@ -13,6 +13,7 @@ import (
 	// Import the generated protobuf code package
 	rawledger "github.com/stellar/stellar-live-source/gen/raw_ledger_service"
 
+	"github.com/stellar/go/xdr"
 	"github.com/stellar/stellar-rpc/client"
 	"github.com/stellar/stellar-rpc/protocol"
 	"go.uber.org/zap"
@@ -258,7 +259,7 @@ func (s *RawLedgerServer) fetchLedgerForCache(sequence uint32) ([]byte, string,
 	defer cancel()
 
 	// Build request with historical options
-	getLedgersReq := s.buildGetLedgersRequest(sequence)
+	getLedgersReq := s.buildGetLedgersRequest(sequence, 0)
 
 	resp, err := s.rpcClient.GetLedgers(ctx, getLedgersReq)
 	if err != nil {
@@ -289,12 +290,17 @@ func (s *RawLedgerServer) fetchLedgerForCache(sequence uint32) ([]byte, string,
 	return decodedBytes, source, nil
 }
 
-// buildGetLedgersRequest builds a GetLedgers request
-func (s *RawLedgerServer) buildGetLedgersRequest(startLedger uint32) protocol.GetLedgersRequest {
+// buildGetLedgersRequest builds a GetLedgers request. A non-zero batchSizeHint
+// lowers the page size below the configured BatchSize.
+func (s *RawLedgerServer) buildGetLedgersRequest(startLedger, batchSizeHint uint32) protocol.GetLedgersRequest {
+	limit := uint(s.config.BatchSize)
+	if batchSizeHint > 0 && uint(batchSizeHint) < limit {
+		limit = uint(batchSizeHint)
+	}
 	req := protocol.GetLedgersRequest{
 		StartLedger: startLedger,
 		Pagination: &protocol.LedgerPaginationOptions{
-			Limit: uint(s.config.BatchSize),
+			Limit: limit,
 		},
 	}
 
@@ -310,9 +316,29 @@ func (s *RawLedgerServer) StreamRawLedgers(req *rawledger.StreamLedgersRequest,
 	ctx := stream.Context()
 	s.logger.Info("Starting enterprise ledger stream",
 		zap.Uint32("start_sequence", req.StartLedger),
+		zap.Uint32("end_ledger", req.EndLedger),
+		zap.Uint32("batch_size_hint", req.BatchSizeHint),
+		zap.Bool("resuming", req.ResumeToken != ""),
+		zap.Bool("filtered", req.Filter != nil),
 		zap.Duration("max_latency_p99", MaxLatencyP99),
 	)
 
+	startLedger := req.StartLedger
+	if req.ResumeToken != "" {
+		lastDelivered, err := decodeResumeToken(s.config.NetworkPassphrase, req.ResumeToken)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		startLedger = lastDelivered + 1
+	}
+	if req.EndLedger != 0 && req.EndLedger < startLedger {
+		return status.Errorf(codes.OutOfRange, "start ledger %d is after end ledger %d", startLedger, req.EndLedger)
+	}
+	filter, err := newLedgerFilter(req.Filter)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// Initialize retry state with enterprise-grade backoff
 	retryCount := 0
 	backoff := s.config.InitialBackoff
@@ -322,7 +348,7 @@ func (s *RawLedgerServer) StreamRawLedgers(req *rawledger.StreamLedgersRequest,
 	lastProcessedSeq := uint32(0)
 
 	// Create initial GetLedgers request using our enhanced builder
-	getLedgersReq := s.buildGetLedgersRequest(req.StartLedger)
+	getLedgersReq := s.buildGetLedgersRequest(startLedger, req.BatchSizeHint)
 
 	// --- Enterprise-Grade Continuous Polling Loop ---
 	for {
@@ -400,13 +426,16 @@ func (s *RawLedgerServer) StreamRawLedgers(req *rawledger.StreamLedgersRequest,
 
 		// Process each ledger with enterprise-grade error handling
 		for _, ledgerInfo := range resp.Ledgers {
-			if ledgerInfo.Sequence < req.StartLedger {
+			if ledgerInfo.Sequence < startLedger {
 				s.logger.Debug("Skipping historical ledger",
 					zap.Uint32("sequence", ledgerInfo.Sequence),
-					zap.Uint32("start_ledger", req.StartLedger),
+					zap.Uint32("start_ledger", startLedger),
 				)
 				continue
 			}
+			if req.EndLedger != 0 && ledgerInfo.Sequence > req.EndLedger {
+				break
+			}
 
 			// Check cache first if enabled
 			var rawXdrBytes []byte
@@ -464,10 +493,24 @@ func (s *RawLedgerServer) StreamRawLedgers(req *rawledger.StreamLedgersRequest,
 				}
 			}
 
+			// Filtering needs the decoded ledger; unfiltered streams skip the decode
+			if filter != nil {
+				var lcm xdr.LedgerCloseMeta
+				if err := lcm.UnmarshalBinary(rawXdrBytes); err != nil {
+					return status.Errorf(codes.Internal, "failed to decode ledger %d for filtering: %v", ledgerInfo.Sequence, err)
+				}
+				if !filter.matches(lcm) {
+					// Skipped ledgers still count toward end_ledger
+					lastProcessedSeq = ledgerInfo.Sequence
+					continue
+				}
+			}
+
 			// Create and send message with enterprise-grade metrics
 			rawLedgerMsg := &rawledger.RawLedger{
 				Sequence:           ledgerInfo.Sequence,
 				LedgerCloseMetaXdr: rawXdrBytes,
+				ResumeToken:        encodeResumeToken(s.config.NetworkPassphrase, ledgerInfo.Sequence),
 			}
 
 			// Update metrics with data source information
@@ -503,6 +546,16 @@ func (s *RawLedgerServer) StreamRawLedgers(req *rawledger.StreamLedgersRequest,
 			lastProcessedSeq = ledgerInfo.Sequence
 		}
 
+		if req.EndLedger != 0 && lastProcessedSeq >= req.EndLedger {
+			if s.config.CheckpointPath != "" {
+				s.SaveCheckpointAsync(lastProcessedSeq)
+			}
+			s.logger.Info("Reached end ledger, closing stream",
+				zap.Uint32("end_ledger", req.EndLedger),
+			)
+			return nil
+		}
+
 		// Save checkpoint asynchronously at configured interval
 		if s.config.CheckpointPath != "" && lastProcessedSeq > 0 && time.Since(lastCheckpointTime) >= s.config.CheckpointInterval {
 			s.SaveCheckpointAsync(lastProcessedSeq)
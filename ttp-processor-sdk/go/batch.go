@@ -266,12 +266,7 @@ func (bp *BatchProcessor) processBatch(ctx context.Context, batch []*stellarv1.R
 			bp.metrics.RecordEventExtracted(eventType, bp.networkName, true)
 
 			// Apply filter
-			if !bp.filterConfig.ShouldIncludeEvent(ttpEvent, bp.logger) {
-				// Determine filter reason
-				filterReason := "event_type"
-				if bp.filterConfig.MinAmount != nil && getEventAmount(ttpEvent) < *bp.filterConfig.MinAmount {
-					filterReason = "min_amount"
-				}
+			if filterReason := bp.filterConfig.ExclusionReason(ttpEvent, bp.logger); filterReason != "" {
 				bp.metrics.RecordEventFiltered(eventType, filterReason, bp.networkName)
 				batchStats.EventsFiltered++
 				continue
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"math/big"
 	"strings"
 
 	"go.uber.org/zap"
@@ -156,15 +157,64 @@ func (cv *ConfigValidator) ValidateFilterConfig(filterConfig *FilterConfig) {
 		)
 	}
 
-	// Warn if filter might be too aggressive
-	if filterConfig.MinAmount != nil && *filterConfig.MinAmount > 1000000000000 {
+	// Values that did not parse were left out of the filter, which would let
+	// through events the operator meant to drop
+	for _, loadErr := range filterConfig.loadErrors {
+		cv.AddIssue(
+			SeverityFatal,
+			loadErr.Field,
+			fmt.Sprintf("Invalid value '%s': %v", loadErr.Value, loadErr.Err),
+			filterFieldHint(loadErr.Field),
+		)
+	}
+
+	if filterConfig.MinAmount != nil && filterConfig.MaxAmount != nil && filterConfig.MinAmount.Cmp(filterConfig.MaxAmount) > 0 {
+		cv.AddIssue(
+			SeverityError,
+			"FILTER_MIN_AMOUNT / FILTER_MAX_AMOUNT",
+			"Minimum amount is greater than maximum amount",
+			"No events will be emitted. Lower FILTER_MIN_AMOUNT or raise FILTER_MAX_AMOUNT.",
+		)
+	}
+
+	// Warn if filter might be too aggressive. Thresholds are in the asset's
+	// smallest unit, so this is only a hint for classic (7 decimal) assets.
+	if filterConfig.MinAmount != nil && filterConfig.MinAmount.Cmp(big.NewInt(1000000000000)) > 0 {
+		xlm := new(big.Rat).SetFrac(filterConfig.MinAmount, big.NewInt(10000000))
 		cv.AddIssue(
 			SeverityWarning,
 			"FILTER_MIN_AMOUNT",
 			"Very high minimum amount threshold",
-			fmt.Sprintf("Filter set to %d stroops (%.2f XLM). Most events may be filtered out.", *filterConfig.MinAmount, float64(*filterConfig.MinAmount)/10000000.0),
+			fmt.Sprintf("Filter set to %s stroops (%s XLM). Most classic asset events may be filtered out; Soroban tokens often use more decimals.", filterConfig.MinAmount, xlm.FloatString(2)),
 		)
 	}
+
+	if len(filterConfig.Assets) > 0 && len(filterConfig.ContractAddresses) > 0 {
+		cv.AddIssue(
+			SeverityWarning,
+			"FILTER_ASSETS / FILTER_CONTRACT_IDS",
+			"Asset and contract filters are both set",
+			"Custom Soroban tokens have no asset, so FILTER_ASSETS drops them even when their contract is listed. Use one or the other, or combine them in FILTER_EXPRESSION with 'or'.",
+		)
+	}
+}
+
+// filterFieldHint explains the expected format of a filter setting
+func filterFieldHint(field string) string {
+	switch field {
+	case "FILTER_MIN_AMOUNT", "FILTER_MAX_AMOUNT":
+		return "Use an integer in the asset's smallest unit, for example 10000000 for 1 XLM. Values beyond int64 are fine."
+	case "FILTER_ACCOUNTS", "FILTER_FROM_ACCOUNTS", "FILTER_TO_ACCOUNTS":
+		return "Use comma-separated G, M (muxed) or C (contract) addresses."
+	case "FILTER_ASSETS":
+		return "Use comma-separated 'native', 'CODE' or 'CODE:ISSUER' values."
+	case "FILTER_EXPRESSION":
+		return "Combine field=value predicates with and, or, not and parentheses, e.g. type=transfer and amount>=10000000. Fields: type, from, to, account, asset, contract, amount."
+	case "FILTER_FILE":
+		return "FILTER_FILE must be a readable JSON object using the keys include_event_types, exclude_event_types, contract_ids, accounts, from_accounts, to_accounts, assets, min_amount, max_amount and expression."
+	default:
+		return "Check the filter configuration."
+	}
 }
 
 // ValidateBatchConfig validates batch processing configuration
@@ -252,7 +302,8 @@ func (cv *ConfigValidator) ValidateConfiguration(
 		if len(filterConfig.ExcludeEventTypes) > 0 {
 			expectedReduction += 20 // Rough estimate
 		}
-		if filterConfig.MinAmount != nil {
+		if filterConfig.MinAmount != nil || len(filterConfig.Accounts) > 0 ||
+			len(filterConfig.FromAccounts) > 0 || len(filterConfig.ToAccounts) > 0 {
 			expectedReduction += 50
 		}
 
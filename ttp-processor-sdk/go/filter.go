@@ -1,10 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
 	"strconv"
 	"strings"
 
+	"github.com/stellar/go-stellar-sdk/asset"
 	"github.com/stellar/go-stellar-sdk/processors/token_transfer"
+	"github.com/stellar/go-stellar-sdk/strkey"
 	"go.uber.org/zap"
 )
 
@@ -16,56 +23,225 @@ type FilterConfig struct {
 	// Event types to exclude (takes precedence over include)
 	ExcludeEventTypes []string
 
-	// Minimum amount threshold (in stroops)
-	MinAmount *int64
+	// Amount bounds, inclusive, in the asset's smallest unit (stroops for
+	// classic assets). Soroban token amounts are i128, so these are big ints.
+	MinAmount *big.Int
+	MaxAmount *big.Int
 
 	// Contract addresses to filter by (for Soroban events)
 	ContractAddresses []string
 
+	// Accounts match either side of an event, FromAccounts and ToAccounts
+	// only one. G, M (muxed) and C (contract) addresses are accepted.
+	Accounts     []string
+	FromAccounts []string
+	ToAccounts   []string
+
+	// Assets to include: "native", "CODE" (any issuer) or "CODE:ISSUER".
+	// Custom Soroban tokens carry no asset; filter them by contract instead.
+	Assets []string
+
+	// Expression is an optional boolean filter that must match as well, e.g.
+	// `type=transfer and (from=GABC... or amount>=1000000000)`
+	Expression string
+
 	// Whether filtering is enabled
 	Enabled bool
+
+	accounts     []accountMatcher
+	fromAccounts []accountMatcher
+	toAccounts   []accountMatcher
+	assets       []assetMatcher
+	expr         filterExpr
+
+	// loadErrors holds values that could not be parsed. ValidateFilterConfig
+	// reports them, so a typo cannot silently widen the filter.
+	loadErrors []filterLoadError
+}
+
+type filterLoadError struct {
+	Field string
+	Value string
+	Err   error
+}
+
+// filterFile is the JSON document FILTER_FILE points at. Every key is
+// optional, and an environment variable that is set overrides its key.
+// Amounts are strings because i128 values do not fit a JSON number.
+type filterFile struct {
+	IncludeEventTypes []string `json:"include_event_types"`
+	ExcludeEventTypes []string `json:"exclude_event_types"`
+	ContractIDs       []string `json:"contract_ids"`
+	Accounts          []string `json:"accounts"`
+	FromAccounts      []string `json:"from_accounts"`
+	ToAccounts        []string `json:"to_accounts"`
+	Assets            []string `json:"assets"`
+	MinAmount         string   `json:"min_amount"`
+	MaxAmount         string   `json:"max_amount"`
+	Expression        string   `json:"expression"`
 }
 
-// LoadFilterConfig loads filtering configuration from environment variables
+// LoadFilterConfig loads filtering configuration from FILTER_FILE and
+// environment variables
 func LoadFilterConfig(logger *zap.Logger) *FilterConfig {
+	var raw filterFile
+	var fileErr error
+	filePath := getEnv("FILTER_FILE", "")
+	if filePath != "" {
+		raw, fileErr = readFilterFile(filePath)
+	}
+
+	overrideCSV(&raw.IncludeEventTypes, "FILTER_EVENT_TYPES")
+	overrideCSV(&raw.ExcludeEventTypes, "EXCLUDE_EVENT_TYPES")
+	overrideCSV(&raw.ContractIDs, "FILTER_CONTRACT_IDS")
+	overrideCSV(&raw.Accounts, "FILTER_ACCOUNTS")
+	overrideCSV(&raw.FromAccounts, "FILTER_FROM_ACCOUNTS")
+	overrideCSV(&raw.ToAccounts, "FILTER_TO_ACCOUNTS")
+	overrideCSV(&raw.Assets, "FILTER_ASSETS")
+	overrideString(&raw.MinAmount, "FILTER_MIN_AMOUNT")
+	overrideString(&raw.MaxAmount, "FILTER_MAX_AMOUNT")
+	overrideString(&raw.Expression, "FILTER_EXPRESSION")
+
+	config := newFilterConfig(raw)
+	if fileErr != nil {
+		config.loadErrors = append(config.loadErrors, filterLoadError{Field: "FILTER_FILE", Value: filePath, Err: fileErr})
+	}
+
+	if config.Enabled {
+		logger.Info("Event filtering enabled",
+			zap.String("filter_file", filePath),
+			zap.Strings("include_types", config.IncludeEventTypes),
+			zap.Strings("exclude_types", config.ExcludeEventTypes),
+			zap.Stringer("min_amount", config.MinAmount),
+			zap.Stringer("max_amount", config.MaxAmount),
+			zap.Strings("contract_ids", config.ContractAddresses),
+			zap.Strings("accounts", config.Accounts),
+			zap.Strings("from_accounts", config.FromAccounts),
+			zap.Strings("to_accounts", config.ToAccounts),
+			zap.Strings("assets", config.Assets),
+			zap.String("expression", config.Expression))
+	}
+
+	return config
+}
+
+func readFilterFile(path string) (filterFile, error) {
+	var raw filterFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return raw, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&raw); err != nil {
+		return filterFile{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return raw, nil
+}
+
+func overrideCSV(dst *[]string, key string) {
+	if values := parseCSV(getEnv(key, "")); values != nil {
+		*dst = values
+	}
+}
+
+func overrideString(dst *string, key string) {
+	if value := strings.TrimSpace(getEnv(key, "")); value != "" {
+		*dst = value
+	}
+}
+
+// newFilterConfig parses raw filter settings. Values that do not parse are
+// recorded in loadErrors and left out of the filter.
+func newFilterConfig(raw filterFile) *FilterConfig {
 	config := &FilterConfig{
-		IncludeEventTypes: parseCSV(getEnv("FILTER_EVENT_TYPES", "")),
-		ExcludeEventTypes: parseCSV(getEnv("EXCLUDE_EVENT_TYPES", "")),
-		ContractAddresses: parseCSV(getEnv("FILTER_CONTRACT_IDS", "")),
+		IncludeEventTypes: raw.IncludeEventTypes,
+		ExcludeEventTypes: raw.ExcludeEventTypes,
+		ContractAddresses: raw.ContractIDs,
+		Accounts:          raw.Accounts,
+		FromAccounts:      raw.FromAccounts,
+		ToAccounts:        raw.ToAccounts,
+		Assets:            raw.Assets,
+		Expression:        strings.TrimSpace(raw.Expression),
+	}
+	fail := func(field, value string, err error) {
+		config.loadErrors = append(config.loadErrors, filterLoadError{Field: field, Value: value, Err: err})
 	}
 
-	// Parse minimum amount
-	if minAmtStr := getEnv("FILTER_MIN_AMOUNT", ""); minAmtStr != "" {
-		if amt, err := strconv.ParseInt(minAmtStr, 10, 64); err == nil {
-			config.MinAmount = &amt
+	if raw.MinAmount != "" {
+		if amt, err := parseAmount(raw.MinAmount); err == nil {
+			config.MinAmount = amt
 		} else {
-			logger.Warn("Invalid FILTER_MIN_AMOUNT, ignoring",
-				zap.String("value", minAmtStr),
-				zap.Error(err))
+			fail("FILTER_MIN_AMOUNT", raw.MinAmount, err)
+		}
+	}
+	if raw.MaxAmount != "" {
+		if amt, err := parseAmount(raw.MaxAmount); err == nil {
+			config.MaxAmount = amt
+		} else {
+			fail("FILTER_MAX_AMOUNT", raw.MaxAmount, err)
+		}
+	}
+
+	parseAccounts := func(field string, values []string) []accountMatcher {
+		var matchers []accountMatcher
+		for _, v := range values {
+			m, err := parseAccount(v)
+			if err != nil {
+				fail(field, v, err)
+				continue
+			}
+			matchers = append(matchers, m)
+		}
+		return matchers
+	}
+	config.accounts = parseAccounts("FILTER_ACCOUNTS", config.Accounts)
+	config.fromAccounts = parseAccounts("FILTER_FROM_ACCOUNTS", config.FromAccounts)
+	config.toAccounts = parseAccounts("FILTER_TO_ACCOUNTS", config.ToAccounts)
+
+	for _, v := range config.Assets {
+		m, err := parseAsset(v)
+		if err != nil {
+			fail("FILTER_ASSETS", v, err)
+			continue
+		}
+		config.assets = append(config.assets, m)
+	}
+
+	if config.Expression != "" {
+		expr, err := parseFilterExpression(config.Expression)
+		if err != nil {
+			fail("FILTER_EXPRESSION", config.Expression, err)
+		} else {
+			config.expr = expr
 		}
 	}
 
 	// Determine if filtering is enabled
 	config.Enabled = len(config.IncludeEventTypes) > 0 ||
 		len(config.ExcludeEventTypes) > 0 ||
-		config.MinAmount != nil ||
-		len(config.ContractAddresses) > 0
-
-	if config.Enabled {
-		logger.Info("Event filtering enabled",
-			zap.Strings("include_types", config.IncludeEventTypes),
-			zap.Strings("exclude_types", config.ExcludeEventTypes),
-			zap.Int64p("min_amount", config.MinAmount),
-			zap.Strings("contract_ids", config.ContractAddresses))
-	}
+		raw.MinAmount != "" ||
+		raw.MaxAmount != "" ||
+		len(config.ContractAddresses) > 0 ||
+		len(config.Accounts) > 0 ||
+		len(config.FromAccounts) > 0 ||
+		len(config.ToAccounts) > 0 ||
+		len(config.Assets) > 0 ||
+		config.Expression != ""
 
 	return config
 }
 
 // ShouldIncludeEvent determines if an event should be included based on filters
 func (f *FilterConfig) ShouldIncludeEvent(event *token_transfer.TokenTransferEvent, logger *zap.Logger) bool {
+	return f.ExclusionReason(event, logger) == ""
+}
+
+// ExclusionReason returns the name of the filter that rejects event, or ""
+// when the event passes. The name is used as the filtered-events metric label.
+func (f *FilterConfig) ExclusionReason(event *token_transfer.TokenTransferEvent, logger *zap.Logger) string {
 	if !f.Enabled {
-		return true
+		return ""
 	}
 
 	eventType := getEventType(event)
@@ -77,7 +253,7 @@ func (f *FilterConfig) ShouldIncludeEvent(event *token_transfer.TokenTransferEve
 				logger.Debug("Event excluded by type",
 					zap.String("event_type", eventType),
 					zap.String("ledger", strconv.FormatUint(uint64(event.Meta.LedgerSequence), 10)))
-				return false
+				return "event_type"
 			}
 		}
 	}
@@ -94,19 +270,26 @@ func (f *FilterConfig) ShouldIncludeEvent(event *token_transfer.TokenTransferEve
 		if !found {
 			logger.Debug("Event not in include list",
 				zap.String("event_type", eventType))
-			return false
+			return "event_type"
 		}
 	}
 
-	// Check minimum amount
-	if f.MinAmount != nil {
-		amount := getEventAmount(event)
-		if amount < *f.MinAmount {
+	// Check amount bounds. An amount that does not parse fails both.
+	if f.MinAmount != nil || f.MaxAmount != nil {
+		amount, ok := getEventAmount(event)
+		if !ok || (f.MinAmount != nil && amount.Cmp(f.MinAmount) < 0) {
 			logger.Debug("Event below minimum amount",
 				zap.String("event_type", eventType),
-				zap.Int64("amount", amount),
-				zap.Int64("min_amount", *f.MinAmount))
-			return false
+				zap.Stringer("amount", amount),
+				zap.Stringer("min_amount", f.MinAmount))
+			return "min_amount"
+		}
+		if f.MaxAmount != nil && amount.Cmp(f.MaxAmount) > 0 {
+			logger.Debug("Event above maximum amount",
+				zap.String("event_type", eventType),
+				zap.Stringer("amount", amount),
+				zap.Stringer("max_amount", f.MaxAmount))
+			return "max_amount"
 		}
 	}
 
@@ -122,11 +305,52 @@ func (f *FilterConfig) ShouldIncludeEvent(event *token_transfer.TokenTransferEve
 		if !found {
 			logger.Debug("Contract address not in filter list",
 				zap.String("contract_address", event.Meta.ContractAddress))
-			return false
+			return "contract_address"
 		}
 	}
 
-	return true
+	// Check accounts
+	from, to := getEventFrom(event), getEventTo(event)
+	toMuxed := event.GetMeta().GetToMuxedInfo()
+	if len(f.accounts) > 0 &&
+		!matchAnyAccount(f.accounts, from, nil) && !matchAnyAccount(f.accounts, to, toMuxed) {
+		logger.Debug("Event accounts not in filter list",
+			zap.String("from", from), zap.String("to", to))
+		return "account"
+	}
+	if len(f.fromAccounts) > 0 && !matchAnyAccount(f.fromAccounts, from, nil) {
+		logger.Debug("Event sender not in filter list", zap.String("from", from))
+		return "account"
+	}
+	if len(f.toAccounts) > 0 && !matchAnyAccount(f.toAccounts, to, toMuxed) {
+		logger.Debug("Event recipient not in filter list", zap.String("to", to))
+		return "account"
+	}
+
+	// Check assets
+	if len(f.assets) > 0 {
+		eventAsset := getEventAsset(event)
+		found := false
+		for _, m := range f.assets {
+			if m.matches(eventAsset) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			logger.Debug("Event asset not in filter list",
+				zap.String("event_type", eventType))
+			return "asset"
+		}
+	}
+
+	if f.expr != nil && !f.expr.eval(event) {
+		logger.Debug("Event rejected by filter expression",
+			zap.String("event_type", eventType))
+		return "expression"
+	}
+
+	return ""
 }
 
 // getEventType returns the string type of the event
@@ -147,8 +371,9 @@ func getEventType(event *token_transfer.TokenTransferEvent) string {
 	}
 }
 
-// getEventAmount returns the amount from the event
-func getEventAmount(event *token_transfer.TokenTransferEvent) int64 {
+// getEventAmount returns the amount from the event. Amounts are i128 decimal
+// strings; ok is false if the event has none or it does not parse.
+func getEventAmount(event *token_transfer.TokenTransferEvent) (amount *big.Int, ok bool) {
 	var amountStr string
 	switch evt := event.Event.(type) {
 	case *token_transfer.TokenTransferEvent_Transfer:
@@ -162,15 +387,169 @@ func getEventAmount(event *token_transfer.TokenTransferEvent) int64 {
 	case *token_transfer.TokenTransferEvent_Fee:
 		amountStr = evt.Fee.Amount
 	default:
-		return 0
+		return nil, false
 	}
 
-	// Parse amount string to int64
-	amount, err := strconv.ParseInt(amountStr, 10, 64)
-	if err != nil {
-		return 0
+	return new(big.Int).SetString(amountStr, 10)
+}
+
+// getEventFrom returns the sending address, "" for mints
+func getEventFrom(event *token_transfer.TokenTransferEvent) string {
+	switch evt := event.Event.(type) {
+	case *token_transfer.TokenTransferEvent_Transfer:
+		return evt.Transfer.From
+	case *token_transfer.TokenTransferEvent_Burn:
+		return evt.Burn.From
+	case *token_transfer.TokenTransferEvent_Clawback:
+		return evt.Clawback.From
+	case *token_transfer.TokenTransferEvent_Fee:
+		return evt.Fee.From
+	default:
+		return ""
+	}
+}
+
+// getEventTo returns the receiving address, "" for events without one
+func getEventTo(event *token_transfer.TokenTransferEvent) string {
+	switch evt := event.Event.(type) {
+	case *token_transfer.TokenTransferEvent_Transfer:
+		return evt.Transfer.To
+	case *token_transfer.TokenTransferEvent_Mint:
+		return evt.Mint.To
+	default:
+		return ""
+	}
+}
+
+// getEventAsset returns the classic asset of the event, nil for custom tokens
+func getEventAsset(event *token_transfer.TokenTransferEvent) *asset.Asset {
+	switch evt := event.Event.(type) {
+	case *token_transfer.TokenTransferEvent_Transfer:
+		return evt.Transfer.Asset
+	case *token_transfer.TokenTransferEvent_Mint:
+		return evt.Mint.Asset
+	case *token_transfer.TokenTransferEvent_Burn:
+		return evt.Burn.Asset
+	case *token_transfer.TokenTransferEvent_Clawback:
+		return evt.Clawback.Asset
+	case *token_transfer.TokenTransferEvent_Fee:
+		return evt.Fee.Asset
+	default:
+		return nil
+	}
+}
+
+// parseAmount parses an integer amount in the asset's smallest unit
+func parseAmount(s string) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+	if !ok {
+		return nil, fmt.Errorf("amount must be an integer in the asset's smallest unit")
+	}
+	return amount, nil
+}
+
+// accountMatcher matches one configured address. G and C addresses match
+// exactly. Events carry the base G account of a muxed sender or recipient
+// and record only the recipient's muxed ID, in ToMuxedInfo. An M address
+// therefore matches a recipient with that base account and muxed ID, and
+// never a sender: senders can only be matched by their base G account.
+type accountMatcher struct {
+	address string
+	base    string
+	muxedID *uint64
+}
+
+func parseAccount(s string) (accountMatcher, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strkey.IsValidEd25519PublicKey(s), strkey.IsValidContractAddress(s):
+		return accountMatcher{address: s, base: s}, nil
+	case strkey.IsValidMuxedAccountEd25519PublicKey(s):
+		muxed, err := strkey.DecodeMuxedAccount(s)
+		if err != nil {
+			return accountMatcher{}, err
+		}
+		base, err := muxed.AccountID()
+		if err != nil {
+			return accountMatcher{}, err
+		}
+		id := muxed.ID()
+		return accountMatcher{address: s, base: base, muxedID: &id}, nil
+	default:
+		return accountMatcher{}, fmt.Errorf("not a G, M or C address")
+	}
+}
+
+// matches reports whether addr is this account. toMuxed is the event's
+// ToMuxedInfo when addr is the recipient, nil otherwise.
+func (m accountMatcher) matches(addr string, toMuxed *token_transfer.MuxedInfo) bool {
+	if addr == "" {
+		return false
+	}
+	if addr == m.address {
+		return true
+	}
+	if addr != m.base {
+		return false
+	}
+	if m.muxedID == nil {
+		return true
+	}
+	if toMuxed == nil {
+		return false
+	}
+	id, isID := toMuxed.Content.(*token_transfer.MuxedInfo_Id)
+	return isID && id.Id == *m.muxedID
+}
+
+func matchAnyAccount(matchers []accountMatcher, addr string, toMuxed *token_transfer.MuxedInfo) bool {
+	for _, m := range matchers {
+		if m.matches(addr, toMuxed) {
+			return true
+		}
+	}
+	return false
+}
+
+// assetMatcher matches "native", "CODE" (any issuer) or "CODE:ISSUER"
+type assetMatcher struct {
+	native bool
+	code   string
+	issuer string
+}
+
+func parseAsset(s string) (assetMatcher, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "native") {
+		return assetMatcher{native: true}, nil
+	}
+	code, issuer, hasIssuer := strings.Cut(s, ":")
+	if len(code) < 1 || len(code) > 12 {
+		return assetMatcher{}, fmt.Errorf("asset code must be 1-12 characters")
+	}
+	for _, c := range code {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return assetMatcher{}, fmt.Errorf("asset code must be alphanumeric")
+		}
+	}
+	if hasIssuer && !strkey.IsValidEd25519PublicKey(issuer) {
+		return assetMatcher{}, fmt.Errorf("asset issuer must be a G address")
+	}
+	return assetMatcher{code: code, issuer: issuer}, nil
+}
+
+func (m assetMatcher) matches(a *asset.Asset) bool {
+	if a == nil {
+		return false
+	}
+	if m.native {
+		return a.GetNative()
+	}
+	issued := a.GetIssuedAsset()
+	if issued == nil || issued.GetAssetCode() != m.code {
+		return false
 	}
-	return amount
+	return m.issuer == "" || issued.GetIssuer() == m.issuer
 }
 
 // parseCSV parses a comma-separated string into a slice
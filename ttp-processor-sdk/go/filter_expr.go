@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/stellar/go-stellar-sdk/processors/token_transfer"
+)
+
+// FILTER_EXPRESSION combines predicates with and, or, not and parentheses:
+//
+//	type=transfer and (to=GABC... or asset=USDC:GA5Z...) and amount>=10000000
+//
+// Fields:
+//
+//	type      transfer, mint, burn, clawback or fee          = !=
+//	from      sending address (G or C)                       = !=
+//	to        receiving address (G, M or C)                  = !=
+//	account   either the sender or the receiver              = !=
+//	asset     native, CODE or CODE:ISSUER                     = !=
+//	contract  Soroban contract address of the event          = !=
+//	amount    integer in the asset's smallest unit           = != < <= > >=
+//
+// Values containing spaces or operator characters can be double-quoted.
+// and binds tighter than or; keywords are case-insensitive.
+
+// filterExpr is a compiled FILTER_EXPRESSION
+type filterExpr interface {
+	eval(event *token_transfer.TokenTransferEvent) bool
+}
+
+type andExpr struct{ left, right filterExpr }
+
+func (e andExpr) eval(event *token_transfer.TokenTransferEvent) bool {
+	return e.left.eval(event) && e.right.eval(event)
+}
+
+type orExpr struct{ left, right filterExpr }
+
+func (e orExpr) eval(event *token_transfer.TokenTransferEvent) bool {
+	return e.left.eval(event) || e.right.eval(event)
+}
+
+type notExpr struct{ expr filterExpr }
+
+func (e notExpr) eval(event *token_transfer.TokenTransferEvent) bool {
+	return !e.expr.eval(event)
+}
+
+// matchExpr is an = predicate on a non-numeric field; != is not(matchExpr)
+type matchExpr func(event *token_transfer.TokenTransferEvent) bool
+
+func (e matchExpr) eval(event *token_transfer.TokenTransferEvent) bool { return e(event) }
+
+type amountExpr struct {
+	op    string
+	value *big.Int
+}
+
+func (e amountExpr) eval(event *token_transfer.TokenTransferEvent) bool {
+	amount, ok := getEventAmount(event)
+	if !ok {
+		return false
+	}
+	c := amount.Cmp(e.value)
+	switch e.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default: // ">="
+		return c >= 0
+	}
+}
+
+type exprToken struct {
+	text   string
+	quoted bool
+	pos    int
+}
+
+func parseFilterExpression(s string) (filterExpr, error) {
+	tokens, err := lexFilterExpression(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q at offset %d", tok.text, tok.pos)
+	}
+	return expr, nil
+}
+
+func lexFilterExpression(s string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, exprToken{text: string(c), pos: i})
+			i++
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(s) && s[i+1] == '=' {
+				op += "="
+			}
+			switch op {
+			case "!":
+				return nil, fmt.Errorf("unexpected '!' at offset %d (use not or !=)", i)
+			case "==":
+				tokens = append(tokens, exprToken{text: "=", pos: i})
+			default:
+				tokens = append(tokens, exprToken{text: op, pos: i})
+			}
+			i += len(op)
+		case c == '"':
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote at offset %d", i)
+			}
+			tokens = append(tokens, exprToken{text: s[i+1 : i+1+end], quoted: true, pos: i})
+			i += end + 2
+		default:
+			start := i
+			for i < len(s) && !unicode.IsSpace(rune(s[i])) && !strings.ContainsRune("()=!<>\"", rune(s[i])) {
+				i++
+			}
+			tokens = append(tokens, exprToken{text: s[start:i], pos: start})
+		}
+	}
+	return tokens, nil
+}
+
+type exprParser struct {
+	tokens []exprToken
+	next   int
+}
+
+func (p *exprParser) peek() (exprToken, bool) {
+	if p.next >= len(p.tokens) {
+		return exprToken{}, false
+	}
+	return p.tokens[p.next], true
+}
+
+func (p *exprParser) take() (exprToken, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return exprToken{}, fmt.Errorf("unexpected end of expression")
+	}
+	p.next++
+	return tok, nil
+}
+
+func (p *exprParser) keyword(word string) bool {
+	tok, ok := p.peek()
+	if ok && !tok.quoted && strings.EqualFold(tok.text, word) {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (filterExpr, error) {
+	if p.keyword("not") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr}, nil
+	}
+	if tok, ok := p.peek(); ok && !tok.quoted && tok.text == "(" {
+		p.next++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, err := p.take()
+		if err != nil || closing.quoted || closing.text != ")" {
+			return nil, fmt.Errorf("missing ')' for '(' at offset %d", tok.pos)
+		}
+		return expr, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *exprParser) parsePredicate() (filterExpr, error) {
+	field, err := p.take()
+	if err != nil {
+		return nil, err
+	}
+	op, err := p.take()
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.take()
+	if err != nil {
+		return nil, err
+	}
+	if field.quoted || value.text == "(" || value.text == ")" || (!value.quoted && isExprOperator(value.text)) {
+		return nil, fmt.Errorf("expected field, operator and value at offset %d", field.pos)
+	}
+	if op.quoted || !isExprOperator(op.text) {
+		return nil, fmt.Errorf("expected an operator after %q at offset %d", field.text, op.pos)
+	}
+
+	name := strings.ToLower(field.text)
+	if name == "amount" {
+		amount, err := parseAmount(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("amount at offset %d: %w", value.pos, err)
+		}
+		return amountExpr{op: op.text, value: amount}, nil
+	}
+	if op.text != "=" && op.text != "!=" {
+		return nil, fmt.Errorf("%s only supports = and != (offset %d)", name, op.pos)
+	}
+
+	var match matchExpr
+	switch name {
+	case "type":
+		want := strings.ToLower(value.text)
+		switch want {
+		case "transfer", "mint", "burn", "clawback", "fee":
+		default:
+			return nil, fmt.Errorf("unknown event type %q at offset %d", value.text, value.pos)
+		}
+		match = func(event *token_transfer.TokenTransferEvent) bool { return getEventType(event) == want }
+	case "from", "to", "account":
+		account, err := parseAccount(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("%s at offset %d: %w", name, value.pos, err)
+		}
+		match = func(event *token_transfer.TokenTransferEvent) bool {
+			fromMatch := name != "to" && account.matches(getEventFrom(event), nil)
+			toMatch := name != "from" && account.matches(getEventTo(event), event.GetMeta().GetToMuxedInfo())
+			return fromMatch || toMatch
+		}
+	case "asset":
+		assetMatch, err := parseAsset(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("asset at offset %d: %w", value.pos, err)
+		}
+		match = func(event *token_transfer.TokenTransferEvent) bool { return assetMatch.matches(getEventAsset(event)) }
+	case "contract":
+		want := value.text
+		match = func(event *token_transfer.TokenTransferEvent) bool {
+			return event.GetMeta().GetContractAddress() == want
+		}
+	default:
+		return nil, fmt.Errorf("unknown field %q at offset %d", field.text, field.pos)
+	}
+
+	if op.text == "!=" {
+		return notExpr{match}, nil
+	}
+	return match, nil
+}
+
+func isExprOperator(s string) bool {
+	switch s {
+	case "=", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/processors/token_transfer"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	alice, bob := testAccount(1), testAccount(2)
+
+	tests := []struct {
+		name  string
+		expr  string
+		event *token_transfer.TokenTransferEvent
+		want  bool
+	}{
+		{"type", "type=transfer", transferEvent(alice, bob, "50", nil), true},
+		{"other type", "type=transfer", mintEvent(bob, "50", nil), false},
+		{"keywords are case-insensitive", "TYPE = Transfer AND amount >= 50", transferEvent(alice, bob, "50", nil), true},
+		{"== is =", "type==mint", mintEvent(bob, "50", nil), true},
+		{"!=", "type!=mint", transferEvent(alice, bob, "50", nil), true},
+
+		// and binds tighter than or: mint or (transfer and amount>100)
+		{"and before or, left side", "type=mint or type=transfer and amount>100", mintEvent(bob, "50", nil), true},
+		{"and before or, right side", "type=mint or type=transfer and amount>100", transferEvent(alice, bob, "50", nil), false},
+		{"parentheses override precedence", "(type=mint or type=transfer) and amount>100", mintEvent(bob, "50", nil), false},
+		{"nested parentheses", "((type=mint) or (type=burn and amount<10))", mintEvent(bob, "50", nil), true},
+
+		// not binds to the next predicate: (not mint) and amount>100
+		{"not", "not type=mint", transferEvent(alice, bob, "50", nil), true},
+		{"not binds tighter than and", "not type=mint and amount>100", transferEvent(alice, bob, "50", nil), false},
+		{"double not", "not not type=mint", mintEvent(bob, "50", nil), true},
+
+		{"from", "from=" + alice, transferEvent(alice, bob, "50", nil), true},
+		{"to is not from", "to=" + alice, transferEvent(alice, bob, "50", nil), false},
+		{"account matches either side", "account=" + bob, transferEvent(alice, bob, "50", nil), true},
+		{"asset", "asset=USDC:" + issuer, transferEvent(alice, bob, "50", usdc()), true},
+		{"native asset", "asset=native", transferEvent(alice, bob, "50", usdc()), false},
+
+		{"quoted address", `to = "` + bob + `"`, transferEvent(alice, bob, "50", nil), true},
+		{"quoted value with spaces and keywords", `contract="a and b" or type=mint`, withContract(transferEvent(alice, bob, "50", nil), "a and b"), true},
+		{"quoted keyword is a value", `contract="or"`, withContract(transferEvent(alice, bob, "50", nil), "or"), true},
+
+		{"amount above int64", "amount>9223372036854775807", transferEvent(alice, bob, "9223372036854775808", nil), true},
+		{"amount at int64 max", "amount>9223372036854775807", transferEvent(alice, bob, "9223372036854775807", nil), false},
+		{"i128 amount equality", "amount=170141183460469231731687303715884105727", transferEvent(alice, bob, "170141183460469231731687303715884105727", nil), true},
+		{"unparseable event amount", "amount>=0", transferEvent(alice, bob, "NaN", nil), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parseFilterExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("parseFilterExpression(%q): %v", tt.expr, err)
+			}
+			if got := expr.eval(tt.event); got != tt.want {
+				t.Errorf("eval(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterExpressionErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"", "unexpected end of expression"},
+		{"type=transfer and", "unexpected end of expression"},
+		{"(type=mint", "missing ')' for '(' at offset 0"},
+		{"type=mint)", `unexpected ")" at offset 9`},
+		{"type=mint type=burn", `unexpected "type" at offset 10`},
+		{"type!mint", "unexpected '!' at offset 4"},
+		{`contract="abc`, "unterminated quote at offset 9"},
+		{"type transfer x", `expected an operator after "type" at offset 5`},
+		{`"type"=mint`, "expected field, operator and value at offset 0"},
+		{"type=<", "expected field, operator and value at offset 0"},
+		{"size=1", `unknown field "size" at offset 0`},
+		{"type=swap", `unknown event type "swap" at offset 5`},
+		{"type<mint", "type only supports = and != (offset 4)"},
+		{"amount>1.5", "amount at offset 7"},
+		{"amount>=1e9", "amount at offset 8"},
+		{"from=GNOPE", "from at offset 5"},
+		{"asset=USDC:GNOPE", "asset at offset 6"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseFilterExpression(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseFilterExpression(%q) = %v, want error containing %q", tt.expr, err, tt.wantErr)
+		}
+	}
+}
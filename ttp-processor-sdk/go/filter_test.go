@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/asset"
+	"github.com/stellar/go-stellar-sdk/processors/token_transfer"
+	"github.com/stellar/go-stellar-sdk/strkey"
+	"go.uber.org/zap"
+)
+
+var (
+	issuer   = testAccount(9)
+	contract = strkey.MustEncode(strkey.VersionByteContract, bytes.Repeat([]byte{7}, 32))
+)
+
+func testAccount(b byte) string {
+	return strkey.MustEncode(strkey.VersionByteAccountID, bytes.Repeat([]byte{b}, 32))
+}
+
+func testMuxedAccount(t *testing.T, base string, id uint64) string {
+	t.Helper()
+	var m strkey.MuxedAccount
+	if err := m.SetAccountID(base); err != nil {
+		t.Fatal(err)
+	}
+	m.SetID(id)
+	address, err := m.Address()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+func usdc() *asset.Asset {
+	return &asset.Asset{AssetType: &asset.Asset_IssuedAsset{IssuedAsset: &asset.IssuedAsset{AssetCode: "USDC", Issuer: issuer}}}
+}
+
+func native() *asset.Asset {
+	return &asset.Asset{AssetType: &asset.Asset_Native{Native: true}}
+}
+
+func transferEvent(from, to, amount string, a *asset.Asset) *token_transfer.TokenTransferEvent {
+	return &token_transfer.TokenTransferEvent{
+		Meta:  &token_transfer.EventMeta{LedgerSequence: 1, ContractAddress: contract},
+		Event: &token_transfer.TokenTransferEvent_Transfer{Transfer: &token_transfer.Transfer{From: from, To: to, Asset: a, Amount: amount}},
+	}
+}
+
+func mintEvent(to, amount string, a *asset.Asset) *token_transfer.TokenTransferEvent {
+	return &token_transfer.TokenTransferEvent{
+		Meta:  &token_transfer.EventMeta{LedgerSequence: 1, ContractAddress: contract},
+		Event: &token_transfer.TokenTransferEvent_Mint{Mint: &token_transfer.Mint{To: to, Asset: a, Amount: amount}},
+	}
+}
+
+func withContract(event *token_transfer.TokenTransferEvent, address string) *token_transfer.TokenTransferEvent {
+	event.Meta.ContractAddress = address
+	return event
+}
+
+func withToMuxed(event *token_transfer.TokenTransferEvent, info *token_transfer.MuxedInfo) *token_transfer.TokenTransferEvent {
+	event.Meta.ToMuxedInfo = info
+	return event
+}
+
+func muxedID(id uint64) *token_transfer.MuxedInfo {
+	return &token_transfer.MuxedInfo{Content: &token_transfer.MuxedInfo_Id{Id: id}}
+}
+
+func TestShouldIncludeEvent(t *testing.T) {
+	alice, bob, carol := testAccount(1), testAccount(2), testAccount(3)
+	bob7 := testMuxedAccount(t, bob, 7)
+
+	tests := []struct {
+		name       string
+		filter     filterFile
+		event      *token_transfer.TokenTransferEvent
+		wantReason string
+	}{
+		{"no filter", filterFile{}, transferEvent(alice, bob, "1", nil), ""},
+		{"included type", filterFile{IncludeEventTypes: []string{"Transfer"}}, transferEvent(alice, bob, "1", nil), ""},
+		{"type not included", filterFile{IncludeEventTypes: []string{"transfer"}}, mintEvent(bob, "1", nil), "event_type"},
+		{"exclude wins over include", filterFile{IncludeEventTypes: []string{"mint"}, ExcludeEventTypes: []string{"mint"}}, mintEvent(bob, "1", nil), "event_type"},
+
+		{"min amount above int64", filterFile{MinAmount: "9223372036854775808"}, transferEvent(alice, bob, "9223372036854775807", nil), "min_amount"},
+		{"i128 amount within bounds", filterFile{MinAmount: "9223372036854775808", MaxAmount: "170141183460469231731687303715884105727"}, transferEvent(alice, bob, "100000000000000000000", nil), ""},
+		{"max amount", filterFile{MaxAmount: "100"}, transferEvent(alice, bob, "101", nil), "max_amount"},
+		{"unparseable amount", filterFile{MinAmount: "0"}, transferEvent(alice, bob, "", nil), "min_amount"},
+
+		{"contract", filterFile{ContractIDs: []string{contract}}, transferEvent(alice, bob, "1", nil), ""},
+		{"other contract", filterFile{ContractIDs: []string{testAccount(8)}}, transferEvent(alice, bob, "1", nil), "contract_address"},
+
+		{"account as sender", filterFile{Accounts: []string{alice}}, transferEvent(alice, bob, "1", nil), ""},
+		{"account as recipient", filterFile{Accounts: []string{bob}}, transferEvent(alice, bob, "1", nil), ""},
+		{"account on neither side", filterFile{Accounts: []string{carol}}, transferEvent(alice, bob, "1", nil), "account"},
+		{"from account", filterFile{FromAccounts: []string{bob}}, transferEvent(alice, bob, "1", nil), "account"},
+		{"to account", filterFile{ToAccounts: []string{bob}}, mintEvent(bob, "1", nil), ""},
+		{"mint has no sender", filterFile{FromAccounts: []string{bob}}, mintEvent(bob, "1", nil), "account"},
+
+		// Events carry the base account; the recipient's muxed ID is in ToMuxedInfo
+		{"G address matches a muxed recipient", filterFile{ToAccounts: []string{bob}}, withToMuxed(transferEvent(alice, bob, "1", nil), muxedID(7)), ""},
+		{"M address with the recipient's ID", filterFile{ToAccounts: []string{bob7}}, withToMuxed(transferEvent(alice, bob, "1", nil), muxedID(7)), ""},
+		{"M address with another ID", filterFile{ToAccounts: []string{bob7}}, withToMuxed(transferEvent(alice, bob, "1", nil), muxedID(8)), "account"},
+		{"M address without muxed info", filterFile{ToAccounts: []string{bob7}}, transferEvent(alice, bob, "1", nil), "account"},
+		{"M address with a text memo", filterFile{ToAccounts: []string{bob7}}, withToMuxed(transferEvent(alice, bob, "1", nil), &token_transfer.MuxedInfo{Content: &token_transfer.MuxedInfo_Text{Text: "7"}}), "account"},
+		{"M address never matches the sender", filterFile{Accounts: []string{bob7}}, transferEvent(bob, alice, "1", nil), "account"},
+
+		{"native asset", filterFile{Assets: []string{"native"}}, transferEvent(alice, bob, "1", native()), ""},
+		{"asset code from any issuer", filterFile{Assets: []string{"USDC"}}, transferEvent(alice, bob, "1", usdc()), ""},
+		{"asset code and issuer", filterFile{Assets: []string{"USDC:" + issuer}}, transferEvent(alice, bob, "1", usdc()), ""},
+		{"asset from another issuer", filterFile{Assets: []string{"USDC:" + alice}}, transferEvent(alice, bob, "1", usdc()), "asset"},
+		{"custom token has no asset", filterFile{Assets: []string{"native"}}, transferEvent(alice, bob, "1", nil), "asset"},
+
+		{"expression", filterFile{Expression: "type=transfer and amount>=10"}, transferEvent(alice, bob, "10", nil), ""},
+		{"expression rejects", filterFile{Expression: "type=transfer and amount>=10"}, transferEvent(alice, bob, "9", nil), "expression"},
+		{"lists and expression both apply", filterFile{Accounts: []string{alice}, Expression: "to=" + carol}, transferEvent(alice, bob, "1", nil), "expression"},
+	}
+
+	logger := zap.NewNop()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newFilterConfig(tt.filter)
+			if len(config.loadErrors) > 0 {
+				t.Fatalf("filter did not load: %+v", config.loadErrors)
+			}
+			if got := config.ExclusionReason(tt.event, logger); got != tt.wantReason {
+				t.Errorf("ExclusionReason = %q, want %q", got, tt.wantReason)
+			}
+			if got := config.ShouldIncludeEvent(tt.event, logger); got != (tt.wantReason == "") {
+				t.Errorf("ShouldIncludeEvent = %v, want %v", got, tt.wantReason == "")
+			}
+		})
+	}
+}
+
+func TestNewFilterConfigRecordsBadValues(t *testing.T) {
+	config := newFilterConfig(filterFile{
+		Accounts:   []string{"GNOPE"},
+		Assets:     []string{"TOOLONGASSETCODE"},
+		MinAmount:  "1.5",
+		Expression: "type=",
+	})
+	fields := map[string]bool{}
+	for _, e := range config.loadErrors {
+		fields[e.Field] = true
+	}
+	for _, field := range []string{"FILTER_ACCOUNTS", "FILTER_ASSETS", "FILTER_MIN_AMOUNT", "FILTER_EXPRESSION"} {
+		if !fields[field] {
+			t.Errorf("no load error recorded for %s: %+v", field, config.loadErrors)
+		}
+	}
+}
@@ -172,13 +172,8 @@ func main() {
 				metrics.RecordEventExtracted(eventType, config.NetworkName, false)
 
 				// Apply filter
-				if !config.Filter.ShouldIncludeEvent(ttpEvent, logger) {
+				if filterReason := config.Filter.ExclusionReason(ttpEvent, logger); filterReason != "" {
 					filteredCount++
-					// Determine filter reason
-					filterReason := "event_type"
-					if config.Filter.MinAmount != nil && getEventAmount(ttpEvent) < *config.Filter.MinAmount {
-						filterReason = "min_amount"
-					}
 					metrics.RecordEventFiltered(eventType, filterReason, config.NetworkName)
 					continue
 				}